@@ -4,7 +4,7 @@
 //
 // NOTE - INCOMPLETE! DO NOT USE YET.
 //
-// Note that named colors and system colors are not implemented.
+// Note that system colors, and the currentcolor keyword, are not implemented.
 //
 // [CSS Color Module Level 4]: https://www.w3.org/TR/css-color-4/
 //
@@ -55,6 +55,7 @@ import (
 
 const (
     typeHex = "#hexidecimal"
+    typeNamed = "named"
     typeRGB = "rgb()"
     typeHSL = "hsl()"
     typeHWB = "hwb()"
@@ -65,6 +66,7 @@ const (
 
 type Color struct {
     _type string
+    name string // for named colors
     space Space
     components [3]maybe.M[float64]
     alpha maybe.M[float64]
@@ -97,6 +99,8 @@ func (c Color) String() string {
     cC[3] = alpha
 
     switch c._type {
+        case typeNamed:
+            return c.name
         case typeHex:
             r := int(0.5 + (c.components[0].Or(0.0) * 255.0))
             g := int(0.5 + (c.components[1].Or(0.0) * 255.0))
@@ -173,6 +177,26 @@ func Hexadecimal(red, green, blue, alpha uint8) Color {
     }
 }
 
+// Named returns a color as if specified by a CSS named color (e.g. "red"), or
+// the keyword "transparent". The name is matched case-insensitively. Returns
+// false if the name is not a named color.
+func Named(name string) (Color, bool) {
+    name = strings.ToLower(name)
+    if name == "transparent" {
+        c := Hexadecimal(0, 0, 0, 0)
+        c._type = typeNamed
+        c.name = name
+        return c, true
+    }
+
+    x, ok := namedColors[name]
+    if !ok { return Color{}, false }
+    c := Hexadecimal(uint8(x >> 16), uint8(x >> 8), uint8(x), 255)
+    c._type = typeNamed
+    c.name = name
+    return c, true
+}
+
 // RGB returns a color as if specified by the CSS rgb() function. However,
 // each argument here is specified in the normalized range [0,1] (but may lie
 // outside this range until computed). The computed value needs to clamp the
@@ -203,7 +227,7 @@ func HSL(
     alpha maybe.M[float64],
 ) Color {
     return Color{
-        _type: typeRGB,
+        _type: typeHSL,
         space: SpaceSRGB,
         components: [3]maybe.M[float64]{hue, saturation, lightness},
         alpha: alpha,
@@ -233,17 +257,25 @@ func (c Color) Norm() Color {
 
     // clamp all except color() function defined
     switch c._type {
-        case typeHex: fallthrough
-        case typeRGB: fallthrough
-        case typeHSL:
+        case typeHex:   fallthrough
+        case typeNamed: fallthrough
+        case typeRGB:
             clampComponents(clamp_0_1, c.loadPtrs(0, 4))
+        case typeHSL:
+            // hue is an angle, so wraps instead
+            clampComponents(clamp_0_1, c.loadPtrs(1, 4))
     }
 
     // convert hexadecimal, hsl, hsla, hwb, named colors to rgb
     switch c._type {
-        case typeHex: fallthrough
+        case typeHex:   fallthrough
+        case typeNamed: fallthrough
         case typeRGB:
             c._type = typeRGB
+            c.name = ""
+        case typeHSL:
+            c._type = typeRGB
+            c.components = hslToRGB(c.components)
     }
 
     return c
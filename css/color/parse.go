@@ -2,6 +2,7 @@ package color
 
 import (
     "fmt"
+    "math"
     "strings"
 
     "github.com/tawesoft/golib/v2/css/tokenizer"
@@ -34,7 +35,7 @@ var (
     ErrSyntax                    = fmt.Errorf("invalid color syntax")
     ErrUnexpectedEOF             = fmt.Errorf("unexpected end of file")
     ErrUnexpectedTrailing        = fmt.Errorf("unexpected trailing input")
-    ErrNotSupportedNamedOrSystem = fmt.Errorf("named and system colors not supported")
+    ErrNotSupportedNamedOrSystem = fmt.Errorf("unknown named color, or system colors not supported")
     ErrUnrecognisedFunction      = fmt.Errorf("unrecognised function")
     ErrInvalidArguments          = fmt.Errorf("invalid function arguments")
     ErrInvalidHex                = fmt.Errorf("invalid hexadecimal color")
@@ -119,6 +120,7 @@ func ParseColor(tokenizer Tokenizer) (Color, error) {
         if err != nil { return zero, err }
         return parseColorFromFunction(t, args)
     } else if t.Is(token.TypeIdent) { // e.g. "red"
+        if c, ok := Named(t.StringValue()); ok { return c, nil }
         return zero, errSyntax{ErrNotSupportedNamedOrSystem, t.Position()}
     } else {
         return zero, errSyntax{ErrSyntax, t.Position()}
@@ -183,6 +185,9 @@ func parseColorFromFunction(f token.Token, args []token.Token) (Color, error) {
         case strings.EqualFold(name, "rgb"): fallthrough
         case strings.EqualFold(name, "rgba"):
             return parseRGBFromFunction(f, args)
+        case strings.EqualFold(name, "hsl"): fallthrough
+        case strings.EqualFold(name, "hsla"):
+            return parseHSLFromFunction(f, args)
     default:
         return zero, errSyntax{ErrUnrecognisedFunction, f.Position()}
     }
@@ -311,3 +316,102 @@ func parseRGBFromFunction(f token.Token, args []token.Token) (Color, error) {
 
     return zero, errSyntax{ErrInvalidArguments, f.Position()}
 }
+
+// acceptHue accepts a <hue>, which is a <number> (interpreted as degrees) or
+// an <angle>, normalised so that 360 degrees is 1.0.
+func acceptHue(t token.Token) (maybe.M[float64], bool) {
+    _, nv := t.NumericValue()
+    if t.Is(token.TypeNumber) { return maybe.Some(nv / 360.0), true }
+    if !t.Is(token.TypeDimension) { return maybe.Nothing[float64](), false }
+
+    switch strings.ToLower(t.Unit()) {
+        case "deg":  return maybe.Some(nv / 360.0), true
+        case "grad": return maybe.Some(nv / 400.0), true
+        case "rad":  return maybe.Some(nv / (2.0 * math.Pi)), true
+        case "turn": return maybe.Some(nv), true
+    }
+    return maybe.Nothing[float64](), false
+}
+
+func parseHSLFromFunction(f token.Token, args []token.Token) (Color, error) {
+    zero := Color{}
+    var h,s,l,a maybe.M[float64]
+    acceptRawNumber := numericAcceptor(token.TypeNumber, 1.0)
+
+    modern := func() bool {
+        var ok bool
+        rest := args
+
+        t, rest := step(rest)
+        h, ok = acceptEither(t, acceptHue, acceptNone)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        s, ok = acceptEither(t, acceptPercentage, acceptNone)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        l, ok = acceptEither(t, acceptPercentage, acceptNone)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        if t.Is(token.TypeEOF) {
+            a = maybe.Some(1.0)
+            return true
+        }
+        if !(t.Is(token.TypeDelim) && (t.Delim() == '/')) { return false }
+
+        t, rest = step(rest)
+        a, ok = acceptEither(t, acceptPercentage, acceptRawNumber, acceptNone)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        return t.Is(token.TypeEOF)
+    }
+
+    // hsl( [<hue> | none] [<percentage> | none] [<percentage> | none]
+    //     [ / [<alpha-value> | none] ]? )
+    if modern() { return HSL(h, s, l, a), nil }
+
+    legacy := func() bool {
+        var ok bool
+        rest := args
+
+        t, rest := step(rest)
+        h, ok = acceptHue(t)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        if !t.Is(token.TypeComma) { return false }
+
+        t, rest = step(rest)
+        s, ok = acceptPercentage(t)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        if !t.Is(token.TypeComma) { return false }
+
+        t, rest = step(rest)
+        l, ok = acceptPercentage(t)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        if t.Is(token.TypeEOF) {
+            a = maybe.Some(1.0)
+            return true
+        }
+        if !t.Is(token.TypeComma) { return false }
+
+        t, rest = step(rest)
+        a, ok = acceptEither(t, acceptPercentage, acceptRawNumber)
+        if !ok { return false }
+
+        t, rest = step(rest)
+        return t.Is(token.TypeEOF)
+    }
+
+    // hsla?( <hue>, <percentage>, <percentage>, <alpha-value>? )
+    if legacy() { return HSL(h, s, l, a), nil }
+
+    return zero, errSyntax{ErrInvalidArguments, f.Position()}
+}
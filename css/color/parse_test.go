@@ -95,6 +95,54 @@ func TestParseColor(t *testing.T) {
             computed:  "rgb(182, 0, 47)",
             ok: true,
         },
+
+        // named colors
+        {
+            input:     "Red",
+            specified: "red",
+            computed:  "rgb(255, 0, 0)",
+            ok: true,
+        },
+        {
+            input:     "rebeccapurple",
+            specified: "rebeccapurple",
+            computed:  "rgb(102, 51, 153)",
+            ok: true,
+        },
+        {
+            input:     "transparent",
+            specified: "transparent",
+            computed:  "rgba(0, 0, 0, 0)",
+            ok: true,
+        },
+        {
+            input: "currentcolor",
+            ok: false,
+        },
+
+        // hsl
+        {
+            input:     "hsl(120 100% 50%)",
+            specified: "hsl(120, 100%, 50%)",
+            computed:  "rgb(0, 255, 0)",
+            ok: true,
+        },
+        {
+            input:     "hsla(0.5turn, 50%, 25%, 0.5)",
+            specified: "hsla(180, 50%, 25%, 0.5)",
+            computed:  "rgba(31.875, 95.625, 95.625, 0.5)",
+            ok: true,
+        },
+        {
+            input:     "hsl(-120deg 100% 150% / 50%)",
+            specified: "hsla(-120, 100%, 150%, 0.5)",
+            computed:  "rgba(255, 255, 255, 0.5)",
+            ok: true,
+        },
+        {
+            input: "hsl(120, 100%, 50% / 1)",
+            ok: false,
+        },
     }
 
     for _, r := range rows {
@@ -105,6 +153,8 @@ func TestParseColor(t *testing.T) {
             t.Errorf("expected ok=%v for input %s but got (%v, %v)",
                 r.ok, r.input, specified, err)
             continue
+        } else if !r.ok {
+            continue
         }
         if specified.String() != r.specified {
             t.Errorf("expected specified %s but got %s on input %q",
@@ -51,7 +51,8 @@ var (
 
 func clampComponents(clampFunc clampFuncT, ptrs componentPtrs) {
     clampFuncM := maybe.Map(clampFunc)
-    for _, x := range ptrs.components {
+    for i := ptrs.start; i < ptrs.end; i++ {
+        x := ptrs.components[i]
         *x = clampFuncM(*x)
     }
 }
@@ -65,3 +66,24 @@ func clampFunc(
         return math.Min(math.Max(x, min), max)
     }
 }
+
+// hslToRGB converts hue, saturation, and lightness components, each in the
+// normalised range [0, 1], to red, green, and blue components. A missing
+// hue, saturation or lightness is treated as zero. See section 7.1 of the
+// specification.
+func hslToRGB(hsl [3]maybe.M[float64]) [3]maybe.M[float64] {
+    hue := hsl[0].Or(0.0) * 360.0
+    sat := hsl[1].Or(0.0)
+    light := hsl[2].Or(0.0)
+
+    hue = math.Mod(hue, 360.0)
+    if hue < 0 { hue += 360.0 }
+
+    f := func(n float64) maybe.M[float64] {
+        k := math.Mod(n + (hue / 30.0), 12.0)
+        a := sat * math.Min(light, 1.0 - light)
+        return maybe.Some(light - (a * math.Max(-1.0, math.Min(math.Min(k - 3.0, 9.0 - k), 1.0))))
+    }
+
+    return [3]maybe.M[float64]{f(0), f(8), f(4)}
+}
@@ -0,0 +1,494 @@
+// Package media parses and evaluates CSS media queries, based on
+// [Media Queries Level 4] (W3C Candidate Recommendation Draft), 25 December
+// 2021.
+//
+// Media queries are parsed from a sequence of tokens (see [css/tokenizer]),
+// for example the prelude of an @media rule produced by [css/parser] (see
+// [css/parser/item.Tokens]), and can then be evaluated against an
+// [Environment] describing the device.
+//
+// Media queries use three-valued logic: a media feature that is not
+// recognised, or is given an invalid value, and any other syntax enclosed in
+// parentheses that is not a media feature (the <general-enclosed>
+// production), evaluates to "unknown". Here, "unknown" is represented by
+// [maybe.Nothing]. A media query that evaluates to unknown does not match.
+//
+// Media features given with a "min-" or "max-" prefix, or in range syntax,
+// are normalised to comparisons. For example, "(min-width: 400px)" is
+// written back as "(width >= 400px)", which has the same meaning.
+//
+// [Media Queries Level 4]: https://www.w3.org/TR/mediaqueries-4/
+//
+// This software includes material derived from Media Queries Level 4, W3C
+// Candidate Recommendation Draft, 25 December 2021. Copyright © 2021 W3C®
+// (MIT, ERCIM, Keio, Beihang). See LICENSE-PARTS.txt and TRADEMARKS.md.
+package media
+
+import (
+    "math"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/css/value"
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+// List is a media query list, for example "screen, print and (color)".
+type List []Query
+
+// Query is a single media query.
+//
+// An invalid media query in a list is replaced by "not all", which never
+// matches.
+type Query struct {
+    // Not is true if the media query is negated with the "not" keyword.
+    // Only is true if the media query uses the "only" keyword, which has no
+    // effect.
+    Not, Only bool
+
+    // Type is the media type e.g. "screen", in lower case, or the empty
+    // string if the query does not give a media type (equivalent to "all").
+    Type string
+
+    // Condition, if any, is the media condition, e.g. "(color)".
+    Condition maybe.M[Condition]
+}
+
+type Op string
+const (
+    OpFeature = Op("feature") // a media feature, e.g. "(width >= 400px)"
+    OpNot     = Op("not")     // the single Arg, negated
+    OpAnd     = Op("and")     // all Args
+    OpOr      = Op("or")      // any Args
+    OpUnknown = Op("unknown") // a <general-enclosed> or unrecognised feature
+)
+
+// Condition is a media condition, which is a tree of media features joined
+// by "not", "and", and "or".
+type Condition struct {
+    Op Op
+
+    // Feature is the media feature of an OpFeature condition.
+    Feature Feature
+
+    // Args are the children of an OpNot, OpAnd, or OpOr condition.
+    Args []Condition
+
+    // Tokens are the original tokens of an OpUnknown condition, including
+    // the enclosing parentheses or function.
+    Tokens []token.Token
+}
+
+type Comparator string
+const (
+    ComparatorEQ = Comparator("=")
+    ComparatorLT = Comparator("<")
+    ComparatorLE = Comparator("<=")
+    ComparatorGT = Comparator(">")
+    ComparatorGE = Comparator(">=")
+)
+
+// flip returns the comparator that gives the same result with the operands
+// swapped.
+func (c Comparator) flip() Comparator {
+    switch c {
+        case ComparatorLT: return ComparatorGT
+        case ComparatorLE: return ComparatorGE
+        case ComparatorGT: return ComparatorLT
+        case ComparatorGE: return ComparatorLE
+    }
+    return c
+}
+
+// Feature is a media feature, such as "(width >= 400px)".
+type Feature struct {
+    // Name is the name of the feature, in lower case, without any "min-" or
+    // "max-" prefix.
+    Name string
+
+    // Comparisons are tests of the form "Name Op Value". There are none for
+    // a feature in a boolean context e.g. "(color)", one for a feature such
+    // as "(width: 400px)" or "(width < 400px)", and two for a range such
+    // as "(400px <= width < 700px)".
+    Comparisons []Comparison
+}
+
+// Comparison compares a media feature to a value.
+type Comparison struct {
+    Op Comparator
+    Value Value
+}
+
+// Value is the value of a media feature, which is a number, dimension,
+// ratio, or identifier.
+type Value struct {
+    // Ident is the value of an identifier, in lower case. If empty, the
+    // value is a number, dimension, or ratio.
+    Ident string
+
+    // Number is the value of a number or dimension, or the numerator of a
+    // ratio.
+    Number value.Numeric
+
+    // Denominator is the denominator of a ratio, e.g. 9 in "16/9".
+    Denominator maybe.M[float64]
+}
+
+// Environment describes the device that media queries are evaluated
+// against.
+type Environment struct {
+    // MediaType is the media type of the device, "screen" or "print".
+    MediaType string
+
+    // Width and Height are the size of the viewport, in pixels.
+    Width, Height float64
+
+    // Resolution is the pixel density of the device, in dppx.
+    Resolution float64
+
+    // Color is the number of bits per colour component, or zero if the
+    // device is not a colour device. ColorIndex is the number of entries in
+    // the device's colour lookup table, if any. Monochrome is the number of
+    // bits per pixel of a monochrome device, or zero.
+    Color, ColorIndex, Monochrome int
+
+    // Grid is true if the device is a grid device, such as a text terminal.
+    Grid bool
+
+    // FontSize is the initial font-size, in pixels, that relative lengths
+    // such as "em" are resolved against. If zero, this defaults to 16.
+    FontSize float64
+
+    // Features are the values of any discrete media features, for example
+    // "hover": "hover", or "prefers-color-scheme": "dark". A missing
+    // feature takes a value that is false in a boolean context, where known.
+    // The "orientation" and "grid" features are computed from the other
+    // fields instead.
+    Features map[string]string
+}
+
+type kind int
+const (
+    kindLength kind = iota
+    kindRatio
+    kindResolution
+    kindInteger
+    kindDiscrete
+)
+
+type feature struct {
+    kind kind
+    values []string // for a discrete feature, its allowed values
+    falsy string    // for a discrete feature, its value that is false
+}
+
+var features = map[string]feature{
+    "width":            {kind: kindLength},
+    "height":           {kind: kindLength},
+    "aspect-ratio":     {kind: kindRatio},
+    "resolution":       {kind: kindResolution},
+    "color":            {kind: kindInteger},
+    "color-index":      {kind: kindInteger},
+    "monochrome":       {kind: kindInteger},
+    "grid":             {kind: kindDiscrete},
+    "orientation":      {kindDiscrete, []string{"portrait", "landscape"}, ""},
+    "scan":             {kindDiscrete, []string{"interlace", "progressive"}, ""},
+    "update":           {kindDiscrete, []string{"none", "slow", "fast"}, "none"},
+    "overflow-block":   {kindDiscrete, []string{"none", "scroll", "paged"}, "none"},
+    "overflow-inline":  {kindDiscrete, []string{"none", "scroll"}, "none"},
+    "color-gamut":      {kindDiscrete, []string{"srgb", "p3", "rec2020"}, ""},
+    "pointer":          {kindDiscrete, []string{"none", "coarse", "fine"}, "none"},
+    "any-pointer":      {kindDiscrete, []string{"none", "coarse", "fine"}, "none"},
+    "hover":            {kindDiscrete, []string{"none", "hover"}, "none"},
+    "any-hover":        {kindDiscrete, []string{"none", "hover"}, "none"},
+
+    // from Media Queries Level 5
+    "prefers-color-scheme":   {kindDiscrete, []string{"light", "dark"}, ""},
+    "prefers-reduced-motion": {kindDiscrete, []string{"no-preference", "reduce"}, "no-preference"},
+    "prefers-contrast":       {kindDiscrete, []string{"no-preference", "less", "more", "custom"}, "no-preference"},
+}
+
+// deprecated media types are valid, but never match.
+var deprecatedTypes = []string{
+    "tty", "tv", "projection", "handheld", "braille", "embossed", "aural",
+    "speech",
+}
+
+func contains(xs []string, x string) bool {
+    for _, y := range xs {
+        if x == y { return true }
+    }
+    return false
+}
+
+// Match returns true if any media query in the list matches. An empty list
+// always matches.
+func (l List) Match(env Environment) bool {
+    if len(l) == 0 { return true }
+    for _, q := range l {
+        if q.Match(env) { return true }
+    }
+    return false
+}
+
+// Match returns true if the media query matches. A query that evaluates to
+// unknown does not match.
+func (q Query) Match(env Environment) bool {
+    result := maybe.Some(true)
+
+    if q.Type != "" {
+        result = maybe.Some((q.Type == "all") ||
+            ((q.Type == strings.ToLower(env.MediaType)) && !contains(deprecatedTypes, q.Type)))
+    }
+    if c, ok := q.Condition.Unpack(); ok {
+        result = and(result, c.Eval(env))
+    }
+    if q.Not { result = not(result) }
+
+    return result.Or(false)
+}
+
+func not(a maybe.M[bool]) maybe.M[bool] {
+    x, ok := a.Unpack()
+    if !ok { return a }
+    return maybe.Some(!x)
+}
+
+func and(a maybe.M[bool], b maybe.M[bool]) maybe.M[bool] {
+    if (a == maybe.Some(false)) || (b == maybe.Some(false)) { return maybe.Some(false) }
+    if !(a.Ok && b.Ok) { return maybe.Nothing[bool]() }
+    return maybe.Some(true)
+}
+
+func or(a maybe.M[bool], b maybe.M[bool]) maybe.M[bool] {
+    if (a == maybe.Some(true)) || (b == maybe.Some(true)) { return maybe.Some(true) }
+    if !(a.Ok && b.Ok) { return maybe.Nothing[bool]() }
+    return maybe.Some(false)
+}
+
+// Eval evaluates a media condition, returning true or false, or Nothing if
+// the result is unknown.
+func (c Condition) Eval(env Environment) maybe.M[bool] {
+    switch c.Op {
+        case OpFeature:
+            return c.Feature.Eval(env)
+        case OpNot:
+            return not(c.Args[0].Eval(env))
+        case OpAnd:
+            result := maybe.Some(true)
+            for _, x := range c.Args {
+                result = and(result, x.Eval(env))
+            }
+            return result
+        case OpOr:
+            result := maybe.Some(false)
+            for _, x := range c.Args {
+                result = or(result, x.Eval(env))
+            }
+            return result
+        default:
+            return maybe.Nothing[bool]()
+    }
+}
+
+// Eval evaluates a media feature, returning true or false, or Nothing if
+// the feature is not recognised or has an invalid value.
+func (f Feature) Eval(env Environment) maybe.M[bool] {
+    info, ok := features[f.Name]
+    if !ok || !f.valid(info) { return maybe.Nothing[bool]() }
+
+    if info.kind == kindDiscrete {
+        actual := env.discrete(f.Name)
+        if len(f.Comparisons) == 0 {
+            return maybe.Some((actual != "") && (actual != info.falsy) && (actual != "0"))
+        }
+        expected := f.Comparisons[0].Value
+        if f.Name == "grid" { return maybe.Some(actual == expected.Number.String()) }
+        return maybe.Some(actual == expected.Ident)
+    }
+
+    actual := env.numeric(f.Name)
+    if len(f.Comparisons) == 0 {
+        return maybe.Some(actual != 0)
+    }
+    for _, c := range f.Comparisons {
+        expected, ok := env.resolve(info.kind, c.Value)
+        if !ok { return maybe.Nothing[bool]() }
+        if !compare(actual, c.Op, expected) { return maybe.Some(false) }
+    }
+    return maybe.Some(true)
+}
+
+func compare(a float64, op Comparator, b float64) bool {
+    switch op {
+        case ComparatorEQ: return a == b
+        case ComparatorLT: return a < b
+        case ComparatorLE: return a <= b
+        case ComparatorGT: return a > b
+        case ComparatorGE: return a >= b
+    }
+    return false
+}
+
+// valid returns true if a feature has valid comparisons and values.
+func (f Feature) valid(info feature) bool {
+    if (info.kind == kindDiscrete) && (len(f.Comparisons) > 0) {
+        if (len(f.Comparisons) != 1) || (f.Comparisons[0].Op != ComparatorEQ) { return false }
+    }
+    for _, c := range f.Comparisons {
+        if !c.Value.valid(f.Name, info) { return false }
+    }
+    return true
+}
+
+func (v Value) valid(name string, info feature) bool {
+    isNumber := (v.Ident == "") && (v.Number.Unit == "") && !v.Denominator.Ok
+    switch info.kind {
+        case kindLength:
+            if isNumber { return v.Number.Value == 0 }
+            return (v.Ident == "") && (v.Number.Type() == value.TypeLength) && !v.Denominator.Ok
+        case kindRatio:
+            return (v.Ident == "") && (v.Number.Unit == "") && (v.Number.Value >= 0) &&
+                (v.Denominator.Or(1) >= 0)
+        case kindResolution:
+            if v.Ident != "" { return v.Ident == "infinite" }
+            return (v.Number.Type() == value.TypeResolution) && !v.Denominator.Ok
+        case kindInteger:
+            return isNumber && (v.Number.Value == math.Trunc(v.Number.Value))
+        default: // discrete
+            if name == "grid" {
+                return isNumber && ((v.Number.Value == 0) || (v.Number.Value == 1))
+            }
+            return contains(info.values, v.Ident)
+    }
+}
+
+// discrete returns the value of a discrete feature in an environment.
+func (env Environment) discrete(name string) string {
+    switch name {
+        case "grid":
+            if env.Grid { return "1" }
+            return "0"
+        case "orientation":
+            if env.Height >= env.Width { return "portrait" }
+            return "landscape"
+    }
+    return strings.ToLower(env.Features[name])
+}
+
+// numeric returns the value of a range feature in an environment.
+func (env Environment) numeric(name string) float64 {
+    switch name {
+        case "width":        return env.Width
+        case "height":       return env.Height
+        case "aspect-ratio": return env.Width / env.Height
+        case "resolution":   return env.Resolution
+        case "color":        return float64(env.Color)
+        case "color-index":  return float64(env.ColorIndex)
+        case "monochrome":   return float64(env.Monochrome)
+    }
+    return 0
+}
+
+// resolve converts a value to a number comparable with a range feature in
+// an environment.
+func (env Environment) resolve(k kind, v Value) (float64, bool) {
+    switch k {
+        case kindLength:
+            fontSize := env.FontSize
+            if fontSize == 0 { fontSize = 16 }
+            n := v.Number.Resolve(value.Context{
+                FontSize:       fontSize,
+                RootFontSize:   fontSize,
+                LineHeight:     fontSize * 1.2,
+                RootLineHeight: fontSize * 1.2,
+                ViewportWidth:  env.Width,
+                ViewportHeight: env.Height,
+            })
+            if (n.Unit != "px") && (n.Unit != "") { return 0, false }
+            return n.Value, true
+        case kindRatio:
+            return v.Number.Value / v.Denominator.Or(1), true
+        case kindResolution:
+            if v.Ident == "infinite" { return math.Inf(1), true }
+            return v.Number.Canonical().Value, true
+        default:
+            return v.Number.Value, true
+    }
+}
+
+func (l List) String() string {
+    var sb strings.Builder
+    for i, q := range l {
+        if i > 0 { sb.WriteString(", ") }
+        sb.WriteString(q.String())
+    }
+    return sb.String()
+}
+
+func (q Query) String() string {
+    var parts []string
+    if q.Not  { parts = append(parts, "not") }
+    if q.Only { parts = append(parts, "only") }
+    if q.Type != "" { parts = append(parts, serializeIdent(q.Type)) }
+    if c, ok := q.Condition.Unpack(); ok {
+        if q.Type != "" { parts = append(parts, "and") }
+        parts = append(parts, c.serialize(q.Type != ""))
+    }
+    return strings.Join(parts, " ")
+}
+
+func (c Condition) String() string {
+    return c.serialize(false)
+}
+
+// serialize writes a condition. If nested, an "and" or "or" condition is
+// wrapped in parentheses.
+func (c Condition) serialize(nested bool) string {
+    switch c.Op {
+        case OpFeature:
+            return c.Feature.String()
+        case OpNot:
+            return "not " + c.Args[0].serialize(true)
+        case OpAnd: fallthrough
+        case OpOr:
+            parts := make([]string, len(c.Args))
+            for i, x := range c.Args {
+                parts[i] = x.serialize(true)
+            }
+            s := strings.Join(parts, " " + string(c.Op) + " ")
+            if nested { s = "(" + s + ")" }
+            return s
+        default:
+            return tokenizer.Serialize(c.Tokens)
+    }
+}
+
+func (f Feature) String() string {
+    name := serializeIdent(f.Name)
+    switch len(f.Comparisons) {
+        case 0:
+            return "(" + name + ")"
+        case 1:
+            c := f.Comparisons[0]
+            if c.Op == ComparatorEQ { return "(" + name + ": " + c.Value.String() + ")" }
+            return "(" + name + " " + string(c.Op) + " " + c.Value.String() + ")"
+        default:
+            a, b := f.Comparisons[0], f.Comparisons[1]
+            return "(" + a.Value.String() + " " + string(a.Op.flip()) + " " + name +
+                " " + string(b.Op) + " " + b.Value.String() + ")"
+    }
+}
+
+func (v Value) String() string {
+    if v.Ident != "" { return serializeIdent(v.Ident) }
+    if d, ok := v.Denominator.Unpack(); ok {
+        return v.Number.String() + "/" + value.Number(d).String()
+    }
+    return v.Number.String()
+}
+
+func serializeIdent(s string) string {
+    return tokenizer.Serialize([]token.Token{token.Ident(s)})
+}
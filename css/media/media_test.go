@@ -0,0 +1,152 @@
+package media_test
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/media"
+)
+
+func ExampleParseString() {
+    list, err := media.ParseString("screen and (400px <= width <= 700px), print")
+    if err != nil { panic(err) }
+    fmt.Println(list)
+
+    for _, width := range []float64{320, 600} {
+        env := media.Environment{MediaType: "screen", Width: width, Height: 800}
+        fmt.Printf("%v: %v\n", width, list.Match(env))
+    }
+
+    // Output:
+    // screen and (400px <= width <= 700px), print
+    // 320: false
+    // 600: true
+}
+
+func TestParseString(t *testing.T) {
+    type row struct {
+        input string
+        expected string
+        err error
+    }
+    rows := []row{
+        {"",                                  "",                                  nil},
+        {"screen",                            "screen",                            nil},
+        {"SCREEN, Print",                     "screen, print",                     nil},
+        {"only screen and (color)",           "only screen and (color)",           nil},
+        {"not print and (min-width: 40em)",   "not print and (width >= 40em)",     nil},
+        {"(max-width:600px)",                 "(width <= 600px)",                  nil},
+        {"(width: 600px)",                    "(width: 600px)",                    nil},
+        {"(width < 600px)",                   "(width < 600px)",                   nil},
+        {"(600px > width)",                   "(width < 600px)",                   nil},
+        {"(400px<=width<=700px)",             "(400px <= width <= 700px)",         nil},
+        {"(700px > width > 400px)",           "(700px > width > 400px)",           nil},
+        {"(aspect-ratio: 16 / 9)",            "(aspect-ratio: 16/9)",              nil},
+        {"(min-resolution: 2dppx)",           "(resolution >= 2dppx)",             nil},
+        {"(resolution < infinite)",           "(resolution < infinite)",           nil},
+        {"(orientation: landscape)",          "(orientation: landscape)",          nil},
+        {"not (color) and (hover)",           "not all",                           media.ErrSyntax},
+        {"not (color)",                       "not (color)",                       nil},
+        {"(color) and ((hover) or (grid))",   "(color) and ((hover) or (grid))",   nil},
+        {"(color) and (hover) or (grid)",     "not all",                           media.ErrSyntax},
+        {"screen and (color) or (hover)",     "not all",                           media.ErrSyntax},
+        {"screen and not (color)",            "screen and not (color)",            nil},
+        {"(unknown-feature)",                 "(unknown-feature)",                 nil},
+        {"(width: red)",                      "(width: red)",                      nil},
+        {"(min-orientation: portrait)",       "(min-orientation: portrait)",       nil},
+        {"(400px < width > 700px)",           "(400px < width > 700px)",           nil},
+        {"foo(bar) or (color)",               "foo(bar) or (color)",               nil},
+        {"screen and",                        "not all",                           media.ErrUnexpectedEOF},
+        {"and",                               "not all",                           media.ErrSyntax},
+        {"(color, print",                     "not all",                           media.ErrUnexpectedEOF},
+        {"screen, (width >), print",          "screen, (width >), print",          nil},
+        {"screen, print and, tv",             "screen, not all, tv",               media.ErrUnexpectedEOF},
+    }
+    for _, r := range rows {
+        l, err := media.ParseString(r.input)
+        if r.err == nil && err != nil {
+            t.Errorf("%q: unexpected error %v", r.input, err)
+        } else if !errors.Is(err, r.err) {
+            t.Errorf("%q: expected error %v but got %v", r.input, r.err, err)
+        }
+        if l.String() != r.expected {
+            t.Errorf("%q: expected %q but got %q", r.input, r.expected, l.String())
+        }
+    }
+}
+
+func TestList_Match(t *testing.T) {
+    env := media.Environment{
+        MediaType: "screen",
+        Width: 800,
+        Height: 600,
+        Resolution: 2,
+        Color: 8,
+        Features: map[string]string{
+            "hover": "hover",
+            "prefers-color-scheme": "dark",
+            "prefers-reduced-motion": "no-preference",
+        },
+    }
+    type row struct {
+        input string
+        expected bool
+    }
+    rows := []row{
+        {"",                                       true},
+        {"all",                                    true},
+        {"screen",                                 true},
+        {"print",                                  false},
+        {"not print",                              true},
+        {"tv",                                     false},
+        {"not tv",                                 true},
+        {"print, screen",                          true},
+        {"(width: 800px)",                         true},
+        {"(width: 50em)",                          true},
+        {"(min-width: 801px)",                     false},
+        {"(max-width: 100vw)",                     true},
+        {"(400px <= width <= 800px)",              true},
+        {"(400px <= width < 800px)",               false},
+        {"(height > 599.5px)",                     true},
+        {"(width)",                                true},
+        {"(aspect-ratio: 4/3)",                    true},
+        {"(min-aspect-ratio: 16/9)",               false},
+        {"(aspect-ratio > 1)",                     true},
+        {"(orientation: landscape)",               true},
+        {"(orientation: portrait)",                false},
+        {"(resolution: 2dppx)",                    true},
+        {"(min-resolution: 192dpi)",               true},
+        {"(resolution < infinite)",                true},
+        {"(color)",                                true},
+        {"(min-color: 8)",                         true},
+        {"(monochrome)",                           false},
+        {"(grid)",                                 false},
+        {"(grid: 0)",                              true},
+        {"(hover)",                                true},
+        {"(hover: none)",                          false},
+        {"(pointer)",                              false},
+        {"(prefers-color-scheme: dark)",           true},
+        {"(prefers-reduced-motion)",               false},
+        {"not (hover)",                            false},
+        {"(hover) and (width > 1000px)",           false},
+        {"(hover) or (width > 1000px)",            true},
+        {"only screen and ((color) or (grid))",    true},
+        {"(unknown-feature)",                      false},
+        {"not (unknown-feature)",                  false},
+        {"not screen and (unknown-feature)",       false},
+        {"(unknown-feature) or (color)",           true},
+        {"(width: red)",                           false},
+        {"(width: 800)",                           false},
+        {"(width: 0)",                             false},
+        {"screen, (width >), print",               true},
+        {"print and, screen",                      true},
+        {"print and",                              false},
+    }
+    for _, r := range rows {
+        l, _ := media.ParseString(r.input)
+        if got := l.Match(env); got != r.expected {
+            t.Errorf("%q: expected %v but got %v", r.input, r.expected, got)
+        }
+    }
+}
@@ -0,0 +1,430 @@
+package media
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/css/value"
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+var (
+    ErrSyntax        = fmt.Errorf("invalid media query syntax")
+    ErrUnexpectedEOF = fmt.Errorf("unexpected end of media query")
+)
+
+type errSyntax struct {
+    err error
+    at token.Position
+}
+
+func (e errSyntax) Unwrap() error {
+    return e.err
+}
+
+func (e errSyntax) Error() string {
+    return fmt.Sprintf("error at %s: %s", e.at, e.err.Error())
+}
+
+// reserved words that cannot be used as a media type.
+var reserved = []string{"not", "and", "or", "only", "layer"}
+
+// ParseString parses a media query list from a string.
+func ParseString(s string) (List, error) {
+    k := tokenizer.New(strings.NewReader(s))
+    var tokens []token.Token
+    for {
+        t := k.Next()
+        if t.Is(token.TypeEOF) { break }
+        tokens = append(tokens, t)
+    }
+    if len(k.Errors()) > 0 {
+        return nil, fmt.Errorf("parse errors: %+v", k.Errors())
+    }
+    return Parse(tokens)
+}
+
+// Parse parses a media query list from a sequence of tokens. The sequence
+// should not include a token.EOF().
+//
+// As required by the specification, an invalid media query does not make
+// the whole list invalid. Instead, it is replaced with "not all", which
+// never matches. In this case, the list is returned along with the error
+// for the first invalid media query.
+func Parse(tokens []token.Token) (List, error) {
+    var result List
+    var firstErr error
+
+    parts := splitCommas(tokens)
+    if (len(parts) == 1) && (len(trim(parts[0])) == 0) { return result, nil }
+
+    for _, part := range parts {
+        q, err := parseQuery(part)
+        if err != nil {
+            if firstErr == nil { firstErr = err }
+            q = Query{Not: true, Type: "all"}
+        }
+        result = append(result, q)
+    }
+    return result, firstErr
+}
+
+// splitCommas splits tokens on any top-level comma.
+func splitCommas(tokens []token.Token) [][]token.Token {
+    var result [][]token.Token
+    depth, start := 0, 0
+    for i, t := range tokens {
+        switch {
+            case isOpen(t):
+                depth++
+            case isClose(t):
+                if depth > 0 { depth-- }
+            case t.Is(token.TypeComma) && (depth == 0):
+                result = append(result, tokens[start:i])
+                start = i + 1
+        }
+    }
+    return append(result, tokens[start:])
+}
+
+func isOpen(t token.Token) bool {
+    return t.Is(token.TypeFunction) || t.Is(token.TypeLeftParen) ||
+        t.Is(token.TypeLeftSquareBracket) || t.Is(token.TypeLeftCurlyBracket)
+}
+
+func isClose(t token.Token) bool {
+    return t.Is(token.TypeRightParen) ||
+        t.Is(token.TypeRightSquareBracket) || t.Is(token.TypeRightCurlyBracket)
+}
+
+// trim removes leading and trailing whitespace tokens.
+func trim(tokens []token.Token) []token.Token {
+    for (len(tokens) > 0) && tokens[0].Is(token.TypeWhitespace) {
+        tokens = tokens[1:]
+    }
+    for (len(tokens) > 0) && tokens[len(tokens) - 1].Is(token.TypeWhitespace) {
+        tokens = tokens[:len(tokens) - 1]
+    }
+    return tokens
+}
+
+type parser struct {
+    tokens []token.Token
+    pos int
+}
+
+// peek returns the next token, or EOF.
+func (p *parser) peek() token.Token {
+    if p.pos >= len(p.tokens) { return token.EOF() }
+    return p.tokens[p.pos]
+}
+
+func (p *parser) next() token.Token {
+    t := p.peek()
+    if p.pos < len(p.tokens) { p.pos++ }
+    return t
+}
+
+func (p *parser) skipWS() {
+    for p.peek().Is(token.TypeWhitespace) { p.pos++ }
+}
+
+// peekKeyword returns the lower case value of the next token, after
+// whitespace, if it is an identifier.
+func (p *parser) peekKeyword() string {
+    p.skipWS()
+    t := p.peek()
+    if !t.Is(token.TypeIdent) { return "" }
+    return strings.ToLower(t.StringValue())
+}
+
+func (p *parser) errorf(err error) error {
+    t := p.peek()
+    if t.Is(token.TypeEOF) { err = ErrUnexpectedEOF }
+    return errSyntax{err, t.Position()}
+}
+
+func (p *parser) expectEOF() error {
+    p.skipWS()
+    if !p.peek().Is(token.TypeEOF) { return p.errorf(ErrSyntax) }
+    return nil
+}
+
+// parseQuery parses a media query:
+//
+//     <media-query> = <media-condition>
+//                   | [ not | only ]? <media-type> [ and <media-condition-without-or> ]?
+func parseQuery(tokens []token.Token) (Query, error) {
+    var q Query
+    p := &parser{tokens: tokens}
+
+    kw := p.peekKeyword()
+    if (kw == "") || ((kw == "not") && p.notCondition()) {
+        c, err := p.condition(true)
+        if err != nil { return q, err }
+        q.Condition = maybe.Some(c)
+        return q, p.expectEOF()
+    }
+
+    if (kw == "not") || (kw == "only") {
+        q.Not, q.Only = (kw == "not"), (kw == "only")
+        p.next()
+        kw = p.peekKeyword()
+    }
+    if (kw == "") || contains(reserved, kw) { return q, p.errorf(ErrSyntax) }
+    q.Type = kw
+    p.next()
+
+    if p.peekKeyword() == "and" {
+        p.next()
+        c, err := p.condition(false)
+        if err != nil { return q, err }
+        q.Condition = maybe.Some(c)
+    }
+    return q, p.expectEOF()
+}
+
+// notCondition returns true if the next token is the keyword "not",
+// followed by the start of a <media-in-parens>, rather than a media type.
+func (p *parser) notCondition() bool {
+    start := p.pos
+    defer func() { p.pos = start }()
+    p.next()
+    p.skipWS()
+    t := p.peek()
+    return t.Is(token.TypeLeftParen) || t.Is(token.TypeFunction)
+}
+
+// condition parses a <media-condition>, or a
+// <media-condition-without-or> if allowOr is false.
+func (p *parser) condition(allowOr bool) (Condition, error) {
+    if p.peekKeyword() == "not" {
+        p.next()
+        x, err := p.inParens()
+        if err != nil { return x, err }
+        return Condition{Op: OpNot, Args: []Condition{x}}, nil
+    }
+
+    first, err := p.inParens()
+    if err != nil { return first, err }
+    args := []Condition{first}
+
+    var op Op
+    for {
+        start := p.pos
+        kw := p.peekKeyword()
+        if !((kw == "and") || ((kw == "or") && allowOr)) {
+            p.pos = start
+            break
+        }
+        if (op != "") && (op != Op(kw)) { return first, p.errorf(ErrSyntax) }
+        op = Op(kw)
+        p.next()
+
+        x, err := p.inParens()
+        if err != nil { return x, err }
+        args = append(args, x)
+    }
+
+    if len(args) == 1 { return first, nil }
+    return Condition{Op: op, Args: args}, nil
+}
+
+// block returns the tokens of a block or function starting at the next
+// token, including the opening and closing tokens.
+func (p *parser) block() ([]token.Token, error) {
+    start := p.pos
+    depth := 0
+    for {
+        t := p.next()
+        switch {
+            case t.Is(token.TypeEOF):
+                return nil, errSyntax{ErrUnexpectedEOF, t.Position()}
+            case isOpen(t):
+                depth++
+            case isClose(t):
+                depth--
+        }
+        if depth == 0 { return p.tokens[start:p.pos], nil }
+    }
+}
+
+// inParens parses a <media-in-parens>, which is a parenthesised
+// <media-condition>, a <media-feature>, or a <general-enclosed>.
+func (p *parser) inParens() (Condition, error) {
+    p.skipWS()
+    t := p.peek()
+    if !(t.Is(token.TypeLeftParen) || t.Is(token.TypeFunction)) {
+        return Condition{}, p.errorf(ErrSyntax)
+    }
+    block, err := p.block()
+    if err != nil { return Condition{}, err }
+    unknown := Condition{Op: OpUnknown, Tokens: block}
+    if t.Is(token.TypeFunction) { return unknown, nil }
+
+    inner := block[1:len(block) - 1]
+
+    q := &parser{tokens: inner}
+    if c, err := q.condition(true); (err == nil) && (q.expectEOF() == nil) {
+        return c, nil
+    }
+
+    if f, err := parseFeature(inner); err == nil {
+        if info, ok := features[f.Name]; ok && f.valid(info) {
+            return Condition{Op: OpFeature, Feature: f}, nil
+        }
+    }
+    return unknown, nil
+}
+
+// atom is a <mf-name> or <mf-value> in a media feature.
+type atom struct {
+    ident string
+    value Value
+}
+
+// parseFeature parses the contents of a <media-feature>:
+//
+//     <mf-boolean> = <mf-name>
+//     <mf-plain>   = <mf-name> : <mf-value>
+//     <mf-range>   = <mf-name> <mf-comparison> <mf-value>
+//                  | <mf-value> <mf-comparison> <mf-name>
+//                  | <mf-value> <mf-lt> <mf-name> <mf-lt> <mf-value>
+//                  | <mf-value> <mf-gt> <mf-name> <mf-gt> <mf-value>
+func parseFeature(tokens []token.Token) (Feature, error) {
+    var f Feature
+    p := &parser{tokens: tokens}
+
+    a, err := p.atom()
+    if err != nil { return f, err }
+
+    p.skipWS()
+    if p.peek().Is(token.TypeEOF) {
+        if a.ident == "" { return f, p.errorf(ErrSyntax) }
+        f.Name = a.ident
+        return f, nil
+    }
+
+    if p.peek().Is(token.TypeColon) {
+        if a.ident == "" { return f, p.errorf(ErrSyntax) }
+        p.next()
+        b, err := p.atom()
+        if err != nil { return f, err }
+
+        op := ComparatorEQ
+        f.Name = a.ident
+        if name, ok := strings.CutPrefix(a.ident, "min-"); ok {
+            op, f.Name = ComparatorGE, name
+        } else if name, ok := strings.CutPrefix(a.ident, "max-"); ok {
+            op, f.Name = ComparatorLE, name
+        }
+        if (op != ComparatorEQ) && (features[f.Name].kind == kindDiscrete) {
+            return f, p.errorf(ErrSyntax)
+        }
+        f.Comparisons = []Comparison{{op, b.value}}
+        return f, p.expectEOF()
+    }
+
+    op1, err := p.comparator()
+    if err != nil { return f, err }
+    b, err := p.atom()
+    if err != nil { return f, err }
+
+    p.skipWS()
+    if p.peek().Is(token.TypeEOF) {
+        if (a.ident != "") && !isValueKeyword(a.ident) {
+            f.Name = a.ident
+            f.Comparisons = []Comparison{{op1, b.value}}
+        } else if b.ident != "" {
+            f.Name = b.ident
+            f.Comparisons = []Comparison{{op1.flip(), a.value}}
+        } else {
+            return f, p.errorf(ErrSyntax)
+        }
+        return f, nil
+    }
+
+    op2, err := p.comparator()
+    if err != nil { return f, err }
+    c, err := p.atom()
+    if err != nil { return f, err }
+
+    lt := func(x Comparator) bool { return (x == ComparatorLT) || (x == ComparatorLE) }
+    gt := func(x Comparator) bool { return (x == ComparatorGT) || (x == ComparatorGE) }
+    if (b.ident == "") || !((lt(op1) && lt(op2)) || (gt(op1) && gt(op2))) {
+        return f, p.errorf(ErrSyntax)
+    }
+    f.Name = b.ident
+    f.Comparisons = []Comparison{{op1.flip(), a.value}, {op2, c.value}}
+    return f, p.expectEOF()
+}
+
+// isValueKeyword returns true for an identifier that is a value of a range
+// feature, rather than the name of a feature.
+func isValueKeyword(x string) bool {
+    return x == "infinite"
+}
+
+// atom parses a <mf-name> or <mf-value>, which is a number, dimension,
+// ratio, or identifier.
+func (p *parser) atom() (atom, error) {
+    p.skipWS()
+    t := p.next()
+    _, v := t.NumericValue()
+
+    switch t.Type() {
+        case token.TypeIdent:
+            x := strings.ToLower(t.StringValue())
+            return atom{ident: x, value: Value{Ident: x}}, nil
+        case token.TypeDimension:
+            return atom{value: Value{Number: value.Dimension(v, t.Unit())}}, nil
+        case token.TypeNumber:
+            result := atom{value: Value{Number: value.Number(v)}}
+
+            // <ratio> = <number [0,∞]> [ / <number [0,∞]> ]?
+            start := p.pos
+            p.skipWS()
+            if p.peek().Is(token.TypeDelim) && (p.peek().Delim() == '/') {
+                p.next()
+                p.skipWS()
+                if d := p.next(); d.Is(token.TypeNumber) {
+                    _, dv := d.NumericValue()
+                    result.value.Denominator = maybe.Some(dv)
+                    return result, nil
+                }
+                return result, p.errorf(ErrSyntax)
+            }
+            p.pos = start
+            return result, nil
+        case token.TypeEOF:
+            return atom{}, errSyntax{ErrUnexpectedEOF, t.Position()}
+    }
+    return atom{}, errSyntax{ErrSyntax, t.Position()}
+}
+
+// comparator parses a <mf-comparison> i.e. "=", "<", "<=", ">", or ">=".
+func (p *parser) comparator() (Comparator, error) {
+    p.skipWS()
+    t := p.next()
+    if !t.Is(token.TypeDelim) { return "", errSyntax{ErrSyntax, t.Position()} }
+
+    orEqual := func() bool {
+        eq := p.peek().Is(token.TypeDelim) && (p.peek().Delim() == '=')
+        if eq { p.next() }
+        return eq
+    }
+
+    switch t.Delim() {
+        case '=':
+            return ComparatorEQ, nil
+        case '<':
+            if orEqual() { return ComparatorLE, nil }
+            return ComparatorLT, nil
+        case '>':
+            if orEqual() { return ComparatorGE, nil }
+            return ComparatorGT, nil
+    }
+    return "", errSyntax{ErrSyntax, t.Position()}
+}
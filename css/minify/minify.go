@@ -0,0 +1,355 @@
+// Package minify removes unnecessary whitespace and comments from CSS, and
+// rewrites some values in a shorter form, without changing their meaning.
+//
+// The minifier works on the tokens produced by [css/tokenizer], and writes
+// them back to CSS using a [tokenizer.Serializer]. It does not parse the
+// stylesheet into rules, so it is fast and tolerant of syntax it does not
+// recognise, but it only makes changes that are safe everywhere they can
+// occur:
+//
+//   - comments are removed (the tokenizer discards them);
+//   - whitespace is removed where it cannot be significant, for example
+//     around "{", "}", ";", and ",", and redundant semicolons are removed,
+//     including the last semicolon in a block;
+//   - numbers are written without redundant zeros, e.g. "0.50" becomes ".5"
+//     and "0.0" becomes "0";
+//   - in a declaration value, a zero length is written without a unit, e.g.
+//     "0px" becomes "0", except for the "flex" property (with or without a
+//     vendor prefix) and inside functions such as calc(), where the unit is
+//     significant;
+//   - in a declaration value, hexadecimal colours are written in lower case,
+//     and in the short form where possible, e.g. "#AABBCC" becomes "#abc".
+//
+// Numbers and colours in the value of a custom property, such as "--x: 0px",
+// are left unchanged, because the value may be substituted anywhere with
+// var().
+package minify
+
+import (
+    "fmt"
+    "io"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/css/value"
+)
+
+// Minify reads CSS from r, and writes the minified CSS to w.
+//
+// If the input contains parse errors, the input is still minified, but an
+// error is returned listing the parse errors. The output, when tokenized,
+// produces the same parse errors, as far as possible.
+func Minify(w io.Writer, r io.Reader) error {
+    k := tokenizer.New(r)
+    var tokens []token.Token
+    for {
+        t := k.Next()
+        if t.Is(token.TypeEOF) { break }
+        tokens = append(tokens, t)
+    }
+
+    s := tokenizer.NewSerializer(w)
+    for _, t := range Tokens(tokens) {
+        if err := s.Write(t); err != nil { return err }
+    }
+    if err := s.Flush(); err != nil { return err }
+
+    if len(k.Errors()) > 0 {
+        return fmt.Errorf("parse errors: %+v", k.Errors())
+    }
+    return nil
+}
+
+// String returns the input minified. See [Minify].
+func String(s string) (string, error) {
+    var sb strings.Builder
+    err := Minify(&sb, strings.NewReader(s))
+    return sb.String(), err
+}
+
+// Tokens performs the minification on a sequence of tokens, returning a new
+// sequence of tokens to be serialized.
+func Tokens(tokens []token.Token) []token.Token {
+    properties := declarationValues(tokens)
+    result := make([]token.Token, 0, len(tokens))
+
+    // open blocks and functions
+    var stack []token.Type
+    functions := 0
+
+    last := func() token.Token {
+        if len(result) == 0 { return token.EOF() }
+        return result[len(result) - 1]
+    }
+
+    for i, t := range tokens {
+        j := nextExceptWS(tokens, i + 1)
+        next := token.EOF()
+        if j < len(tokens) { next = tokens[j] }
+        property := properties[i]
+        custom := strings.HasPrefix(property, "--")
+
+        switch t.Type() {
+            case token.TypeWhitespace:
+                if last().Is(token.TypeWhitespace) { continue }
+                if canRemoveWhitespace(last(), next, functions > 0) { continue }
+
+                // whitespace before the colon of a declaration, or of a
+                // media feature e.g. "(width : 100px)".
+                inParens := (len(stack) > 0) && (stack[len(stack) - 1] == token.TypeLeftParen)
+                if next.Is(token.TypeColon) && (((functions == 0) && inParens) || (properties[j] != "")) {
+                    continue
+                }
+            case token.TypeSemicolon:
+                if last().Is(token.TypeEOF) || last().Is(token.TypeLeftCurlyBracket) ||
+                    last().Is(token.TypeSemicolon) || next.Is(token.TypeSemicolon) ||
+                    next.Is(token.TypeRightCurlyBracket) {
+                    continue
+                }
+            case token.TypeNumber:     fallthrough
+            case token.TypePercentage: fallthrough
+            case token.TypeDimension:
+                if custom { break }
+                t = shortenNumber(t)
+                if (property != "") && (unprefixed(property) != "flex") && (functions == 0) {
+                    t = trimZeroLength(t)
+                }
+            case token.TypeHash:
+                if (property != "") && !custom { t = shortenColor(t) }
+            case token.TypeFunction:           fallthrough
+            case token.TypeLeftParen:          fallthrough
+            case token.TypeLeftSquareBracket:  fallthrough
+            case token.TypeLeftCurlyBracket:
+                stack = append(stack, t.Type())
+                if t.Is(token.TypeFunction) { functions++ }
+            case token.TypeRightParen:         fallthrough
+            case token.TypeRightSquareBracket: fallthrough
+            case token.TypeRightCurlyBracket:
+                if len(stack) > 0 {
+                    if stack[len(stack) - 1] == token.TypeFunction { functions-- }
+                    stack = stack[:len(stack) - 1]
+                }
+        }
+
+        result = append(result, t)
+    }
+
+    return result
+}
+
+// nextExceptWS returns the index of the first token from tokens[i:] that is
+// not whitespace, or len(tokens).
+func nextExceptWS(tokens []token.Token, i int) int {
+    for ; i < len(tokens); i++ {
+        if !tokens[i].Is(token.TypeWhitespace) { break }
+    }
+    return i
+}
+
+// prevExceptWS returns the index of the last token from tokens[:i] that is
+// not whitespace, or -1.
+func prevExceptWS(tokens []token.Token, i int) int {
+    for i--; i >= 0; i-- {
+        if !tokens[i].Is(token.TypeWhitespace) { return i }
+    }
+    return -1
+}
+
+// unprefixed returns a property name without a vendor prefix, such as
+// "-webkit-".
+func unprefixed(property string) string {
+    if strings.HasPrefix(property, "-") && !strings.HasPrefix(property, "--") {
+        if _, name, ok := strings.Cut(property[1:], "-"); ok { return name }
+    }
+    return property
+}
+
+func isDelim(t token.Token, xs string) bool {
+    return t.Is(token.TypeDelim) && strings.ContainsRune(xs, t.Delim())
+}
+
+// canRemoveWhitespace returns true if a whitespace token between prev and
+// next is not significant.
+func canRemoveWhitespace(prev token.Token, next token.Token, inFunction bool) bool {
+    punctuation := func(t token.Token) bool {
+        return t.Is(token.TypeEOF) ||
+            t.Is(token.TypeLeftCurlyBracket) || t.Is(token.TypeRightCurlyBracket) ||
+            t.Is(token.TypeSemicolon) || t.Is(token.TypeComma)
+    }
+
+    switch {
+        case punctuation(prev) || punctuation(next):
+            return true
+        case prev.Is(token.TypeColon):
+            return true
+        case prev.Is(token.TypeLeftParen) || prev.Is(token.TypeFunction):
+            return true
+        case next.Is(token.TypeRightParen):
+            return true
+        case isDelim(next, "!"):
+            return true
+        case inFunction:
+            // e.g. "calc(1px + 2px)", where whitespace around "+" and "-" is
+            // significant.
+            return false
+        case isDelim(prev, ">~+") || isDelim(next, ">~+"):
+            // combinators in selectors, and comparisons in media queries.
+            return true
+    }
+    return false
+}
+
+// declarationValues returns, for each token, the lower case name of the
+// property if the token is the colon or part of the value of a declaration,
+// or the empty string.
+//
+// A declaration is recognised as an <ident-token> and a <colon-token> inside
+// a {}-block, at the start of the block or following a semicolon, that is
+// ended by a semicolon or the end of the block. This distinguishes a
+// declaration such as "color: red" from a nested rule with a selector such
+// as "a:hover { ... }".
+func declarationValues(tokens []token.Token) []string {
+    result := make([]string, len(tokens))
+    depth := 0 // {}-blocks
+
+    for i, t := range tokens {
+        switch {
+            case t.Is(token.TypeLeftCurlyBracket):
+                depth++
+                continue
+            case t.Is(token.TypeRightCurlyBracket):
+                if depth > 0 { depth-- }
+                continue
+            case !t.Is(token.TypeColon) || (depth == 0):
+                continue
+        }
+
+        name := prevExceptWS(tokens, i)
+        if (name < 0) || !tokens[name].Is(token.TypeIdent) { continue }
+        if before := prevExceptWS(tokens, name); before >= 0 {
+            b := tokens[before]
+            if !(b.Is(token.TypeLeftCurlyBracket) || b.Is(token.TypeSemicolon) ||
+                b.Is(token.TypeRightCurlyBracket)) {
+                continue
+            }
+        }
+
+        end, ok := declarationEnd(tokens, i + 1)
+        if !ok { continue }
+        property := strings.ToLower(tokens[name].StringValue())
+        for j := i; j < end; j++ {
+            result[j] = property
+        }
+    }
+
+    return result
+}
+
+// declarationEnd returns the index of the semicolon or closing bracket that
+// ends a declaration value starting at tokens[i], or false if a "{" is
+// found first, meaning that this is not a declaration.
+func declarationEnd(tokens []token.Token, i int) (int, bool) {
+    depth := 0 // (), [], and functions
+    for ; i < len(tokens); i++ {
+        t := tokens[i]
+        switch {
+            case t.Is(token.TypeFunction) || t.Is(token.TypeLeftParen) ||
+                t.Is(token.TypeLeftSquareBracket):
+                depth++
+            case t.Is(token.TypeRightParen) || t.Is(token.TypeRightSquareBracket):
+                if depth > 0 { depth-- }
+            case t.Is(token.TypeLeftCurlyBracket):
+                return i, false
+            case (depth == 0) && (t.Is(token.TypeSemicolon) || t.Is(token.TypeRightCurlyBracket)):
+                return i, true
+        }
+    }
+    return i, true
+}
+
+// shortenNumber removes redundant zeros from the representation of a
+// numeric token, without changing whether it is an integer, except for zero.
+// For example, "0.50" becomes ".5", "007" becomes "7", and "0.0" becomes
+// "0".
+func shortenNumber(t token.Token) token.Token {
+    repr := t.Repr()
+    if (repr == "") || strings.ContainsAny(repr, "eE") { return t }
+
+    sign := ""
+    if (repr[0] == '+') || (repr[0] == '-') {
+        sign, repr = repr[0:1], repr[1:]
+    }
+    integer, fraction, isFraction := strings.Cut(repr, ".")
+
+    integer = strings.TrimLeft(integer, "0")
+    nt, v := t.NumericValue()
+    if v == 0 { // e.g. "0.0"
+        isFraction = false
+        nt = token.NumberTypeInteger
+    }
+    if isFraction {
+        fraction = strings.TrimRight(fraction, "0")
+        if fraction == "" { fraction = "0" } // still not an integer e.g. "1.0"
+        if integer == "" {
+            repr = "." + fraction
+        } else {
+            repr = integer + "." + fraction
+        }
+    } else {
+        if integer == "" { integer = "0" }
+        repr = integer
+    }
+    repr = sign + repr
+
+    switch t.Type() {
+        case token.TypeNumber:
+            return token.Number(nt, repr, v).WithPosition(t.Position())
+        case token.TypePercentage:
+            return token.Percentage(nt, repr, v).WithPosition(t.Position())
+        default:
+            return token.Dimension(nt, repr, v, t.Unit()).WithPosition(t.Position())
+    }
+}
+
+// trimZeroLength converts a zero length, such as "0px", to the number "0".
+func trimZeroLength(t token.Token) token.Token {
+    if !t.Is(token.TypeDimension) { return t }
+    _, v := t.NumericValue()
+    if v != 0 { return t }
+    if value.Dimension(v, t.Unit()).Type() != value.TypeLength { return t }
+    return token.Number(token.NumberTypeInteger, "0", 0).WithPosition(t.Position())
+}
+
+// shortenColor converts a hexadecimal colour such as "#AABBCC" to the
+// shorter form "#abc", or otherwise to lower case.
+func shortenColor(t token.Token) token.Token {
+    s := t.StringValue()
+    for _, x := range s {
+        if !(((x >= '0') && (x <= '9')) || ((x >= 'a') && (x <= 'f')) || ((x >= 'A') && (x <= 'F'))) {
+            return t
+        }
+    }
+
+    s = strings.ToLower(s)
+    switch len(s) {
+        case 6: fallthrough
+        case 8:
+            short := make([]byte, 0, 4)
+            for i := 0; i < len(s); i += 2 {
+                if s[i] != s[i+1] {
+                    short = nil
+                    break
+                }
+                short = append(short, s[i])
+            }
+            if short != nil { s = string(short) }
+        case 3: fallthrough
+        case 4:
+        default:
+            return t
+    }
+    hashType := token.HashTypeID
+    if (s[0] >= '0') && (s[0] <= '9') { hashType = token.HashTypeUnrestricted }
+    return token.Hash(hashType, s).WithPosition(t.Position())
+}
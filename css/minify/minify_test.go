@@ -0,0 +1,118 @@
+package minify_test
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/minify"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+)
+
+func ExampleString() {
+    s, err := minify.String(`
+        /* a comment */
+        a:hover > .b,
+        #ABCDEF {
+            color: #AABBCC;
+            margin: 0px 0.50em;
+            width: calc(100% - 0px);
+        }
+    `)
+    if err != nil { panic(err) }
+    fmt.Println(s)
+
+    // Output:
+    // a:hover>.b,#ABCDEF{color:#abc;margin:0 .5em;width:calc(100% - 0px)}
+}
+
+func TestString(t *testing.T) {
+    type row struct {
+        input string
+        expected string
+    }
+    rows := []row{
+        {"",                                  ""},
+        {"  a  {  }  ",                        "a{}"},
+        {"a b { color : red ; }",              "a b{color:red}"},
+        {"a  ~  b , c + d > e { }",            "a~b,c+d>e{}"},
+        {"a :hover { }",                       "a :hover{}"},
+        {"a * b { }",                          "a * b{}"},
+        {"a{;;color:red;;margin:0;;}",         "a{color:red;margin:0}"},
+        {"a { color: red !important }",        "a{color:red!important}"},
+        {"a { margin: 0px 0em 0% 0deg }",      "a{margin:0 0 0% 0deg}"},
+        {"a { transition: 0s }",               "a{transition:0s}"},
+        {"a { flex: 1 1 0px }",                "a{flex:1 1 0px}"},
+        {"a { -webkit-flex: 1 1 0px }",        "a{-webkit-flex:1 1 0px}"},
+        {"a { --x: 0px; --c: #AABBCC }",       "a{--x:0px;--c:#AABBCC}"},
+        {"a { --n: 0.50 }",                    "a{--n:0.50}"},
+        {"a { width: calc(0px + 1em) }",       "a{width:calc(0px + 1em)}"},
+        {"a { width: calc( 1px + 2px ) }",     "a{width:calc(1px + 2px)}"},
+        {"a { opacity: 0.50; z-index: 007 }",  "a{opacity:.5;z-index:7}"},
+        {"a { x: -0.5 +0.5 1.0 1e3 }",         "a{x:-.5 +.5 1.0 1e3}"},
+        {"a { x: 0.0 00.00% 0.0em }",          "a{x:0 0% 0}"},
+        {"@import url(x) screen;",             "@import url(x) screen;"},
+        {"@import url(x); a { }",              "@import url(x);a{}"},
+        {"a { color: #FFFFFF }",               "a{color:#fff}"},
+        {"a { color: #11223344 }",             "a{color:#1234}"},
+        {"a { color: #123456 }",               "a{color:#123456}"},
+        {"a { color: #ABC }",                  "a{color:#abc}"},
+        {"a { x: #notacolor }",                "a{x:#notacolor}"},
+        {"#AABBCC { }",                        "#AABBCC{}"},
+        {"@media screen and (min-width : 100px) { a { margin: 0PX } }",
+            "@media screen and (min-width:100px){a{margin:0}}"},
+        {"@media (color) and (hover) { }",     "@media (color) and (hover){}"},
+        {"a { b:hover { margin: 0px } }",      "a{b:hover{margin:0}}"},
+        {"a { font: 12px / 1.5 serif }",       "a{font:12px / 1.5 serif}"},
+        {`a { content: "a  b" }`,              `a{content:"a  b"}`},
+        {"a/**/b { }",                         "a/**/b{}"},
+    }
+    for _, r := range rows {
+        s, err := minify.String(r.input)
+        if err != nil {
+            t.Errorf("%q: unexpected error %v", r.input, err)
+        }
+        if s != r.expected {
+            t.Errorf("%q: expected %q but got %q", r.input, r.expected, s)
+        }
+    }
+}
+
+func tokens(s string) []token.Token {
+    var result []token.Token
+    k := tokenizer.New(strings.NewReader(s))
+    for {
+        t := k.Next()
+        if t.Is(token.TypeEOF) { break }
+        result = append(result, t)
+    }
+    return result
+}
+
+// TestRoundTrip tests that the minified output, when tokenized again,
+// produces exactly the tokens returned by the minifier.
+func TestRoundTrip(t *testing.T) {
+    inputs := []string{
+        "a:hover > .b, #c { color: #AABBCC; margin: 0px .50em }",
+        "@media (400px <= width <= 700px) { a { width: calc(100% - 2 * 1em) } }",
+        "a:nth-child(2n + 1) { grid-area: 1 / 2 / -1 / -2 }",
+        "a { b: 1e3 c -- > d url( x ) u+0-7F \\ }",
+        "@charset \"x\"; a { x: 0.0 0.0em }",
+    }
+    for _, input := range inputs {
+        expected := minify.Tokens(tokens(input))
+        s, _ := minify.String(input)
+        got := tokens(s)
+
+        if len(got) != len(expected) {
+            t.Errorf("%q: expected %d tokens but got %d (%q)", input, len(expected), len(got), s)
+            continue
+        }
+        for i := range got {
+            if !token.Equals(got[i], expected[i]) {
+                t.Errorf("%q: token %d: expected %v but got %v", input, i, expected[i], got[i])
+            }
+        }
+    }
+}
@@ -0,0 +1,427 @@
+// Package nesting flattens nested CSS style rules, as described by the
+// [CSS Nesting Module] (W3C Working Draft), 14 February 2023, into equivalent
+// rules that do not use nesting, for user agents that do not support it.
+//
+// For example,
+//
+//     .a {
+//         color: red;
+//         & > .b { color: blue; }
+//         .c & { color: green; }
+//         @media print { color: black; }
+//     }
+//
+// is flattened into
+//
+//     .a { color: red; }
+//     .a > .b { color: blue; }
+//     .c :is(.a) { color: green; }
+//     @media print { .a { color: black; } }
+//
+// The rules are parsed by [css/parser], which parses nested style rules in
+// the contents of a style rule's block (see
+// [parser.Parser.ConsumeStyleBlockContents]), and their selectors by
+// [css/selector].
+//
+// As required by the specification, the nesting selector "&" is replaced by
+// the parent rule's selector list wrapped in ":is()", and a nested selector
+// that does not contain "&" is relative to the parent, as if it started
+// with "& ". Where this can be done without changing the meaning (or the
+// specificity) of the selector, the parent's selector is instead written
+// directly: for example, "& > .b" becomes ".a > .b", instead of
+// ":is(.a) > .b".
+//
+// Note that, as in the specification, declarations that follow a nested
+// rule are treated as if they came before it.
+//
+// [CSS Nesting Module]: https://www.w3.org/TR/css-nesting-1/
+//
+// This software includes material derived from CSS Nesting Module, W3C
+// Working Draft, 14 February 2023. Copyright © 2023 W3C® (MIT, ERCIM, Keio,
+// Beihang). See LICENSE-PARTS.txt and TRADEMARKS.md.
+package nesting
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/parser"
+    "github.com/tawesoft/golib/v2/css/parser/item"
+    "github.com/tawesoft/golib/v2/css/selector"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+)
+
+var (
+    ErrSelector = fmt.Errorf("invalid selector")
+    ErrAtRule   = fmt.Errorf("at-rule not allowed in a style rule")
+)
+
+type errRule struct {
+    err error
+    at token.Position
+    detail error
+}
+
+func (e errRule) Unwrap() error {
+    return e.err
+}
+
+func (e errRule) Error() string {
+    if e.detail == nil { return fmt.Sprintf("error at %s: %s", e.at, e.err) }
+    return fmt.Sprintf("error at %s: %s: %s", e.at, e.err, e.detail)
+}
+
+// groupRules are the at-rules that may be nested in a style rule, in which
+// case their contents are also nested in that style rule.
+var groupRules = []string{"media", "supports", "container", "layer", "scope", "starting-style"}
+
+func isGroupRule(name string) bool {
+    name = strings.ToLower(name)
+    for _, x := range groupRules {
+        if name == x { return true }
+    }
+    return false
+}
+
+// Flatten returns the rules of a stylesheet, such as those returned by
+// [parser.Parser.ParseStylesheet], with any nested style rules flattened.
+//
+// A nested style rule that has an invalid selector, and an at-rule nested
+// in a style rule that is not a conditional group rule such as @media, is
+// removed, as it would be ignored by a user agent, and an error is returned
+// for each. A style rule at the top level with an invalid selector is
+// returned unchanged, with an error.
+func Flatten(rules []item.Item) ([]item.Item, []error) {
+    var f flattener
+    return f.rules(rules, nil), f.errors
+}
+
+// Write reads a stylesheet from r, and writes the flattened stylesheet to
+// w. Any parse errors, or errors returned by [Flatten], are joined and
+// returned, but the stylesheet is still written.
+func Write(w io.Writer, r io.Reader) error {
+    p := parser.New(r)
+    rules, errs := Flatten(p.ParseStylesheet())
+    errs = append(append([]error(nil), p.Errors()...), errs...)
+
+    s := tokenizer.NewSerializer(w)
+    for _, rule := range rules {
+        for _, t := range item.Tokens(ruleValues(rule)) {
+            if err := s.Write(t); err != nil { return err }
+        }
+    }
+    if err := s.Flush(); err != nil { return err }
+
+    return errors.Join(errs...)
+}
+
+// String returns a stylesheet flattened. See [Write].
+func String(s string) (string, error) {
+    var sb strings.Builder
+    err := Write(&sb, strings.NewReader(s))
+    return sb.String(), err
+}
+
+type flattener struct {
+    errors []error
+}
+
+func (f *flattener) error(err error, at []item.ComponentValue, detail error) {
+    var pos token.Position
+    if tokens := item.Tokens(at); len(tokens) > 0 { pos = tokens[0].Position() }
+    f.errors = append(f.errors, errRule{err, pos, detail})
+}
+
+// rules flattens a list of rules, which are nested in a style rule with the
+// given selector list, or at the top level if parent is nil.
+func (f *flattener) rules(rules []item.Item, parent selector.List) []item.Item {
+    var result []item.Item
+    for _, rule := range rules {
+        switch rule.Type() {
+            case item.TypeQualifiedRule:
+                result = append(result, f.styleRule(rule.ToQualifiedRule(), parent)...)
+            case item.TypeAtRule:
+                result = append(result, f.atRule(rule.ToAtRule(), parent)...)
+            default:
+                result = append(result, rule)
+        }
+    }
+    return result
+}
+
+// styleRule flattens a style rule.
+func (f *flattener) styleRule(rule item.QualifiedRule, parent selector.List) []item.Item {
+    var sel selector.List
+    var err error
+    if parent == nil {
+        sel, err = selector.Parse(item.Tokens(rule.Prelude))
+        if err != nil {
+            f.error(ErrSelector, rule.Prelude, err)
+            return []item.Item{rule.ToItem()}
+        }
+    } else {
+        sel, err = selector.ParseRelative(item.Tokens(rule.Prelude))
+        if err != nil {
+            f.error(ErrSelector, rule.Prelude, err)
+            return nil
+        }
+        sel = resolve(sel, parent)
+    }
+
+    contents := parser.NewFromComponentValues(rule.Block.Value).ParseStyleBlockContents()
+    return f.contents(contents, sel, parent == nil)
+}
+
+// contents flattens the contents of a style rule's block, for a rule with
+// the given selector list, returning the rule containing the declarations,
+// followed by any flattened nested rules. If keepEmpty is true, the rule is
+// returned even if it has no declarations, but only if it also has no nested
+// rules.
+func (f *flattener) contents(contents []item.Item, sel selector.List, keepEmpty bool) []item.Item {
+    var decls, nested []item.Item
+    for _, x := range contents {
+        if x.Is(item.TypeDeclaration) {
+            decls = append(decls, x)
+        } else {
+            nested = append(nested, x)
+        }
+    }
+
+    var result []item.Item
+    if (len(decls) > 0) || (keepEmpty && (len(nested) == 0)) {
+        result = append(result, item.QualifiedRule{
+            Prelude: selectorValues(sel),
+            Block:   declarationBlock(decls),
+        }.ToItem())
+    }
+    return append(result, f.rules(nested, sel)...)
+}
+
+// atRule flattens the contents of an at-rule.
+func (f *flattener) atRule(rule item.AtRule, parent selector.List) []item.Item {
+    block, hasBlock := rule.Block.Unpack()
+
+    if !isGroupRule(rule.Name) || !hasBlock {
+        if parent != nil {
+            f.error(ErrAtRule, rule.Prelude, fmt.Errorf("@%s", rule.Name))
+            return nil
+        }
+        return []item.Item{rule.ToItem()}
+    }
+
+    var contents []item.Item
+    p := parser.NewFromComponentValues(block.Value)
+    if parent == nil {
+        contents = f.rules(p.ParseRuleList(), nil)
+    } else {
+        contents = f.contents(p.ParseStyleBlockContents(), parent, false)
+    }
+
+    var values []item.ComponentValue
+    for _, x := range contents {
+        values = append(values, ruleValues(x)...)
+    }
+    block.Value = values
+    rule.Block.Value = block
+    return []item.Item{rule.ToItem()}
+}
+
+// resolve returns the selector list of a nested style rule, given the
+// relative selector list of the nested rule, and the selector list of its
+// parent.
+func resolve(nested selector.List, parent selector.List) selector.List {
+    result := make(selector.List, 0, len(nested))
+    for _, c := range nested {
+        // A relative selector that does not start with "&" is relative to
+        // the parent e.g. "> b" is "& > b", and "b" is "& b".
+        if (c[0].Combinator != selector.CombinatorNone) || !containsNesting(c) {
+            combinator := c[0].Combinator
+            if combinator == selector.CombinatorNone {
+                combinator = selector.CombinatorDescendant
+            }
+            rest := append(selector.Complex(nil), c...)
+            rest[0].Combinator = combinator
+            c = append(selector.Complex{{
+                Simples: []selector.Simple{{Kind: selector.KindNesting}},
+            }}, rest...)
+        }
+
+        if x, ok := inline(c, parent); ok {
+            result = append(result, x)
+        } else {
+            result = append(result, replace(c, parent))
+        }
+    }
+    return result
+}
+
+// containsNesting returns true if a complex selector contains a nesting
+// selector, including in the arguments of a functional pseudo-class.
+func containsNesting(c selector.Complex) bool {
+    for _, compound := range c {
+        for _, s := range compound.Simples {
+            if hasNesting(s) { return true }
+        }
+    }
+    return false
+}
+
+// hasNesting returns true if a simple selector is, or contains, a nesting
+// selector.
+func hasNesting(s selector.Simple) bool {
+    if s.Kind == selector.KindNesting { return true }
+    for _, x := range s.Selectors {
+        if containsNesting(x) { return true }
+    }
+    return false
+}
+
+// inline returns a complex selector with the nesting selector replaced by
+// the parent selector directly, instead of with :is(), if this has the same
+// meaning. This is the case if the parent is a single complex selector, the
+// nesting selector appears only in the first compound selector, and that
+// compound selector can be merged with the last compound selector of the
+// parent.
+func inline(c selector.Complex, parent selector.List) (selector.Complex, bool) {
+    if (len(parent) != 1) || containsNesting(c[1:]) { return nil, false }
+
+    var simples []selector.Simple
+    for _, s := range c[0].Simples {
+        switch {
+            case s.Kind == selector.KindNesting:
+                continue
+            case (s.Kind == selector.KindType) || (s.Kind == selector.KindUniversal):
+                // must be first in a compound selector
+                return nil, false
+            case hasNesting(s):
+                return nil, false
+        }
+        simples = append(simples, s)
+    }
+
+    p := parent[0]
+    last := p[len(p) - 1]
+    for _, s := range last.Simples {
+        // a pseudo-element must be last in a compound selector
+        if (s.Kind == selector.KindPseudoElement) && (len(simples) > 0) { return nil, false }
+    }
+
+    result := append(selector.Complex(nil), p...)
+    result[len(result) - 1] = selector.Compound{
+        Combinator: last.Combinator,
+        Simples:    append(append([]selector.Simple(nil), last.Simples...), simples...),
+    }
+    return append(result, c[1:]...), true
+}
+
+// replace returns a complex selector with each nesting selector replaced by
+// ":is(parent)".
+func replace(c selector.Complex, parent selector.List) selector.Complex {
+    is := selector.Simple{
+        Kind:      selector.KindPseudoClass,
+        Name:      "is",
+        Function:  true,
+        Args:      tokens(parent.String()),
+        Selectors: parent,
+    }
+
+    result := make(selector.Complex, len(c))
+    for i, compound := range c {
+        simples := make([]selector.Simple, len(compound.Simples))
+        for j, s := range compound.Simples {
+            switch {
+                case s.Kind == selector.KindNesting:
+                    s = is
+                case len(s.Selectors) > 0:
+                    selectors := make(selector.List, len(s.Selectors))
+                    changed := false
+                    for k, x := range s.Selectors {
+                        if containsNesting(x) {
+                            x, changed = replace(x, parent), true
+                        }
+                        selectors[k] = x
+                    }
+                    if changed {
+                        s.Selectors = selectors
+                        s.Args = replaceArgs(s.Args, selectors)
+                    }
+            }
+            simples[j] = s
+        }
+        result[i] = selector.Compound{Combinator: compound.Combinator, Simples: simples}
+    }
+    return result
+}
+
+// replaceArgs returns the arguments of a functional pseudo-class with the
+// selector list replaced. For :nth-child() and :nth-last-child(), this is
+// the selector list after the keyword "of".
+func replaceArgs(args []token.Token, selectors selector.List) []token.Token {
+    for i, t := range args {
+        if t.Is(token.TypeIdent) && strings.EqualFold(t.StringValue(), "of") {
+            result := append([]token.Token(nil), args[:i + 1]...)
+            result = append(result, token.Whitespace())
+            return append(result, tokens(selectors.String())...)
+        }
+    }
+    return tokens(selectors.String())
+}
+
+// tokens tokenizes a string.
+func tokens(s string) []token.Token {
+    var result []token.Token
+    z := tokenizer.New(strings.NewReader(s))
+    for {
+        t := z.Next()
+        if t.Is(token.TypeEOF) { break }
+        result = append(result, t)
+    }
+    return result
+}
+
+// selectorValues returns a selector list as component values.
+func selectorValues(l selector.List) []item.ComponentValue {
+    return parser.New(strings.NewReader(l.String())).ParseComponentValueList()
+}
+
+// declarationBlock returns a {}-block containing declarations.
+func declarationBlock(decls []item.Item) item.Block {
+    var values []item.ComponentValue
+    add := func(t token.Token) {
+        values = append(values, item.PreservedToken(t).ToComponentValue())
+    }
+    for i, x := range decls {
+        d := x.ToDeclaration()
+        if i > 0 { add(token.Semicolon()) }
+        add(token.Ident(d.Name))
+        add(token.Colon())
+        values = append(values, d.Value...)
+        if d.Important {
+            add(token.Delim('!'))
+            add(token.Ident("important"))
+        }
+    }
+    return item.Block{Delim: token.LeftCurlyBracket(), Value: values}
+}
+
+// ruleValues returns a rule as component values.
+func ruleValues(rule item.Item) []item.ComponentValue {
+    switch rule.Type() {
+        case item.TypeQualifiedRule:
+            qr := rule.ToQualifiedRule()
+            return append(append([]item.ComponentValue(nil), qr.Prelude...), qr.Block.ToComponentValue())
+        case item.TypeAtRule:
+            ar := rule.ToAtRule()
+            result := []item.ComponentValue{item.PreservedToken(token.AtKeyword(ar.Name)).ToComponentValue()}
+            result = append(result, ar.Prelude...)
+            if block, ok := ar.Block.Unpack(); ok {
+                return append(result, block.ToComponentValue())
+            }
+            return append(result, item.PreservedToken(token.Semicolon()).ToComponentValue())
+        default:
+            return nil
+    }
+}
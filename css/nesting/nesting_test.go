@@ -0,0 +1,74 @@
+package nesting_test
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/nesting"
+)
+
+func ExampleString() {
+    s, err := nesting.String(`
+        .a {
+            color: red;
+            & > .b { color: blue; }
+            .c & { color: green; }
+            @media print { color: black; }
+        }
+    `)
+    if err != nil { panic(err) }
+    fmt.Println(s)
+
+    // Output:
+    // .a{color:red}.a > .b{color:blue}.c :is(.a){color:green}@media print {.a{color:black}}
+}
+
+func TestString(t *testing.T) {
+    type row struct {
+        input string
+        expected string
+        err error
+    }
+    rows := []row{
+        {"",                                   "",                                      nil},
+        {"a { color: red }",                   "a{color:red}",                          nil},
+        {"a { }",                              "a{}",                                   nil},
+        {"a { b { x: y } }",                   "a b{x:y}",                              nil},
+        {"a { > b { x: y } }",                 "a > b{x:y}",                            nil},
+        {"a { + b, ~ c { x: y } }",            "a + b, a ~ c{x:y}",                     nil},
+        {"a { &.b { x: y } }",                 "a.b{x:y}",                              nil},
+        {"a { &:hover { x: y } }",             "a:hover{x:y}",                          nil},
+        {"a { :hover { x: y } }",              "a :hover{x:y}",                         nil},
+        {"a b { &.c { x: y } }",               "a b.c{x:y}",                            nil},
+        {"a, b { &.c { x: y } }",              ":is(a, b).c{x:y}",                      nil},
+        {"a, b { c { x: y } }",                ":is(a, b) c{x:y}",                      nil},
+        {".a { div& { x: y } }",               "div:is(.a){x:y}",                       nil},
+        {".a { & & { x: y } }",                ":is(.a) :is(.a){x:y}",                  nil},
+        {".a { :not(&) { x: y } }",            ":not(:is(.a)){x:y}",                    nil},
+        {".a { &:not(&) { x: y } }",           ":is(.a):not(:is(.a)){x:y}",             nil},
+        {"a::before { &:hover { x: y } }",     ":is(a::before):hover{x:y}",             nil},
+        {"a { b { c { x: y } } }",             "a b c{x:y}",                            nil},
+        {"a { x: 1; b { x: 2 } x: 3 }",        "a{x:1;x:3}a b{x:2}",                    nil},
+        {"a { x: y !important; b:hover { } }", "a{x:y!important}",                      nil},
+        {"a { --x: { b } }",                   "a{--x:{ b }}",                          nil},
+        {"a { @media print { b { x: y } } }",  "@media print {a b{x:y}}",               nil},
+        {"a { @media print { x: y; b { x: z } } }", "@media print {a{x:y}a b{x:z}}",    nil},
+        {"@media print { a { b { x: y } } }",  "@media print {a b{x:y}}",               nil},
+        {"@import 'x'; a { }",                 `@import "x";a{}`,                       nil},
+        {"a { @font-face { } x: y }",          "a{x:y}",                                nesting.ErrAtRule},
+        {"a { b ! { x: y } c { x: z } }",      "a c{x:z}",                              nesting.ErrSelector},
+        {"a ! { b { x: y } }",                 "a ! { b { x: y } }",                    nesting.ErrSelector},
+    }
+    for _, r := range rows {
+        s, err := nesting.String(r.input)
+        if r.err == nil && err != nil {
+            t.Errorf("%q: unexpected error %v", r.input, err)
+        } else if !errors.Is(err, r.err) {
+            t.Errorf("%q: expected error %v but got %v", r.input, r.err, err)
+        }
+        if s != r.expected {
+            t.Errorf("%q: expected %q but got %q", r.input, r.expected, s)
+        }
+    }
+}
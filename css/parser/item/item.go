@@ -1,4 +1,9 @@
 // Package item defines CSS items in the tree produced by a parser.
+//
+// Each item is one of an [AtRule], [QualifiedRule], [Declaration],
+// [ComponentValue], [PreservedToken], [Function], or [Block]. These may be
+// converted to and from the general [Item] type, which is used where a list
+// may contain items of more than one type, such as a list of rules.
 package item
 
 import (
@@ -35,11 +40,21 @@ type Item struct {
     token token.Token
 }
 
+// Type returns the type of an item.
+func (i Item) Type() Type {
+    return i._type
+}
+
+// Is returns true if the item is of the given type.
+func (i Item) Is(t Type) bool {
+    return i._type == t
+}
+
 func (i Item) String() string {
     switch i._type {
         case TypeAtRule: return i.ToAtRule().String()
-        case TypeQualifiedRule: return "<QualifiedRule(TODO)>"
-        case TypeDeclaration: return "<Declaration(TODO)>"
+        case TypeQualifiedRule: return i.ToQualifiedRule().String()
+        case TypeDeclaration: return i.ToDeclaration().String()
         case TypeComponentValue: return i.ToComponentValue().String()
         case TypePreservedToken: return i.token.String()
         case TypeFunction:
@@ -123,6 +138,11 @@ func (i QualifiedRule) ToItem() Item {
     }
 }
 
+func (i QualifiedRule) String() string {
+    return fmt.Sprintf("<QualifiedRule{Prelude: %v, Block: %s}>",
+        i.Prelude, i.Block.ToItem().String())
+}
+
 // ComponentValue describes an Item that is either one of the preserved tokens,
 // a function, or a simple block.
 type ComponentValue struct {
@@ -156,6 +176,12 @@ func (i ComponentValue) ToItem() Item {
     }
 }
 
+// Type returns the type of a component value: one of TypePreservedToken,
+// TypeFunction, or TypeBlock.
+func (i ComponentValue) Type() Type {
+    return i._type
+}
+
 func (i ComponentValue) ToPreservedToken() PreservedToken {
     must.Equal(i._type, TypePreservedToken)
     return i.token
@@ -225,6 +251,11 @@ func (i Declaration) ToItem() Item {
     }
 }
 
+func (i Declaration) String() string {
+    return fmt.Sprintf("<Declaration{Name: %q, Value: %v, Important: %t}>",
+        i.Name, i.Value, i.Important)
+}
+
 // PreservedToken describes an Item that is any token produced by the tokenizer
 // except for <function-token>, <{-token>, <(-token>, or <[-token>.
 //
@@ -314,3 +345,42 @@ func (i Block) ToComponentValue() ComponentValue {
         block: i,
     }
 }
+
+// Tokens returns a list of component values as a flat list of tokens, such
+// that parsing the tokens as a list of component values would produce the
+// same list of component values again.
+//
+// For example, a function is returned as a <function-token>, followed by the
+// tokens of its value, followed by a <)-token>.
+func Tokens(values []ComponentValue) []token.Token {
+    return appendTokens(nil, values)
+}
+
+func appendTokens(dest []token.Token, values []ComponentValue) []token.Token {
+    for _, v := range values {
+        switch v._type {
+            case TypePreservedToken:
+                dest = append(dest, token.Token(v.token))
+            case TypeFunction:
+                dest = append(dest, token.Function(v.function.Name))
+                dest = appendTokens(dest, v.function.Value)
+                dest = append(dest, token.RightParen())
+            case TypeBlock:
+                dest = append(dest, v.block.Delim)
+                dest = appendTokens(dest, v.block.Value)
+                dest = append(dest, mirror(v.block.Delim))
+        }
+    }
+    return dest
+}
+
+// mirror returns the token that closes a block opened by the given token.
+func mirror(x token.Token) token.Token {
+    switch {
+        case x.Is(token.TypeLeftParen):         return token.RightParen()
+        case x.Is(token.TypeLeftCurlyBracket):  return token.RightCurlyBracket()
+        case x.Is(token.TypeLeftSquareBracket): return token.RightSquareBracket()
+    }
+    must.Neverf("invalid mirror")
+    return token.Token{}
+}
@@ -0,0 +1,692 @@
+// Package parser parses CSS based on part five of the
+// [CSS Syntax Module Level 3] (W3C Candidate Recommendation Draft),
+// 24 December 2021.
+//
+// The parser consumes a stream of tokens (see [css/tokenizer]) and produces a
+// tree of items (see [css/parser/item]): rules, declarations, and component
+// values.
+//
+// The main elements of this package are the [New] function, which returns a
+// new [Parser], and its "Parse" methods, which implement the parser entry
+// points defined in the specification, such as [Parser.ParseStylesheet]. The
+// parser also exposes the lower-level "Consume" methods, which implement
+// specific algorithms in the specification.
+//
+// Note that, like the specification, the parser is generic: it does not know
+// anything about specific at-rules, properties, or values. For example, the
+// block of a qualified rule is returned as a simple block of component
+// values, which may be parsed further with [NewFromComponentValues] and
+// [Parser.ParseStyleBlockContents].
+//
+// [CSS Syntax Module Level 3]: https://www.w3.org/TR/css-syntax-3/
+// [css/tokenizer]: https://pkg.go.dev/github.com/tawesoft/golib/v2/css/tokenizer
+// [css/parser/item]: https://pkg.go.dev/github.com/tawesoft/golib/v2/css/parser/item
+//
+// This software includes material derived from CSS Syntax Module Level 3,
+// W3C Candidate Recommendation Draft, 24 December 2021. Copyright © 2021 W3C®
+// (MIT, ERCIM, Keio, Beihang). See LICENSE-PARTS.txt and TRADEMARKS.md.
+package parser
+
+import (
+    "fmt"
+    "io"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/parser/item"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+)
+
+var (
+    ErrUnexpectedEOF = fmt.Errorf("unexpected end of file")
+    ErrInvalidDeclaration = fmt.Errorf("invalid declaration")
+    ErrUnexpectedToken = fmt.Errorf("unexpected token")
+    ErrSyntax = fmt.Errorf("syntax error")
+)
+
+// Stream is a stream of tokens with a pushback buffer, such as a
+// [tokenizer.Tokenizer]. Once the stream has ended, Next must return
+// token.EOF().
+type Stream interface {
+    Next() token.Token
+    Push(x token.Token)
+}
+
+type Parser struct {
+    t *tokenizer.Tokenizer
+    s Stream
+    errors []error
+}
+
+// Tokenizer returns the tokenizer that the parser reads from, or nil if the
+// parser was created with [NewFromComponentValues].
+func (p *Parser) Tokenizer() *tokenizer.Tokenizer {
+    return p.t
+}
+
+// New returns a new [Parser] that parses CSS from r.
+func New(r io.Reader) *Parser {
+    t := tokenizer.New(r)
+    return &Parser{
+        t: t,
+        s: t,
+    }
+}
+
+// NewFromComponentValues returns a new [Parser] that parses a list of
+// component values, for example the value of a block.
+func NewFromComponentValues(values []item.ComponentValue) *Parser {
+    return &Parser{
+        s: &tokenList{tokens: item.Tokens(values)},
+    }
+}
+
+// NewFromStream returns a new [Parser] that parses a stream of tokens.
+func NewFromStream(s Stream) *Parser {
+    return &Parser{
+        s: s,
+    }
+}
+
+// Errors reports parse errors. These include any errors reported by the
+// tokenizer (see [tokenizer.Tokenizer.Errors]), followed by any errors
+// reported by the parser.
+//
+// Parse errors are not fatal: like a web browser, the parser recovers from
+// them, for example by ignoring an invalid declaration.
+func (p *Parser) Errors() []error {
+    if (p.t == nil) || (len(p.t.Errors()) == 0) { return p.errors }
+    return append(append([]error(nil), p.t.Errors()...), p.errors...)
+}
+
+type parseError struct {
+    err error
+    at token.Position
+}
+
+func (e parseError) Error() string {
+    return fmt.Sprintf("parse error at %+v: %s", e.at, e.err)
+}
+
+func (e parseError) Unwrap() error {
+    return e.err
+}
+
+func (p *Parser) error(err error, at token.Token) {
+    p.errors = append(p.errors, parseError{
+        err: err,
+        at:  at.Position(),
+    })
+}
+
+// skipWhitespace consumes any whitespace tokens.
+func skipWhitespace(k Stream) {
+    for {
+        t := k.Next()
+        if !t.Is(token.TypeWhitespace) {
+            k.Push(t)
+            return
+        }
+    }
+}
+
+// ParseStylesheet parses a stylesheet, returning a list of rules, each of
+// which is an [item.AtRule] or an [item.QualifiedRule]. This is the normal
+// entry point for parsing a CSS file.
+func (p *Parser) ParseStylesheet() []item.Item {
+    return p.ConsumeListOfRules(p.s, true)
+}
+
+// ParseRuleList parses a list of rules, for example the contents of an
+// at-rule block such as @media.
+func (p *Parser) ParseRuleList() []item.Item {
+    return p.ConsumeListOfRules(p.s, false)
+}
+
+// ParseRule parses a single rule, which is an [item.AtRule] or an
+// [item.QualifiedRule], surrounded by optional whitespace. Otherwise, it
+// returns an error wrapping ErrSyntax.
+func (p *Parser) ParseRule() (item.Item, error) {
+    skipWhitespace(p.s)
+
+    var rule item.Item
+    t := p.s.Next()
+    switch {
+        case t.Is(token.TypeEOF):
+            return item.Item{}, parseError{ErrSyntax, t.Position()}
+        case t.Is(token.TypeAtKeyword):
+            p.s.Push(t)
+            rule = p.ConsumeAtRule(p.s).ToItem()
+        default:
+            p.s.Push(t)
+            qr, ok := p.ConsumeQualifiedRule(p.s)
+            if !ok { return item.Item{}, parseError{ErrSyntax, t.Position()} }
+            rule = qr.ToItem()
+    }
+
+    skipWhitespace(p.s)
+    if t := p.s.Next(); !t.Is(token.TypeEOF) {
+        return item.Item{}, parseError{ErrSyntax, t.Position()}
+    }
+    return rule, nil
+}
+
+// ParseDeclaration parses a single declaration, surrounded by optional
+// whitespace, for example "color: red !important". Otherwise, it returns an
+// error wrapping ErrSyntax.
+func (p *Parser) ParseDeclaration() (item.Declaration, error) {
+    skipWhitespace(p.s)
+    t := p.s.Next()
+    if !t.Is(token.TypeIdent) {
+        return item.Declaration{}, parseError{ErrSyntax, t.Position()}
+    }
+    p.s.Push(t)
+
+    values := p.ParseComponentValueList()
+    decl, ok := p.ConsumeDeclaration(values)
+    if !ok { return item.Declaration{}, parseError{ErrSyntax, t.Position()} }
+    return decl, nil
+}
+
+// ParseStyleBlockContents parses the contents of a style rule's block,
+// returning a list of [item.Declaration], followed by a list of rules (see
+// [Parser.ConsumeStyleBlockContents]).
+func (p *Parser) ParseStyleBlockContents() []item.Item {
+    return p.ConsumeStyleBlockContents(p.s)
+}
+
+// ParseDeclarationList parses a list of declarations and at-rules, for
+// example the contents of the block of a @page rule.
+func (p *Parser) ParseDeclarationList() []item.Item {
+    return p.ConsumeListOfDeclarations(p.s)
+}
+
+// ParseComponentValue parses a single component value, surrounded by
+// optional whitespace. Otherwise, it returns an error wrapping ErrSyntax.
+func (p *Parser) ParseComponentValue() (item.ComponentValue, error) {
+    skipWhitespace(p.s)
+    t := p.s.Next()
+    if t.Is(token.TypeEOF) {
+        return item.ComponentValue{}, parseError{ErrSyntax, t.Position()}
+    }
+    p.s.Push(t)
+
+    cv := p.ConsumeComponentValue(p.s)
+    skipWhitespace(p.s)
+    if t := p.s.Next(); !t.Is(token.TypeEOF) {
+        return item.ComponentValue{}, parseError{ErrSyntax, t.Position()}
+    }
+    return cv, nil
+}
+
+// ParseComponentValueList parses a list of component values, until the end
+// of the input.
+func (p *Parser) ParseComponentValueList() []item.ComponentValue {
+    var values []item.ComponentValue
+    for {
+        t := p.s.Next()
+        if t.Is(token.TypeEOF) { return values }
+        p.s.Push(t)
+        values = append(values, p.ConsumeComponentValue(p.s))
+    }
+}
+
+// ParseCommaSeparatedComponentValues parses a list of lists of component
+// values, separated by commas, until the end of the input. For example,
+// "a b, c" is parsed as [[a, whitespace, b], [whitespace, c]].
+func (p *Parser) ParseCommaSeparatedComponentValues() [][]item.ComponentValue {
+    var lists [][]item.ComponentValue
+    for {
+        var values []item.ComponentValue
+        for {
+            t := p.s.Next()
+            if t.Is(token.TypeEOF) {
+                return append(lists, values)
+            } else if t.Is(token.TypeComma) {
+                break
+            }
+            p.s.Push(t)
+            values = append(values, p.ConsumeComponentValue(p.s))
+        }
+        lists = append(lists, values)
+    }
+}
+
+// ConsumeListOfRules consumes a list of rules. The topLevel flag is set for a
+// stylesheet, where CDO and CDC tokens (i.e. "<!--" and "-->") are ignored.
+func (p *Parser) ConsumeListOfRules(k Stream, topLevel bool) []item.Item {
+    // Create an initially empty list of rules.
+    var rules []item.Item
+
+    // Repeatedly consume the next input token:
+    for {
+        t := k.Next()
+        switch {
+            case t.Is(token.TypeWhitespace):
+                // Do nothing.
+            case t.Is(token.TypeEOF):
+                // Return the list of rules.
+                return rules
+            case t.Is(token.TypeCDO), t.Is(token.TypeCDC):
+                // If the top-level flag is set, do nothing.
+                if topLevel { continue }
+                // Otherwise, reconsume the current input token. Consume a
+                // qualified rule. If anything is returned, append it to the
+                // list of rules.
+                k.Push(t)
+                if qr, ok := p.ConsumeQualifiedRule(k); ok {
+                    rules = append(rules, qr.ToItem())
+                }
+            case t.Is(token.TypeAtKeyword):
+                // Reconsume the current input token. Consume an at-rule, and
+                // append the returned value to the list of rules.
+                k.Push(t)
+                rules = append(rules, p.ConsumeAtRule(k).ToItem())
+            default:
+                // Reconsume the current input token. Consume a qualified
+                // rule. If anything is returned, append it to the list of
+                // rules.
+                k.Push(t)
+                if qr, ok := p.ConsumeQualifiedRule(k); ok {
+                    rules = append(rules, qr.ToItem())
+                }
+        }
+    }
+}
+
+// ConsumeAtRule consumes an at-rule. Note that this algorithm assumes that
+// the next input token has already been checked to be an
+// <at-keyword-token>.
+func (p *Parser) ConsumeAtRule(k Stream) item.AtRule {
+    // Consume the next input token. Create a new at-rule with its name set
+    // to the value of the current input token, its prelude initially set to
+    // an empty list, and its value initially set to nothing.
+    t := k.Next()
+    rule := item.AtRule{
+        Name: t.StringValue(),
+    }
+
+    // Repeatedly consume the next input token:
+    for {
+        t := k.Next()
+        switch {
+            case t.Is(token.TypeSemicolon):
+                // Return the at-rule.
+                return rule
+            case t.Is(token.TypeEOF):
+                // This is a parse error. Return the at-rule.
+                p.error(ErrUnexpectedEOF, t)
+                return rule
+            case t.Is(token.TypeLeftCurlyBracket):
+                // Consume a simple block and assign it to the at-rule’s
+                // block. Return the at-rule.
+                rule.Block.Ok = true
+                rule.Block.Value = p.ConsumeBlock(k, t)
+                return rule
+            default:
+                // Reconsume the current input token. Consume a component
+                // value. Append the returned value to the at-rule’s prelude.
+                k.Push(t)
+                rule.Prelude = append(rule.Prelude, p.ConsumeComponentValue(k))
+        }
+    }
+}
+
+// ConsumeQualifiedRule consumes a qualified rule. It returns false if nothing
+// is returned (if the input ends before the rule's block).
+func (p *Parser) ConsumeQualifiedRule(k Stream) (item.QualifiedRule, bool) {
+    // Create a new qualified rule with its prelude initially set to an empty
+    // list, and its value initially set to nothing.
+    var rule item.QualifiedRule
+
+    // Repeatedly consume the next input token:
+    for {
+        t := k.Next()
+        switch {
+            case t.Is(token.TypeEOF):
+                // This is a parse error. Return nothing.
+                p.error(ErrUnexpectedEOF, t)
+                return item.QualifiedRule{}, false
+            case t.Is(token.TypeLeftCurlyBracket):
+                // Consume a simple block and assign it to the qualified
+                // rule’s block. Return the qualified rule.
+                rule.Block = p.ConsumeBlock(k, t)
+                return rule, true
+            default:
+                // Reconsume the current input token. Consume a component
+                // value. Append the returned value to the qualified rule’s
+                // prelude.
+                k.Push(t)
+                rule.Prelude = append(rule.Prelude, p.ConsumeComponentValue(k))
+        }
+    }
+}
+
+// ConsumeStyleBlockContents consumes the contents of a style rule's block.
+// It returns a list of [item.Declaration], followed by a list of rules: any
+// at-rules, and any nested style rules, as described by the [CSS Nesting
+// Module] (W3C Working Draft), 14 February 2023.
+//
+// A nested style rule is returned as an [item.QualifiedRule]. As in the
+// current draft of the CSS Syntax Module Level 3, something that starts like
+// a declaration, such as "a:hover { ... }", is parsed as a nested style rule
+// instead if it contains a {}-block, unless it is the declaration of a
+// custom property such as "--x: { ... }".
+//
+// [CSS Nesting Module]: https://www.w3.org/TR/css-nesting-1/
+func (p *Parser) ConsumeStyleBlockContents(k Stream) []item.Item {
+    // Create an initially empty list of declarations decls, and an initially
+    // empty list of rules rules.
+    var decls, rules []item.Item
+
+    // Repeatedly consume the next input token:
+    for {
+        t := k.Next()
+        switch {
+            case t.Is(token.TypeWhitespace), t.Is(token.TypeSemicolon):
+                // Do nothing.
+            case t.Is(token.TypeEOF):
+                // Extend decls with rules, then return decls.
+                return append(decls, rules...)
+            case t.Is(token.TypeAtKeyword):
+                // Reconsume the current input token. Consume an at-rule, and
+                // append the result to rules.
+                k.Push(t)
+                rules = append(rules, p.ConsumeAtRule(k).ToItem())
+            case t.Is(token.TypeIdent):
+                // Reconsume the current input token. Consume a declaration or
+                // a nested style rule. If a declaration was returned, append
+                // it to decls. If a rule was returned, append it to rules.
+                k.Push(t)
+                custom := strings.HasPrefix(t.StringValue(), "--")
+                values, isRule := p.consumeUntilSemicolonOrBlock(k, !custom)
+                if isRule {
+                    rules = append(rules, nestedRule(values).ToItem())
+                } else if decl, ok := p.ConsumeDeclaration(values); ok {
+                    decls = append(decls, decl.ToItem())
+                }
+            default:
+                // Reconsume the current input token. Consume a nested style
+                // rule. If anything was returned, append it to rules.
+                // Otherwise, this is a parse error.
+                k.Push(t)
+                values, isRule := p.consumeUntilSemicolonOrBlock(k, true)
+                if isRule {
+                    rules = append(rules, nestedRule(values).ToItem())
+                } else {
+                    p.error(ErrUnexpectedToken, t)
+                }
+        }
+    }
+}
+
+// consumeUntilSemicolonOrBlock is like consumeUntilSemicolon, but if
+// stopAtBlock is true, also stops after a top-level {}-block, in which case
+// it returns true.
+func (p *Parser) consumeUntilSemicolonOrBlock(k Stream, stopAtBlock bool) ([]item.ComponentValue, bool) {
+    var values []item.ComponentValue
+    for {
+        t := k.Next()
+        if t.Is(token.TypeSemicolon) || t.Is(token.TypeEOF) {
+            k.Push(t)
+            return values, false
+        }
+        k.Push(t)
+        cv := p.ConsumeComponentValue(k)
+        values = append(values, cv)
+        if stopAtBlock && (cv.Type() == item.TypeBlock) &&
+            cv.ToBlock().Delim.Is(token.TypeLeftCurlyBracket) {
+            return values, true
+        }
+    }
+}
+
+// nestedRule returns a qualified rule from a list of component values
+// ending with a {}-block.
+func nestedRule(values []item.ComponentValue) item.QualifiedRule {
+    n := len(values)
+    return item.QualifiedRule{
+        Prelude: values[0:n - 1],
+        Block:   values[n - 1].ToBlock(),
+    }
+}
+
+// ConsumeListOfDeclarations consumes a list of declarations and at-rules.
+func (p *Parser) ConsumeListOfDeclarations(k Stream) []item.Item {
+    // Create an initially empty list of declarations.
+    var decls []item.Item
+
+    // Repeatedly consume the next input token:
+    for {
+        t := k.Next()
+        switch {
+            case t.Is(token.TypeWhitespace), t.Is(token.TypeSemicolon):
+                // Do nothing.
+            case t.Is(token.TypeEOF):
+                // Return the list of declarations.
+                return decls
+            case t.Is(token.TypeAtKeyword):
+                // Reconsume the current input token. Consume an at-rule.
+                // Append the returned rule to the list of declarations.
+                k.Push(t)
+                decls = append(decls, p.ConsumeAtRule(k).ToItem())
+            case t.Is(token.TypeIdent):
+                // Initialize a temporary list initially filled with the
+                // current input token. As long as the next input token is
+                // anything other than a <semicolon-token> or <EOF-token>,
+                // consume a component value and append it to the temporary
+                // list. Consume a declaration from the temporary list. If
+                // anything was returned, append it to the list of
+                // declarations.
+                k.Push(t)
+                if decl, ok := p.ConsumeDeclaration(p.consumeUntilSemicolon(k)); ok {
+                    decls = append(decls, decl.ToItem())
+                }
+            default:
+                // This is a parse error. Reconsume the current input token.
+                // As long as the next input token is anything other than a
+                // <semicolon-token> or <EOF-token>, consume a component value
+                // and throw away the returned value.
+                p.error(ErrUnexpectedToken, t)
+                k.Push(t)
+                p.consumeUntilSemicolon(k)
+        }
+    }
+}
+
+// consumeUntilSemicolon consumes component values for as long as the next
+// input token is anything other than a <semicolon-token> or <EOF-token>.
+func (p *Parser) consumeUntilSemicolon(k Stream) []item.ComponentValue {
+    var values []item.ComponentValue
+    for {
+        t := k.Next()
+        if t.Is(token.TypeSemicolon) || t.Is(token.TypeEOF) {
+            k.Push(t)
+            return values
+        }
+        k.Push(t)
+        values = append(values, p.ConsumeComponentValue(k))
+    }
+}
+
+// ConsumeDeclaration consumes a declaration from a list of component values.
+// It returns false if nothing is returned (if the declaration is invalid).
+// Note that this algorithm assumes that the first component value has
+// already been checked to be an <ident-token>.
+func (p *Parser) ConsumeDeclaration(values []item.ComponentValue) (item.Declaration, bool) {
+    isToken := func(i int, t token.Type) bool {
+        return (i < len(values)) &&
+            (values[i].Type() == item.TypePreservedToken) &&
+            token.Token(values[i].ToPreservedToken()).Is(t)
+    }
+
+    // Consume the next input token. Create a new declaration with its name
+    // set to the value of the current input token and its value initially
+    // set to the empty list.
+    first := token.Token(values[0].ToPreservedToken())
+    decl := item.Declaration{
+        Name: first.StringValue(),
+    }
+    i := 1
+
+    // While the next input token is a <whitespace-token>, consume the next
+    // input token.
+    for isToken(i, token.TypeWhitespace) { i++ }
+
+    // If the next input token is anything other than a <colon-token>, this
+    // is a parse error. Return nothing.
+    if !isToken(i, token.TypeColon) {
+        p.error(ErrInvalidDeclaration, first)
+        return item.Declaration{}, false
+    }
+
+    // Otherwise, consume the next input token.
+    i++
+
+    // While the next input token is a <whitespace-token>, consume the next
+    // input token.
+    for isToken(i, token.TypeWhitespace) { i++ }
+
+    // As long as the next input token is anything other than an
+    // <EOF-token>, consume a component value and append it to the
+    // declaration’s value.
+    value := append([]item.ComponentValue(nil), values[i:]...)
+
+    // If the last two non-<whitespace-token>s in the declaration’s value are
+    // a <delim-token> with the value "!" followed by an <ident-token> with a
+    // value that is an ASCII case-insensitive match for "important", remove
+    // them from the declaration’s value and set the declaration’s important
+    // flag to true.
+    value = trimWhitespace(value)
+    if n := len(value); n >= 2 {
+        last := value[n - 1]
+        if (last.Type() == item.TypePreservedToken) &&
+            token.Token(last.ToPreservedToken()).Is(token.TypeIdent) &&
+            strings.EqualFold(token.Token(last.ToPreservedToken()).StringValue(), "important") {
+
+            rest := trimWhitespace(value[0:n - 1])
+            if m := len(rest); (m >= 1) && rest[m - 1].IsPreservedToken(token.Delim('!')) {
+                value = rest[0:m - 1]
+                decl.Important = true
+            }
+        }
+    }
+
+    // While the last token in the declaration’s value is a
+    // <whitespace-token>, remove that token.
+    decl.Value = trimWhitespace(value)
+
+    // Return the declaration.
+    return decl, true
+}
+
+// trimWhitespace returns values without any trailing whitespace tokens.
+func trimWhitespace(values []item.ComponentValue) []item.ComponentValue {
+    for (len(values) > 0) && values[len(values) - 1].IsPreservedToken(token.Whitespace()) {
+        values = values[0:len(values) - 1]
+    }
+    return values
+}
+
+// ConsumeComponentValue consumes a component value, which is either a
+// preserved token, a function, or a simple block.
+func (p *Parser) ConsumeComponentValue(k Stream) item.ComponentValue {
+    // Consume the next input token.
+    t := k.Next()
+
+    // If the current input token is a <{-token>, <[-token>, or <(-token>,
+    // consume a simple block and return it.
+    if TokenIsBlockStart(t) {
+        return p.ConsumeBlock(k, t).ToComponentValue()
+    }
+
+    // Otherwise, if the current input token is a <function-token>, consume a function and return it.
+    if t.Is(token.TypeFunction) {
+        return p.ConsumeFunction(k, t).ToComponentValue()
+    }
+
+    // Otherwise, return the current input token.
+    return item.PreservedToken(t).ToComponentValue()
+}
+
+// ConsumeBlock consumes a simple block. Note that this algorithm assumes that
+// the current input token has already been checked to be an <{-token>,
+// <[-token>, or <(-token>.
+func (p *Parser) ConsumeBlock(k Stream, start token.Token) item.Block {
+    // The ending token is the mirror variant of the current input token.
+    // (E.g. if it was called with <[-token>, the ending token is <]-token>.)
+    end := mirror(start)
+
+    // Create a simple block with its associated token set to the current
+    // input token and with its value initially set to an empty list.
+    block := item.Block{
+        Delim: start,
+    }
+
+    // Repeatedly consume the next input token and process it as follows:
+    for {
+        t := k.Next()
+        if token.Equals(t, end) {
+            return block
+        } else if t.Is(token.TypeEOF) {
+            // This is a parse error. Return the block.
+            p.error(ErrUnexpectedEOF, t)
+            return block
+        } else {
+            // Reconsume the current input token.
+            k.Push(t)
+            // Consume a component value and append it to the value of the block.
+            block.Value = append(block.Value, p.ConsumeComponentValue(k))
+        }
+    }
+}
+
+// ConsumeFunction consumes a function. Note: This algorithm assumes that the
+// current input token has already been checked to be a <function-token>.
+func (p *Parser) ConsumeFunction(k Stream, name token.Token) item.Function {
+    // Create a function with its name equal to the value of the current input
+    // token and with its value initially set to an empty list.
+    f := item.Function{
+        Name: name.StringValue(),
+    }
+
+    // Repeatedly consume the next input token and process it as follows:
+    for {
+        t := k.Next()
+        if t.Is(token.TypeRightParen) {
+            return f
+        } else if t.Is(token.TypeEOF) {
+            // This is a parse error. Return the function.
+            p.error(ErrUnexpectedEOF, t)
+            return f
+        } else {
+            // Reconsume the current input token.
+            k.Push(t)
+            // Consume a component value and append the returned value to the
+            // function’s value.
+            f.Value = append(f.Value, p.ConsumeComponentValue(k))
+        }
+    }
+}
+
+// tokenList is a [Stream] over a list of tokens.
+type tokenList struct {
+    tokens []token.Token
+    pushed []token.Token
+}
+
+func (l *tokenList) Next() token.Token {
+    if n := len(l.pushed); n > 0 {
+        t := l.pushed[n - 1]
+        l.pushed = l.pushed[0:n - 1]
+        return t
+    }
+    if len(l.tokens) == 0 { return token.EOF() }
+    t := l.tokens[0]
+    l.tokens = l.tokens[1:]
+    return t
+}
+
+func (l *tokenList) Push(x token.Token) {
+    l.pushed = append(l.pushed, x)
+}
@@ -0,0 +1,238 @@
+package parser_test
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/parser"
+    "github.com/tawesoft/golib/v2/css/parser/item"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+)
+
+func ExampleParser() {
+    str := `rgb(128, 64, 64)`
+    p := parser.New(strings.NewReader(str))
+    k := p.Tokenizer()
+
+    for {
+        cv := p.ConsumeComponentValue(k)
+        if cv.IsPreservedToken(token.EOF()) { break }
+        fmt.Println(cv)
+    }
+
+    // Output:
+    // <ComponentValue/Function{Name: "rgb", Value: [<ComponentValue:<number-token>{type: "integer", value: 128.000000, repr: "128"}> <ComponentValue:<comma-token>> <ComponentValue:<whitespace-token>> <ComponentValue:<number-token>{type: "integer", value: 64.000000, repr: "64"}> <ComponentValue:<comma-token>> <ComponentValue:<whitespace-token>> <ComponentValue:<number-token>{type: "integer", value: 64.000000, repr: "64"}>]}>
+}
+
+func ExampleParser_ParseStylesheet() {
+    str := `@import "foo.css";
+a:hover {
+    color: red !important;
+    margin: 0 auto;
+}`
+    p := parser.New(strings.NewReader(str))
+
+    for _, rule := range p.ParseStylesheet() {
+        switch rule.Type() {
+            case item.TypeAtRule:
+                fmt.Printf("at-rule %q\n", rule.ToAtRule().Name)
+            case item.TypeQualifiedRule:
+                qr := rule.ToQualifiedRule()
+                fmt.Printf("qualified rule with prelude of %d component values\n", len(qr.Prelude))
+
+                decls := parser.NewFromComponentValues(qr.Block.Value).ParseStyleBlockContents()
+                for _, decl := range decls {
+                    d := decl.ToDeclaration()
+                    fmt.Printf("    declaration %q, important=%t, with %d component values\n",
+                        d.Name, d.Important, len(d.Value))
+                }
+        }
+    }
+
+    if len(p.Errors()) > 0 {
+        fmt.Printf("%v\n", p.Errors())
+    }
+
+    // Output:
+    // at-rule "import"
+    // qualified rule with prelude of 4 component values
+    //     declaration "color", important=true, with 1 component values
+    //     declaration "margin", important=false, with 3 component values
+}
+
+func TestParser_ParseStylesheet(t *testing.T) {
+    str := `<!-- @media screen { p { color: blue } } -->
+h1, h2 { font: 1em/2 "Foo" } ; @page :first { margin: 1in; } p {`
+
+    p := parser.New(strings.NewReader(str))
+    rules := p.ParseStylesheet()
+
+    if len(rules) != 4 {
+        t.Fatalf("expected 4 rules, got %d: %v", len(rules), rules)
+    }
+
+    media := rules[0].ToAtRule()
+    if media.Name != "media" { t.Errorf("expected @media, got %q", media.Name) }
+    block, ok := media.Block.Unpack()
+    if !ok { t.Fatalf("expected @media block") }
+    inner := parser.NewFromComponentValues(block.Value).ParseRuleList()
+    if (len(inner) != 1) || !inner[0].Is(item.TypeQualifiedRule) {
+        t.Errorf("expected one nested qualified rule, got %v", inner)
+    }
+
+    // note that a stray semicolon at the top level begins a qualified rule
+    h := rules[1].ToQualifiedRule()
+    if len(h.Prelude) != 5 { t.Errorf("expected 5 prelude values, got %v", h.Prelude) }
+
+    page := rules[2].ToQualifiedRule()
+    if !page.Prelude[0].IsPreservedToken(token.Semicolon()) {
+        t.Errorf("expected qualified rule beginning with ';', got %v", page.Prelude)
+    }
+
+    // the final block is closed at EOF, but this is a parse error
+    p2 := rules[3].ToQualifiedRule()
+    if len(p2.Block.Value) != 0 { t.Errorf("expected empty block, got %v", p2.Block) }
+    errs := p.Errors()
+    if (len(errs) != 1) || !errors.Is(errs[0], parser.ErrUnexpectedEOF) {
+        t.Errorf("expected one ErrUnexpectedEOF, got %v", errs)
+    }
+}
+
+func TestParser_ParseDeclarationList(t *testing.T) {
+    type row struct {
+        input string
+        names []string
+        important []bool
+        values []int // number of component values
+        errors int
+    }
+    rows := []row{
+        {"", nil, nil, nil, 0},
+        {"a: b", []string{"a"}, []bool{false}, []int{1}, 0},
+        {" a : b c ; ;", []string{"a"}, []bool{false}, []int{3}, 0},
+        {"a: b ! IMPORTANT ", []string{"a"}, []bool{true}, []int{1}, 0},
+        {"a: !important", []string{"a"}, []bool{true}, []int{0}, 0},
+        {"a: important", []string{"a"}, []bool{false}, []int{1}, 0},
+        {"a: b; c d; e: f(;)", []string{"a", "e"}, []bool{false, false}, []int{1, 1}, 1},
+        {"a: b; 12: c; d: e", []string{"a", "d"}, []bool{false, false}, []int{1, 1}, 1},
+        {"a: {;}; b: c", []string{"a", "b"}, []bool{false, false}, []int{1, 1}, 0},
+        {"--x: ; --y:{}", []string{"--x", "--y"}, []bool{false, false}, []int{0, 1}, 0},
+    }
+
+    for _, r := range rows {
+        p := parser.New(strings.NewReader(r.input))
+        items := p.ParseDeclarationList()
+        if len(items) != len(r.names) {
+            t.Errorf("%q: expected %d declarations, got %v", r.input, len(r.names), items)
+            continue
+        }
+        for i, x := range items {
+            decl := x.ToDeclaration()
+            if (decl.Name != r.names[i]) ||
+                (decl.Important != r.important[i]) ||
+                (len(decl.Value) != r.values[i]) {
+                t.Errorf("%q: unexpected declaration %d: %v", r.input, i, decl)
+            }
+        }
+        if len(p.Errors()) != r.errors {
+            t.Errorf("%q: expected %d errors, got %v", r.input, r.errors, p.Errors())
+        }
+    }
+}
+
+func TestParser_ParseStyleBlockContents(t *testing.T) {
+    str := `color: red; & > b { color: blue } @media print { color: black } margin: 0`
+    p := parser.New(strings.NewReader(str))
+    items := p.ParseStyleBlockContents()
+
+    var types []item.Type
+    for _, x := range items {
+        types = append(types, x.Type())
+    }
+    expected := []item.Type{
+        item.TypeDeclaration, item.TypeDeclaration, // declarations first
+        item.TypeQualifiedRule, item.TypeAtRule,
+    }
+    if fmt.Sprint(types) != fmt.Sprint(expected) {
+        t.Errorf("got %v, expected %v", types, expected)
+    }
+}
+
+func TestParser_ParseStyleBlockContents_nesting(t *testing.T) {
+    type row struct {
+        input string
+        types []item.Type
+        errors int
+    }
+    d, q, a := item.TypeDeclaration, item.TypeQualifiedRule, item.TypeAtRule
+    rows := []row{
+        {"a:hover { } color: red",          []item.Type{d, q},    0},
+        {".a { } > b { } color: red",       []item.Type{d, q, q}, 0},
+        {"color: red; b c { x: y }; d: e",  []item.Type{d, d, q}, 0},
+        {"--x: { a } b; a: {}",             []item.Type{d, q},    0},
+        {"@media print { } :is(a) { }",     []item.Type{a, q},    0},
+        {"color: red; 12; b { }",           []item.Type{d, q},    1},
+    }
+    for _, r := range rows {
+        p := parser.New(strings.NewReader(r.input))
+        var types []item.Type
+        for _, x := range p.ParseStyleBlockContents() {
+            types = append(types, x.Type())
+        }
+        if fmt.Sprint(types) != fmt.Sprint(r.types) {
+            t.Errorf("%q: got %v, expected %v", r.input, types, r.types)
+        }
+        if len(p.Errors()) != r.errors {
+            t.Errorf("%q: expected %d errors, got %v", r.input, r.errors, p.Errors())
+        }
+    }
+}
+
+func TestParser_entryPoints(t *testing.T) {
+    rule, err := parser.New(strings.NewReader(" a { } ")).ParseRule()
+    if (err != nil) || !rule.Is(item.TypeQualifiedRule) {
+        t.Errorf("ParseRule: unexpected %v, %v", rule, err)
+    }
+    for _, input := range []string{"", "   ", "a { } b { }", "a"} {
+        if _, err := parser.New(strings.NewReader(input)).ParseRule(); !errors.Is(err, parser.ErrSyntax) {
+            t.Errorf("ParseRule(%q): expected ErrSyntax, got %v", input, err)
+        }
+    }
+
+    decl, err := parser.New(strings.NewReader(" color : red ")).ParseDeclaration()
+    if (err != nil) || (decl.Name != "color") || (len(decl.Value) != 1) {
+        t.Errorf("ParseDeclaration: unexpected %v, %v", decl, err)
+    }
+    for _, input := range []string{"", "12: red", "color red"} {
+        if _, err := parser.New(strings.NewReader(input)).ParseDeclaration(); !errors.Is(err, parser.ErrSyntax) {
+            t.Errorf("ParseDeclaration(%q): expected ErrSyntax, got %v", input, err)
+        }
+    }
+
+    cv, err := parser.New(strings.NewReader(" [a b] ")).ParseComponentValue()
+    if (err != nil) || (cv.Type() != item.TypeBlock) {
+        t.Errorf("ParseComponentValue: unexpected %v, %v", cv, err)
+    }
+    if _, err := parser.New(strings.NewReader("a b")).ParseComponentValue(); !errors.Is(err, parser.ErrSyntax) {
+        t.Errorf("ParseComponentValue: expected ErrSyntax, got %v", err)
+    }
+
+    lists := parser.New(strings.NewReader("a b, c,")).ParseCommaSeparatedComponentValues()
+    if (len(lists) != 3) || (len(lists[0]) != 3) || (len(lists[1]) != 2) || (len(lists[2]) != 0) {
+        t.Errorf("ParseCommaSeparatedComponentValues: unexpected %v", lists)
+    }
+}
+
+func TestTokens(t *testing.T) {
+    str := `a(b [c {d}]) e`
+    values := parser.New(strings.NewReader(str)).ParseComponentValueList()
+    again := parser.NewFromComponentValues(values).ParseComponentValueList()
+    if fmt.Sprint(values) != fmt.Sprint(again) {
+        t.Errorf("got %v, expected %v", again, values)
+    }
+    if n := len(item.Tokens(values)); n != 13 {
+        t.Errorf("expected 13 tokens, got %d", n)
+    }
+}
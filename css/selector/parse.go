@@ -0,0 +1,432 @@
+package selector
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+var (
+    ErrSyntax        = fmt.Errorf("invalid selector syntax")
+    ErrEmpty         = fmt.Errorf("empty selector")
+    ErrUnexpectedEOF = fmt.Errorf("unexpected end of selector")
+)
+
+type errSyntax struct {
+    err error
+    at token.Position
+}
+
+func (e errSyntax) Unwrap() error {
+    return e.err
+}
+
+func (e errSyntax) Error() string {
+    return fmt.Sprintf("error at %s: %s", e.at, e.err.Error())
+}
+
+// ParseString parses a selector list from a string.
+func ParseString(s string) (List, error) {
+    k := tokenizer.New(strings.NewReader(s))
+    var tokens []token.Token
+    for {
+        t := k.Next()
+        if t.Is(token.TypeEOF) { break }
+        tokens = append(tokens, t)
+    }
+    if len(k.Errors()) > 0 {
+        return nil, fmt.Errorf("parse errors: %+v", k.Errors())
+    }
+    return Parse(tokens)
+}
+
+// Parse parses a selector list from a sequence of tokens. The sequence
+// should not include a token.EOF().
+//
+// If any selector in the list is invalid, the whole list is invalid, and an
+// error is returned.
+func Parse(tokens []token.Token) (List, error) {
+    return parseList(tokens, false, false)
+}
+
+// ParseRelative is like [Parse], but parses a relative selector list, such
+// as the argument of :has(), where each selector may start with a
+// combinator.
+func ParseRelative(tokens []token.Token) (List, error) {
+    return parseList(tokens, true, false)
+}
+
+// parseList parses a comma-separated list of complex selectors. If forgiving
+// is true, invalid selectors are omitted from the list, rather than making
+// the whole list invalid.
+func parseList(tokens []token.Token, relative bool, forgiving bool) (List, error) {
+    var result List
+    for _, part := range splitCommas(tokens) {
+        c, err := parseComplex(part, relative)
+        if err != nil {
+            if forgiving { continue }
+            return nil, err
+        }
+        result = append(result, c)
+    }
+    return result, nil
+}
+
+// splitCommas splits tokens at each top-level comma, and trims whitespace
+// from each part.
+func splitCommas(tokens []token.Token) [][]token.Token {
+    var result [][]token.Token
+    depth := 0
+    start := 0
+    for i, t := range tokens {
+        switch {
+            case isOpen(t):
+                depth++
+            case isClose(t):
+                depth--
+            case t.Is(token.TypeComma) && (depth == 0):
+                result = append(result, trim(tokens[start:i]))
+                start = i + 1
+        }
+    }
+    return append(result, trim(tokens[start:]))
+}
+
+func isOpen(t token.Token) bool {
+    return t.Is(token.TypeFunction) ||
+        t.Is(token.TypeLeftParen) ||
+        t.Is(token.TypeLeftSquareBracket) ||
+        t.Is(token.TypeLeftCurlyBracket)
+}
+
+func isClose(t token.Token) bool {
+    return t.Is(token.TypeRightParen) ||
+        t.Is(token.TypeRightSquareBracket) ||
+        t.Is(token.TypeRightCurlyBracket)
+}
+
+func isDelim(t token.Token, x rune) bool {
+    return t.Is(token.TypeDelim) && (t.Delim() == x)
+}
+
+// trim removes any leading or trailing whitespace tokens.
+func trim(tokens []token.Token) []token.Token {
+    for (len(tokens) > 0) && tokens[0].Is(token.TypeWhitespace) {
+        tokens = tokens[1:]
+    }
+    for (len(tokens) > 0) && tokens[len(tokens) - 1].Is(token.TypeWhitespace) {
+        tokens = tokens[0:len(tokens) - 1]
+    }
+    return tokens
+}
+
+type parser struct {
+    tokens []token.Token
+    pos int
+}
+
+// peek returns the token n tokens ahead, or token.EOF().
+func (p *parser) peek(n int) token.Token {
+    if p.pos + n >= len(p.tokens) {
+        if len(p.tokens) == 0 { return token.EOF() }
+        end := p.tokens[len(p.tokens) - 1].Position()
+        end.Byte, end.Rune, end.Line = end.End, end.EndRune, end.EndLine
+        return token.EOF().WithPosition(end)
+    }
+    return p.tokens[p.pos + n]
+}
+
+func (p *parser) atEnd() bool {
+    return p.pos >= len(p.tokens)
+}
+
+func (p *parser) skipWhitespace() bool {
+    skipped := false
+    for p.peek(0).Is(token.TypeWhitespace) {
+        p.pos++
+        skipped = true
+    }
+    return skipped
+}
+
+func (p *parser) error(err error) error {
+    t := p.peek(0)
+    if t.Is(token.TypeEOF) && (err == ErrSyntax) { err = ErrUnexpectedEOF }
+    return errSyntax{err, t.Position()}
+}
+
+func parseComplex(tokens []token.Token, relative bool) (Complex, error) {
+    p := &parser{tokens: tokens}
+    if len(tokens) == 0 { return nil, p.error(ErrEmpty) }
+
+    var result Complex
+    combinator := CombinatorNone
+    if relative {
+        combinator = p.combinator()
+        p.skipWhitespace()
+    }
+
+    for {
+        compound, err := p.compound()
+        if err != nil { return nil, err }
+        compound.Combinator = combinator
+        result = append(result, compound)
+
+        if p.atEnd() { break }
+
+        ws := p.skipWhitespace()
+        combinator = p.combinator()
+        if combinator == CombinatorNone {
+            if !ws { return nil, p.error(ErrSyntax) }
+            combinator = CombinatorDescendant
+        }
+        p.skipWhitespace()
+    }
+
+    return result, nil
+}
+
+// combinator consumes a combinator other than a descendant combinator, if
+// there is one.
+func (p *parser) combinator() Combinator {
+    t := p.peek(0)
+    switch {
+        case isDelim(t, '>'):
+            p.pos++
+            return CombinatorChild
+        case isDelim(t, '+'):
+            p.pos++
+            return CombinatorNextSibling
+        case isDelim(t, '~'):
+            p.pos++
+            return CombinatorSubsequentSibling
+        case isDelim(t, '|') && isDelim(p.peek(1), '|'):
+            p.pos += 2
+            return CombinatorColumn
+    }
+    return CombinatorNone
+}
+
+func (p *parser) compound() (Compound, error) {
+    var result Compound
+
+    if s, ok := p.typeSelector(); ok {
+        result.Simples = append(result.Simples, s)
+    }
+
+    for {
+        t := p.peek(0)
+        switch {
+            case t.Is(token.TypeHash):
+                if t.HashType() != token.HashTypeID { return result, p.error(ErrSyntax) }
+                p.pos++
+                result.Simples = append(result.Simples, Simple{
+                    Kind: KindID,
+                    Name: t.StringValue(),
+                })
+            case isDelim(t, '.'):
+                if !p.peek(1).Is(token.TypeIdent) {
+                    p.pos++
+                    return result, p.error(ErrSyntax)
+                }
+                result.Simples = append(result.Simples, Simple{
+                    Kind: KindClass,
+                    Name: p.peek(1).StringValue(),
+                })
+                p.pos += 2
+            case isDelim(t, '&'):
+                p.pos++
+                result.Simples = append(result.Simples, Simple{Kind: KindNesting})
+            case t.Is(token.TypeLeftSquareBracket):
+                p.pos++
+                s, err := p.attribute()
+                if err != nil { return result, err }
+                result.Simples = append(result.Simples, s)
+            case t.Is(token.TypeColon):
+                p.pos++
+                s, err := p.pseudo()
+                if err != nil { return result, err }
+                result.Simples = append(result.Simples, s)
+            default:
+                if len(result.Simples) == 0 { return result, p.error(ErrSyntax) }
+                return result, nil
+        }
+    }
+}
+
+// namespace consumes a namespace prefix, if there is one, that is followed
+// by an <ident-token> (or, if universal is true, a '*').
+func (p *parser) namespace(universal bool) maybe.M[string] {
+    isName := func(t token.Token) bool {
+        return t.Is(token.TypeIdent) || (universal && isDelim(t, '*'))
+    }
+
+    a, b, c := p.peek(0), p.peek(1), p.peek(2)
+    switch {
+        case isDelim(a, '|') && isName(b):
+            p.pos++
+            return maybe.Some("")
+        case isDelim(a, '*') && isDelim(b, '|') && isName(c):
+            p.pos += 2
+            return maybe.Some("*")
+        case a.Is(token.TypeIdent) && isDelim(b, '|') && isName(c):
+            p.pos += 2
+            return maybe.Some(a.StringValue())
+    }
+    return maybe.Nothing[string]()
+}
+
+func (p *parser) typeSelector() (Simple, bool) {
+    start := p.pos
+    ns := p.namespace(true)
+    t := p.peek(0)
+    switch {
+        case t.Is(token.TypeIdent):
+            p.pos++
+            return Simple{Kind: KindType, Name: t.StringValue(), Namespace: ns}, true
+        case isDelim(t, '*'):
+            p.pos++
+            return Simple{Kind: KindUniversal, Namespace: ns}, true
+    }
+    p.pos = start
+    return Simple{}, false
+}
+
+// attribute parses an attribute selector, after the opening '['.
+func (p *parser) attribute() (Simple, error) {
+    result := Simple{Kind: KindAttribute}
+
+    p.skipWhitespace()
+    result.Namespace = p.namespace(false)
+    t := p.peek(0)
+    if !t.Is(token.TypeIdent) { return result, p.error(ErrSyntax) }
+    p.pos++
+    result.Name = t.StringValue()
+    p.skipWhitespace()
+
+    if p.peek(0).Is(token.TypeRightSquareBracket) {
+        p.pos++
+        return result, nil
+    }
+
+    // <attr-matcher> = [ '~' | '|' | '^' | '$' | '*' ]? '='
+    t = p.peek(0)
+    if isDelim(t, '=') {
+        p.pos++
+        result.Matcher = "="
+    } else if t.Is(token.TypeDelim) && strings.ContainsRune("~|^$*", t.Delim()) && isDelim(p.peek(1), '=') {
+        p.pos += 2
+        result.Matcher = string(t.Delim()) + "="
+    } else {
+        return result, p.error(ErrSyntax)
+    }
+    p.skipWhitespace()
+
+    t = p.peek(0)
+    if !(t.Is(token.TypeString) || t.Is(token.TypeIdent)) { return result, p.error(ErrSyntax) }
+    p.pos++
+    result.Value = t.StringValue()
+    p.skipWhitespace()
+
+    // <attr-modifier> = i | s
+    t = p.peek(0)
+    if t.Is(token.TypeIdent) {
+        m := strings.ToLower(t.StringValue())
+        if (m != "i") && (m != "s") { return result, p.error(ErrSyntax) }
+        p.pos++
+        result.Modifier = m
+        p.skipWhitespace()
+    }
+
+    if !p.peek(0).Is(token.TypeRightSquareBracket) { return result, p.error(ErrSyntax) }
+    p.pos++
+    return result, nil
+}
+
+// pseudo parses a pseudo-class or pseudo-element, after the first ':'.
+func (p *parser) pseudo() (Simple, error) {
+    result := Simple{Kind: KindPseudoClass}
+    if p.peek(0).Is(token.TypeColon) {
+        p.pos++
+        result.Kind = KindPseudoElement
+    }
+
+    t := p.peek(0)
+    switch {
+        case t.Is(token.TypeIdent):
+            p.pos++
+            result.Name = t.StringValue()
+        case t.Is(token.TypeFunction):
+            p.pos++
+            result.Name = t.StringValue()
+            result.Function = true
+            args, err := p.args()
+            if err != nil { return result, err }
+            result.Args = trim(args)
+        default:
+            return result, p.error(ErrSyntax)
+    }
+
+    if result.Kind == KindPseudoElement { return result, nil }
+
+    name := strings.ToLower(result.Name)
+    if !result.Function {
+        switch name {
+            // legacy pseudo-elements written with a single colon
+            case "before": fallthrough
+            case "after": fallthrough
+            case "first-line": fallthrough
+            case "first-letter":
+                result.Kind = KindPseudoElement
+        }
+        return result, nil
+    }
+
+    var err error
+    switch name {
+        case "is": fallthrough
+        case "where":
+            result.Selectors, err = parseList(result.Args, false, true)
+        case "not":
+            result.Selectors, err = parseList(result.Args, false, false)
+        case "has":
+            result.Selectors, err = parseList(result.Args, true, false)
+        case "nth-child": fallthrough
+        case "nth-last-child":
+            // <an+b> [ of <complex-selector-list> ]?
+            for i, x := range result.Args {
+                if x.Is(token.TypeIdent) && strings.EqualFold(x.StringValue(), "of") {
+                    result.Selectors, err = parseList(result.Args[i+1:], false, false)
+                    break
+                }
+            }
+    }
+    return result, err
+}
+
+// args consumes the arguments of a function, after the <function-token>,
+// up to and including the matching ')', and returns them excluding the ')'.
+func (p *parser) args() ([]token.Token, error) {
+    start := p.pos
+    depth := 0
+    for {
+        t := p.peek(0)
+        switch {
+            case t.Is(token.TypeEOF):
+                return nil, p.error(ErrUnexpectedEOF)
+            case isOpen(t):
+                depth++
+            case isClose(t):
+                if depth == 0 {
+                    if !t.Is(token.TypeRightParen) { return nil, p.error(ErrSyntax) }
+                    p.pos++
+                    return p.tokens[start:p.pos - 1], nil
+                }
+                depth--
+        }
+        p.pos++
+    }
+}
@@ -0,0 +1,290 @@
+// Package selector parses CSS selectors, based on the
+// [Selectors Level 4] (W3C Working Draft), 11 November 2022, and computes
+// their specificity.
+//
+// Selectors are parsed from a sequence of tokens (see [css/tokenizer]), for
+// example the prelude of a qualified rule produced by [css/parser] (see
+// [css/parser/item.Tokens]).
+//
+// The arguments of the functional pseudo-classes :is(), :not(), :where(),
+// and :has() are themselves parsed as selector lists, as is the "of S" part
+// of the arguments of :nth-child() and :nth-last-child(). The arguments of
+// any other function are kept as tokens.
+//
+// The nesting selector "&", from the [CSS Nesting Module], is also parsed
+// (see [KindNesting]), for selectors of nested style rules.
+//
+// [Selectors Level 4]: https://www.w3.org/TR/selectors-4/
+// [CSS Nesting Module]: https://www.w3.org/TR/css-nesting-1/
+//
+// This software includes material derived from Selectors Level 4, W3C
+// Working Draft, 11 November 2022. Copyright © 2022 W3C® (MIT, ERCIM, Keio,
+// Beihang). See LICENSE-PARTS.txt and TRADEMARKS.md.
+package selector
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+// List is a selector list, for example "a, b > c".
+type List []Complex
+
+// Complex is a complex selector, for example "a b > c", made of one or more
+// compound selectors, each joined to the previous one by a combinator.
+type Complex []Compound
+
+// Compound is a compound selector, for example "a.b:hover", made of one or
+// more simple selectors not separated by a combinator.
+type Compound struct {
+    // Combinator is the combinator that joins this compound selector to the
+    // previous one in a complex selector. For the first compound selector,
+    // this is CombinatorNone, except in a relative selector such as the
+    // argument of :has(), where it may be given explicitly.
+    Combinator Combinator
+
+    // Simples are the simple selectors, in the order written.
+    Simples []Simple
+}
+
+type Combinator string
+const (
+    CombinatorNone              = Combinator("")
+    CombinatorDescendant        = Combinator(" ")
+    CombinatorChild             = Combinator(">")
+    CombinatorNextSibling       = Combinator("+")
+    CombinatorSubsequentSibling = Combinator("~")
+    CombinatorColumn            = Combinator("||")
+)
+
+type Kind string
+const (
+    KindType          = Kind("type")           // e.g. "a", "svg|a"
+    KindUniversal     = Kind("universal")      // e.g. "*", "svg|*"
+    KindID            = Kind("id")             // e.g. "#a"
+    KindClass         = Kind("class")          // e.g. ".a"
+    KindAttribute     = Kind("attribute")      // e.g. "[a]", "[a=b i]"
+    KindPseudoClass   = Kind("pseudo-class")   // e.g. ":hover", ":is(a)"
+    KindPseudoElement = Kind("pseudo-element") // e.g. "::before"
+
+    // KindNesting is the nesting selector "&" of the CSS Nesting Module,
+    // which represents the elements matched by the parent style rule.
+    KindNesting = Kind("nesting")
+)
+
+// Simple is a simple selector.
+type Simple struct {
+    Kind Kind
+
+    // Name is the name of the element, ID, class, attribute, pseudo-class, or
+    // pseudo-element, as written (i.e. not case folded).
+    Name string
+
+    // Namespace is the namespace prefix of a type, universal, or attribute
+    // selector. It is Nothing if there is no namespace prefix, the empty
+    // string for the prefix "|" (no namespace), or "*" for the prefix "*|"
+    // (any namespace).
+    Namespace maybe.M[string]
+
+    // Matcher is the attribute matcher e.g. "=" or "~=", or the empty string
+    // if the attribute selector only tests for the attribute's presence.
+    Matcher string
+
+    // Value is the value that an attribute is compared against.
+    Value string
+
+    // Modifier is the attribute modifier "i" or "s" (in lower case), or the
+    // empty string.
+    Modifier string
+
+    // Function is true for a functional pseudo-class or pseudo-element,
+    // such as ":not(a)", and Args are the tokens of its arguments, excluding
+    // any leading or trailing whitespace.
+    Function bool
+    Args []token.Token
+
+    // Selectors is the parsed selector list argument of a functional
+    // pseudo-class, if any (see package documentation).
+    Selectors List
+}
+
+// Specificity is the specificity of a selector, where A counts ID selectors,
+// B counts class selectors, attribute selectors, and pseudo-classes, and C
+// counts type selectors and pseudo-elements.
+type Specificity struct {
+    A, B, C int
+}
+
+// Add returns the sum of two specificities.
+func (s Specificity) Add(t Specificity) Specificity {
+    return Specificity{s.A + t.A, s.B + t.B, s.C + t.C}
+}
+
+// Compare returns -1, 0, or 1 if s is less specific, as specific, or more
+// specific than t, respectively.
+func (s Specificity) Compare(t Specificity) int {
+    a := [3]int{s.A, s.B, s.C}
+    b := [3]int{t.A, t.B, t.C}
+    for i := 0; i < 3; i++ {
+        if a[i] < b[i] { return -1 }
+        if a[i] > b[i] { return 1 }
+    }
+    return 0
+}
+
+func (s Specificity) String() string {
+    return fmt.Sprintf("(%d, %d, %d)", s.A, s.B, s.C)
+}
+
+// Specificity returns the specificity of the most specific complex selector
+// in the list, as for the argument of :is(). An empty list has zero
+// specificity.
+func (l List) Specificity() Specificity {
+    var result Specificity
+    for _, c := range l {
+        s := c.Specificity()
+        if s.Compare(result) > 0 { result = s }
+    }
+    return result
+}
+
+// Specificity returns the specificity of a complex selector.
+func (c Complex) Specificity() Specificity {
+    var result Specificity
+    for _, x := range c {
+        result = result.Add(x.Specificity())
+    }
+    return result
+}
+
+// Specificity returns the specificity of a compound selector.
+func (c Compound) Specificity() Specificity {
+    var result Specificity
+    for _, x := range c.Simples {
+        result = result.Add(x.Specificity())
+    }
+    return result
+}
+
+// Specificity returns the specificity of a simple selector.
+func (s Simple) Specificity() Specificity {
+    switch s.Kind {
+        case KindID:
+            return Specificity{A: 1}
+        case KindClass: fallthrough
+        case KindAttribute:
+            return Specificity{B: 1}
+        case KindType: fallthrough
+        case KindPseudoElement:
+            return Specificity{C: 1}
+        case KindPseudoClass:
+            switch strings.ToLower(s.Name) {
+                case "where":
+                    return Specificity{}
+                case "is": fallthrough
+                case "not": fallthrough
+                case "has":
+                    return s.Selectors.Specificity()
+                case "nth-child": fallthrough
+                case "nth-last-child":
+                    return Specificity{B: 1}.Add(s.Selectors.Specificity())
+                default:
+                    return Specificity{B: 1}
+            }
+        default: // universal, or nesting (see below)
+            // The nesting selector has the specificity of the parent style
+            // rule's selector list, as for :is(), which is not known here.
+            // See css/nesting, which replaces it.
+            return Specificity{}
+    }
+}
+
+func (l List) String() string {
+    var sb strings.Builder
+    for i, c := range l {
+        if i > 0 { sb.WriteString(", ") }
+        sb.WriteString(c.String())
+    }
+    return sb.String()
+}
+
+func (c Complex) String() string {
+    var sb strings.Builder
+    for i, x := range c {
+        switch x.Combinator {
+            case CombinatorNone:
+            case CombinatorDescendant:
+                if i > 0 { sb.WriteString(" ") }
+            default:
+                if i > 0 { sb.WriteString(" ") }
+                sb.WriteString(string(x.Combinator))
+                sb.WriteString(" ")
+        }
+        sb.WriteString(x.String())
+    }
+    return sb.String()
+}
+
+// String returns the compound selector, excluding its combinator.
+func (c Compound) String() string {
+    var sb strings.Builder
+    for _, x := range c.Simples {
+        sb.WriteString(x.String())
+    }
+    return sb.String()
+}
+
+func (s Simple) String() string {
+    serialize := func(xs ... token.Token) string {
+        return tokenizer.Serialize(xs)
+    }
+    ns := func() string {
+        x, ok := s.Namespace.Unpack()
+        if !ok { return "" }
+        if x == "*" { return "*|" }
+        if x == "" { return "|" }
+        return serialize(token.Ident(x)) + "|"
+    }
+    function := func() string {
+        if !s.Function { return "" }
+        return "(" + serialize(s.Args...) + ")"
+    }
+
+    switch s.Kind {
+        case KindType:
+            return ns() + serialize(token.Ident(s.Name))
+        case KindUniversal:
+            return ns() + "*"
+        case KindID:
+            return serialize(token.Hash(token.HashTypeID, s.Name))
+        case KindClass:
+            return "." + serialize(token.Ident(s.Name))
+        case KindAttribute:
+            var sb strings.Builder
+            sb.WriteString("[")
+            sb.WriteString(ns())
+            sb.WriteString(serialize(token.Ident(s.Name)))
+            if s.Matcher != "" {
+                sb.WriteString(s.Matcher)
+                sb.WriteString(serialize(token.String(s.Value)))
+            }
+            if s.Modifier != "" {
+                sb.WriteString(" ")
+                sb.WriteString(s.Modifier)
+            }
+            sb.WriteString("]")
+            return sb.String()
+        case KindPseudoClass:
+            return ":" + serialize(token.Ident(s.Name)) + function()
+        case KindPseudoElement:
+            return "::" + serialize(token.Ident(s.Name)) + function()
+        case KindNesting:
+            return "&"
+        default:
+            return ""
+    }
+}
@@ -0,0 +1,117 @@
+package selector_test
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/parser"
+    "github.com/tawesoft/golib/v2/css/parser/item"
+    "github.com/tawesoft/golib/v2/css/selector"
+)
+
+func Example() {
+    str := `#nav > li.item:not(.hidden) a[href^="https:" i]::after, :where(ul) b {}`
+
+    rule, err := parser.New(strings.NewReader(str)).ParseRule()
+    if err != nil { panic(err) }
+
+    list, err := selector.Parse(item.Tokens(rule.ToQualifiedRule().Prelude))
+    if err != nil { panic(err) }
+
+    for _, complex := range list {
+        fmt.Printf("%s %s\n", complex.Specificity(), complex)
+    }
+
+    // Output:
+    // (1, 3, 3) #nav > li.item:not(.hidden) a[href^="https:" i]::after
+    // (0, 0, 1) :where(ul) b
+}
+
+func TestParseString(t *testing.T) {
+    type row struct {
+        input string
+        expected string
+        specificity selector.Specificity
+    }
+    rows := []row{
+        {"*", "*", selector.Specificity{0, 0, 0}},
+        {"a", "a", selector.Specificity{0, 0, 1}},
+        {"svg|a, *|*, |b", "svg|a, *|*, |b", selector.Specificity{0, 0, 1}},
+        {" a   b ", "a b", selector.Specificity{0, 0, 2}},
+        {"a>b+c~d", "a > b + c ~ d", selector.Specificity{0, 0, 4}},
+        {"col || td", "col || td", selector.Specificity{0, 0, 2}},
+        {"#a#b", "#a#b", selector.Specificity{2, 0, 0}},
+        {".a.b", ".a.b", selector.Specificity{0, 2, 0}},
+        {"[a]", "[a]", selector.Specificity{0, 1, 0}},
+        {"[ ns|a |= b ]", `[ns|a|="b"]`, selector.Specificity{0, 1, 0}},
+        {"[*|a='b' S]", `[*|a="b" s]`, selector.Specificity{0, 1, 0}},
+        {"a:hover::before", "a:hover::before", selector.Specificity{0, 1, 2}},
+        {"a:after", "a::after", selector.Specificity{0, 0, 2}},
+        {":is(#a, .b) c", ":is(#a, .b) c", selector.Specificity{1, 0, 1}},
+        {":IS(a, !, .b)", ":IS(a, !, .b)", selector.Specificity{0, 1, 0}},
+        {":not(a.b, c)", ":not(a.b, c)", selector.Specificity{0, 1, 1}},
+        {":where(#a) c", ":where(#a) c", selector.Specificity{0, 0, 1}},
+        {"a:has(> img, + .b)", "a:has(> img, + .b)", selector.Specificity{0, 1, 1}},
+        {":nth-child(2n+1)", ":nth-child(2n+1)", selector.Specificity{0, 1, 0}},
+        {":nth-child(2n+1 of #a)", ":nth-child(2n+1 of #a)", selector.Specificity{1, 1, 0}},
+        {"::part(foo)", "::part(foo)", selector.Specificity{0, 0, 1}},
+        {"a\\ b.\\31 c", "a\\ b.\\31 c", selector.Specificity{0, 1, 1}},
+        {"&.a > b&", "&.a > b&", selector.Specificity{0, 1, 1}},
+    }
+
+    for _, r := range rows {
+        list, err := selector.ParseString(r.input)
+        if err != nil {
+            t.Errorf("%q: unexpected error %v", r.input, err)
+            continue
+        }
+        if list.String() != r.expected {
+            t.Errorf("%q: got %q, expected %q", r.input, list.String(), r.expected)
+        }
+        if list.Specificity() != r.specificity {
+            t.Errorf("%q: got specificity %s, expected %s", r.input, list.Specificity(), r.specificity)
+        }
+    }
+}
+
+func TestParseString_errors(t *testing.T) {
+    type row struct {
+        input string
+        err error
+    }
+    rows := []row{
+        {"", selector.ErrEmpty},
+        {"a,", selector.ErrEmpty},
+        {"a, , b", selector.ErrEmpty},
+        {"a >", selector.ErrUnexpectedEOF},
+        {"a > > b", selector.ErrSyntax},
+        {"#1", selector.ErrSyntax},
+        {".", selector.ErrUnexpectedEOF},
+        {"[a", selector.ErrUnexpectedEOF},
+        {"[a=]", selector.ErrSyntax},
+        {"[a=b c]", selector.ErrSyntax},
+        {"a:", selector.ErrUnexpectedEOF},
+        {":not(a, !)", selector.ErrSyntax},
+        {":not(a", selector.ErrUnexpectedEOF},
+        {"a{}", selector.ErrSyntax},
+        {"a!", selector.ErrSyntax},
+    }
+
+    for _, r := range rows {
+        _, err := selector.ParseString(r.input)
+        if !errors.Is(err, r.err) {
+            t.Errorf("%q: got error %v, expected %v", r.input, err, r.err)
+        }
+    }
+}
+
+func TestSpecificity_Compare(t *testing.T) {
+    a := selector.Specificity{1, 0, 0}
+    b := selector.Specificity{0, 10, 10}
+    c := selector.Specificity{0, 10, 11}
+    if a.Compare(b) != 1 { t.Errorf("expected %s > %s", a, b) }
+    if b.Compare(c) != -1 { t.Errorf("expected %s < %s", b, c) }
+    if c.Compare(c) != 0 { t.Errorf("expected %s = %s", c, c) }
+}
@@ -0,0 +1,261 @@
+package supports
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/selector"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/css/vars"
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+var (
+    ErrSyntax        = fmt.Errorf("invalid supports condition syntax")
+    ErrUnexpectedEOF = fmt.Errorf("unexpected end of supports condition")
+)
+
+type errSyntax struct {
+    err error
+    at token.Position
+}
+
+func (e errSyntax) Unwrap() error {
+    return e.err
+}
+
+func (e errSyntax) Error() string {
+    return fmt.Sprintf("error at %s: %s", e.at, e.err.Error())
+}
+
+// ParseString parses a supports condition from a string.
+func ParseString(s string) (Condition, error) {
+    k := tokenizer.New(strings.NewReader(s))
+    var tokens []token.Token
+    for {
+        t := k.Next()
+        if t.Is(token.TypeEOF) { break }
+        tokens = append(tokens, t)
+    }
+    if len(k.Errors()) > 0 {
+        return Condition{}, fmt.Errorf("parse errors: %+v", k.Errors())
+    }
+    return Parse(tokens)
+}
+
+// Parse parses a supports condition from a sequence of tokens. The sequence
+// should not include a token.EOF().
+//
+// If the condition is invalid, an error is returned. As required by the
+// specification, an @supports rule with an invalid condition should be
+// ignored entirely.
+func Parse(tokens []token.Token) (Condition, error) {
+    p := &parser{tokens: tokens}
+    c, err := p.condition()
+    if err != nil { return Condition{}, err }
+    if err := p.expectEOF(); err != nil { return Condition{}, err }
+    return c, nil
+}
+
+func isOpen(t token.Token) bool {
+    return t.Is(token.TypeFunction) || t.Is(token.TypeLeftParen) ||
+        t.Is(token.TypeLeftSquareBracket) || t.Is(token.TypeLeftCurlyBracket)
+}
+
+func isClose(t token.Token) bool {
+    return t.Is(token.TypeRightParen) ||
+        t.Is(token.TypeRightSquareBracket) || t.Is(token.TypeRightCurlyBracket)
+}
+
+// trim removes leading and trailing whitespace tokens.
+func trim(tokens []token.Token) []token.Token {
+    for (len(tokens) > 0) && tokens[0].Is(token.TypeWhitespace) {
+        tokens = tokens[1:]
+    }
+    for (len(tokens) > 0) && tokens[len(tokens) - 1].Is(token.TypeWhitespace) {
+        tokens = tokens[:len(tokens) - 1]
+    }
+    return tokens
+}
+
+type parser struct {
+    tokens []token.Token
+    pos int
+}
+
+// peek returns the next token, or EOF.
+func (p *parser) peek() token.Token {
+    if p.pos >= len(p.tokens) { return token.EOF() }
+    return p.tokens[p.pos]
+}
+
+func (p *parser) next() token.Token {
+    t := p.peek()
+    if p.pos < len(p.tokens) { p.pos++ }
+    return t
+}
+
+func (p *parser) skipWS() {
+    for p.peek().Is(token.TypeWhitespace) { p.pos++ }
+}
+
+// peekKeyword returns the lower case value of the next token, after
+// whitespace, if it is an identifier.
+func (p *parser) peekKeyword() string {
+    p.skipWS()
+    t := p.peek()
+    if !t.Is(token.TypeIdent) { return "" }
+    return strings.ToLower(t.StringValue())
+}
+
+func (p *parser) errorf(err error) error {
+    t := p.peek()
+    if t.Is(token.TypeEOF) { err = ErrUnexpectedEOF }
+    return errSyntax{err, t.Position()}
+}
+
+func (p *parser) expectEOF() error {
+    p.skipWS()
+    if !p.peek().Is(token.TypeEOF) { return p.errorf(ErrSyntax) }
+    return nil
+}
+
+// condition parses a <supports-condition>:
+//
+//     <supports-condition> = not <supports-in-parens>
+//                          | <supports-in-parens> [ and <supports-in-parens> ]*
+//                          | <supports-in-parens> [ or <supports-in-parens> ]*
+func (p *parser) condition() (Condition, error) {
+    if p.peekKeyword() == "not" {
+        p.next()
+        x, err := p.inParens()
+        if err != nil { return x, err }
+        return Condition{Op: OpNot, Args: []Condition{x}}, nil
+    }
+
+    first, err := p.inParens()
+    if err != nil { return first, err }
+    args := []Condition{first}
+
+    var op Op
+    for {
+        start := p.pos
+        kw := p.peekKeyword()
+        if !((kw == "and") || (kw == "or")) {
+            p.pos = start
+            break
+        }
+        if (op != "") && (op != Op(kw)) { return first, p.errorf(ErrSyntax) }
+        op = Op(kw)
+        p.next()
+
+        x, err := p.inParens()
+        if err != nil { return x, err }
+        args = append(args, x)
+    }
+
+    if len(args) == 1 { return first, nil }
+    return Condition{Op: op, Args: args}, nil
+}
+
+// block returns the tokens of a block or function starting at the next
+// token, including the opening and closing tokens.
+func (p *parser) block() ([]token.Token, error) {
+    start := p.pos
+    depth := 0
+    for {
+        t := p.next()
+        switch {
+            case t.Is(token.TypeEOF):
+                return nil, errSyntax{ErrUnexpectedEOF, t.Position()}
+            case isOpen(t):
+                depth++
+            case isClose(t):
+                depth--
+        }
+        if depth == 0 { return p.tokens[start:p.pos], nil }
+    }
+}
+
+// inParens parses a <supports-in-parens>:
+//
+//     <supports-in-parens>   = ( <supports-condition> )
+//                            | <supports-feature>
+//                            | <general-enclosed>
+//     <supports-feature>     = <supports-selector-fn> | <supports-decl>
+//     <supports-selector-fn> = selector( <complex-selector> )
+//     <supports-decl>        = ( <declaration> )
+func (p *parser) inParens() (Condition, error) {
+    p.skipWS()
+    t := p.peek()
+    if !(t.Is(token.TypeLeftParen) || t.Is(token.TypeFunction)) {
+        return Condition{}, p.errorf(ErrSyntax)
+    }
+    block, err := p.block()
+    if err != nil { return Condition{}, err }
+    unknown := Condition{Op: OpUnknown, Tokens: block}
+    inner := block[1:len(block) - 1]
+
+    if t.Is(token.TypeFunction) {
+        if !strings.EqualFold(t.StringValue(), "selector") { return unknown, nil }
+        c := Condition{Op: OpSelector, Tokens: block}
+        if l, err := selector.Parse(trim(inner)); (err == nil) && (len(l) == 1) {
+            c.Selector = maybe.Some(l[0])
+        }
+        return c, nil
+    }
+
+    q := &parser{tokens: inner}
+    if c, err := q.condition(); (err == nil) && (q.expectEOF() == nil) {
+        return c, nil
+    }
+
+    if d, ok := parseDeclaration(inner); ok {
+        return Condition{Op: OpDeclaration, Declaration: d}, nil
+    }
+    return unknown, nil
+}
+
+// parseDeclaration parses the contents of a <supports-decl>, which is a
+// property name, a colon, and a value, optionally followed by "!important".
+func parseDeclaration(tokens []token.Token) (Declaration, bool) {
+    var d Declaration
+    tokens = trim(tokens)
+    if (len(tokens) == 0) || !tokens[0].Is(token.TypeIdent) { return d, false }
+    d.Property = tokens[0].StringValue()
+    if !vars.IsCustomProperty(d.Property) { d.Property = strings.ToLower(d.Property) }
+
+    tokens = trim(tokens[1:])
+    if (len(tokens) == 0) || !tokens[0].Is(token.TypeColon) { return d, false }
+    value := trim(tokens[1:])
+
+    // "!" followed by "important", with optional whitespace between.
+    if n := len(value); (n >= 2) && value[n - 1].Is(token.TypeIdent) &&
+        strings.EqualFold(value[n - 1].StringValue(), "important") {
+        rest := trim(value[:n - 1])
+        if (len(rest) > 0) && rest[len(rest) - 1].Is(token.TypeDelim) &&
+            (rest[len(rest) - 1].Delim() == '!') {
+            value, d.Important = trim(rest[:len(rest) - 1]), true
+        }
+    }
+
+    // a declaration value cannot contain unmatched closing brackets,
+    // top-level semicolons, or bad strings or URLs.
+    depth := 0
+    for _, t := range value {
+        switch {
+            case isOpen(t):
+                depth++
+            case isClose(t):
+                depth--
+                if depth < 0 { return d, false }
+            case t.Is(token.TypeSemicolon) && (depth == 0): fallthrough
+            case t.Is(token.TypeBadString) || t.Is(token.TypeBadUrl):
+                return d, false
+        }
+    }
+
+    d.Value = value
+    return d, true
+}
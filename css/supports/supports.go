@@ -0,0 +1,181 @@
+// Package supports parses and evaluates CSS feature queries, such as the
+// prelude of an @supports rule, based on [CSS Conditional Rules Module Level
+// 3] (W3C Candidate Recommendation Snapshot), 13 January 2022, and the
+// selector() function of [CSS Conditional Rules Module Level 4].
+//
+// Supports conditions are parsed from a sequence of tokens (see
+// [css/tokenizer]), for example the prelude of an @supports rule produced by
+// [css/parser] (see [css/parser/item.Tokens]). For example:
+//
+//     (display: grid) and (not (display: inline-grid))
+//     selector(a > b) or (--custom: value)
+//
+// Whether a declaration or selector is supported depends on the user agent,
+// and so a condition is evaluated with an [Oracle] that decides this.
+//
+// Unlike media queries, a supports condition uses two-valued logic: any
+// other syntax enclosed in parentheses, or a function other than selector()
+// (the <general-enclosed> production), is parsed successfully, but is
+// always false.
+//
+// [CSS Conditional Rules Module Level 3]: https://www.w3.org/TR/css-conditional-3/
+// [CSS Conditional Rules Module Level 4]: https://www.w3.org/TR/css-conditional-4/
+//
+// This software includes material derived from CSS Conditional Rules Module
+// Level 3, W3C Candidate Recommendation Snapshot, 13 January 2022. Copyright
+// © 2022 W3C® (MIT, ERCIM, Keio, Beihang). See LICENSE-PARTS.txt and
+// TRADEMARKS.md.
+package supports
+
+import (
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/selector"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/css/vars"
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+type Op string
+const (
+    OpDeclaration = Op("declaration") // a declaration, e.g. "(display: grid)"
+    OpSelector    = Op("selector")    // a selector, e.g. "selector(a > b)"
+    OpNot         = Op("not")         // the single Arg, negated
+    OpAnd         = Op("and")         // all Args
+    OpOr          = Op("or")          // any Args
+    OpUnknown     = Op("unknown")     // a <general-enclosed>, always false
+)
+
+// Condition is a supports condition, which is a tree of declarations and
+// selectors joined by "not", "and", and "or".
+type Condition struct {
+    Op Op
+
+    // Declaration is the declaration of an OpDeclaration condition.
+    Declaration Declaration
+
+    // Selector is the complex selector of an OpSelector condition, or
+    // Nothing if the argument of the selector() function is not a valid
+    // complex selector (in which case the condition is always false).
+    Selector maybe.M[selector.Complex]
+
+    // Args are the children of an OpNot, OpAnd, or OpOr condition.
+    Args []Condition
+
+    // Tokens are the original tokens of an OpSelector or OpUnknown
+    // condition, including the enclosing parentheses or function.
+    Tokens []token.Token
+}
+
+// Declaration is a declaration tested by a supports condition, for example
+// "(display: grid)".
+type Declaration struct {
+    // Property is the property name, in lower case, unless it is a custom
+    // property (see [vars.IsCustomProperty]), which is case-sensitive.
+    Property string
+
+    // Value is the value of the declaration, without leading or trailing
+    // whitespace, and without any "!important".
+    Value []token.Token
+
+    // Important is true if the declaration is marked "!important", which
+    // does not change whether it is supported.
+    Important bool
+}
+
+// Oracle decides if the user agent supports a declaration or selector.
+//
+// A declaration is supported if the user agent supports the property, and
+// the value would be successfully parsed for that property. A selector is
+// supported if the user agent supports every part of it (for example, every
+// pseudo-class).
+type Oracle interface {
+    Declaration(d Declaration) bool
+    Selector(s selector.Complex) bool
+}
+
+// OracleFuncs implements an [Oracle] with a function for each method. A nil
+// function supports nothing.
+type OracleFuncs struct {
+    DeclarationFunc func(d Declaration) bool
+    SelectorFunc    func(s selector.Complex) bool
+}
+
+func (o OracleFuncs) Declaration(d Declaration) bool {
+    if o.DeclarationFunc == nil { return false }
+    return o.DeclarationFunc(d)
+}
+
+func (o OracleFuncs) Selector(s selector.Complex) bool {
+    if o.SelectorFunc == nil { return false }
+    return o.SelectorFunc(s)
+}
+
+// Eval evaluates a supports condition, using the oracle to decide if each
+// declaration or selector is supported.
+//
+// A declaration of a custom property, such as "(--x: 1)", is always
+// supported, without consulting the oracle.
+func (c Condition) Eval(o Oracle) bool {
+    switch c.Op {
+        case OpDeclaration:
+            if vars.IsCustomProperty(c.Declaration.Property) { return true }
+            return o.Declaration(c.Declaration)
+        case OpSelector:
+            s, ok := c.Selector.Unpack()
+            return ok && o.Selector(s)
+        case OpNot:
+            return !c.Args[0].Eval(o)
+        case OpAnd:
+            for _, x := range c.Args {
+                if !x.Eval(o) { return false }
+            }
+            return true
+        case OpOr:
+            for _, x := range c.Args {
+                if x.Eval(o) { return true }
+            }
+            return false
+        default:
+            return false
+    }
+}
+
+func (c Condition) String() string {
+    return c.serialize(false)
+}
+
+// serialize writes a condition. If nested, a "not", "and", or "or" condition
+// is wrapped in parentheses.
+func (c Condition) serialize(nested bool) string {
+    switch c.Op {
+        case OpDeclaration:
+            return "(" + c.Declaration.String() + ")"
+        case OpNot:
+            s := "not " + c.Args[0].serialize(true)
+            if nested { s = "(" + s + ")" }
+            return s
+        case OpAnd: fallthrough
+        case OpOr:
+            parts := make([]string, len(c.Args))
+            for i, x := range c.Args {
+                parts[i] = x.serialize(true)
+            }
+            s := strings.Join(parts, " " + string(c.Op) + " ")
+            if nested { s = "(" + s + ")" }
+            return s
+        case OpSelector:
+            if s, ok := c.Selector.Unpack(); ok { return "selector(" + s.String() + ")" }
+            fallthrough
+        default:
+            return tokenizer.Serialize(c.Tokens)
+    }
+}
+
+func (d Declaration) String() string {
+    s := tokenizer.Serialize([]token.Token{token.Ident(d.Property), token.Colon()})
+    if len(d.Value) > 0 { s += " " + tokenizer.Serialize(d.Value) }
+    if d.Important { s += " !important" }
+    return s
+}
@@ -0,0 +1,137 @@
+package supports_test
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/selector"
+    "github.com/tawesoft/golib/v2/css/supports"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+)
+
+// oracle supports the display property with the values "block" and "grid",
+// and any selector without a pseudo-element.
+var oracle = supports.OracleFuncs{
+    DeclarationFunc: func(d supports.Declaration) bool {
+        if d.Property != "display" { return false }
+        switch tokenizer.Serialize(d.Value) {
+            case "block": fallthrough
+            case "grid":
+                return true
+        }
+        return false
+    },
+    SelectorFunc: func(s selector.Complex) bool {
+        for _, c := range s {
+            for _, x := range c.Simples {
+                if x.Kind == selector.KindPseudoElement { return false }
+            }
+        }
+        return true
+    },
+}
+
+func ExampleParseString() {
+    c, err := supports.ParseString("(display: grid) and (not (display: inline-grid))")
+    if err != nil { panic(err) }
+    fmt.Println(c)
+    fmt.Println(c.Eval(oracle))
+
+    // Output:
+    // (display: grid) and (not (display: inline-grid))
+    // true
+}
+
+func TestParseString(t *testing.T) {
+    type row struct {
+        input string
+        expected string
+        err error
+    }
+    rows := []row{
+        {"(display: grid)",                       "(display: grid)",                       nil},
+        {"( DISPLAY : grid )",                    "(display: grid)",                       nil},
+        {"(--Custom:)",                           "(--Custom:)",                           nil},
+        {"(display: grid !important)",            "(display: grid !important)",            nil},
+        {"(display: grid ! IMPORTANT)",           "(display: grid !important)",            nil},
+        {"not (display: grid)",                   "not (display: grid)",                   nil},
+        {"(a: b) and (c: d) and (e: f)",          "(a: b) and (c: d) and (e: f)",          nil},
+        {"(a: b) or ((c: d) and (e: f))",         "(a: b) or ((c: d) and (e: f))",         nil},
+        {"((a: b))",                              "(a: b)",                                nil},
+        {"(not (a: b))",                          "not (a: b)",                            nil},
+        {"selector(a > b)",                       "selector(a > b)",                       nil},
+        {"SELECTOR( a>b )",                       "selector(a > b)",                       nil},
+        {"selector(a, b)",                        "selector(a, b)",                        nil},
+        {"selector(:::)",                         "selector(:::)",                         nil},
+        {"foo(bar) or (a: b)",                    "foo(bar) or (a: b)",                    nil},
+        {"(foo bar)",                             "(foo bar)",                             nil},
+        {"(a: b;)",                               "(a: b;)",                               nil},
+        {"(a: b) and (c: d) or (e: f)",           "",                                      supports.ErrSyntax},
+        {"not (a: b) and (c: d)",                 "",                                      supports.ErrSyntax},
+        {"(a: b) and",                            "",                                      supports.ErrUnexpectedEOF},
+        {"(a: b) and(c: d)",                      "",                                      supports.ErrSyntax},
+        {"display: grid",                         "",                                      supports.ErrSyntax},
+        {"(a: b",                                 "",                                      supports.ErrUnexpectedEOF},
+        {"",                                      "",                                      supports.ErrUnexpectedEOF},
+    }
+    for _, r := range rows {
+        c, err := supports.ParseString(r.input)
+        if r.err == nil && err != nil {
+            t.Errorf("%q: unexpected error %v", r.input, err)
+            continue
+        } else if !errors.Is(err, r.err) {
+            t.Errorf("%q: expected error %v but got %v", r.input, r.err, err)
+            continue
+        }
+        if err != nil { continue }
+
+        if c.String() != r.expected {
+            t.Errorf("%q: expected %q but got %q", r.input, r.expected, c.String())
+        }
+    }
+}
+
+func TestCondition_Eval(t *testing.T) {
+    type row struct {
+        input string
+        expected bool
+    }
+    rows := []row{
+        {"(display: grid)",                              true},
+        {"(display: GRID)",                              false},
+        {"(display: inline-grid)",                       false},
+        {"(color: red)",                                 false},
+        {"(--anything: at all)",                         true},
+        {"(display: block !important)",                  true},
+        {"not (display: grid)",                          false},
+        {"not (color: red)",                             true},
+        {"(display: grid) and (display: block)",         true},
+        {"(display: grid) and (color: red)",             false},
+        {"(color: red) or (display: block)",             true},
+        {"(color: red) or (float: left)",                false},
+        {"((color: red) or (display: grid)) and (--x: 1)", true},
+        {"selector(a > b)",                              true},
+        {"selector(a::before)",                          false},
+        {"not selector(:::)",                            true},
+        {"selector(a, b)",                               false},
+        {"foo(display: grid)",                           false},
+        {"not foo(display: grid)",                       true},
+        {"(foo bar) or (display: grid)",                 true},
+    }
+    for _, r := range rows {
+        c, err := supports.ParseString(r.input)
+        if err != nil {
+            t.Errorf("%q: unexpected error %v", r.input, err)
+            continue
+        }
+        if got := c.Eval(oracle); got != r.expected {
+            t.Errorf("%q: expected %v but got %v", r.input, r.expected, got)
+        }
+    }
+
+    c, _ := supports.ParseString("(display: grid) or selector(a)")
+    if c.Eval(supports.OracleFuncs{}) {
+        t.Errorf("expected the zero OracleFuncs to support nothing")
+    }
+}
@@ -3,17 +3,32 @@
 // 24 December 2021.
 //
 // The main elements of this package are the [New] function, which returns a
-// new [Tokenizer], and the [Tokenizer.Next] method.
+// new [Tokenizer], and the [Tokenizer.Next] method. A [Serializer] writes
+// tokens back to CSS text.
+//
+// A Tokenizer returned by [NewWithOptions] can also, optionally, produce
+// tokens that are not part of the specification: a <comment-token> for each
+// comment, which is useful for tools that must preserve comments, and the
+// <unicode-range-token> of earlier drafts of the specification, which is
+// useful for parsing the unicode-range descriptor of a @font-face rule. It
+// can also limit the input that it accepts, for tokenizing untrusted input.
 //
 // This package also exposes several low-level "Consume" functions, which
 // implement specific algorithms in the CSS specification. Note that all
-// "Consume" functions may panic on I/O error. The [Tokenizer.Next] method
-// catches these panics. Also note that all "Consume" functions operate on a
-// steam of filtered code points (see
+// "Consume" functions may panic on I/O error, so should be given a reader
+// that cannot fail, such as a [strings.Reader]. A Tokenizer does not panic:
+// an I/O error ends the stream of tokens, and is reported as a fatal
+// [ParseError]. Also note that all "Consume" functions operate on a
+// stream of filtered code points (see
 // https://www.w3.org/TR/css-syntax-3/#input-preprocessing), not raw input.
 // This is implemented by [css/tokenizer/filter.Transform] and automatically
 // handled by a [New] Tokenizer.
 //
+// Like a web browser, a Tokenizer is lenient: on a parse error, such as an
+// unterminated string, it recovers as described by the specification, and
+// continues. Each error is recorded as a [ParseError], retrieved by
+// [Tokenizer.ParseErrors] once the stream has ended.
+//
 // [CSS Syntax Module Level 3]: https://www.w3.org/TR/css-syntax-3/
 //
 // Disclaimer: although this software runs against a thorough and diverse set
@@ -26,7 +41,6 @@
 // (MIT, ERCIM, Keio, Beihang). See LICENSE-PARTS.txt and TRADEMARKS.md.
 package tokenizer
 
-// TODO: add serializer (has special rules to prevent ambiguous grammar).
 // TODO add more tests from
 // https://github.com/web-platform-tests/wpt/blob/master/css/css-syntax/
 
@@ -43,68 +57,311 @@ import (
 
     "github.com/tawesoft/golib/v2/css/tokenizer/filter"
     "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/iter"
     "github.com/tawesoft/golib/v2/must"
     "github.com/tawesoft/golib/v2/text/runeio"
     "golang.org/x/text/transform"
 )
 
 const maxRuneLookahead = 3
-const maxTokenLookahead = 7
 
 var (
     ErrUnexpectedEOF = fmt.Errorf("unexpected end of file")
     ErrUnexpectedLinebreak = fmt.Errorf("unexpected line break")
     ErrUnexpectedInput = fmt.Errorf("unexpected input")
     ErrBadUrl = fmt.Errorf("invalid URL syntax")
+    ErrLimit = fmt.Errorf("limit exceeded") // matches any LimitError
+)
+
+// Limit identifies one of the limits that may be set by [Options].
+type Limit string
+const (
+    LimitTokenLength = Limit("token length")
+    LimitEscapes     = Limit("escape count")
+    LimitDepth       = Limit("nesting depth")
 )
 
+// LimitError is the Err of a fatal [ParseError] reported when the input
+// exceeds a limit set by [Options]. It matches ErrLimit with [errors.Is].
+type LimitError struct {
+    Limit Limit
+    Max int // the configured maximum
+}
+
+func (e LimitError) Error() string {
+    return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Max)
+}
+
+func (e LimitError) Is(target error) bool {
+    return target == ErrLimit
+}
+
 type Tokenizer struct {
     rdr *runeio.Reader
-    errors []error
+    src *eofReader
+    errors []ParseError
+    handler func(ParseError)
+    options Options
+    pending []error // parse errors in the current token
+    depth int // unclosed blocks and functions
     eof bool
 
     // pushback buffer
-    buf [maxTokenLookahead]token.Token
-    bufTop int
+    buf []token.Token
+}
+
+// eofReader reads from an underlying reader, but returns io.EOF in place of
+// any other error, so that the "Consume" functions never see (and never
+// panic on) an I/O error. The first such error is kept in err.
+//
+// If stop is not zero, eofReader also returns io.EOF, without recording an
+// error, once that many bytes have been read in total. If escapes is not nil,
+// it records the offset of each U+005C REVERSE SOLIDUS (\) read.
+type eofReader struct {
+    rdr io.Reader
+    err error
+    n, stop int64
+    escapes *[]int64
 }
 
-func reader(r io.Reader) *runeio.Reader {
+func (r *eofReader) Read(p []byte) (int, error) {
+    if r.err != nil { return 0, io.EOF }
+    if r.stop > 0 {
+        if r.n >= r.stop { return 0, io.EOF }
+        if int64(len(p)) > r.stop - r.n { p = p[:r.stop - r.n] }
+    }
+    n, err := r.rdr.Read(p)
+    if r.escapes != nil {
+        for i := 0; i < n; i++ {
+            if p[i] == '\\' { *r.escapes = append(*r.escapes, r.n + int64(i)) }
+        }
+    }
+    r.n += int64(n)
+    if (err != nil) && (err != io.EOF) {
+        r.err = err
+        return n, io.EOF
+    }
+    return n, err
+}
+
+func reader(r io.Reader) (*runeio.Reader, *eofReader) {
     br := bufio.NewReader(r)
-    rdr := runeio.NewReader(transform.NewReader(br, filter.Transformer()))
+    src := &eofReader{rdr: transform.NewReader(br, filter.Transformer())}
+    rdr := runeio.NewReader(src)
     rdr.Buffer(nil, utf8.UTFMax *maxRuneLookahead)
-    return rdr
+    return rdr, src
 }
 
 func New(r io.Reader) *Tokenizer {
+    rdr, src := reader(r)
     return &Tokenizer{
-        rdr: reader(r),
+        rdr: rdr,
+        src: src,
     }
 }
 
-// Errors reports parse errors.
-func (z *Tokenizer) Errors() []error {
+// NewStreaming returns a new [Tokenizer] that reads incrementally from r,
+// like [New], but that passes each parse error to the function f as soon as
+// it is found, instead of recording it to be returned by
+// [Tokenizer.ParseErrors].
+//
+// This allows arbitrarily long input to be tokenized without also keeping a
+// growing list of parse errors in memory. As with any Tokenizer, most parse
+// errors are recovered from as described in the specification, and
+// tokenizing continues.
+func NewStreaming(r io.Reader, f func(e ParseError)) *Tokenizer {
+    rdr, src := reader(r)
+    return &Tokenizer{
+        rdr: rdr,
+        src: src,
+        handler: f,
+    }
+}
+
+// Options configure a [Tokenizer] returned by [NewWithOptions]. The zero
+// value tokenizes exactly as described by the specification, like [New].
+type Options struct {
+    // Comments, if true, produces a <comment-token> for each comment (see
+    // [token.Comment]), instead of discarding it.
+    Comments bool
+
+    // UnicodeRange, if true, produces a <unicode-range-token> (see
+    // [token.UnicodeRange]) for input such as "U+0025-00FF" or "u+4??",
+    // as described by the 20 February 2014 Candidate Recommendation of the
+    // CSS Syntax Module Level 3, instead of an <ident-token> followed by
+    // other tokens.
+    UnicodeRange bool
+
+    // Handler, if not nil, is passed each parse error as soon as it is
+    // found, as with [NewStreaming].
+    Handler func(ParseError)
+
+    // MaxTokenLength, MaxEscapes, and MaxDepth, if not zero, limit the input
+    // that is accepted, protecting a service that tokenizes untrusted
+    // stylesheets from using unbounded time or memory. When a limit is
+    // exceeded, the tokenizer reports a fatal [ParseError], with a
+    // [LimitError], and returns only token.EOF() after that.
+
+    // MaxTokenLength is the maximum length, in bytes of (preprocessed,
+    // UTF-8) input, of any one token, including a whitespace token. A
+    // discarded comment may be any length.
+    MaxTokenLength int
+
+    // MaxEscapes is the maximum number of escapes in any one token. Each
+    // U+005C REVERSE SOLIDUS (\) in the input of a token counts as one, so
+    // that an escaped reverse solidus counts as two.
+    MaxEscapes int
+
+    // MaxDepth is the maximum nesting depth of blocks and functions: the
+    // number of <(-token>, <[-token>, <{-token>, and <function-token> not
+    // yet closed by a <)-token>, <]-token>, or <}-token>.
+    MaxDepth int
+}
+
+// NewWithOptions returns a new [Tokenizer] that reads incrementally from r,
+// like [New], but configured by the given options.
+func NewWithOptions(r io.Reader, o Options) *Tokenizer {
+    rdr, src := reader(r)
+    if o.MaxEscapes > 0 { src.escapes = new([]int64) }
+    return &Tokenizer{
+        rdr: rdr,
+        src: src,
+        handler: o.Handler,
+        options: o,
+    }
+}
+
+// ParseErrors returns the parse errors recorded so far, in the order they
+// were found.
+//
+// A Tokenizer returned by [NewStreaming], or by [NewWithOptions] with a
+// Handler, never records any errors here.
+func (z *Tokenizer) ParseErrors() []ParseError {
     return z.errors
 }
 
-type readError struct {
-    err error
-    offset runeio.Offset
+// Errors is like [Tokenizer.ParseErrors], but returns each [ParseError] as
+// an error, or nil if there are none.
+func (z *Tokenizer) Errors() []error {
+    if len(z.errors) == 0 { return nil }
+    errs := make([]error, len(z.errors))
+    for i, e := range z.errors {
+        errs[i] = e
+    }
+    return errs
+}
+
+// ParseError describes a parse error, or an I/O error, encountered while
+// tokenizing. For example, an unterminated string or comment, or a "\"
+// that does not start a valid escape.
+//
+// Unless the error is Fatal, tokenizing recovers and continues.
+type ParseError struct {
+    // Err is the underlying error, for example ErrUnexpectedEOF.
+    Err error
+
+    // Position is the position of the token that contains the error, or of
+    // the comment, if the error is in a comment. For an I/O error, this is
+    // the position in the input stream where the error was encountered.
+    Position token.Position
+
+    // Token is the type of token that the tokenizer produced when it
+    // recovered from the error, for example token.TypeBadString for a string
+    // that contains an unescaped line break. This is the empty string for an
+    // error in a comment that was discarded, or for a Fatal error.
+    Token token.Type
+
+    // Fatal is true if the tokenizer could not recover from the error, for
+    // example an I/O error. Every following token is token.EOF(). Any token
+    // produced immediately before a fatal error may be incomplete.
+    Fatal bool
 }
 
-func (e readError) Error() string {
-    return fmt.Sprintf("parse error at %+v: %s", e.offset, e.err)
+func (e ParseError) Error() string {
+    return fmt.Sprintf("parse error at %s: %s", e.Position, e.Err)
 }
 
-func (e readError) Unwrap() error {
-    return e.err
+func (e ParseError) Unwrap() error {
+    return e.Err
 }
 
+// error records a parse error in the current token.
 func (z *Tokenizer) error(err error) {
-    rerr := readError{
-        err: err,
-        offset: z.rdr.Offset(),
+    z.pending = append(z.pending, err)
+}
+
+// report reports any parse errors recorded in the current token, of the
+// given type, and the given extra error (if not nil), at the given position.
+func (z *Tokenizer) report(pos token.Position, t token.Type, err error) {
+    if err != nil { z.pending = append(z.pending, err) }
+    for _, err := range z.pending {
+        z.emit(ParseError{
+            Err: err,
+            Position: pos,
+            Token: t,
+        })
+    }
+    z.pending = z.pending[0:0]
+}
+
+func (z *Tokenizer) emit(e ParseError) {
+    if z.handler != nil {
+        z.handler(e)
+    } else {
+        z.errors = append(z.errors, e)
     }
-    z.errors = append(z.errors, rerr)
+}
+
+// fatal ends the stream of tokens, reporting a fatal error at the given
+// position, and returns token.EOF().
+func (z *Tokenizer) fatal(pos token.Position, err error) token.Token {
+    z.pending = z.pending[0:0]
+    z.emit(ParseError{
+        Err: err,
+        Position: pos,
+        Fatal: true,
+    })
+    z.eof = true
+    return token.EOF().WithPosition(pos)
+}
+
+// limit returns a LimitError if the token t, read from the input between the
+// byte offsets start and end, exceeds any limit set by the options.
+func (z *Tokenizer) limit(start, end int64, t token.Token) error {
+    o := z.options
+
+    if (o.MaxTokenLength > 0) && (end - start > int64(o.MaxTokenLength)) {
+        return LimitError{LimitTokenLength, o.MaxTokenLength}
+    }
+
+    if o.MaxEscapes > 0 {
+        escapes := *z.src.escapes
+        count, i := 0, 0
+        for ; (i < len(escapes)) && (escapes[i] < end); i++ {
+            if escapes[i] >= start { count++ }
+        }
+        *z.src.escapes = append(escapes[:0], escapes[i:]...)
+        if count > o.MaxEscapes {
+            return LimitError{LimitEscapes, o.MaxEscapes}
+        }
+    }
+
+    switch t.Type() {
+        case token.TypeFunction: fallthrough
+        case token.TypeLeftParen: fallthrough
+        case token.TypeLeftSquareBracket: fallthrough
+        case token.TypeLeftCurlyBracket:
+            z.depth++
+            if (o.MaxDepth > 0) && (z.depth > o.MaxDepth) {
+                return LimitError{LimitDepth, o.MaxDepth}
+            }
+        case token.TypeRightParen: fallthrough
+        case token.TypeRightSquareBracket: fallthrough
+        case token.TypeRightCurlyBracket:
+            if z.depth > 0 { z.depth-- }
+    }
+
+    return nil
 }
 
 // NextExcept is like [Tokenizer.Next] however any tokens matching the given
@@ -125,48 +382,84 @@ func (z *Tokenizer) NextExcept(types ... token.Type) (result token.Token) {
     return
 }
 
-// Push places a token back on a pushback buffer (first in, first out) so that
-// it is returned by Next() before advancing the input stream. This has a
-// limited capacity, and will panic if exceeded.
-func (z *Tokenizer) Push(x token.Token) {
-    if z.bufTop >= maxTokenLookahead {
-        panic(fmt.Errorf("tokenizer: pushback buffer overflow"))
+// Tokens returns an iterator over the remaining tokens, ending before
+// token.EOF(). Tokens are read from the input stream only as the iterator is
+// advanced.
+func (z *Tokenizer) Tokens() iter.It[token.Token] {
+    return func() (token.Token, bool) {
+        t := z.Next()
+        if t.Is(token.TypeEOF) { return t, false }
+        return t, true
     }
-    z.buf[z.bufTop] = x
-    z.bufTop++
+}
+
+// Push places a token back on a pushback buffer (last in, first out) so that
+// it is returned by Next() before advancing the input stream.
+func (z *Tokenizer) Push(x token.Token) {
+    z.buf = append(z.buf, x)
 }
 
 // Next returns the next token from the input stream. Once the stream has
-// ended, it returns token.EOF().
+// ended, it returns token.EOF(). Each token records its position in the input
+// stream (see [token.Token.Position]).
 //
-// Check z.Errors() once the stream has ended, or at any point if you want to
-// fail-fast without recovering, to detect parse errors.
+// Check z.ParseErrors() once the stream has ended, or at any point if you
+// want to fail-fast without recovering, to detect parse errors.
 func (z *Tokenizer) Next() (result token.Token) {
-    if z.bufTop == 0 {
-        return z.next()
-    } else {
-        result = z.buf[z.bufTop - 1]
-        z.bufTop--
+    if n := len(z.buf); n > 0 {
+        result = z.buf[n - 1]
+        z.buf = z.buf[:n - 1]
         return result
     }
+    return z.next()
 }
 
 func (z *Tokenizer) next() (result token.Token) {
     if z.eof {
-        return token.EOF().WithPosition(position0(z.rdr.Offset()))
+        return token.EOF().WithPosition(position(z.rdr.Offset(), z.rdr.Offset()))
     }
 
-    // i/o and runtime panic handling
+    // The "Consume" functions never see an I/O error (see eofReader), so
+    // this only guards against a bug, which is still reported as a fatal
+    // error rather than crashing a lenient caller.
     defer func() {
         if r := recover(); r != nil {
-            z.error(r.(error))
-            z.eof = true
-            result = token.EOF()
+            err, ok := r.(error)
+            if !ok { err = fmt.Errorf("%v", r) }
+            result = z.fatal(position(z.rdr.Offset(), z.rdr.Offset()), err)
         }
     }()
 
-    err := ConsumeComments(z.rdr)
-    if err != nil { z.error(err) } // recovers
+    if !z.options.Comments {
+        z.src.stop = 0
+        start := z.rdr.Offset()
+        err := ConsumeComments(z.rdr)
+        if err != nil { z.report(position(start, z.rdr.Offset()), "", err) } // recovers
+    }
+
+    // Note that the "Consume" functions only push a code point back onto the
+    // reader to reconsume it at the start of a token, and otherwise peek
+    // ahead, so that the reader's offset is exact at the end of each token.
+    start := z.rdr.Offset()
+    if z.options.MaxTokenLength > 0 {
+        // stop reading soon after the limit, to bound memory use, but not so
+        // soon that lookahead at the end of a token of exactly the maximum
+        // length is affected.
+        z.src.stop = start.Byte + int64(z.options.MaxTokenLength) +
+            int64(utf8.UTFMax * (maxRuneLookahead + 1))
+    }
+    defer func() {
+        pos := position(start, z.rdr.Offset())
+        if err := z.limit(start.Byte, z.rdr.Offset().Byte, result); err != nil {
+            result = z.fatal(pos, err)
+            return
+        }
+        result = result.WithPosition(pos)
+        z.report(pos, result.Type(), nil)
+        if result.Is(token.TypeEOF) && (z.src.err != nil) {
+            result = z.fatal(position(z.rdr.Offset(), z.rdr.Offset()), z.src.err)
+        }
+    }()
 
     c := runeio.Must(z.rdr.Next())
     switch {
@@ -219,7 +512,7 @@ func (z *Tokenizer) next() (result token.Token) {
                 return token.Delim(c)
             }
         case c == ',': // U+002C COMMA (,)
-            return token.Comma().WithPosition(position1(z.rdr.Offset()))
+            return token.Comma()
         case c == '-': // U+002D HYPHEN-MINUS (-)
             // If the input stream starts with a number...
             var xs[2]rune
@@ -324,11 +617,25 @@ func (z *Tokenizer) next() (result token.Token) {
             return token.LeftCurlyBracket()
         case c == '}': // U+007D RIGHT CURLY BRACKET (})
             return token.RightCurlyBracket()
+        case (c == '/') && z.options.Comments && (runeio.Must(z.rdr.Peek()) == '*'):
+            // Not in the specification: reconsume the current input code
+            // point, consume a comment, and return it.
+            z.rdr.Push(c)
+            t, err := ConsumeComment(z.rdr)
+            if err != nil { z.error(err) }
+            return t
         case runeIsDigit(c):
             // Reconsume the current input code point,
             z.rdr.Push(c)
             // consume a numeric token, and return it.
             return ConsumeNumericToken(z.rdr)
+        case ((c == 'u') || (c == 'U')) && z.options.UnicodeRange && z.startsUnicodeRange():
+            // From the 2014 Candidate Recommendation: if the next two input
+            // code points are U+002B PLUS SIGN (+) followed by a hex digit or
+            // U+003F QUESTION MARK (?), consume the next input code point,
+            // consume a unicode-range token, and return it.
+            runeio.Must(z.rdr.Next())
+            return ConsumeUnicodeRangeToken(z.rdr)
         case runeIsIdentStartCodepoint(c):
             // Reconsume the current input code point,
             z.rdr.Push(c)
@@ -344,6 +651,14 @@ func (z *Tokenizer) next() (result token.Token) {
     }
 }
 
+// startsUnicodeRange returns true if the next two input code points are
+// U+002B PLUS SIGN (+) followed by a hex digit or U+003F QUESTION MARK (?).
+func (z *Tokenizer) startsUnicodeRange() bool {
+    var xs [2]rune
+    must.Result(z.rdr.PeekN(xs[:], 2))
+    return (xs[0] == '+') && (runeIsHexDigit(xs[1]) || (xs[1] == '?'))
+}
+
 // ConsumeComments consumes zero or more CSS comments.
 func ConsumeComments(rdr *runeio.Reader) error {
     for {
@@ -383,6 +698,93 @@ func ConsumeComments(rdr *runeio.Reader) error {
     return nil
 }
 
+// ConsumeComment consumes a single CSS comment, if the input stream starts
+// with one, and returns it as a <comment-token>. This is not part of the
+// specification, but consumes a comment in the same way as
+// [ConsumeComments]. The returned error is ErrUnexpectedEOF if the comment
+// is not terminated.
+//
+// If the input stream does not start with a comment, returns an
+// <EOF-token>, and consumes nothing.
+func ConsumeComment(rdr *runeio.Reader) (token.Token, error) {
+    var xs [2]rune
+    must.Result(rdr.PeekN(xs[:], 2))
+    if !((xs[0] == '/') && (xs[1] == '*')) { return token.EOF(), nil }
+    must.Check(rdr.Skip(2))
+
+    var sb strings.Builder
+    x := rune(0)
+    for {
+        y := runeio.Must(rdr.Next())
+        if x == '*' && y == '/' {
+            s := sb.String()
+            return token.Comment(s[:len(s) - 1]), nil
+        }
+        if y == runeio.RuneEOF {
+            return token.Comment(sb.String()), ErrUnexpectedEOF
+        }
+        sb.WriteRune(y)
+        x = y
+    }
+}
+
+// ConsumeUnicodeRangeToken consumes a unicode-range token, as described by
+// the 20 February 2014 Candidate Recommendation of the CSS Syntax Module
+// Level 3, and returns it. This assumes that the "U+" has already been
+// consumed, and that the next input code point is a hex digit or U+003F
+// QUESTION MARK (?).
+//
+// This does not check that the range is valid, i.e. that the start is not
+// greater than the end, or that the end is not greater than U+10FFFF.
+func ConsumeUnicodeRangeToken(rdr *runeio.Reader) token.Token {
+    var start, end rune
+
+    // Consume as many hex digits as possible, but no more than 6.
+    digits := 0
+    for ; digits < 6; digits++ {
+        x := runeio.Must(rdr.Peek())
+        if !runeIsHexDigit(x) { break }
+        runeio.Must(rdr.Next())
+        start = (start << 4) | hexValue(x)
+    }
+    end = start
+
+    // If less than 6 hex digits were consumed, consume as many U+003F
+    // QUESTION MARK (?) code points as possible, but no more than enough to
+    // make the total of hex digits and U+003F QUESTION MARK (?) code points
+    // equal to 6.
+    questions := 0
+    for ; digits + questions < 6; questions++ {
+        if runeio.Must(rdr.Peek()) != '?' { break }
+        runeio.Must(rdr.Next())
+        // Replace each "?" with 0 for the start, and F for the end.
+        start = start << 4
+        end = (end << 4) | 0xF
+    }
+    if questions > 0 {
+        return token.UnicodeRange(start, end)
+    }
+
+    // If the next 2 input code points are U+002D HYPHEN-MINUS (-) followed
+    // by a hex digit, then consume the next input code point, and consume as
+    // many hex digits as possible, but no more than 6, as the end of the
+    // range. Otherwise, the end of the range is the start.
+    var xs [2]rune
+    must.Result(rdr.PeekN(xs[:], 2))
+    if (xs[0] == '-') && runeIsHexDigit(xs[1]) {
+        runeio.Must(rdr.Next())
+        end = 0
+        for digits = 0; digits < 6; digits++ {
+            x := runeio.Must(rdr.Peek())
+            if !runeIsHexDigit(x) { break }
+            runeio.Must(rdr.Next())
+            end = (end << 4) | hexValue(x)
+        }
+    }
+
+    return token.UnicodeRange(start, end)
+}
+
 // ConsumeWhitespace consumes as much whitespace as possible and returns a
 // <whitespace-token>.
 func ConsumeWhitespace(rdr *runeio.Reader) token.Token {
@@ -402,15 +804,17 @@ func ConsumeString(rdr *runeio.Reader, endpoint rune) (t token.Token, err error)
     var sb strings.Builder
 
     for {
+        // a newline is reconsumed, so peek rather than push it back
+        if runeio.Must(rdr.Peek()) == '\n' {
+            return token.BadString(), ErrUnexpectedLinebreak
+        }
+
         c := runeio.Must(rdr.Next())
         switch c {
             case endpoint:
                 return token.String(sb.String()), nil
             case runeio.RuneEOF:
                 return token.String(sb.String()), ErrUnexpectedEOF
-            case '\n':
-                rdr.Push(c)
-                return token.BadString(), ErrUnexpectedLinebreak
             case '\\': // U+005C REVERSE SOLIDUS (\)
                 n := runeio.Must(rdr.Peek())
                 if n == runeio.RuneEOF { continue }
@@ -475,20 +879,23 @@ func ConsumeIdentSequence(rdr *runeio.Reader) string {
     var sb strings.Builder
 
     for {
-        c := runeio.Must(rdr.Next())
+        // peek, rather than reconsume the final code point
+        var xs [2]rune
+        must.Result(rdr.PeekN(xs[:], 2))
+        c, n := xs[0], xs[1]
 
         if runeIsIdentCodepoint(c) {
+            rdr.Skip(1)
             sb.WriteRune(c)
             continue
         }
 
-        n := runeio.Must(rdr.Peek())
         if isValidEscape(c, n) {
+            rdr.Skip(1)
             sb.WriteRune(ConsumeEscapedCodepoint(rdr))
             continue
         }
 
-        rdr.Push(c)
         return sb.String()
     }
 }
@@ -12,14 +12,18 @@ package tokenizer_test
 import (
     "errors"
     "fmt"
+    "io"
     "math"
+    "strconv"
     "strings"
     "testing"
+    "testing/iotest"
     "unicode"
 
     "github.com/stretchr/testify/assert"
     "github.com/tawesoft/golib/v2/css/tokenizer"
     "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/iter"
 )
 
 func ExampleTokenizer() {
@@ -461,9 +465,79 @@ func TestTokenizer_Percentages(t *testing.T) {
         token.Delim('%'))
 }
 
-// func TestTokenizer_UnicodeRange(t *testing.T)
-// not implemented because CSS now defines urange in terms of other CSS tokens,
-// and urange is detected at the parser step instead.
+// testWithOptions is like test, but for a tokenizer with options, and
+// asserts that there are no parse errors.
+func testWithOptions(t *testing.T, o tokenizer.Options, css string, tokens ... token.Token) {
+    z := tokenizer.NewWithOptions(strings.NewReader(css), o)
+    seen := iter.ToSlice(z.Tokens())
+    if len(seen) != len(tokens) {
+        t.Errorf("input: %q\n    expected: %v\n    seen: %v", css, tokens, seen)
+        return
+    }
+    for i := range tokens {
+        if !equal(tokens[i], seen[i]) {
+            t.Errorf("input: %q\n    expected: %v\n    seen: %v", css, tokens, seen)
+            return
+        }
+    }
+    assert.Empty(t, z.Errors(), "input: %q", css)
+}
+
+func TestTokenizer_UnicodeRange(t *testing.T) {
+    // CSS now defines urange in terms of other CSS tokens, and urange is
+    // detected at the parser step instead, so this is only enabled by an
+    // option.
+    o := tokenizer.Options{UnicodeRange: true}
+    num := func(repr string, v float64) token.Token {
+        return token.Number(token.NumberTypeInteger, repr, v)
+    }
+
+    // Tests from chromium.googlesource.com (from an earlier version)
+    testWithOptions(t, o, "u+012345-123456", token.UnicodeRange(0x012345, 0x123456))
+    testWithOptions(t, o, "U+1234-2345",     token.UnicodeRange(0x1234, 0x2345))
+    testWithOptions(t, o, "u+222-111",       token.UnicodeRange(0x222, 0x111))
+    testWithOptions(t, o, "U+CafE-d00D",     token.UnicodeRange(0xCAFE, 0xD00D))
+    testWithOptions(t, o, "U+2??",           token.UnicodeRange(0x200, 0x2FF))
+    testWithOptions(t, o, "U+2??-3??",       token.UnicodeRange(0x200, 0x2FF), num("-3", -3), token.Delim('?'), token.Delim('?'))
+    testWithOptions(t, o, "u+?",             token.UnicodeRange(0x0, 0xF))
+    testWithOptions(t, o, "U+??????",        token.UnicodeRange(0x0, 0xFFFFFF))
+    testWithOptions(t, o, "U+???????",       token.UnicodeRange(0x0, 0xFFFFFF), token.Delim('?'))
+    testWithOptions(t, o, "u+1234567",       token.UnicodeRange(0x123456, 0x123456), num("7", 7))
+    testWithOptions(t, o, "u+1-2-3",         token.UnicodeRange(0x1, 0x2), num("-3", -3))
+    testWithOptions(t, o, "U+12-",           token.UnicodeRange(0x12, 0x12), token.Delim('-'))
+    testWithOptions(t, o, "u+ab,u+cd",       token.UnicodeRange(0xAB, 0xAB), token.Comma(), token.UnicodeRange(0xCD, 0xCD))
+    testWithOptions(t, o, "u+g",             token.Ident("u"), token.Delim('+'), token.Ident("g"))
+    testWithOptions(t, o, "u+",              token.Ident("u"), token.Delim('+'))
+    testWithOptions(t, o, "ru+1",            token.Ident("ru"), num("+1", 1))
+
+    // without the option
+    test(t, "u+1-2", token.Ident("u"), num("+1", 1), num("-2", -2))
+}
+
+func TestTokenizer_CommentTokens(t *testing.T) {
+    o := tokenizer.Options{Comments: true}
+    testWithOptions(t, o, "/*comment*/a",     token.Comment("comment"), token.Ident("a"))
+    testWithOptions(t, o, "/**/",             token.Comment(""))
+    testWithOptions(t, o, "/***/",            token.Comment("*"))
+    testWithOptions(t, o, "/**y*a*y**/ ",     token.Comment("*y*a*y*"), token.Whitespace())
+    testWithOptions(t, o, ",/* \n :) \n */)", token.Comma(), token.Comment(" \n :) \n "), token.RightParen())
+    testWithOptions(t, o, ":/*/*/",           token.Colon(), token.Comment("/"))
+    testWithOptions(t, o, "/**//**/",         token.Comment(""), token.Comment(""))
+    testWithOptions(t, o, "/ *",              token.Delim('/'), token.Whitespace(), token.Delim('*'))
+
+    var errs []error
+    z := tokenizer.NewWithOptions(strings.NewReader(";/* abc"), tokenizer.Options{
+        Comments: true,
+        Handler: func(d tokenizer.ParseError) { errs = append(errs, d) },
+    })
+    tokens := iter.ToSlice(z.Tokens())
+    assert.Equal(t, 2, len(tokens))
+    assert.True(t, equal(token.Comment(" abc"), tokens[1]))
+    assert.Equal(t, pos(1, 1, 0, 7, 7, 0), tokens[1].Position())
+    assert.Nil(t, z.Errors())
+    assert.Equal(t, 1, len(errs))
+    assert.True(t, errors.Is(errs[0], tokenizer.ErrUnexpectedEOF))
+}
 
 func TestTokenizer_Comments(t *testing.T) {
     test(t, "/*comment*/a",       token.Ident("a"))
@@ -480,3 +554,189 @@ func TestTokenizer_Comments(t *testing.T) {
     }
     testWithErrCheck(t, ";/******", checkEof, token.Semicolon())
 }
+
+// pos returns a token.Position with the fields in order.
+func pos(start, col, line, end, endCol, endLine int64) token.Position {
+    return token.Position{
+        Byte: start, Rune: col, Line: line,
+        End: end, EndRune: endCol, EndLine: endLine,
+    }
+}
+
+func TestTokenizer_Positions(t *testing.T) {
+    str := "a {\n  b: \"c\nd\n}"
+    z := tokenizer.New(strings.NewReader(str))
+
+    type row struct {
+        t token.Type
+        pos token.Position
+    }
+    rows := []row{
+        {token.TypeIdent,             pos(0, 0, 0, 1, 1, 0)},
+        {token.TypeWhitespace,        pos(1, 1, 0, 2, 2, 0)},
+        {token.TypeLeftCurlyBracket,  pos(2, 2, 0, 3, 3, 0)},
+        {token.TypeWhitespace,        pos(3, 3, 0, 6, 2, 1)},
+        {token.TypeIdent,             pos(6, 2, 1, 7, 3, 1)},
+        {token.TypeColon,             pos(7, 3, 1, 8, 4, 1)},
+        {token.TypeWhitespace,        pos(8, 4, 1, 9, 5, 1)},
+        {token.TypeBadString,         pos(9, 5, 1, 11, 7, 1)},
+        {token.TypeWhitespace,        pos(11, 7, 1, 12, 0, 2)},
+        {token.TypeIdent,             pos(12, 0, 2, 13, 1, 2)},
+        {token.TypeWhitespace,        pos(13, 1, 2, 14, 0, 3)},
+        {token.TypeRightCurlyBracket, pos(14, 0, 3, 15, 1, 3)},
+        {token.TypeEOF,               pos(15, 1, 3, 15, 1, 3)},
+    }
+    for i, r := range rows {
+        tok := z.Next()
+        if !tok.Is(r.t) || (tok.Position() != r.pos) {
+            t.Errorf("token %d: got %v at %#v, expected %s at %#v",
+                i, tok, tok.Position(), r.t, r.pos)
+        }
+    }
+
+    assert.Equal(t, "4:2", z.Next().Position().String())
+}
+
+func TestTokenizer_PositionsNumeric(t *testing.T) {
+    str := "url(é) -1.5e3px,#f\\00 \n-->"
+    z := tokenizer.New(strings.NewReader(str))
+
+    type row struct {
+        t token.Type
+        pos token.Position
+    }
+    rows := []row{
+        {token.TypeUrl,        pos(0, 0, 0, 7, 6, 0)},
+        {token.TypeWhitespace, pos(7, 6, 0, 8, 7, 0)},
+        {token.TypeDimension,  pos(8, 7, 0, 16, 15, 0)},
+        {token.TypeComma,      pos(16, 15, 0, 17, 16, 0)},
+        {token.TypeHash,       pos(17, 16, 0, 23, 22, 0)},
+        {token.TypeWhitespace, pos(23, 22, 0, 24, 0, 1)},
+        {token.TypeCDC,        pos(24, 0, 1, 27, 3, 1)},
+        {token.TypeEOF,        pos(27, 3, 1, 27, 3, 1)},
+    }
+    for i, r := range rows {
+        tok := z.Next()
+        if !tok.Is(r.t) || (tok.Position() != r.pos) {
+            t.Errorf("token %d: got %v at %#v, expected %s at %#v",
+                i, tok, tok.Position(), r.t, r.pos)
+        }
+    }
+}
+
+func ExampleNewStreaming() {
+    str := `a { content: "unterminated
+    ; b: \
+    }`
+    z := tokenizer.NewStreaming(strings.NewReader(str), func(d tokenizer.ParseError) {
+        fmt.Printf("%s: %v\n", d.Position, d.Err)
+    })
+
+    iter.Exhaust(z.Tokens())
+
+    // Output:
+    // 1:14: unexpected line break
+    // 2:10: unexpected input
+}
+
+func TestTokenizer_ParseErrors(t *testing.T) {
+    str := "a \"b\n/* c"
+    z := tokenizer.New(strings.NewReader(str))
+    tokens := iter.ToSlice(z.Tokens())
+    assert.Equal(t, 4, len(tokens)) // ident, whitespace, bad-string, whitespace
+
+    errs := z.Errors()
+    assert.Equal(t, 2, len(errs))
+
+    var d tokenizer.ParseError
+    assert.True(t, errors.As(errs[0], &d))
+    assert.True(t, errors.Is(d, tokenizer.ErrUnexpectedLinebreak))
+    assert.Equal(t, pos(2, 2, 0, 4, 4, 0), d.Position)
+    assert.Equal(t, token.TypeBadString, d.Token)
+    assert.False(t, d.Fatal)
+
+    assert.True(t, errors.As(errs[1], &d))
+    assert.True(t, errors.Is(d, tokenizer.ErrUnexpectedEOF))
+    assert.Equal(t, pos(5, 0, 1, 9, 4, 1), d.Position)
+    assert.Equal(t, "parse error at 2:1: unexpected end of file", d.Error())
+    assert.Equal(t, token.Type(""), d.Token)
+
+    assert.Equal(t, z.ParseErrors()[1], d)
+}
+
+func TestTokenizer_ReadError(t *testing.T) {
+    errRead := fmt.Errorf("read error")
+    rdr := io.MultiReader(strings.NewReader("a b"), iotest.ErrReader(errRead))
+
+    var ds []tokenizer.ParseError
+    z := tokenizer.NewStreaming(rdr, func(d tokenizer.ParseError) {
+        ds = append(ds, d)
+    })
+
+    var types []token.Type
+    iter.Walk(func(tok token.Token) { types = append(types, tok.Type()) }, z.Tokens())
+
+    assert.Equal(t, []token.Type{token.TypeIdent, token.TypeWhitespace, token.TypeIdent}, types)
+    assert.Nil(t, z.Errors())
+    assert.Equal(t, 1, len(ds))
+    assert.True(t, errors.Is(ds[0], errRead))
+    assert.True(t, ds[0].Fatal)
+    assert.True(t, z.Next().Is(token.TypeEOF))
+}
+
+func TestTokenizer_Push(t *testing.T) {
+    z := tokenizer.New(strings.NewReader("a"))
+    for i := 0; i < 100; i++ {
+        z.Push(token.Ident(strconv.Itoa(i)))
+    }
+    for i := 99; i >= 0; i-- {
+        assert.Equal(t, strconv.Itoa(i), z.Next().StringValue())
+    }
+    assert.True(t, z.Next().Is(token.TypeIdent))
+    assert.True(t, z.Next().Is(token.TypeEOF))
+}
+
+func TestTokenizer_Limits(t *testing.T) {
+    type row struct {
+        input string
+        options tokenizer.Options
+        count int // tokens before the limit
+        limit tokenizer.Limit // or empty if not exceeded
+    }
+    long := strings.Repeat("a", 10000)
+    rows := []row{
+        {"abcd efgh",           tokenizer.Options{MaxTokenLength: 4}, 3, ""},
+        {"abcd efghi",          tokenizer.Options{MaxTokenLength: 4}, 2, tokenizer.LimitTokenLength},
+        {"a " + long,           tokenizer.Options{MaxTokenLength: 4}, 2, tokenizer.LimitTokenLength},
+        {"a/*" + long + "*/b",  tokenizer.Options{MaxTokenLength: 4}, 2, ""},
+        {`"` + long,            tokenizer.Options{MaxTokenLength: 4}, 0, tokenizer.LimitTokenLength},
+        {`\61\62 x`,            tokenizer.Options{MaxEscapes: 2},     1, ""},
+        {`\61\62\63 x`,         tokenizer.Options{MaxEscapes: 2},     0, tokenizer.LimitEscapes},
+        {`\61 \62 \63 x`,       tokenizer.Options{MaxEscapes: 2},     0, tokenizer.LimitEscapes},
+        {`"\\" \61`,            tokenizer.Options{MaxEscapes: 1},     0, tokenizer.LimitEscapes},
+        {`\61 x \62 x \63 x`,   tokenizer.Options{MaxEscapes: 1},     5, ""},
+        {"(()[])",              tokenizer.Options{MaxDepth: 2},       6, ""},
+        {"(([a]))",             tokenizer.Options{MaxDepth: 2},       2, tokenizer.LimitDepth},
+        {"a(b(c{",              tokenizer.Options{MaxDepth: 2},       3, tokenizer.LimitDepth},
+        {")))(((",              tokenizer.Options{MaxDepth: 3},       6, ""},
+    }
+    for _, r := range rows {
+        z := tokenizer.NewWithOptions(strings.NewReader(r.input), r.options)
+        tokens := iter.ToSlice(z.Tokens())
+        if r.limit == "" {
+            assert.Equal(t, r.count, len(tokens), "%q", r.input)
+            assert.Nil(t, z.Errors(), "%q", r.input)
+            continue
+        }
+        assert.Equal(t, r.count, len(tokens), "%q", r.input)
+
+        errs := z.ParseErrors()
+        if !assert.Equal(t, 1, len(errs), "%q", r.input) { continue }
+        var e tokenizer.LimitError
+        assert.True(t, errs[0].Fatal, "%q", r.input)
+        assert.True(t, errors.Is(errs[0], tokenizer.ErrLimit), "%q", r.input)
+        assert.True(t, errors.As(errs[0], &e), "%q", r.input)
+        assert.Equal(t, r.limit, e.Limit, "%q", r.input)
+        assert.True(t, z.Next().Is(token.TypeEOF), "%q", r.input)
+    }
+}
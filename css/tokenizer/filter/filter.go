@@ -54,7 +54,7 @@ func (t *filter) Transform(dst, src []byte, atEOF bool) (nDst int, nSrc int, err
             break
         } else if atEOF && (size == 0) {
             break
-        } else if r == utf8.RuneError {
+        } else if (r == utf8.RuneError) && (size == 1) {
             err = DecodeError
             break
         }
@@ -21,6 +21,8 @@ func TestFilter(t *testing.T) {
         {"foo\r\r\n", "foo\n\n", nil},
         {"foo\r\n\r", "foo\n\n", nil},
         {"foo\000foo", "foo\uFFFDfoo", nil},
+        {"foo\uFFFDfoo", "foo\uFFFDfoo", nil},
+        {"foo\xFFfoo", "foo", filter.DecodeError},
     }
 
     f := filter.Transformer()
@@ -0,0 +1,372 @@
+package tokenizer
+
+import (
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/text/runeio"
+)
+
+// Serializer writes tokens back to CSS text, based on part nine of the
+// [CSS Syntax Module Level 3], and the "serialize an identifier",
+// "serialize a string", and "serialize a URL" algorithms from the
+// [CSS Object Model].
+//
+// Serializing a sequence of tokens, and then tokenizing the result, produces
+// the same sequence of tokens again (ignoring parse errors and positions).
+// To achieve this, code points are escaped where necessary, and a separator
+// is written between any two adjacent tokens that would otherwise be read
+// back as something else (for example, the two <ident-token>s "a" and "b"
+// would otherwise be read back as the single <ident-token> "ab").
+//
+// A <bad-string-token> is serialized as a quotation mark followed by a line
+// break, and a <bad-url-token> is serialized as "url(()". These are read back
+// as the same token (with a parse error), but the first is also followed by a
+// <whitespace-token>.
+//
+// Note that the tokenizer discards comments, and does not record the
+// original whitespace, so these are not preserved exactly. Set the
+// Separator and Whitespace fields to control how they are written instead.
+//
+// A <comment-token> or <unicode-range-token>, produced by a Tokenizer with
+// the corresponding [Options], is serialized as a comment or unicode-range
+// respectively. These are only read back as the same tokens by a Tokenizer
+// with the same options. Note that such a Tokenizer also reads back the
+// default Separator, "/**/", as a <comment-token>.
+//
+// [CSS Syntax Module Level 3]: https://www.w3.org/TR/css-syntax-3/#serialization
+// [CSS Object Model]: https://www.w3.org/TR/cssom-1/#common-serializing-idioms
+type Serializer struct {
+    // Separator is written between two adjacent tokens that would otherwise
+    // be read back as a different sequence of tokens. If empty, this defaults
+    // to the empty comment "/**/", as recommended by the specification.
+    //
+    // A single space is also a reasonable choice, and is more readable,
+    // however this adds a <whitespace-token> when read back.
+    Separator string
+
+    // Whitespace is written for each <whitespace-token>. If empty, this
+    // defaults to a single space. It must only contain whitespace.
+    Whitespace string
+
+    wtr *runeio.Writer
+    prev token.Token
+}
+
+// NewSerializer returns a new [Serializer] that writes to w. Call
+// [Serializer.Flush] once all tokens have been written.
+func NewSerializer(w io.Writer) *Serializer {
+    return &Serializer{
+        wtr: runeio.NewWriter(w),
+        prev: token.EOF(),
+    }
+}
+
+// Serialize returns the tokens serialized as CSS text, using the default
+// options of a [Serializer].
+func Serialize(tokens []token.Token) string {
+    var sb strings.Builder
+    s := NewSerializer(&sb)
+    for _, t := range tokens {
+        s.Write(t) // never fails writing to a strings.Builder
+    }
+    s.Flush()
+    return sb.String()
+}
+
+// Offset returns the offset of the end of the output so far.
+func (s *Serializer) Offset() runeio.Offset {
+    return s.wtr.Offset()
+}
+
+// Flush writes any buffered output to the underlying io.Writer.
+func (s *Serializer) Flush() error {
+    return s.wtr.Flush()
+}
+
+// Write serializes a token. Writing a <EOF-token> does nothing.
+func (s *Serializer) Write(t token.Token) error {
+    if t.Is(token.TypeEOF) { return nil }
+
+    prev := s.prev
+    s.prev = t
+
+    // A <delim-token> containing U+005C REVERSE SOLIDUS (\) is serialized
+    // followed by a line break, which is already a <whitespace-token>.
+    if prev.Is(token.TypeDelim) && (prev.Delim() == '\\') {
+        if t.Is(token.TypeWhitespace) { return nil }
+    } else if needsSeparator(prev, t) {
+        if err := s.writeString(s.Separator, "/**/"); err != nil { return err }
+    }
+
+    switch t.Type() {
+        case token.TypeWhitespace:
+            return s.writeString(s.Whitespace, " ")
+        case token.TypeIdent:
+            return s.write(serializeIdent(t.StringValue()))
+        case token.TypeFunction:
+            return s.write(serializeIdent(t.StringValue()), "(")
+        case token.TypeAtKeyword:
+            return s.write("@", serializeIdent(t.StringValue()))
+        case token.TypeHash:
+            if t.HashType() == token.HashTypeID {
+                return s.write("#", serializeIdent(t.StringValue()))
+            }
+            return s.write("#", serializeName(t.StringValue()))
+        case token.TypeString:
+            return s.write(serializeString(t.StringValue()))
+        case token.TypeBadString:
+            return s.write("\"\n")
+        case token.TypeUrl:
+            return s.write("url(", serializeUrl(t.StringValue()), ")")
+        case token.TypeBadUrl:
+            return s.write("url(()")
+        case token.TypeDelim:
+            if t.Delim() == '\\' { return s.write("\\\n") }
+            return s.write(string(t.Delim()))
+        case token.TypeNumber:
+            return s.write(serializeNumber(t))
+        case token.TypePercentage:
+            return s.write(serializeNumber(t), "%")
+        case token.TypeDimension:
+            return s.write(serializeNumber(t), serializeUnit(t.Unit()))
+        case token.TypeComment:
+            if strings.Contains(t.StringValue(), "*/") {
+                return fmt.Errorf("tokenizer: cannot serialize comment containing \"*/\"")
+            }
+            return s.write("/*", t.StringValue(), "*/")
+        case token.TypeUnicodeRange:
+            return s.write(serializeUnicodeRange(t.UnicodeRange()))
+        case token.TypeCDO:                return s.write("<!--")
+        case token.TypeCDC:                return s.write("-->")
+        case token.TypeColon:              return s.write(":")
+        case token.TypeSemicolon:          return s.write(";")
+        case token.TypeComma:              return s.write(",")
+        case token.TypeLeftParen:          return s.write("(")
+        case token.TypeRightParen:         return s.write(")")
+        case token.TypeLeftSquareBracket:  return s.write("[")
+        case token.TypeRightSquareBracket: return s.write("]")
+        case token.TypeLeftCurlyBracket:   return s.write("{")
+        case token.TypeRightCurlyBracket:  return s.write("}")
+        default:
+            return fmt.Errorf("tokenizer: cannot serialize token %v", t)
+    }
+}
+
+func (s *Serializer) write(xs ... string) error {
+    for _, x := range xs {
+        if _, err := s.wtr.WriteString(x); err != nil { return err }
+    }
+    return nil
+}
+
+// writeString writes x, or the default value if x is empty.
+func (s *Serializer) writeString(x string, def string) error {
+    if x == "" { x = def }
+    return s.write(x)
+}
+
+// needsSeparator returns true if two adjacent tokens must be separated when
+// serialized. This implements the table given in part nine of the CSS
+// Syntax Module Level 3.
+func needsSeparator(a token.Token, b token.Token) bool {
+    // the columns of the table
+    var (
+        ident      = b.Is(token.TypeIdent)
+        function   = b.Is(token.TypeFunction)
+        url        = b.Is(token.TypeUrl)
+        badUrl     = b.Is(token.TypeBadUrl)
+        hyphen     = b.Is(token.TypeDelim) && (b.Delim() == '-')
+        number     = b.Is(token.TypeNumber)
+        percentage = b.Is(token.TypePercentage)
+        dimension  = b.Is(token.TypeDimension)
+        cdc        = b.Is(token.TypeCDC)
+        paren      = b.Is(token.TypeLeftParen)
+        asterisk   = b.Is(token.TypeDelim) && (b.Delim() == '*')
+        percent    = b.Is(token.TypeDelim) && (b.Delim() == '%')
+        urange     = b.Is(token.TypeUnicodeRange)
+    )
+    // not in the table, but a <unicode-range-token> starts with "U".
+    identLike := ident || function || url || badUrl || urange
+
+    // not in the table, but a number starting with "+" is never read back
+    // as part of the previous token, e.g. "2n+1".
+    numeric := (number || percentage || dimension) && !strings.HasPrefix(serializeNumber(b), "+")
+
+    switch a.Type() {
+        case token.TypeWhitespace:
+            // not in the table, but adjacent whitespace is read back as a
+            // single <whitespace-token>, e.g. from "a /**/ b".
+            return b.Is(token.TypeWhitespace)
+        case token.TypeIdent:
+            // not in the table, but "--" followed by ">" is read back as a
+            // <CDC-token>.
+            if (a.StringValue() == "--") && b.Is(token.TypeDelim) && (b.Delim() == '>') {
+                return true
+            }
+            return identLike || hyphen || numeric || cdc || paren
+        case token.TypeAtKeyword: fallthrough
+        case token.TypeHash:      fallthrough
+        case token.TypeDimension:
+            return identLike || hyphen || numeric || cdc
+        case token.TypeNumber:
+            return identLike || numeric || percent
+        case token.TypeUnicodeRange:
+            // not in the table, but a following hex digit, "?", or "-" is
+            // read back as part of the range.
+            question := b.Is(token.TypeDelim) && (b.Delim() == '?')
+            return identLike || hyphen || question ||
+                number || percentage || dimension
+        case token.TypeDelim:
+            switch a.Delim() {
+                case '#': fallthrough
+                case '-':
+                    return identLike || hyphen || numeric
+                case '@':
+                    return identLike || hyphen
+                case '.': fallthrough
+                case '+':
+                    return numeric
+                case '/':
+                    return asterisk
+            }
+    }
+    return false
+}
+
+// escapeCodepoint returns a code point escaped as a hexadecimal number,
+// followed by a space.
+func escapeCodepoint(x rune) string {
+    return "\\" + strconv.FormatInt(int64(x), 16) + " "
+}
+
+// serializeIdent implements the "serialize an identifier" algorithm.
+func serializeIdent(s string) string {
+    var sb strings.Builder
+    var first rune = runeio.RuneEOF
+    i := 0
+    for _, x := range s {
+        switch {
+            case x == 0:
+                sb.WriteRune(0xFFFD)
+            case ((x >= 0x01) && (x <= 0x1F)) || (x == 0x7F):
+                sb.WriteString(escapeCodepoint(x))
+            case (i == 0) && runeIsDigit(x):
+                sb.WriteString(escapeCodepoint(x))
+            case (i == 1) && runeIsDigit(x) && (first == '-'):
+                sb.WriteString(escapeCodepoint(x))
+            case (i == 0) && (x == '-') && (len(s) == 1):
+                sb.WriteString("\\-")
+            case (x >= 0x80) || (x == '-') || (x == '_') || runeIsDigit(x) || runeIsLetter(x):
+                sb.WriteRune(x)
+            default:
+                sb.WriteRune('\\')
+                sb.WriteRune(x)
+        }
+        if i == 0 { first = x }
+        i++
+    }
+    return sb.String()
+}
+
+// serializeName is like serializeIdent, but for an ident sequence that does
+// not have to be a valid identifier, such as the value of an
+// "unrestricted" <hash-token>.
+func serializeName(s string) string {
+    var sb strings.Builder
+    for _, x := range s {
+        switch {
+            case x == 0:
+                sb.WriteRune(0xFFFD)
+            case ((x >= 0x01) && (x <= 0x1F)) || (x == 0x7F):
+                sb.WriteString(escapeCodepoint(x))
+            case runeIsIdentCodepoint(x):
+                sb.WriteRune(x)
+            default:
+                sb.WriteRune('\\')
+                sb.WriteRune(x)
+        }
+    }
+    return sb.String()
+}
+
+// serializeString implements the "serialize a string" algorithm.
+func serializeString(s string) string {
+    var sb strings.Builder
+    sb.WriteRune('"')
+    for _, x := range s {
+        switch {
+            case x == 0:
+                sb.WriteRune(0xFFFD)
+            case ((x >= 0x01) && (x <= 0x1F)) || (x == 0x7F):
+                sb.WriteString(escapeCodepoint(x))
+            case (x == '"') || (x == '\\'):
+                sb.WriteRune('\\')
+                sb.WriteRune(x)
+            default:
+                sb.WriteRune(x)
+        }
+    }
+    sb.WriteRune('"')
+    return sb.String()
+}
+
+// serializeUrl serializes the value of an unquoted <url-token>.
+func serializeUrl(s string) string {
+    var sb strings.Builder
+    for _, x := range s {
+        switch {
+            case x == 0:
+                sb.WriteRune(0xFFFD)
+            case runeIsWhitespace(x) || runeIsNonPrintable(x):
+                sb.WriteString(escapeCodepoint(x))
+            case (x == '"') || (x == '\'') || (x == '(') || (x == ')') || (x == '\\'):
+                sb.WriteRune('\\')
+                sb.WriteRune(x)
+            default:
+                sb.WriteRune(x)
+        }
+    }
+    return sb.String()
+}
+
+// serializeUnicodeRange serializes the value of a <unicode-range-token>.
+func serializeUnicodeRange(start rune, end rune) string {
+    if start == end { return fmt.Sprintf("U+%X", start) }
+    return fmt.Sprintf("U+%X-%X", start, end)
+}
+
+// serializeNumber serializes the numeric part of a <number-token>,
+// <percentage-token>, or <dimension-token>, preferring its original
+// representation, if known.
+func serializeNumber(t token.Token) string {
+    if repr := t.Repr(); repr != "" { return repr }
+
+    nt, value := t.NumericValue()
+    if nt == token.NumberTypeInteger {
+        return strconv.FormatFloat(value, 'f', -1, 64)
+    }
+
+    s := strconv.FormatFloat(value, 'g', -1, 64)
+    if !strings.ContainsAny(s, ".eE") {
+        s += ".0" // otherwise, read back as an integer
+    }
+    return s
+}
+
+// serializeUnit serializes the unit of a <dimension-token>. In addition to
+// the usual rules for an identifier, a unit such as "e3" must not be read
+// back as the exponent of the number.
+func serializeUnit(unit string) string {
+    s := serializeIdent(unit)
+    if (len(s) < 2) || ((s[0] != 'e') && (s[0] != 'E')) { return s }
+
+    if runeIsDigit(rune(s[1])) ||
+        ((len(s) > 2) && ((s[1] == '+') || (s[1] == '-')) && runeIsDigit(rune(s[2]))) {
+        return escapeCodepoint(rune(s[0])) + s[1:]
+    }
+    return s
+}
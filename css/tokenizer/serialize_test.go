@@ -0,0 +1,177 @@
+package tokenizer_test
+
+import (
+    "fmt"
+    "io"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+func ExampleSerializer() {
+    str := `a:hover{color:RED;margin:-1px auto}`
+    z := tokenizer.New(strings.NewReader(str))
+
+    var sb strings.Builder
+    s := tokenizer.NewSerializer(&sb)
+    for {
+        tok := z.Next()
+        if tok.Is(token.TypeEOF) { break }
+        if tok.Is(token.TypeIdent) && (tok.StringValue() == "RED") {
+            tok = token.Hash(token.HashTypeUnrestricted, "f00")
+        }
+        s.Write(tok)
+    }
+    s.Flush()
+    fmt.Println(sb.String())
+
+    // Output:
+    // a:hover{color:#f00;margin:-1px auto}
+}
+
+func tokenize(css string) []token.Token {
+    z := tokenizer.New(strings.NewReader(css))
+    var tokens []token.Token
+    for {
+        tok := z.Next()
+        if tok.Is(token.TypeEOF) { break }
+        tokens = append(tokens, tok)
+    }
+    return tokens
+}
+
+func TestSerialize(t *testing.T) {
+    type row struct {
+        tokens []token.Token
+        expected string
+    }
+    rows := []row{
+        {[]token.Token{token.Ident("a"), token.Ident("b")}, `a/**/b`},
+        {[]token.Token{token.Ident("a"), token.LeftParen()}, `a/**/(`},
+        {[]token.Token{token.Ident("a"), token.Whitespace(), token.Ident("b")}, `a b`},
+        {[]token.Token{token.Ident("a"), token.Whitespace(), token.Whitespace(), token.Ident("b")}, `a /**/ b`},
+        {[]token.Token{token.Ident("a"), token.Colon(), token.Ident("b")}, `a:b`},
+        {[]token.Token{token.Number(token.NumberTypeInteger, "1", 1), token.Ident("px")}, `1/**/px`},
+        {[]token.Token{token.Number(token.NumberTypeInteger, "1", 1), token.Delim('%')}, `1/**/%`},
+        {[]token.Token{token.Delim('/'), token.Delim('*')}, `//**/*`},
+        {[]token.Token{token.Delim('@'), token.Ident("x")}, `@/**/x`},
+        {[]token.Token{token.Delim('#'), token.Delim('-')}, `#/**/-`},
+        {[]token.Token{token.Ident("--"), token.Delim('>')}, `--/**/>`},
+        {[]token.Token{token.Delim('\\'), token.Whitespace(), token.Ident("a")}, "\\\na"},
+        {[]token.Token{token.Ident("1a")}, `\31 a`},
+        {[]token.Token{token.Ident("-1")}, `-\31 `},
+        {[]token.Token{token.Ident("-")}, `\-`},
+        {[]token.Token{token.Ident("a b.c\x00")}, "a\\ b\\.c�"},
+        {[]token.Token{token.Function("url"), token.String("x")}, `url("x"`},
+        {[]token.Token{token.AtKeyword("media")}, `@media`},
+        {[]token.Token{token.Hash(token.HashTypeID, "a")}, `#a`},
+        {[]token.Token{token.Hash(token.HashTypeUnrestricted, "1a")}, `#1a`},
+        {[]token.Token{token.String("a\"b\\c\nd")}, `"a\"b\\c\a d"`},
+        {[]token.Token{token.Url("a b(c)")}, `url(a\20 b\(c\))`},
+        {[]token.Token{token.Number(token.NumberTypeNumber, "", 2)}, `2.0`},
+        {[]token.Token{token.Number(token.NumberTypeNumber, "+.5E1", 5)}, `+.5E1`},
+        {[]token.Token{token.Percentage(token.NumberTypeInteger, "50", 50)}, `50%`},
+        {[]token.Token{token.Dimension(token.NumberTypeInteger, "1", 1, "e3")}, `1\65 3`},
+        {[]token.Token{token.Dimension(token.NumberTypeInteger, "1", 1, "em")}, `1em`},
+        {[]token.Token{token.CDO(), token.CDC()}, `<!---->`},
+        {[]token.Token{token.BadUrl()}, `url(()`},
+        {[]token.Token{token.Comment(" a "), token.Ident("b")}, `/* a */b`},
+        {[]token.Token{token.Ident("a"), token.UnicodeRange(0x25, 0xFF)}, `a/**/U+25-FF`},
+        {[]token.Token{token.UnicodeRange(0x25, 0x25), token.Ident("a")}, `U+25/**/a`},
+        {[]token.Token{token.UnicodeRange(0x25, 0x25), token.Delim('?')}, `U+25/**/?`},
+        {[]token.Token{token.UnicodeRange(0x25, 0x25), token.Delim('-')}, `U+25/**/-`},
+        {[]token.Token{token.UnicodeRange(0x25, 0x25), token.Comma()}, `U+25,`},
+    }
+
+    for _, r := range rows {
+        assert.Equal(t, r.expected, tokenizer.Serialize(r.tokens), "%v", r.tokens)
+    }
+}
+
+func TestSerialize_roundTrip(t *testing.T) {
+    inputs := []string{
+        `#something[rel~="external"] { background-color: rgb(128, 64, 64); }`,
+        `a/**/b c/**/(d) 1/**/px 2/**/% 3/**/4 .5/**/6`,
+        `@media screen and (max-width: 100px) { a { b: c !important } }`,
+        `url( "a" ) url(a\)b) url(a\ b) u\rl(a) url(a"b) --/**/> <!-- -->`,
+        `\31 23 -\31 x \-- \\ \
+ a "a\"b\
+c" 'a\'b' "\0" "a`,
+        `1e3 1e-3 1.5E+3px 1\65 3 -.5% +1 -a --a #-a #1 #\31 @-a @\31 `,
+        `@/**/a #/**/- -/**/- ./**/1 +/**/1 //**/* ident\(x`,
+        `a /**/ b /**/ /**/ c`,
+    }
+
+    for _, input := range inputs {
+        tokens := tokenize(input)
+        again := tokenize(tokenizer.Serialize(tokens))
+        if len(tokens) != len(again) {
+            t.Errorf("%q: got %v, expected %v", input, again, tokens)
+            continue
+        }
+        for i := range tokens {
+            if !equal(tokens[i], again[i]) {
+                t.Errorf("%q: got %v, expected %v", input, again[i], tokens[i])
+            }
+        }
+    }
+}
+
+func TestSerialize_roundTripWithOptions(t *testing.T) {
+    o := tokenizer.Options{Comments: true, UnicodeRange: true}
+    tokenize := func(css string) []token.Token {
+        return iter.ToSlice(tokenizer.NewWithOptions(strings.NewReader(css), o).Tokens())
+    }
+    inputs := []string{
+        `@font-face { unicode-range: U+0025-00FF, u+4??, U+1/**/a; }`,
+        `/* a */ b /**/ c/*d*/e U+1 1 U+1 - U+1 ? U+1 x`,
+    }
+
+    for _, input := range inputs {
+        var sb strings.Builder
+        s := tokenizer.NewSerializer(&sb)
+        s.Separator = " "
+        tokens := tokenize(input)
+        for _, tok := range tokens {
+            assert.Nil(t, s.Write(tok))
+        }
+        assert.Nil(t, s.Flush())
+
+        var again []token.Token
+        for _, tok := range tokenize(sb.String()) {
+            if !tok.Is(token.TypeWhitespace) { again = append(again, tok) }
+        }
+        var expected []token.Token
+        for _, tok := range tokens {
+            if !tok.Is(token.TypeWhitespace) { expected = append(expected, tok) }
+        }
+
+        if len(expected) != len(again) {
+            t.Errorf("%q: got %v, expected %v", input, again, expected)
+            continue
+        }
+        for i := range expected {
+            if !equal(expected[i], again[i]) {
+                t.Errorf("%q: got %v, expected %v", input, again[i], expected[i])
+            }
+        }
+    }
+
+    assert.NotNil(t, tokenizer.NewSerializer(io.Discard).Write(token.Comment("*/")))
+}
+
+func TestSerializer_options(t *testing.T) {
+    var sb strings.Builder
+    s := tokenizer.NewSerializer(&sb)
+    s.Separator = " "
+    s.Whitespace = "\n"
+    for _, tok := range tokenize(`a/**/b c`) {
+        assert.Nil(t, s.Write(tok))
+    }
+    assert.Nil(t, s.Flush())
+    assert.Equal(t, "a b\nc", sb.String())
+}
@@ -33,6 +33,19 @@ const (
     TypeRightSquareBracket = Type("]-token")
     TypeLeftCurlyBracket   = Type("{-token")
     TypeRightCurlyBracket  = Type("}-token")
+
+    // The following are not produced by a tokenizer by default.
+
+    // TypeComment is a comment, produced only if enabled in the tokenizer
+    // options. This is not a token in the CSS Syntax Module Level 3.
+    TypeComment = Type("comment-token")
+
+    // TypeUnicodeRange is a unicode-range e.g. "U+0025-00FF", produced only
+    // if enabled in the tokenizer options. This is not a token in the CSS
+    // Syntax Module Level 3 (which instead defines the <urange> production
+    // in terms of other tokens), but was in earlier drafts, and is simpler
+    // to use when parsing the unicode-range descriptor of a @font-face rule.
+    TypeUnicodeRange = Type("unicode-range-token")
 )
 
 type HashType string
@@ -47,11 +60,26 @@ const (
     NumberTypeNumber  = NumberType("number")
 )
 
+// Position describes the location of a token in the input stream, from the
+// start of the token up to (but not including) its end.
+//
+// Lines and line offsets are exact. Byte offsets are counted after input
+// preprocessing (see [css/tokenizer/filter]), so differ from the raw input
+// where it contains a CR LF pair, or a NULL, for example.
 type Position struct {
     Byte int64 // steam offset in bytes (0-indexed)
     Rune int64 // line offset in runes (0-indexed)
     Line int64 // current line (0-indexed)
     End  int64 // stream offset in bytes (0-indexed)
+
+    EndRune int64 // line offset in runes of the end (0-indexed)
+    EndLine int64 // line of the end (0-indexed)
+}
+
+// String formats a position as "line:column", counting from one, as is
+// conventional in error messages.
+func (p Position) String() string {
+    return fmt.Sprintf("%d:%d", p.Line + 1, p.Rune + 1)
 }
 
 type Token struct {
@@ -66,7 +94,7 @@ type Token struct {
     repr string
 
     // Value is used by <ident-token>, <function-token>, <at-keyword-token>,
-    // <hash-token>, <string-token>, and <url-token>.
+    // <hash-token>, <string-token>, <url-token>, and <comment-token>.
     stringValue string
 
     unit string // used by <dimension-token>.
@@ -86,6 +114,9 @@ type Token struct {
     // numberValue is used by <number-token>, <dimension-token>,
     // <percentage-token>.
     numberValue float64
+
+    // rangeStart and rangeEnd are used by <unicode-range-token>.
+    rangeStart, rangeEnd rune
 }
 
 func (t Token) WithPosition(p Position) Token {
@@ -111,8 +142,11 @@ func (t Token) String() string {
         case TypeAtKeyword: fallthrough
         case TypeUrl:       fallthrough
         case TypeFunction:  fallthrough
+        case TypeComment:   fallthrough
         case TypeIdent:
             return fmt.Sprintf("<%s>{value: %q}", t._type, t.stringValue)
+        case TypeUnicodeRange:
+            return fmt.Sprintf("<%s>{start: %U, end: %U}", t._type, t.rangeStart, t.rangeEnd)
         case TypeDelim:
             return fmt.Sprintf("<%s>{delim: %q}", t._type, t.delim)
         case TypeHash:
@@ -145,12 +179,16 @@ func Equals(a Token, b Token) bool {
         case TypeAtKeyword: fallthrough
         case TypeUrl:       fallthrough
         case TypeFunction:  fallthrough
+        case TypeComment:   fallthrough
         case TypeIdent:
             return a.stringValue == b.stringValue
 
         case TypeDelim:
             return a.delim == b.delim
 
+        case TypeUnicodeRange:
+            return (a.rangeStart == b.rangeStart) && (a.rangeEnd == b.rangeEnd)
+
         case TypeDimension:
             if a.unit != b.unit { return false }
             fallthrough
@@ -173,8 +211,8 @@ func Equals(a Token, b Token) bool {
 // type, this function returns an empty string.
 func (t Token) Repr() string {
     switch t._type {
-        case TypeNumber:
-        case TypePercentage:
+        case TypeNumber:     fallthrough
+        case TypePercentage: fallthrough
         case TypeDimension:
             return t.repr
     }
@@ -183,9 +221,11 @@ func (t Token) Repr() string {
 
 // StringValue returns the string value of a <ident-token>, <function-token>,
 // <at-keyword-token>, <hash-token>, <string-token>, or <url-token>, or the
-// empty string if the token is not one of these types.
+// empty string if the token is not one of these types. For a
+// <comment-token>, this is the text between the "/*" and "*/".
 func (t Token) StringValue() string {
     switch t._type {
+        case TypeComment:   fallthrough
         case TypeHash:      fallthrough
         case TypeString:    fallthrough
         case TypeAtKeyword: fallthrough
@@ -233,6 +273,16 @@ func (t Token) Unit() string {
     }
 }
 
+// UnicodeRange returns the first and last code points (inclusive) of a
+// <unicode-range-token>. If the token is not a unicode-range token, returns
+// (0, 0).
+func (t Token) UnicodeRange() (start rune, end rune) {
+    if t._type == TypeUnicodeRange {
+        return t.rangeStart, t.rangeEnd
+    }
+    return 0, 0
+}
+
 // HashType returns the hash type of a <hash-token>. If the token is not a
 // hash token, returns HashType("")
 func (t Token) HashType() HashType {
@@ -347,3 +397,18 @@ func AtKeyword(s string) Token {
         stringValue: s,
     }
 }
+
+func Comment(s string) Token {
+    return Token{
+        _type:       TypeComment,
+        stringValue: s,
+    }
+}
+
+func UnicodeRange(start rune, end rune) Token {
+    return Token{
+        _type:      TypeUnicodeRange,
+        rangeStart: start,
+        rangeEnd:   end,
+    }
+}
@@ -6,38 +6,18 @@ import (
     "github.com/tawesoft/golib/v2/text/runeio"
 )
 
-// position0 returns a [token.Position] from an offset, with a length of
-// zero.
-func position0(start runeio.Offset) token.Position {
-    return token.Position{
-        Byte: start.Byte,
-        Rune: start.Rune,
-        Line: start.Line,
-        End:  start.Byte,
-    }
-}
-
-// position1 returns a [token.Position] from an offset with a length of
-// one byte.
-func position1(start runeio.Offset) token.Position {
-    return token.Position{
-        Byte: start.Byte,
-        Rune: start.Rune,
-        Line: start.Line,
-        End:  start.Byte + 1,
-    }
-}
-
 // position returns a [token.Position] from a (start, end) offset pair.
 func position(start runeio.Offset, end runeio.Offset) token.Position {
     if end.Byte < start.Byte {
         must.Neverf("token end before token start")
     }
     return token.Position{
-        Byte: start.Byte,
-        Rune: start.Rune,
-        Line: start.Line,
-        End:  end.Byte - start.Byte,
+        Byte:    start.Byte,
+        Rune:    start.Rune,
+        Line:    start.Line,
+        End:     end.Byte,
+        EndRune: end.Rune,
+        EndLine: end.Line,
     }
 }
 
@@ -58,6 +38,15 @@ func runeIsHexDigit(x rune) bool {
            ((x >= 'A') && (x <= 'F'))
 }
 
+// hexValue returns the value of a hex digit.
+func hexValue(x rune) rune {
+    switch {
+        case (x >= 'a') && (x <= 'f'): return x - 'a' + 10
+        case (x >= 'A') && (x <= 'F'): return x - 'A' + 10
+        default:                       return x - '0'
+    }
+}
+
 func runeIsDigit(x rune) bool {
     return (x >= '0') && (x <= '9')
 }
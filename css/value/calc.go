@@ -0,0 +1,405 @@
+package value
+
+import (
+    "math"
+    "sort"
+    "strings"
+)
+
+// Op is the operation of a node in a calculation tree.
+type Op string
+const (
+    OpValue   = Op("value")   // a numeric value
+    OpSum     = Op("sum")     // the sum of all Args
+    OpProduct = Op("product") // the product of all Args
+    OpNegate  = Op("negate")  // the single Arg, negated
+    OpInvert  = Op("invert")  // the reciprocal of the single Arg
+    OpMin     = Op("min")     // min() of all Args
+    OpMax     = Op("max")     // max() of all Args
+    OpClamp   = Op("clamp")   // clamp() of exactly three Args
+)
+
+// Calc is a node in a calculation tree, such as that produced by parsing a
+// calc() expression. A plain numeric value is a single node with the Op
+// OpValue.
+//
+// Subtraction is represented as a sum including an OpNegate node, and
+// division as a product including an OpInvert node.
+type Calc struct {
+    Op Op
+
+    // Value is the value of an OpValue node.
+    Value Numeric
+
+    // Args are the children of any other node.
+    Args []Calc
+}
+
+// Value returns a calculation tree containing a single value.
+func Value(n Numeric) Calc {
+    return Calc{Op: OpValue, Value: n}
+}
+
+// IsValue returns true if the calculation is a single numeric value, i.e.
+// it is fully simplified.
+func (c Calc) IsValue() bool {
+    return c.Op == OpValue
+}
+
+// Type returns the type that a calculation resolves to, or an error
+// wrapping [ErrType] if the calculation mixes incompatible types, such as
+// adding a length to a time.
+//
+// A percentage added to (or compared with) some other type, such as a
+// length, takes on that type, because the percentage will resolve to a
+// value of that type. Only a number may be multiplied by, or divide, another
+// type.
+func (c Calc) Type() (Type, error) {
+    switch c.Op {
+        case OpValue:
+            t := c.Value.Type()
+            if t == TypeUnknown { return t, errType{ErrUnit, c.Value.Unit} }
+            return t, nil
+        case OpNegate:
+            if len(c.Args) != 1 { return TypeUnknown, errType{ErrType, string(c.Op)} }
+            return c.Args[0].Type()
+        case OpInvert:
+            if len(c.Args) != 1 { return TypeUnknown, errType{ErrType, string(c.Op)} }
+            t, err := c.Args[0].Type()
+            if err != nil { return t, err }
+            if t != TypeNumber { return TypeUnknown, errType{ErrType, "division by " + string(t)} }
+            return TypeNumber, nil
+        case OpProduct:
+            result := TypeNumber
+            for _, arg := range c.Args {
+                t, err := arg.Type()
+                if err != nil { return t, err }
+                if t == TypeNumber { continue }
+                if result != TypeNumber {
+                    return TypeUnknown, errType{ErrType, string(result) + " * " + string(t)}
+                }
+                result = t
+            }
+            return result, nil
+        case OpClamp:
+            if len(c.Args) != 3 { return TypeUnknown, errType{ErrType, string(c.Op)} }
+            fallthrough
+        case OpSum: fallthrough
+        case OpMin: fallthrough
+        case OpMax:
+            if len(c.Args) == 0 { return TypeUnknown, errType{ErrType, string(c.Op)} }
+            result := TypeUnknown
+            for _, arg := range c.Args {
+                t, err := arg.Type()
+                if err != nil { return t, err }
+                switch {
+                    case result == TypeUnknown: result = t
+                    case result == t:
+                    case (result == TypePercentage) && (t != TypeNumber): result = t
+                    case (t == TypePercentage) && (result != TypeNumber):
+                    default:
+                        return TypeUnknown, errType{ErrType, string(result) + " and " + string(t)}
+                }
+            }
+            return result, nil
+        default:
+            return TypeUnknown, errType{ErrType, string(c.Op)}
+    }
+}
+
+// Contains returns true if any value in the calculation tree satisfies the
+// predicate function f. For example, this can be used to test if a
+// calculation contains a percentage.
+func (c Calc) Contains(f func(n Numeric) bool) bool {
+    if c.Op == OpValue { return f(c.Value) }
+    for _, arg := range c.Args {
+        if arg.Contains(f) { return true }
+    }
+    return false
+}
+
+// Map returns a copy of the calculation tree with f applied to every value.
+// The result is not simplified.
+func (c Calc) Map(f func(n Numeric) Numeric) Calc {
+    if c.Op == OpValue { return Value(f(c.Value)) }
+    args := make([]Calc, len(c.Args))
+    for i, arg := range c.Args {
+        args[i] = arg.Map(f)
+    }
+    return Calc{Op: c.Op, Args: args}
+}
+
+// Simplify returns the calculation tree simplified, following the "simplify
+// a calculation tree" algorithm in the specification. Absolute dimensions
+// are converted to their canonical unit, like terms are combined, and
+// anything that can be computed without a [Context] is computed.
+func (c Calc) Simplify() Calc {
+    return simplify(c)
+}
+
+// Evaluate resolves relative units and percentages in a calculation tree
+// using the given Context, and returns the computed value in the canonical
+// unit for its type (for example, lengths are returned in pixels).
+//
+// An error wrapping [ErrUnresolved] is returned if the calculation cannot
+// be reduced to a single value, for example if it contains a percentage but
+// the Context has no PercentageBasis.
+func (c Calc) Evaluate(ctx Context) (Numeric, error) {
+    if _, err := c.Type(); err != nil { return Numeric{}, err }
+    result := simplify(c.Map(func(n Numeric) Numeric {
+        return n.Resolve(ctx)
+    }))
+    if result.Op != OpValue {
+        return Numeric{}, errType{ErrUnresolved, result.String()}
+    }
+    return result.Value, nil
+}
+
+func simplify(c Calc) Calc {
+    if c.Op == OpValue { return Value(c.Value.Canonical()) }
+
+    args := make([]Calc, 0, len(c.Args))
+    for _, arg := range c.Args {
+        arg = simplify(arg)
+        if (arg.Op == c.Op) && ((c.Op == OpSum) || (c.Op == OpProduct)) {
+            args = append(args, arg.Args...) // flatten
+        } else {
+            args = append(args, arg)
+        }
+    }
+
+    switch c.Op {
+        case OpNegate:
+            x := args[0]
+            if x.Op == OpValue { return Value(Numeric{-x.Value.Value, x.Value.Unit}) }
+            if x.Op == OpNegate { return x.Args[0] }
+            if (x.Op == OpSum) && allValues(x.Args) {
+                sum := make([]Calc, len(x.Args))
+                for i, y := range x.Args {
+                    sum[i] = Value(Numeric{-y.Value.Value, y.Value.Unit})
+                }
+                return Calc{Op: OpSum, Args: sum}
+            }
+        case OpInvert:
+            x := args[0]
+            if (x.Op == OpValue) && (x.Value.Unit == "") { return Value(Number(1.0 / x.Value.Value)) }
+            if x.Op == OpInvert { return x.Args[0] }
+        case OpMin: fallthrough
+        case OpMax:
+            args = simplifyMinMax(c.Op, args)
+            if len(args) == 1 { return args[0] }
+        case OpClamp:
+            if v, ok := sameUnit(args); ok {
+                result := v[1]
+                result.Value = math.Max(v[0].Value, math.Min(v[1].Value, v[2].Value))
+                return Value(result)
+            }
+        case OpSum:
+            args = simplifySum(args)
+            if len(args) == 1 { return args[0] }
+        case OpProduct:
+            args = simplifyProduct(args)
+            if len(args) == 1 { return args[0] }
+            if (len(args) == 2) && (args[0].Op == OpValue) && (args[0].Value.Unit == "") &&
+                (args[1].Op == OpSum) && allValues(args[1].Args) {
+                // distribute a number into a sum
+                factor := args[0].Value.Value
+                sum := make([]Calc, len(args[1].Args))
+                for i, x := range args[1].Args {
+                    sum[i] = Value(Numeric{x.Value.Value * factor, x.Value.Unit})
+                }
+                return Calc{Op: OpSum, Args: sum}
+            }
+    }
+
+    return Calc{Op: c.Op, Args: args}
+}
+
+// simplifySum combines values with the same unit, and sorts the values
+// in the order numbers, percentages, then dimensions by unit.
+func simplifySum(args []Calc) []Calc {
+    var values []Numeric
+    var rest []Calc
+    combine:
+    for _, arg := range args {
+        if arg.Op != OpValue {
+            rest = append(rest, arg)
+            continue
+        }
+        for i := range values {
+            if values[i].Unit == arg.Value.Unit {
+                values[i].Value += arg.Value.Value
+                continue combine
+            }
+        }
+        values = append(values, arg.Value)
+    }
+
+    sort.SliceStable(values, func(i, j int) bool {
+        return unitOrder(values[i].Unit) < unitOrder(values[j].Unit)
+    })
+
+    result := make([]Calc, 0, len(values) + len(rest))
+    for _, v := range values {
+        result = append(result, Value(v))
+    }
+    return append(result, rest...)
+}
+
+func unitOrder(u string) string {
+    switch u {
+        case "":  return "0"
+        case "%": return "1"
+    }
+    return "2" + u
+}
+
+// simplifyMinMax combines values with the same unit, keeping only the
+// smallest (or largest) in the position of the first.
+func simplifyMinMax(op Op, args []Calc) []Calc {
+    result := make([]Calc, 0, len(args))
+    combine:
+    for _, arg := range args {
+        if arg.Op == OpValue {
+            for i := range result {
+                x := &result[i]
+                if (x.Op != OpValue) || (x.Value.Unit != arg.Value.Unit) { continue }
+                if op == OpMin {
+                    x.Value.Value = math.Min(x.Value.Value, arg.Value.Value)
+                } else {
+                    x.Value.Value = math.Max(x.Value.Value, arg.Value.Value)
+                }
+                continue combine
+            }
+        }
+        result = append(result, arg)
+    }
+    return result
+}
+
+// simplifyProduct multiplies together all numbers, and any dimension, and
+// puts the resulting value first.
+func simplifyProduct(args []Calc) []Calc {
+    product := Number(1.0)
+    var rest []Calc
+    numbers := 0
+    for _, arg := range args {
+        if (arg.Op != OpValue) || ((arg.Value.Unit != "") && (product.Unit != "")) {
+            rest = append(rest, arg)
+            continue
+        }
+        numbers++
+        product.Value *= arg.Value.Value
+        if arg.Value.Unit != "" { product.Unit = arg.Value.Unit }
+    }
+    if numbers == 0 { return rest }
+    return append([]Calc{Value(product)}, rest...)
+}
+
+// sameUnit returns the values of args if they are all values with the same
+// unit.
+func sameUnit(args []Calc) ([]Numeric, bool) {
+    if !allValues(args) { return nil, false }
+    result := make([]Numeric, len(args))
+    for i, arg := range args {
+        if arg.Value.Unit != args[0].Value.Unit { return nil, false }
+        result[i] = arg.Value
+    }
+    return result, true
+}
+
+func allValues(args []Calc) bool {
+    for _, arg := range args {
+        if arg.Op != OpValue { return false }
+    }
+    return true
+}
+
+// String returns the calculation in CSS syntax. A single value is written
+// as-is e.g. "10px", min(), max(), and clamp() are written as functions, and
+// anything else is wrapped in calc().
+func (c Calc) String() string {
+    switch c.Op {
+        case OpValue:
+            if isFinite(c.Value.Value) { return c.Value.String() }
+            return "calc(" + serializeValue(c.Value) + ")"
+        case OpMin: fallthrough
+        case OpMax: fallthrough
+        case OpClamp:
+            return serialize(c, false)
+    }
+    return "calc(" + serialize(c, false) + ")"
+}
+
+func isFinite(x float64) bool {
+    return !(math.IsNaN(x) || math.IsInf(x, 0))
+}
+
+// serializeValue writes a value inside a math function, where a value like
+// infinity has to be written as "infinity * 1px".
+func serializeValue(n Numeric) string {
+    if isFinite(n.Value) || (n.Unit == "") { return n.String() }
+    return formatNumber(n.Value) + " * 1" + n.Unit
+}
+
+// serialize writes a calculation, without the wrapping calc(). If nested,
+// a sum or product is written in parentheses.
+func serialize(c Calc, nested bool) string {
+    var sb strings.Builder
+    switch c.Op {
+        case OpValue:
+            return serializeValue(c.Value)
+        case OpMin: fallthrough
+        case OpMax: fallthrough
+        case OpClamp:
+            sb.WriteString(string(c.Op))
+            sb.WriteString("(")
+            for i, arg := range c.Args {
+                if i > 0 { sb.WriteString(", ") }
+                sb.WriteString(serialize(arg, false))
+            }
+            sb.WriteString(")")
+        case OpSum:
+            if nested { sb.WriteString("(") }
+            for i, arg := range c.Args {
+                switch {
+                    case i == 0:
+                        sb.WriteString(serialize(arg, true))
+                    case arg.Op == OpNegate:
+                        sb.WriteString(" - ")
+                        sb.WriteString(serialize(arg.Args[0], true))
+                    case (arg.Op == OpValue) && (arg.Value.Value < 0):
+                        sb.WriteString(" - ")
+                        sb.WriteString(serializeValue(Numeric{-arg.Value.Value, arg.Value.Unit}))
+                    default:
+                        sb.WriteString(" + ")
+                        sb.WriteString(serialize(arg, true))
+                }
+            }
+            if nested { sb.WriteString(")") }
+        case OpProduct:
+            if nested { sb.WriteString("(") }
+            for i, arg := range c.Args {
+                switch {
+                    case (i > 0) && (arg.Op == OpInvert):
+                        sb.WriteString(" / ")
+                        sb.WriteString(serialize(arg.Args[0], true))
+                    case i > 0:
+                        sb.WriteString(" * ")
+                        fallthrough
+                    default:
+                        sb.WriteString(serialize(arg, true))
+                }
+            }
+            if nested { sb.WriteString(")") }
+        case OpNegate:
+            sb.WriteString("(-1 * ")
+            sb.WriteString(serialize(c.Args[0], true))
+            sb.WriteString(")")
+        case OpInvert:
+            sb.WriteString("(1 / ")
+            sb.WriteString(serialize(c.Args[0], true))
+            sb.WriteString(")")
+    }
+    return sb.String()
+}
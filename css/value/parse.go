@@ -0,0 +1,315 @@
+package value
+
+import (
+    "fmt"
+    "math"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+)
+
+// Tokenizer produces CSS tokens - it is implemented, for example, by a
+// [tokenizer.Tokenizer].
+type Tokenizer interface {
+    Next() token.Token
+}
+
+var (
+    ErrSyntax               = fmt.Errorf("invalid value syntax")
+    ErrUnexpectedEOF        = fmt.Errorf("unexpected end of file")
+    ErrUnexpectedTrailing   = fmt.Errorf("unexpected trailing input")
+    ErrUnrecognisedFunction = fmt.Errorf("unrecognised function")
+    ErrUnit                 = fmt.Errorf("unrecognised unit")
+    ErrType                 = fmt.Errorf("incompatible types")
+    ErrUnresolved           = fmt.Errorf("value cannot be fully resolved")
+)
+
+type errSyntax struct {
+    err error
+    at token.Position
+}
+
+func (e errSyntax) Unwrap() error {
+    return e.err
+}
+
+func (e errSyntax) Error() string {
+    return fmt.Sprintf("error at %s: %s", e.at, e.err.Error())
+}
+
+type errType struct {
+    err error
+    detail string
+}
+
+func (e errType) Unwrap() error {
+    return e.err
+}
+
+func (e errType) Error() string {
+    return fmt.Sprintf("%s: %s", e.err.Error(), e.detail)
+}
+
+// ParseString parses a numeric value, or a math function such as calc(),
+// from a string.
+func ParseString(s string) (Calc, error) {
+    k := tokenizer.New(strings.NewReader(s))
+    result, err := Parse(k)
+    if err == nil {
+        t := nextExceptWS(k)
+        if !t.Is(token.TypeEOF) {
+            err = errSyntax{ErrUnexpectedTrailing, t.Position()}
+        }
+    }
+    if len(k.Errors()) > 0 {
+        return result, fmt.Errorf("parse errors: %+v", k.Errors())
+    }
+    return result, err
+}
+
+// Parse parses a numeric value, such as "10px", or a math function, such as
+// "calc(100% - 2em)", from CSS tokens. The result is type-checked (see
+// [Calc.Type]) and simplified (see [Calc.Simplify]).
+//
+// The math functions calc(), min(), max(), and clamp() are supported, as are
+// the constants e, pi, infinity, -infinity, and NaN.
+//
+// Note: the caller should ensure that, after a valid value is returned, the
+// tokenizer produces token.EOF() (possibly preceded by whitespace) if
+// necessary when parsing an entire input as a value.
+func Parse(t Tokenizer) (Calc, error) {
+    first := nextExceptWS(t)
+    tokens := []token.Token{first}
+
+    if first.Is(token.TypeFunction) {
+        depth := 1
+        for depth > 0 {
+            x := t.Next()
+            switch x.Type() {
+                case token.TypeEOF:
+                    return Calc{}, errSyntax{ErrUnexpectedEOF, x.Position()}
+                case token.TypeFunction: fallthrough
+                case token.TypeLeftParen:
+                    depth++
+                case token.TypeRightParen:
+                    depth--
+            }
+            tokens = append(tokens, x)
+        }
+    }
+
+    p := parser{tokens: tokens}
+    result, err := p.value()
+    if err != nil { return Calc{}, err }
+    if _, err := result.Type(); err != nil {
+        return Calc{}, errSyntax{err, first.Position()}
+    }
+    return result.Simplify(), nil
+}
+
+// ParseLength is like [Parse], but returns an error wrapping [ErrType]
+// unless the result is a <length>. A plain zero, without a unit, is also
+// accepted as a length.
+func ParseLength(t Tokenizer) (Calc, error) {
+    return parseTyped(t, false)
+}
+
+// ParseLengthPercentage is like [ParseLength], but also accepts a
+// <percentage>, or a math function that mixes lengths and percentages, such
+// as "calc(100% - 2em)".
+func ParseLengthPercentage(t Tokenizer) (Calc, error) {
+    return parseTyped(t, true)
+}
+
+func parseTyped(t Tokenizer, percentages bool) (Calc, error) {
+    c, err := Parse(t)
+    if err != nil { return c, err }
+
+    if (c.Op == OpValue) && (c.Value == Number(0)) {
+        return Value(Dimension(0, "px")), nil
+    }
+
+    typ, _ := c.Type()
+    isPercentage := func(n Numeric) bool { return n.Unit == "%" }
+    switch {
+        case typ == TypeLength:
+            if percentages || !c.Contains(isPercentage) { return c, nil }
+        case (typ == TypePercentage) && percentages:
+            return c, nil
+    }
+    return Calc{}, errType{ErrType, fmt.Sprintf("expected length but got %s", c)}
+}
+
+func nextExceptWS(t Tokenizer) token.Token {
+    for {
+        x := t.Next()
+        if !x.Is(token.TypeWhitespace) { return x }
+    }
+}
+
+// parser is a recursive descent parser for the grammar:
+//
+//     <calc-sum>     = <calc-product> [ [ '+' | '-' ] <calc-product> ]*
+//     <calc-product> = <calc-value> [ [ '*' | '/' ] <calc-value> ]*
+//     <calc-value>   = <number> | <dimension> | <percentage> |
+//                      <calc-constant> | ( <calc-sum> ) | <math-function>
+//
+// The '+' and '-' operators must be surrounded by whitespace.
+type parser struct {
+    tokens []token.Token
+    pos int
+}
+
+// peek returns the next token, or EOF.
+func (p *parser) peek() token.Token {
+    if p.pos >= len(p.tokens) { return token.EOF() }
+    return p.tokens[p.pos]
+}
+
+func (p *parser) next() token.Token {
+    t := p.peek()
+    if p.pos < len(p.tokens) { p.pos++ }
+    return t
+}
+
+// skipWS skips any whitespace, returning true if there was any.
+func (p *parser) skipWS() bool {
+    skipped := false
+    for p.peek().Is(token.TypeWhitespace) {
+        p.pos++
+        skipped = true
+    }
+    return skipped
+}
+
+func (p *parser) errorf(err error) error {
+    return errSyntax{err, p.peek().Position()}
+}
+
+func (p *parser) sum() (Calc, error) {
+    first, err := p.product()
+    if err != nil { return first, err }
+    args := []Calc{first}
+
+    for {
+        start := p.pos
+        before := p.skipWS()
+        t := p.peek()
+        if !(t.Is(token.TypeDelim) && ((t.Delim() == '+') || (t.Delim() == '-'))) {
+            p.pos = start
+            break
+        }
+        p.pos++
+        if !(before && p.skipWS()) { return Calc{}, errSyntax{ErrSyntax, t.Position()} }
+
+        x, err := p.product()
+        if err != nil { return x, err }
+        if t.Delim() == '-' { x = Calc{Op: OpNegate, Args: []Calc{x}} }
+        args = append(args, x)
+    }
+
+    if len(args) == 1 { return first, nil }
+    return Calc{Op: OpSum, Args: args}, nil
+}
+
+func (p *parser) product() (Calc, error) {
+    first, err := p.value()
+    if err != nil { return first, err }
+    args := []Calc{first}
+
+    for {
+        start := p.pos
+        p.skipWS()
+        t := p.peek()
+        if !(t.Is(token.TypeDelim) && ((t.Delim() == '*') || (t.Delim() == '/'))) {
+            p.pos = start
+            break
+        }
+        p.pos++
+        p.skipWS()
+
+        x, err := p.value()
+        if err != nil { return x, err }
+        if t.Delim() == '/' { x = Calc{Op: OpInvert, Args: []Calc{x}} }
+        args = append(args, x)
+    }
+
+    if len(args) == 1 { return first, nil }
+    return Calc{Op: OpProduct, Args: args}, nil
+}
+
+func (p *parser) value() (Calc, error) {
+    p.skipWS()
+    t := p.next()
+    _, v := t.NumericValue()
+
+    switch t.Type() {
+        case token.TypeNumber:
+            return Value(Number(v)), nil
+        case token.TypePercentage:
+            return Value(Percentage(v)), nil
+        case token.TypeDimension:
+            n := Dimension(v, t.Unit())
+            if n.Type() == TypeUnknown { return Calc{}, errSyntax{ErrUnit, t.Position()} }
+            return Value(n), nil
+        case token.TypeIdent:
+            switch strings.ToLower(t.StringValue()) {
+                case "e":         return Value(Number(math.E)), nil
+                case "pi":        return Value(Number(math.Pi)), nil
+                case "infinity":  return Value(Number(math.Inf(1))), nil
+                case "-infinity": return Value(Number(math.Inf(-1))), nil
+                case "nan":       return Value(Number(math.NaN())), nil
+            }
+            return Calc{}, errSyntax{ErrSyntax, t.Position()}
+        case token.TypeLeftParen:
+            x, err := p.sum()
+            if err != nil { return x, err }
+            return x, p.close()
+        case token.TypeFunction:
+            return p.function(t)
+        case token.TypeEOF:
+            return Calc{}, errSyntax{ErrUnexpectedEOF, t.Position()}
+        default:
+            return Calc{}, errSyntax{ErrSyntax, t.Position()}
+    }
+}
+
+// close consumes a closing parenthesis, preceded by optional whitespace.
+func (p *parser) close() error {
+    p.skipWS()
+    t := p.next()
+    if t.Is(token.TypeRightParen) { return nil }
+    if t.Is(token.TypeEOF) { return errSyntax{ErrUnexpectedEOF, t.Position()} }
+    return errSyntax{ErrSyntax, t.Position()}
+}
+
+func (p *parser) function(f token.Token) (Calc, error) {
+    var op Op
+    switch strings.ToLower(f.StringValue()) {
+        case "calc":
+            x, err := p.sum()
+            if err != nil { return x, err }
+            return x, p.close()
+        case "min":   op = OpMin
+        case "max":   op = OpMax
+        case "clamp": op = OpClamp
+        default:
+            return Calc{}, errSyntax{ErrUnrecognisedFunction, f.Position()}
+    }
+
+    var args []Calc
+    for {
+        x, err := p.sum()
+        if err != nil { return x, err }
+        args = append(args, x)
+
+        p.skipWS()
+        if !p.peek().Is(token.TypeComma) { break }
+        p.pos++
+    }
+    if (op == OpClamp) && (len(args) != 3) {
+        return Calc{}, errSyntax{ErrSyntax, f.Position()}
+    }
+    return Calc{Op: op, Args: args}, p.close()
+}
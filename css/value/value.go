@@ -0,0 +1,302 @@
+// Package value implements parsing, converting, and evaluating CSS numeric
+// values, such as <length> and <percentage>, and math functions such as
+// calc(), based on [CSS Values and Units Module Level 4] (W3C Working Draft),
+// 6 April 2023.
+//
+// For <color> values, see [css/color].
+//
+// Values are parsed from CSS tokens (see [css/tokenizer]) into a [Calc],
+// which is a tree of calculation nodes. A plain value, such as "10px", is
+// simply a tree containing a single node. Math functions are type-checked and
+// simplified as they are parsed, so that, for example, "calc(1in + 4px)" is
+// parsed as the single value "100px".
+//
+// Relative units, such as "em" or "vw", and percentages can only be resolved
+// once more is known about the element a value applies to. Supply this
+// information in a [Context], and use [Calc.Evaluate] to get the final
+// computed value.
+//
+// [CSS Values and Units Module Level 4]: https://www.w3.org/TR/css-values-4/
+//
+// This software includes material derived from CSS Values and Units Module
+// Level 4, W3C Working Draft, 6 April 2023. Copyright © 2023 W3C® (MIT,
+// ERCIM, Keio, Beihang). See LICENSE-PARTS.txt and TRADEMARKS.md.
+package value
+
+import (
+    "math"
+    "strconv"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+// Type is the type of a numeric value, such as a <length>.
+type Type string
+const (
+    TypeUnknown    = Type("")
+    TypeNumber     = Type("number")
+    TypePercentage = Type("percentage")
+    TypeLength     = Type("length")
+    TypeAngle      = Type("angle")
+    TypeTime       = Type("time")
+    TypeFrequency  = Type("frequency")
+    TypeResolution = Type("resolution")
+    TypeFlex       = Type("flex")
+)
+
+type unit struct {
+    _type Type
+
+    // factor converts to the canonical unit for the type, or is zero for a
+    // relative unit that cannot be converted without a Context.
+    factor float64
+}
+
+// units maps each (lower case) unit to its type. The canonical units are
+// "px", "deg", "s", "hz", and "dppx".
+var units = map[string]unit{
+    // absolute lengths
+    "px": {TypeLength, 1.0},
+    "cm": {TypeLength, 96.0 / 2.54},
+    "mm": {TypeLength, 96.0 / 25.4},
+    "q":  {TypeLength, 96.0 / 101.6},
+    "in": {TypeLength, 96.0},
+    "pt": {TypeLength, 96.0 / 72.0},
+    "pc": {TypeLength, 16.0},
+
+    // font-relative lengths
+    "em":  {TypeLength, 0},
+    "rem": {TypeLength, 0},
+    "ex":  {TypeLength, 0},
+    "rex": {TypeLength, 0},
+    "ch":  {TypeLength, 0},
+    "rch": {TypeLength, 0},
+    "lh":  {TypeLength, 0},
+    "rlh": {TypeLength, 0},
+
+    // viewport-relative lengths
+    "vw":    {TypeLength, 0}, "svw":    {TypeLength, 0},
+    "lvw":   {TypeLength, 0}, "dvw":    {TypeLength, 0},
+    "vh":    {TypeLength, 0}, "svh":    {TypeLength, 0},
+    "lvh":   {TypeLength, 0}, "dvh":    {TypeLength, 0},
+    "vi":    {TypeLength, 0}, "svi":    {TypeLength, 0},
+    "lvi":   {TypeLength, 0}, "dvi":    {TypeLength, 0},
+    "vb":    {TypeLength, 0}, "svb":    {TypeLength, 0},
+    "lvb":   {TypeLength, 0}, "dvb":    {TypeLength, 0},
+    "vmin":  {TypeLength, 0}, "svmin":  {TypeLength, 0},
+    "lvmin": {TypeLength, 0}, "dvmin":  {TypeLength, 0},
+    "vmax":  {TypeLength, 0}, "svmax":  {TypeLength, 0},
+    "lvmax": {TypeLength, 0}, "dvmax":  {TypeLength, 0},
+
+    // angles
+    "deg":  {TypeAngle, 1.0},
+    "grad": {TypeAngle, 0.9},
+    "rad":  {TypeAngle, 180.0 / math.Pi},
+    "turn": {TypeAngle, 360.0},
+
+    // durations
+    "s":  {TypeTime, 1.0},
+    "ms": {TypeTime, 0.001},
+
+    // frequencies
+    "hz":  {TypeFrequency, 1.0},
+    "khz": {TypeFrequency, 1000.0},
+
+    // resolutions
+    "dppx": {TypeResolution, 1.0},
+    "x":    {TypeResolution, 1.0},
+    "dpi":  {TypeResolution, 1.0 / 96.0},
+    "dpcm": {TypeResolution, 2.54 / 96.0},
+
+    // flexible lengths
+    "fr": {TypeFlex, 0},
+}
+
+// Numeric is a number, percentage, or dimension, such as "10px".
+type Numeric struct {
+    Value float64
+
+    // Unit is the empty string for a number, "%" for a percentage, or the
+    // unit of a dimension, in lower case, e.g. "px".
+    Unit string
+}
+
+// Number returns a Numeric value with no unit.
+func Number(x float64) Numeric {
+    return Numeric{Value: x}
+}
+
+// Percentage returns a Numeric value with the unit "%", where 50% is given
+// as 50.0.
+func Percentage(x float64) Numeric {
+    return Numeric{Value: x, Unit: "%"}
+}
+
+// Dimension returns a Numeric value with the given unit, which is converted
+// to lower case.
+func Dimension(x float64, unit string) Numeric {
+    return Numeric{Value: x, Unit: strings.ToLower(unit)}
+}
+
+// Type returns the type of a numeric value, or TypeUnknown if it has an
+// unrecognised unit.
+func (n Numeric) Type() Type {
+    switch n.Unit {
+        case "":  return TypeNumber
+        case "%": return TypePercentage
+    }
+    return units[n.Unit]._type
+}
+
+// IsAbsolute returns true if the value is a number, or a dimension that
+// can be converted to its canonical unit without a [Context].
+func (n Numeric) IsAbsolute() bool {
+    if n.Unit == "" { return true }
+    return units[n.Unit].factor != 0
+}
+
+// Canonical returns the value converted to the canonical unit for its type
+// (for example, "1in" is converted to "96px"). Numbers, percentages, and
+// relative or unknown units are returned unchanged.
+func (n Numeric) Canonical() Numeric {
+    u, ok := units[n.Unit]
+    if !ok || (u.factor == 0) { return n }
+    return Numeric{Value: n.Value * u.factor, Unit: canonicalUnit(u._type)}
+}
+
+// To converts a value to another unit of the same type, returning false if
+// this is not possible without a [Context] (see [Numeric.Resolve]).
+func (n Numeric) To(unit string) (Numeric, bool) {
+    unit = strings.ToLower(unit)
+    if n.Unit == unit { return n, true }
+
+    from, ok1 := units[n.Unit]
+    to, ok2 := units[unit]
+    if !(ok1 && ok2) { return n, false }
+    if (from._type != to._type) || (from.factor == 0) || (to.factor == 0) {
+        return n, false
+    }
+    return Numeric{Value: n.Value * from.factor / to.factor, Unit: unit}, true
+}
+
+func canonicalUnit(t Type) string {
+    switch t {
+        case TypeLength:     return "px"
+        case TypeAngle:      return "deg"
+        case TypeTime:       return "s"
+        case TypeFrequency:  return "hz"
+        case TypeResolution: return "dppx"
+    }
+    return ""
+}
+
+// String returns the value in CSS syntax, e.g. "10px", "50%", or "1.5".
+func (n Numeric) String() string {
+    return formatNumber(n.Value) + n.Unit
+}
+
+func formatNumber(x float64) string {
+    switch {
+        case math.IsNaN(x):     return "NaN"
+        case math.IsInf(x, 1):  return "infinity"
+        case math.IsInf(x, -1): return "-infinity"
+    }
+    return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+// Context is the information needed to resolve relative lengths and
+// percentages into absolute values. All lengths are given in pixels.
+//
+// A horizontal writing mode is assumed, so that the "vi" unit is
+// equivalent to "vw", and "vb" to "vh". The small, large, and dynamic
+// viewport units (e.g. "svh", "lvh", "dvh") all resolve against the same
+// viewport size.
+type Context struct {
+    // FontSize is the computed font-size of the element, for "em" units,
+    // and RootFontSize is the font-size of the root element, for "rem" units.
+    FontSize, RootFontSize float64
+
+    // XHeight and ChWidth are the x-height and the advance width of the "0"
+    // glyph in the element's font, for "ex" and "ch" units, respectively. If
+    // zero, these default to half of FontSize, as permitted by the
+    // specification. The "rex" and "rch" units likewise default to half of
+    // RootFontSize.
+    XHeight, ChWidth float64
+
+    // LineHeight is the computed line-height of the element, for "lh" units,
+    // and RootLineHeight is that of the root element, for "rlh" units.
+    LineHeight, RootLineHeight float64
+
+    // ViewportWidth and ViewportHeight are the size of the initial
+    // containing block, for viewport-relative units such as "vw".
+    ViewportWidth, ViewportHeight float64
+
+    // PercentageBasis is the value that percentages are resolved against,
+    // which depends on the property a value is for. For example, "50%" in a
+    // width property resolves against the width of the containing block. If
+    // Nothing, percentages are left unresolved.
+    PercentageBasis maybe.M[Numeric]
+}
+
+// Resolve converts a value to the canonical unit for its type, using the
+// Context to resolve relative lengths and percentages. A value that cannot
+// be resolved, for example a percentage with no PercentageBasis, or a value
+// with an unknown unit, is returned unchanged.
+func (n Numeric) Resolve(ctx Context) Numeric {
+    if n.Unit == "%" {
+        basis, ok := ctx.PercentageBasis.Unpack()
+        if !ok { return n }
+        basis = basis.Resolve(Context{
+            FontSize:       ctx.FontSize,
+            RootFontSize:   ctx.RootFontSize,
+            XHeight:        ctx.XHeight,
+            ChWidth:        ctx.ChWidth,
+            LineHeight:     ctx.LineHeight,
+            RootLineHeight: ctx.RootLineHeight,
+            ViewportWidth:  ctx.ViewportWidth,
+            ViewportHeight: ctx.ViewportHeight,
+        })
+        return Numeric{Value: basis.Value * n.Value / 100.0, Unit: basis.Unit}
+    }
+
+    u, ok := units[n.Unit]
+    if !ok { return n }
+    if u.factor != 0 { return n.Canonical() }
+    if u._type != TypeLength { return n }
+
+    orDefault := func(x float64, def float64) float64 {
+        if x == 0 { return def }
+        return x
+    }
+
+    // treat the small, large, and dynamic viewport units e.g. "svh" the same
+    // as the plain viewport units e.g. "vh".
+    name := n.Unit
+    if (len(name) > 2) && strings.ContainsRune("sld", rune(name[0])) && (name[1] == 'v') {
+        name = name[1:]
+    }
+
+    var px float64
+    switch name {
+        case "em":  px = ctx.FontSize
+        case "rem": px = ctx.RootFontSize
+        case "ex":  px = orDefault(ctx.XHeight, ctx.FontSize / 2.0)
+        case "rex": px = ctx.RootFontSize / 2.0
+        case "ch":  px = orDefault(ctx.ChWidth, ctx.FontSize / 2.0)
+        case "rch": px = ctx.RootFontSize / 2.0
+        case "lh":  px = ctx.LineHeight
+        case "rlh": px = ctx.RootLineHeight
+        case "vw":  fallthrough
+        case "vi":  px = ctx.ViewportWidth / 100.0
+        case "vh":  fallthrough
+        case "vb":  px = ctx.ViewportHeight / 100.0
+        case "vmin":
+            px = math.Min(ctx.ViewportWidth, ctx.ViewportHeight) / 100.0
+        case "vmax":
+            px = math.Max(ctx.ViewportWidth, ctx.ViewportHeight) / 100.0
+        default:
+            return n
+    }
+    return Numeric{Value: n.Value * px, Unit: "px"}
+}
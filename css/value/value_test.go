@@ -0,0 +1,232 @@
+package value_test
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/value"
+    "github.com/tawesoft/golib/v2/fun/maybe"
+)
+
+func ExampleParseString() {
+    c, err := value.ParseString("calc(100% - 2 * (1em + 4px))")
+    if err != nil { panic(err) }
+    fmt.Println(c)
+
+    ctx := value.Context{
+        FontSize: 16,
+        PercentageBasis: maybe.Some(value.Dimension(400, "px")),
+    }
+    n, err := c.Evaluate(ctx)
+    if err != nil { panic(err) }
+    fmt.Println(n)
+
+    // Output:
+    // calc(100% - 2em - 8px)
+    // 360px
+}
+
+func TestNumeric_To(t *testing.T) {
+    type row struct {
+        input value.Numeric
+        unit string
+        expected string
+        ok bool
+    }
+    rows := []row{
+        {value.Dimension(1, "in"),   "px",   "96px",  true},
+        {value.Dimension(1, "IN"),   "CM",   "2.54cm", true},
+        {value.Dimension(12, "pt"),  "pc",   "1pc",   true},
+        {value.Dimension(0.5, "turn"), "deg", "180deg", true},
+        {value.Dimension(200, "grad"), "deg", "180deg", true},
+        {value.Dimension(250, "ms"), "s",    "0.25s", true},
+        {value.Dimension(1, "khz"),  "hz",   "1000hz", true},
+        {value.Dimension(96, "dpi"), "x",    "1x",    true},
+        {value.Dimension(1, "em"),   "px",   "1em",   false},
+        {value.Dimension(1, "px"),   "s",    "1px",   false},
+        {value.Percentage(50),       "px",   "50%",   false},
+    }
+    for _, r := range rows {
+        n, ok := r.input.To(r.unit)
+        if ok != r.ok {
+            t.Errorf("%v to %s: expected ok=%v", r.input, r.unit, r.ok)
+        }
+        if n.String() != r.expected {
+            t.Errorf("%v to %s: expected %s but got %s", r.input, r.unit, r.expected, n)
+        }
+    }
+}
+
+func TestNumeric_Resolve(t *testing.T) {
+    ctx := value.Context{
+        FontSize:       20,
+        RootFontSize:   16,
+        LineHeight:     30,
+        RootLineHeight: 24,
+        ViewportWidth:  800,
+        ViewportHeight: 600,
+    }
+    type row struct {
+        input value.Numeric
+        expected string
+    }
+    rows := []row{
+        {value.Dimension(2, "em"),    "40px"},
+        {value.Dimension(2, "rem"),   "32px"},
+        {value.Dimension(2, "ex"),    "20px"},
+        {value.Dimension(2, "ch"),    "20px"},
+        {value.Dimension(2, "lh"),    "60px"},
+        {value.Dimension(2, "rlh"),   "48px"},
+        {value.Dimension(10, "vw"),   "80px"},
+        {value.Dimension(10, "dvh"),  "60px"},
+        {value.Dimension(10, "vi"),   "80px"},
+        {value.Dimension(10, "svb"),  "60px"},
+        {value.Dimension(10, "vmin"), "60px"},
+        {value.Dimension(10, "vmax"), "80px"},
+        {value.Dimension(1, "in"),    "96px"},
+        {value.Dimension(1, "turn"),  "360deg"},
+        {value.Dimension(1, "fr"),    "1fr"},
+        {value.Percentage(50),        "50%"},
+        {value.Number(3),             "3"},
+    }
+    for _, r := range rows {
+        n := r.input.Resolve(ctx)
+        if n.String() != r.expected {
+            t.Errorf("%v: expected %s but got %s", r.input, r.expected, n)
+        }
+    }
+
+    ctx.PercentageBasis = maybe.Some(value.Dimension(10, "em"))
+    if n := value.Percentage(50).Resolve(ctx); n.String() != "100px" {
+        t.Errorf("percentage: expected 100px but got %s", n)
+    }
+}
+
+func TestParseString(t *testing.T) {
+    type row struct {
+        input string
+        expected string
+        err error
+    }
+    rows := []row{
+        {"10px",                       "10px",             nil},
+        {"  50% ",                     "50%",              nil},
+        {"1in",                        "96px",             nil},
+        {"calc(1in + 4px)",            "100px",            nil},
+        {"CALC(2 * 3)",                "6",                nil},
+        {"calc(10px / 4)",             "2.5px",            nil},
+        {"calc((1px + 2px) * 3)",      "9px",              nil},
+        {"calc(1px - 2px)",            "-1px",             nil},
+        {"calc(100% - 10px)",          "calc(100% - 10px)", nil},
+        {"calc(100% - -10px)",         "calc(100% + 10px)", nil},
+        {"calc(1em + 2px + 3em)",      "calc(4em + 2px)",  nil},
+        {"calc(2 * (1em - 10%))",      "calc(-20% + 2em)", nil},
+        {"calc(1em * 2 / 4)",          "0.5em",            nil},
+        {"calc(1rem / (2 * 4))",       "0.125rem",         nil},
+        {"min(10px, 2em, 5px)",        "min(5px, 2em)",    nil},
+        {"min(10px, 1in)",             "10px",             nil},
+        {"max(10px, 20px)",            "20px",             nil},
+        {"clamp(1px, 5px, 3px)",       "3px",              nil},
+        {"clamp(1px, 5%, 3px)",        "clamp(1px, 5%, 3px)", nil},
+        {"calc(min(1px, 2px) + 1px)",  "2px",              nil},
+        {"calc(pi)",                   "3.141592653589793", nil},
+        {"calc(infinity * 1px)",       "calc(infinity * 1px)", nil},
+        {"calc(-infinity)",            "calc(-infinity)",  nil},
+        {"calc(1s + 500ms)",           "1.5s",             nil},
+        {"calc(1px+2px)",              "",                 value.ErrSyntax},
+        {"calc(1px -2px)",             "",                 value.ErrSyntax},
+        {"calc(1px + 2s)",             "",                 value.ErrType},
+        {"calc(1px * 2px)",            "",                 value.ErrType},
+        {"calc(1px / 2px)",            "",                 value.ErrType},
+        {"calc(1 + 2%)",               "",                 value.ErrType},
+        {"calc(1foo)",                 "",                 value.ErrUnit},
+        {"calc(1px",                   "",                 value.ErrUnexpectedEOF},
+        {"clamp(1px, 2px)",            "",                 value.ErrSyntax},
+        {"sin(1)",                     "",                 value.ErrUnrecognisedFunction},
+        {"10px 20px",                  "",                 value.ErrUnexpectedTrailing},
+        {"red",                        "",                 value.ErrSyntax},
+    }
+    for _, r := range rows {
+        c, err := value.ParseString(r.input)
+        if r.err != nil {
+            if !errors.Is(err, r.err) {
+                t.Errorf("%q: expected error %v but got %v (%v)", r.input, r.err, err, c)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%q: unexpected error %v", r.input, err)
+            continue
+        }
+        if c.String() != r.expected {
+            t.Errorf("%q: expected %s but got %s", r.input, r.expected, c)
+        }
+    }
+}
+
+func TestCalc_Evaluate(t *testing.T) {
+    ctx := value.Context{
+        FontSize:       10,
+        ViewportWidth:  1000,
+        ViewportHeight: 500,
+    }
+
+    c, err := value.ParseString("calc(50% - 2em)")
+    if err != nil { t.Fatal(err) }
+
+    _, err = c.Evaluate(ctx)
+    if !errors.Is(err, value.ErrUnresolved) {
+        t.Errorf("expected ErrUnresolved, got %v", err)
+    }
+
+    ctx.PercentageBasis = maybe.Some(value.Dimension(200, "px"))
+    n, err := c.Evaluate(ctx)
+    if err != nil { t.Fatal(err) }
+    if n.String() != "80px" { t.Errorf("expected 80px but got %s", n) }
+
+    c, err = value.ParseString("clamp(1rem, 2vw, 3em)")
+    if err != nil { t.Fatal(err) }
+    ctx.RootFontSize = 16
+    n, err = c.Evaluate(ctx)
+    if err != nil { t.Fatal(err) }
+    if n.String() != "20px" { t.Errorf("expected 20px but got %s", n) }
+}
+
+func TestParseLength(t *testing.T) {
+    type row struct {
+        input string
+        percentages bool
+        expected string
+        ok bool
+    }
+    rows := []row{
+        {"10px",              false, "10px",              true},
+        {"0",                 false, "0px",               true},
+        {"1",                 false, "",                  false},
+        {"50%",               false, "",                  false},
+        {"50%",               true,  "50%",               true},
+        {"calc(50% + 1px)",   false, "",                  false},
+        {"calc(50% + 1px)",   true,  "calc(50% + 1px)",   true},
+        {"1s",                true,  "",                  false},
+    }
+    for _, r := range rows {
+        k := tokenizer.New(strings.NewReader(r.input))
+        var c value.Calc
+        var err error
+        if r.percentages {
+            c, err = value.ParseLengthPercentage(k)
+        } else {
+            c, err = value.ParseLength(k)
+        }
+        if r.ok != (err == nil) {
+            t.Errorf("%q: expected ok=%v but got %v, %v", r.input, r.ok, c, err)
+            continue
+        }
+        if r.ok && (c.String() != r.expected) {
+            t.Errorf("%q: expected %s but got %s", r.input, r.expected, c)
+        }
+    }
+}
@@ -0,0 +1,313 @@
+// Package vars implements CSS custom properties (such as "--main-color")
+// and var() substitution, based on [CSS Custom Properties for Cascading
+// Variables Module Level 1] (W3C Candidate Recommendation Snapshot), 16 June
+// 2022.
+//
+// This works on the declarations produced by [css/parser] (see
+// [parser.Parser.ParseStyleBlockContents]), for a single element: the
+// declarations that apply to it, in cascade order, and the computed custom
+// properties of its parent element, if any.
+//
+// As required by the specification:
+//
+//   - custom property names are case-sensitive;
+//   - custom properties are inherited from the parent;
+//   - a custom property that is part of a dependency cycle (for example,
+//     "--a: var(--b); --b: var(--a)") is invalid at computed-value time, and
+//     takes the "guaranteed-invalid value", as does a custom property
+//     declared with the value "initial", or that references a property with
+//     the guaranteed-invalid value without giving a fallback;
+//   - any other property that is invalid at computed-value time because of a
+//     var() reference computes to "unset".
+//
+// [CSS Custom Properties for Cascading Variables Module Level 1]: https://www.w3.org/TR/css-variables-1/
+//
+// This software includes material derived from CSS Custom Properties for
+// Cascading Variables Module Level 1, W3C Candidate Recommendation Snapshot,
+// 16 June 2022. Copyright © 2022 W3C® (MIT, ERCIM, Keio, Beihang). See
+// LICENSE-PARTS.txt and TRADEMARKS.md.
+package vars
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/css/parser/item"
+    "github.com/tawesoft/golib/v2/css/tokenizer/token"
+)
+
+var (
+    ErrSyntax  = fmt.Errorf("invalid var() syntax")
+    ErrCycle   = fmt.Errorf("custom property dependency cycle")
+    ErrInvalid = fmt.Errorf("var() references a property with the guaranteed-invalid value, and has no fallback")
+)
+
+type errDeclaration struct {
+    err error
+    name string
+}
+
+func (e errDeclaration) Unwrap() error {
+    return e.err
+}
+
+func (e errDeclaration) Error() string {
+    return fmt.Sprintf("invalid at computed-value time: property %q: %s", e.name, e.err.Error())
+}
+
+// Properties maps the name of each custom property, including the leading
+// "--", to its computed value. A custom property that is not in the map has
+// the guaranteed-invalid value.
+type Properties map[string][]item.ComponentValue
+
+// IsCustomProperty returns true if the name of a property is the name of a
+// custom property i.e. it starts with "--".
+func IsCustomProperty(name string) bool {
+    return strings.HasPrefix(name, "--")
+}
+
+// Resolve computes the custom properties declared in decls, inheriting from
+// the computed custom properties of the parent element (which may be nil),
+// and substitutes any var() references in the values of all the
+// declarations.
+//
+// The returned declarations contain one declaration for each property, in
+// the order of the declaration that wins the cascade: the last declaration
+// for that property, or the last declaration marked "!important", if any.
+// A custom property that computes to the guaranteed-invalid value is
+// omitted. The returned Properties are the computed custom properties, which
+// are inherited by any child element.
+//
+// Errors are returned for each declaration that is invalid at computed-value
+// time, wrapping ErrSyntax, ErrCycle, or ErrInvalid.
+func Resolve(decls []item.Declaration, parent Properties) ([]item.Declaration, Properties, []error) {
+    r := resolver{
+        declared: make(map[string]item.Declaration),
+        parent:   parent,
+        computed: make(Properties),
+        state:    make(map[string]state),
+    }
+
+    // cascade
+    var order []string
+    index := make(map[string]int)
+    for _, d := range decls {
+        name := d.Name
+        if !IsCustomProperty(name) { name = strings.ToLower(name) }
+        if prev, exists := r.declared[name]; exists && prev.Important && !d.Important {
+            continue
+        }
+        if i, exists := index[name]; exists {
+            order = append(order[:i], order[i+1:]...)
+            for k, v := range index {
+                if v > i { index[k] = v - 1 }
+            }
+        }
+        index[name] = len(order)
+        order = append(order, name)
+        r.declared[name] = d
+    }
+
+    // inherited custom properties
+    for name, value := range parent {
+        r.computed[name] = value
+    }
+
+    // custom properties
+    for _, name := range order {
+        if IsCustomProperty(name) { r.compute(name) }
+    }
+
+    var result []item.Declaration
+    for _, name := range order {
+        d := r.declared[name]
+        if IsCustomProperty(name) {
+            value, ok := r.computed[name]
+            if !ok { continue }
+            d.Value = value
+            result = append(result, d)
+            continue
+        }
+
+        value, err := r.substitute(d.Value)
+        if err != nil {
+            r.errors = append(r.errors, errDeclaration{err, d.Name})
+            value = []item.ComponentValue{
+                item.PreservedToken(token.Ident("unset")).ToComponentValue(),
+            }
+        }
+        d.Value = value
+        result = append(result, d)
+    }
+
+    return result, r.computed, r.errors
+}
+
+// Substitute replaces any var() references in a value with the values of
+// the given custom properties, returning an error if the value is invalid
+// at computed-value time.
+func Substitute(value []item.ComponentValue, props Properties) ([]item.ComponentValue, error) {
+    r := resolver{computed: props, state: map[string]state{}}
+    return r.substitute(value)
+}
+
+type state int
+const (
+    stateNone state = iota
+    stateVisiting
+    stateDone
+)
+
+type resolver struct {
+    declared map[string]item.Declaration
+    parent Properties
+    computed Properties
+    state map[string]state
+
+    // stack of custom properties currently being computed, and those found
+    // to be in a cycle.
+    stack []string
+    cyclic map[string]bool
+
+    errors []error
+}
+
+// compute computes the value of a custom property declared on this element,
+// by depth-first search of its dependencies.
+func (r *resolver) compute(name string) {
+    switch r.state[name] {
+        case stateVisiting:
+            // found a cycle: every property on the stack since name is in it.
+            if r.cyclic == nil { r.cyclic = make(map[string]bool) }
+            for i := len(r.stack) - 1; i >= 0; i-- {
+                r.cyclic[r.stack[i]] = true
+                if r.stack[i] == name { break }
+            }
+            return
+        case stateDone:
+            return
+    }
+
+    r.state[name] = stateVisiting
+    r.stack = append(r.stack, name)
+    defer func() {
+        r.stack = r.stack[:len(r.stack) - 1]
+        r.state[name] = stateDone
+    }()
+
+    value := r.declared[name].Value
+    delete(r.computed, name) // remove any inherited value
+
+    // CSS-wide keywords
+    if keyword, ok := isKeyword(value); ok {
+        switch keyword {
+            case "inherit": fallthrough
+            case "unset":
+                if v, ok := r.parent[name]; ok { r.computed[name] = v }
+        }
+        return // "initial" is the guaranteed-invalid value
+    }
+
+    result, err := r.substitute(value)
+    if r.cyclic[name] {
+        r.errors = append(r.errors, errDeclaration{ErrCycle, name})
+        return
+    }
+    if err != nil {
+        r.errors = append(r.errors, errDeclaration{err, name})
+        return
+    }
+    r.computed[name] = result
+}
+
+// isKeyword returns the lower case value of a value that is one of the
+// CSS-wide keywords.
+func isKeyword(value []item.ComponentValue) (string, bool) {
+    if (len(value) != 1) || (value[0].Type() != item.TypePreservedToken) { return "", false }
+    t := token.Token(value[0].ToPreservedToken())
+    if !t.Is(token.TypeIdent) { return "", false }
+    switch keyword := strings.ToLower(t.StringValue()); keyword {
+        case "initial": fallthrough
+        case "inherit": fallthrough
+        case "unset":   fallthrough
+        case "revert":  fallthrough
+        case "revert-layer":
+            return keyword, true
+    }
+    return "", false
+}
+
+// lookup returns the computed value of a custom property, computing it
+// first if it is declared on this element.
+func (r *resolver) lookup(name string) ([]item.ComponentValue, bool) {
+    if _, ok := r.declared[name]; ok { r.compute(name) }
+    v, ok := r.computed[name]
+    return v, ok
+}
+
+// substitute replaces var() references in a value, recursively.
+func (r *resolver) substitute(value []item.ComponentValue) ([]item.ComponentValue, error) {
+    var result []item.ComponentValue
+    for _, cv := range value {
+        switch cv.Type() {
+            case item.TypeFunction:
+                f := cv.ToFunction()
+                if strings.EqualFold(f.Name, "var") {
+                    x, err := r.substituteVar(f)
+                    if err != nil { return nil, err }
+                    result = append(result, x...)
+                    continue
+                }
+                args, err := r.substitute(f.Value)
+                if err != nil { return nil, err }
+                f.Value = args
+                cv = f.ToComponentValue()
+            case item.TypeBlock:
+                b := cv.ToBlock()
+                contents, err := r.substitute(b.Value)
+                if err != nil { return nil, err }
+                b.Value = contents
+                cv = b.ToComponentValue()
+        }
+        result = append(result, cv)
+    }
+    return result, nil
+}
+
+// substituteVar returns the value of a var() function:
+//
+//     var( <custom-property-name> , <declaration-value>? )
+func (r *resolver) substituteVar(f item.Function) ([]item.ComponentValue, error) {
+    args := trim(f.Value)
+    if (len(args) == 0) || (args[0].Type() != item.TypePreservedToken) {
+        return nil, ErrSyntax
+    }
+    t := token.Token(args[0].ToPreservedToken())
+    if !t.Is(token.TypeIdent) || !IsCustomProperty(t.StringValue()) {
+        return nil, ErrSyntax
+    }
+    name := t.StringValue()
+
+    rest := trim(args[1:])
+    hasFallback := false
+    if len(rest) > 0 {
+        if !rest[0].IsPreservedToken(token.Comma()) { return nil, ErrSyntax }
+        rest, hasFallback = trim(rest[1:]), true
+    }
+
+    if v, ok := r.lookup(name); ok { return v, nil }
+    if !hasFallback { return nil, ErrInvalid }
+    return r.substitute(rest)
+}
+
+// trim removes leading and trailing whitespace.
+func trim(values []item.ComponentValue) []item.ComponentValue {
+    ws := token.Whitespace()
+    for (len(values) > 0) && values[0].IsPreservedToken(ws) {
+        values = values[1:]
+    }
+    for (len(values) > 0) && values[len(values) - 1].IsPreservedToken(ws) {
+        values = values[:len(values) - 1]
+    }
+    return values
+}
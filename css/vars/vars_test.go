@@ -0,0 +1,191 @@
+package vars_test
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/css/parser"
+    "github.com/tawesoft/golib/v2/css/parser/item"
+    "github.com/tawesoft/golib/v2/css/tokenizer"
+    "github.com/tawesoft/golib/v2/css/vars"
+)
+
+func declarations(s string) []item.Declaration {
+    var result []item.Declaration
+    for _, i := range parser.New(strings.NewReader(s)).ParseStyleBlockContents() {
+        if i.Is(item.TypeDeclaration) {
+            result = append(result, i.ToDeclaration())
+        }
+    }
+    return result
+}
+
+func format(decls []item.Declaration) string {
+    var parts []string
+    for _, d := range decls {
+        s := d.Name + ": " + tokenizer.Serialize(item.Tokens(d.Value))
+        if d.Important { s += " !important" }
+        parts = append(parts, s)
+    }
+    return strings.Join(parts, "; ")
+}
+
+func ExampleResolve() {
+    // custom properties computed for a parent element
+    _, inherited, _ := vars.Resolve(declarations(`
+        --spacing: 4px;
+    `), nil)
+
+    decls, _, errs := vars.Resolve(declarations(`
+        --color: var(--theme, blue);
+        margin: var(--spacing) calc(2 * var(--spacing));
+        color: var(--color);
+    `), inherited)
+
+    fmt.Println(format(decls))
+    fmt.Println(errs)
+
+    // Output:
+    // --color: blue; margin: 4px calc(2 * 4px); color: blue
+    // []
+}
+
+func TestResolve(t *testing.T) {
+    type row struct {
+        input string
+        parent string
+        expected string
+        errs []error
+    }
+    rows := []row{
+        {
+            input: "--a: 1px; width: var(--a)",
+            expected: "--a: 1px; width: 1px",
+        },
+        {
+            input: "width: var(--a); --a: 1px",
+            expected: "width: 1px; --a: 1px",
+        },
+        {
+            input: "--a: 1px; --A: 2px; width: var(--A)",
+            expected: "--a: 1px; --A: 2px; width: 2px",
+        },
+        {
+            input: "--a: 1px; --a: 2px; width: var(--a)",
+            expected: "--a: 2px; width: 2px",
+        },
+        {
+            input: "--a: 1px !important; --a: 2px; width: var(--a)",
+            expected: "--a: 1px !important; width: 1px",
+        },
+        {
+            input: "WIDTH: 1px; width: 2px",
+            expected: "width: 2px",
+        },
+        {
+            input: "--a: var(--b); --b: var(--c); --c: 3px; width: var(--a)",
+            expected: "--a: 3px; --b: 3px; --c: 3px; width: 3px",
+        },
+        {
+            input: "width: var(--missing, 1px)",
+            expected: "width: 1px",
+        },
+        {
+            input: "width: var(--missing, var(--also-missing, 2px))",
+            expected: "width: 2px",
+        },
+        {
+            input: "width: var( --missing , )",
+            expected: "width: ",
+        },
+        {
+            input: "width: var(--missing)",
+            expected: "width: unset",
+            errs: []error{vars.ErrInvalid},
+        },
+        {
+            input: "width: var(missing)",
+            expected: "width: unset",
+            errs: []error{vars.ErrSyntax},
+        },
+        {
+            input: "width: var(--a 1px)",
+            expected: "width: unset",
+            errs: []error{vars.ErrSyntax},
+        },
+        {
+            input: "--a: var(--b); --b: var(--a); --c: var(--a, 1px); width: var(--a, 2px)",
+            expected: "--c: 1px; width: 2px",
+            errs: []error{vars.ErrCycle, vars.ErrCycle},
+        },
+        {
+            input: "--a: var(--a, 1px); width: var(--a)",
+            expected: "width: unset",
+            errs: []error{vars.ErrCycle, vars.ErrInvalid},
+        },
+        {
+            input: "--b: var(--a); --c: x var(--b) y",
+            parent: "--a: 1px",
+            expected: "--b: 1px; --c: x 1px y",
+        },
+        {
+            input: "--a: initial; width: var(--a, 2px)",
+            parent: "--a: 1px",
+            expected: "width: 2px",
+        },
+        {
+            input: "--a: inherit; --b: var(--a)",
+            parent: "--a: 1px",
+            expected: "--a: 1px; --b: 1px",
+        },
+        {
+            input: "--a: 2px; width: var(--a)",
+            parent: "--a: 1px",
+            expected: "--a: 2px; width: 2px",
+        },
+        {
+            input: "width: calc(var(--a) + (var(--a) * 2))",
+            parent: "--a: 1px",
+            expected: "width: calc(1px + (1px * 2))",
+        },
+    }
+
+    for _, r := range rows {
+        var parent vars.Properties
+        if r.parent != "" {
+            _, parent, _ = vars.Resolve(declarations(r.parent), nil)
+        }
+
+        decls, _, errs := vars.Resolve(declarations(r.input), parent)
+        if got := format(decls); got != r.expected {
+            t.Errorf("%q: expected %q but got %q", r.input, r.expected, got)
+        }
+        if len(errs) != len(r.errs) {
+            t.Errorf("%q: expected errors %v but got %v", r.input, r.errs, errs)
+            continue
+        }
+        for i := range errs {
+            if !errors.Is(errs[i], r.errs[i]) {
+                t.Errorf("%q: expected error %v but got %v", r.input, r.errs[i], errs[i])
+            }
+        }
+    }
+}
+
+func TestSubstitute(t *testing.T) {
+    _, props, _ := vars.Resolve(declarations("--a: 1px; --b: 2px"), nil)
+    value := declarations("x: var(--a) var(--b) var(--c, 3px)")[0].Value
+
+    result, err := vars.Substitute(value, props)
+    if err != nil { t.Fatal(err) }
+    if s := tokenizer.Serialize(item.Tokens(result)); s != "1px 2px 3px" {
+        t.Errorf("expected %q but got %q", "1px 2px 3px", s)
+    }
+
+    _, err = vars.Substitute(declarations("x: var(--c)")[0].Value, props)
+    if !errors.Is(err, vars.ErrInvalid) {
+        t.Errorf("expected ErrInvalid but got %v", err)
+    }
+}
@@ -10,6 +10,8 @@ import (
     "slices"
     "strings"
 
+    "github.com/tawesoft/golib/v2/hash/checksum"
+    "github.com/tawesoft/golib/v2/internal/words"
     "github.com/tawesoft/golib/v2/ks"
 )
 
@@ -61,12 +63,75 @@ func (s Store) String() string {
 
 // Clear resets the sequence to zeroes.
 func (s *Store) Clear() {
-    for i := 0; i < cap(s.buckets); i++ {
-        s.buckets[i] = 0
-    }
+    clear(s.buckets[0:cap(s.buckets)])
     s.numTrue = 0
 }
 
+// ProcessBuckets calls f with the underlying storage of the sequence, packed
+// into 64-bit words, where bit i of the sequence is bit (i % 64) of word
+// (i / 64). Every bit past the end of the slice is false. This allows bulk
+// operations on many bits at once.
+//
+// The function f may modify the words in place, but must not retain the
+// slice after it returns.
+func (s *Store) ProcessBuckets(f func(buckets []uint64)) {
+    f(s.buckets)
+    s.numTrue = words.Count(s.buckets)
+}
+
+// grow ensures that the store is backed by at least n buckets.
+func (s *Store) grow(n int) {
+    if n <= len(s.buckets) { return }
+    s.buckets = slices.Grow(s.buckets, n - len(s.buckets))
+    s.buckets = s.buckets[0:cap(s.buckets)]
+}
+
+// Clone returns a copy of the store that does not share memory with the
+// original.
+func (s Store) Clone() Store {
+    return Store{
+        buckets: slices.Clone(s.croppedBuckets()),
+        numTrue: s.numTrue,
+    }
+}
+
+// Equal returns true if both stores contain the same sequence of bits.
+func (s Store) Equal(t Store) bool {
+    return (s.numTrue == t.numTrue) && words.Equal(s.buckets, t.buckets)
+}
+
+// And sets every bit in s to false where the bit at the same index in t is
+// false.
+func (s *Store) And(t Store) {
+    words.And(s.buckets, t.buckets)
+    if len(s.buckets) > len(t.buckets) { clear(s.buckets[len(t.buckets):]) }
+    s.numTrue = words.Count(s.buckets)
+}
+
+// Or sets every bit in s to true where the bit at the same index in t is
+// true.
+func (s *Store) Or(t Store) {
+    t.buckets = t.croppedBuckets()
+    s.grow(len(t.buckets))
+    words.Or(s.buckets, t.buckets)
+    s.numTrue = words.Count(s.buckets)
+}
+
+// Xor inverts every bit in s where the bit at the same index in t is true.
+func (s *Store) Xor(t Store) {
+    t.buckets = t.croppedBuckets()
+    s.grow(len(t.buckets))
+    words.Xor(s.buckets, t.buckets)
+    s.numTrue = words.Count(s.buckets)
+}
+
+// AndNot sets every bit in s to false where the bit at the same index in t
+// is true.
+func (s *Store) AndNot(t Store) {
+    words.AndNot(s.buckets, t.buckets)
+    s.numTrue = words.Count(s.buckets)
+}
+
 // Crop attempts to reclaim any surplus backing memory consumed by trailing
 // zero bits.
 func (s *Store) Crop() {
@@ -114,7 +179,7 @@ func(s Store) Write(w io.Writer) error {
     var crc uint64
 
     write := ks.LiftErrorFunc(func(value uint64) error {
-        crc = ks.Checksum64(crc, value)
+        crc = checksum.Update(crc, value)
         return binary.Write(w, binary.LittleEndian, value)
     })
 
@@ -188,29 +253,18 @@ func (s Store) getFromBucket(bucket, offset int) bool {
 // NextFalse returns the index of the next false bit found after the given
 // index. To start at the beginning, start with NextFalse(-1).
 func (s Store) NextFalse(after int) int {
-    buckets := len(s.buckets)
-    start := after + 1
-    bucket := start / 64
-    var offset int
-
-    for i := bucket; i < buckets; i++ {
-        if s.buckets[i] == ^uint64(0) { continue }
-
-        if i == bucket {
-            // First bucket - start search midway through at given offset
-            offset = start % 64
-        } else {
-            // Beginning of a subsequent bucket - start at beginning
-            offset = 0
-        }
-
-        for j := offset; j < 64; j++ {
-            index := (i * 64) + j
-            if !s.getFromBucket(i, j) { return index }
-        }
+    start := max(after + 1, 0)
+    bucket, offset := fromIndex(start)
+
+    for i := bucket; i < len(s.buckets); i++ {
+        b := ^s.buckets[i]
+        // First bucket - ignore bits before the start
+        if i == bucket { b &= ^uint64(0) << offset }
+        if b == 0 { continue }
+        return (i * 64) + bits.TrailingZeros64(b)
     }
 
-    return start
+    return max(start, len(s.buckets) * 64)
 }
 
 // NextTrue returns the index of the next true bit found after the given
@@ -218,32 +272,7 @@ func (s Store) NextFalse(after int) int {
 // return value is false, then the search has finished, and the remaining
 // sequence is an infinite sequence of false bits.
 func (s Store) NextTrue(after int) (int, bool) {
-    buckets := len(s.buckets)
-    start := after + 1
-    bucket := start / 64
-
-    for i := bucket; i < buckets; i++ {
-        if s.buckets[i] == 0 { continue }
-
-        var offset, limit int
-        if i == bucket {
-            // First bucket - start search midway through. If the entire
-            // remainder is trailing zeros, we can skip early.
-            offset = start % 64
-            limit = 64 - bits.TrailingZeros64(s.buckets[i])
-        } else {
-            // Beginning of a subsequent bucket, skip zero prefix/suffixes.
-            offset = bits.TrailingZeros64(s.buckets[i])
-            limit = 64 - bits.LeadingZeros64(s.buckets[i])
-        }
-
-        for j := offset; j < limit; j++ {
-            index := (i * 64) + j
-            if s.getFromBucket(i, j) { return index, true }
-        }
-    }
-
-    return -1, false
+    return words.Next(s.buckets, after)
 }
 
 // PrevTrue returns the index of the previous true bit found before the given
@@ -251,30 +280,6 @@ func (s Store) NextTrue(after int) (int, bool) {
 // return value is false, then the search has finished, and the remaining
 // sequence prefix is either empty or all false bits.
 func (s Store) PrevTrue(before int) (int, bool) {
-    if before <= 0 { return -1, false }
-    start := before - 1
-    bucket := start / 64
-
-    for i := bucket; i >= 0; i-- {
-        if s.buckets[i] == 0 { continue }
-
-        var offset, limit int
-        if i == bucket {
-            // First bucket - start search midway through. If the entire
-            // prefix is trailing zeros, we can skip early.
-            offset = start % 64
-            limit = bits.LeadingZeros64(s.buckets[i])
-        } else {
-            // Beginning of a subsequent bucket, skip zero prefix/suffixes.
-            offset = 64 - bits.TrailingZeros64(s.buckets[i])
-            limit = bits.LeadingZeros64(s.buckets[i])
-        }
-
-        for j := offset; j >= limit; j-- {
-            index := (i * 64) + j
-            if s.getFromBucket(i, j) { return index, true }
-        }
-    }
-
-    return -1, false
+    if before < 0 { before = len(s.buckets) * 64 }
+    return words.Prev(s.buckets, before)
 }
@@ -152,3 +152,120 @@ func TestStore_NextFalse(t *testing.T) {
     next := s.NextFalse(current)
     expect(t, next == idx, "expected NextFalse(%d) to return %d after loop, but got %d after %d written", current, idx, next, written)
 }
+
+func TestStore_PrevTrue(t *testing.T) {
+    var s bitseq.Store
+    for _, i := range []int{0, 3, 63, 64, 70, 200} {
+        s.Set(i, true)
+    }
+
+    var got []int
+    current := -1
+    for {
+        prev, ok := s.PrevTrue(current)
+        if !ok { break }
+        got = append(got, prev)
+        current = prev
+    }
+    expected := []int{200, 70, 64, 63, 3, 0}
+    expect(t, len(got) == len(expected), "got %v, expected %v", got, expected)
+    for i := 0; (i < len(got)) && (i < len(expected)); i++ {
+        expect(t, got[i] == expected[i], "got %v, expected %v", got, expected)
+    }
+
+    prev, ok := s.PrevTrue(1000)
+    expect(t, ok && (prev == 200), "expected PrevTrue(1000) to return 200, but got %d", prev)
+
+    var empty bitseq.Store
+    _, ok = empty.PrevTrue(-1)
+    expect(t, !ok, "expected PrevTrue(-1) on an empty Store to be false")
+}
+
+func TestStore_NextTrue_boundaries(t *testing.T) {
+    var s bitseq.Store
+    s.Set(63, true)
+    s.Set(127, true)
+
+    next, ok := s.NextTrue(-1)
+    expect(t, ok && (next == 63), "expected NextTrue(-1) to return 63, but got %d", next)
+    next, ok = s.NextTrue(63)
+    expect(t, ok && (next == 127), "expected NextTrue(63) to return 127, but got %d", next)
+    _, ok = s.NextTrue(127)
+    expect(t, !ok, "expected NextTrue(127) to be false")
+}
+
+func TestStore_NextFalse_full(t *testing.T) {
+    var s bitseq.Store
+    for i := 0; i < 128; i++ {
+        s.Set(i, true)
+    }
+    next := s.NextFalse(-1)
+    expect(t, next >= 128, "expected NextFalse(-1) on a full prefix to return at least 128, but got %d", next)
+    expect(t, !s.Get(next), "expected NextFalse(-1) to return a false bit, but got %d", next)
+}
+
+// bitsOf returns a Store with the given bits set.
+func bitsOf(xs ... int) bitseq.Store {
+    var s bitseq.Store
+    for _, x := range xs {
+        s.Set(x, true)
+    }
+    return s
+}
+
+func TestStore_bulk(t *testing.T) {
+    type op func(s *bitseq.Store, t bitseq.Store)
+    rows := []struct{
+        name     string
+        op       op
+        a, b     bitseq.Store
+        expected bitseq.Store
+    }{
+        {"and",        (*bitseq.Store).And,    bitsOf(1, 64, 200), bitsOf(1, 200, 300), bitsOf(1, 200)},
+        {"and short",  (*bitseq.Store).And,    bitsOf(1, 64, 200), bitsOf(1, 64),       bitsOf(1, 64)},
+        {"or",         (*bitseq.Store).Or,     bitsOf(1, 64),      bitsOf(2, 300),      bitsOf(1, 2, 64, 300)},
+        {"xor",        (*bitseq.Store).Xor,    bitsOf(1, 64, 200), bitsOf(1, 300),      bitsOf(64, 200, 300)},
+        {"and not",    (*bitseq.Store).AndNot, bitsOf(1, 64, 200), bitsOf(64, 300),     bitsOf(1, 200)},
+        {"empty",      (*bitseq.Store).Or,     bitsOf(),           bitsOf(),            bitsOf()},
+    }
+
+    for _, row := range rows {
+        a := row.a.Clone()
+        row.op(&a, row.b)
+        expect(t, a.Equal(row.expected), "%s: got %s, expected %s", row.name, a, row.expected)
+        expect(t, a.CountTrue() == row.expected.CountTrue(), "%s: got count %d, expected %d",
+            row.name, a.CountTrue(), row.expected.CountTrue())
+        expect(t, row.a.Equal(row.a.Clone()), "%s: clone modified the original", row.name)
+    }
+
+    s := bitsOf(3, 70)
+    s.ProcessBuckets(func(buckets []uint64) {
+        buckets[0] = 0b1111
+    })
+    expect(t, s.Equal(bitsOf(0, 1, 2, 3, 70)), "ProcessBuckets: got %s", s)
+    expect(t, s.CountTrue() == 5, "ProcessBuckets: got count %d", s.CountTrue())
+}
+
+func BenchmarkStore_Or(b *testing.B) {
+    const n = 1 << 16
+    x, y := bitsOf(), bitsOf()
+    for i := 0; i < n; i += 3 {
+        x.Set(i, true)
+        y.Set(i + 1, true)
+    }
+
+    b.Run("bulk", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            s := x.Clone()
+            s.Or(y)
+        }
+    })
+    b.Run("per-bit", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            s := x.Clone()
+            for j, ok := y.NextTrue(-1); ok; j, ok = y.NextTrue(j) {
+                s.Set(j, true)
+            }
+        }
+    })
+}
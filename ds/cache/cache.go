@@ -0,0 +1,346 @@
+// Package cache implements a generic in-memory cache with a bounded size,
+// which evicts entries by a least-recently-used (LRU) or least-frequently-used
+// (LFU) policy, and optionally expires entries after a time-to-live (TTL).
+//
+// Every operation takes constant time, except for [Cache.Purge], and except
+// that, with the LFU policy, the first eviction after an entry is deleted or
+// expires takes time proportional to the number of distinct use counts.
+package cache
+
+import (
+    "errors"
+    "time"
+)
+
+var ErrOptions = errors.New("invalid cache options")
+
+// Policy decides which entry is evicted when a cache is full.
+type Policy int
+const (
+    // LRU evicts the entry that was least recently read or written.
+    LRU = Policy(iota)
+
+    // LFU evicts the entry that was least frequently read or written. Of
+    // those, it evicts the least recently used.
+    LFU
+)
+
+// Reason is the reason that an entry was removed from a cache.
+type Reason int
+const (
+    Evicted = Reason(iota) // removed to make space, according to the Policy
+    Expired                // removed because its TTL elapsed
+    Deleted                // removed by Cache.Delete
+)
+
+func (r Reason) String() string {
+    switch r {
+        case Evicted: return "evicted"
+        case Expired: return "expired"
+        case Deleted: return "deleted"
+        default:      return "unknown"
+    }
+}
+
+// Options configure a [Cache] returned by [New].
+type Options[K comparable, V any] struct {
+    Policy Policy
+
+    // MaxEntries, if not zero, is the maximum number of entries in the
+    // cache.
+    MaxEntries int
+
+    // MaxSize, if not zero, is the maximum total size of the entries in the
+    // cache, where Size gives the size of each entry. An entry that is, by
+    // itself, larger than MaxSize is evicted as soon as it is added.
+    MaxSize int64
+    Size func(key K, value V) int64
+
+    // TTL, if not zero, is the time after which an entry expires, measured
+    // from when it was last written. An expired entry is never returned, and
+    // is removed when it is next accessed, or by [Cache.Purge].
+    TTL time.Duration
+
+    // OnEvict, if not nil, is called for each entry removed from the cache,
+    // except by [Cache.Clear], after it is removed. It must not modify the
+    // cache.
+    OnEvict func(key K, value V, reason Reason)
+
+    // Now, if not nil, is used in place of [time.Now], for example for
+    // testing.
+    Now func() time.Time
+}
+
+type entry[K comparable, V any] struct {
+    key     K
+    value   V
+    size    int64
+    expires time.Time
+    freq    int // LFU only
+
+    prev, next *entry[K, V]
+}
+
+// list is a circular doubly-linked list of entries, with the most recently
+// used at the front. The zero value must be initialised with init.
+type list[K comparable, V any] struct {
+    root entry[K, V] // sentinel
+    len  int
+}
+
+func (l *list[K, V]) init() *list[K, V] {
+    l.root.next = &l.root
+    l.root.prev = &l.root
+    l.len = 0
+    return l
+}
+
+func (l *list[K, V]) pushFront(e *entry[K, V]) {
+    e.prev = &l.root
+    e.next = l.root.next
+    e.prev.next = e
+    e.next.prev = e
+    l.len++
+}
+
+func (l *list[K, V]) remove(e *entry[K, V]) {
+    e.prev.next = e.next
+    e.next.prev = e.prev
+    e.prev, e.next = nil, nil
+    l.len--
+}
+
+// back returns the least recently used entry, or nil if the list is empty.
+func (l *list[K, V]) back() *entry[K, V] {
+    if l.len == 0 { return nil }
+    return l.root.prev
+}
+
+// Cache is a cache of values by key.
+//
+// A Cache must be created with [New]. It is not suitable for concurrent use
+// without additional synchronization.
+type Cache[K comparable, V any] struct {
+    options Options[K, V]
+    entries map[K]*entry[K, V]
+    size    int64
+
+    // LRU policy
+    recent list[K, V]
+
+    // LFU policy: entries by frequency of use
+    freqs   map[int]*list[K, V]
+    minFreq int
+}
+
+// New returns a new, empty, cache configured by the given options.
+//
+// The return value, if not nil, is [ErrOptions] if MaxEntries, MaxSize, or
+// TTL is negative, or if MaxSize is set but Size is nil, or if the Policy is
+// unknown.
+func New[K comparable, V any](o Options[K, V]) (*Cache[K, V], error) {
+    if (o.MaxEntries < 0) || (o.MaxSize < 0) || (o.TTL < 0) { return nil, ErrOptions }
+    if (o.MaxSize > 0) && (o.Size == nil) { return nil, ErrOptions }
+    if (o.Policy != LRU) && (o.Policy != LFU) { return nil, ErrOptions }
+    if o.Now == nil { o.Now = time.Now }
+
+    c := &Cache[K, V]{options: o}
+    c.Clear()
+    return c, nil
+}
+
+// Len returns the number of entries in the cache, including any that have
+// expired but have not yet been removed.
+func (c *Cache[K, V]) Len() int {
+    return len(c.entries)
+}
+
+// Size returns the total size of the entries in the cache, if the Size
+// option is set, or zero.
+func (c *Cache[K, V]) Size() int64 {
+    return c.size
+}
+
+// Clear removes every entry from the cache, without calling OnEvict.
+func (c *Cache[K, V]) Clear() {
+    c.entries = make(map[K]*entry[K, V])
+    c.size = 0
+    c.recent.init()
+    c.freqs = make(map[int]*list[K, V])
+    c.minFreq = 0
+}
+
+// Get returns the value for a key, or false if the key is not in the cache
+// or has expired. This counts as a use of the entry.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+    e, ok := c.lookup(key)
+    if !ok {
+        var zero V
+        return zero, false
+    }
+    c.touch(e)
+    return e.value, true
+}
+
+// Peek is like [Cache.Get], but does not count as a use of the entry.
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+    e, ok := c.lookup(key)
+    if !ok {
+        var zero V
+        return zero, false
+    }
+    return e.value, true
+}
+
+// Put sets the value for a key, which counts as a use of the entry, and
+// evicts other entries as necessary to keep the cache within its limits.
+func (c *Cache[K, V]) Put(key K, value V) {
+    var size int64
+    if c.options.Size != nil { size = c.options.Size(key, value) }
+
+    e, exists := c.entries[key]
+    if exists {
+        c.size += size - e.size
+        e.value, e.size = value, size
+        c.touch(e)
+    } else {
+        e = &entry[K, V]{key: key, value: value, size: size}
+        c.entries[key] = e
+        c.size += size
+        c.insert(e)
+    }
+    if c.options.TTL > 0 { e.expires = c.options.Now().Add(c.options.TTL) }
+
+    if (c.options.MaxSize > 0) && (size > c.options.MaxSize) {
+        c.remove(e, Evicted)
+        return
+    }
+    if c.full() {
+        // with LFU, a new entry is likely the least frequently used, so
+        // take it out of the running while choosing victims
+        c.unlink(e)
+        for c.full() && (len(c.entries) > 1) {
+            c.remove(c.victim(), Evicted)
+        }
+        c.link(e)
+    }
+}
+
+// Delete removes a key from the cache, and returns true if it existed.
+func (c *Cache[K, V]) Delete(key K) bool {
+    e, ok := c.entries[key]
+    if !ok { return false }
+    c.remove(e, Deleted)
+    return true
+}
+
+// Purge removes every expired entry from the cache, in O(n) time, and
+// returns the number of entries removed.
+func (c *Cache[K, V]) Purge() int {
+    if c.options.TTL <= 0 { return 0 }
+    now := c.options.Now()
+    var expired []*entry[K, V]
+    for _, e := range c.entries {
+        if !now.Before(e.expires) { expired = append(expired, e) }
+    }
+    for _, e := range expired {
+        c.remove(e, Expired)
+    }
+    return len(expired)
+}
+
+// lookup returns an entry that has not expired, removing it if it has.
+func (c *Cache[K, V]) lookup(key K) (*entry[K, V], bool) {
+    e, ok := c.entries[key]
+    if !ok { return nil, false }
+    if (c.options.TTL > 0) && !c.options.Now().Before(e.expires) {
+        c.remove(e, Expired)
+        return nil, false
+    }
+    return e, true
+}
+
+func (c *Cache[K, V]) full() bool {
+    o := c.options
+    if len(c.entries) == 0 { return false }
+    return ((o.MaxEntries > 0) && (len(c.entries) > o.MaxEntries)) ||
+        ((o.MaxSize > 0) && (c.size > o.MaxSize))
+}
+
+// frequency returns the list of LFU entries used n times.
+func (c *Cache[K, V]) frequency(n int) *list[K, V] {
+    l, ok := c.freqs[n]
+    if !ok {
+        l = new(list[K, V]).init()
+        c.freqs[n] = l
+    }
+    return l
+}
+
+// unlink removes an entry from the list for its policy.
+func (c *Cache[K, V]) unlink(e *entry[K, V]) {
+    if c.options.Policy == LRU {
+        c.recent.remove(e)
+        return
+    }
+    l := c.freqs[e.freq]
+    l.remove(e)
+    if l.len == 0 {
+        delete(c.freqs, e.freq)
+        if c.minFreq == e.freq { c.minFreq = 0 } // unknown; see victim
+    }
+}
+
+// link adds an entry to the list for its policy, as the most recently used
+// entry with its frequency.
+func (c *Cache[K, V]) link(e *entry[K, V]) {
+    if c.options.Policy == LRU {
+        c.recent.pushFront(e)
+        return
+    }
+    c.frequency(e.freq).pushFront(e)
+    if (c.minFreq != 0) && (e.freq < c.minFreq) { c.minFreq = e.freq }
+}
+
+func (c *Cache[K, V]) insert(e *entry[K, V]) {
+    if c.options.Policy == LRU {
+        c.recent.pushFront(e)
+        return
+    }
+    e.freq = 1
+    c.frequency(1).pushFront(e)
+    c.minFreq = 1
+}
+
+// touch records a use of an entry.
+func (c *Cache[K, V]) touch(e *entry[K, V]) {
+    if c.options.Policy == LRU {
+        c.recent.remove(e)
+        c.recent.pushFront(e)
+        return
+    }
+    wasMin := c.minFreq == e.freq
+    c.unlink(e)
+    e.freq++
+    c.frequency(e.freq).pushFront(e)
+    if wasMin && (c.minFreq == 0) { c.minFreq = e.freq }
+}
+
+// victim returns the entry to evict next. The cache must not be empty.
+func (c *Cache[K, V]) victim() *entry[K, V] {
+    if c.options.Policy == LRU { return c.recent.back() }
+    if c.minFreq == 0 {
+        // the least frequency is unknown after an arbitrary removal
+        for n := range c.freqs {
+            if (c.minFreq == 0) || (n < c.minFreq) { c.minFreq = n }
+        }
+    }
+    return c.freqs[c.minFreq].back()
+}
+
+func (c *Cache[K, V]) remove(e *entry[K, V], reason Reason) {
+    c.unlink(e)
+    delete(c.entries, e.key)
+    c.size -= e.size
+    if c.options.OnEvict != nil { c.options.OnEvict(e.key, e.value, reason) }
+}
@@ -0,0 +1,189 @@
+package cache_test
+
+import (
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/cache"
+)
+
+func ExampleNew() {
+    c, err := cache.New(cache.Options[string, int]{
+        Policy: cache.LRU,
+        MaxEntries: 2,
+        OnEvict: func(key string, value int, reason cache.Reason) {
+            fmt.Printf("%s %s\n", key, reason)
+        },
+    })
+    if err != nil { panic(err) }
+
+    c.Put("a", 1)
+    c.Put("b", 2)
+    c.Get("a") // "a" is now more recently used than "b"
+    c.Put("c", 3)
+
+    _, ok := c.Get("b")
+    fmt.Println(ok, c.Len())
+
+    // Output:
+    // b evicted
+    // false 2
+}
+
+// record returns an OnEvict function that appends to a slice.
+func record(events *[]string) func(string, int, cache.Reason) {
+    return func(key string, value int, reason cache.Reason) {
+        *events = append(*events, fmt.Sprintf("%s=%d %s", key, value, reason))
+    }
+}
+
+func TestCache_LRU(t *testing.T) {
+    var events []string
+    c, err := cache.New(cache.Options[string, int]{
+        MaxEntries: 3,
+        OnEvict: record(&events),
+    })
+    assert.Nil(t, err)
+
+    c.Put("a", 1)
+    c.Put("b", 2)
+    c.Put("c", 3)
+    c.Get("a")
+    c.Peek("b") // doesn't count as a use
+    c.Put("d", 4) // evicts b
+    c.Put("c", 30) // replaces, uses c
+    c.Put("e", 5) // evicts a
+    assert.True(t, c.Delete("d"))
+    assert.False(t, c.Delete("d"))
+
+    assert.Equal(t, []string{"b=2 evicted", "a=1 evicted", "d=4 deleted"}, events)
+    assert.Equal(t, 2, c.Len())
+    v, ok := c.Get("c")
+    assert.True(t, ok)
+    assert.Equal(t, 30, v)
+
+    c.Clear()
+    assert.Equal(t, 0, c.Len())
+    assert.Equal(t, 3, len(events))
+}
+
+func TestCache_LFU(t *testing.T) {
+    var events []string
+    c, err := cache.New(cache.Options[string, int]{
+        Policy: cache.LFU,
+        MaxEntries: 3,
+        OnEvict: record(&events),
+    })
+    assert.Nil(t, err)
+
+    c.Put("a", 1)
+    c.Put("b", 2)
+    c.Put("c", 3)
+    c.Get("a")
+    c.Get("a")
+    c.Get("b")
+    c.Put("d", 4) // evicts c (used once)
+    c.Put("e", 5) // evicts d (used once, less recently than e)
+    c.Get("e")
+    c.Delete("a") // the least frequency is now unknown
+    c.Put("f", 6)
+    c.Put("g", 7) // evicts f: b and e are used twice
+
+    assert.Equal(t, []string{
+        "c=3 evicted",
+        "d=4 evicted",
+        "a=1 deleted",
+        "f=6 evicted",
+    }, events)
+
+    _, ok := c.Get("b")
+    assert.True(t, ok)
+    _, ok = c.Get("e")
+    assert.True(t, ok)
+    _, ok = c.Get("g")
+    assert.True(t, ok)
+}
+
+func TestCache_LFU_warm(t *testing.T) {
+    // a new entry must not be evicted just because it has only been used once
+    var events []string
+    c, err := cache.New(cache.Options[string, int]{
+        Policy: cache.LFU,
+        MaxEntries: 2,
+        OnEvict: record(&events),
+    })
+    assert.Nil(t, err)
+
+    c.Put("a", 1)
+    c.Get("a")
+    c.Put("b", 2)
+    c.Get("b")
+    c.Put("c", 3) // evicts a (used twice, less recently than b)
+
+    assert.Equal(t, []string{"a=1 evicted"}, events)
+    v, ok := c.Get("c")
+    assert.True(t, ok)
+    assert.Equal(t, 3, v)
+    _, ok = c.Get("b")
+    assert.True(t, ok)
+}
+
+func TestCache_MaxSize(t *testing.T) {
+    var events []string
+    c, err := cache.New(cache.Options[string, int]{
+        MaxSize: 10,
+        Size: func(key string, value int) int64 { return int64(value) },
+        OnEvict: record(&events),
+    })
+    assert.Nil(t, err)
+
+    c.Put("a", 4)
+    c.Put("b", 4)
+    assert.Equal(t, int64(8), c.Size())
+    c.Put("c", 4) // evicts a
+    c.Put("d", 11) // too large by itself
+    c.Put("b", 1)
+    assert.Equal(t, int64(5), c.Size())
+
+    assert.Equal(t, []string{"a=4 evicted", "d=11 evicted"}, events)
+}
+
+func TestCache_TTL(t *testing.T) {
+    now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+    var events []string
+    c, err := cache.New(cache.Options[string, int]{
+        TTL: time.Minute,
+        Now: func() time.Time { return now },
+        OnEvict: record(&events),
+    })
+    assert.Nil(t, err)
+
+    c.Put("a", 1)
+    now = now.Add(30 * time.Second)
+    c.Put("b", 2)
+    c.Put("c", 3)
+    now = now.Add(30 * time.Second)
+
+    _, ok := c.Get("a")
+    assert.False(t, ok)
+    _, ok = c.Get("b")
+    assert.True(t, ok)
+
+    c.Put("c", 30) // refreshes the TTL
+    now = now.Add(30 * time.Second)
+    assert.Equal(t, 1, c.Purge())
+    assert.Equal(t, 1, c.Len())
+
+    assert.Equal(t, []string{"a=1 expired", "b=2 expired"}, events)
+}
+
+func TestNew(t *testing.T) {
+    _, err := cache.New(cache.Options[string, int]{MaxEntries: -1})
+    assert.Equal(t, cache.ErrOptions, err)
+    _, err = cache.New(cache.Options[string, int]{MaxSize: 1})
+    assert.Equal(t, cache.ErrOptions, err)
+    _, err = cache.New(cache.Options[string, int]{Policy: cache.Policy(99)})
+    assert.Equal(t, cache.ErrOptions, err)
+}
@@ -0,0 +1,177 @@
+// Package deque implements a generic double-ended queue, backed by a
+// growable ring buffer.
+//
+// Values can be added to, or removed from, either end of a [Deque] in
+// amortised constant time. This makes a Deque suitable as a first-in
+// first-out queue (with [Deque.PushBack] and [Deque.PopFront]), a stack, or
+// both at once.
+package deque
+
+import (
+    "errors"
+
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+var ErrRange = errors.New("index out of range")
+
+// minCapacity is the initial size of the ring buffer.
+const minCapacity = 8
+
+// Deque is a double-ended queue.
+//
+// The zero-value Deque is a useful value. A Deque is not suitable for
+// concurrent use without additional synchronization.
+type Deque[T any] struct {
+    // buf is a ring buffer with a length that is zero or a power of two,
+    // so that an index wraps around with a bitwise AND.
+    buf   []T
+    head  int // index of the front value in buf
+    count int
+}
+
+// Len returns the number of values in the deque.
+func (d *Deque[T]) Len() int {
+    return d.count
+}
+
+// Cap returns the number of values that the deque can hold before it must
+// grow.
+func (d *Deque[T]) Cap() int {
+    return len(d.buf)
+}
+
+// Clear removes every value from the deque, but keeps the underlying
+// storage for reuse.
+func (d *Deque[T]) Clear() {
+    clear(d.buf)
+    d.head = 0
+    d.count = 0
+}
+
+// Grow increases the deque's capacity, if necessary, to guarantee space for
+// another n values. After Grow(n), at least n values can be added to the
+// deque without another allocation. This is an optional optimisation.
+//
+// If n is negative, Grow panics with [ErrRange].
+func (d *Deque[T]) Grow(n int) {
+    if n < 0 { panic(ErrRange) }
+    need := d.count + n
+    if need <= len(d.buf) { return }
+    capacity := minCapacity
+    for capacity < need { capacity *= 2 }
+    d.resize(capacity)
+}
+
+// resize moves the values into a new ring buffer of the given capacity,
+// starting at index zero.
+func (d *Deque[T]) resize(capacity int) {
+    buf := make([]T, capacity)
+    if d.count > 0 {
+        if d.head + d.count <= len(d.buf) {
+            copy(buf, d.buf[d.head:d.head + d.count])
+        } else {
+            n := copy(buf, d.buf[d.head:])
+            copy(buf[n:], d.buf[:d.count - n])
+        }
+    }
+    d.buf = buf
+    d.head = 0
+}
+
+// index returns the index in buf of the i'th value from the front.
+func (d *Deque[T]) index(i int) int {
+    return (d.head + i) & (len(d.buf) - 1)
+}
+
+// PushBack adds a value to the back of the deque.
+func (d *Deque[T]) PushBack(value T) {
+    if d.count == len(d.buf) { d.Grow(1) }
+    d.buf[d.index(d.count)] = value
+    d.count++
+}
+
+// PushFront adds a value to the front of the deque.
+func (d *Deque[T]) PushFront(value T) {
+    if d.count == len(d.buf) { d.Grow(1) }
+    d.head = d.index(len(d.buf) - 1)
+    d.buf[d.head] = value
+    d.count++
+}
+
+// PopFront removes and returns the value at the front of the deque, or
+// returns false if the deque is empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+    var zero T
+    if d.count == 0 { return zero, false }
+    value := d.buf[d.head]
+    d.buf[d.head] = zero // allow garbage collection
+    d.head = d.index(1)
+    d.count--
+    return value, true
+}
+
+// PopBack removes and returns the value at the back of the deque, or
+// returns false if the deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+    var zero T
+    if d.count == 0 { return zero, false }
+    i := d.index(d.count - 1)
+    value := d.buf[i]
+    d.buf[i] = zero // allow garbage collection
+    d.count--
+    return value, true
+}
+
+// Front returns the value at the front of the deque, without removing it,
+// or returns false if the deque is empty.
+func (d *Deque[T]) Front() (T, bool) {
+    if d.count == 0 {
+        var zero T
+        return zero, false
+    }
+    return d.buf[d.head], true
+}
+
+// Back returns the value at the back of the deque, without removing it,
+// or returns false if the deque is empty.
+func (d *Deque[T]) Back() (T, bool) {
+    if d.count == 0 {
+        var zero T
+        return zero, false
+    }
+    return d.buf[d.index(d.count - 1)], true
+}
+
+// At returns the i'th value from the front of the deque, where the front
+// value has index zero.
+//
+// If i is not in the range [0, Len()), At panics with [ErrRange].
+func (d *Deque[T]) At(i int) T {
+    if (i < 0) || (i >= d.count) { panic(ErrRange) }
+    return d.buf[d.index(i)]
+}
+
+// Set replaces the i'th value from the front of the deque, where the front
+// value has index zero.
+//
+// If i is not in the range [0, Len()), Set panics with [ErrRange].
+func (d *Deque[T]) Set(i int, value T) {
+    if (i < 0) || (i >= d.count) { panic(ErrRange) }
+    d.buf[d.index(i)] = value
+}
+
+// Values returns an iterator over the values in the deque, from front to
+// back. The deque must not be modified until the iterator is exhausted.
+func (d *Deque[T]) Values() iter.It[T] {
+    i := 0
+    return func() (T, bool) {
+        if i >= d.count {
+            var zero T
+            return zero, false
+        }
+        value := d.buf[d.index(i)]
+        i++
+        return value, true
+    }
+}
@@ -0,0 +1,99 @@
+package deque_test
+
+import (
+    "fmt"
+    "math/rand"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/deque"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+func ExampleDeque() {
+    var d deque.Deque[string]
+    d.PushBack("b")
+    d.PushBack("c")
+    d.PushFront("a")
+
+    fmt.Println(iter.ToSlice(d.Values()))
+
+    front, _ := d.PopFront()
+    back, _ := d.PopBack()
+    fmt.Println(front, back, d.Len())
+
+    // Output:
+    // [a b c]
+    // a c 1
+}
+
+func TestDeque(t *testing.T) {
+    var d deque.Deque[int]
+    _, ok := d.PopFront()
+    assert.False(t, ok)
+    _, ok = d.PopBack()
+    assert.False(t, ok)
+    _, ok = d.Front()
+    assert.False(t, ok)
+    _, ok = d.Back()
+    assert.False(t, ok)
+
+    // compare against a slice, with enough operations that the ring buffer
+    // wraps around and grows several times
+    var expected []int
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 10000; i++ {
+        switch r.Intn(5) {
+            case 0: fallthrough
+            case 1:
+                d.PushBack(i)
+                expected = append(expected, i)
+            case 2:
+                d.PushFront(i)
+                expected = append([]int{i}, expected...)
+            case 3:
+                v, ok := d.PopFront()
+                assert.Equal(t, len(expected) > 0, ok)
+                if ok {
+                    assert.Equal(t, expected[0], v)
+                    expected = expected[1:]
+                }
+            case 4:
+                v, ok := d.PopBack()
+                assert.Equal(t, len(expected) > 0, ok)
+                if ok {
+                    assert.Equal(t, expected[len(expected) - 1], v)
+                    expected = expected[:len(expected) - 1]
+                }
+        }
+        if !assert.Equal(t, len(expected), d.Len()) { return }
+    }
+
+    assert.Equal(t, expected, iter.ToSlice(d.Values()))
+    for i := range expected {
+        assert.Equal(t, expected[i], d.At(i))
+    }
+    front, _ := d.Front()
+    back, _ := d.Back()
+    assert.Equal(t, expected[0], front)
+    assert.Equal(t, expected[len(expected) - 1], back)
+
+    d.Set(0, -1)
+    assert.Equal(t, -1, d.At(0))
+    assert.Panics(t, func() { d.At(d.Len()) })
+    assert.Panics(t, func() { d.Set(-1, 0) })
+
+    capacity := d.Cap()
+    d.Clear()
+    assert.Equal(t, 0, d.Len())
+    assert.Equal(t, capacity, d.Cap())
+}
+
+func TestDeque_Grow(t *testing.T) {
+    var d deque.Deque[int]
+    d.PushBack(1)
+    d.PushFront(0)
+    d.Grow(100)
+    assert.GreaterOrEqual(t, d.Cap(), 102)
+    assert.Equal(t, []int{0, 1}, iter.ToSlice(d.Values()))
+}
@@ -8,6 +8,10 @@
 // Each array slot supports a 64-bit generation count. In rare cases this
 // may eventually overflow, raising a panic with [ErrRange] on insertion.
 //
+// A Store must not be modified while iterating over it, and an iterator will
+// panic with [ErrModified] if it detects this. To iterate while modifying the
+// Store, iterate over a [Snapshot] instead.
+//
 // Security model: note that keys are predictable and keys may be leaked
 // through timing side-channel attacks. Do not treat these keys as secret
 // values.
@@ -28,6 +32,7 @@ import (
 
 var ErrNotFound = errors.New("not found")
 var ErrRange    = errors.New("value out of range")
+var ErrModified = errors.New("store modified during iteration")
 var ErrLimit    = errors.New("index exceeds limit")
 var ErrConflict = errors.New("key conflict")
 
@@ -116,6 +121,31 @@ type Store[ValueT any] struct {
     filled      bitseq.Store // fast lookup for finding gaps
     active      int
     gaps        int
+
+    // modified is incremented each time a key is added or removed, to
+    // detect modification during iteration.
+    modified uint64
+
+    // shared is true if the backing arrays are shared with a Snapshot, and
+    // must be copied before they are next written to.
+    shared bool
+}
+
+// cloneExact returns a copy of xs where the capacity equals the length.
+func cloneExact[X any](xs []X) []X {
+    result := make([]X, len(xs))
+    copy(result, xs)
+    return result
+}
+
+// own ensures that the backing arrays are not shared with a Snapshot, by
+// copying them if necessary, before they are written to.
+func (s *Store[ValueT]) own() {
+    if !s.shared { return }
+    s.generations = cloneExact(s.generations)
+    s.values      = cloneExact(s.values)
+    s.filled      = s.filled.Clone()
+    s.shared      = false
 }
 
 // Count returns the number of values currently in the Store.
@@ -131,6 +161,8 @@ func (s *Store[ValueT]) Clear() {
     s.filled      = bitseq.Store{}
     s.gaps        = 0
     s.active      = 0
+    s.shared      = false
+    s.modified++
 }
 
 // ReadKeys (re)initialises a store from a binary serialisation, clearing
@@ -166,6 +198,7 @@ func (s *Store[ValueT]) ReadKeys(r io.Reader, limit int) error {
             s.Grow(cap(s.generations) - index)
         }
 
+        s.own()
         s.generations[index] = generation
         s.filled.Set(index, true)
         s.active++
@@ -200,6 +233,8 @@ func (s *Store[ValueT]) WriteKeys(w io.Writer) error {
 func (s *Store[ValueT]) Grow(n int) {
     if n <= s.gaps { return }
     n = n - s.gaps
+    s.own()
+    s.modified++
 
     capBefore := cap(s.generations)
     s.generations = slices.Grow(s.generations, n)
@@ -219,6 +254,8 @@ func (s *Store[ValueT]) Grow(n int) {
 // overflow, or in the case that the limit set in [Store.Init] is exceeded,
 // panics with ErrRange or ErrLimit.
 func (s *Store[ValueT]) Insert(value ValueT) Key {
+    s.own()
+    s.modified++
     if s.gaps == 0 {
         // append directly to end of a full store
         index := cap(s.generations)
@@ -261,6 +298,8 @@ func (s *Store[ValueT]) Delete(key Key) error {
         return ErrNotFound
     }
 
+    s.own()
+    s.modified++
     s.values[index] = operator.Zero[ValueT]()
     s.filled.Set(index, false)
     s.gaps++
@@ -302,6 +341,7 @@ func (s *Store[ValueT]) Get(key Key) (ValueT, bool) {
 // Otherwise, returns nil.
 func (s *Store[ValueT]) Update(key Key, value ValueT) error {
     if index, ok := lookup(s, key); ok {
+        s.own()
         s.values[index] = value
         return nil
     } else {
@@ -311,11 +351,15 @@ func (s *Store[ValueT]) Update(key Key, value ValueT) error {
 
 // Keys returns an iterator function that generates each stored key. The order
 // of iteration is not defined, except that [Store.Keys], [Store.Values] and
-// [Store.Pairs] produce values in the same order. It is not safe to mutate the
-// Store during this iteration.
+// [Store.Pairs] produce values in the same order.
+//
+// A key must not be inserted or deleted during this iteration, or the
+// iterator panics with [ErrModified]. Values may be updated.
 func (s *Store[ValueT]) Keys() func()(Key, bool) {
     current := -1
+    modified := s.modified
     return func() (Key, bool) {
+        if s.modified != modified { panic(ErrModified) }
         idx, ok := s.filled.NextTrue(current)
         if !ok { return Key{}, false }
         current = idx
@@ -326,11 +370,15 @@ func (s *Store[ValueT]) Keys() func()(Key, bool) {
 
 // Values returns an iterator function that generates each stored value. The
 // order of iteration is not defined, except that [Store.Keys], [Store.Values]
-// and [Store.Pairs] produce values in the same order. It is not safe to mutate
-// the Store during this iteration.
+// and [Store.Pairs] produce values in the same order.
+//
+// A key must not be inserted or deleted during this iteration, or the
+// iterator panics with [ErrModified]. Values may be updated.
 func (s *Store[ValueT]) Values() func()(ValueT, bool) {
     current := -1
+    modified := s.modified
     return func() (ValueT, bool) {
+        if s.modified != modified { panic(ErrModified) }
         idx, ok := s.filled.NextTrue(current)
         if !ok { return operator.Zero[ValueT](), false }
         current = idx
@@ -340,11 +388,15 @@ func (s *Store[ValueT]) Values() func()(ValueT, bool) {
 
 // Pairs returns an iterator function that generates each stored (Key, Value)
 // pair. The order of iteration is not defined, except that [Store.Keys],
-// [Store.Values] and [Store.Pairs] produce values in the same order. It is not
-// safe to mutate the Store during this iteration.
+// [Store.Values] and [Store.Pairs] produce values in the same order.
+//
+// A key must not be inserted or deleted during this iteration, or the
+// iterator panics with [ErrModified]. Values may be updated.
 func (s *Store[ValueT]) Pairs() func()(iter.Pair[Key, ValueT], bool) {
     current := -1
+    modified := s.modified
     return func() (pair iter.Pair[Key, ValueT], ok bool) {
+        if s.modified != modified { panic(ErrModified) }
         idx, ok := s.filled.NextTrue(current)
         if !ok { return iter.Pair[Key, ValueT]{}, false }
         current = idx
@@ -352,3 +404,62 @@ func (s *Store[ValueT]) Pairs() func()(iter.Pair[Key, ValueT], bool) {
         return iter.Pair[Key, ValueT]{Key: key, Value: s.values[idx]}, true
     }
 }
+
+// Snapshot is a read-only view of a [Store] at the time the snapshot was
+// taken. It is unaffected by later changes to the Store, so it is safe to
+// iterate over a Snapshot while modifying the Store.
+//
+// A Snapshot is safe for concurrent use by multiple goroutines, and while
+// the Store is modified by another goroutine, because the Store never writes
+// to memory that it shares with a Snapshot.
+type Snapshot[ValueT any] struct {
+    s Store[ValueT]
+}
+
+// Snapshot returns a read-only view of the Store, in constant time, without
+// copying its contents.
+//
+// The Store and its snapshots share memory until the Store is next
+// modified, at which point the Store copies its contents (once, in O(n)
+// time) before making the modification. Taking frequent snapshots of a
+// frequently modified Store is therefore no cheaper than a full copy.
+//
+// Taking a snapshot is not itself safe for concurrent use with other
+// methods on the Store, but the returned Snapshot may then be passed to
+// another goroutine.
+func (s *Store[ValueT]) Snapshot() *Snapshot[ValueT] {
+    s.shared = true
+    return &Snapshot[ValueT]{s: *s}
+}
+
+// Count returns the number of values in the Snapshot.
+func (s *Snapshot[ValueT]) Count() int {
+    return s.s.Count()
+}
+
+// Contains returns true iff the key was a valid reference to a value when
+// the Snapshot was taken.
+func (s *Snapshot[ValueT]) Contains(key Key) bool {
+    return s.s.Contains(key)
+}
+
+// Get retrieves a copy of a value from the Snapshot, referenced by Key. The
+// second return value is true iff found.
+func (s *Snapshot[ValueT]) Get(key Key) (ValueT, bool) {
+    return s.s.Get(key)
+}
+
+// Keys is like [Store.Keys], but for the Snapshot.
+func (s *Snapshot[ValueT]) Keys() func()(Key, bool) {
+    return s.s.Keys()
+}
+
+// Values is like [Store.Values], but for the Snapshot.
+func (s *Snapshot[ValueT]) Values() func()(ValueT, bool) {
+    return s.s.Values()
+}
+
+// Pairs is like [Store.Pairs], but for the Snapshot.
+func (s *Snapshot[ValueT]) Pairs() func()(iter.Pair[Key, ValueT], bool) {
+    return s.s.Pairs()
+}
@@ -2,6 +2,7 @@ package genarray_test
 
 import (
     "fmt"
+    "testing"
 
     "github.com/tawesoft/golib/v2/ds/genarray"
     "github.com/tawesoft/golib/v2/must"
@@ -53,3 +54,88 @@ func ExampleStore() {
     // everyone
     // 2
 }
+
+func ExampleStore_Snapshot() {
+    var store genarray.Store[string]
+    store.Insert("apple")
+    banana := store.Insert("banana")
+
+    // iterate over a snapshot while modifying the store
+    snapshot := store.Snapshot()
+    values := snapshot.Values()
+    for {
+        value, ok := values()
+        if !ok { break }
+        store.Insert(value + " pie")
+    }
+    must.Equal(store.Delete(banana), nil)
+
+    // the snapshot is unaffected by the changes
+    fmt.Println(snapshot.Count(), snapshot.Contains(banana), store.Count())
+
+    // Output:
+    // 2 true 3
+}
+
+func TestStore_modifiedDuringIteration(t *testing.T) {
+    var store genarray.Store[int]
+    a := store.Insert(1)
+    store.Insert(2)
+
+    panics := func(f func()) (result bool) {
+        defer func() { result = recover() == genarray.ErrModified }()
+        f()
+        return false
+    }
+
+    // updating a value is allowed
+    keys := store.Keys()
+    keys()
+    must.Equal(store.Update(a, 10), nil)
+    if panics(func() { keys() }) { t.Errorf("unexpected panic after Update") }
+
+    // inserting or deleting is not
+    values := store.Values()
+    values()
+    store.Insert(3)
+    if !panics(func() { values() }) { t.Errorf("expected panic after Insert") }
+
+    pairs := store.Pairs()
+    must.Equal(store.Delete(a), nil)
+    if !panics(func() { pairs() }) { t.Errorf("expected panic after Delete") }
+}
+
+func TestStore_Snapshot(t *testing.T) {
+    var store genarray.Store[int]
+    keys := make([]genarray.Key, 0)
+    for i := 0; i < 10; i++ {
+        keys = append(keys, store.Insert(i))
+    }
+
+    snapshot := store.Snapshot()
+    must.Equal(store.Update(keys[0], 100), nil)
+    must.Equal(store.Delete(keys[1]), nil)
+    for i := 0; i < 100; i++ {
+        store.Insert(i) // reuses the gap, and grows
+    }
+
+    if snapshot.Count() != 10 { t.Errorf("expected snapshot count 10, got %d", snapshot.Count()) }
+    for i, key := range keys {
+        value, ok := snapshot.Get(key)
+        if !ok || (value != i) {
+            t.Errorf("snapshot: expected key %d to have value %d, got %d, %t", i, i, value, ok)
+        }
+    }
+    if value, _ := store.Get(keys[0]); value != 100 {
+        t.Errorf("store: expected updated value 100, got %d", value)
+    }
+    if store.Contains(keys[1]) {
+        t.Errorf("store: expected deleted key to be removed")
+    }
+
+    count := 0
+    for pairs := snapshot.Pairs(); ; count++ {
+        if _, ok := pairs(); !ok { break }
+    }
+    if count != 10 { t.Errorf("expected snapshot to iterate over 10 pairs, got %d", count) }
+}
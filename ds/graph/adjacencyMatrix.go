@@ -85,15 +85,7 @@ func (m AdjacencyMatrix) Clear() {
 func (m *AdjacencyMatrix) Resize(width int) {
     if m.mat.Length('x') > width { return }
     width = int(integer.AlignPowTwo(uint(width)))
-
-    var dest matrix.M[int]
-    if m.multi {
-        dest = matrix.NewGrid[int](width, width)
-    } else {
-        dest = matrix.NewBit(width, width)
-    }
-    matrix.Copy(dest, m.mat)
-    m.mat = dest
+    m.mat = matrix.Resize(m.mat, width, width)
 }
 
 // Indegree returns the number of directed edges from any vertex to the
@@ -1,11 +1,20 @@
 package graph
 
 import (
+    "bufio"
+    "encoding/binary"
+    "errors"
+    "io"
     "math"
 
+    "github.com/tawesoft/golib/v2/ds/deque"
+    "github.com/tawesoft/golib/v2/hash/checksum"
     "github.com/tawesoft/golib/v2/ks"
 )
 
+// ErrFormat is returned when reading invalid or corrupt binary data.
+var ErrFormat = errors.New("invalid or corrupt data")
+
 type vertexBFS struct {
     // predecessor is the vertex immediately previous to this one along one
     // possible shortest path. Unreachable and root verticies have this
@@ -31,7 +40,7 @@ type vertexBFS struct {
 type BfsTree struct {
     vertexes []vertexBFS
     start    VertexIndex
-    queue    []VertexIndex
+    queue    deque.Deque[VertexIndex]
 }
 
 // NewBfsTree returns a new (empty) breadth-first search tree object for
@@ -39,7 +48,6 @@ type BfsTree struct {
 func NewBfsTree() *BfsTree {
     return &BfsTree{
         vertexes: make([]vertexBFS, 0),
-        queue:    make([]VertexIndex, 0),
     }
 }
 
@@ -54,8 +62,7 @@ func (t *BfsTree) Clear() {
     maximum := Weight(math.MaxInt)
     t.start = 0
     clear(t.vertexes)
-    clear(t.queue)
-    t.queue = t.queue[0:0]
+    t.queue.Clear()
     for i := 0; i < len(t.vertexes); i++ {
         t.vertexes[i].predecessor = -1
         t.vertexes[i].distance = maximum
@@ -134,12 +141,11 @@ func (t *BfsTree) CalculateUnweighted(graph Iterator, start VertexIndex) {
     t.start = start
     t.vertexes[start].discovered = true
     t.vertexes[start].distance = 0
-    t.queue = append(t.queue, t.start)
+    t.queue.PushBack(t.start)
 
-    for len(t.queue) != 0 {
-        // dequeue
-        source := t.queue[len(t.queue)-1]
-        t.queue = t.queue[:len(t.queue)-1]
+    for {
+        source, ok := t.queue.PopFront()
+        if !ok { break }
         u := t.vertexes[source]
 
         edgesIter := graph.Edges(source)
@@ -153,7 +159,7 @@ func (t *BfsTree) CalculateUnweighted(graph Iterator, start VertexIndex) {
             v.distance    = u.distance + 1
             v.predecessor = source
 
-            t.queue = append(t.queue, target)
+            t.queue.PushBack(target)
         }
     }
 }
@@ -188,7 +194,6 @@ func (t *BfsTree) CalculateWeightedGeneral(
     t.start = start
     t.vertexes[start].discovered = true
     t.vertexes[start].distance = 0
-    t.queue = append(t.queue, t.start)
 
     // repeat limit-1 times
     for i := 0; i < limit - 1; i++ {
@@ -217,3 +222,116 @@ func (t *BfsTree) CalculateWeightedGeneral(
 
 // TODO CalculateWeighted
 // (Dijkstra's algorithm)
+
+// bfsMagic is the magic bytes in the header of a serialised BfsTree.
+const bfsMagic = uint64(
+    (uint64('B') <<  0) +
+    (uint64('f') <<  8) +
+    (uint64('s') << 16) +
+    (uint64('T') << 24) +
+    (uint64('r') << 32) +
+    (uint64('e') << 40) +
+    (uint64('e') << 48) +
+    (uint64('1') << 56))
+
+// bfsChunk is the number of vertexes read at a time by [BfsTree.Read].
+const bfsChunk = 4096
+
+// Write writes an opaque binary representation of the BfsTree into w,
+// including the predecessor and distance of every vertex. This allows the
+// result of an expensive search to be saved, and later restored with
+// [BfsTree.Read].
+func (t BfsTree) Write(w io.Writer) error {
+    var err error
+    var crc uint64
+    var buf [8]byte
+    bw := bufio.NewWriter(w)
+
+    write := ks.LiftErrorFunc(func(value uint64) error {
+        crc = checksum.Update(crc, value)
+        binary.LittleEndian.PutUint64(buf[:], value)
+        _, err := bw.Write(buf[:])
+        return err
+    })
+
+    err = write(err, bfsMagic)
+    err = write(err, uint64(t.start))
+    err = write(err, uint64(len(t.vertexes)))
+    for _, v := range t.vertexes {
+        err = write(err, uint64(v.predecessor))
+        err = write(err, uint64(v.distance))
+    }
+    err = write(err, crc)
+    if err != nil { return err }
+    return bw.Flush()
+}
+
+// readUint64s reads len(dest) values from r, adding them to the checksum
+// crc. Unless the values are at the start of the data, an [io.EOF] is
+// reported as an [io.ErrUnexpectedEOF].
+func readUint64s(r io.Reader, crc *uint64, buf []byte, dest []uint64, start bool) error {
+    buf = buf[0:8 * len(dest)]
+    if _, err := io.ReadFull(r, buf); err != nil {
+        if (err == io.EOF) && !start { err = io.ErrUnexpectedEOF }
+        return err
+    }
+    *crc = checksum.UpdateBytes(*crc, buf)
+    for i := range dest {
+        dest[i] = binary.LittleEndian.Uint64(buf[8*i:])
+    }
+    return nil
+}
+
+// Read reads an opaque binary representation, written by [BfsTree.Write],
+// from r into the BfsTree, replacing its existing contents iff successful.
+// If the data is invalid or corrupt, the error is [ErrFormat].
+//
+// Important: While relatively robust against corrupt data, care should be
+// taken when parsing arbitrary input. A malicious actor could craft an input
+// that would allocate a large amount of memory, or attempt to extract
+// information by continuing to consume from the reader. [io.LimitReader] may
+// be helpful here.
+func (t *BfsTree) Read(r io.Reader) error {
+    var crc uint64
+    buf := make([]byte, 8 * 2 * bfsChunk)
+
+    var header [3]uint64
+    if err := readUint64s(r, &crc, buf, header[:], true); err != nil { return err }
+    magic, start, n := header[0], header[1], header[2]
+    if magic != bfsMagic { return ErrFormat }
+    if (n > math.MaxInt) || ((start >= n) && !((start == 0) && (n == 0))) {
+        return ErrFormat
+    }
+
+    // grow as data is read, so that a bad length can't allocate memory
+    // beyond the size of the input
+    vertexes := make([]vertexBFS, 0, min(n, bfsChunk))
+    values := make([]uint64, 2 * bfsChunk)
+    for remaining := int(n); remaining > 0; {
+        chunk := values[0:2 * min(remaining, bfsChunk)]
+        if err := readUint64s(r, &crc, buf, chunk, false); err != nil { return err }
+        for i := 0; i < len(chunk); i += 2 {
+            predecessor := int64(chunk[i])
+            if (predecessor < -1) || (predecessor >= int64(n)) { return ErrFormat }
+            vertexes = append(vertexes, vertexBFS{
+                predecessor: VertexIndex(predecessor),
+                distance:    Weight(int64(chunk[i+1])),
+            })
+        }
+        remaining -= len(chunk) / 2
+    }
+
+    expected := crc
+    var sum [1]uint64
+    if err := readUint64s(r, &crc, buf, sum[:], false); err != nil { return err }
+    if sum[0] != expected { return ErrFormat }
+
+    for i := range vertexes {
+        v := &vertexes[i]
+        v.discovered = (v.predecessor >= 0) || (i == int(start))
+    }
+    t.vertexes = vertexes
+    t.start = VertexIndex(start)
+    t.queue.Clear()
+    return nil
+}
@@ -1,6 +1,9 @@
 package graph_test
 
 import (
+    "bytes"
+    "errors"
+    "io"
     "testing"
 
     "github.com/tawesoft/golib/v2/ds/graph"
@@ -161,3 +164,132 @@ func TestBfsTree_CalculateUnweighted(t *testing.T) {
 
     // TODO bfst.CalculateWeighted
 }
+
+// listGraph is a graph with a fixed order of edges, where the targets of
+// each vertex are listed in the order they are visited.
+type listGraph [][]graph.VertexIndex
+
+func (g listGraph) Vertexes() graph.VertexIterator {
+    i := 0
+    return func() (graph.VertexIndex, bool) {
+        if i >= len(g) { return 0, false }
+        i++
+        return graph.VertexIndex(i - 1), true
+    }
+}
+
+func (g listGraph) Edges(source graph.VertexIndex) graph.EdgeIterator {
+    i := 0
+    return func() (graph.VertexIndex, int, bool) {
+        if i >= len(g[source]) { return 0, 0, false }
+        i++
+        return g[source][i - 1], 1, true
+    }
+}
+
+func (g listGraph) Weight(source, target graph.VertexIndex) graph.Weight {
+    return 1
+}
+
+func TestBfsTree_CalculateUnweighted_order(t *testing.T) {
+    // 0 -> 1 -> 4
+    // |         ^
+    // \--> 2 -> 3
+    //
+    // Vertex 4 must be discovered via 1 (a distance of 2), not via 3, even
+    // though 2 was discovered last.
+    g := listGraph{
+        {1, 2},
+        {4},
+        {3},
+        {4},
+        {},
+    }
+
+    bfst := graph.NewBfsTree()
+    bfst.CalculateUnweighted(g, 0)
+
+    predecessor, _ := bfst.Predecessor(4)
+    distance, _ := bfst.Distance(4)
+    if (predecessor != 1) || (distance != 2) {
+        t.Errorf("expected predecessor 1 at distance 2, got %d at distance %d",
+            predecessor, distance)
+    }
+}
+
+func TestBfsTree_WriteRead(t *testing.T) {
+    g := listGraph{
+        {1, 2},
+        {4},
+        {3},
+        {4},
+        {},
+        {0}, // unreachable
+    }
+
+    bfst := graph.NewBfsTree()
+    bfst.CalculateUnweighted(g, 0)
+
+    var buf bytes.Buffer
+    if err := bfst.Write(&buf); err != nil {
+        t.Fatalf("unexpected write error: %v", err)
+    }
+    data := bytes.Clone(buf.Bytes())
+
+    result := graph.NewBfsTree()
+    if err := result.Read(bytes.NewReader(data)); err != nil {
+        t.Fatalf("unexpected read error: %v", err)
+    }
+    for v := graph.VertexIndex(-1); v <= 7; v++ {
+        p1, ok1 := bfst.Predecessor(v)
+        p2, ok2 := result.Predecessor(v)
+        d1, r1 := bfst.Distance(v)
+        d2, r2 := result.Distance(v)
+        if (p1 != p2) || (ok1 != ok2) || (d1 != d2) || (r1 != r2) {
+            t.Errorf("vertex %d: expected (%d, %t, %d, %t), got (%d, %t, %d, %t)",
+                v, p1, ok1, d1, r1, p2, ok2, d2, r2)
+        }
+    }
+
+    // an empty tree round-trips too
+    buf.Reset()
+    if err := graph.NewBfsTree().Write(&buf); err != nil {
+        t.Fatalf("unexpected write error: %v", err)
+    }
+    if err := result.Read(&buf); err != nil {
+        t.Errorf("unexpected read error for empty tree: %v", err)
+    }
+    if result.Reachable(0) {
+        t.Errorf("expected empty tree after read")
+    }
+
+    // corrupt inputs are rejected, leaving the tree unchanged
+    corrupt := func(i int) []byte {
+        c := bytes.Clone(data)
+        c[i] ^= 0x10
+        return c
+    }
+    rows := []struct{
+        name  string
+        input []byte
+        err   error
+    }{
+        {"empty",       nil,                    io.EOF},
+        {"truncated",   data[:len(data) - 3],   io.ErrUnexpectedEOF},
+        {"no vertexes", data[:24],              io.ErrUnexpectedEOF},
+        {"magic",       corrupt(0),             graph.ErrFormat},
+        {"start",       corrupt(8),             graph.ErrFormat},
+        {"distance",    corrupt(24 + 8),        graph.ErrFormat},
+        {"checksum",    corrupt(len(data) - 1), graph.ErrFormat},
+    }
+    bfst.CalculateUnweighted(g, 0)
+    for _, row := range rows {
+        err := bfst.Read(bytes.NewReader(row.input))
+        if !errors.Is(err, row.err) {
+            t.Errorf("%s: expected error %v, got %v", row.name, row.err, err)
+        }
+        if d, _ := bfst.Distance(4); d != 2 {
+            t.Errorf("%s: tree was modified by a failed read", row.name)
+        }
+    }
+}
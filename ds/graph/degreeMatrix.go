@@ -48,9 +48,7 @@ func (m *DegreeMatrix) Set(source VertexIndex, count int) {
 func (m *DegreeMatrix) Resize(width int) {
     if m.mat.Length('x') > width { return }
     width = int(integer.AlignPowTwo(uint(width)))
-    dest := matrix.NewDiagonal[int](2, width)
-    matrix.Copy(dest, m.mat)
-    m.mat = dest
+    m.mat = matrix.Resize(m.mat, width, width)
 }
 
 // CountEdges returns the total number of edges in the degree matrix.
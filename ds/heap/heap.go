@@ -0,0 +1,220 @@
+// Package heap implements a generic binary heap, which is useful as a
+// priority queue.
+//
+// Unlike the standard library's [container/heap], a [Heap] is a concrete
+// type, ordered by a function, and each value pushed onto the heap returns a
+// [Handle] that can later be used to change its priority (for example, the
+// "decrease key" operation used by Dijkstra's shortest path algorithm) or to
+// remove it.
+package heap
+
+import (
+    "errors"
+
+    "github.com/tawesoft/golib/v2/operator"
+    "golang.org/x/exp/constraints"
+)
+
+var ErrNotFound = errors.New("not found")
+var ErrIncrease = errors.New("new key is greater than current key")
+
+type entry[T any] struct {
+    value T
+    index int // position in Heap.entries, or -1 once removed
+    heap any  // the *Heap[T] that the entry belongs to
+}
+
+// Handle refers to a value in a Heap, and remains valid as the heap changes,
+// until the value is removed from the heap, for example by [Heap.Pop].
+//
+// The zero-value Handle is valid, but never refers to any value.
+type Handle[T any] struct {
+    e *entry[T]
+}
+
+// Heap is a binary min-heap: the least value, according to a less function,
+// is always at the top of the heap. For a max-heap, reverse the order of the
+// less function.
+//
+// A Heap must be created with [New] or [NewOrdered]. It is not suitable for
+// concurrent use without additional synchronization.
+type Heap[T any] struct {
+    less func(a, b T) bool
+    entries []*entry[T]
+}
+
+// New returns a new, empty, Heap ordered by the less function, which must
+// return true if a has a higher priority than (i.e. should be popped before)
+// b.
+func New[T any](less func(a, b T) bool) *Heap[T] {
+    return &Heap[T]{less: less}
+}
+
+// NewOrdered returns a new, empty, Heap ordered by the natural ordering of
+// T, so that the smallest value is popped first.
+func NewOrdered[T constraints.Ordered]() *Heap[T] {
+    return New[T](operator.LT[T])
+}
+
+// Len returns the number of values in the heap.
+func (h *Heap[T]) Len() int {
+    return len(h.entries)
+}
+
+// Clear removes every value from the heap. Existing handles no longer refer
+// to any value.
+func (h *Heap[T]) Clear() {
+    for _, e := range h.entries {
+        e.index = -1
+    }
+    h.entries = nil
+}
+
+// Push adds a value to the heap, and returns a handle to it, in O(log n)
+// time.
+func (h *Heap[T]) Push(value T) Handle[T] {
+    e := &entry[T]{value: value, index: len(h.entries), heap: h}
+    h.entries = append(h.entries, e)
+    h.up(e.index)
+    return Handle[T]{e}
+}
+
+// Peek returns the value at the top of the heap, without removing it, or
+// false if the heap is empty.
+func (h *Heap[T]) Peek() (T, bool) {
+    if len(h.entries) == 0 {
+        var zero T
+        return zero, false
+    }
+    return h.entries[0].value, true
+}
+
+// Pop removes and returns the value at the top of the heap, in O(log n)
+// time, or returns false if the heap is empty.
+func (h *Heap[T]) Pop() (T, bool) {
+    if len(h.entries) == 0 {
+        var zero T
+        return zero, false
+    }
+    return h.remove(0), true
+}
+
+// Contains returns true if the handle refers to a value in the heap.
+func (h *Heap[T]) Contains(x Handle[T]) bool {
+    _, ok := h.lookup(x)
+    return ok
+}
+
+// Get returns the value referred to by a handle, or false if the handle does
+// not refer to a value in the heap.
+func (h *Heap[T]) Get(x Handle[T]) (T, bool) {
+    i, ok := h.lookup(x)
+    if !ok {
+        var zero T
+        return zero, false
+    }
+    return h.entries[i].value, true
+}
+
+// DecreaseKey replaces the value referred to by a handle with a new value
+// that has the same or a higher priority (that is, the new value is not
+// greater than the old value, according to the heap's less function), in
+// O(log n) time.
+//
+// The return value, if not nil, may be [ErrNotFound] if the handle does not
+// refer to a value in the heap, or [ErrIncrease] if the new value has a
+// lower priority, in which case the heap is not modified. To change a value
+// in either direction, use [Heap.Update].
+func (h *Heap[T]) DecreaseKey(x Handle[T], value T) error {
+    i, ok := h.lookup(x)
+    if !ok { return ErrNotFound }
+    if h.less(h.entries[i].value, value) { return ErrIncrease }
+    h.entries[i].value = value
+    h.up(i)
+    return nil
+}
+
+// Update replaces the value referred to by a handle with a new value of any
+// priority, in O(log n) time.
+//
+// The return value, if not nil, is [ErrNotFound] if the handle does not refer
+// to a value in the heap.
+func (h *Heap[T]) Update(x Handle[T], value T) error {
+    i, ok := h.lookup(x)
+    if !ok { return ErrNotFound }
+    h.entries[i].value = value
+    if !h.down(i) { h.up(i) }
+    return nil
+}
+
+// Remove removes and returns the value referred to by a handle, in O(log n)
+// time.
+//
+// The return value, if not nil, is [ErrNotFound] if the handle does not refer
+// to a value in the heap.
+func (h *Heap[T]) Remove(x Handle[T]) (T, error) {
+    i, ok := h.lookup(x)
+    if !ok {
+        var zero T
+        return zero, ErrNotFound
+    }
+    return h.remove(i), nil
+}
+
+func (h *Heap[T]) lookup(x Handle[T]) (int, bool) {
+    if (x.e == nil) || (x.e.index < 0) || (x.e.heap != any(h)) { return -1, false }
+    return x.e.index, true
+}
+
+// remove removes the entry at index i, restoring the heap property.
+func (h *Heap[T]) remove(i int) T {
+    last := len(h.entries) - 1
+    e := h.entries[i]
+    if i != last {
+        h.swap(i, last)
+    }
+    h.entries[last] = nil
+    h.entries = h.entries[:last]
+    if i != last {
+        if !h.down(i) { h.up(i) }
+    }
+    e.index = -1
+    return e.value
+}
+
+func (h *Heap[T]) swap(i, j int) {
+    h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+    h.entries[i].index = i
+    h.entries[j].index = j
+}
+
+// up moves the entry at index i towards the top of the heap until it is not
+// less than its parent.
+func (h *Heap[T]) up(i int) {
+    for i > 0 {
+        parent := (i - 1) / 2
+        if !h.less(h.entries[i].value, h.entries[parent].value) { break }
+        h.swap(i, parent)
+        i = parent
+    }
+}
+
+// down moves the entry at index i towards the bottom of the heap until
+// neither child is less than it, and returns true if it moved.
+func (h *Heap[T]) down(i int) bool {
+    start, n := i, len(h.entries)
+    for {
+        least := i
+        left, right := (2 * i) + 1, (2 * i) + 2
+        if (left < n) && h.less(h.entries[left].value, h.entries[least].value) {
+            least = left
+        }
+        if (right < n) && h.less(h.entries[right].value, h.entries[least].value) {
+            least = right
+        }
+        if least == i { break }
+        h.swap(i, least)
+        i = least
+    }
+    return i > start
+}
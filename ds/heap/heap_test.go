@@ -0,0 +1,117 @@
+package heap_test
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/heap"
+)
+
+func ExampleHeap_DecreaseKey() {
+    type task struct {
+        name string
+        priority int
+    }
+    h := heap.New(func(a, b task) bool { return a.priority < b.priority })
+
+    h.Push(task{"write report", 3})
+    h.Push(task{"make coffee", 2})
+    fire := h.Push(task{"put out fire", 5})
+
+    // the fire is now more urgent than anything else
+    err := h.DecreaseKey(fire, task{"put out fire", 1})
+    if err != nil { panic(err) }
+
+    for h.Len() > 0 {
+        t, _ := h.Pop()
+        fmt.Println(t.name)
+    }
+
+    // Output:
+    // put out fire
+    // make coffee
+    // write report
+}
+
+func TestHeap(t *testing.T) {
+    h := heap.NewOrdered[int]()
+    _, ok := h.Peek()
+    assert.False(t, ok)
+    _, ok = h.Pop()
+    assert.False(t, ok)
+
+    r := rand.New(rand.NewSource(0))
+    var values []int
+    handles := make(map[int]heap.Handle[int])
+    for i := 0; i < 1000; i++ {
+        v := r.Intn(1000000)
+        if _, exists := handles[v]; exists { continue }
+        handles[v] = h.Push(v)
+        values = append(values, v)
+    }
+
+    // remove some values, decrease others, and increase others
+    for i, v := range values {
+        x := handles[v]
+        switch i % 4 {
+            case 0:
+                got, err := h.Remove(x)
+                assert.Nil(t, err)
+                assert.Equal(t, v, got)
+                assert.False(t, h.Contains(x))
+                _, err = h.Remove(x)
+                assert.Equal(t, heap.ErrNotFound, err)
+                values[i] = -1
+            case 1:
+                assert.Nil(t, h.DecreaseKey(x, v - 1000000))
+                values[i] = v - 1000000
+            case 2:
+                assert.Equal(t, heap.ErrIncrease, h.DecreaseKey(x, v + 1))
+                assert.Nil(t, h.Update(x, v + 2000000))
+                values[i] = v + 2000000
+        }
+    }
+
+    var expected []int
+    for _, v := range values {
+        if v != -1 { expected = append(expected, v) }
+    }
+    sort.Ints(expected)
+    assert.Equal(t, len(expected), h.Len())
+
+    top, ok := h.Peek()
+    assert.True(t, ok)
+    assert.Equal(t, expected[0], top)
+
+    var got []int
+    for h.Len() > 0 {
+        v, _ := h.Pop()
+        got = append(got, v)
+    }
+    assert.Equal(t, expected, got)
+
+    for _, x := range handles {
+        assert.False(t, h.Contains(x))
+    }
+}
+
+func TestHeap_Handles(t *testing.T) {
+    a := heap.NewOrdered[string]()
+    b := heap.NewOrdered[string]()
+    x := a.Push("x")
+
+    assert.False(t, b.Contains(x))
+    assert.Equal(t, heap.ErrNotFound, b.Update(x, "y"))
+    assert.False(t, a.Contains(heap.Handle[string]{}))
+
+    v, ok := a.Get(x)
+    assert.True(t, ok)
+    assert.Equal(t, "x", v)
+
+    a.Clear()
+    assert.Equal(t, 0, a.Len())
+    assert.False(t, a.Contains(x))
+}
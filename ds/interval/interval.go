@@ -0,0 +1,264 @@
+// Package interval implements an interval tree, which stores values by
+// interval, and efficiently finds every interval that contains a point (a
+// "stabbing" query), or that overlaps another interval.
+//
+// The tree is a self-balancing (AVL) binary search tree, ordered by the
+// start of each interval, where each node is augmented with the greatest end
+// of any interval in its subtree. Insertion and deletion take O(log n) time,
+// and a query takes O(log n + m) time, where m is the number of results.
+package interval
+
+import (
+    "errors"
+
+    "github.com/tawesoft/golib/v2/iter"
+    "golang.org/x/exp/constraints"
+)
+
+var ErrRange = errors.New("interval start is greater than end")
+
+// Interval is a closed interval, containing every point x where
+// Start <= x <= End.
+type Interval[T constraints.Ordered] struct {
+    Start, End T
+}
+
+// Valid returns true if the interval is not empty i.e. Start <= End.
+func (i Interval[T]) Valid() bool {
+    return i.Start <= i.End
+}
+
+// Contains returns true if the point x is in the interval.
+func (i Interval[T]) Contains(x T) bool {
+    return (i.Start <= x) && (x <= i.End)
+}
+
+// Overlaps returns true if the two intervals have at least one point in
+// common.
+func (i Interval[T]) Overlaps(j Interval[T]) bool {
+    return (i.Start <= j.End) && (j.Start <= i.End)
+}
+
+// less orders intervals by start, then by end.
+func (i Interval[T]) less(j Interval[T]) bool {
+    if i.Start != j.Start { return i.Start < j.Start }
+    return i.End < j.End
+}
+
+// Entry is an interval, value pair stored in a [Tree].
+type Entry[T constraints.Ordered, V any] struct {
+    Interval Interval[T]
+    Value    V
+}
+
+type node[T constraints.Ordered, V any] struct {
+    entry       Entry[T, V]
+    max         T // greatest End in this subtree
+    height      int
+    left, right *node[T, V]
+}
+
+func height[T constraints.Ordered, V any](n *node[T, V]) int {
+    if n == nil { return 0 }
+    return n.height
+}
+
+// update recalculates the height and max of a node from its children.
+func (n *node[T, V]) update() {
+    n.height = 1 + max(height(n.left), height(n.right))
+    n.max = n.entry.Interval.End
+    if (n.left  != nil) && (n.left.max  > n.max) { n.max = n.left.max }
+    if (n.right != nil) && (n.right.max > n.max) { n.max = n.right.max }
+}
+
+func (n *node[T, V]) rotateLeft() *node[T, V] {
+    r := n.right
+    n.right, r.left = r.left, n
+    n.update()
+    r.update()
+    return r
+}
+
+func (n *node[T, V]) rotateRight() *node[T, V] {
+    l := n.left
+    n.left, l.right = l.right, n
+    n.update()
+    l.update()
+    return l
+}
+
+// balance updates a node and restores the AVL property, returning the new
+// root of the subtree.
+func (n *node[T, V]) balance() *node[T, V] {
+    n.update()
+    switch b := height(n.left) - height(n.right); {
+        case b > 1:
+            if height(n.left.left) < height(n.left.right) {
+                n.left = n.left.rotateLeft()
+            }
+            return n.rotateRight()
+        case b < -1:
+            if height(n.right.right) < height(n.right.left) {
+                n.right = n.right.rotateRight()
+            }
+            return n.rotateLeft()
+    }
+    return n
+}
+
+// Tree is an interval tree. Each distinct interval appears in the tree at
+// most once, with one value.
+//
+// The zero-value Tree is a useful value. A Tree is not suitable for
+// concurrent use without additional synchronization.
+type Tree[T constraints.Ordered, V any] struct {
+    root  *node[T, V]
+    count int
+}
+
+// Len returns the number of intervals in the tree.
+func (t *Tree[T, V]) Len() int {
+    return t.count
+}
+
+// Clear removes every interval from the tree.
+func (t *Tree[T, V]) Clear() {
+    t.root = nil
+    t.count = 0
+}
+
+// Put sets the value for an interval, and returns true if it replaced an
+// existing value.
+//
+// If the interval is not valid (see [Interval.Valid]), Put panics with
+// [ErrRange].
+func (t *Tree[T, V]) Put(i Interval[T], value V) bool {
+    if !i.Valid() { panic(ErrRange) }
+    var replaced bool
+    t.root = t.put(t.root, Entry[T, V]{i, value}, &replaced)
+    if !replaced { t.count++ }
+    return replaced
+}
+
+func (t *Tree[T, V]) put(n *node[T, V], e Entry[T, V], replaced *bool) *node[T, V] {
+    switch {
+        case n == nil:
+            n = &node[T, V]{entry: e}
+        case e.Interval.less(n.entry.Interval):
+            n.left = t.put(n.left, e, replaced)
+        case n.entry.Interval.less(e.Interval):
+            n.right = t.put(n.right, e, replaced)
+        default:
+            n.entry.Value = e.Value
+            *replaced = true
+            return n
+    }
+    return n.balance()
+}
+
+// Get returns the value for an interval, or false if the interval is not in
+// the tree.
+func (t *Tree[T, V]) Get(i Interval[T]) (V, bool) {
+    n := t.root
+    for n != nil {
+        switch {
+            case i.less(n.entry.Interval):
+                n = n.left
+            case n.entry.Interval.less(i):
+                n = n.right
+            default:
+                return n.entry.Value, true
+        }
+    }
+    var zero V
+    return zero, false
+}
+
+// Delete removes an interval from the tree, and returns true if it existed.
+func (t *Tree[T, V]) Delete(i Interval[T]) bool {
+    var deleted bool
+    t.root = t.delete(t.root, i, &deleted)
+    if deleted { t.count-- }
+    return deleted
+}
+
+func (t *Tree[T, V]) delete(n *node[T, V], i Interval[T], deleted *bool) *node[T, V] {
+    switch {
+        case n == nil:
+            return nil
+        case i.less(n.entry.Interval):
+            n.left = t.delete(n.left, i, deleted)
+        case n.entry.Interval.less(i):
+            n.right = t.delete(n.right, i, deleted)
+        default:
+            *deleted = true
+            if n.left  == nil { return n.right }
+            if n.right == nil { return n.left }
+
+            // replace with the least entry in the right subtree
+            m := n.right
+            for m.left != nil { m = m.left }
+            n.entry = m.entry
+            n.right = t.delete(n.right, m.entry.Interval, new(bool))
+    }
+    return n.balance()
+}
+
+// All returns an iterator over every interval, value pair in the tree, in
+// order of interval start, then end. The tree must not be modified until the
+// iterator is exhausted.
+func (t *Tree[T, V]) All() iter.It[Entry[T, V]] {
+    return t.search(func(*node[T, V]) (bool, bool, bool) {
+        return true, true, true
+    })
+}
+
+// Stab returns an iterator over every interval, value pair in the tree
+// where the interval contains the point x, in order of interval start, then
+// end. The tree must not be modified until the iterator is exhausted.
+func (t *Tree[T, V]) Stab(x T) iter.It[Entry[T, V]] {
+    return t.Overlapping(Interval[T]{x, x})
+}
+
+// Overlapping returns an iterator over every interval, value pair in the
+// tree where the interval overlaps q, in order of interval start, then end.
+// The tree must not be modified until the iterator is exhausted.
+func (t *Tree[T, V]) Overlapping(q Interval[T]) iter.It[Entry[T, V]] {
+    return t.search(func(n *node[T, V]) (left, self, right bool) {
+        // nothing in the subtree ends at or after the query starts
+        if n.max < q.Start { return false, false, false }
+        // everything to the right starts after the query ends
+        if q.End < n.entry.Interval.Start { return true, false, false }
+        return true, n.entry.Interval.Overlaps(q), true
+    })
+}
+
+// search returns an in-order iterator over the tree, where f decides for
+// each node whether to visit its left subtree, itself, and its right
+// subtree.
+func (t *Tree[T, V]) search(f func(n *node[T, V]) (left, self, right bool)) iter.It[Entry[T, V]] {
+    type item struct {
+        n *node[T, V]
+        visit bool // true if n itself is to be produced
+    }
+    var stack []item
+    push := func(n *node[T, V]) {
+        if n == nil { return }
+        left, self, right := f(n)
+        // pushed in reverse order: right subtree, self, left subtree
+        if right && (n.right != nil) { stack = append(stack, item{n.right, false}) }
+        if self { stack = append(stack, item{n, true}) }
+        if left && (n.left != nil) { stack = append(stack, item{n.left, false}) }
+    }
+    push(t.root)
+
+    return func() (Entry[T, V], bool) {
+        for len(stack) > 0 {
+            x := stack[len(stack) - 1]
+            stack = stack[:len(stack) - 1]
+            if x.visit { return x.n.entry, true }
+            push(x.n)
+        }
+        return Entry[T, V]{}, false
+    }
+}
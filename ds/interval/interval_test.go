@@ -0,0 +1,101 @@
+package interval_test
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/interval"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+func ExampleTree_Stab() {
+    var bookings interval.Tree[int, string]
+    bookings.Put(interval.Interval[int]{Start:  9, End: 11}, "Alice")
+    bookings.Put(interval.Interval[int]{Start: 10, End: 12}, "Bob")
+    bookings.Put(interval.Interval[int]{Start: 13, End: 14}, "Carol")
+
+    // who is booked at 10 o'clock?
+    iter.Walk(func(e interval.Entry[int, string]) {
+        fmt.Println(e.Value)
+    }, bookings.Stab(10))
+
+    // Output:
+    // Alice
+    // Bob
+}
+
+type entries []interval.Entry[int, int]
+
+func (xs entries) Len() int { return len(xs) }
+func (xs entries) Swap(i, j int) { xs[i], xs[j] = xs[j], xs[i] }
+func (xs entries) Less(i, j int) bool {
+    a, b := xs[i].Interval, xs[j].Interval
+    if a.Start != b.Start { return a.Start < b.Start }
+    return a.End < b.End
+}
+
+func TestTree(t *testing.T) {
+    var tree interval.Tree[int, int]
+    expected := make(map[interval.Interval[int]]int)
+
+    r := rand.New(rand.NewSource(0))
+    random := func() interval.Interval[int] {
+        start := r.Intn(100)
+        return interval.Interval[int]{start, start + r.Intn(20)}
+    }
+
+    // brute force query of the expected contents
+    query := func(f func(i interval.Interval[int]) bool) entries {
+        result := entries{}
+        for k, v := range expected {
+            if f(k) { result = append(result, interval.Entry[int, int]{k, v}) }
+        }
+        sort.Sort(result)
+        return result
+    }
+
+    check := func() {
+        assert.Equal(t, len(expected), tree.Len())
+        assert.Equal(t, query(func(interval.Interval[int]) bool { return true }),
+            entries(iter.ToSlice(tree.All())))
+        for x := -1; x < 125; x += 3 {
+            assert.Equal(t, query(func(i interval.Interval[int]) bool { return i.Contains(x) }),
+                entries(iter.ToSlice(tree.Stab(x))), "stab %d", x)
+        }
+        for j := 0; j < 20; j++ {
+            q := random()
+            assert.Equal(t, query(func(i interval.Interval[int]) bool { return i.Overlaps(q) }),
+                entries(iter.ToSlice(tree.Overlapping(q))), "overlapping %v", q)
+        }
+    }
+
+    for i := 0; i < 3000; i++ {
+        k := random()
+        _, exists := expected[k]
+        if r.Intn(3) == 0 {
+            assert.Equal(t, exists, tree.Delete(k))
+            delete(expected, k)
+        } else {
+            assert.Equal(t, exists, tree.Put(k, i))
+            expected[k] = i
+        }
+        if i % 500 == 0 { check() }
+    }
+    check()
+
+    for k, v := range expected {
+        got, ok := tree.Get(k)
+        assert.True(t, ok)
+        assert.Equal(t, v, got)
+        assert.True(t, tree.Delete(k))
+    }
+    assert.Equal(t, 0, tree.Len())
+    assert.Empty(t, iter.ToSlice(tree.All()))
+
+    assert.PanicsWithValue(t, interval.ErrRange, func() {
+        tree.Put(interval.Interval[int]{2, 1}, 0)
+    })
+}
@@ -0,0 +1,252 @@
+package matrix
+
+import (
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+    "github.com/tawesoft/golib/v2/operator"
+)
+
+// isSparse returns true if m is an implementation that stores only its
+// non-zero elements, so that iterating with Next is cheaper than visiting
+// every index.
+func isSparse[T comparable](m M[T]) bool {
+    switch m.(type) {
+        case Hashmap[T], Diagonal[T], CSR[T], CSC[T], BlockSparse[T]: return true
+        default: return false
+    }
+}
+
+// nonZero calls f for each non-zero element of m.
+func nonZero[T comparable](m M[T], f func(idx int, value T)) {
+    for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {
+        f(idx, m.Get(idx))
+    }
+}
+
+// setAll clears dest, then sets each element in values.
+func setAll[T comparable](dest M[T], values map[int]T) {
+    dest.Clear()
+    for idx, value := range values {
+        dest.Set(idx, value)
+    }
+}
+
+// broadcast returns views of a and b with the same shape, according to
+// [dimensions.Broadcast], or panics with ErrShape. A matrix that already has
+// that shape is returned unchanged.
+func broadcast[T comparable](a, b M[T]) (M[T], M[T]) {
+    if sameShape(a, b) { return a, b }
+    d, ma, mb, err := dimensions.Broadcast(a, b)
+    if err != nil { panic(ErrShape) }
+    if !sameShape(a, d) { a = NewView(a, ma) }
+    if !sameShape(b, d) { b = NewView(b, mb) }
+    return a, b
+}
+
+// elementwise sets dest[i] = f(a[i], b[i]) for every index i, where
+// f(0, 0) == 0, after broadcasting a and b to the same shape.
+func elementwise[T operator.Number](dest, a, b M[T], f func(x, y T) T) {
+    a, b = broadcast(a, b)
+    if !sameShape(dest, a) { panic(ErrShape) }
+
+    ga, aGrid := a.(Grid[T])
+    gb, bGrid := b.(Grid[T])
+    gd, dGrid := dest.(Grid[T])
+    switch {
+        case aGrid && bGrid && dGrid:
+            x, y, d := ga.values[:ga.Size()], gb.values[:ga.Size()], gd.values[:ga.Size()]
+            for i := range d {
+                d[i] = f(x[i], y[i])
+            }
+        case isSparse(a) && isSparse(b):
+            // computed before modifying dest, which may be a or b
+            var zero T
+            result := make(map[int]T)
+            nonZero(a, func(idx int, value T) { result[idx] = f(value, b.Get(idx)) })
+            nonZero(b, func(idx int, value T) {
+                if _, ok := result[idx]; !ok { result[idx] = f(zero, value) }
+            })
+            setAll(dest, result)
+        default:
+            for i := 0; i < a.Size(); i++ {
+                dest.Set(i, f(a.Get(i), b.Get(i)))
+            }
+    }
+}
+
+// Add sets each element of dest to the sum of the elements at the same
+// index in a and b. The matrices must have the same shape, or Add panics
+// with [ErrShape]. The destination may be the same matrix as a or b.
+//
+// As a special case, a and b may have different shapes if they can be
+// broadcast together, as described by [dimensions.Broadcast], and dest has
+// the broadcast shape. For example, adding a matrix with a width of 1 adds
+// the same column to every column of the other matrix.
+func Add[T operator.Number](dest, a, b M[T]) {
+    elementwise(dest, a, b, operator.Add[T])
+}
+
+// Sub sets each element of dest to the element at the same index in a minus
+// the element at the same index in b. The matrices must have the same
+// shape, or Sub panics with [ErrShape]. The destination may be the same
+// matrix as a or b.
+//
+// As with [Add], a and b may have different shapes if they can be broadcast
+// together.
+func Sub[T operator.Number](dest, a, b M[T]) {
+    elementwise(dest, a, b, operator.Sub[T])
+}
+
+// Scale sets each element of dest to the element at the same index in a,
+// multiplied by k. The matrices must have the same shape, or Scale panics
+// with [ErrShape]. The destination may be the same matrix as a.
+func Scale[T operator.Number](dest, a M[T], k T) {
+    if !sameShape(dest, a) { panic(ErrShape) }
+
+    ga, aGrid := a.(Grid[T])
+    gd, dGrid := dest.(Grid[T])
+    switch {
+        case aGrid && dGrid:
+            x, d := ga.values[:ga.Size()], gd.values[:ga.Size()]
+            for i := range d {
+                d[i] = k * x[i]
+            }
+        case isSparse(a):
+            result := make(map[int]T)
+            nonZero(a, func(idx int, value T) { result[idx] = k * value })
+            setAll(dest, result)
+        default:
+            for i := 0; i < a.Size(); i++ {
+                dest.Set(i, k * a.Get(i))
+            }
+    }
+}
+
+// MatMul sets dest to the matrix product of a and b, where each is a
+// 2-dimensional matrix with a width (number of columns) and height (number
+// of rows).
+//
+// The width of a must equal the height of b, and dest must have the height
+// of a and the width of b, or MatMul panics with [ErrShape]. The destination
+// must not be the same matrix as a or b.
+//
+// If any of the matrices is sparse (for example a [Hashmap], [Diagonal], or
+// [CSR]), only the non-zero elements of a and b are visited.
+func MatMul[T operator.Number](dest, a, b M[T]) {
+    if (a.Dimensionality() != 2) || (b.Dimensionality() != 2) || (dest.Dimensionality() != 2) {
+        panic(ErrShape)
+    }
+    n, m, p := a.Length(1), a.Length(0), b.Length(0) // (n×m)(m×p) = (n×p)
+    if (b.Length(1) != m) || (dest.Length(1) != n) || (dest.Length(0) != p) {
+        panic(ErrShape)
+    }
+
+    ga, aGrid := a.(Grid[T])
+    gb, bGrid := b.(Grid[T])
+    gd, dGrid := dest.(Grid[T])
+    if aGrid && bGrid && dGrid {
+        x, y, d := ga.values, gb.values, gd.values[:n * p]
+        clear(d)
+        for i := 0; i < n; i++ {
+            row := d[i * p:(i + 1) * p]
+            for k := 0; k < m; k++ {
+                // i-k-j order walks each slice sequentially
+                aik := x[(i * m) + k]
+                if aik == 0 { continue }
+                bk := y[k * p:(k + 1) * p]
+                for j := range row {
+                    row[j] += aik * bk[j]
+                }
+            }
+        }
+        return
+    }
+
+    // group the non-zero elements of b by row
+    type element struct { column int; value T }
+    rows := make(map[int][]element)
+    nonZero(b, func(idx int, value T) {
+        k, j := idx / p, idx % p
+        rows[k] = append(rows[k], element{j, value})
+    })
+
+    result := make(map[int]T)
+    nonZero(a, func(idx int, value T) {
+        i, k := idx / m, idx % m
+        for _, e := range rows[k] {
+            result[(i * p) + e.column] += value * e.value
+        }
+    })
+    setAll(dest, result)
+}
+
+// Kronecker sets dest to the Kronecker product of a and b, a block matrix
+// formed by multiplying every element of a by the whole of b.
+//
+// The matrices a and b must have the same dimensionality, and dest must have
+// the same dimensionality, with a length along each axis equal to the
+// product of the lengths of a and b along that axis, or Kronecker panics with
+// [ErrShape]. The element of dest at offset (i × len(b)) + k along each axis
+// is the product of the element of a at offset i, and the element of b at
+// offset k, along each axis. For example, if a is 2×2 and b is 3×3, then
+// dest is 6×6.
+//
+// Only the non-zero elements of a and b are visited. The destination must
+// not be the same matrix as a or b.
+func Kronecker[T operator.Number](dest, a, b M[T]) {
+    dims := a.Dimensionality()
+    if (b.Dimensionality() != dims) || (dest.Dimensionality() != dims) { panic(ErrShape) }
+    for i := 0; i < dims; i++ {
+        if dest.Length(i) != a.Length(i) * b.Length(i) { panic(ErrShape) }
+    }
+
+    type element struct { offsets []int; value T }
+    var elements []element
+    nonZero(b, func(idx int, value T) {
+        offsets := make([]int, dims)
+        b.Offsets(offsets, idx)
+        elements = append(elements, element{offsets, value})
+    })
+
+    dest.Clear()
+    offsetsA := make([]int, dims)
+    offsets := make([]int, dims)
+    nonZero(a, func(idx int, value T) {
+        a.Offsets(offsetsA, idx)
+        for _, e := range elements {
+            for i := range offsets {
+                offsets[i] = (offsetsA[i] * b.Length(i)) + e.offsets[i]
+            }
+            dest.Set(dest.Index(offsets...), value * e.value)
+        }
+    })
+}
+
+// Outer sets dest to the outer product of u and v, so that each element of
+// dest is the product of an element of u and an element of v.
+//
+// The matrices u and v may have any dimensionality. The axes of dest are the
+// axes of v followed by the axes of u, or Outer panics with [ErrShape]. For
+// example, if u and v are 1-dimensional vectors of length n and m, then dest
+// has a width of m and a height of n, and the element at column j and row i
+// is the product of u[i] and v[j].
+//
+// Only the non-zero elements of u and v are visited. The destination must
+// not be the same matrix as u or v.
+func Outer[T operator.Number](dest, u, v M[T]) {
+    du, dv := u.Dimensionality(), v.Dimensionality()
+    if dest.Dimensionality() != du + dv { panic(ErrShape) }
+    for i := 0; i < dv; i++ {
+        if dest.Length(i) != v.Length(i) { panic(ErrShape) }
+    }
+    for i := 0; i < du; i++ {
+        if dest.Length(dv + i) != u.Length(i) { panic(ErrShape) }
+    }
+
+    // with row-major indexes, the axes of v vary fastest
+    dest.Clear()
+    nonZero(u, func(i int, x T) {
+        nonZero(v, func(j int, y T) {
+            dest.Set((i * v.Size()) + j, x * y)
+        })
+    })
+}
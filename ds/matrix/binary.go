@@ -0,0 +1,465 @@
+package matrix
+
+import (
+    "bufio"
+    "encoding/binary"
+    "errors"
+    "io"
+    "math"
+    "reflect"
+    "slices"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+    "github.com/tawesoft/golib/v2/hash/checksum"
+    "github.com/tawesoft/golib/v2/internal/words"
+)
+
+// ErrFormat is returned when reading invalid or corrupt binary data, or data
+// that represents a matrix of a different element type.
+var ErrFormat = errors.New("invalid or corrupt data")
+
+// ErrLimit is returned when reading binary data that represents a matrix
+// larger than the limit given to [Read].
+var ErrLimit = errors.New("matrix exceeds size limit")
+
+// ErrUnsupported is returned when writing or reading a matrix with an
+// element type that has no binary representation.
+var ErrUnsupported = errors.New("matrix element type cannot be serialised")
+
+// magic bytes in the header, including the format version
+const magic = uint64(
+    (uint64('M') <<  0) +
+    (uint64('a') <<  8) +
+    (uint64('t') << 16) +
+    (uint64('r') << 24) +
+    (uint64('i') << 32) +
+    (uint64('x') << 40) +
+    (uint64('V') << 48) +
+    (uint64('1') << 56))
+
+// implementation tags, identifying how the body is encoded
+const (
+    tagGrid = uint64(iota + 1)
+    tagBool
+    tagBit
+    tagHashmap
+    tagDiagonal
+    tagCSR
+    tagCSC
+)
+
+// readChunk is the number of values read at a time, so that a bad length
+// can't allocate memory beyond the size of the input.
+const readChunk = 4096
+
+// codec describes the binary representation of an element type.
+type codec struct {
+    kind reflect.Kind
+
+    // size is the encoded size of each value in bytes, or zero for strings,
+    // which are encoded as a length followed by that many bytes.
+    size int
+}
+
+// codecFor returns the binary representation of T, or false if there is
+// none. Supported types are those with a fixed size understood by
+// [encoding/binary] (booleans, sized numbers, and arrays and structs of
+// these), as well as int, uint, uintptr (encoded as 64 bits) and strings.
+func codecFor[T comparable]() (codec, bool) {
+    var zero T
+    t := reflect.TypeOf(zero)
+    if t == nil { return codec{}, false } // interface type
+
+    switch t.Kind() {
+        case reflect.Int, reflect.Uint, reflect.Uintptr:
+            return codec{t.Kind(), 8}, true
+        case reflect.String:
+            return codec{t.Kind(), 0}, true
+    }
+
+    if size := binary.Size(zero); size > 0 {
+        return codec{t.Kind(), size}, true
+    }
+    return codec{}, false
+}
+
+// encoder writes little-endian values, remembering the first error.
+type encoder struct {
+    w   io.Writer
+    buf [8]byte
+    err error
+}
+
+func (e *encoder) write(p []byte) {
+    if e.err != nil { return }
+    _, e.err = e.w.Write(p)
+}
+
+func (e *encoder) uint64s(values ... uint64) {
+    for _, value := range values {
+        binary.LittleEndian.PutUint64(e.buf[:], value)
+        e.write(e.buf[:])
+    }
+}
+
+func (e *encoder) ints(values []int) {
+    for _, value := range values {
+        e.uint64s(uint64(value))
+    }
+}
+
+func writeValues[T comparable](e *encoder, c codec, values []T) {
+    if e.err != nil { return }
+    switch c.kind {
+        case reflect.Int, reflect.Uint, reflect.Uintptr:
+            if xs, ok := any(values).([]int); ok {
+                e.ints(xs)
+                return
+            }
+            for _, value := range values {
+                v := reflect.ValueOf(value)
+                if c.kind == reflect.Int {
+                    e.uint64s(uint64(v.Int()))
+                } else {
+                    e.uint64s(v.Uint())
+                }
+            }
+        case reflect.String:
+            for _, value := range values {
+                s := reflect.ValueOf(value).String()
+                e.uint64s(uint64(len(s)))
+                e.write([]byte(s))
+            }
+        default:
+            e.err = binary.Write(e.w, binary.LittleEndian, values)
+    }
+}
+
+// Write writes an opaque binary representation of the matrix m into w. The
+// representation records the implementation of m, so that, for example, a
+// sparse matrix is written and read back as a sparse matrix. Other
+// implementations of M, such as a [View], are written as a [Grid].
+//
+// The element type must be a type with a fixed size understood by
+// [encoding/binary] (for example, a bool, float64, int32, or a struct of
+// these), an int, uint, or uintptr, or a string. Otherwise, the error is
+// [ErrUnsupported].
+func Write[T comparable](w io.Writer, m M[T]) error {
+    c, ok := codecFor[T]()
+    if !ok { return ErrUnsupported }
+    if cm, ok := m.(constMatrix[T]); ok { m = cm.m }
+
+    var digest checksum.Digest
+    bw := bufio.NewWriter(w)
+    e := &encoder{w: io.MultiWriter(bw, &digest)}
+
+    tag := tagGrid
+    switch any(m).(type) {
+        case Bool:        tag = tagBool
+        case Bit:         tag = tagBit
+        case Hashmap[T]:  tag = tagHashmap
+        case Diagonal[T]: tag = tagDiagonal
+        case CSR[T]:      tag = tagCSR
+        case CSC[T]:      tag = tagCSC
+    }
+
+    e.uint64s(magic, tag, uint64(c.kind), uint64(c.size))
+    e.uint64s(uint64(m.Dimensionality()))
+    for i := 0; i < m.Dimensionality(); i++ {
+        e.uint64s(uint64(m.Length(i)))
+    }
+
+    switch x := any(m).(type) {
+        case Grid[T]:
+            writeValues(e, c, x.values[:x.Size()])
+        case Bool:
+            e.uint64s(x.buckets...)
+        case Bit:
+            e.uint64s(x.buckets...)
+        case Hashmap[T]:
+            var zero T
+            indexes := make([]int, 0, len(x.values))
+            for idx, value := range x.values {
+                if value != zero { indexes = append(indexes, idx) }
+            }
+            slices.Sort(indexes)
+            values := make([]T, len(indexes))
+            for i, idx := range indexes {
+                values[i] = x.values[idx]
+            }
+            e.uint64s(uint64(len(indexes)))
+            e.ints(indexes)
+            writeValues(e, c, values)
+        case Diagonal[T]:
+            writeValues(e, c, x.values)
+        case CSR[T]:
+            e.uint64s(uint64(len(x.c.values)))
+            e.ints(x.c.offsets)
+            e.ints(x.c.indexes)
+            writeValues(e, c, x.c.values)
+        case CSC[T]:
+            e.uint64s(uint64(len(x.c.values)))
+            e.ints(x.c.offsets)
+            e.ints(x.c.indexes)
+            writeValues(e, c, x.c.values)
+        default:
+            values := make([]T, min(m.Size(), readChunk))
+            for start := 0; start < m.Size(); start += len(values) {
+                chunk := values[:min(len(values), m.Size() - start)]
+                for i := range chunk {
+                    chunk[i] = m.Get(start + i)
+                }
+                writeValues(e, c, chunk)
+            }
+    }
+
+    e.w = bw // the checksum itself is not checksummed
+    e.uint64s(digest.Sum64())
+    if e.err != nil { return e.err }
+    return bw.Flush()
+}
+
+// unexpected converts an [io.EOF] part-way through the data into an
+// [io.ErrUnexpectedEOF].
+func unexpected(err error) error {
+    if err == io.EOF { return io.ErrUnexpectedEOF }
+    return err
+}
+
+// decoder reads little-endian values.
+type decoder struct {
+    r     io.Reader
+    limit int
+    buf   []byte
+}
+
+func (d *decoder) uint64s(dest []uint64) error {
+    buf := d.buf[0:8 * len(dest)]
+    if _, err := io.ReadFull(d.r, buf); err != nil { return unexpected(err) }
+    for i := range dest {
+        dest[i] = binary.LittleEndian.Uint64(buf[8*i:])
+    }
+    return nil
+}
+
+// count reads a length, which must not be greater than max.
+func (d *decoder) count(max int) (int, error) {
+    var n [1]uint64
+    if err := d.uint64s(n[:]); err != nil { return 0, err }
+    if n[0] > uint64(max) { return 0, ErrFormat }
+    return int(n[0]), nil
+}
+
+// ints reads n integers, each of which must not be greater than max.
+func (d *decoder) ints(n int, max int) ([]int, error) {
+    if n < 0 { return nil, ErrFormat }
+    result := make([]int, 0, min(n, readChunk))
+    values := make([]uint64, min(n, readChunk))
+    for remaining := n; remaining > 0; {
+        chunk := values[0:min(remaining, readChunk)]
+        if err := d.uint64s(chunk); err != nil { return nil, err }
+        for _, value := range chunk {
+            if value > uint64(max) { return nil, ErrFormat }
+            result = append(result, int(value))
+        }
+        remaining -= len(chunk)
+    }
+    return result, nil
+}
+
+func readValues[T comparable](d *decoder, c codec, n int) ([]T, error) {
+    if n < 0 { return nil, ErrFormat }
+    result := make([]T, 0, min(n, readChunk))
+    values := make([]T, min(n, readChunk))
+    var raw []uint64
+    if c.size == 8 { raw = make([]uint64, len(values)) }
+
+    for remaining := n; remaining > 0; {
+        chunk := values[0:min(remaining, readChunk)]
+        switch c.kind {
+            case reflect.Int, reflect.Uint, reflect.Uintptr:
+                if err := d.uint64s(raw[0:len(chunk)]); err != nil { return nil, err }
+                for i := range chunk {
+                    v := reflect.ValueOf(&chunk[i]).Elem()
+                    if c.kind == reflect.Int {
+                        x := int64(raw[i])
+                        if v.OverflowInt(x) { return nil, ErrFormat }
+                        v.SetInt(x)
+                    } else {
+                        if v.OverflowUint(raw[i]) { return nil, ErrFormat }
+                        v.SetUint(raw[i])
+                    }
+                }
+            case reflect.String:
+                for i := range chunk {
+                    length, err := d.count(math.MaxInt)
+                    if err != nil { return nil, err }
+                    if (d.limit > 0) && (length > d.limit) { return nil, ErrLimit }
+
+                    // grows as data is read
+                    var sb strings.Builder
+                    if _, err := io.CopyN(&sb, d.r, int64(length)); err != nil {
+                        return nil, unexpected(err)
+                    }
+                    reflect.ValueOf(&chunk[i]).Elem().SetString(sb.String())
+                }
+            default:
+                if err := binary.Read(d.r, binary.LittleEndian, chunk); err != nil {
+                    return nil, unexpected(err)
+                }
+        }
+        result = append(result, chunk...)
+        remaining -= len(chunk)
+    }
+    return result, nil
+}
+
+// Read reads an opaque binary representation, written by [Write], from r,
+// and returns a new matrix using the same implementation as the matrix that
+// was written. If the data is invalid or corrupt, or represents a matrix
+// with a different element type, the error is [ErrFormat]. If the element
+// type T cannot be serialised, the error is [ErrUnsupported].
+//
+// If limit is greater than zero, it is the maximum number of values that
+// may be stored: every element of a dense matrix, or only the non-zero
+// elements of a sparse matrix. For a matrix of strings, it is also the
+// maximum length of each string. If the data exceeds the limit, the error is
+// [ErrLimit].
+//
+// Read does not consume any input past the end of the data, so it may be
+// followed by other data in the same stream. For many small reads, r should
+// be buffered e.g. with [bufio.NewReader].
+//
+// Important: While relatively robust against corrupt data, care should be
+// taken when parsing arbitrary input. A malicious actor could craft an input
+// that would allocate a large amount of memory, or attempt to extract
+// information by continuing to consume from the reader. A limit, and
+// [io.LimitReader], may be helpful here.
+func Read[T comparable](r io.Reader, limit int) (M[T], error) {
+    c, ok := codecFor[T]()
+    if !ok { return nil, ErrUnsupported }
+
+    var digest checksum.Digest
+    d := &decoder{
+        r:     io.TeeReader(r, &digest),
+        limit: limit,
+        buf:   make([]byte, 8 * readChunk),
+    }
+    checkLimit := func(n int) error {
+        if (limit > 0) && (n > limit) { return ErrLimit }
+        return nil
+    }
+
+    var header [5]uint64
+    if _, err := io.ReadFull(d.r, d.buf[0:8 * len(header)]); err != nil { return nil, err }
+    for i := range header {
+        header[i] = binary.LittleEndian.Uint64(d.buf[8*i:])
+    }
+    tag, kind, size, dimensionality := header[1], header[2], header[3], header[4]
+    if header[0] != magic { return nil, ErrFormat }
+    if (kind != uint64(c.kind)) || (size != uint64(c.size)) { return nil, ErrFormat }
+    if (dimensionality == 0) || (dimensionality > 64) { return nil, ErrFormat }
+
+    lengths64 := make([]uint64, dimensionality)
+    if err := d.uint64s(lengths64); err != nil { return nil, err }
+    lengths := make([]int, dimensionality)
+    total := 1
+    for i, length := range lengths64 {
+        if (length == 0) || (length > uint64(math.MaxInt / total)) { return nil, ErrFormat }
+        lengths[i] = int(length)
+        total *= lengths[i]
+    }
+    dims := dimensions.New(lengths...)
+
+    var m M[T]
+    switch tag {
+        case tagGrid:
+            if err := checkLimit(total); err != nil { return nil, err }
+            values, err := readValues[T](d, c, total)
+            if err != nil { return nil, err }
+            m = Grid[T]{D: dims, values: values}
+
+        case tagBool, tagBit:
+            var x any = Bool{}
+            if tag == tagBit { x = Bit{} }
+            if _, ok := x.(M[T]); !ok { return nil, ErrFormat }
+            if err := checkLimit(total); err != nil { return nil, err }
+
+            n := words.Words(total)
+            buckets := make([]uint64, 0, min(n, readChunk))
+            for remaining := n; remaining > 0; {
+                chunk := make([]uint64, min(remaining, readChunk))
+                if err := d.uint64s(chunk); err != nil { return nil, err }
+                buckets = append(buckets, chunk...)
+                remaining -= len(chunk)
+            }
+            if (total % 64 != 0) && (buckets[n - 1] >> (total % 64) != 0) {
+                return nil, ErrFormat // bits set past the last element
+            }
+            x = Bool{D: dims, buckets: buckets}
+            if tag == tagBit { x = Bit{D: dims, buckets: buckets} }
+            m = x.(M[T])
+
+        case tagHashmap:
+            n, err := d.count(total)
+            if err != nil { return nil, err }
+            if err := checkLimit(n); err != nil { return nil, err }
+            indexes, err := d.ints(n, total - 1)
+            if err != nil { return nil, err }
+            values, err := readValues[T](d, c, n)
+            if err != nil { return nil, err }
+
+            var zero T
+            result := make(map[int]T, n)
+            for i, idx := range indexes {
+                if (i > 0) && (idx <= indexes[i - 1]) { return nil, ErrFormat }
+                if values[i] != zero { result[idx] = values[i] }
+            }
+            m = Hashmap[T]{D: dims, values: result}
+
+        case tagDiagonal:
+            for _, length := range lengths {
+                if length != lengths[0] { return nil, ErrFormat }
+            }
+            if err := checkLimit(lengths[0]); err != nil { return nil, err }
+            values, err := readValues[T](d, c, lengths[0])
+            if err != nil { return nil, err }
+            m = Diagonal[T]{D: dims, values: values}
+
+        case tagCSR, tagCSC:
+            if dimensionality != 2 { return nil, ErrFormat }
+            lines, length := lengths[1], lengths[0]
+            if tag == tagCSC { lines, length = length, lines }
+            if lines >= math.MaxInt { return nil, ErrFormat } // lines + 1 offsets
+
+            n, err := d.count(total)
+            if err != nil { return nil, err }
+            if err := checkLimit(n); err != nil { return nil, err }
+            offsets, err := d.ints(lines + 1, n)
+            if err != nil { return nil, err }
+            indexes, err := d.ints(n, length - 1)
+            if err != nil { return nil, err }
+            values, err := readValues[T](d, c, n)
+            if err != nil { return nil, err }
+            if checkCompressed(lines, length, offsets, indexes, values) != nil {
+                return nil, ErrFormat
+            }
+
+            storage := &compressed[T]{offsets, indexes, values}
+            if tag == tagCSR {
+                m = CSR[T]{D: dims, c: storage}
+            } else {
+                m = CSC[T]{D: dims, c: storage}
+            }
+
+        default:
+            return nil, ErrFormat
+    }
+
+    expected := digest.Sum64()
+    var sum [8]byte
+    if _, err := io.ReadFull(r, sum[:]); err != nil { return nil, unexpected(err) }
+    if binary.LittleEndian.Uint64(sum[:]) != expected { return nil, ErrFormat }
+    return m, nil
+}
@@ -0,0 +1,123 @@
+package matrix
+
+import (
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+)
+
+// BlockSparse is an implementation of the matrix interface [M] that divides
+// a matrix into blocks of a fixed shape, and stores each block as a
+// contiguous slice of values, allocated only when an element in that block is
+// first set to a non-zero value. In most cases, this is initialised by
+// calling [NewBlockSparse].
+//
+// This is best suited to matrices that are sparse at a coarse granularity,
+// but dense within clusters of elements, such as the adjacency matrix of a
+// graph with a community structure. Compared to a [Hashmap], this uses less
+// memory per element within a block, and Next skips over each row of an
+// unallocated block with a single lookup, without visiting each of its
+// elements.
+//
+// Once allocated, a block remains allocated until the matrix is cleared.
+type BlockSparse[T comparable] struct {
+    dimensions.D
+
+    // block is the shape of each block, and grid is the shape of the blocks
+    // covering the whole matrix. Blocks at the far edges of the matrix may
+    // extend past it.
+    block dimensions.D
+    grid  dimensions.D
+
+    blocks map[int][]T
+}
+
+    // NewBlockSparse allocates and returns a new, empty, [BlockSparse]
+    // matrix implementing M, with the given lengths along each axis, divided
+    // into blocks with the given blockLengths along each axis.
+    //
+    // Panics with [ErrShape] if the number of blockLengths is not the same as
+    // the number of lengths, or if any block length is not greater than zero.
+    func NewBlockSparse[T comparable](blockLengths []int, lengths ... int) M[T] {
+        if len(blockLengths) != len(lengths) { panic(ErrShape) }
+        gridLengths := make([]int, len(lengths))
+        for i, n := range blockLengths {
+            if n <= 0 { panic(ErrShape) }
+            gridLengths[i] = (lengths[i] + n - 1) / n
+        }
+        return BlockSparse[T]{
+            D:      dimensions.New(lengths...),
+            block:  dimensions.New(blockLengths...),
+            grid:   dimensions.New(gridLengths...),
+            blocks: make(map[int][]T),
+        }
+    }
+
+    // locate returns the index of the block containing an element, the index
+    // of the element within the block, and the offset of the element along
+    // the first axis within the block. This is called for every element
+    // access, so computes each index directly, without allocating.
+    func (m BlockSparse[T]) locate(idx int) (block int, inner int, x int) {
+        blockStride, innerStride := 1, 1
+        for i := 0; i < m.Dimensionality(); i++ {
+            length, n := m.Length(i), m.block.Length(i)
+            offset := idx % length
+            idx /= length
+
+            block += (offset / n) * blockStride
+            inner += (offset % n) * innerStride
+            if i == 0 { x = offset % n }
+
+            blockStride *= m.grid.Length(i)
+            innerStride *= n
+        }
+        return block, inner, x
+    }
+
+    func (m BlockSparse[T]) Get(idx int) T {
+        block, inner, _ := m.locate(idx)
+        if values, ok := m.blocks[block]; ok { return values[inner] }
+        var zero T
+        return zero
+    }
+
+    func (m BlockSparse[T]) Set(idx int, value T) {
+        var zero T
+        block, inner, _ := m.locate(idx)
+        values, ok := m.blocks[block]
+        if !ok {
+            if value == zero { return }
+            values = make([]T, m.block.Size())
+            m.blocks[block] = values
+        }
+        values[inner] = value
+    }
+
+    func (m BlockSparse[T]) Next(idx int) (int, bool) {
+        var zero T
+        width, blockWidth := m.Length(0), m.block.Length(0)
+        for i := max(idx + 1, 0); i < m.Size(); {
+            block, inner, x := m.locate(i)
+
+            // the run of elements along the first axis in this block
+            run := min(blockWidth - x, width - (i % width))
+
+            values, ok := m.blocks[block]
+            if !ok {
+                i += run
+                continue
+            }
+            for j := 0; j < run; j++ {
+                if values[inner + j] != zero { return i + j, true }
+            }
+            i += run
+        }
+        return 0, false
+    }
+
+    func (m BlockSparse[T]) Clear() {
+        clear(m.blocks)
+    }
+
+    // Blocks returns the number of blocks that have been allocated.
+    func (m BlockSparse[T]) Blocks() int {
+        return len(m.blocks)
+    }
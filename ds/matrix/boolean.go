@@ -0,0 +1,131 @@
+package matrix
+
+import (
+    "github.com/tawesoft/golib/v2/internal/words"
+)
+
+// packedBuckets returns the underlying storage of a [Bool] or [Bit] matrix,
+// or false for any other implementation.
+func packedBuckets[T comparable](m M[T]) ([]uint64, bool) {
+    switch x := any(m).(type) {
+        case Bool: return x.buckets, true
+        case Bit:  return x.buckets, true
+        default:   return nil, false
+    }
+}
+
+// bitRows returns each row of a 2-dimensional matrix packed into words,
+// where each non-zero element is a set bit.
+func bitRows[T bool | int](m M[T]) [][]uint64 {
+    width, height := m.Length(0), m.Length(1)
+    n := words.Words(width)
+    storage := make([]uint64, n * height)
+    rows := make([][]uint64, height)
+    for y := range rows {
+        rows[y] = storage[y * n:(y + 1) * n:(y + 1) * n]
+    }
+
+    if buckets, ok := packedBuckets(m); ok {
+        for y, row := range rows {
+            words.Extract(row, buckets, y * width, width)
+        }
+    } else {
+        nonZero(m, func(idx int, _ T) {
+            x := idx % width
+            rows[idx / width][x / 64] |= 1 << (x % 64)
+        })
+    }
+    return rows
+}
+
+// setRow sets each element in row y of a 2-dimensional matrix to true (or
+// one) where the bit at the same position in row is set, or false (or zero)
+// otherwise.
+func setRow[T bool | int](m M[T], y int, row []uint64) {
+    width := m.Length(0)
+    if buckets, ok := packedBuckets(m); ok {
+        words.Insert(buckets, row, y * width, width)
+        return
+    }
+
+    var zero, one T
+    switch p := any(&one).(type) {
+        case *bool: *p = true
+        case *int:  *p = 1
+    }
+    for x := 0; x < width; x++ {
+        value := zero
+        if (row[x / 64] & (1 << (x % 64))) != 0 { value = one }
+        m.Set((y * width) + x, value)
+    }
+}
+
+// BoolMul sets dest to the boolean matrix product of a and b, where each is
+// a 2-dimensional matrix with a width (number of columns) and height (number
+// of rows). This is the same as the matrix product computed by [MatMul],
+// except that addition is logical OR and multiplication is logical AND, so
+// that the element at column j and row i of dest is true (or one) if there is
+// any k where the element at column k and row i of a, and the element at
+// column j and row k of b, are both non-zero.
+//
+// For example, if a and b are adjacency matrices of a graph, where the
+// element at column j and row i is non-zero if there is an edge from vertex i
+// to vertex j, then dest is the matrix of paths of length two.
+//
+// Rows are combined a whole word at a time, which is especially fast if
+// the matrices are a [Bool] or [Bit].
+//
+// The width of a must equal the height of b, and dest must have the height
+// of a and the width of b, or BoolMul panics with [ErrShape]. The
+// destination may be the same matrix as a or b.
+func BoolMul[T bool | int](dest, a, b M[T]) {
+    if (a.Dimensionality() != 2) || (b.Dimensionality() != 2) || (dest.Dimensionality() != 2) {
+        panic(ErrShape)
+    }
+    n, m, p := a.Length(1), a.Length(0), b.Length(0) // (n×m)(m×p) = (n×p)
+    if (b.Length(1) != m) || (dest.Length(1) != n) || (dest.Length(0) != p) {
+        panic(ErrShape)
+    }
+
+    // computed before modifying dest, which may be a or b
+    rowsA, rowsB := bitRows(a), bitRows(b)
+
+    row := make([]uint64, words.Words(p))
+    for i := 0; i < n; i++ {
+        clear(row)
+        for k, ok := words.Next(rowsA[i], -1); ok; k, ok = words.Next(rowsA[i], k) {
+            words.Or(row, rowsB[k])
+        }
+        setRow(dest, i, row)
+    }
+}
+
+// TransitiveClosure sets dest to the transitive closure of a square
+// 2-dimensional adjacency matrix, where the element at column j and row i is
+// non-zero if there is an edge from vertex i to vertex j. In the result, the
+// element at column j and row i is true (or one) if there is a path of one or
+// more edges from vertex i to vertex j.
+//
+// This uses Warshall's algorithm, combining rows a whole word at a time,
+// which is especially fast if the matrices are a [Bool] or [Bit].
+//
+// The matrices must have the same shape, and be square, or
+// TransitiveClosure panics with [ErrShape]. The destination may be the same
+// matrix as adjacency.
+func TransitiveClosure[T bool | int](dest, adjacency M[T]) {
+    if (adjacency.Dimensionality() != 2) || !sameShape(dest, adjacency) { panic(ErrShape) }
+    n := square(adjacency)
+
+    rows := bitRows(adjacency)
+    for k := 0; k < n; k++ {
+        for i := 0; i < n; i++ {
+            if (rows[i][k / 64] & (1 << (k % 64))) != 0 {
+                words.Or(rows[i], rows[k])
+            }
+        }
+    }
+
+    for i, row := range rows {
+        setRow(dest, i, row)
+    }
+}
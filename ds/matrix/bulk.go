@@ -0,0 +1,186 @@
+package matrix
+
+// Fill sets every element of the matrix m to value.
+//
+// A [Grid], [Bool], or [Bit] is filled a slice or a word at a time, and
+// filling any matrix with the zero value is equivalent to calling Clear. Note
+// that some implementations, such as a [Diagonal], can't represent every
+// matrix, and will panic if filled with a non-zero value.
+func Fill[T comparable](m M[T], value T) {
+    var zero T
+    if value == zero {
+        m.Clear()
+        return
+    }
+
+    switch x := any(m).(type) {
+        case Grid[T]:
+            values := x.values[:x.Size()]
+            for i := range values {
+                values[i] = value
+            }
+        case Bool:
+            x.ProcessBuckets(fillBuckets)
+        case Bit:
+            if any(value).(int) > 0 {
+                x.ProcessBuckets(fillBuckets)
+            } else {
+                x.Clear()
+            }
+        default:
+            for i := 0; i < m.Size(); i++ {
+                m.Set(i, value)
+            }
+    }
+}
+
+func fillBuckets(buckets []uint64) {
+    for i := range buckets {
+        buckets[i] = ^uint64(0)
+    }
+}
+
+// MapValues sets each element of dest to the result of calling f on the
+// element at the same index in src. The matrices must have the same shape, or
+// MapValues panics with [ErrShape]. If T and U are the same type, the
+// destination may be the same matrix as src.
+//
+// If src is sparse (for example a [Hashmap], [Diagonal], or [CSR]), and f
+// maps the zero value of T to the zero value of U, only the non-zero elements
+// of src are visited. If src is a [Bool] or [Bit], f is only called once for
+// each possible value.
+func MapValues[T comparable, U comparable](dest M[U], src M[T], f func(T) U) {
+    if !sameShape(dest, src) { panic(ErrShape) }
+
+    var zeroT T
+    var zeroU U
+    gs, sGrid := src.(Grid[T])
+    gd, dGrid := dest.(Grid[U])
+
+    switch {
+        case sGrid && dGrid:
+            s, d := gs.values[:gs.Size()], gd.values[:gs.Size()]
+            for i := range d {
+                d[i] = f(s[i])
+            }
+        case isSparse(src) && (f(zeroT) == zeroU):
+            // computed before modifying dest, which may be src
+            result := make(map[int]U)
+            nonZero(src, func(idx int, value T) { result[idx] = f(value) })
+            setAll(dest, result)
+        default:
+            var buckets []uint64
+            var one T
+            switch x := any(src).(type) {
+                case Bool: buckets, one = x.buckets, any(true).(T)
+                case Bit:  buckets, one = x.buckets, any(1).(T)
+            }
+
+            if buckets != nil {
+                values := [2]U{f(zeroT), f(one)}
+                for i := 0; i < src.Size(); i++ {
+                    dest.Set(i, values[(buckets[i / 64] >> (i % 64)) & 1])
+                }
+                return
+            }
+
+            for i := 0; i < src.Size(); i++ {
+                dest.Set(i, f(src.Get(i)))
+            }
+    }
+}
+
+// Reduce calls f for each element of the matrix m, in increasing order of
+// index, with the result of the previous call (or init, for the first call)
+// and the element, and returns the result of the last call.
+//
+// A [Grid], [Bool], or [Bit] is read directly from its storage, rather than
+// by calling Get for each element.
+func Reduce[T comparable, R any](m M[T], init R, f func(R, T) R) R {
+    result := init
+    var buckets []uint64
+    var values [2]T
+
+    switch x := any(m).(type) {
+        case Grid[T]:
+            for _, value := range x.values[:x.Size()] {
+                result = f(result, value)
+            }
+            return result
+        case Bool:
+            buckets = x.buckets
+            values = [2]T{any(false).(T), any(true).(T)}
+        case Bit:
+            buckets = x.buckets
+            values = [2]T{any(0).(T), any(1).(T)}
+        default:
+            for i := 0; i < m.Size(); i++ {
+                result = f(result, m.Get(i))
+            }
+            return result
+    }
+
+    // a word at a time
+    size := m.Size()
+    for i, word := range buckets {
+        n := min(64, size - (i * 64))
+        for j := 0; j < n; j++ {
+            result = f(result, values[word & 1])
+            word >>= 1
+        }
+    }
+    return result
+}
+
+// ReduceAxis is like [Reduce], but reduces each line of elements of src
+// along the given axis separately, and stores each result in dest, so that
+// dest has one fewer dimension than src. For each element of dest, f is
+// called for each element along the axis, in increasing order of offset,
+// with the result of the previous call (or init, for the first call) and the
+// element.
+//
+// For example, given a 2-dimensional matrix, ReduceAxis along axis 0 (the x
+// axis) computes a result for each row, such as the sum of each row, and
+// along axis 1 (the y axis) computes a result for each column, such as the
+// maximum of each column.
+//
+// The lengths of dest must be the lengths of src without the given axis, or
+// ReduceAxis panics with [ErrShape]. As a special case, a 1-dimensional src
+// is reduced to a dest with a single element.
+func ReduceAxis[T comparable, R comparable](dest M[R], src M[T], axis int, init R, f func(R, T) R) {
+    dims := src.Dimensionality()
+    if (axis < 0) || (axis >= dims) { panic(ErrShape) }
+    if dims == 1 {
+        if dest.Size() != 1 { panic(ErrShape) }
+    } else {
+        if dest.Dimensionality() != dims - 1 { panic(ErrShape) }
+        for i, j := 0, 0; i < dims; i++ {
+            if i == axis { continue }
+            if dest.Length(j) != src.Length(i) { panic(ErrShape) }
+            j++
+        }
+    }
+
+    // in row-major order, an index in src is made up of an offset along
+    // the axis, an index into the axes before it (inner), and an index
+    // into the axes after it (outer)
+    inner := 1
+    for i := 0; i < axis; i++ {
+        inner *= src.Length(i)
+    }
+    block := inner * src.Length(axis)
+
+    results := make([]R, dest.Size())
+    for i := range results {
+        results[i] = init
+    }
+    for idx := 0; idx < src.Size(); idx++ {
+        j := ((idx / block) * inner) + (idx % inner)
+        results[j] = f(results[j], src.Get(idx))
+    }
+
+    for i, result := range results {
+        dest.Set(i, result)
+    }
+}
+
@@ -0,0 +1,80 @@
+package matrix
+
+import (
+    "slices"
+
+    "github.com/tawesoft/golib/v2/internal/words"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// nonZeroUnion returns an iterator that produces, in increasing order, each
+// index where a or b (which have the same shape) is non-zero, by walking the
+// non-zero elements of both matrices in tandem.
+func nonZeroUnion[T comparable](a, b M[T]) iter.It[int] {
+    ia, aOk := a.Next(-1)
+    ib, bOk := b.Next(-1)
+    return func() (int, bool) {
+        var idx int
+        switch {
+            case !aOk && !bOk:
+                return 0, false
+            case aOk && (!bOk || (ia < ib)):
+                idx = ia
+                ia, aOk = a.Next(ia)
+            case bOk && (!aOk || (ib < ia)):
+                idx = ib
+                ib, bOk = b.Next(ib)
+            default: // same index
+                idx = ia
+                ia, aOk = a.Next(ia)
+                ib, bOk = b.Next(ib)
+        }
+        return idx, true
+    }
+}
+
+// Equal returns true if the matrices a and b have the same shape, and every
+// element of a is equal to the element at the same index in b.
+//
+// Only the non-zero elements of each matrix are visited. Two matrices that
+// are both a [Grid], both a [Bool], or both a [Bit] are compared directly.
+func Equal[T comparable](a, b M[T]) bool {
+    if !sameShape(a, b) { return false }
+
+    switch x := any(a).(type) {
+        case Grid[T]:
+            if y, ok := b.(Grid[T]); ok {
+                return slices.Equal(x.values[:x.Size()], y.values[:y.Size()])
+            }
+        case Bool:
+            if y, ok := any(b).(Bool); ok { return words.Equal(x.buckets, y.buckets) }
+        case Bit:
+            if y, ok := any(b).(Bit); ok { return words.Equal(x.buckets, y.buckets) }
+    }
+
+    it := nonZeroUnion(a, b)
+    for idx, ok := it(); ok; idx, ok = it() {
+        if a.Get(idx) != b.Get(idx) { return false }
+    }
+    return true
+}
+
+// Diff returns an iterator that produces, in increasing order of index, each
+// element where the matrices a and b differ, as a pair of the index and the
+// values of the element in a and b, respectively. Only the non-zero elements
+// of each matrix are visited.
+//
+// The matrices must have the same shape, or Diff panics with [ErrShape].
+// The matrices should not be modified while the iterator is in use.
+func Diff[T comparable](a, b M[T]) iter.It[iter.Pair[int, [2]T]] {
+    if !sameShape(a, b) { panic(ErrShape) }
+
+    it := nonZeroUnion(a, b)
+    return func() (iter.Pair[int, [2]T], bool) {
+        for idx, ok := it(); ok; idx, ok = it() {
+            x, y := a.Get(idx), b.Get(idx)
+            if x != y { return iter.Pair[int, [2]T]{Key: idx, Value: [2]T{x, y}}, true }
+        }
+        return iter.Pair[int, [2]T]{}, false
+    }
+}
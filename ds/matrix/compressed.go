@@ -0,0 +1,334 @@
+package matrix
+
+import (
+    "errors"
+    "sort"
+
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+)
+
+// compressed is the storage shared by the CSR and CSC matrix types. Each
+// "line" (a row, for CSR, or a column, for CSC) is a sorted run of (minor
+// index, value) pairs.
+type compressed[T comparable] struct {
+    // offsets[i] to offsets[i+1] is the range, in indexes and values, of the
+    // entries in line i.
+    offsets []int
+
+    // indexes is the position of each entry along its line.
+    indexes []int
+    values  []T
+}
+
+func newCompressed[T comparable](lines int) *compressed[T] {
+    return &compressed[T]{
+        offsets: make([]int, lines + 1),
+    }
+}
+
+// find returns the position in indexes and values of an entry, or where it
+// would be inserted, and true if the entry exists.
+func (c *compressed[T]) find(line, index int) (int, bool) {
+    start, end := c.offsets[line], c.offsets[line + 1]
+    i := start + sort.SearchInts(c.indexes[start:end], index)
+    return i, (i < end) && (c.indexes[i] == index)
+}
+
+func (c *compressed[T]) get(line, index int) T {
+    if i, ok := c.find(line, index); ok { return c.values[i] }
+    var zero T
+    return zero
+}
+
+func (c *compressed[T]) set(line, index int, value T) {
+    var zero T
+    i, ok := c.find(line, index)
+    switch {
+        case ok && (value != zero):
+            c.values[i] = value
+        case ok:
+            copy(c.indexes[i:], c.indexes[i+1:])
+            copy(c.values[i:], c.values[i+1:])
+            c.indexes = c.indexes[:len(c.indexes) - 1]
+            c.values[len(c.values) - 1] = zero
+            c.values = c.values[:len(c.values) - 1]
+            for j := line + 1; j < len(c.offsets); j++ {
+                c.offsets[j]--
+            }
+        case value != zero:
+            c.indexes = append(c.indexes, 0)
+            c.values = append(c.values, zero)
+            copy(c.indexes[i+1:], c.indexes[i:])
+            copy(c.values[i+1:], c.values[i:])
+            c.indexes[i] = index
+            c.values[i] = value
+            for j := line + 1; j < len(c.offsets); j++ {
+                c.offsets[j]++
+            }
+    }
+}
+
+// next returns the line and index of the first non-zero entry after the
+// given line and index, in storage order.
+func (c *compressed[T]) next(line, index int) (int, int, bool) {
+    var zero T
+    i, ok := c.find(line, index)
+    if ok { i++ }
+    for ; i < len(c.values); i++ {
+        if c.values[i] != zero { break } // shared storage may contain zeros
+    }
+    if i >= len(c.values) { return 0, 0, false }
+
+    // the line containing entry i is the last line that starts at or
+    // before it
+    lines := len(c.offsets) - 1
+    line = sort.Search(lines, func(j int) bool { return c.offsets[j + 1] > i })
+    return line, c.indexes[i], true
+}
+
+// nextAcross is like next, but returns the first non-zero entry after the
+// given line and index in the transposed order: that is, the entry with the
+// least index, and of those, the least line. This uses a binary search of
+// every line for its first entry after the given position. Use a line of -1
+// to start searching from index 0.
+func (c *compressed[T]) nextAcross(line, index int) (int, int, bool) {
+    var zero T
+    bestLine, bestIndex := -1, 0
+    for l := 0; l < len(c.offsets) - 1; l++ {
+        start, end := c.offsets[l], c.offsets[l + 1]
+        after := index
+        if l <= line { after++ } // at the same index, only a later line
+        i := start + sort.SearchInts(c.indexes[start:end], after)
+        for (i < end) && (c.values[i] == zero) { i++ } // shared storage may contain zeros
+        if i >= end { continue }
+        if (bestLine < 0) || (c.indexes[i] < bestIndex) {
+            bestLine, bestIndex = l, c.indexes[i]
+        }
+    }
+    return bestLine, bestIndex, bestLine >= 0
+}
+
+func (c *compressed[T]) clear() {
+    clear(c.offsets)
+    clear(c.values) // allow garbage collection
+    c.indexes = c.indexes[:0]
+    c.values = c.values[:0]
+}
+
+// resize returns the storage for a new number of lines and length of each
+// line, discarding entries outside these bounds. The indexes and values are
+// filtered in place.
+func (c *compressed[T]) resize(lines, length int) *compressed[T] {
+    offsets := make([]int, lines + 1)
+    n := 0
+    for line := 0; line < min(lines, len(c.offsets) - 1); line++ {
+        for j := c.offsets[line]; j < c.offsets[line + 1]; j++ {
+            if c.indexes[j] >= length { continue }
+            c.indexes[n], c.values[n] = c.indexes[j], c.values[j]
+            n++
+        }
+        offsets[line + 1] = n
+    }
+    for line := len(c.offsets); line <= lines; line++ {
+        offsets[line] = n // new empty lines
+    }
+    return &compressed[T]{
+        offsets: offsets,
+        indexes: c.indexes[:n],
+        values:  resizeSlice(c.values, n),
+    }
+}
+
+// compact returns the storage without any entries that are zero, with
+// the indexes and values filtered in place, then copied to slices with no
+// spare capacity.
+func (c *compressed[T]) compact() *compressed[T] {
+    var zero T
+    offsets := make([]int, len(c.offsets))
+    n := 0
+    for line := 0; line < len(c.offsets) - 1; line++ {
+        for j := c.offsets[line]; j < c.offsets[line + 1]; j++ {
+            if c.values[j] == zero { continue }
+            c.indexes[n], c.values[n] = c.indexes[j], c.values[j]
+            n++
+        }
+        offsets[line + 1] = n
+    }
+    return &compressed[T]{
+        offsets: offsets,
+        indexes: trimSlice(c.indexes, n),
+        values:  trimSlice(c.values, n),
+    }
+}
+
+// checkCompressed returns an error if the arrays are not a valid compressed
+// representation.
+func checkCompressed[T comparable](lines, length int, offsets, indexes []int, values []T) error {
+    if len(offsets) != lines + 1 { return errors.New("compressed matrix offsets have the wrong length") }
+    if len(indexes) != len(values) { return errors.New("compressed matrix indexes and values have different lengths") }
+    if (offsets[0] != 0) || (offsets[lines] != len(values)) {
+        return errors.New("compressed matrix offsets do not cover the values")
+    }
+    for i := 0; i < lines; i++ {
+        start, end := offsets[i], offsets[i + 1]
+        if (start > end) || (end > len(values)) {
+            return errors.New("compressed matrix offsets are not increasing")
+        }
+        for j := start; j < end; j++ {
+            if (indexes[j] < 0) || (indexes[j] >= length) {
+                return errors.New("compressed matrix index out of range")
+            }
+            if (j > start) && (indexes[j] <= indexes[j - 1]) {
+                return errors.New("compressed matrix indexes are not increasing")
+            }
+        }
+    }
+    return nil
+}
+
+// CSR is an implementation of the matrix interface [M] that represents a
+// sparse 2-dimensional matrix in Compressed Sparse Row format. Only non-zero
+// elements are stored, in row-major order, along with the column of each
+// element and the offset of the start of each row. In most cases, this is
+// initialised by calling [NewCSR] or [NewSharedCSR]. Performance sensitive
+// code may cast M to this type.
+//
+// Get takes O(log n) time, where n is the number of non-zero elements in a
+// row, and Next takes O(log h) time, where h is the height of the matrix,
+// regardless of how many zero elements it skips over. Setting an element that
+// was zero to a non-zero value, or a non-zero element to zero, takes
+// O(N + h) time, where N is the total number of non-zero elements, so a large
+// matrix is best constructed with [NewSharedCSR].
+type CSR[T comparable] struct {
+    dimensions.D
+    c *compressed[T]
+}
+
+    // NewCSR allocates and returns a new, empty, 2-dimensional [CSR] matrix
+    // implementing M.
+    func NewCSR[T comparable](width, height int) M[T] {
+        return CSR[T]{
+            D: dimensions.New(width, height),
+            c: newCompressed[T](height),
+        }
+    }
+
+    // NewSharedCSR returns a new 2-dimensional [CSR] matrix implementing M,
+    // using the provided slices, in the standard CSR format, as its storage:
+    //
+    //   - rowOffsets has length height + 1. The non-zero elements in row y
+    //     are at positions rowOffsets[y] to rowOffsets[y+1] in columns and
+    //     values.
+    //   - columns gives the column of each element, and must be strictly
+    //     increasing within each row.
+    //   - values gives the value of each element.
+    //
+    // This memory is shared: modifications to the values slice will modify
+    // the matrix, and setting an existing non-zero element of the matrix will
+    // modify the values slice. Adding or removing a non-zero element may
+    // reallocate the storage, after which it is no longer shared.
+    //
+    // Panics if the slices are not a valid CSR representation.
+    func NewSharedCSR[T comparable](width, height int, rowOffsets, columns []int, values []T) M[T] {
+        if err := checkCompressed(height, width, rowOffsets, columns, values); err != nil { panic(err) }
+        return CSR[T]{
+            D: dimensions.New(width, height),
+            c: &compressed[T]{rowOffsets, columns, values},
+        }
+    }
+
+    func (m CSR[T]) Get(idx int) T {
+        w := m.Length(0)
+        return m.c.get(idx / w, idx % w)
+    }
+
+    func (m CSR[T]) Set(idx int, value T) {
+        w := m.Length(0)
+        m.c.set(idx / w, idx % w, value)
+    }
+
+    func (m CSR[T]) Next(idx int) (int, bool) {
+        w := m.Length(0)
+        y, x := 0, -1
+        if idx >= 0 { y, x = idx / w, idx % w }
+        y, x, ok := m.c.next(y, x)
+        if !ok { return 0, false }
+        return (y * w) + x, true
+    }
+
+    func (m CSR[T]) Clear() {
+        m.c.clear()
+    }
+
+    // NonZero returns the number of non-zero elements stored in the matrix.
+    func (m CSR[T]) NonZero() int {
+        return len(m.c.values)
+    }
+
+// CSC is an implementation of the matrix interface [M] that represents a
+// sparse 2-dimensional matrix in Compressed Sparse Column format. It is the
+// same as [CSR], except that the non-zero elements are stored in
+// column-major order, along with the row of each element and the offset of
+// the start of each column. In most cases, this is initialised by calling
+// [NewCSC] or [NewSharedCSC]. Performance sensitive code may cast M to this
+// type.
+//
+// Like every implementation of M, Next enumerates non-zero elements in
+// increasing order of index, which is not the order in which they are
+// stored, so Next takes O(w log n) time, where w is the width of the matrix.
+// Where iterating with Next is common, prefer a [CSR] matrix.
+type CSC[T comparable] struct {
+    dimensions.D
+    c *compressed[T]
+}
+
+    // NewCSC allocates and returns a new, empty, 2-dimensional [CSC] matrix
+    // implementing M.
+    func NewCSC[T comparable](width, height int) M[T] {
+        return CSC[T]{
+            D: dimensions.New(width, height),
+            c: newCompressed[T](width),
+        }
+    }
+
+    // NewSharedCSC returns a new 2-dimensional [CSC] matrix implementing M,
+    // using the provided slices, in the standard CSC format, as its storage.
+    // This is the same as [NewSharedCSR], except that columnOffsets has
+    // length width + 1, and rows gives the row of each element.
+    //
+    // Panics if the slices are not a valid CSC representation.
+    func NewSharedCSC[T comparable](width, height int, columnOffsets, rows []int, values []T) M[T] {
+        if err := checkCompressed(width, height, columnOffsets, rows, values); err != nil { panic(err) }
+        return CSC[T]{
+            D: dimensions.New(width, height),
+            c: &compressed[T]{columnOffsets, rows, values},
+        }
+    }
+
+    func (m CSC[T]) Get(idx int) T {
+        w := m.Length(0)
+        return m.c.get(idx % w, idx / w)
+    }
+
+    func (m CSC[T]) Set(idx int, value T) {
+        w := m.Length(0)
+        m.c.set(idx % w, idx / w, value)
+    }
+
+    func (m CSC[T]) Next(idx int) (int, bool) {
+        w := m.Length(0)
+        x, y := -1, 0
+        if idx >= 0 { x, y = idx % w, idx / w }
+        x, y, ok := m.c.nextAcross(x, y)
+        if !ok { return 0, false }
+        return (y * w) + x, true
+    }
+
+    func (m CSC[T]) Clear() {
+        m.c.clear()
+    }
+
+    // NonZero returns the number of non-zero elements stored in the matrix.
+    func (m CSC[T]) NonZero() int {
+        return len(m.c.values)
+    }
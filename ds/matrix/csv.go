@@ -0,0 +1,120 @@
+package matrix
+
+import (
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io"
+)
+
+// csvOptions configures [ReadCSV] and [WriteCSV].
+type csvOptions struct {
+    delimiter rune
+    header    *[]string
+}
+
+// CSVOption configures the behaviour of [ReadCSV] and [WriteCSV].
+type CSVOption func(o *csvOptions)
+
+// CSVDelimiter returns a CSVOption that separates fields with the given
+// delimiter, instead of a comma. For example, use '\t' for TSV
+// (tab-separated values).
+func CSVDelimiter(delimiter rune) CSVOption {
+    return func(o *csvOptions) {
+        o.delimiter = delimiter
+    }
+}
+
+// CSVHeader returns a CSVOption for data where the first record is a header,
+// such as the name of each column, rather than values.
+//
+// When reading, the header is stored in *header, unless header is nil, in
+// which case it is discarded. When writing, *header is written as the first
+// record, unless header is nil or empty.
+func CSVHeader(header *[]string) CSVOption {
+    return func(o *csvOptions) {
+        o.header = header
+        if header == nil { o.header = new([]string) }
+    }
+}
+
+func newCSVOptions(opts []CSVOption) csvOptions {
+    o := csvOptions{delimiter: ','}
+    for _, opt := range opts {
+        opt(&o)
+    }
+    return o
+}
+
+// ReadCSV reads CSV (comma-separated values) data from r, and returns a new
+// 2-dimensional [Grid] matrix, where each record is a row, and each field of
+// a record is parsed into a value with the function parse. Options, such as
+// [CSVDelimiter] and [CSVHeader], may be used to read other formats.
+//
+// Every record must have the same number of fields. The error returned for
+// a field that cannot be parsed wraps the error returned by parse. Returns
+// an error wrapping [ErrFormat] if there are no records.
+func ReadCSV[T comparable](r io.Reader, parse func(string) (T, error), opts ... CSVOption) (M[T], error) {
+    o := newCSVOptions(opts)
+    cr := csv.NewReader(r)
+    cr.Comma = o.delimiter
+    cr.ReuseRecord = true
+
+    if o.header != nil {
+        header, err := cr.Read()
+        if errors.Is(err, io.EOF) { return nil, fmt.Errorf("%w: missing CSV header", ErrFormat) }
+        if err != nil { return nil, err }
+        *o.header = append([]string{}, header...)
+    }
+
+    var width, height int
+    var values []T
+    for {
+        record, err := cr.Read()
+        if errors.Is(err, io.EOF) { break }
+        if err != nil { return nil, err }
+
+        width = len(record)
+        for column, field := range record {
+            value, err := parse(field)
+            if err != nil {
+                line, _ := cr.FieldPos(column)
+                return nil, fmt.Errorf("CSV line %d, column %d: %w", line, column + 1, err)
+            }
+            values = append(values, value)
+        }
+        height++
+    }
+
+    if (width == 0) || (height == 0) { return nil, fmt.Errorf("%w: no CSV records", ErrFormat) }
+    return NewSharedGrid([]int{width, height}, values), nil
+}
+
+// WriteCSV writes a 2-dimensional matrix to w as CSV (comma-separated
+// values) data, where each row is a record, and each value is formatted
+// as a field with the function format. Options, such as [CSVDelimiter] and
+// [CSVHeader], may be used to write other formats.
+//
+// Returns [ErrShape] if the matrix is not 2-dimensional.
+func WriteCSV[T comparable](w io.Writer, m M[T], format func(T) string, opts ... CSVOption) error {
+    if m.Dimensionality() != 2 { return ErrShape }
+    o := newCSVOptions(opts)
+    cw := csv.NewWriter(w)
+    cw.Comma = o.delimiter
+
+    if (o.header != nil) && (len(*o.header) > 0) {
+        if err := cw.Write(*o.header); err != nil { return err }
+    }
+
+    width, height := m.Length(0), m.Length(1)
+    record := make([]string, width)
+    for y := 0; y < height; y++ {
+        for x := range record {
+            record[x] = format(m.Get(m.Index(x, y)))
+        }
+        if err := cw.Write(record); err != nil { return err }
+    }
+
+    cw.Flush()
+    return cw.Error()
+}
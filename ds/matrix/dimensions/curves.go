@@ -0,0 +1,85 @@
+package dimensions
+
+import (
+    "errors"
+    "math/bits"
+
+    "github.com/tawesoft/golib/v2/math/integer"
+)
+
+var errLimitCurve = errors.New("space-filling curve too large to index")
+
+// Morton returns a [Map] from a shape with the given lengths onto a
+// 1-dimensional shape, such that each element is laid out in Morton order
+// (also known as Z-order). See [integer.InterleaveBits].
+//
+// This is useful for laying out data that is accessed with spatial locality
+// in a cache-coherent manner. For example:
+//
+//     m := dimensions.Morton(256, 256)
+//     storage := matrix.NewGrid[float64](m.Original().Size())
+//     grid := matrix.NewView(storage, m)
+//
+// The original shape is large enough to hold every element, and its size is
+// rounded up to a power of two along each axis. As such, some elements of the
+// original shape may never be mapped to. Lengths must be positive, and the
+// total number of bits needed to index the original shape must not exceed 62,
+// or this function panics.
+func Morton(lengths ... int) Map {
+    new := New(lengths...)
+    n := len(lengths)
+
+    var maxLength int
+    for _, length := range lengths {
+        if length > maxLength { maxLength = length }
+    }
+    width := bits.Len(uint(maxLength - 1))
+    if width * n > 62 { panic(errLimitCurve) }
+
+    original := New(1 << (width * n))
+
+    return mapping{
+        D:        new,
+        original: original,
+        offsets:  func(dest []int, source ... int) {
+            var buf [64]uint64 // at most 64 dimensions
+            coordinates := buf[0:n]
+            for i := 0; i < n; i++ {
+                if i < len(source) {
+                    coordinates[i] = uint64(source[i] % lengths[i])
+                } else {
+                    coordinates[i] = 0
+                }
+            }
+            dest[0] = int(integer.InterleaveBits(coordinates...))
+        },
+    }
+}
+
+// Hilbert returns a [Map] from a 2-dimensional shape with the given width and
+// height onto a 1-dimensional shape, such that each element is laid out along
+// a Hilbert curve. See [integer.HilbertIndex].
+//
+// The Hilbert curve preserves spatial locality better than [Morton] order, but
+// is more expensive to compute.
+//
+// The original shape is large enough to hold every element, and is a square
+// with sides rounded up to a power of two. As such, some elements of the
+// original shape may never be mapped to. Lengths must be positive.
+func Hilbert(width, height int) Map {
+    new := New(width, height)
+    order := bits.Len(uint(max(width, height) - 1))
+    if order * 2 > 62 { panic(errLimitCurve) }
+    original := New(1 << (order * 2))
+
+    return mapping{
+        D:        new,
+        original: original,
+        offsets:  func(dest []int, source ... int) {
+            var x, y int
+            if len(source) > 0 { x = source[0] % width }
+            if len(source) > 1 { y = source[1] % height }
+            dest[0] = int(integer.HilbertIndex(order, uint32(x), uint32(y)))
+        },
+    }
+}
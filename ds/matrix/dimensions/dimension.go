@@ -31,6 +31,8 @@ func ascii(x int) int {
 //
 // It implements a bidirectional mapping from a single integer index to a
 // slice of offsets along each axis. The mapping assumes row-major order.
+// For storage in other orders, such as column-major order, see
+// [NewWithStrides].
 //
 // In general, D is treated as immutable and may be copied, but, for the sake
 // of robustness, copies should be made using the Dimensions method.
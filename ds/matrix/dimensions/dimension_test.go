@@ -7,6 +7,7 @@ import (
     "testing"
 
     "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+    "github.com/tawesoft/golib/v2/iter"
     "github.com/tawesoft/golib/v2/must"
 )
 
@@ -374,10 +375,65 @@ func TestMapping(t *testing.T) {
             func(in dimensions.D) dimensions.Map {
                 return dimensions.Sampler("xxy").Bind(in)
             },
+            []int{2, 2, 3},
+            []int{
+             // X, Y, Z, X',Y',Z'
+                0, 0, 0, 0, 0, 0,
+                1, 0, 2, 1, 2, 0,
+                0, 1, 2, 0, 2, 0, // repeats
+            },
+            nil,
+        },
+        {
+            "reorder/duplicate-in-group",
+            []int{2, 3, 4}, // 3D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Sampler("(xx)").Bind(in)
+            },
             []int{},
             []int{},
             dimensions.SamplerSyntaxError{},
         },
+        {
+            "reorder/constant-and-referenced",
+            []int{2, 3, 4}, // 3D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Sampler("x !x", 1).Bind(in)
+            },
+            []int{},
+            []int{},
+            dimensions.SamplerSyntaxError{},
+        },
+        {
+            "reorder/diagonal",
+            []int{3, 4}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Sampler("(xy)").Bind(in)
+            },
+            []int{3},
+            []int{
+             // X, X',Y'
+                0, 0, 0,
+                2, 2, 2,
+                3, 0, 0, // wraps
+            },
+            nil,
+        },
+        {
+            "reorder/insert",
+            []int{3}, // 1D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Sampler("+2 -x").Bind(in)
+            },
+            []int{2, 3},
+            []int{
+             // X, Y, X'
+                0, 0, 2,
+                1, 0, 2,
+                1, 2, 0,
+            },
+            nil,
+        },
         {
             "reorder/identity",
             []int{2, 3, 4}, // 3D
@@ -474,6 +530,143 @@ func TestMapping(t *testing.T) {
             },
             nil,
         },
+        {
+            "compose/crop-of-sample",
+            []int{3, 4}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                inner := dimensions.Sampler("-x y").Bind(in)
+                outer := dimensions.Crop(inner, inner.Index(1, 1), 2, 2)
+                return dimensions.Compose(outer, inner)
+            },
+            []int{2, 2},
+            []int{
+             // X, Y, X',Y'
+                0, 0, 1, 1,
+                1, 0, 0, 1,
+                1, 1, 0, 2,
+            },
+            nil,
+        },
+        {
+            "compose/flattened",
+            []int{3, 4}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                inner := dimensions.Sampler("-x y").Bind(in)
+                outer := dimensions.Crop(inner, inner.Index(1, 1), 2, 2)
+                c := dimensions.Compose(outer, inner)
+                return dimensions.Compose(dimensions.Sampler("yx").Bind(c), c)
+            },
+            []int{2, 2},
+            []int{
+             // X, Y, X',Y'
+                0, 0, 1, 1,
+                1, 0, 1, 2,
+                0, 1, 0, 1,
+            },
+            nil,
+        },
+        {
+            "strides/column-major",
+            []int{6}, // 1D storage
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.ColumnMajor(3, 2)
+            },
+            []int{3, 2},
+            []int{
+             // X, Y, X'
+                0, 0, 0,
+                0, 1, 1,
+                1, 0, 2,
+                2, 1, 5,
+                3, 2, 0, // wraps
+            },
+            nil,
+        },
+        {
+            "strides/interleaved",
+            []int{34}, // 1D storage
+            func(in dimensions.D) dimensions.Map {
+                // every third element, in rows of 4
+                return dimensions.NewWithStrides([]int{4, 3}, []int{3, 12})
+            },
+            []int{4, 3},
+            []int{
+             // X, Y, X'
+                0, 0, 0,
+                1, 0, 3,
+                0, 1, 12,
+                3, 2, 33,
+            },
+            nil,
+        },
+        {
+            "slice/every-other-row",
+            []int{5, 6}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Slice(in, nil, nil, []int{1, 2})
+            },
+            []int{5, 3},
+            []int{
+             // X, Y, X',Y'
+                0, 0, 0, 0,
+                2, 1, 2, 2,
+                4, 2, 4, 4,
+            },
+            nil,
+        },
+        {
+            "slice/reverse",
+            []int{5, 6}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Slice(in,
+                    []int{dimensions.Omitted, -2},
+                    []int{dimensions.Omitted,  0},
+                    []int{-2, -1},
+                )
+            },
+            []int{3, 4},
+            []int{
+             // X, Y, X',Y'
+                0, 0, 4, 4,
+                1, 1, 2, 3,
+                2, 3, 0, 1,
+            },
+            nil,
+        },
+        {
+            "slice/clamp",
+            []int{5, 6}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Slice(in, []int{-10, 1}, []int{10, 100}, []int{3})
+            },
+            []int{2, 5},
+            []int{
+             // X, Y, X',Y'
+                0, 0, 0, 1,
+                1, 4, 3, 5,
+            },
+            nil,
+        },
+        {
+            "slice/empty",
+            []int{5, 6}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Slice(in, []int{2}, []int{2}, nil)
+            },
+            nil,
+            nil,
+            dimensions.ErrSlice,
+        },
+        {
+            "slice/zero-step",
+            []int{5, 6}, // 2D
+            func(in dimensions.D) dimensions.Map {
+                return dimensions.Slice(in, nil, nil, []int{1, 0})
+            },
+            nil,
+            nil,
+            dimensions.ErrSlice,
+        },
     }
 
     for _, tt := range tests {
@@ -523,3 +716,236 @@ func TestMapping(t *testing.T) {
         })
     }
 }
+
+func TestCurves(t *testing.T) {
+    tests := []struct {
+        name string
+        mapping dimensions.Map
+        originalSize int
+        // values is a sequence of offsets on the new shape, followed by the
+        // expected index into the original shape.
+        values []int
+    }{
+        {
+            "morton/2d",
+            dimensions.Morton(4, 3),
+            16,
+            []int{
+             // X, Y, IDX
+                0, 0, 0,
+                1, 0, 1,
+                0, 1, 2,
+                1, 1, 3,
+                2, 0, 4,
+                3, 2, 13,
+                4, 0, 0, // wraps
+            },
+        },
+        {
+            "hilbert/2d",
+            dimensions.Hilbert(2, 2),
+            4,
+            []int{
+             // X, Y, IDX
+                0, 0, 0,
+                0, 1, 1,
+                1, 1, 2,
+                1, 0, 3,
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if tt.mapping.Original().Size() != tt.originalSize {
+                t.Errorf("original size %d, want %d", tt.mapping.Original().Size(), tt.originalSize)
+            }
+
+            stride := tt.mapping.Dimensionality() + 1
+            for i := 0; i < len(tt.values) / stride; i++ {
+                row := tt.values[i * stride : (i + 1) * stride]
+                offsets, expected := row[0:stride - 1], row[stride - 1]
+                var dest [1]int
+                tt.mapping.MapOffsets(dest[:], offsets...)
+                if dest[0] != expected {
+                    t.Errorf("MapOffsets(%v): got %d, want %d", offsets, dest[0], expected)
+                }
+            }
+
+            // every element maps to a unique index
+            seen := make(map[int]bool)
+            for i := 0; i < tt.mapping.Size(); i++ {
+                seen[tt.mapping.MapIndex(i)] = true
+            }
+            if len(seen) != tt.mapping.Size() {
+                t.Errorf("got %d unique indexes, want %d", len(seen), tt.mapping.Size())
+            }
+        })
+    }
+}
+
+func TestNamed(t *testing.T) {
+    n := dimensions.Named(dimensions.New(4, 3, 2), "col", "row", "batch")
+
+    if n.LengthByName("row") != 3 {
+        t.Errorf("LengthByName(row): got %d, want 3", n.LengthByName("row"))
+    }
+    if n.Axis("batch") != 2 {
+        t.Errorf("Axis(batch): got %d, want 2", n.Axis("batch"))
+    }
+
+    idx := n.IndexByName(map[string]int{"batch": 1, "col": 2})
+    if idx != n.Index(2, 0, 1) {
+        t.Errorf("IndexByName: got %d, want %d", idx, n.Index(2, 0, 1))
+    }
+    offsets := n.OffsetsByName(idx)
+    if (offsets["col"] != 2) || (offsets["row"] != 0) || (offsets["batch"] != 1) {
+        t.Errorf("OffsetsByName: got %v", offsets)
+    }
+
+    m := n.Sampler("row -col !batch", 1).Bind(n)
+    if (m.Length(0) != 3) || (m.Length(1) != 4) {
+        t.Errorf("Sampler: got lengths (%d, %d), want (3, 4)", m.Length(0), m.Length(1))
+    }
+    dest := make([]int, 3)
+    m.MapOffsets(dest, 2, 0)
+    if !slices.Equal(dest, []int{3, 2, 1}) {
+        t.Errorf("Sampler: got offsets %v, want [3 2 1]", dest)
+    }
+
+    _, err := must.Try(func() dimensions.Mapper {
+        return n.Sampler("row depth")
+    })()
+    if !errors.Is(err, dimensions.SamplerSyntaxError{}) {
+        t.Errorf("Sampler: expected SamplerSyntaxError, got %v", err)
+    }
+
+    _, err = must.Try(func() int {
+        return n.LengthByName("depth")
+    })()
+    if !errors.Is(err, dimensions.ErrAxisName) {
+        t.Errorf("LengthByName: expected ErrAxisName, got %v", err)
+    }
+
+    for _, names := range [][]string{
+        {"col", "col"},
+        {"col", ""},
+        {"col", "a row"},
+        {"col", "-row"},
+        {"col", "!row"},
+        {"col", "+row"},
+    } {
+        _, err = must.Try(func() dimensions.NamedD {
+            return dimensions.Named(dimensions.New(4, 3), names...)
+        })()
+        if !errors.Is(err, dimensions.ErrAxisName) {
+            t.Errorf("Named(%q): expected ErrAxisName, got %v", names, err)
+        }
+    }
+}
+
+func TestBroadcast(t *testing.T) {
+    a := dimensions.New(3, 1)
+    b := dimensions.New(1, 4, 2)
+
+    d, ma, mb, err := dimensions.Broadcast(a, b)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    var lengths [3]int
+    d.Lengths(lengths[:])
+    if lengths != [3]int{3, 4, 2} {
+        t.Errorf("got lengths %v, want [3 4 2]", lengths)
+    }
+
+    var offsetsA [2]int
+    var offsetsB [3]int
+    ma.MapOffsets(offsetsA[:], 2, 3, 1)
+    mb.MapOffsets(offsetsB[:], 2, 3, 1)
+    if offsetsA != [2]int{2, 0} {
+        t.Errorf("got offsets %v on a, want [2 0]", offsetsA)
+    }
+    if offsetsB != [3]int{0, 3, 1} {
+        t.Errorf("got offsets %v on b, want [0 3 1]", offsetsB)
+    }
+
+    _, _, _, err = dimensions.Broadcast(dimensions.New(3, 2), dimensions.New(3, 4))
+    if !errors.Is(err, dimensions.ErrBroadcast) {
+        t.Errorf("expected ErrBroadcast, got %v", err)
+    }
+}
+
+func TestStrict(t *testing.T) {
+    d := dimensions.Strict(dimensions.New(4, 3))
+
+    if d.Index(3, 2) != 11 {
+        t.Errorf("Index(3, 2): got %d, want 11", d.Index(3, 2))
+    }
+    if d.Index(3, 2, 0) != 11 {
+        t.Errorf("Index(3, 2, 0): got %d, want 11", d.Index(3, 2, 0))
+    }
+
+    tests := []struct {
+        name string
+        f func()
+        expected dimensions.RangeError
+    }{
+        {"offset too large", func() { d.Index(4, 0) },    dimensions.RangeError{Axis: 0, Value: 4, Length: 4}},
+        {"offset negative",  func() { d.Index(0, -1) },   dimensions.RangeError{Axis: 1, Value: -1, Length: 3}},
+        {"trailing offset",  func() { d.Index(0, 0, 1) }, dimensions.RangeError{Axis: 2, Value: 1, Length: 1}},
+        {"index too large",  func() { d.Offsets(make([]int, 2), 12) }, dimensions.RangeError{Axis: -1, Value: 12, Length: 12}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := must.Try(func() int { tt.f(); return 0 })()
+            var rangeError dimensions.RangeError
+            if !errors.As(err, &rangeError) {
+                t.Errorf("expected RangeError, got %v", err)
+            } else if rangeError != tt.expected {
+                t.Errorf("got %+v, want %+v", rangeError, tt.expected)
+            }
+        })
+    }
+}
+
+func TestIndexes_Range(t *testing.T) {
+    d := dimensions.New(3, 2)
+
+    var got [][]int
+    it := dimensions.Indexes(d)
+    for offsets, ok := it(); ok; offsets, ok = it() {
+        if d.Index(offsets...) != len(got) {
+            t.Errorf("Indexes: offsets %v out of order", offsets)
+        }
+        got = append(got, slices.Clone(offsets))
+    }
+    if len(got) != d.Size() {
+        t.Errorf("Indexes: got %d offsets, want %d", len(got), d.Size())
+    }
+
+    it = dimensions.Range(dimensions.New(4, 3, 2), []int{1, 1}, []int{2, 2, 0})
+    got = got[:0]
+    for offsets, ok := it(); ok; offsets, ok = it() {
+        got = append(got, slices.Clone(offsets))
+    }
+    expected := [][]int{
+        {1, 1, 0}, {2, 1, 0},
+        {1, 2, 0}, {2, 2, 0},
+    }
+    if !slices.EqualFunc(got, expected, slices.Equal[[]int]) {
+        t.Errorf("Range: got %v, want %v", got, expected)
+    }
+
+    it = dimensions.Range(d, []int{2}, []int{1})
+    if _, ok := it(); ok {
+        t.Errorf("Range: expected empty region")
+    }
+
+    _, err := must.Try(func() iter.It[[]int] {
+        return dimensions.Range(d, nil, []int{3})
+    })()
+    if !errors.Is(err, dimensions.RangeError{}) {
+        t.Errorf("Range: expected RangeError, got %v", err)
+    }
+}
@@ -0,0 +1,67 @@
+package dimensions
+
+import (
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// Indexes returns an iterator that produces the offsets along each axis of
+// every element of d, in row-major order (i.e. in increasing order of
+// index).
+//
+// For performance, the same slice is produced each time, overwritten with
+// the next offsets. The caller must copy it to keep it past the next call to
+// the iterator.
+func Indexes(d D) iter.It[[]int] {
+    return Range(d, nil, nil)
+}
+
+// Range is like [Indexes], but produces only the offsets of elements in the
+// region of d from the offsets first to the offsets last, inclusive, in
+// row-major order.
+//
+// If first or last is shorter than the dimensionality of d, including if it
+// is nil, then the missing offsets default to the start or end of each
+// axis, respectively. If first is greater than last along any axis, the
+// region is empty. Panics with a [RangeError] if any offset is out of
+// range.
+func Range(d D, first, last []int) iter.It[[]int] {
+    dims := d.Dimensionality()
+    lower, upper := make([]int, dims), make([]int, dims)
+    empty := false
+
+    for i := 0; i < dims; i++ {
+        length := d.Length(i)
+        upper[i] = length - 1
+        if i < len(first) { lower[i] = first[i] }
+        if i < len(last)  { upper[i] = last[i] }
+
+        for _, offset := range [2]int{lower[i], upper[i]} {
+            if (offset < 0) || (offset >= length) {
+                panic(RangeError{Axis: i, Value: offset, Length: length})
+            }
+        }
+        if lower[i] > upper[i] { empty = true }
+    }
+
+    current := append([]int{}, lower...)
+    result := make([]int, dims)
+    done := empty
+
+    return func() ([]int, bool) {
+        if done { return nil, false }
+        copy(result, current)
+
+        // advance, starting with the first axis
+        done = true
+        for i := 0; i < dims; i++ {
+            if current[i] < upper[i] {
+                current[i]++
+                done = false
+                break
+            }
+            current[i] = lower[i]
+        }
+
+        return result, true
+    }
+}
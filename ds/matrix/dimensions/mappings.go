@@ -3,6 +3,7 @@ package dimensions
 import (
     "errors"
     "fmt"
+    "math"
 )
 
 // Map describes a new set of dimensions formed by applying a mapping operation
@@ -71,6 +72,93 @@ type Mapper struct {
         }
     }
 
+var errCompose = errors.New("Compose with incompatible shapes")
+
+// composed is a [Map] formed by a chain of mappings, where the original shape
+// of each mapping is the new shape of the next. Offsets are translated
+// through the whole chain at once, without converting to and from an index
+// at each step.
+type composed struct {
+    D
+    maps []Map
+    width int // the greatest dimensionality of any shape in the chain
+}
+    func (c composed) Original() D {
+        return c.maps[len(c.maps) - 1].Original()
+    }
+    func (c composed) MapOffsets(dest []int, source ... int) {
+        c.chain(make([]int, 2 * c.width), dest, source)
+    }
+    func (c composed) MapIndex(idx int) int {
+        originalDim := c.Original().Dimensionality()
+        newDim      := c.D.Dimensionality()
+
+        // share a temporary array for every set of offsets
+        buf := make([]int, newDim + originalDim + (2 * c.width))
+        source, dest, buf := buf[0:newDim], buf[newDim:newDim + originalDim], buf[newDim + originalDim:]
+
+        c.D.Offsets(source, idx)
+        c.chain(buf, dest, source)
+        return c.Original().Index(dest...)
+    }
+
+    // chain maps offsets through each mapping in turn, using buf, which must
+    // have a length of 2 * c.width, for the intermediate offsets.
+    func (c composed) chain(buf []int, dest []int, source []int) {
+        in, out := buf[0:c.width], buf[c.width:]
+        n := c.maps[0].Dimensionality()
+        copy(in[0:n], source) // missing offsets are zero
+        for i, m := range c.maps {
+            if i == len(c.maps) - 1 {
+                m.MapOffsets(dest, in[0:n]...)
+                return
+            }
+            next := m.Original().Dimensionality()
+            m.MapOffsets(out[0:next], in[0:n]...)
+            in, out, n = out, in, next
+        }
+    }
+
+// Compose returns a [Map] that applies the mapping outer to the new shape of
+// the mapping inner. For example, if inner is a sampled view of a shape, and
+// outer is a crop of that view, then the composed Map is the crop of the
+// sampled view. The new shape is the new shape of outer, and the original
+// shape is the original shape of inner.
+//
+// Where the result of Compose is used as an argument to Compose again, the
+// chain of mappings is flattened, so that offsets are translated through
+// every mapping in one step. This is faster than nesting views of views.
+//
+// The original shape of outer must have the same lengths as the new shape of
+// inner, or this function panics.
+func Compose(outer, inner Map) Map {
+    a, b := outer.Original(), inner.Dimensions()
+    if a.Dimensionality() != b.Dimensionality() { panic(errCompose) }
+    for i := 0; i < a.Dimensionality(); i++ {
+        if a.Length(i) != b.Length(i) { panic(errCompose) }
+    }
+
+    var maps []Map
+    for _, m := range []Map{outer, inner} {
+        if c, ok := m.(composed); ok {
+            maps = append(maps, c.maps...)
+        } else {
+            maps = append(maps, m)
+        }
+    }
+
+    width := 0
+    for _, m := range maps {
+        width = max(width, m.Dimensionality(), m.Original().Dimensionality())
+    }
+
+    return composed{
+        D:     outer.Dimensions(),
+        maps:  maps,
+        width: width,
+    }
+}
+
 // Crop returns a [Map] of a sub-region of the target shape.
 //
 // It is specified by identifying the index of a start point on the target,
@@ -112,11 +200,187 @@ func Crop(target D, startIdx int, lengths ... int) Map {
     }.Bind(target)
 }
 
-// Sampler returns a new Mapper that can flip, drop, or reorder dimensions
-// of shapes arbitrarily.
+// Tile returns a new Mapper that repeats a shape periodically along each
+// axis, to fill a new shape with the given lengths. An offset along axis i
+// of the new shape maps to that offset, modulo the length along axis i, of
+// the original shape.
+//
+// For example, Tile(6, 4).Bind(d2), for a 2D shape d2 with lengths (3, 2),
+// returns a [Map] where d2 is repeated twice along each axis. As lengths may
+// be less than the original lengths, this can also be used to crop.
+//
+// Any dimensions of the original shape without a corresponding length are
+// mapped to offset zero.
+func Tile(lengths ... int) Mapper {
+    lengths = append([]int{}, lengths...) // don't share memory
+    return Mapper{
+        Shapes: func(original D) D {
+            return New(lengths...)
+        },
+        Offsets: func(original, new D) func(dest []int, source ... int) {
+            return func(dest []int, source ... int) {
+                for i := 0; i < original.Dimensionality(); i++ {
+                    if i >= len(dest) { break }
+                    if i >= len(source) {
+                        dest[i] = 0
+                        continue
+                    }
+                    dest[i] = source[i] % original.Length(i)
+                }
+            }
+        },
+    }
+}
+
+// ErrBroadcast is the error returned by [Broadcast] when two shapes are not
+// compatible.
+var ErrBroadcast = errors.New("shapes cannot be broadcast together")
+
+// Broadcast returns a shape that is compatible with both a and b, and a [Map]
+// from that shape back to each of a and b, following the broadcasting rules
+// of NumPy. This allows an element-wise operation on two shapes that are
+// not the same, but are compatible.
+//
+// Starting with axis 0, the lengths of a and b along each axis must either
+// be the same, or one of them must be 1, in which case that length is
+// stretched to match the other. Every offset along a stretched axis maps to
+// offset zero. If one shape has fewer dimensions than the other, it is
+// treated as having a length of 1 along each missing axis.
+//
+// For example, a shape with lengths (3, 1) and a shape with lengths (1, 4, 2)
+// broadcast to a shape with lengths (3, 4, 2), and a shape with lengths (3)
+// broadcasts with either of them.
+//
+// Note that, as axis 0 is the axis where consecutive elements are adjacent,
+// axes here are aligned in the same way as NumPy aligns trailing axes.
+//
+// Returns an error wrapping [ErrBroadcast] if the shapes are not compatible.
+func Broadcast(a, b D) (D, Map, Map, error) {
+    dims := max(a.Dimensionality(), b.Dimensionality())
+    lengths := make([]int, dims)
+    for i := 0; i < dims; i++ {
+        la, lb := max(1, a.Length(i)), max(1, b.Length(i))
+        switch {
+            case la == lb: lengths[i] = la
+            case la == 1:  lengths[i] = lb
+            case lb == 1:  lengths[i] = la
+            default:
+                return nil, nil, nil, fmt.Errorf("%w: axis %d has lengths %d and %d", ErrBroadcast, i, la, lb)
+        }
+    }
+
+    d := New(lengths...)
+    mapper := Mapper{
+        Shapes: func(original D) D {
+            return d
+        },
+        Offsets: func(original, new D) func(dest []int, source ... int) {
+            return func(dest []int, source ... int) {
+                for i := 0; i < original.Dimensionality(); i++ {
+                    if i >= len(dest) { break }
+                    if (i >= len(source)) || (original.Length(i) == 1) {
+                        dest[i] = 0
+                        continue
+                    }
+                    dest[i] = source[i] % original.Length(i)
+                }
+            }
+        },
+    }
+    return d, mapper.Bind(a), mapper.Bind(b), nil
+}
+
+// Omitted may be given as a start, stop, or step value to [Slice] to use the
+// default for that axis, like an omitted value in a Python slice.
+const Omitted = math.MinInt
+
+// ErrSlice is the error raised by [Slice] for a step of zero, or for a slice
+// that would be empty along any axis.
+var ErrSlice = errors.New("invalid dimensions slice")
+
+// sliceIndices returns the first offset, and the number of offsets, along an
+// axis of length n selected by a slice with the given start, stop, and step.
+// Like Python's slice.indices, a negative start or stop counts back from the
+// end, and values out of range are clamped.
+func sliceIndices(n, start, stop, step int) (first int, count int) {
+    clamp := func(x, lower, upper int) int {
+        if x < 0 { x += n }
+        return max(lower, min(x, upper))
+    }
+
+    if step > 0 {
+        if start == Omitted { start = 0 } else { start = clamp(start, 0, n) }
+        if stop  == Omitted { stop  = n } else { stop  = clamp(stop,  0, n) }
+        if stop > start { count = (stop - start + step - 1) / step }
+    } else {
+        if start == Omitted { start = n - 1 } else { start = clamp(start, -1, n - 1) }
+        if stop  == Omitted { stop  = -1    } else { stop  = clamp(stop,  -1, n - 1) }
+        if start > stop { count = (start - stop - step - 1) / -step }
+    }
+    return start, count
+}
+
+// Slice returns a [Map] of every step-th offset along each axis of the
+// target shape, from a start offset up to, but not including, a stop
+// offset. This is similar to basic slicing in NumPy, for example
+// Slice(target, nil, nil, []int{1, 2}) is like target[::2, :] (rows are the
+// second axis), and selects every other row.
+//
+// A negative start or stop counts back from the end of an axis, so that -1
+// is the last offset. A negative step reads the axis in reverse, from start
+// down to, but not including, stop. As in Python, a start or stop outside
+// the axis is clamped to the axis.
+//
+// Each slice argument has a value for each axis. A value of [Omitted], or a
+// missing value where a slice is shorter than the dimensionality of target
+// (including a nil slice), means the default for that axis: the start or end
+// of the axis, depending on the direction of step, or a step of one.
+//
+// Panics with [ErrSlice] if any step is zero, or if the slice would select
+// no offsets along any axis.
+func Slice(target D, start, stop, step []int) Map {
+    dims := target.Dimensionality()
+    get := func(xs []int, i int) int {
+        if i < len(xs) { return xs[i] }
+        return Omitted
+    }
+
+    firsts := make([]int, dims)
+    steps  := make([]int, dims)
+    lengths := make([]int, dims)
+    for i := 0; i < dims; i++ {
+        steps[i] = get(step, i)
+        if steps[i] == Omitted { steps[i] = 1 }
+        if steps[i] == 0 { panic(ErrSlice) }
+
+        firsts[i], lengths[i] = sliceIndices(target.Length(i), get(start, i), get(stop, i), steps[i])
+        if lengths[i] == 0 { panic(ErrSlice) }
+    }
+
+    return Mapper{
+        Shapes: func(original D) D {
+            return New(lengths...)
+        },
+        Offsets: func(original, new D) func(dest []int, source ... int) {
+            return func(dest []int, source ... int) {
+                for i := 0; i < original.Dimensionality(); i++ {
+                    if i >= len(dest) { break }
+                    offset := 0
+                    if i < len(source) { offset = source[i] % new.Length(i) }
+                    dest[i] = firsts[i] + (offset * steps[i])
+                }
+            }
+        },
+    }.Bind(target)
+}
+
+// Sampler returns a new Mapper that can flip, drop, reorder, repeat, or
+// insert dimensions of shapes arbitrarily.
 //
 // The string argument encodes operations on each dimension and an ordering
 // by the presence, or lack thereof, of a sequence of indexes and modifiers.
+// Each index, or group of indexes, produces a dimension of the new shape,
+// in order.
 //
 // The presence of the characters '0'-'9' and 'A'-'F' identify dimensions 0 to
 // 15 on the parent. As syntax sugar, the characters in the string "xyzw" are
@@ -133,102 +397,154 @@ func Crop(target D, startIdx int, lengths ... int) Map {
 // the next element in the optional "constants" argument, which encodes a
 // constant integer offset into that dimension.
 //
+// A dimension may be referenced more than once, producing a new dimension
+// each time. Where the offsets along these new dimensions differ, the first
+// reference decides the offset in the parent, and the others are repeats
+// that do not change the element, like a stretched dimension in [Broadcast].
+//
+// A group of dimensions in parentheses, for example "(xy)", produces a single
+// new dimension that steps along every dimension in the group at once, with
+// a length of the shortest of them. For example, this can extract the
+// diagonal of a square. A group may be preceded by a negative sign, but not
+// an exclamation mark.
+//
+// A plus sign followed by a decimal number, for example "+8", inserts a new
+// dimension of that length that does not map to any dimension of the parent,
+// so that every offset along it refers to the same elements.
+//
 // ASCII whitespace is ignored. The syntax does not support more than 16
-// dimensions. A dimension may not be referenced twice. May panic with
-// [SwizzleSyntaxError].
+// dimensions on the parent. A dimension may not be both referenced and
+// mapped to a constant, or referenced twice in the same group. May panic
+// with [SamplerSyntaxError].
 //
-// For example, given a 2D matrix d2, Reorder(matrix, "yx").Map(d2) returns a
+// For example, given a 2D matrix d2, Sampler("yx").Bind(d2) returns a
 // [Map] that rotates the x & y dimensions in d2, turning it from row major
-// order into column major order. Similarly, Reorder(matrix, "-x -y").Map(d2)
-// returns a Map that mirrors the matrix along x and y axes. For some 3D matrix
-// d3, Reorder(matrix, "xy !z", 4).Map(d3) returns a Map that models a 2D slice
-// of d3 along the axis z=4.
+// order into column major order. Similarly, Sampler("-x -y").Bind(d2)
+// returns a Map that mirrors the matrix along x and y axes, and
+// Sampler("(xy)").Bind(d2) returns a Map of its leading diagonal. For some 3D
+// matrix d3, Sampler("xy !z", 4).Bind(d3) returns a Map that models a 2D
+// slice of d3 along the axis z=4. For some 1D matrix d1, Sampler("x +3")
+// returns a Map that repeats d1 as three identical rows.
 func Sampler(reorder string, constants ... int) Mapper {
+    type output struct {
+        axes     []int // parent dimensions, or none for an inserted dimension
+        length   int   // length of an inserted dimension
+        mirror   bool
+        constant bool
+    }
 
-    // note: this implementation is pretty horrible, but tests confirm it works
-
-    // output[0:5]: parent dimension index 0b00001111
-    // output[5]:   unused
-    // output[6]:   mirror ?
-    // output[7]:   constant ?
-    const mask_index  = 0b00001111
-    const mask_mirror = 0b01000000
-    const mask_const  = 0b10000000
-
-    var outputs [16]uint8
-    var seen uint16
-    const nothing = uint8(255)
-    for i := 0; i < 16; i++ { outputs[i] = nothing }
-    precede := nothing
-    currentOutput := 0
+    var outputs []output
+    var referenced, constant uint16
     currentConstant := 0
     newDims := 0
 
+    fail := func(offset int, reason string) {
+        panic(SamplerSyntaxError{
+            Offset:     offset,
+            Input:      reorder,
+            Reason:     reason,
+        })
+    }
+
+    // axis returns the parent dimension identified by c, or -1
+    axis := func(c uint8) int {
+        switch {
+            case (c >= '0') && (c <= '9'): return int(c - '0')
+            case (c >= 'a') && (c <= 'f'): return int(c - 'a') + 10
+            case (c >= 'A') && (c <= 'F'): return int(c - 'A') + 10
+        }
+        switch c {
+            case 'x', 'X': return 0
+            case 'y', 'Y': return 1
+            case 'z', 'Z': return 2
+            case 'w', 'W': return 3
+        }
+        return -1
+    }
+
     // first parse the encoded instructions into outputs
+    var precede uint8
     for i := 0; i < len(reorder); i++ {
-        idx := nothing
         c := reorder[i]
+        switch {
+            case (c == '\t') || (c == '\n') || (c == ' '):
+                continue
 
-        if c == '\t' || c == '\n' || c == ' ' {
-            continue
-        } else  if ((c == '-') || (c == '!')) && precede == nothing {
-            precede = c
-        } else if c >= '0' && c <= '9' {
-            idx = c - '0'
-        } else if c >= 'a' && c <= 'f' {
-            idx = c - 'a'
-        } else if c >= 'A' && c <= 'F' {
-            idx = c - 'A'
-        } else {
-            switch c {
-                case 'x': idx = 0
-                case 'y': idx = 1
-                case 'z': idx = 2
-                case 'w': idx = 3
-                case 'X': idx = 0
-                case 'Y': idx = 1
-                case 'Z': idx = 2
-                case 'W': idx = 3
-                default:
-                    panic(SamplerSyntaxError{
-                        Offset:     i,
-                        Input:      reorder,
-                        Unexpected: c,
-                    })
-            }
+            case ((c == '-') || (c == '!')) && (precede == 0):
+                precede = c
+                continue
+
+            case (c == '+') && (precede == 0):
+                start := i
+                length := 0
+                for (i + 1 < len(reorder)) && (reorder[i + 1] >= '0') && (reorder[i + 1] <= '9') {
+                    i++
+                    if length > (1 << 30) { fail(start, "inserted dimension too long") }
+                    length = (length * 10) + int(reorder[i] - '0')
+                }
+                if length == 0 { fail(start, "inserted dimension must have a positive length") }
+                outputs = append(outputs, output{length: length})
+                newDims++
+                continue
+
+            case (c == '(') && (precede != '!'):
+                start := i
+                var group uint16
+                o := output{mirror: precede == '-'}
+                for i++; (i < len(reorder)) && (reorder[i] != ')'); i++ {
+                    if (reorder[i] == ' ') || (reorder[i] == '\t') || (reorder[i] == '\n') { continue }
+                    idx := axis(reorder[i])
+                    if idx < 0 {
+                        panic(SamplerSyntaxError{
+                            Offset:     i,
+                            Input:      reorder,
+                            Unexpected: reorder[i],
+                        })
+                    }
+                    if group & (1 << idx) != 0 { fail(i, "dimension referenced twice in a group") }
+                    if constant & (1 << idx) != 0 { fail(i, "dimension both constant and referenced") }
+                    group |= 1 << idx
+                    o.axes = append(o.axes, idx)
+                }
+                if i >= len(reorder) { fail(start, "unclosed group") }
+                if len(o.axes) == 0 { fail(start, "empty group") }
+                referenced |= group
+                outputs = append(outputs, o)
+                newDims++
+                precede = 0
+                continue
         }
-        if idx == nothing { continue }
-        if seen & (1 << idx) == (1 << idx) {
+
+        idx := axis(c)
+        if idx < 0 {
             panic(SamplerSyntaxError{
                 Offset:     i,
                 Input:      reorder,
-                Reason:     "dimension referenced twice",
+                Unexpected: c,
             })
         }
-        if (precede == '!') && (currentConstant >= len(constants)) {
-            panic(SamplerSyntaxError{
-                Offset:     i,
-                Input:      reorder,
-                Reason:     "constant index out of range",
-            })
+
+        if precede == '!' {
+            if (referenced | constant) & (1 << idx) != 0 { fail(i, "dimension both constant and referenced") }
+            if currentConstant >= len(constants) { fail(i, "constant index out of range") }
+            constant |= 1 << idx
+            currentConstant++
+        } else {
+            if constant & (1 << idx) != 0 { fail(i, "dimension both constant and referenced") }
+            referenced |= 1 << idx
+            newDims++
         }
-        seen |= (1 << idx)
-
-        v := idx
-        if precede == '-' { v |= mask_mirror }
-        if precede == '!' { v |= mask_const; currentConstant++ }
-        if precede != '!' { newDims++ }
-        precede = nothing
-        outputs[currentOutput] = v
-        currentOutput++
+
+        outputs = append(outputs, output{
+            axes:     []int{idx},
+            mirror:   precede == '-',
+            constant: precede == '!',
+        })
+        precede = 0
     }
 
     if newDims == 0 {
-        panic(SamplerSyntaxError{
-            Offset:     0,
-            Input:      reorder,
-            Reason:     "must have at least one non-constant output",
-        })
+        fail(0, "must have at least one non-constant output")
     }
 
     // now implement a mapper that applies outputs
@@ -236,41 +552,43 @@ func Sampler(reorder string, constants ... int) Mapper {
 
     return Mapper{
         Shapes: func(original D) D {
-            lengths := make([]int, newDims)
-            for i, j := 0, 0; i < currentOutput; i++ {
-                if outputs[i] & mask_const == mask_const { continue }
-                lengths[j] = original.Length(int(outputs[i] & mask_index))
-                j++
+            lengths := make([]int, 0, newDims)
+            for _, o := range outputs {
+                if o.constant { continue }
+                length := o.length
+                for i, idx := range o.axes {
+                    if (i == 0) || (original.Length(idx) < length) { length = original.Length(idx) }
+                }
+                lengths = append(lengths, length)
             }
             return New(lengths...)
         },
         Offsets: func(original, new D) func(dest []int, source ... int) {
             return func(dest []int, source ... int) {
+                var set uint16 // dimensions decided by an earlier reference
                 for i := 0; i < len(dest); i++ {
                     dest[i] = 0
                 }
-                for i, c, s := 0, 0, 0; i < 16; i++ {
-                    output := outputs[i]
-                    if output == nothing {
-                        break
-                    }
-                    idx := output & mask_index
-                    if output & mask_const == mask_const {
-                        dest[idx] = constants[c]
+                for i, c, s := 0, 0, 0; i < len(outputs); i++ {
+                    o := outputs[i]
+                    if o.constant {
+                        dest[o.axes[0]] = constants[c]
                         c++
                         continue
                     }
                     var offset int
                     if s < len(source) {
-                        offset = source[s] % original.Length(int(idx))
-                        s++
-                    } else {
-                        offset = 0
+                        offset = source[s] % new.Length(s)
                     }
-                    if output & mask_mirror == mask_mirror {
-                        dest[idx] = original.Length(int(idx)) - offset - 1;
-                    } else {
-                        dest[idx] = offset
+                    s++
+                    for _, idx := range o.axes {
+                        if set & (1 << idx) != 0 { continue }
+                        set |= 1 << idx
+                        if o.mirror {
+                            dest[idx] = original.Length(idx) - offset - 1
+                        } else {
+                            dest[idx] = offset
+                        }
                     }
                 }
             }
@@ -0,0 +1,163 @@
+package dimensions
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "strings"
+)
+
+// ErrAxisName is raised in a panic when an axis name is invalid, or does not
+// name an axis of a [NamedD].
+var ErrAxisName = errors.New("invalid axis name")
+
+const whitespace = " \t\r\n\v\f"
+
+// NamedD is an implementation of the [D] interface that gives a name to each
+// axis of another D. In most cases, this is initialised by calling [Named].
+//
+// Axes can still be referred to by number, but the methods on NamedD accept
+// names instead, so that, for example, n.LengthByName("row") is equivalent
+// to n.Length(1), given the names ("col", "row", "batch").
+type NamedD struct {
+    D
+    names []string
+}
+
+    // Named returns a [NamedD] that gives a name to each axis of the shape
+    // d, in order, so that names[0] is the name of axis 0, and so on.
+    //
+    // Panics with [ErrAxisName] unless there is exactly one name for each
+    // axis, and each name is unique, non-empty, contains no whitespace, and
+    // does not start with "+", "-" or "!" (see [NamedD.Sampler]).
+    func Named(d D, names ... string) NamedD {
+        if len(names) != d.Dimensionality() {
+            panic(fmt.Errorf("%w: got %d names for %d axes", ErrAxisName, len(names), d.Dimensionality()))
+        }
+        for i, name := range names {
+            switch {
+                case name == "":
+                    panic(fmt.Errorf("%w: empty name for axis %d", ErrAxisName, i))
+                case strings.ContainsAny(name, whitespace):
+                    panic(fmt.Errorf("%w: %q contains whitespace", ErrAxisName, name))
+                case strings.IndexByte("+-!", name[0]) >= 0:
+                    panic(fmt.Errorf("%w: %q starts with a modifier", ErrAxisName, name))
+                case slices.Index(names[0:i], name) >= 0:
+                    panic(fmt.Errorf("%w: %q is not unique", ErrAxisName, name))
+            }
+        }
+        return NamedD{
+            D:     d.Dimensions(),
+            names: append([]string{}, names...), // don't share memory
+        }
+    }
+
+    func (n NamedD) Dimensions() D {
+        return n // immutable, so fine
+    }
+
+    // Names returns the name of each axis, in order.
+    func (n NamedD) Names() []string {
+        return append([]string{}, n.names...)
+    }
+
+    // Axis returns the number of the axis with the given name, or panics
+    // with [ErrAxisName].
+    func (n NamedD) Axis(name string) int {
+        i := slices.Index(n.names, name)
+        if i < 0 { panic(fmt.Errorf("%w: no axis named %q", ErrAxisName, name)) }
+        return i
+    }
+
+    // LengthByName returns the length along the axis with the given name,
+    // or panics with [ErrAxisName].
+    func (n NamedD) LengthByName(name string) int {
+        return n.Length(n.Axis(name))
+    }
+
+    // IndexByName computes an index calculated from the offset along each
+    // named axis. Axes that are not named in the map have offset zero. Panics
+    // with [ErrAxisName] if a name in the map does not name an axis.
+    //
+    // Like [D.Index], an offset out of bounds is wrapped round.
+    func (n NamedD) IndexByName(offsets map[string]int) int {
+        var buf [64]int // at most 64 dimensions
+        xs := buf[0:n.Dimensionality()]
+        for name, offset := range offsets {
+            xs[n.Axis(name)] = offset
+        }
+        return n.Index(xs...)
+    }
+
+    // OffsetsByName returns the offset along each named axis identified by
+    // the given index.
+    func (n NamedD) OffsetsByName(idx int) map[string]int {
+        var buf [64]int // at most 64 dimensions
+        xs := buf[0:n.Dimensionality()]
+        n.Offsets(xs, idx)
+
+        result := make(map[string]int, len(xs))
+        for i, name := range n.names {
+            result[name] = xs[i]
+        }
+        return result
+    }
+
+    // Sampler is like the function [Sampler], but the reorder string is a
+    // sequence of axis names, separated by whitespace, instead of a sequence
+    // of axis numbers. As with Sampler, a name may be preceded by a negative
+    // sign to flip that axis, or by an exclamation mark to map that axis to
+    // the next constant, and a token such as "+8" inserts a new dimension.
+    // Groups are not supported.
+    //
+    // For example, given a 3D shape named ("col", "row", "batch"),
+    // n.Sampler("row -col !batch", 4).Bind(n) returns a [Map] that models a
+    // 2D slice of the shape at batch 4, transposed and mirrored along its
+    // columns. May panic with [SamplerSyntaxError].
+    func (n NamedD) Sampler(reorder string, constants ... int) Mapper {
+        const digits = "0123456789ABCDEF"
+        var sb strings.Builder
+
+        for offset := 0; offset < len(reorder); {
+            // skip whitespace
+            if strings.IndexByte(whitespace, reorder[offset]) >= 0 {
+                offset++
+                continue
+            }
+
+            start := offset
+            for (offset < len(reorder)) && (strings.IndexByte(whitespace, reorder[offset]) < 0) {
+                offset++
+            }
+            token := reorder[start:offset]
+
+            if token[0] == '+' { // an inserted dimension
+                sb.WriteString(token)
+                sb.WriteByte(' ')
+                continue
+            }
+            if (token[0] == '-') || (token[0] == '!') {
+                sb.WriteByte(token[0])
+                token = token[1:]
+            }
+            axis := slices.Index(n.names, token)
+            if axis < 0 {
+                panic(SamplerSyntaxError{
+                    Offset: start,
+                    Input: reorder,
+                    Reason: fmt.Sprintf("no axis named %q", token),
+                })
+            }
+            if axis >= len(digits) {
+                panic(SamplerSyntaxError{
+                    Offset: start,
+                    Input: reorder,
+                    Reason: fmt.Sprintf("axis %q is not one of the first %d axes", token, len(digits)),
+                })
+            }
+            sb.WriteByte(digits[axis])
+            sb.WriteByte(' ')
+        }
+
+        return Sampler(sb.String(), constants...)
+    }
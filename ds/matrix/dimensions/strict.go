@@ -0,0 +1,74 @@
+package dimensions
+
+import (
+    "errors"
+    "fmt"
+)
+
+// RangeError is raised in a panic by a D returned by [Strict] when an index
+// or offset is out of range.
+type RangeError struct {
+    Axis int // the axis of the offset, or -1 for an index
+    Value int // the offset or index that is out of range
+    Length int // the length along the axis, or the size for an index
+}
+
+func (e RangeError) Is(err error) bool {
+    var rangeError RangeError
+    ok := errors.As(err, &rangeError)
+    return ok
+}
+
+func (e RangeError) Error() string {
+    if e.Axis < 0 {
+        return fmt.Sprintf("index %d out of range for size %d", e.Value, e.Length)
+    } else {
+        return fmt.Sprintf("offset %d out of range for length %d along axis %d",
+            e.Value, e.Length, e.Axis)
+    }
+}
+
+// strict is an implementation of the [D] interface returned by [Strict].
+type strict struct {
+    D
+}
+
+// Strict returns an implementation of the [D] interface with the same shape
+// as d, except that instead of wrapping an out-of-range offset or index
+// round, the Index and Offsets methods panic with a [RangeError].
+//
+// This costs a bounds check on every call, but may be useful for catching
+// bugs, for example in tests or debug builds:
+//
+//     d := dimensions.Strict(m)
+//     value := m.Get(d.Index(x, y))
+//
+// As with Contains, trailing offsets past the dimensionality of d are
+// permitted if they are zero.
+func Strict(d D) D {
+    if s, ok := d.(strict); ok { return s }
+    return strict{d.Dimensions()}
+}
+
+    func (s strict) Dimensions() D {
+        return s
+    }
+
+    func (s strict) Index(offsets ... int) int {
+        dims := s.Dimensionality()
+        for i, offset := range offsets {
+            length := 1 // trailing offsets must be zero
+            if i < dims { length = s.Length(i) }
+            if (offset < 0) || (offset >= length) {
+                panic(RangeError{Axis: i, Value: offset, Length: length})
+            }
+        }
+        return s.D.Index(offsets...)
+    }
+
+    func (s strict) Offsets(dest []int, idx int) {
+        if (idx < 0) || (idx >= s.Size()) {
+            panic(RangeError{Axis: -1, Value: idx, Length: s.Size()})
+        }
+        s.D.Offsets(dest, idx)
+    }
@@ -0,0 +1,72 @@
+package dimensions
+
+import (
+    "errors"
+)
+
+var errStrides = errors.New("NewWithStrides with wrong number of strides or negative stride")
+
+// NewWithStrides returns a [Map] from a shape with the given lengths onto a
+// 1-dimensional shape, such that the element at offsets (x, y, z...) is
+// at the sum of each offset multiplied by the stride for that axis, i.e.
+// x*strides[0] + y*strides[1] + z*strides[2]...
+//
+// This is useful for sharing storage, without a copy, with code that lays
+// out elements in an order other than row-major order, such as Fortran or
+// BLAS buffers in column-major order, or for interleaved data where elements
+// of interest are spaced apart. For example, to view the green channel of a
+// buffer of packed RGB pixels:
+//
+//     m := dimensions.NewWithStrides([]int{width, height}, []int{3, 3 * width})
+//     storage := matrix.NewSharedGrid[uint8]([]int{m.Original().Size()}, pixels[1:])
+//     green := matrix.NewView(storage, m)
+//
+// The original shape is just large enough to hold every element. Strides
+// may be zero, in which case every offset along that axis maps to the same
+// elements, but must not be negative. Strides need not be distinct, so
+// different offsets may map to the same element. The number of strides must
+// be the same as the number of lengths, and lengths must be positive, or
+// this function panics.
+func NewWithStrides(lengths, strides []int) Map {
+    new := New(lengths...)
+    if len(strides) != len(lengths) { panic(errStrides) }
+    lengths = append([]int{}, lengths...) // don't share memory
+    strides = append([]int{}, strides...)
+
+    size := 1
+    for i, stride := range strides {
+        if stride < 0 { panic(errStrides) }
+        size += (lengths[i] - 1) * stride
+    }
+
+    return mapping{
+        D:        new,
+        original: New(size),
+        offsets:  func(dest []int, source ... int) {
+            idx := 0
+            for i := 0; i < len(strides); i++ {
+                if i >= len(source) { break }
+                idx += (source[i] % lengths[i]) * strides[i]
+            }
+            dest[0] = idx
+        },
+    }
+}
+
+// ColumnMajor returns a [Map] from a shape with the given lengths onto a
+// 1-dimensional shape, such that each element is laid out in column-major
+// order, where consecutive elements along the last axis (rather than the
+// first) are adjacent, as in Fortran. For example, in a 2-dimensional shape,
+// each column is stored contiguously.
+//
+// This is a shortcut for [NewWithStrides] with the appropriate strides.
+// Lengths must be positive, or this function panics.
+func ColumnMajor(lengths ... int) Map {
+    strides := make([]int, len(lengths))
+    stride := 1
+    for i := len(lengths) - 1; i >= 0; i-- {
+        strides[i] = stride
+        stride *= lengths[i]
+    }
+    return NewWithStrides(lengths, strides)
+}
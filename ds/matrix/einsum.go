@@ -0,0 +1,144 @@
+package matrix
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+    "github.com/tawesoft/golib/v2/operator"
+)
+
+// ErrEinsum is raised in a panic when the specification given to [Einsum] is
+// not valid.
+var ErrEinsum = errors.New("invalid einsum specification")
+
+// einsumSampler returns a string for [dimensions.Sampler] that maps the
+// offsets along each of the given labels, with the given lengths, onto the
+// axes of a matrix with the given axis labels.
+func einsumSampler(space string, lengths []int, axes string) string {
+    const digits = "0123456789ABCDEF"
+    var sb strings.Builder
+    for i := 0; i < len(space); i++ {
+        if strings.IndexByte(axes, space[i]) < 0 {
+            fmt.Fprintf(&sb, "+%d ", lengths[i]) // not an axis of this matrix
+            continue
+        }
+        sb.WriteByte('(')
+        for j := 0; j < len(axes); j++ {
+            if axes[j] == space[i] { sb.WriteByte(digits[j]) }
+        }
+        sb.WriteString(") ")
+    }
+    return sb.String()
+}
+
+// Einsum sets out to the result of an operation on the matrices ins,
+// described by a restricted form of Einstein summation notation, like
+// NumPy's einsum in explicit mode.
+//
+// The specification names each axis of each input matrix with a letter,
+// separated by commas, followed by "->" and a letter for each axis of out.
+// Each element of out is the sum, over every combination of offsets along
+// the axes whose labels do not appear in the output, of the products of the
+// elements of each input at the offsets given by their labels. Labels are
+// given in axis order, so the first label is axis 0 (the x axis, or the
+// offset within a row), and the second is axis 1 (the y axis, or the row).
+//
+// For example, with 2-dimensional matrices a and b:
+//
+//     Einsum("ij->ji", out, a)         // transpose
+//     Einsum("ii->i", out, a)          // diagonal
+//     Einsum("ij->j", out, a)          // sum of each row
+//     Einsum("ij,ij->ij", out, a, b)   // element-wise product
+//     Einsum("ki,jk->ji", out, a, b)   // matrix product, as MatMul(out, a, b)
+//
+// If no labels follow the "->", out must have a single element, which is
+// set to the sum of every product. For example, Einsum("ii->", out, a)
+// computes the trace of a.
+//
+// A label repeated in the same input, as in "ii", refers to the diagonal
+// along those axes. Labels in the output must be unique. The input matrices
+// may have up to 16 axes.
+//
+// Each input is accessed through a [View] constructed by
+// [dimensions.Sampler], so Einsum visits every combination of offsets,
+// whether or not an element is zero. For a large sparse matrix, a dedicated
+// operation such as [MatMul] is likely to be faster.
+//
+// Panics with [ErrEinsum] if the specification is not valid, or has the
+// wrong number of inputs or labels for a matrix. Panics with [ErrShape] if
+// axes with the same label do not have the same length, or if out does not
+// have the shape given by its labels. The output must not be the same
+// matrix as any input.
+func Einsum[T operator.Number](spec string, out M[T], ins ... M[T]) {
+    spec = strings.Join(strings.Fields(spec), "") // ignore whitespace
+    lhs, rhs, ok := strings.Cut(spec, "->")
+    if !ok { panic(fmt.Errorf("%w: %q has no \"->\"", ErrEinsum, spec)) }
+    inputs := strings.Split(lhs, ",")
+    if len(inputs) != len(ins) {
+        panic(fmt.Errorf("%w: %q has %d inputs, got %d matrices", ErrEinsum, spec, len(inputs), len(ins)))
+    }
+
+    isLabel := func(c byte) bool {
+        return ((c >= 'a') && (c <= 'z')) || ((c >= 'A') && (c <= 'Z'))
+    }
+
+    // every distinct label, with output labels first, and the length along
+    // each
+    var space []byte
+    var lengths []int
+    label := func(c byte, length int) {
+        if !isLabel(c) { panic(fmt.Errorf("%w: %q has invalid label %q", ErrEinsum, spec, c)) }
+        if i := strings.IndexByte(string(space), c); i >= 0 {
+            if lengths[i] != length { panic(ErrShape) }
+            return
+        }
+        space = append(space, c)
+        lengths = append(lengths, length)
+    }
+
+    for i := 0; i < len(rhs); i++ {
+        if strings.IndexByte(rhs[0:i], rhs[i]) >= 0 {
+            panic(fmt.Errorf("%w: %q repeats output label %q", ErrEinsum, spec, rhs[i]))
+        }
+        if i >= out.Dimensionality() { break } // checked below
+        label(rhs[i], out.Length(i))
+    }
+    if len(rhs) == 0 {
+        if out.Size() != 1 { panic(ErrShape) }
+    } else if len(rhs) != out.Dimensionality() {
+        panic(fmt.Errorf("%w: %q has %d output labels, got %d axes", ErrEinsum, spec, len(rhs), out.Dimensionality()))
+    }
+
+    for i, axes := range inputs {
+        if len(axes) != ins[i].Dimensionality() {
+            panic(fmt.Errorf("%w: %q has %d labels for input %d, got %d axes",
+                ErrEinsum, spec, len(axes), i, ins[i].Dimensionality()))
+        }
+        if len(axes) > 16 {
+            panic(fmt.Errorf("%w: input %d has more than 16 axes", ErrEinsum, i))
+        }
+        for j := 0; j < len(axes); j++ {
+            label(axes[j], ins[i].Length(j))
+        }
+    }
+
+    // a view of each matrix with an axis for every label
+    views := make([]M[T], len(ins))
+    for i, axes := range inputs {
+        views[i] = NewView(ins[i], dimensions.Sampler(einsumSampler(string(space), lengths, axes)).Bind(ins[i]))
+    }
+    result := NewView(out, dimensions.Sampler(einsumSampler(string(space), lengths, rhs)).Bind(out))
+
+    out.Clear()
+    for idx := 0; idx < result.Size(); idx++ {
+        product := T(1)
+        for _, v := range views {
+            product *= v.Get(idx)
+            if product == 0 { break }
+        }
+        if product == 0 { continue }
+        result.Set(idx, result.Get(idx) + product)
+    }
+}
@@ -0,0 +1,165 @@
+package matrix
+
+import (
+    "fmt"
+    "io"
+    "strings"
+    "unicode/utf8"
+)
+
+// formatOptions configures [Format].
+type formatOptions struct {
+    format    func(value any) string
+    maxWidth  int
+    maxHeight int
+    sparse    bool
+}
+
+// FormatOption configures the output of [Format].
+type FormatOption func(o *formatOptions)
+
+// FormatVerb returns a FormatOption that formats each element with
+// [fmt.Sprintf] and the given verb, for example "%.2f". The default is "%v".
+func FormatVerb(verb string) FormatOption {
+    return func(o *formatOptions) {
+        o.format = func(value any) string { return fmt.Sprintf(verb, value) }
+    }
+}
+
+// FormatFunc returns a FormatOption that formats each element with the
+// function f.
+func FormatFunc(f func(value any) string) FormatOption {
+    return func(o *formatOptions) {
+        o.format = f
+    }
+}
+
+// FormatMaxWidth returns a FormatOption that limits each row to n elements.
+// Longer rows show the elements at the start and the end of the row,
+// separated by an ellipsis. A value of zero means no limit.
+func FormatMaxWidth(n int) FormatOption {
+    return func(o *formatOptions) {
+        o.maxWidth = n
+    }
+}
+
+// FormatMaxHeight returns a FormatOption that limits each plane to n rows.
+// Taller planes show the rows at the start and the end of the plane,
+// separated by an ellipsis. In sparse mode, this limits the total number of
+// elements shown. A value of zero means no limit.
+func FormatMaxHeight(n int) FormatOption {
+    return func(o *formatOptions) {
+        o.maxHeight = n
+    }
+}
+
+// FormatSparse returns a FormatOption that shows only the non-zero elements,
+// one per line, as their offsets along each axis followed by their value.
+func FormatSparse() FormatOption {
+    return func(o *formatOptions) {
+        o.sparse = true
+    }
+}
+
+// visible returns the positions to show along an axis of length n, limited
+// to at most limit positions, where -1 marks an ellipsis.
+func visible(n, limit int) []int {
+    var result []int
+    if (limit <= 0) || (n <= limit) {
+        for i := 0; i < n; i++ { result = append(result, i) }
+        return result
+    }
+
+    head := (limit + 1) / 2
+    for i := 0; i < head; i++ { result = append(result, i) }
+    result = append(result, -1)
+    for i := n - (limit - head); i < n; i++ { result = append(result, i) }
+    return result
+}
+
+func joinInts(xs []int) string {
+    s := make([]string, len(xs))
+    for i, x := range xs {
+        s[i] = fmt.Sprint(x)
+    }
+    return strings.Join(s, ", ")
+}
+
+// Format writes a human-readable representation of the matrix m to w, for
+// debugging. The first two axes are shown as rows of aligned columns. A
+// matrix with more than two dimensions is shown as a sequence of these
+// 2-dimensional planes, each preceded by its offsets along the higher axes,
+// for example "[:, :, 1, 2]".
+//
+// The output may be configured by options such as [FormatVerb],
+// [FormatMaxWidth], [FormatMaxHeight], and [FormatSparse].
+func Format[T comparable](w io.Writer, m M[T], opts ... FormatOption) error {
+    o := formatOptions{
+        format: func(value any) string { return fmt.Sprint(value) },
+    }
+    for _, opt := range opts {
+        opt(&o)
+    }
+
+    var sb strings.Builder
+    offsets := make([]int, m.Dimensionality())
+
+    if o.sparse {
+        count := 0
+        for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {
+            if (o.maxHeight > 0) && (count == o.maxHeight) {
+                sb.WriteString("...\n")
+                break
+            }
+            m.Offsets(offsets, idx)
+            fmt.Fprintf(&sb, "(%s): %s\n", joinInts(offsets), o.format(m.Get(idx)))
+            count++
+        }
+        _, err := io.WriteString(w, sb.String())
+        return err
+    }
+
+    width, height := m.Length(0), max(1, m.Length(1))
+    columns := visible(width, o.maxWidth)
+    rows := visible(height, o.maxHeight)
+    cells := make([][]string, len(rows))
+    widths := make([]int, len(columns))
+
+    for plane := 0; plane < m.Size() / (width * height); plane++ {
+        start := plane * width * height
+        if m.Dimensionality() > 2 {
+            m.Offsets(offsets, start)
+            if plane > 0 { sb.WriteString("\n") }
+            fmt.Fprintf(&sb, "[:, :, %s]\n", joinInts(offsets[2:]))
+        }
+
+        // format every cell first, to find the width of each column
+        clear(widths)
+        for i, y := range rows {
+            cells[i] = cells[i][:0]
+            if y < 0 { continue }
+            for j, x := range columns {
+                s := "..."
+                if x >= 0 { s = o.format(m.Get(start + (y * width) + x)) }
+                cells[i] = append(cells[i], s)
+                widths[j] = max(widths[j], utf8.RuneCountInString(s))
+            }
+        }
+
+        for i, row := range cells {
+            if rows[i] < 0 {
+                sb.WriteString("...\n")
+                continue
+            }
+            for j, s := range row {
+                if j > 0 { sb.WriteByte(' ') }
+                sb.WriteString(strings.Repeat(" ", widths[j] - utf8.RuneCountInString(s)))
+                sb.WriteString(s)
+            }
+            sb.WriteByte('\n')
+        }
+    }
+
+    _, err := io.WriteString(w, sb.String())
+    return err
+}
@@ -0,0 +1,101 @@
+// Package imaging converts between matrices and images from the standard
+// library [image] package, sharing memory where possible.
+//
+// A grayscale image is a 2-dimensional matrix of uint8 values, with the
+// width and height of the image, such as a heightmap. An 8-bit colour image
+// is a 3-dimensional matrix of uint8 values, where axis 0 is the channel,
+// with a length of 4 (in the order red, green, blue, alpha), and axes 1 and
+// 2 are the x and y axes of the image.
+//
+// In each case, offset (0, 0) of the matrix is the minimum point of the
+// image bounds, which is not necessarily (0, 0) in the image.
+package imaging
+
+import (
+    "image"
+    "image/color"
+
+    "github.com/tawesoft/golib/v2/ds/matrix"
+)
+
+// FromImage returns a new 2-dimensional [matrix.Grid] of the colour of each
+// pixel of any image. The result is a copy, and does not share memory with
+// the image. For an image that is an [image.Gray] or [image.NRGBA], prefer
+// [FromGray] or [FromNRGBA].
+//
+// The image bounds must not be empty.
+func FromImage(img image.Image) matrix.M[color.Color] {
+    bounds := img.Bounds()
+    m := matrix.NewGrid[color.Color](bounds.Dx(), bounds.Dy())
+    for y := 0; y < bounds.Dy(); y++ {
+        for x := 0; x < bounds.Dx(); x++ {
+            m.Set(m.Index(x, y), img.At(bounds.Min.X + x, bounds.Min.Y + y))
+        }
+    }
+    return m
+}
+
+// FromGray returns a 2-dimensional matrix of the value of each pixel of a
+// grayscale image. The matrix shares memory with the image: modifications to
+// either modify the other.
+//
+// If the image has no padding between rows, this is a [matrix.Grid];
+// otherwise, for example for a sub-image, it is a [matrix.View] (see
+// [matrix.NewSharedGridWithStrides]).
+//
+// The image bounds must not be empty.
+func FromGray(img *image.Gray) matrix.M[uint8] {
+    w, h := img.Rect.Dx(), img.Rect.Dy()
+    if img.Stride == w {
+        return matrix.NewSharedGrid([]int{w, h}, img.Pix)
+    }
+    return matrix.NewSharedGridWithStrides([]int{w, h}, []int{1, img.Stride}, img.Pix)
+}
+
+// FromNRGBA returns a 3-dimensional matrix of each channel of each pixel of
+// a colour image, where axis 0 is the channel. The matrix shares memory with
+// the image: modifications to either modify the other.
+//
+// If the image has no padding between rows, this is a [matrix.Grid];
+// otherwise, for example for a sub-image, it is a [matrix.View] (see
+// [matrix.NewSharedGridWithStrides]).
+//
+// The image bounds must not be empty.
+func FromNRGBA(img *image.NRGBA) matrix.M[uint8] {
+    w, h := img.Rect.Dx(), img.Rect.Dy()
+    if img.Stride == 4 * w {
+        return matrix.NewSharedGrid([]int{4, w, h}, img.Pix)
+    }
+    return matrix.NewSharedGridWithStrides([]int{4, w, h}, []int{1, 4, img.Stride}, img.Pix)
+}
+
+// ToImage returns an image of a 2-dimensional matrix, as an [image.Gray], or
+// of a 3-dimensional matrix with a length of 4 along axis 0, as an
+// [image.NRGBA]. The image bounds start at (0, 0).
+//
+// If the matrix is a [matrix.Grid], the image shares memory with the matrix:
+// modifications to either modify the other. Otherwise, the image is a copy.
+//
+// Panics with [matrix.ErrShape] if the matrix does not have one of these
+// shapes.
+func ToImage(m matrix.M[uint8]) image.Image {
+    colour := (m.Dimensionality() == 3) && (m.Length(0) == 4)
+    if (m.Dimensionality() != 2) && !colour { panic(matrix.ErrShape) }
+
+    var pix []uint8
+    if g, ok := m.(matrix.Grid[uint8]); ok {
+        pix = g.Values()
+    } else {
+        pix = make([]uint8, m.Size())
+        for i := range pix {
+            pix[i] = m.Get(i)
+        }
+    }
+
+    if colour {
+        w, h := m.Length(1), m.Length(2)
+        return &image.NRGBA{Pix: pix, Stride: 4 * w, Rect: image.Rect(0, 0, w, h)}
+    }
+    w, h := m.Length(0), m.Length(1)
+    return &image.Gray{Pix: pix, Stride: w, Rect: image.Rect(0, 0, w, h)}
+}
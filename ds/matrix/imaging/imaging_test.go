@@ -0,0 +1,70 @@
+package imaging_test
+
+import (
+    "image"
+    "image/color"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/ds/matrix"
+    "github.com/tawesoft/golib/v2/ds/matrix/imaging"
+)
+
+func TestGray(t *testing.T) {
+    img := image.NewGray(image.Rect(0, 0, 4, 3))
+    img.SetGray(2, 1, color.Gray{Y: 200})
+
+    m := imaging.FromGray(img)
+    if m.Get(m.Index(2, 1)) != 200 {
+        t.Errorf("FromGray: got %d, want 200", m.Get(m.Index(2, 1)))
+    }
+    m.Set(m.Index(3, 2), 50)
+    if img.GrayAt(3, 2).Y != 50 {
+        t.Errorf("FromGray: expected set to modify image")
+    }
+
+    // a sub-image has padding between rows
+    sub := imaging.FromGray(img.SubImage(image.Rect(1, 1, 4, 3)).(*image.Gray))
+    if (sub.Length(0) != 3) || (sub.Length(1) != 2) || (sub.Get(sub.Index(1, 0)) != 200) {
+        t.Errorf("FromGray(sub-image): wrong result")
+    }
+
+    back := imaging.ToImage(m).(*image.Gray)
+    back.SetGray(0, 0, color.Gray{Y: 1})
+    if (img.GrayAt(0, 0).Y != 1) || (back.GrayAt(2, 1).Y != 200) {
+        t.Errorf("ToImage: expected image to share memory")
+    }
+
+    copied := imaging.ToImage(sub).(*image.Gray)
+    if (copied.Bounds() != image.Rect(0, 0, 3, 2)) || (copied.GrayAt(1, 0).Y != 200) {
+        t.Errorf("ToImage(sub-image): wrong result")
+    }
+}
+
+func TestNRGBA(t *testing.T) {
+    img := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+    img.SetNRGBA(1, 2, color.NRGBA{R: 10, G: 20, B: 30, A: 40})
+
+    m := imaging.FromNRGBA(img)
+    for c, want := range []uint8{10, 20, 30, 40} {
+        if got := m.Get(m.Index(c, 1, 2)); got != want {
+            t.Errorf("FromNRGBA: channel %d got %d, want %d", c, got, want)
+        }
+    }
+
+    back := imaging.ToImage(m).(*image.NRGBA)
+    if back.NRGBAAt(1, 2) != (color.NRGBA{R: 10, G: 20, B: 30, A: 40}) {
+        t.Errorf("ToImage: wrong result")
+    }
+
+    colours := imaging.FromImage(img.SubImage(image.Rect(1, 1, 3, 3)))
+    if colours.Get(colours.Index(0, 1)) != (color.NRGBA{R: 10, G: 20, B: 30, A: 40}) {
+        t.Errorf("FromImage: wrong result")
+    }
+}
+
+func TestToImage_shape(t *testing.T) {
+    defer func() {
+        if recover() != matrix.ErrShape { t.Errorf("expected ToImage to panic") }
+    }()
+    imaging.ToImage(matrix.NewGrid[uint8](3, 4, 5))
+}
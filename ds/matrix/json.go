@@ -0,0 +1,187 @@
+package matrix
+
+import (
+    "encoding/json"
+    "fmt"
+    "math"
+
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+)
+
+// jsonMatrix is the JSON encoding of a matrix, where V is a dense array of
+// values, or a sparse object mapping indexes to values.
+type jsonMatrix[V any] struct {
+    Lengths []int `json:"lengths"`
+    Values  V     `json:"values"`
+}
+
+// jsonDiagonal is the JSON encoding of a Diagonal matrix.
+type jsonDiagonal[T any] struct {
+    Dimensionality int `json:"dimensionality"`
+    Values         []T `json:"values"`
+}
+
+// jsonLengths returns the lengths of a matrix along each axis.
+func jsonLengths(d dimensions.D) []int {
+    lengths := make([]int, d.Dimensionality())
+    d.Lengths(lengths)
+    return lengths
+}
+
+// checkJSONLengths returns the size of a matrix with the given lengths, or
+// an error if they are not valid.
+func checkJSONLengths(lengths []int) (int, error) {
+    if (len(lengths) == 0) || (len(lengths) > 64) {
+        return 0, fmt.Errorf("%w: JSON matrix must have between 1 and 64 dimensions", ErrFormat)
+    }
+    size := 1
+    for _, n := range lengths {
+        if n <= 0 { return 0, fmt.Errorf("%w: JSON matrix lengths must be positive", ErrFormat) }
+        if size > math.MaxInt / n { return 0, fmt.Errorf("%w: JSON matrix too large", ErrLimit) }
+        size *= n
+    }
+    return size, nil
+}
+
+// unmarshalDense decodes a matrix encoded with a dense array of values.
+func unmarshalDense[V any](data []byte) ([]int, []V, error) {
+    var j jsonMatrix[[]V]
+    if err := json.Unmarshal(data, &j); err != nil { return nil, nil, err }
+    size, err := checkJSONLengths(j.Lengths)
+    if err != nil { return nil, nil, err }
+    if len(j.Values) != size {
+        return nil, nil, fmt.Errorf("%w: JSON matrix has %d values, expected %d", ErrFormat, len(j.Values), size)
+    }
+    return j.Lengths, j.Values, nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface. A Grid is encoded
+// as an object with "lengths", an array of the length along each axis, and
+// "values", an array of every value in order of index.
+func (g Grid[T]) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonMatrix[[]T]{
+        Lengths: jsonLengths(g),
+        Values:  g.values[:g.Size()],
+    })
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface, decoding the
+// encoding produced by MarshalJSON into a new Grid.
+func (g *Grid[T]) UnmarshalJSON(data []byte) error {
+    lengths, values, err := unmarshalDense[T](data)
+    if err != nil { return err }
+    *g = Grid[T]{D: dimensions.New(lengths...), values: values}
+    return nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface. A Bool is encoded
+// as an object with "lengths", an array of the length along each axis, and
+// "values", an array of every value, true or false, in order of index.
+func (b Bool) MarshalJSON() ([]byte, error) {
+    values := make([]bool, b.Size())
+    for i := range values {
+        values[i] = b.Get(i)
+    }
+    return json.Marshal(jsonMatrix[[]bool]{Lengths: jsonLengths(b), Values: values})
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface, decoding the
+// encoding produced by MarshalJSON into a new Bool.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+    lengths, values, err := unmarshalDense[bool](data)
+    if err != nil { return err }
+    m := NewBool(lengths...).(Bool)
+    for i, value := range values {
+        m.Set(i, value)
+    }
+    *b = m
+    return nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface. A Bit is encoded
+// as an object with "lengths", an array of the length along each axis, and
+// "values", an array of every value, 0 or 1, in order of index.
+func (b Bit) MarshalJSON() ([]byte, error) {
+    values := make([]int, b.Size())
+    for i := range values {
+        values[i] = b.Get(i)
+    }
+    return json.Marshal(jsonMatrix[[]int]{Lengths: jsonLengths(b), Values: values})
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface, decoding the
+// encoding produced by MarshalJSON into a new Bit.
+func (b *Bit) UnmarshalJSON(data []byte) error {
+    lengths, values, err := unmarshalDense[int](data)
+    if err != nil { return err }
+    m := NewBit(lengths...).(Bit)
+    for i, value := range values {
+        if (value != 0) && (value != 1) {
+            return fmt.Errorf("%w: JSON Bit matrix has value %d at index %d", ErrFormat, value, i)
+        }
+        m.Set(i, value)
+    }
+    *b = m
+    return nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface. A Hashmap is
+// encoded as an object with "lengths", an array of the length along each
+// axis, and "values", an object mapping the index of each non-zero element,
+// as a string, to its value.
+func (m Hashmap[T]) MarshalJSON() ([]byte, error) {
+    var zero T
+    values := make(map[int]T, len(m.values))
+    for idx, value := range m.values {
+        if value != zero { values[idx] = value }
+    }
+    return json.Marshal(jsonMatrix[map[int]T]{Lengths: jsonLengths(m), Values: values})
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface, decoding the
+// encoding produced by MarshalJSON into a new Hashmap.
+func (m *Hashmap[T]) UnmarshalJSON(data []byte) error {
+    var j jsonMatrix[map[int]T]
+    if err := json.Unmarshal(data, &j); err != nil { return err }
+    size, err := checkJSONLengths(j.Lengths)
+    if err != nil { return err }
+
+    result := NewHashmap[T](j.Lengths...).(Hashmap[T])
+    for idx, value := range j.Values {
+        if (idx < 0) || (idx >= size) {
+            return fmt.Errorf("%w: JSON Hashmap matrix index %d out of range", ErrFormat, idx)
+        }
+        result.Set(idx, value)
+    }
+    *m = result
+    return nil
+}
+
+// MarshalJSON implements the [json.Marshaler] interface. A Diagonal is
+// encoded as an object with "dimensionality", the number of axes, and
+// "values", an array of the values along the diagonal only. Like
+// [NewSharedDiagonal], the number of values is the length of each side.
+func (m Diagonal[T]) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonDiagonal[T]{
+        Dimensionality: m.Dimensionality(),
+        Values:         m.values,
+    })
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface, decoding the
+// encoding produced by MarshalJSON into a new Diagonal.
+func (m *Diagonal[T]) UnmarshalJSON(data []byte) error {
+    var j jsonDiagonal[T]
+    if err := json.Unmarshal(data, &j); err != nil { return err }
+    if (j.Dimensionality <= 0) || (j.Dimensionality > 64) || (len(j.Values) == 0) {
+        return fmt.Errorf("%w: JSON Diagonal matrix has no dimensions or values", ErrFormat)
+    }
+    lengths := make([]int, j.Dimensionality)
+    for i := range lengths {
+        lengths[i] = len(j.Values)
+    }
+    if _, err := checkJSONLengths(lengths); err != nil { return err }
+
+    *m = NewSharedDiagonal(j.Dimensionality, j.Values).(Diagonal[T])
+    return nil
+}
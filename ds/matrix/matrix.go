@@ -9,10 +9,17 @@
 package matrix
 
 import (
+    "errors"
+
     "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+    "github.com/tawesoft/golib/v2/internal/words"
     "github.com/tawesoft/golib/v2/math/series"
 )
 
+// ErrShape is raised in a panic when matrices do not have compatible shapes
+// for an operation, or when dimensions are specified incorrectly.
+var ErrShape = errors.New("matrix shapes are not compatible")
+
 // M is the interface implemented by a matrix of Values.
 type M[T comparable] interface {
     // D provides an efficient way to index the matrix and query its shape.
@@ -36,7 +43,7 @@ type M[T comparable] interface {
     // are the zero value for type T.
     //
     // This function therefore efficiently enumerates all non-zero values in a
-    // sparse matrix.
+    // sparse matrix. Values are enumerated in increasing order of index.
     Next(idx int) (int, bool)
 
     // Clear sets every element in the matrix to the zero value for type T.
@@ -47,6 +54,7 @@ type M[T comparable] interface {
 // offsets. If dest is smaller than src along any dimension, the results are
 // cropped.
 func Copy[T comparable](dest, src M[T]) {
+    if copyBuckets(dest, src) { return }
     dest.Clear()
     offsets := make([]int, src.Dimensionality())
     for i := 0; i < src.Size(); i++ {
@@ -57,6 +65,79 @@ func Copy[T comparable](dest, src M[T]) {
     }
 }
 
+// CopyAt copies every value from src into dest, starting at the given
+// offsets along each axis of dest, so that the element at offsets (x, y) in
+// src is copied to offsets (x + destOffsets[0], y + destOffsets[1]) in dest.
+// Unlike [Copy], elements of dest outside this region are unchanged. Any
+// part of src that would lie outside dest, including at negative offsets, is
+// cropped.
+//
+// The number of destOffsets must be the same as the dimensionality of src,
+// or CopyAt panics with [ErrShape]. The destination must not be the same
+// matrix as src.
+func CopyAt[T comparable](dest, src M[T], destOffsets ... int) {
+    dims := src.Dimensionality()
+    if len(destOffsets) != dims { panic(ErrShape) }
+    offsets := make([]int, dims)
+    for i := 0; i < src.Size(); i++ {
+        src.Offsets(offsets, i)
+        for j := range offsets {
+            offsets[j] += destOffsets[j]
+        }
+        if dest.Contains(offsets...) {
+            dest.Set(dest.Index(offsets...), src.Get(i))
+        }
+    }
+}
+
+// Paste copies every value from src into the region of dest described by a
+// [dimensions.Map], which maps each index in the region to an index in dest,
+// such as one returned by [dimensions.Crop] or [dimensions.Sampler]. Elements
+// of dest outside the region are unchanged.
+//
+// This is the same as copying src into a [View] of dest, but without
+// clearing the view first. For example, Paste(dest, src,
+// dimensions.Sampler("-x").Bind(dest)) copies src into dest mirrored along
+// the x axis.
+//
+// The region must have the same shape as src, or Paste panics with
+// [ErrShape]. The destination must not be the same matrix as src.
+func Paste[T comparable](dest, src M[T], region dimensions.Map) {
+    if !sameShape(region, src) { panic(ErrShape) }
+    for i := 0; i < src.Size(); i++ {
+        dest.Set(region.MapIndex(i), src.Get(i))
+    }
+}
+
+// sameShape returns true if a and b have the same length along every
+// dimension.
+func sameShape(a, b dimensions.D) bool {
+    if a.Dimensionality() != b.Dimensionality() { return false }
+    for i := 0; i < a.Dimensionality(); i++ {
+        if a.Length(i) != b.Length(i) { return false }
+    }
+    return true
+}
+
+// copyBuckets implements Copy a whole word at a time, if dest and src are
+// both bit-packed matrices of the same shape, and returns true if it did.
+func copyBuckets[T comparable](dest, src M[T]) bool {
+    var d, s []uint64
+    switch x := any(dest).(type) {
+        case Bool: d = x.buckets
+        case Bit:  d = x.buckets
+        default:   return false
+    }
+    switch x := any(src).(type) {
+        case Bool: s = x.buckets
+        case Bit:  s = x.buckets
+        default:   return false
+    }
+    if !sameShape(dest, src) { return false }
+    copy(d, s)
+    return true
+}
+
 type constMatrix[T comparable] struct { dimensions.D; m M[T] }
 
     // Const returns a read-only view of matrix m. Changes to m will affect the
@@ -118,6 +199,29 @@ type Grid[T comparable] struct {
         }
     }
 
+    // NewSharedGridWithStrides returns a new [View] implementing M, of a
+    // [Grid] that uses the provided slice of values as its storage, where
+    // values are laid out according to the given strides along each axis.
+    // For example, strides of (height, 1) for a 2-dimensional matrix lay out
+    // values in column-major order. See [dimensions.NewWithStrides].
+    //
+    // As with [NewSharedGrid], this memory is shared. The length of the
+    // values slice must be large enough to hold every element.
+    func NewSharedGridWithStrides[T comparable](lengths, strides []int, values []T) M[T] {
+        mapping := dimensions.NewWithStrides(lengths, strides)
+        size := mapping.Original().Size()
+        if len(values) < size { panic("shared grid buffer too small") }
+        return NewView(NewSharedGrid([]int{size}, values), mapping)
+    }
+
+    // Values returns the storage of the grid, with one value for each
+    // index, in row-major order. This memory is shared: modifications to the
+    // returned slice will modify the matrix, and modifications to the matrix
+    // will modify the returned slice.
+    func (g Grid[T]) Values() []T {
+        return g.values[:g.Size():g.Size()]
+    }
+
     func (g Grid[T]) Get(idx int) T {
         return g.values[idx]
     }
@@ -126,12 +230,19 @@ type Grid[T comparable] struct {
         g.values[idx] = value
     }
 
+    // Next implements the M interface Next method. Note that, as a Grid
+    // stores every element, this must visit every zero element between
+    // non-zero elements. For a matrix with few non-zero elements, where
+    // iterating with Next is common, prefer a [CSR] matrix, which skips
+    // zero elements entirely, or a [Bool] or [Bit] matrix, which skip zero
+    // elements a word at a time.
     func (g Grid[T]) Next(idx int) (int, bool) {
         var zero T
         if idx < 0 { idx = -1 }
         idx++
-        for i := idx; i < g.Size(); i++ {
-            if g.values[i] != zero {
+        values := g.values[:g.Size()] // one bounds check, not one per element
+        for i := idx; i < len(values); i++ {
+            if values[i] != zero {
                 return i, true
             }
         }
@@ -154,13 +265,9 @@ type Bool struct {
     // NewBool allocates and returns a new [Bool] matrix implementing M.
     func NewBool(lengths ... int) M[bool] {
         dims := dimensions.New(lengths...)
-        numBuckets := dims.Size() / 64
-        if dims.Size() % 64 != 0 {
-            numBuckets++
-        }
         return Bool{
             D: dims,
-            buckets: make([]uint64, numBuckets),
+            buckets: make([]uint64, words.Words(dims.Size())),
         }
     }
 
@@ -184,35 +291,63 @@ type Bool struct {
     }
 
     func (b Bool) Next(idx int) (int, bool) {
-        if idx < 0 { idx = -1 }
-        idx++
-        start, offset := idx / 64, uint64(idx % 64)
-        target := -1
-
-        // Find the next non-zero bucket
-        for i := start; i < len(b.buckets); i++ {
-            if b.buckets[i] != 0 {
-                target = i
-                break
-            }
-        }
-        if target < 0 { return 0, false }
-
-        // Find the next non-zero bit
-        for i := offset; i < 64; i++ {
-            mask := uint64(1) << i
-            if b.buckets[i] & mask == mask {
-                return int(i), true
-            }
-        }
-
-        return 0, false
+        return words.Next(b.buckets, idx)
     }
 
     func (b Bool) Clear() {
         clear(b.buckets)
     }
 
+    // ProcessBuckets calls f with the underlying storage of the matrix,
+    // packed into 64-bit words, where the element at index i is bit (i % 64)
+    // of word (i / 64). This allows bulk operations on many elements at once.
+    //
+    // The function f may modify the words in place, but must not retain the
+    // slice after it returns. Any bits set past the last element are cleared
+    // after f returns.
+    func (b Bool) ProcessBuckets(f func(buckets []uint64)) {
+        f(b.buckets)
+        words.Mask(b.buckets, b.Size())
+    }
+
+    // And sets each element of the matrix to the logical AND of itself and
+    // the element at the same index in x, a whole word at a time. The
+    // matrices must have the same shape, or And panics with [ErrShape].
+    func (b Bool) And(x Bool) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.And(b.buckets, x.buckets)
+    }
+
+    // Or sets each element of the matrix to the logical OR of itself and
+    // the element at the same index in x, a whole word at a time. The
+    // matrices must have the same shape, or Or panics with [ErrShape].
+    func (b Bool) Or(x Bool) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.Or(b.buckets, x.buckets)
+    }
+
+    // Xor sets each element of the matrix to the logical XOR of itself and
+    // the element at the same index in x, a whole word at a time. The
+    // matrices must have the same shape, or Xor panics with [ErrShape].
+    func (b Bool) Xor(x Bool) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.Xor(b.buckets, x.buckets)
+    }
+
+    // AndNot clears each element of the matrix where the element at the
+    // same index in x is set, a whole word at a time. The matrices must have
+    // the same shape, or AndNot panics with [ErrShape].
+    func (b Bool) AndNot(x Bool) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.AndNot(b.buckets, x.buckets)
+    }
+
+    // PopCount returns the number of set elements in the matrix, counted a
+    // whole word at a time.
+    func (b Bool) PopCount() int {
+        return words.Count(b.buckets)
+    }
+
 // Bit is an implementation of the matrix interface [M] that stores data using
 // a densely packed sequence of bits that are either 1 or 0. In most cases,
 // this is initialised by calling [New] or [NewBit]. Performance sensitive
@@ -225,13 +360,9 @@ type Bit struct {
     // NewBit allocates and returns a [Bit] matrix implementing M.
     func NewBit(lengths ... int) M[int] {
         dims := dimensions.New(lengths...)
-        numBuckets := dims.Size() / 64
-        if dims.Size() % 64 != 0 {
-            numBuckets++
-        }
         return Bit{
             D: dims,
-            buckets: make([]uint64, numBuckets),
+            buckets: make([]uint64, words.Words(dims.Size())),
         }
     }
 
@@ -250,35 +381,63 @@ type Bit struct {
     }
 
     func (b Bit) Next(idx int) (int, bool) {
-        if idx < 0 { idx = -1 }
-        idx++
-        start, offset := idx / 64, uint64(idx % 64)
-        target := -1
-
-        // Find the next non-zero bucket
-        for i := start; i < len(b.buckets); i++ {
-            if b.buckets[i] != 0 {
-                target = i
-                break
-            }
-        }
-        if target < 0 { return 0, false }
-
-        // Find the next non-zero bit
-        for i := offset; i < 64; i++ {
-            mask := uint64(1) << i
-            if b.buckets[target] & mask == mask {
-                return int(i), true
-            }
-        }
-
-        return 0, false
+        return words.Next(b.buckets, idx)
     }
 
     func (b Bit) Clear() {
         clear(b.buckets)
     }
 
+    // ProcessBuckets calls f with the underlying storage of the matrix,
+    // packed into 64-bit words, where the element at index i is bit (i % 64)
+    // of word (i / 64). This allows bulk operations on many elements at once.
+    //
+    // The function f may modify the words in place, but must not retain the
+    // slice after it returns. Any bits set past the last element are cleared
+    // after f returns.
+    func (b Bit) ProcessBuckets(f func(buckets []uint64)) {
+        f(b.buckets)
+        words.Mask(b.buckets, b.Size())
+    }
+
+    // And sets each element of the matrix to the logical AND of itself and
+    // the element at the same index in x, a whole word at a time. The
+    // matrices must have the same shape, or And panics with [ErrShape].
+    func (b Bit) And(x Bit) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.And(b.buckets, x.buckets)
+    }
+
+    // Or sets each element of the matrix to the logical OR of itself and
+    // the element at the same index in x, a whole word at a time. The
+    // matrices must have the same shape, or Or panics with [ErrShape].
+    func (b Bit) Or(x Bit) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.Or(b.buckets, x.buckets)
+    }
+
+    // Xor sets each element of the matrix to the logical XOR of itself and
+    // the element at the same index in x, a whole word at a time. The
+    // matrices must have the same shape, or Xor panics with [ErrShape].
+    func (b Bit) Xor(x Bit) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.Xor(b.buckets, x.buckets)
+    }
+
+    // AndNot clears each element of the matrix where the element at the
+    // same index in x is set, a whole word at a time. The matrices must have
+    // the same shape, or AndNot panics with [ErrShape].
+    func (b Bit) AndNot(x Bit) {
+        if !sameShape(b, x) { panic(ErrShape) }
+        words.AndNot(b.buckets, x.buckets)
+    }
+
+    // PopCount returns the number of set elements in the matrix, counted a
+    // whole word at a time.
+    func (b Bit) PopCount() int {
+        return words.Count(b.buckets)
+    }
+
 // Hashmap is an implementation of the matrix interface [M] that stores data
 // using a hashmap with element indexes as keys. Elements with the zero value
 // are omitted. This implementation is best suited to representing very sparse
@@ -432,13 +591,12 @@ type Diagonal[T comparable] struct {
 
     func (m Diagonal[T]) Next(idx int) (int, bool) {
         var zero T
-        if idx < 0 { idx = -1 }
-        for {
-            idx = nextDiagonalIndex(m.Dimensionality(), len(m.values), idx)
-            if idx > m.Size() { break }
-            i := diagonalOffset(m.Dimensionality(), len(m.values), idx)
+        step := nextDiagonalIndex(m.Dimensionality(), len(m.values), 0)
+        start := 0
+        if idx >= 0 { start = (idx / step) + 1 }
+        for i := start; i < len(m.values); i++ {
             if m.values[i] != zero {
-                return idx, true
+                return i * step, true
             }
         }
         return 0, false
@@ -469,7 +627,14 @@ type View[T comparable] struct {
     //
     // See [dimensions.Crop] and the Bind method on [dimensions.Sampler] for
     // constructing maps from a parent.
+    //
+    // If the parent is itself a View, the result is a single View of its
+    // parent, with the two mappings combined by [dimensions.Compose], so that
+    // each access to a view of a view maps offsets in one step.
     func NewView[T comparable](parent M[T], mapping dimensions.Map) M[T] {
+        if v, ok := parent.(View[T]); ok && sameShape(mapping.Original(), v) {
+            parent, mapping = v.parent, dimensions.Compose(mapping, v.mapping)
+        }
         return View[T]{
             D: mapping.Dimensions(),
             parent:  parent,
@@ -518,3 +683,71 @@ type View[T comparable] struct {
     func Sample[T comparable](parent M[T], sampler string, constants ... int) M[T] {
         return NewView[T](parent, dimensions.Sampler(sampler, constants...).Bind(parent))
     }
+
+    // Slice is a shortcut for NewView(parent, dimensions.Slice(...))
+    //
+    // See [dimensions.Slice].
+    func Slice[T comparable](parent M[T], start, stop, step []int) M[T] {
+        return NewView[T](parent, dimensions.Slice(parent, start, stop, step))
+    }
+
+    // Tile returns a [View] of parent that repeats the parent periodically
+    // along each axis, with the given length along each axis, so that the
+    // element at offsets (x, y) in the view is the element at offsets
+    // (x mod w, y mod h) in a parent of width w and height h. For example,
+    // this can represent a toroidal grid, where moving past one edge wraps
+    // around to the opposite edge.
+    //
+    // A modification to an element in the view modifies the element in the
+    // parent, and so also every other element in the view that maps to it.
+    //
+    // The view is backed by [dimensions.Tile], and does not copy the parent.
+    // Panics with [ErrShape] if the number of lengths is not the same as the
+    // dimensionality of the parent.
+    func Tile[T comparable](parent M[T], lengths ... int) M[T] {
+        if len(lengths) != parent.Dimensionality() { panic(ErrShape) }
+        return NewView[T](parent, dimensions.Tile(lengths...).Bind(parent))
+    }
+
+    // Permute returns a [View] of parent with its dimensions reordered, so
+    // that dimension i of the view is dimension order[i] of the parent. The
+    // order must contain each dimension of the parent exactly once.
+    //
+    // For example, given a 3-dimensional matrix m, Permute(m, 2, 0, 1)
+    // returns a view where offsets (z, x, y) in the view refer to offsets (x,
+    // y, z) in m.
+    //
+    // The view is backed by [dimensions.Sampler], and does not copy the
+    // parent. Panics with [ErrShape] if the order is not a permutation of the
+    // parent's dimensions, or if the parent has more than 16 dimensions.
+    func Permute[T comparable](parent M[T], order ... int) M[T] {
+        const digits = "0123456789ABCDEF"
+        dims := parent.Dimensionality()
+        if (len(order) != dims) || (dims > len(digits)) { panic(ErrShape) }
+
+        var seen uint16
+        sampler := make([]byte, dims)
+        for i, axis := range order {
+            if (axis < 0) || (axis >= dims) || (seen & (1 << axis) != 0) {
+                panic(ErrShape)
+            }
+            seen |= 1 << axis
+            sampler[i] = digits[axis]
+        }
+        return Sample(parent, string(sampler))
+    }
+
+    // Transpose returns a [View] of parent with the order of its dimensions
+    // reversed. For a 2-dimensional matrix, this swaps rows and columns, so
+    // that the element at offsets (x, y) in the view is the element at (y, x)
+    // in the parent.
+    //
+    // The view does not copy the parent. See [Permute].
+    func Transpose[T comparable](parent M[T]) M[T] {
+        dims := parent.Dimensionality()
+        order := make([]int, dims)
+        for i := range order {
+            order[i] = dims - i - 1
+        }
+        return Permute(parent, order...)
+    }
@@ -1,10 +1,25 @@
 package matrix_test
 
 import (
+    "bytes"
+    "encoding/binary"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "math/rand"
+    "os"
     "slices"
+    "strconv"
+    "strings"
+    "sync"
     "testing"
 
     "github.com/tawesoft/golib/v2/ds/matrix"
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+    "github.com/tawesoft/golib/v2/iter"
+    "github.com/tawesoft/golib/v2/must"
 )
 
 func TestM_Next(t *testing.T) {
@@ -66,6 +81,77 @@ func TestM_Next(t *testing.T) {
                 m.Set(15, 60)
             },
         },
+        {
+            "bit 2",
+            matrix.NewBit(10, 20), // spans several buckets
+            []int{63, 64, 70, 199},
+            []int{1, 1, 1, 1},
+            func(m matrix.M[int]) {
+                m.Set( 63, 1)
+                m.Set( 64, 1)
+                m.Set( 70, 1)
+                m.Set(199, 1)
+            },
+        },
+        {
+            "csr 0",
+            matrix.NewCSR[int](4, 4),
+            []int{},
+            []int{},
+            nil,
+        },
+        {
+            "csr 1",
+            matrix.NewCSR[int](4, 4),
+            []int{2, 4, 5, 6, 7, 8, 15},
+            []int{1, 2, 3, 4, 5, 6,  1},
+            func(m matrix.M[int]) {
+                for i, v := range []int{
+                    0, 0, 1, 0,
+                    2, 3, 4, 5,
+                    6, 0, 0, 0,
+                    0, 0, 0, 1,
+                } { m.Set(i, v) }
+                m.Set(0, 9)
+                m.Set(0, 0) // removes
+            },
+        },
+        {
+            "csr 2",
+            matrix.NewSharedCSR(4, 4,
+                []int{0, 1, 1, 1, 3},
+                []int{2, 0, 3},
+                []int{1, 7, 8},
+            ),
+            []int{2, 12, 15},
+            []int{1,  7,  8},
+            nil,
+        },
+        {
+            "csc 1",
+            matrix.NewCSC[int](4, 4),
+            []int{2, 4, 5, 6, 7, 8, 15}, // not column-major order
+            []int{1, 2, 3, 4, 5, 6,  1},
+            func(m matrix.M[int]) {
+                for i, v := range []int{
+                    0, 0, 1, 0,
+                    2, 3, 4, 5,
+                    6, 0, 0, 0,
+                    0, 0, 0, 1,
+                } { m.Set(i, v) }
+            },
+        },
+        {
+            "csc 2",
+            matrix.NewSharedCSC(4, 4,
+                []int{0, 0, 0, 2, 2},
+                []int{0, 3},
+                []int{1, 0}, // explicit zeros are skipped
+            ),
+            []int{2},
+            []int{1},
+            nil,
+        },
         {
             "diagonal 0",
             matrix.NewSharedDiagonal(2, []int{0, 0, 0, 0}),
@@ -80,6 +166,13 @@ func TestM_Next(t *testing.T) {
             []int{ 1,  2},
             nil,
         },
+        {
+            "diagonal 2",
+            matrix.NewSharedDiagonal(2, []int{1, 0, 2}),
+            []int{0, 8},
+            []int{1, 2},
+            nil,
+        },
         {
             "hashmap 0",
             matrix.NewSharedHashmap([]int{4, 4}, map[int]int{
@@ -123,6 +216,18 @@ func TestM_Next(t *testing.T) {
             []int{3},
             nil,
         },
+        {
+            "view 3",
+            matrix.Slice(matrix.NewSharedGrid([]int{4, 4}, []int{
+                0, 0, 1, 0,
+                2, 3, 4, 5,
+                6, 0, 0, 0,
+                0, 0, 0, 1,
+            }), nil, nil, []int{-1, 2}), // every other row, mirrored
+            []int{1, 7},
+            []int{1, 6},
+            nil,
+        },
     }
 
     for _, tt := range tests {
@@ -146,3 +251,1403 @@ func TestM_Next(t *testing.T) {
         })
     }
 }
+
+func TestBool_ProcessBuckets(t *testing.T) {
+    m := matrix.NewBool(10, 10).(matrix.Bool)
+    m.ProcessBuckets(func(buckets []uint64) {
+        for i := range buckets {
+            buckets[i] = ^uint64(0)
+        }
+    })
+
+    // bits past the last element are cleared
+    indexes := make([]int, 0)
+    for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {
+        indexes = append(indexes, idx)
+    }
+    if (len(indexes) != 100) || (indexes[99] != 99) {
+        t.Errorf("expected 100 true elements, got %v", indexes)
+    }
+
+    // copying between matrices of the same shape copies whole buckets
+    dest := matrix.NewBool(10, 10)
+    dest.Set(5, true)
+    m.Set(5, false)
+    matrix.Copy(dest, matrix.M[bool](m))
+    if dest.Get(5) || !dest.Get(99) {
+        t.Errorf("expected dest to be a copy of m")
+    }
+
+    // copying between matrices of different shapes crops
+    small := matrix.NewBool(5, 5)
+    matrix.Copy(small, matrix.M[bool](m))
+    for x := 0; x < 5; x++ {
+        for y := 0; y < 5; y++ {
+            if small.Get(small.Index(x, y)) != m.Get(m.Index(x, y)) {
+                t.Errorf("expected small to be a cropped copy of m at (%d, %d)", x, y)
+            }
+        }
+    }
+}
+
+func BenchmarkNext_sparse(b *testing.B) {
+    // about 1% of elements are non-zero
+    r := rand.New(rand.NewSource(0))
+    ms := []matrix.M[int]{
+        matrix.NewGrid[int](256, 256),
+        matrix.NewHashmap[int](256, 256),
+        matrix.NewCSR[int](256, 256),
+        matrix.NewBlockSparse[int]([]int{16, 16}, 256, 256),
+        matrix.NewBit(256, 256),
+    }
+    for i := 0; i < (256 * 256) / 100; i++ {
+        idx := r.Intn(256 * 256)
+        for _, m := range ms {
+            m.Set(idx, 1)
+        }
+    }
+
+    for _, m := range ms {
+        b.Run(fmt.Sprintf("%T", m), func(b *testing.B) {
+            for i := 0; i < b.N; i++ {
+                for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {}
+            }
+        })
+    }
+}
+
+func BenchmarkCopy_Bool(b *testing.B) {
+    src := matrix.NewBool(256, 256)
+    dest := matrix.NewBool(256, 256)
+    for i := 0; i < src.Size(); i += 3 {
+        src.Set(i, true)
+    }
+
+    b.Run("bulk", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            matrix.Copy(dest, src)
+        }
+    })
+    b.Run("per-element", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            dest.Clear()
+            for idx, ok := src.Next(-1); ok; idx, ok = src.Next(idx) {
+                dest.Set(idx, true)
+            }
+        }
+    })
+}
+
+func TestCompressed(t *testing.T) {
+    // compare against a grid
+    for _, sparse := range []matrix.M[int]{
+        matrix.NewCSR[int](37, 23),
+        matrix.NewCSC[int](37, 23),
+    } {
+        grid := matrix.NewGrid[int](37, 23)
+        r := rand.New(rand.NewSource(0))
+        for i := 0; i < 2000; i++ {
+            idx, value := r.Intn(grid.Size()), r.Intn(3)
+            grid.Set(idx, value)
+            sparse.Set(idx, value)
+        }
+
+        nonZero := 0
+        for i := 0; i < grid.Size(); i++ {
+            if grid.Get(i) != sparse.Get(i) {
+                t.Fatalf("%T: wrong value at %d", sparse, i)
+            }
+            if grid.Get(i) != 0 { nonZero++ }
+        }
+
+        visited := 0
+        for idx, ok := sparse.Next(-1); ok; idx, ok = sparse.Next(idx) {
+            if grid.Get(idx) == 0 { t.Errorf("%T: Next returned a zero element %d", sparse, idx) }
+            visited++
+        }
+        if visited != nonZero {
+            t.Errorf("%T: Next visited %d elements, expected %d", sparse, visited, nonZero)
+        }
+
+        sparse.Clear()
+        if _, ok := sparse.Next(-1); ok {
+            t.Errorf("%T: expected empty matrix after Clear", sparse)
+        }
+    }
+}
+
+func ExampleMatMul() {
+    // (2×3)(3×2) = (2×2), where each matrix is given as (width, height)
+    a := matrix.NewSharedGrid([]int{3, 2}, []int{
+        1, 2, 3,
+        4, 5, 6,
+    })
+    b := matrix.NewSharedGrid([]int{2, 3}, []int{
+         7,  8,
+         9, 10,
+        11, 12,
+    })
+    c := matrix.NewGrid[int](2, 2)
+    matrix.MatMul(c, a, b)
+
+    for y := 0; y < 2; y++ {
+        fmt.Println(c.Get(c.Index(0, y)), c.Get(c.Index(1, y)))
+    }
+
+    // Output:
+    // 58 64
+    // 139 154
+}
+
+// randomMatrices returns matrices of each implementation with the same
+// random contents.
+func randomMatrices(r *rand.Rand, width, height int) []matrix.M[int] {
+    ms := []matrix.M[int]{
+        matrix.NewGrid[int](width, height),
+        matrix.NewHashmap[int](width, height),
+        matrix.NewCSR[int](width, height),
+        matrix.NewCSC[int](width, height),
+    }
+    for i := 0; i < (width * height) / 3; i++ {
+        idx, value := r.Intn(width * height), r.Intn(19) - 9
+        for _, m := range ms {
+            m.Set(idx, value)
+        }
+    }
+    return ms
+}
+
+func equal(a, b matrix.M[int]) bool {
+    if a.Size() != b.Size() { return false }
+    for i := 0; i < a.Size(); i++ {
+        if a.Get(i) != b.Get(i) { return false }
+    }
+    return true
+}
+
+func TestArithmetic(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    as := randomMatrices(r, 7, 5)
+    bs := randomMatrices(r, 7, 5)
+    cs := randomMatrices(r, 4, 7)
+
+    sum := matrix.NewGrid[int](7, 5)
+    difference := matrix.NewGrid[int](7, 5)
+    scaled := matrix.NewGrid[int](7, 5)
+    product := matrix.NewGrid[int](4, 5)
+    for i := 0; i < sum.Size(); i++ {
+        sum.Set(i, as[0].Get(i) + bs[0].Get(i))
+        difference.Set(i, as[0].Get(i) - bs[0].Get(i))
+        scaled.Set(i, as[0].Get(i) * 3)
+    }
+    for y := 0; y < 5; y++ {
+        for x := 0; x < 4; x++ {
+            v := 0
+            for k := 0; k < 7; k++ {
+                v += as[0].Get(as[0].Index(k, y)) * cs[0].Get(cs[0].Index(x, k))
+            }
+            product.Set(product.Index(x, y), v)
+        }
+    }
+
+    for _, a := range as {
+        for _, b := range bs {
+            for _, dest := range randomMatrices(r, 7, 5) {
+                matrix.Add(dest, a, b)
+                if !equal(dest, sum) { t.Errorf("Add(%T, %T, %T): wrong result", dest, a, b) }
+                matrix.Sub(dest, a, b)
+                if !equal(dest, difference) { t.Errorf("Sub(%T, %T, %T): wrong result", dest, a, b) }
+                matrix.Scale(dest, a, 3)
+                if !equal(dest, scaled) { t.Errorf("Scale(%T, %T): wrong result", dest, a) }
+            }
+        }
+        for _, c := range cs {
+            for _, dest := range randomMatrices(r, 4, 5) {
+                matrix.MatMul(dest, a, c)
+                if !equal(dest, product) { t.Errorf("MatMul(%T, %T, %T): wrong result", dest, a, c) }
+            }
+        }
+    }
+
+    // in place
+    a := randomMatrices(r, 7, 5)[1]
+    expected := matrix.NewGrid[int](7, 5)
+    matrix.Add(expected, a, a)
+    matrix.Add(a, a, a)
+    if !equal(a, expected) { t.Errorf("Add in place: wrong result") }
+
+    // diagonal
+    d := matrix.NewSharedDiagonal(2, []int{1, 2, 3, 4, 5, 6, 7})
+    scaledColumns := matrix.NewHashmap[int](7, 5)
+    matrix.MatMul(scaledColumns, as[0], d)
+    for i := 0; i < scaledColumns.Size(); i++ {
+        x := i % 7
+        if scaledColumns.Get(i) != as[0].Get(i) * (x + 1) {
+            t.Fatalf("MatMul by diagonal: wrong result at %d", i)
+        }
+    }
+}
+
+func TestArithmetic_broadcast(t *testing.T) {
+    a := matrix.NewSharedGrid([]int{3, 2}, []int{
+        1, 2, 3,
+        4, 5, 6,
+    })
+    column := matrix.NewSharedGrid([]int{1, 2}, []int{
+        10,
+        20,
+    })
+    row := matrix.NewSharedGrid([]int{3}, []int{100, 200, 300})
+
+    dest := matrix.NewGrid[int](3, 2)
+    matrix.Add(dest, a, column)
+    expected := matrix.NewSharedGrid([]int{3, 2}, []int{
+        11, 12, 13,
+        24, 25, 26,
+    })
+    if !matrix.Equal(dest, expected) {
+        t.Errorf("Add(a, column): wrong result")
+    }
+
+    matrix.Sub(dest, row, column)
+    expected = matrix.NewSharedGrid([]int{3, 2}, []int{
+         90, 190, 290,
+         80, 180, 280,
+    })
+    if !matrix.Equal(dest, expected) {
+        t.Errorf("Sub(row, column): wrong result")
+    }
+}
+
+func TestEinsum(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    a := randomMatrices(r, 7, 5)[0]
+    b := randomMatrices(r, 4, 7)[0]
+    square := randomMatrices(r, 6, 6)[0]
+
+    product := matrix.NewGrid[int](4, 5)
+    matrix.MatMul(product, a, b)
+    out := matrix.NewGrid[int](4, 5)
+    matrix.Einsum("ki,jk->ji", out, a, b)
+    if !matrix.Equal(out, product) {
+        t.Errorf("Einsum matrix product: wrong result")
+    }
+
+    transposed := matrix.NewGrid[int](5, 7)
+    matrix.Einsum("ij->ji", transposed, a)
+    if !matrix.Equal(transposed, matrix.Transpose(a)) {
+        t.Errorf("Einsum transpose: wrong result")
+    }
+
+    rowSums := matrix.NewGrid[int](5)
+    matrix.Einsum("ij->j", rowSums, a)
+    diagonal := matrix.NewGrid[int](6)
+    matrix.Einsum("ii->i", diagonal, square)
+    trace := matrix.NewGrid[int](1)
+    matrix.Einsum("ii->", trace, square)
+
+    expectedTrace := 0
+    for i := 0; i < 6; i++ {
+        x := square.Get(square.Index(i, i))
+        if diagonal.Get(i) != x {
+            t.Errorf("Einsum diagonal: got %d at %d, want %d", diagonal.Get(i), i, x)
+        }
+        expectedTrace += x
+    }
+    if trace.Get(0) != expectedTrace {
+        t.Errorf("Einsum trace: got %d, want %d", trace.Get(0), expectedTrace)
+    }
+    for y := 0; y < 5; y++ {
+        sum := 0
+        for x := 0; x < 7; x++ {
+            sum += a.Get(a.Index(x, y))
+        }
+        if rowSums.Get(y) != sum {
+            t.Errorf("Einsum row sum: got %d at %d, want %d", rowSums.Get(y), y, sum)
+        }
+    }
+
+    panics := func(f func()) (err error) {
+        defer func() { err, _ = recover().(error) }()
+        f()
+        return nil
+    }
+    if err := panics(func() { matrix.Einsum("ij,jk", out, a, b) }); !errors.Is(err, matrix.ErrEinsum) {
+        t.Errorf("expected ErrEinsum, got %v", err)
+    }
+    if err := panics(func() { matrix.Einsum("ij,ij->ij", out, a, b) }); !errors.Is(err, matrix.ErrShape) {
+        t.Errorf("expected ErrShape, got %v", err)
+    }
+}
+
+func TestArithmetic_shape(t *testing.T) {
+    panics := func(f func()) (result bool) {
+        defer func() { result = recover() == matrix.ErrShape }()
+        f()
+        return false
+    }
+    a := matrix.NewGrid[int](2, 3)
+    b := matrix.NewGrid[int](3, 2)
+    if !panics(func() { matrix.Add(a, a, b) }) { t.Errorf("expected Add to panic") }
+    if !panics(func() { matrix.Scale(a, b, 2) }) { t.Errorf("expected Scale to panic") }
+    if !panics(func() { matrix.MatMul(a, a, b) }) { t.Errorf("expected MatMul to panic") }
+}
+
+func TestTranspose(t *testing.T) {
+    m := matrix.NewSharedGrid([]int{3, 2}, []int{
+        1, 2, 3,
+        4, 5, 6,
+    })
+    tr := matrix.Transpose(m)
+    if (tr.Length(0) != 2) || (tr.Length(1) != 3) {
+        t.Fatalf("expected a 2×3 transpose, got %d×%d", tr.Length(0), tr.Length(1))
+    }
+    for x := 0; x < 3; x++ {
+        for y := 0; y < 2; y++ {
+            if tr.Get(tr.Index(y, x)) != m.Get(m.Index(x, y)) {
+                t.Errorf("wrong value at transposed (%d, %d)", y, x)
+            }
+        }
+    }
+
+    // the view shares memory with the parent
+    tr.Set(tr.Index(1, 0), 20)
+    if m.Get(m.Index(0, 1)) != 20 {
+        t.Errorf("expected Set on the view to modify the parent")
+    }
+
+    // transposing twice is the identity
+    if !equal(matrix.Transpose(tr), m) {
+        t.Errorf("expected Transpose(Transpose(m)) == m")
+    }
+}
+
+func TestPermute(t *testing.T) {
+    m := matrix.NewGrid[int](2, 3, 4)
+    for i := 0; i < m.Size(); i++ {
+        m.Set(i, i)
+    }
+
+    p := matrix.Permute(m, 2, 0, 1)
+    if (p.Length(0) != 4) || (p.Length(1) != 2) || (p.Length(2) != 3) {
+        t.Fatalf("wrong permuted shape")
+    }
+    for x := 0; x < 2; x++ {
+        for y := 0; y < 3; y++ {
+            for z := 0; z < 4; z++ {
+                if p.Get(p.Index(z, x, y)) != m.Get(m.Index(x, y, z)) {
+                    t.Errorf("wrong value at permuted (%d, %d, %d)", z, x, y)
+                }
+            }
+        }
+    }
+
+    panics := func(f func()) (result bool) {
+        defer func() { result = recover() == matrix.ErrShape }()
+        f()
+        return false
+    }
+    for _, order := range [][]int{{0, 1}, {0, 1, 1}, {0, 1, 3}, {0, 1, 2, 3}} {
+        if !panics(func() { matrix.Permute(m, order...) }) {
+            t.Errorf("expected Permute(m, %v) to panic", order)
+        }
+    }
+}
+
+func TestWriteRead(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    ms := randomMatrices(r, 70, 5)
+    ms = append(ms,
+        matrix.NewDiagonal[int](3, 4),
+        matrix.NewBit(3, 50),
+        matrix.Transpose(ms[0]),
+    )
+    ms[4].Set(ms[4].Index(2, 2, 2), 7)
+    ms[5].Set(99, 1)
+
+    for i, m := range ms {
+        var buf bytes.Buffer
+        if err := matrix.Write(&buf, m); err != nil {
+            t.Errorf("test %d: write error: %v", i, err)
+            continue
+        }
+        got, err := matrix.Read[int](&buf, 0)
+        if err != nil {
+            t.Errorf("test %d: read error: %v", i, err)
+            continue
+        }
+        if !equal(m, got) {
+            t.Errorf("test %d: matrices are not equal", i)
+        }
+        if _, ok := m.(matrix.View[int]); ok { continue }
+        if fmt.Sprintf("%T", m) != fmt.Sprintf("%T", got) {
+            t.Errorf("test %d: got %T, expected %T", i, got, m)
+        }
+    }
+}
+
+func TestWriteRead_types(t *testing.T) {
+    type point struct { X, Y float32 }
+
+    b := matrix.NewBool(10, 10)
+    b.Set(37, true)
+    s := matrix.NewHashmap[string](3, 3)
+    s.Set(4, "hello")
+    p := matrix.NewGrid[point](2, 2)
+    p.Set(3, point{1.5, -2})
+
+    var buf bytes.Buffer
+    if err := matrix.Write(&buf, b); err != nil { t.Fatal(err) }
+    if err := matrix.Write(&buf, s); err != nil { t.Fatal(err) }
+    if err := matrix.Write(&buf, p); err != nil { t.Fatal(err) }
+
+    if got, err := matrix.Read[bool](&buf, 0); (err != nil) || !got.Get(37) || got.Get(36) {
+        t.Errorf("bool matrix: error %v", err)
+    }
+    if got, err := matrix.Read[string](&buf, 0); (err != nil) || (got.Get(4) != "hello") {
+        t.Errorf("string matrix: error %v", err)
+    }
+    if got, err := matrix.Read[point](&buf, 0); (err != nil) || (got.Get(3) != point{1.5, -2}) {
+        t.Errorf("struct matrix: error %v", err)
+    }
+    if buf.Len() != 0 {
+        t.Errorf("expected all input to be consumed")
+    }
+
+    if err := matrix.Write(&buf, matrix.NewGrid[*int](2, 2)); err != matrix.ErrUnsupported {
+        t.Errorf("got error %v, expected ErrUnsupported", err)
+    }
+}
+
+func TestRead_invalid(t *testing.T) {
+    m := matrix.NewGrid[int](8, 8)
+    m.Set(9, 3)
+    var buf bytes.Buffer
+    if err := matrix.Write(&buf, m); err != nil { t.Fatal(err) }
+    data := buf.Bytes()
+
+    corrupt := bytes.Clone(data)
+    corrupt[len(corrupt) - 20] ^= 1
+
+    // a CSR header (magic, tag, kind, size, dimensionality, lengths, count)
+    // with so many rows that the number of row offsets overflows
+    overflow := []byte("MatrixV1")
+    for _, x := range []uint64{6, 2, 8, 2, 1, math.MaxInt64, 0} {
+        overflow = binary.LittleEndian.AppendUint64(overflow, x)
+    }
+
+    tests := []struct {
+        name  string
+        read  func() error
+        check func(err error) bool
+    }{
+        {"empty", func() error {
+            _, err := matrix.Read[int](bytes.NewReader(nil), 0)
+            return err
+        }, func(err error) bool { return err == io.EOF }},
+        {"truncated", func() error {
+            _, err := matrix.Read[int](bytes.NewReader(data[:len(data) - 3]), 0)
+            return err
+        }, func(err error) bool { return err == io.ErrUnexpectedEOF }},
+        {"corrupt", func() error {
+            _, err := matrix.Read[int](bytes.NewReader(corrupt), 0)
+            return err
+        }, func(err error) bool { return errors.Is(err, matrix.ErrFormat) }},
+        {"overflow", func() error {
+            _, err := matrix.Read[int](bytes.NewReader(overflow), 0)
+            return err
+        }, func(err error) bool { return errors.Is(err, matrix.ErrFormat) }},
+        {"wrong type", func() error {
+            _, err := matrix.Read[float64](bytes.NewReader(data), 0)
+            return err
+        }, func(err error) bool { return errors.Is(err, matrix.ErrFormat) }},
+        {"limit", func() error {
+            _, err := matrix.Read[int](bytes.NewReader(data), 63)
+            return err
+        }, func(err error) bool { return errors.Is(err, matrix.ErrLimit) }},
+        {"within limit", func() error {
+            _, err := matrix.Read[int](bytes.NewReader(data), 64)
+            return err
+        }, func(err error) bool { return err == nil }},
+    }
+
+    for _, tt := range tests {
+        if err := tt.read(); !tt.check(err) {
+            t.Errorf("%s: unexpected error %v", tt.name, err)
+        }
+    }
+}
+
+func TestReadWriteCSV(t *testing.T) {
+    m := matrix.NewSharedGrid([]int{3, 2}, []float64{
+        1.5, 2, -3,
+          4, 0, 6.25,
+    })
+    format := func(x float64) string { return strconv.FormatFloat(x, 'g', -1, 64) }
+    parse := func(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+
+    var buf bytes.Buffer
+    header := []string{"a", "b", "c"}
+    if err := matrix.WriteCSV(&buf, m, format, matrix.CSVDelimiter('\t'), matrix.CSVHeader(&header)); err != nil {
+        t.Fatalf("WriteCSV: unexpected error: %v", err)
+    }
+    if expected := "a\tb\tc\n1.5\t2\t-3\n4\t0\t6.25\n"; buf.String() != expected {
+        t.Errorf("WriteCSV: got %q, expected %q", buf.String(), expected)
+    }
+
+    var gotHeader []string
+    got, err := matrix.ReadCSV(&buf, parse, matrix.CSVDelimiter('\t'), matrix.CSVHeader(&gotHeader))
+    if err != nil {
+        t.Fatalf("ReadCSV: unexpected error: %v", err)
+    }
+    if !matrix.Equal(got, m) {
+        t.Errorf("ReadCSV: matrix does not match")
+    }
+    if !slices.Equal(gotHeader, header) {
+        t.Errorf("ReadCSV: got header %v, expected %v", gotHeader, header)
+    }
+
+    for _, input := range []string{"", "1,2\n3\n", "1,2\n3,x\n"} {
+        if _, err := matrix.ReadCSV(strings.NewReader(input), parse); err == nil {
+            t.Errorf("ReadCSV(%q): expected error", input)
+        }
+    }
+    if _, err := matrix.ReadCSV(strings.NewReader("1,2\n3,x\n"), parse); !errors.Is(err, strconv.ErrSyntax) {
+        t.Errorf("ReadCSV: expected error to wrap parse error, got %v", err)
+    }
+}
+
+func TestJSON(t *testing.T) {
+    grid := matrix.NewSharedGrid([]int{3, 2}, []int{1, 0, 2, 0, 0, 3})
+    hashmap := matrix.NewHashmap[int](3, 2)
+    matrix.Copy(hashmap, grid)
+    bools := matrix.NewBool(3, 2)
+    bools.Set(1, true)
+    bits := matrix.NewBit(3, 2)
+    bits.Set(4, 1)
+    diagonal := matrix.NewSharedDiagonal(2, []int{4, 5})
+
+    // checks the encoding of m, and the result of decoding it
+    check := func(name string, m any, expected string, decoded any, err error, equal bool) {
+        data, _ := json.Marshal(m)
+        if string(data) != expected {
+            t.Errorf("%s: got %s, expected %s", name, data, expected)
+        }
+        if err != nil {
+            t.Errorf("%s: unexpected error: %v", name, err)
+        } else if !equal {
+            t.Errorf("%s: decoded %v does not match", name, decoded)
+        }
+    }
+
+    {
+        var got matrix.Grid[int]
+        err := json.Unmarshal(must.Result(json.Marshal(grid)), &got)
+        check("Grid", grid, `{"lengths":[3,2],"values":[1,0,2,0,0,3]}`, got, err, matrix.Equal[int](got, grid))
+    }
+    {
+        var got matrix.Hashmap[int]
+        err := json.Unmarshal(must.Result(json.Marshal(hashmap)), &got)
+        check("Hashmap", hashmap, `{"lengths":[3,2],"values":{"0":1,"2":2,"5":3}}`, got, err, matrix.Equal[int](got, hashmap))
+    }
+    {
+        var got matrix.Bool
+        err := json.Unmarshal(must.Result(json.Marshal(bools)), &got)
+        check("Bool", bools, `{"lengths":[3,2],"values":[false,true,false,false,false,false]}`, got, err, matrix.Equal[bool](got, bools))
+    }
+    {
+        var got matrix.Bit
+        err := json.Unmarshal(must.Result(json.Marshal(bits)), &got)
+        check("Bit", bits, `{"lengths":[3,2],"values":[0,0,0,0,1,0]}`, got, err, matrix.Equal[int](got, bits))
+    }
+    {
+        var got matrix.Diagonal[int]
+        err := json.Unmarshal(must.Result(json.Marshal(diagonal)), &got)
+        check("Diagonal", diagonal, `{"dimensionality":2,"values":[4,5]}`, got, err, matrix.Equal[int](got, diagonal))
+    }
+
+    for _, input := range []string{
+        `{"lengths":[3,2],"values":[1,2,3]}`,
+        `{"lengths":[3,0],"values":[]}`,
+        `{"lengths":[],"values":[]}`,
+    } {
+        var got matrix.Grid[int]
+        if err := json.Unmarshal([]byte(input), &got); !errors.Is(err, matrix.ErrFormat) {
+            t.Errorf("Grid %s: expected ErrFormat, got %v", input, err)
+        }
+    }
+    var bit matrix.Bit
+    if err := json.Unmarshal([]byte(`{"lengths":[2],"values":[0,2]}`), &bit); !errors.Is(err, matrix.ErrFormat) {
+        t.Errorf("Bit: expected ErrFormat, got %v", err)
+    }
+    var sparse matrix.Hashmap[int]
+    if err := json.Unmarshal([]byte(`{"lengths":[2],"values":{"2":1}}`), &sparse); !errors.Is(err, matrix.ErrFormat) {
+        t.Errorf("Hashmap: expected ErrFormat, got %v", err)
+    }
+}
+
+func TestSynchronized(t *testing.T) {
+    tests := []struct {
+        name string
+        matrix matrix.M[bool]
+    }{
+        {"synchronized bool", matrix.Synchronized(matrix.NewBool(100, 100))},
+        {"synchronized hashmap", matrix.Synchronized(matrix.NewHashmap[bool](100, 100))},
+        {"sharded bool", matrix.SynchronizedSharded(matrix.NewBool(100, 100), 8)},
+        {"sharded grid", matrix.SynchronizedSharded(matrix.NewGrid[bool](100, 100), 8)},
+        {"sharded hashmap", matrix.SynchronizedSharded(matrix.NewHashmap[bool](100, 100), 8)},
+    }
+
+    for _, tt := range tests {
+        // each goroutine sets every element in its own rows
+        m := tt.matrix
+        var wg sync.WaitGroup
+        for g := 0; g < 10; g++ {
+            wg.Add(1)
+            go func(g int) {
+                defer wg.Done()
+                for y := g; y < 100; y += 10 {
+                    for x := 0; x < 100; x++ {
+                        m.Set(m.Index(x, y), true)
+                        _ = m.Get(m.Index(y, x))
+                    }
+                    m.Next(-1)
+                }
+            }(g)
+        }
+        wg.Wait()
+
+        count := 0
+        for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {
+            count++
+        }
+        if count != 100 * 100 {
+            t.Errorf("%s: got %d true elements, expected %d", tt.name, count, 100 * 100)
+        }
+    }
+}
+
+func TestTriangular(t *testing.T) {
+    const n = 9
+    tests := []struct {
+        name string
+        matrix matrix.M[int]
+        allowed func(x, y int) bool
+        mirror bool
+    }{
+        {"lower", matrix.NewLowerTriangular[int](n), func(x, y int) bool { return x <= y }, false},
+        {"upper", matrix.NewUpperTriangular[int](n), func(x, y int) bool { return x >= y }, false},
+        {"symmetric", matrix.NewSymmetric[int](n), func(x, y int) bool { return true }, true},
+    }
+
+    r := rand.New(rand.NewSource(0))
+    for _, tt := range tests {
+        m := tt.matrix
+        model := matrix.NewGrid[int](n, n)
+        for i := 0; i < 50; i++ {
+            x, y, value := r.Intn(n), r.Intn(n), r.Intn(5)
+            if !tt.allowed(x, y) { continue }
+            m.Set(m.Index(x, y), value)
+            model.Set(model.Index(x, y), value)
+            if tt.mirror { model.Set(model.Index(y, x), value) }
+        }
+
+        if !equal(m, model) {
+            t.Errorf("%s: matrix does not match model", tt.name)
+        }
+        var got, expected []int
+        for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {
+            got = append(got, idx)
+        }
+        for idx, ok := model.Next(-1); ok; idx, ok = model.Next(idx) {
+            expected = append(expected, idx)
+        }
+        if !slices.Equal(got, expected) {
+            t.Errorf("%s: Next got %v, expected %v", tt.name, got, expected)
+        }
+
+        m.Clear()
+        if _, ok := m.Next(-1); ok {
+            t.Errorf("%s: expected empty matrix after Clear", tt.name)
+        }
+    }
+
+    for _, m := range []matrix.M[int]{matrix.NewLowerTriangular[int](n), matrix.NewUpperTriangular[int](n)} {
+        m.Set(m.Index(1, 2), 0) // zero is allowed anywhere
+        m.Set(m.Index(2, 1), 0)
+        func() {
+            defer func() {
+                if recover() == nil { t.Errorf("%T: expected panic", m) }
+            }()
+            m.Set(m.Index(1, 2), 1)
+            m.Set(m.Index(2, 1), 1)
+        }()
+    }
+}
+
+func TestEqual_Diff(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    as := randomMatrices(r, 7, 5)
+    bs := randomMatrices(r, 7, 5)
+    as = append(as, matrix.Transpose(matrix.Transpose(as[0])))
+    as = append(as, matrix.Synchronized(as[3]), matrix.Const(as[3])) // wrapped CSC
+    bs = append(bs, matrix.NewGrid[int](7, 5))
+    matrix.Copy(bs[len(bs) - 1], as[0])
+
+    for i, a := range as {
+        for j, b := range bs {
+            var expected []iter.Pair[int, [2]int]
+            for idx := 0; idx < a.Size(); idx++ {
+                if a.Get(idx) == b.Get(idx) { continue }
+                expected = append(expected, iter.Pair[int, [2]int]{Key: idx, Value: [2]int{a.Get(idx), b.Get(idx)}})
+            }
+
+            if got := matrix.Equal(a, b); got != (len(expected) == 0) {
+                t.Errorf("Equal(%d, %d): got %t, expected %t", i, j, got, len(expected) == 0)
+            }
+            got := iter.ToSlice(matrix.Diff(a, b))
+            if !slices.Equal(got, expected) {
+                t.Errorf("Diff(%d, %d): got %v, expected %v", i, j, got, expected)
+            }
+        }
+    }
+
+    if matrix.Equal(matrix.NewGrid[int](7, 5), matrix.NewGrid[int](5, 7)) {
+        t.Errorf("expected matrices of different shapes not to be equal")
+    }
+
+    x, y := matrix.NewBit(100), matrix.NewBit(100)
+    x.Set(70, 1)
+    if matrix.Equal(x, y) { t.Errorf("expected bit matrices not to be equal") }
+    y.Set(70, 1)
+    if !matrix.Equal(x, y) { t.Errorf("expected bit matrices to be equal") }
+}
+
+func TestFill_MapValues_Reduce(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    ms := randomMatrices(r, 70, 5)
+    bits := matrix.NewBit(70, 5)
+    for i := 0; i < 100; i++ {
+        bits.Set(r.Intn(bits.Size()), 1)
+    }
+    transposed := matrix.NewGrid[int](5, 70)
+    for i := 0; i < 100; i++ {
+        transposed.Set(r.Intn(transposed.Size()), r.Intn(9))
+    }
+    ms = append(ms, bits, matrix.Transpose(transposed))
+
+    for i, m := range ms {
+        // Reduce
+        expectedSum, expectedOrder := 0, 0
+        for idx := 0; idx < m.Size(); idx++ {
+            expectedSum += m.Get(idx)
+            expectedOrder = (expectedOrder * 31 + m.Get(idx)) % 1000003
+        }
+        if got := matrix.Reduce(m, 0, func(a, b int) int { return a + b }); got != expectedSum {
+            t.Errorf("Reduce(%d): got sum %d, expected %d", i, got, expectedSum)
+        }
+        order := func(a, b int) int { return (a * 31 + b) % 1000003 }
+        if got := matrix.Reduce(m, 0, order); got != expectedOrder {
+            t.Errorf("Reduce(%d): got %d, expected %d (order)", i, got, expectedOrder)
+        }
+
+        // MapValues, to the same type and to a different type
+        for _, f := range []func(int) int {
+            func(x int) int { return x * 2 },
+            func(x int) int { return x + 1 },
+        } {
+            dest := matrix.NewHashmap[int](70, 5)
+            matrix.MapValues(dest, m, f)
+            for idx := 0; idx < m.Size(); idx++ {
+                if dest.Get(idx) != f(m.Get(idx)) {
+                    t.Errorf("MapValues(%d): got %d, expected %d at %d", i, dest.Get(idx), f(m.Get(idx)), idx)
+                    break
+                }
+            }
+        }
+        positive := matrix.NewBool(70, 5)
+        matrix.MapValues(positive, m, func(x int) bool { return x > 0 })
+        for idx := 0; idx < m.Size(); idx++ {
+            if positive.Get(idx) != (m.Get(idx) > 0) {
+                t.Errorf("MapValues(%d): wrong bool value at %d", i, idx)
+                break
+            }
+        }
+
+        // Fill
+        matrix.Fill(m, 3)
+        for idx := 0; idx < m.Size(); idx++ {
+            if (m.Get(idx) != 3) && (m.Get(idx) != 1) { // a Bit can only store 1
+                t.Errorf("Fill(%d): got %d at %d", i, m.Get(idx), idx)
+                break
+            }
+        }
+        matrix.Fill(m, 0)
+        if _, ok := m.Next(-1); ok {
+            t.Errorf("Fill(%d): expected zero matrix", i)
+        }
+    }
+
+    b := matrix.NewBool(70, 5)
+    matrix.Fill(b, true)
+    if got := matrix.Reduce(b, 0, func(n int, x bool) int { if x { n++ }; return n }); got != 350 {
+        t.Errorf("Fill(bool): got %d true elements, expected 350", got)
+    }
+}
+
+func TestReduceAxis(t *testing.T) {
+    m := matrix.NewSharedGrid([]int{3, 2, 2}, []int{
+        1, 2, 3,
+        4, 5, 6,
+
+        7, 8, 9,
+        1, 2, 3,
+    })
+    sum := func(acc int, v int) int { return acc + v }
+
+    rows := matrix.NewGrid[int](2, 2)
+    matrix.ReduceAxis(rows, m, 0, 0, sum)
+    if !matrix.Equal(rows, matrix.NewSharedGrid([]int{2, 2}, []int{6, 15, 24, 6})) {
+        t.Errorf("ReduceAxis(0): wrong result")
+    }
+
+    columns := matrix.NewGrid[int](3, 2)
+    matrix.ReduceAxis(columns, m, 1, 0, func(acc int, v int) int { return max(acc, v) })
+    if !matrix.Equal(columns, matrix.NewSharedGrid([]int{3, 2}, []int{4, 5, 6, 7, 8, 9})) {
+        t.Errorf("ReduceAxis(1): wrong result")
+    }
+
+    planes := matrix.NewGrid[bool](3, 2)
+    matrix.ReduceAxis(planes, m, 2, false, func(acc bool, v int) bool { return acc || (v > 6) })
+    if !matrix.Equal(planes, matrix.NewSharedGrid([]int{3, 2}, []bool{true, true, true, false, false, false})) {
+        t.Errorf("ReduceAxis(2): wrong result")
+    }
+
+    total := matrix.NewGrid[int](1)
+    matrix.ReduceAxis(total, matrix.NewSharedGrid([]int{4}, []int{1, 2, 3, 4}), 0, 0, sum)
+    if total.Get(0) != 10 {
+        t.Errorf("ReduceAxis(1D): got %d, want 10", total.Get(0))
+    }
+}
+
+func TestPad(t *testing.T) {
+    m := matrix.NewGrid[int](3, 2)
+    for i := 0; i < m.Size(); i++ {
+        m.Set(i, i + 1)
+    }
+
+    p := matrix.Pad(m, []int{1, 2}, []int{2, 0}, -1)
+    if (p.Length(0) != 6) || (p.Length(1) != 4) {
+        t.Fatalf("got lengths %d×%d, expected 6×4", p.Length(0), p.Length(1))
+    }
+
+    var got []int
+    for i := 0; i < p.Size(); i++ {
+        got = append(got, p.Get(i))
+    }
+    expected := []int{
+        -1, -1, -1, -1, -1, -1,
+        -1, -1, -1, -1, -1, -1,
+        -1,  1,  2,  3, -1, -1,
+        -1,  4,  5,  6, -1, -1,
+    }
+    if !slices.Equal(got, expected) {
+        t.Errorf("got %v, expected %v", got, expected)
+    }
+
+    p.Set(p.Index(3, 3), 9)
+    if m.Get(m.Index(2, 1)) != 9 { t.Errorf("expected set to modify parent") }
+    p.Set(p.Index(0, 0), 9) // ignored
+    if p.Get(p.Index(0, 0)) != -1 { t.Errorf("expected set in border to be ignored") }
+
+    if idx, ok := matrix.Pad(matrix.NewHashmap[int](3, 3), []int{1, 1}, []int{1, 1}, 0).Next(-1); ok {
+        t.Errorf("expected no non-zero elements, got index %d", idx)
+    }
+
+    strict := matrix.PadStrict(m, nil, []int{1, 1}, 0)
+    strict.Set(strict.Index(3, 0), 0) // allowed
+    func() {
+        defer func() {
+            if recover() == nil { t.Errorf("expected panic") }
+        }()
+        strict.Set(strict.Index(3, 0), 1)
+    }()
+
+    idx := p.Index(3, 3)
+    if allocs := testing.AllocsPerRun(10, func() { p.Get(idx) }); allocs != 0 {
+        t.Errorf("Get: got %.0f allocations, expected none", allocs)
+    }
+
+    // Clear clears the parent, but the border keeps its fill value
+    p.Clear()
+    if (m.Get(0) != 0) || (p.Get(p.Index(1, 2)) != 0) || (p.Get(0) != -1) {
+        t.Errorf("unexpected values after Clear")
+    }
+}
+
+func TestTile(t *testing.T) {
+    m := matrix.NewGrid[int](3, 2)
+    for i := 0; i < m.Size(); i++ {
+        m.Set(i, i + 1)
+    }
+
+    tiled := matrix.Tile(m, 7, 3)
+    var got []int
+    for i := 0; i < tiled.Size(); i++ {
+        got = append(got, tiled.Get(i))
+    }
+    expected := []int{
+        1, 2, 3, 1, 2, 3, 1,
+        4, 5, 6, 4, 5, 6, 4,
+        1, 2, 3, 1, 2, 3, 1,
+    }
+    if !slices.Equal(got, expected) {
+        t.Errorf("got %v, expected %v", got, expected)
+    }
+
+    // a toroidal neighbour
+    tiled.Set(tiled.Index(3, 2), 9)
+    if (m.Get(0) != 9) || (tiled.Get(tiled.Index(6, 0)) != 9) {
+        t.Errorf("expected set to modify parent and every repeat")
+    }
+}
+
+func TestNewView_nested(t *testing.T) {
+    m := matrix.NewGrid[int](3, 4)
+    for i := 0; i < m.Size(); i++ {
+        m.Set(i, i + 1)
+    }
+
+    // a crop of a mirrored view
+    mirrored := matrix.Sample(m, "-x y")
+    v := matrix.Crop(mirrored, mirrored.Index(1, 1), 2, 2)
+
+    var got []int
+    for i := 0; i < v.Size(); i++ {
+        got = append(got, v.Get(i))
+    }
+    expected := []int{
+        5, 4,
+        8, 7,
+    }
+    if !slices.Equal(got, expected) {
+        t.Errorf("got %v, expected %v", got, expected)
+    }
+
+    v.Set(v.Index(1, 1), 99)
+    if m.Get(m.Index(0, 2)) != 99 {
+        t.Errorf("expected set to modify parent")
+    }
+}
+
+func TestNewSharedGridWithStrides(t *testing.T) {
+    // a 3x2 matrix in column-major order
+    values := []int{1, 4, 2, 5, 3, 6}
+    m := matrix.NewSharedGridWithStrides([]int{3, 2}, []int{2, 1}, values)
+
+    expected := matrix.NewSharedGrid([]int{3, 2}, []int{
+        1, 2, 3,
+        4, 5, 6,
+    })
+    if !matrix.Equal(m, expected) {
+        t.Errorf("column-major matrix not equal to row-major matrix")
+    }
+
+    m.Set(m.Index(2, 0), 9)
+    if values[4] != 9 {
+        t.Errorf("expected set to modify shared values, got %v", values)
+    }
+}
+
+func TestResize(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    shapes := [][2]int{{9, 4}, {13, 7}, {5, 2}, {11, 1}, {3, 9}}
+    squares := []int{6, 9, 4, 4, 1}
+
+    for _, lengths := range shapes {
+        ms := randomMatrices(r, 7, 5)
+        bits := matrix.NewBit(7, 5)
+        for i := 0; i < 10; i++ {
+            bits.Set(r.Intn(bits.Size()), 1)
+        }
+        spare := matrix.NewSharedGrid([]int{7, 5}, make([]int, 100)) // grows in place
+        matrix.Copy(spare, ms[0])
+        ms = append(ms, bits, spare, matrix.Transpose(matrix.NewGrid[int](5, 7)))
+
+        for i, m := range ms {
+            model := matrix.NewGrid[int](lengths[0], lengths[1])
+            matrix.Copy(model, m)
+            name := fmt.Sprintf("%T", m)
+            got := matrix.Resize(m, lengths[0], lengths[1])
+            if !equal(got, model) {
+                t.Errorf("Resize(%d, %v): matrix does not match model", i, lengths)
+            }
+            if _, ok := m.(matrix.View[int]); !ok && (fmt.Sprintf("%T", got) != name) {
+                t.Errorf("Resize(%d, %v): got %T, expected %s", i, lengths, got, name)
+            }
+        }
+    }
+
+    for _, tt := range []struct {
+        m matrix.M[int]
+        x, y int // an element off the diagonal, if allowed
+    }{
+        {matrix.NewDiagonal[int](2, 6), 0, 0},
+        {matrix.NewLowerTriangular[int](6), 2, 3},
+        {matrix.NewUpperTriangular[int](6), 3, 2},
+        {matrix.NewSymmetric[int](6), 1, 3},
+    } {
+        m := tt.m
+        for i := 0; i < 6; i++ {
+            m.Set(m.Index(i, i), i + 1)
+        }
+        m.Set(m.Index(tt.x, tt.y), 10)
+        for _, n := range squares {
+            model := matrix.NewGrid[int](n, n)
+            matrix.Copy(model, m)
+            m = matrix.Resize(m, n, n)
+            if !equal(m, model) {
+                t.Errorf("Resize(%T, %d): matrix does not match model", m, n)
+            }
+        }
+    }
+}
+
+func TestCompact(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    ms := randomMatrices(r, 7, 5)
+
+    shrunk := matrix.Resize(matrix.NewGrid[int](9, 9), 7, 5)
+    matrix.Copy(shrunk, ms[0])
+    blocks := matrix.NewBlockSparse[int]([]int{2, 2}, 7, 5)
+    matrix.Copy(blocks, ms[0])
+    ms = append(ms,
+        shrunk,
+        blocks,
+        matrix.NewSharedHashmap([]int{7, 5}, map[int]int{1: 0, 2: 3, 8: 0}),
+        matrix.NewSharedCSR(7, 5, []int{0, 2, 2, 3, 3, 3}, []int{1, 4, 0}, []int{0, 6, 0}),
+    )
+
+    for i, m := range ms {
+        model := matrix.NewGrid[int](7, 5)
+        matrix.Copy(model, m)
+        name := fmt.Sprintf("%T", m)
+        got := matrix.Compact(m)
+        if !equal(got, model) {
+            t.Errorf("Compact(%d): matrix does not match model", i)
+        }
+        if fmt.Sprintf("%T", got) != name {
+            t.Errorf("Compact(%d): got %T, expected %s", i, got, name)
+        }
+    }
+
+    blocks = matrix.NewBlockSparse[int]([]int{2, 2}, 7, 5)
+    blocks.Set(0, 1)
+    blocks.Set(blocks.Index(6, 4), 1)
+    blocks.Set(blocks.Index(6, 4), 0) // leaves an empty block
+    if n := matrix.Compact(blocks).(matrix.BlockSparse[int]).Blocks(); n != 1 {
+        t.Errorf("Compact(BlockSparse): got %d blocks, expected 1", n)
+    }
+
+    csr := matrix.NewSharedCSR(7, 5, []int{0, 2, 2, 3, 3, 3}, []int{1, 4, 0}, []int{0, 6, 0})
+    if n := matrix.Compact(csr).(matrix.CSR[int]).NonZero(); n != 1 {
+        t.Errorf("Compact(CSR): got %d entries, expected 1", n)
+    }
+}
+
+func TestBlockSparse(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    tests := []struct {
+        blockLengths []int
+        lengths []int
+    }{
+        {[]int{4, 4}, []int{30, 17}},
+        {[]int{1, 8}, []int{5, 20}},
+        {[]int{3, 2, 5}, []int{7, 6, 11}},
+    }
+
+    for _, tt := range tests {
+        m := matrix.NewBlockSparse[int](tt.blockLengths, tt.lengths...)
+        model := matrix.NewGrid[int](tt.lengths...)
+
+        // clustered values
+        for c := 0; c < 3; c++ {
+            centre := r.Intn(m.Size())
+            for i := 0; i < 10; i++ {
+                idx := min(m.Size() - 1, centre + r.Intn(5))
+                value := r.Intn(3)
+                m.Set(idx, value)
+                model.Set(idx, value)
+            }
+        }
+
+        if !equal(m, model) {
+            t.Errorf("%v: matrix does not match model", tt.lengths)
+        }
+        var got, expected []int
+        for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {
+            got = append(got, idx)
+        }
+        for idx, ok := model.Next(-1); ok; idx, ok = model.Next(idx) {
+            expected = append(expected, idx)
+        }
+        if !slices.Equal(got, expected) {
+            t.Errorf("%v: Next got %v, expected %v", tt.lengths, got, expected)
+        }
+        allocs := testing.AllocsPerRun(10, func() {
+            m.Set(0, m.Get(m.Size() - 1))
+            for idx, ok := m.Next(-1); ok; idx, ok = m.Next(idx) {}
+        })
+        if allocs != 0 {
+            t.Errorf("%v: got %.0f allocations, expected none", tt.lengths, allocs)
+        }
+
+        if blocks := m.(matrix.BlockSparse[int]).Blocks(); (blocks == 0) || (blocks > 30) {
+            t.Errorf("%v: unexpected number of blocks %d", tt.lengths, blocks)
+        }
+        m.Clear()
+        if m.(matrix.BlockSparse[int]).Blocks() != 0 {
+            t.Errorf("%v: expected no blocks after Clear", tt.lengths)
+        }
+    }
+}
+
+func ExampleFormat() {
+    m := matrix.NewGrid[int](3, 2, 2)
+    for i := 0; i < m.Size(); i++ {
+        m.Set(i, i * i)
+    }
+    _ = matrix.Format(os.Stdout, m)
+
+    // Output:
+    // [:, :, 0]
+    // 0  1  4
+    // 9 16 25
+    //
+    // [:, :, 1]
+    // 36  49  64
+    // 81 100 121
+}
+
+func ExampleFormat_options() {
+    m := matrix.NewGrid[float64](8, 6)
+    for i := 0; i < m.Size(); i++ {
+        m.Set(i, float64(i) / 4)
+    }
+    fmt.Println("dense:")
+    _ = matrix.Format(os.Stdout, m,
+        matrix.FormatVerb("%.2f"),
+        matrix.FormatMaxWidth(4),
+        matrix.FormatMaxHeight(3),
+    )
+
+    fmt.Println("sparse:")
+    s := matrix.NewHashmap[string](100, 100, 100)
+    s.Set(s.Index(1, 2, 3), "hello")
+    s.Set(s.Index(99, 0, 50), "world")
+    _ = matrix.Format(os.Stdout, s, matrix.FormatSparse())
+
+    // Output:
+    // dense:
+    //  0.00  0.25 ...  1.50  1.75
+    //  2.00  2.25 ...  3.50  3.75
+    // ...
+    // 10.00 10.25 ... 11.50 11.75
+    // sparse:
+    // (1, 2, 3): hello
+    // (99, 0, 50): world
+}
+
+func TestBool_setAlgebra(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    random := func() matrix.Bool {
+        m := matrix.NewBool(13, 11).(matrix.Bool)
+        for i := 0; i < 60; i++ {
+            m.Set(r.Intn(m.Size()), true)
+        }
+        return m
+    }
+
+    tests := []struct {
+        name string
+        op func(a, b matrix.Bool)
+        f func(a, b bool) bool
+    }{
+        {"And",    matrix.Bool.And,    func(a, b bool) bool { return a && b }},
+        {"Or",     matrix.Bool.Or,     func(a, b bool) bool { return a || b }},
+        {"Xor",    matrix.Bool.Xor,    func(a, b bool) bool { return a != b }},
+        {"AndNot", matrix.Bool.AndNot, func(a, b bool) bool { return a && !b }},
+    }
+
+    for _, tt := range tests {
+        a, b := random(), random()
+        expected := matrix.NewBool(13, 11)
+        count := 0
+        for i := 0; i < a.Size(); i++ {
+            value := tt.f(a.Get(i), b.Get(i))
+            expected.Set(i, value)
+            if value { count++ }
+        }
+
+        tt.op(a, b)
+        if !matrix.Equal[bool](a, expected) {
+            t.Errorf("%s: matrix does not match expected", tt.name)
+        }
+        if a.PopCount() != count {
+            t.Errorf("%s: got PopCount %d, expected %d", tt.name, a.PopCount(), count)
+        }
+    }
+
+    x, y := matrix.NewBit(70).(matrix.Bit), matrix.NewBit(70).(matrix.Bit)
+    x.Set(3, 1); x.Set(68, 1); y.Set(68, 1)
+    x.Xor(y)
+    if (x.PopCount() != 1) || (x.Get(3) != 1) {
+        t.Errorf("Bit.Xor: unexpected result")
+    }
+
+    func() {
+        defer func() {
+            if recover() == nil { t.Errorf("expected panic") }
+        }()
+        x.And(matrix.NewBit(7, 10).(matrix.Bit))
+    }()
+}
+
+func TestBoolMul_TransitiveClosure(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    random := func(m matrix.M[int]) matrix.M[int] {
+        for i := 0; i < m.Size() / 8; i++ {
+            m.Set(r.Intn(m.Size()), 1)
+        }
+        return m
+    }
+    nonZero := func(m matrix.M[int]) matrix.M[int] {
+        result := matrix.NewGrid[int](m.Length(0), m.Length(1))
+        matrix.MapValues(result, m, func(x int) int { return min(x, 1) })
+        return result
+    }
+
+    // compared to MatMul
+    for _, dest := range []matrix.M[int]{matrix.NewBit(67, 70), matrix.NewGrid[int](67, 70)} {
+        a, b := random(matrix.NewBit(130, 70)), random(matrix.NewHashmap[int](67, 130))
+        expected := matrix.NewGrid[int](67, 70)
+        matrix.MatMul(expected, a, b)
+
+        matrix.BoolMul(dest, a, b)
+        if !equal(dest, nonZero(expected)) {
+            t.Errorf("BoolMul(%T): matrix does not match MatMul", dest)
+        }
+    }
+
+    // compared to repeated squaring
+    for _, n := range []int{1, 5, 70} {
+        adjacency := random(matrix.NewBit(n, n))
+        expected := matrix.NewGrid[int](n, n)
+        matrix.Copy(expected, adjacency)
+        for i := 0; i < 8; i++ {
+            step := matrix.NewGrid[int](n, n)
+            matrix.MatMul(step, expected, expected)
+            matrix.Add(expected, expected, step)
+            expected = nonZero(expected)
+        }
+
+        for _, dest := range []matrix.M[int]{adjacency, matrix.NewHashmap[int](n, n)} {
+            matrix.TransitiveClosure(dest, adjacency)
+            if !equal(dest, expected) {
+                t.Errorf("TransitiveClosure(%d, %T): matrix does not match expected", n, dest)
+            }
+        }
+    }
+
+    b := matrix.NewBool(3, 3)
+    b.Set(b.Index(1, 0), true) // 0 -> 1
+    b.Set(b.Index(2, 1), true) // 1 -> 2
+    matrix.TransitiveClosure(b, b)
+    if !b.Get(b.Index(2, 0)) || b.Get(b.Index(0, 2)) {
+        t.Errorf("TransitiveClosure(Bool): wrong result")
+    }
+}
+
+func TestKronecker_Outer(t *testing.T) {
+    a := matrix.NewSharedGrid([]int{2, 2}, []int{
+        1, 2,
+        0, 3,
+    })
+    b := matrix.NewHashmap[int](3, 2)
+    b.Set(b.Index(0, 0), 1)
+    b.Set(b.Index(2, 1), 5)
+
+    k := matrix.NewGrid[int](6, 4)
+    matrix.Kronecker(k, a, b)
+    expected := []int{
+        1, 0, 0, 2, 0,  0,
+        0, 0, 5, 0, 0, 10,
+        0, 0, 0, 3, 0,  0,
+        0, 0, 0, 0, 0, 15,
+    }
+    if got := matrix.Reduce(k, []int(nil), func(xs []int, x int) []int { return append(xs, x) }); !slices.Equal(got, expected) {
+        t.Errorf("Kronecker: got %v, expected %v", got, expected)
+    }
+
+    u := matrix.NewSharedGrid([]int{3}, []int{1, 0, 2})
+    v := matrix.NewSharedGrid([]int{2}, []int{3, 4})
+    o := matrix.NewHashmap[int](2, 3)
+    matrix.Outer(o, u, v)
+    expected = []int{
+        3, 4,
+        0, 0,
+        6, 8,
+    }
+    if got := matrix.Reduce(o, []int(nil), func(xs []int, x int) []int { return append(xs, x) }); !slices.Equal(got, expected) {
+        t.Errorf("Outer: got %v, expected %v", got, expected)
+    }
+
+    func() {
+        defer func() {
+            if recover() == nil { t.Errorf("expected panic") }
+        }()
+        matrix.Outer(matrix.NewGrid[int](3, 2), u, v)
+    }()
+}
+
+func TestCopyAt_Paste(t *testing.T) {
+    all := func(m matrix.M[int]) []int {
+        return matrix.Reduce(m, []int(nil), func(xs []int, x int) []int { return append(xs, x) })
+    }
+    src := matrix.NewSharedGrid([]int{2, 2}, []int{
+        1, 2,
+        3, 0,
+    })
+
+    dest := matrix.NewGrid[int](4, 3)
+    matrix.Fill(dest, 9)
+    matrix.CopyAt(dest, src, 1, 1)
+    matrix.CopyAt(dest, src, -1, 2) // partly outside
+    expected := []int{
+        9, 9, 9, 9,
+        9, 1, 2, 9,
+        2, 3, 0, 9,
+    }
+    if got := all(dest); !slices.Equal(got, expected) {
+        t.Errorf("CopyAt: got %v, expected %v", got, expected)
+    }
+
+    dest = matrix.NewGrid[int](4, 3)
+    region := dimensions.Crop(dest, dest.Index(2, 1), 2, 2)
+    matrix.Paste(dest, src, region)
+    expected = []int{
+        0, 0, 0, 0,
+        0, 0, 1, 2,
+        0, 0, 3, 0,
+    }
+    if got := all(dest); !slices.Equal(got, expected) {
+        t.Errorf("Paste: got %v, expected %v", got, expected)
+    }
+
+    func() {
+        defer func() {
+            if recover() == nil { t.Errorf("expected panic") }
+        }()
+        matrix.Paste(dest, src, dimensions.Crop(dest, 0, 3, 2))
+    }()
+}
@@ -0,0 +1,102 @@
+package matrix
+
+import (
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+)
+
+// padded implements M as a view of a parent matrix surrounded by a border
+// of elements with a fixed value.
+type padded[T comparable] struct {
+    dimensions.D
+    parent M[T]
+    before []int
+    fill   T
+    strict bool
+}
+
+func newPadded[T comparable](parent M[T], before, after []int, fill T, strict bool) M[T] {
+    dims := parent.Dimensionality()
+    if before == nil { before = make([]int, dims) }
+    if after  == nil { after  = make([]int, dims) }
+    if (len(before) != dims) || (len(after) != dims) { panic(ErrShape) }
+
+    lengths := make([]int, dims)
+    for i := range lengths {
+        if (before[i] < 0) || (after[i] < 0) { panic(ErrShape) }
+        lengths[i] = before[i] + parent.Length(i) + after[i]
+    }
+    return padded[T]{
+        D:      dimensions.New(lengths...),
+        parent: parent,
+        before: append([]int{}, before...), // don't share memory
+        fill:   fill,
+        strict: strict,
+    }
+}
+
+// Pad returns a view of parent that is larger than the parent, with a
+// border along each axis i of before[i] elements before the start of the
+// parent, and after[i] elements after the end of the parent. Every element in
+// the border has the value fill. This allows, for example, code that visits
+// the neighbours of each element to treat the edges of a matrix the same as
+// its interior.
+//
+// Setting an element in the border has no effect. See also [PadStrict].
+//
+// The slices before and after must have a length equal to the
+// dimensionality of the parent, or be nil, meaning no padding. Panics with
+// [ErrShape] if this is not the case, or if any padding is negative.
+//
+// The view does not copy the parent. Clearing the view clears the parent, but
+// not the border. Unlike the Clear method of other matrices, this means that
+// if fill is not the zero value, the border still reads as fill after Clear.
+func Pad[T comparable](parent M[T], before, after []int, fill T) M[T] {
+    return newPadded(parent, before, after, fill, false)
+}
+
+// PadStrict is like [Pad], except that setting an element in the border to
+// any value other than fill panics.
+func PadStrict[T comparable](parent M[T], before, after []int, fill T) M[T] {
+    return newPadded(parent, before, after, fill, true)
+}
+
+    // parentIndex returns the index in the parent of an index in the view,
+    // or false if it is in the border. As every [dimensions.D] maps offsets
+    // in row-major order, this is computed directly, without allocating.
+    func (p padded[T]) parentIndex(idx int) (int, bool) {
+        result, stride := 0, 1
+        for i := 0; i < p.Dimensionality(); i++ {
+            length, parentLength := p.Length(i), p.parent.Length(i)
+            offset := (idx % length) - p.before[i]
+            idx /= length
+            if (offset < 0) || (offset >= parentLength) { return 0, false }
+            result += offset * stride
+            stride *= parentLength
+        }
+        return result, true
+    }
+
+    func (p padded[T]) Get(idx int) T {
+        if i, ok := p.parentIndex(idx); ok { return p.parent.Get(i) }
+        return p.fill
+    }
+
+    func (p padded[T]) Set(idx int, value T) {
+        if i, ok := p.parentIndex(idx); ok {
+            p.parent.Set(i, value)
+        } else if p.strict && (value != p.fill) {
+            panic("can not set an element in the border of a padded matrix")
+        }
+    }
+
+    func (p padded[T]) Next(idx int) (int, bool) {
+        var zero T
+        for i := max(idx + 1, 0); i < p.Size(); i++ {
+            if p.Get(i) != zero { return i, true }
+        }
+        return 0, false
+    }
+
+    func (p padded[T]) Clear() {
+        p.parent.Clear()
+    }
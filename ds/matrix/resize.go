@@ -0,0 +1,226 @@
+package matrix
+
+import (
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+    "github.com/tawesoft/golib/v2/internal/words"
+)
+
+// resizeSlice returns s with length n, reusing its storage if it has the
+// capacity. Any elements past the end of the previous length, or past n, are
+// cleared.
+func resizeSlice[T any](s []T, n int) []T {
+    switch {
+        case n <= len(s):
+            clear(s[n:]) // allow garbage collection
+            return s[:n]
+        case n <= cap(s):
+            length := len(s)
+            s = s[:n]
+            clear(s[length:])
+            return s
+        default:
+            return append(s, make([]T, n - len(s))...)
+    }
+}
+
+// trimSlice returns the first n elements of s, copied to a new slice if s
+// has any spare capacity, so that the rest can be garbage collected.
+func trimSlice[T any](s []T, n int) []T {
+    if cap(s) == n { return s[:n] }
+    return append(make([]T, 0, n), s[:n]...)
+}
+
+// resizeDirection returns true if every length of new is greater than or
+// equal to the same length of old, or false if every length is less than or
+// equal, and the second return value is false if neither is true.
+func resizeDirection(old, new dimensions.D) (grow bool, ok bool) {
+    grow, shrink := true, true
+    for i := 0; i < old.Dimensionality(); i++ {
+        grow   = grow   && (new.Length(i) >= old.Length(i))
+        shrink = shrink && (new.Length(i) <= old.Length(i))
+    }
+    return grow, grow || shrink
+}
+
+// remap moves each element of storage indexed in the shape old to the same
+// offsets in the shape new, in place, and clears elements outside the new
+// shape. The storage must have room for the larger of the two shapes, with
+// every element past the end of the old shape cleared.
+//
+// When growing, an element never moves to a lower index, so elements are
+// moved starting from the end. When shrinking, an element never moves to a
+// higher index, so elements are moved starting from the start. Either way,
+// an element is never overwritten before it is moved.
+func remap[T comparable](old, new dimensions.D, grow bool, get func(int) T, set func(int, T)) {
+    var zero T
+    offsets := make([]int, old.Dimensionality())
+    move := func(i int) {
+        value := get(i)
+        if value == zero { return }
+        set(i, zero)
+        old.Offsets(offsets, i)
+        if new.Contains(offsets...) { set(new.Index(offsets...), value) }
+    }
+
+    if grow {
+        for i := old.Size() - 1; i >= 0; i-- { move(i) }
+    } else {
+        for i := 0; i < old.Size(); i++ { move(i) }
+    }
+}
+
+// square returns the length of each side of a shape that is the same length
+// along every dimension, or panics with ErrShape.
+func square(d dimensions.D) int {
+    for i := 1; i < d.Dimensionality(); i++ {
+        if d.Length(i) != d.Length(0) { panic(ErrShape) }
+    }
+    return d.Length(0)
+}
+
+// Resize returns a matrix with the given lengths along each axis, and with
+// the same implementation and values as the matrix m. Each element keeps the
+// same offsets along each axis. Any elements at offsets outside the new shape
+// are discarded, and any new elements are the zero value.
+//
+// Where possible, the new matrix reuses the storage of m, which must not be
+// used afterwards. For example, a [Grid], [Bool], or [Bit] is resized in
+// place if it has the capacity, and if it is growing or shrinking along
+// every axis; and a [Diagonal], [CSR], or [CSC] is always resized in place.
+// Other implementations, such as a [View], are copied to a new [Grid].
+//
+// The number of lengths must be the same as the dimensionality of m. A
+// [Diagonal], [LowerTriangular], [UpperTriangular], or [Symmetric] matrix
+// must have the same length along every axis. Otherwise, panics with
+// [ErrShape].
+func Resize[T comparable](m M[T], lengths ... int) M[T] {
+    if len(lengths) != m.Dimensionality() { panic(ErrShape) }
+    d := dimensions.New(lengths...)
+
+    switch x := any(m).(type) {
+        case Grid[T]:
+            grow, ok := resizeDirection(x, d)
+            if !ok || (d.Size() > cap(x.values)) { break }
+            values := resizeSlice(x.values[:x.Size()], max(x.Size(), d.Size()))
+            remap(x, d, grow,
+                func(i int) T { return values[i] },
+                func(i int, value T) { values[i] = value },
+            )
+            return Grid[T]{D: d, values: resizeSlice(values, d.Size())}
+
+        case Bool:
+            grow, ok := resizeDirection(x, d)
+            if !ok || (words.Words(d.Size()) > cap(x.buckets)) { break }
+            b := Bool{D: x.D, buckets: resizeSlice(x.buckets, max(len(x.buckets), words.Words(d.Size())))}
+            remap(x, d, grow, b.Get, b.Set)
+            return any(Bool{D: d, buckets: resizeSlice(b.buckets, words.Words(d.Size()))}).(M[T])
+
+        case Bit:
+            grow, ok := resizeDirection(x, d)
+            if !ok || (words.Words(d.Size()) > cap(x.buckets)) { break }
+            b := Bit{D: x.D, buckets: resizeSlice(x.buckets, max(len(x.buckets), words.Words(d.Size())))}
+            remap(x, d, grow, b.Get, b.Set)
+            return any(Bit{D: d, buckets: resizeSlice(b.buckets, words.Words(d.Size()))}).(M[T])
+
+        case Hashmap[T]:
+            values := make(map[int]T, len(x.values))
+            offsets := make([]int, x.Dimensionality())
+            for idx, value := range x.values {
+                x.Offsets(offsets, idx)
+                if d.Contains(offsets...) { values[d.Index(offsets...)] = value }
+            }
+            return Hashmap[T]{D: d, values: values}
+
+        case Diagonal[T]:
+            return Diagonal[T]{D: d, values: resizeSlice(x.values, square(d))}
+
+        case LowerTriangular[T]:
+            return LowerTriangular[T]{D: d, values: resizeSlice(x.values, packedOffset(0, square(d)))}
+
+        case UpperTriangular[T]:
+            return UpperTriangular[T]{D: d, values: resizeSlice(x.values, packedOffset(0, square(d)))}
+
+        case Symmetric[T]:
+            return Symmetric[T]{D: d, values: resizeSlice(x.values, packedOffset(0, square(d)))}
+
+        case CSR[T]:
+            return CSR[T]{D: d, c: x.c.resize(d.Length(1), d.Length(0))}
+
+        case CSC[T]:
+            return CSC[T]{D: d, c: x.c.resize(d.Length(0), d.Length(1))}
+    }
+
+    var dest M[T]
+    switch any(m).(type) {
+        case Bool: dest = any(NewBool(lengths...)).(M[T])
+        case Bit:  dest = any(NewBit(lengths...)).(M[T])
+        default:   dest = NewGrid[T](lengths...)
+    }
+    Copy(dest, m)
+    return dest
+}
+
+// Compact returns a matrix with the same implementation and values as the
+// matrix m, but using as little memory as that implementation allows. This
+// may be useful after shrinking a matrix with [Resize], or after setting
+// many elements of a sparse matrix to zero.
+//
+// For example, the storage of a [Grid], [Bool], [Bit], [Diagonal], or
+// triangular matrix is copied to a slice with no spare capacity; a [Hashmap]
+// is rebuilt without any zero values; a [CSR] or [CSC] matrix discards any
+// entries that are zero; and a [BlockSparse] matrix frees every block that
+// contains only zero values. Other implementations, such as a [View], are
+// returned unchanged.
+//
+// The new matrix may reuse the storage of m, which must not be used
+// afterwards. Note that the storage of a matrix created with shared memory,
+// such as by [NewSharedGrid], is no longer shared if it is copied.
+func Compact[T comparable](m M[T]) M[T] {
+    var zero T
+    switch x := any(m).(type) {
+        case Grid[T]:
+            return Grid[T]{D: x.D, values: trimSlice(x.values, x.Size())}
+
+        case Bool:
+            return any(Bool{D: x.D, buckets: trimSlice(x.buckets, words.Words(x.Size()))}).(M[T])
+
+        case Bit:
+            return any(Bit{D: x.D, buckets: trimSlice(x.buckets, words.Words(x.Size()))}).(M[T])
+
+        case Hashmap[T]:
+            values := make(map[int]T)
+            for idx, value := range x.values {
+                if value != zero { values[idx] = value }
+            }
+            return Hashmap[T]{D: x.D, values: values}
+
+        case Diagonal[T]:
+            return Diagonal[T]{D: x.D, values: trimSlice(x.values, len(x.values))}
+
+        case LowerTriangular[T]:
+            return LowerTriangular[T]{D: x.D, values: trimSlice(x.values, len(x.values))}
+
+        case UpperTriangular[T]:
+            return UpperTriangular[T]{D: x.D, values: trimSlice(x.values, len(x.values))}
+
+        case Symmetric[T]:
+            return Symmetric[T]{D: x.D, values: trimSlice(x.values, len(x.values))}
+
+        case CSR[T]:
+            return CSR[T]{D: x.D, c: x.c.compact()}
+
+        case CSC[T]:
+            return CSC[T]{D: x.D, c: x.c.compact()}
+
+        case BlockSparse[T]:
+            for block, values := range x.blocks {
+                empty := true
+                for _, value := range values {
+                    if value != zero { empty = false; break }
+                }
+                if empty { delete(x.blocks, block) }
+            }
+            return x
+    }
+    return m
+}
@@ -0,0 +1,167 @@
+// Package stencil implements operations, such as convolution, that compute
+// each element of a matrix from a neighbourhood of elements around the same
+// offsets in another matrix.
+//
+// Neighbourhoods of elements at the edges of a matrix extend past its
+// bounds. A [BorderMode] decides the value of these elements, so that the
+// edges need no special handling.
+package stencil
+
+import (
+    "github.com/tawesoft/golib/v2/ds/matrix"
+    "github.com/tawesoft/golib/v2/operator"
+)
+
+// BorderMode decides the value of an element outside the bounds of a matrix
+// in a [Neighbourhood].
+type BorderMode int
+
+const (
+    // BorderZero treats every element outside the matrix as the zero value.
+    BorderZero BorderMode = iota
+
+    // BorderClamp treats every element outside the matrix as the nearest
+    // element on the edge of the matrix.
+    BorderClamp
+
+    // BorderWrap wraps offsets outside the matrix around to the opposite
+    // edge, as if the matrix was repeated along each axis.
+    BorderWrap
+
+    // BorderReflect mirrors offsets outside the matrix back into the matrix,
+    // about the element on the edge. For example, along an axis of length 4,
+    // offsets -2, -1, 4, and 5 are treated as offsets 2, 1, 2, and 1.
+    BorderReflect
+)
+
+// resolve returns an offset along an axis of length n according to the
+// border mode, or false if it refers to the zero value.
+func (b BorderMode) resolve(offset int, n int) (int, bool) {
+    if (offset >= 0) && (offset < n) { return offset, true }
+    switch b {
+        case BorderClamp:
+            return max(0, min(offset, n - 1)), true
+        case BorderWrap:
+            return ((offset % n) + n) % n, true
+        case BorderReflect:
+            if n == 1 { return 0, true }
+            period := 2 * (n - 1)
+            offset = ((offset % period) + period) % period
+            if offset >= n { offset = period - offset }
+            return offset, true
+        default:
+            return 0, false
+    }
+}
+
+// Neighbourhood is the region of a matrix around an element, called the
+// centre, given to the function called by [ApplyStencil] for each element.
+//
+// A Neighbourhood is only valid for the duration of that function call.
+type Neighbourhood[T comparable] struct {
+    src     matrix.M[T]
+    border  BorderMode
+    centre  []int
+    offsets []int
+}
+
+    // Centre copies the offsets of the centre element along each axis into
+    // dest, which must have a length of at least the dimensionality of the
+    // matrix.
+    func (n *Neighbourhood[T]) Centre(dest []int) {
+        copy(dest, n.centre)
+    }
+
+    // Get returns the element at the given offsets along each axis,
+    // relative to the centre. For example, in a 2-dimensional matrix, Get(0,
+    // 0) is the centre element, and Get(-1, 0) is the element to its left.
+    //
+    // Elements outside the matrix are decided by the [BorderMode].
+    func (n *Neighbourhood[T]) Get(relative ... int) T {
+        for i := range n.centre {
+            r := 0
+            if i < len(relative) { r = relative[i] }
+            offset, ok := n.border.resolve(n.centre[i] + r, n.src.Length(i))
+            if !ok {
+                var zero T
+                return zero
+            }
+            n.offsets[i] = offset
+        }
+        return n.src.Get(n.src.Index(n.offsets...))
+    }
+
+// ApplyStencil sets each element of dest to the result of calling f with
+// the neighbourhood of the element at the same offsets in src.
+//
+// The matrices must have the same shape, or ApplyStencil panics with
+// [matrix.ErrShape]. The destination must not be the same matrix as src.
+func ApplyStencil[T comparable, U comparable](
+    dest matrix.M[U],
+    src matrix.M[T],
+    border BorderMode,
+    f func(n *Neighbourhood[T]) U,
+) {
+    if dest.Dimensionality() != src.Dimensionality() { panic(matrix.ErrShape) }
+    for i := 0; i < src.Dimensionality(); i++ {
+        if dest.Length(i) != src.Length(i) { panic(matrix.ErrShape) }
+    }
+
+    dims := src.Dimensionality()
+    n := &Neighbourhood[T]{
+        src:     src,
+        border:  border,
+        centre:  make([]int, dims),
+        offsets: make([]int, dims),
+    }
+    for idx := 0; idx < src.Size(); idx++ {
+        src.Offsets(n.centre, idx)
+        dest.Set(idx, f(n))
+    }
+}
+
+// Convolve sets dest to the convolution of src with a kernel, a (usually
+// small) matrix with the same dimensionality as src. Each element of dest is
+// the sum of the products of each element of the kernel and an element in
+// the neighbourhood of the element at the same offsets in src:
+//
+//     dest[x] = sum over k of kernel[k] * src[x + c - k]
+//
+// where c is the centre of the kernel, at offset len/2 along each axis.
+// Elements outside src are decided by the [BorderMode]. Note that, as in the
+// mathematical definition of convolution, the kernel is flipped along each
+// axis. This makes no difference for a symmetric kernel, such as a box blur.
+//
+// Only the non-zero elements of the kernel are visited.
+//
+// The matrices dest and src must have the same shape, and the kernel must
+// have the same dimensionality, or Convolve panics with [matrix.ErrShape].
+// The destination must not be the same matrix as src.
+func Convolve[T operator.Number](dest, src, kernel matrix.M[T], border BorderMode) {
+    dims := src.Dimensionality()
+    if kernel.Dimensionality() != dims { panic(matrix.ErrShape) }
+
+    // the relative offsets in src of each non-zero element of the kernel
+    type term struct {
+        relative []int
+        value T
+    }
+    var terms []term
+    offsets := make([]int, dims)
+    for idx, ok := kernel.Next(-1); ok; idx, ok = kernel.Next(idx) {
+        kernel.Offsets(offsets, idx)
+        relative := make([]int, dims)
+        for i := range relative {
+            relative[i] = (kernel.Length(i) / 2) - offsets[i]
+        }
+        terms = append(terms, term{relative, kernel.Get(idx)})
+    }
+
+    ApplyStencil(dest, src, border, func(n *Neighbourhood[T]) T {
+        var sum T
+        for _, t := range terms {
+            sum += t.value * n.Get(t.relative...)
+        }
+        return sum
+    })
+}
@@ -0,0 +1,98 @@
+package stencil_test
+
+import (
+    "fmt"
+    "slices"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/ds/matrix"
+    "github.com/tawesoft/golib/v2/ds/matrix/stencil"
+)
+
+func values(m matrix.M[int]) []int {
+    result := make([]int, m.Size())
+    for i := range result {
+        result[i] = m.Get(i)
+    }
+    return result
+}
+
+func TestConvolve_border(t *testing.T) {
+    src := matrix.NewSharedGrid([]int{4}, []int{1, 2, 3, 4})
+    kernel := matrix.NewSharedGrid([]int{5}, []int{1, 10, 100, 1000, 10000})
+
+    // dest[x] = src[x+2] + 10 src[x+1] + 100 src[x] + 1000 src[x-1] + 10000 src[x-2]
+    tests := []struct {
+        border stencil.BorderMode
+        expected []int
+    }{
+        {stencil.BorderZero,    []int{  123,  1234, 12340, 23400}},
+        {stencil.BorderClamp,   []int{11123, 11234, 12344, 23444}},
+        {stencil.BorderWrap,    []int{34123, 41234, 12341, 23412}},
+        {stencil.BorderReflect, []int{32123, 21234, 12343, 23432}},
+    }
+
+    for _, tt := range tests {
+        dest := matrix.NewGrid[int](4)
+        stencil.Convolve(dest, src, kernel, tt.border)
+        if got := values(dest); !slices.Equal(got, tt.expected) {
+            t.Errorf("border %d: got %v, expected %v", tt.border, got, tt.expected)
+        }
+    }
+}
+
+func TestApplyStencil(t *testing.T) {
+    // one step of Conway's Game of Life on a torus, with a glider crossing
+    // the edge
+    src := matrix.NewBool(5, 5)
+    for _, xy := range [][2]int{{4, 0}, {0, 1}, {3, 2}, {4, 2}, {0, 2}} {
+        src.Set(src.Index(xy[0], xy[1]), true)
+    }
+    dest := matrix.NewBool(5, 5)
+
+    stencil.ApplyStencil(dest, src, stencil.BorderWrap, func(n *stencil.Neighbourhood[bool]) bool {
+        count := 0
+        for y := -1; y <= 1; y++ {
+            for x := -1; x <= 1; x++ {
+                if ((x != 0) || (y != 0)) && n.Get(x, y) { count++ }
+            }
+        }
+        return (count == 3) || (n.Get(0, 0) && (count == 2))
+    })
+
+    var got [][2]int
+    offsets := make([]int, 2)
+    for idx, ok := dest.Next(-1); ok; idx, ok = dest.Next(idx) {
+        dest.Offsets(offsets, idx)
+        got = append(got, [2]int{offsets[0], offsets[1]})
+    }
+    expected := [][2]int{{0, 1}, {0, 2}, {3, 1}, {4, 2}, {4, 3}}
+    slices.SortFunc(got, func(a, b [2]int) int { return (a[0] - b[0]) * 10 + (a[1] - b[1]) })
+    if !slices.Equal(got, expected) {
+        t.Errorf("got %v, expected %v", got, expected)
+    }
+}
+
+func ExampleConvolve() {
+    // a 3×3 box blur, scaled by 9
+    src := matrix.NewGrid[int](4, 3)
+    src.Set(src.Index(1, 1), 9)
+    kernel := matrix.NewGrid[int](3, 3)
+    matrix.Fill(kernel, 1)
+
+    dest := matrix.NewGrid[int](4, 3)
+    stencil.Convolve(dest, src, kernel, stencil.BorderZero)
+
+    for y := 0; y < dest.Length(1); y++ {
+        row := make([]int, dest.Length(0))
+        for x := range row {
+            row[x] = dest.Get(dest.Index(x, y))
+        }
+        fmt.Println(row)
+    }
+
+    // Output:
+    // [9 9 9 0]
+    // [9 9 9 0]
+    // [9 9 9 0]
+}
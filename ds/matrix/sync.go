@@ -0,0 +1,152 @@
+package matrix
+
+import (
+    "sync"
+
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+)
+
+// synchronized implements M by guarding every access to a matrix with a
+// single lock.
+type synchronized[T comparable] struct {
+    dimensions.D
+    mu *sync.RWMutex
+    m  M[T]
+}
+
+// Synchronized returns a matrix implementing M that wraps any matrix m so
+// that it is safe for concurrent use by multiple goroutines. Get and Next may
+// run concurrently with each other, but Set and Clear have exclusive access.
+//
+// Once wrapped, m must only be accessed through the returned matrix. Note
+// that only individual method calls are atomic: for example, a Get followed
+// by a Set may be interleaved with a Set from another goroutine.
+//
+// For write-heavy workloads, see [SynchronizedSharded].
+func Synchronized[T comparable](m M[T]) M[T] {
+    return synchronized[T]{
+        D:  m,
+        mu: &sync.RWMutex{},
+        m:  m,
+    }
+}
+
+    func (s synchronized[T]) Get(idx int) T {
+        s.mu.RLock()
+        defer s.mu.RUnlock()
+        return s.m.Get(idx)
+    }
+
+    func (s synchronized[T]) Set(idx int, value T) {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+        s.m.Set(idx, value)
+    }
+
+    func (s synchronized[T]) Next(idx int) (int, bool) {
+        s.mu.RLock()
+        defer s.mu.RUnlock()
+        return s.m.Next(idx)
+    }
+
+    func (s synchronized[T]) Clear() {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+        s.m.Clear()
+    }
+
+// shardBlock is the number of consecutive elements guarded by the same lock
+// in a sharded matrix. This is a multiple of 64, so that a Bool or Bit matrix
+// never has a word of storage shared between two locks.
+const shardBlock = 64
+
+// shardLock is a lock padded to the size of a typical cache line, so that
+// goroutines holding neighbouring locks do not contend.
+type shardLock struct {
+    sync.RWMutex
+    _ [64 - 24]byte
+}
+
+// sharded implements M by guarding each element of a matrix with one of many
+// locks, chosen by index.
+type sharded[T comparable] struct {
+    dimensions.D
+    locks []shardLock
+    m     M[T]
+}
+
+// SynchronizedSharded returns a matrix implementing M that wraps a matrix m
+// so that it is safe for concurrent use by multiple goroutines, like
+// [Synchronized], but where elements are guarded by many locks, in the given
+// number of shards, so that goroutines setting different elements rarely
+// contend.
+//
+// Get and Set lock a single shard. Next and Clear lock every shard, so are
+// more expensive than with [Synchronized].
+//
+// This is only possible for implementations where each element is stored
+// independently: a [Grid], [Bool], [Bit], or [Diagonal]. For any other
+// implementation, such as a [Hashmap], where setting one element may modify
+// storage shared by other elements, this is equivalent to [Synchronized].
+//
+// If shards is not greater than zero, panics with [ErrShape].
+func SynchronizedSharded[T comparable](m M[T], shards int) M[T] {
+    if shards <= 0 { panic(ErrShape) }
+    switch any(m).(type) {
+        case Grid[T], Bool, Bit, Diagonal[T]:
+            // independent storage
+        default:
+            return Synchronized(m)
+    }
+
+    // no more shards than there are blocks of elements
+    shards = min(shards, (m.Size() + shardBlock - 1) / shardBlock)
+    return sharded[T]{
+        D:     m,
+        locks: make([]shardLock, shards),
+        m:     m,
+    }
+}
+
+    func (s sharded[T]) lock(idx int) *shardLock {
+        return &s.locks[(idx / shardBlock) % len(s.locks)]
+    }
+
+    func (s sharded[T]) Get(idx int) T {
+        l := s.lock(idx)
+        l.RLock()
+        defer l.RUnlock()
+        return s.m.Get(idx)
+    }
+
+    func (s sharded[T]) Set(idx int, value T) {
+        l := s.lock(idx)
+        l.Lock()
+        defer l.Unlock()
+        s.m.Set(idx, value)
+    }
+
+    func (s sharded[T]) Next(idx int) (int, bool) {
+        // always locked in the same order, so cannot deadlock
+        for i := range s.locks {
+            s.locks[i].RLock()
+        }
+        defer func() {
+            for i := range s.locks {
+                s.locks[i].RUnlock()
+            }
+        }()
+        return s.m.Next(idx)
+    }
+
+    func (s sharded[T]) Clear() {
+        for i := range s.locks {
+            s.locks[i].Lock()
+        }
+        defer func() {
+            for i := range s.locks {
+                s.locks[i].Unlock()
+            }
+        }()
+        s.m.Clear()
+    }
@@ -0,0 +1,182 @@
+package matrix
+
+import (
+    "github.com/tawesoft/golib/v2/ds/matrix/dimensions"
+)
+
+// packedOffset returns the offset of the element in column x and row y of a
+// lower triangle, where x <= y, packed in row-major order.
+func packedOffset(x, y int) int {
+    return ((y * (y + 1)) / 2) + x
+}
+
+func newPacked[T comparable](length int) (dimensions.D, []T) {
+    dims := dimensions.New(length, length)
+    return dims, make([]T, packedOffset(0, length))
+}
+
+// LowerTriangular is an implementation of the matrix interface [M] that
+// represents a 2-dimensional square lower triangular matrix (one where
+// entries above the main diagonal are all zero) as a contiguous slice of only
+// the values on or below the diagonal. This stores roughly half the elements
+// of a [Grid]. In most cases, this is initialised by calling
+// [NewLowerTriangular].
+//
+// Setting an element above the diagonal to a non-zero value is an error, and
+// will panic.
+type LowerTriangular[T comparable] struct {
+    dimensions.D
+    values []T
+}
+
+    // NewLowerTriangular allocates and returns a new [LowerTriangular]
+    // matrix implementing M, with the given length of each side.
+    func NewLowerTriangular[T comparable](length int) M[T] {
+        dims, values := newPacked[T](length)
+        return LowerTriangular[T]{D: dims, values: values}
+    }
+
+    func (m LowerTriangular[T]) Get(idx int) T {
+        n := m.Length(0)
+        x, y := idx % n, idx / n
+        if x > y {
+            var zero T
+            return zero
+        }
+        return m.values[packedOffset(x, y)]
+    }
+
+    func (m LowerTriangular[T]) Set(idx int, value T) {
+        var zero T
+        n := m.Length(0)
+        x, y := idx % n, idx / n
+        if x > y {
+            if value == zero { return }
+            panic("can not set a non-zero value above the matrix diagonal")
+        }
+        m.values[packedOffset(x, y)] = value
+    }
+
+    func (m LowerTriangular[T]) Next(idx int) (int, bool) {
+        var zero T
+        n := m.Length(0)
+        for i := max(idx + 1, 0); i < n * n; i++ {
+            x, y := i % n, i / n
+            if x > y {
+                i = ((y + 1) * n) - 1 // skip to the next row
+                continue
+            }
+            if m.values[packedOffset(x, y)] != zero { return i, true }
+        }
+        return 0, false
+    }
+
+    func (m LowerTriangular[T]) Clear() {
+        clear(m.values)
+    }
+
+// UpperTriangular is an implementation of the matrix interface [M] that
+// represents a 2-dimensional square upper triangular matrix (one where
+// entries below the main diagonal are all zero) as a contiguous slice of only
+// the values on or above the diagonal. This stores roughly half the elements
+// of a [Grid]. In most cases, this is initialised by calling
+// [NewUpperTriangular].
+//
+// Setting an element below the diagonal to a non-zero value is an error, and
+// will panic.
+type UpperTriangular[T comparable] struct {
+    dimensions.D
+    values []T
+}
+
+    // NewUpperTriangular allocates and returns a new [UpperTriangular]
+    // matrix implementing M, with the given length of each side.
+    func NewUpperTriangular[T comparable](length int) M[T] {
+        dims, values := newPacked[T](length)
+        return UpperTriangular[T]{D: dims, values: values}
+    }
+
+    func (m UpperTriangular[T]) Get(idx int) T {
+        n := m.Length(0)
+        x, y := idx % n, idx / n
+        if x < y {
+            var zero T
+            return zero
+        }
+        return m.values[packedOffset(y, x)] // stored as the transpose
+    }
+
+    func (m UpperTriangular[T]) Set(idx int, value T) {
+        var zero T
+        n := m.Length(0)
+        x, y := idx % n, idx / n
+        if x < y {
+            if value == zero { return }
+            panic("can not set a non-zero value below the matrix diagonal")
+        }
+        m.values[packedOffset(y, x)] = value
+    }
+
+    func (m UpperTriangular[T]) Next(idx int) (int, bool) {
+        var zero T
+        n := m.Length(0)
+        for i := max(idx + 1, 0); i < n * n; i++ {
+            x, y := i % n, i / n
+            if x < y {
+                i = (y * n) + y - 1 // skip to the diagonal
+                continue
+            }
+            if m.values[packedOffset(y, x)] != zero { return i, true }
+        }
+        return 0, false
+    }
+
+    func (m UpperTriangular[T]) Clear() {
+        clear(m.values)
+    }
+
+// Symmetric is an implementation of the matrix interface [M] that represents
+// a 2-dimensional square symmetric matrix (one that is equal to its
+// transpose) as a contiguous slice of only the values on or below the main
+// diagonal. This stores roughly half the elements of a [Grid]. In most cases,
+// this is initialised by calling [NewSymmetric].
+//
+// The element at column x and row y is the same element as the one at column
+// y and row x, so that setting either one sets both.
+type Symmetric[T comparable] struct {
+    dimensions.D
+    values []T
+}
+
+    // NewSymmetric allocates and returns a new [Symmetric] matrix
+    // implementing M, with the given length of each side.
+    func NewSymmetric[T comparable](length int) M[T] {
+        dims, values := newPacked[T](length)
+        return Symmetric[T]{D: dims, values: values}
+    }
+
+    func (m Symmetric[T]) offset(idx int) int {
+        n := m.Length(0)
+        x, y := idx % n, idx / n
+        return packedOffset(min(x, y), max(x, y))
+    }
+
+    func (m Symmetric[T]) Get(idx int) T {
+        return m.values[m.offset(idx)]
+    }
+
+    func (m Symmetric[T]) Set(idx int, value T) {
+        m.values[m.offset(idx)] = value
+    }
+
+    func (m Symmetric[T]) Next(idx int) (int, bool) {
+        var zero T
+        for i := max(idx + 1, 0); i < m.Size(); i++ {
+            if m.values[m.offset(i)] != zero { return i, true }
+        }
+        return 0, false
+    }
+
+    func (m Symmetric[T]) Clear() {
+        clear(m.values)
+    }
@@ -0,0 +1,249 @@
+// Package multimap implements maps from a key to multiple values.
+//
+// A [Multimap] keeps the values for each key in the order they were added,
+// and may contain duplicate values. A [SetMultimap] keeps the values for
+// each key as a set (see [set.Set]), without duplicates, in no particular
+// order.
+//
+// For example, a directed graph can be represented as a SetMultimap from
+// each vertex to its adjacent vertexes.
+package multimap
+
+import (
+    "github.com/tawesoft/golib/v2/ds/set"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// Multimap maps keys to an ordered list of values.
+//
+// The zero-value Multimap is a useful value. A Multimap is not suitable for
+// concurrent use without additional synchronization.
+type Multimap[K comparable, V any] struct {
+    m map[K][]V
+    count int
+}
+
+// Len returns the total number of values, for all keys.
+func (m *Multimap[K, V]) Len() int {
+    return m.count
+}
+
+// KeyLen returns the number of distinct keys with at least one value.
+func (m *Multimap[K, V]) KeyLen() int {
+    return len(m.m)
+}
+
+// Clear removes every key and value.
+func (m *Multimap[K, V]) Clear() {
+    m.m = nil
+    m.count = 0
+}
+
+// Contains returns true if the key has at least one value.
+func (m *Multimap[K, V]) Contains(key K) bool {
+    _, ok := m.m[key]
+    return ok
+}
+
+// Put appends each of the given values to the values for a key.
+func (m *Multimap[K, V]) Put(key K, values ... V) {
+    if len(values) == 0 { return }
+    if m.m == nil { m.m = make(map[K][]V) }
+    m.m[key] = append(m.m[key], values...)
+    m.count += len(values)
+}
+
+// Get returns the values for a key, in the order they were added, or nil if
+// there are none. The returned slice must not be modified.
+func (m *Multimap[K, V]) Get(key K) []V {
+    return m.m[key]
+}
+
+// Count returns the number of values for a key.
+func (m *Multimap[K, V]) Count(key K) int {
+    return len(m.m[key])
+}
+
+// RemoveKey removes every value for a key, and returns them.
+func (m *Multimap[K, V]) RemoveKey(key K) []V {
+    values := m.m[key]
+    delete(m.m, key)
+    m.count -= len(values)
+    return values
+}
+
+// RemoveFunc removes every value for a key where f returns true, keeping
+// the remaining values in order, and returns the number of values removed.
+func (m *Multimap[K, V]) RemoveFunc(key K, f func(V) bool) int {
+    values, ok := m.m[key]
+    if !ok { return 0 }
+    kept := values[:0]
+    for _, v := range values {
+        if !f(v) { kept = append(kept, v) }
+    }
+    clear(values[len(kept):]) // allow garbage collection
+    removed := len(values) - len(kept)
+    m.count -= removed
+    if len(kept) == 0 {
+        delete(m.m, key)
+    } else {
+        m.m[key] = kept
+    }
+    return removed
+}
+
+// Merge appends every value in another Multimap to the values for the same
+// key in m.
+func (m *Multimap[K, V]) Merge(other *Multimap[K, V]) {
+    for k, vs := range other.m {
+        m.Put(k, vs...)
+    }
+}
+
+// Keys returns an iterator over every key with at least one value, in an
+// arbitrary order. The Multimap must not be modified until the iterator is
+// exhausted.
+func (m *Multimap[K, V]) Keys() iter.It[K] {
+    return iter.Keys(iter.FromMap(m.m))
+}
+
+// All returns an iterator over every key, value pair. Keys are produced in
+// an arbitrary order, and the values for each key in the order they were
+// added. The Multimap must not be modified until the iterator is exhausted.
+func (m *Multimap[K, V]) All() iter.It[iter.Pair[K, V]] {
+    keys := m.Keys()
+    var key K
+    var values []V
+    return func() (iter.Pair[K, V], bool) {
+        for len(values) == 0 {
+            var ok bool
+            key, ok = keys()
+            if !ok { return iter.Pair[K, V]{}, false }
+            values = m.m[key]
+        }
+        v := values[0]
+        values = values[1:]
+        return iter.Pair[K, V]{Key: key, Value: v}, true
+    }
+}
+
+// SetMultimap maps keys to a set of values.
+//
+// The zero-value SetMultimap is a useful value. A SetMultimap is not suitable
+// for concurrent use without additional synchronization.
+type SetMultimap[K comparable, V comparable] struct {
+    m map[K]*set.Set[V]
+    count int
+}
+
+// Len returns the total number of values, for all keys.
+func (m *SetMultimap[K, V]) Len() int {
+    return m.count
+}
+
+// KeyLen returns the number of distinct keys with at least one value.
+func (m *SetMultimap[K, V]) KeyLen() int {
+    return len(m.m)
+}
+
+// Clear removes every key and value.
+func (m *SetMultimap[K, V]) Clear() {
+    m.m = nil
+    m.count = 0
+}
+
+// Contains returns true if the key has the given value.
+func (m *SetMultimap[K, V]) Contains(key K, value V) bool {
+    s, ok := m.m[key]
+    return ok && s.Contains(value)
+}
+
+// ContainsKey returns true if the key has at least one value.
+func (m *SetMultimap[K, V]) ContainsKey(key K) bool {
+    _, ok := m.m[key]
+    return ok
+}
+
+// Put adds each of the given values to the set of values for a key, and
+// returns the number of values that were not already in the set.
+func (m *SetMultimap[K, V]) Put(key K, values ... V) int {
+    if len(values) == 0 { return 0 }
+    if m.m == nil { m.m = make(map[K]*set.Set[V]) }
+    s, ok := m.m[key]
+    if !ok {
+        s = set.New[V]()
+        m.m[key] = s
+    }
+    before := s.Len()
+    s.Add(values...)
+    added := s.Len() - before
+    m.count += added
+    return added
+}
+
+// Get returns the set of values for a key, or nil if there are none. The
+// returned set must not be modified.
+func (m *SetMultimap[K, V]) Get(key K) *set.Set[V] {
+    return m.m[key]
+}
+
+// Count returns the number of values for a key.
+func (m *SetMultimap[K, V]) Count(key K) int {
+    s, ok := m.m[key]
+    if !ok { return 0 }
+    return s.Len()
+}
+
+// Remove removes a value from the set of values for a key, and returns true
+// if it existed.
+func (m *SetMultimap[K, V]) Remove(key K, value V) bool {
+    s, ok := m.m[key]
+    if !ok || !s.Remove(value) { return false }
+    m.count--
+    if s.Len() == 0 { delete(m.m, key) }
+    return true
+}
+
+// RemoveKey removes every value for a key, and returns them as a set, or nil
+// if there were none.
+func (m *SetMultimap[K, V]) RemoveKey(key K) *set.Set[V] {
+    s, ok := m.m[key]
+    if !ok { return nil }
+    delete(m.m, key)
+    m.count -= s.Len()
+    return s
+}
+
+// Merge adds every value in another SetMultimap to the set of values for the
+// same key in m.
+func (m *SetMultimap[K, V]) Merge(other *SetMultimap[K, V]) {
+    for k, s := range other.m {
+        m.Put(k, s.ToSlice()...)
+    }
+}
+
+// Keys returns an iterator over every key with at least one value, in an
+// arbitrary order. The SetMultimap must not be modified until the iterator
+// is exhausted.
+func (m *SetMultimap[K, V]) Keys() iter.It[K] {
+    return iter.Keys(iter.FromMap(m.m))
+}
+
+// All returns an iterator over every key, value pair, in an arbitrary order.
+// The SetMultimap must not be modified until the iterator is exhausted.
+func (m *SetMultimap[K, V]) All() iter.It[iter.Pair[K, V]] {
+    keys := m.Keys()
+    var key K
+    values := iter.Empty[V]()
+    return func() (iter.Pair[K, V], bool) {
+        for {
+            if v, ok := values(); ok {
+                return iter.Pair[K, V]{Key: key, Value: v}, true
+            }
+            var ok bool
+            key, ok = keys()
+            if !ok { return iter.Pair[K, V]{}, false }
+            values = m.m[key].Values()
+        }
+    }
+}
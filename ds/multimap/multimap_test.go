@@ -0,0 +1,101 @@
+package multimap_test
+
+import (
+    "fmt"
+    "sort"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/multimap"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+func ExampleSetMultimap() {
+    // a directed graph, from each vertex to its adjacent vertexes
+    var edges multimap.SetMultimap[string, string]
+    edges.Put("a", "b", "c")
+    edges.Put("b", "c")
+    edges.Put("a", "b") // already exists
+
+    fmt.Println(edges.Len(), edges.KeyLen())
+    fmt.Println(edges.Contains("a", "c"), edges.Contains("c", "a"))
+
+    // Output:
+    // 3 2
+    // true false
+}
+
+func pairs[K comparable, V any](it iter.It[iter.Pair[K, V]]) []string {
+    var result []string
+    iter.Walk(func(p iter.Pair[K, V]) {
+        result = append(result, fmt.Sprintf("%v=%v", p.Key, p.Value))
+    }, it)
+    sort.Strings(result)
+    return result
+}
+
+func TestMultimap(t *testing.T) {
+    var m multimap.Multimap[string, int]
+    assert.Nil(t, m.Get("a"))
+    assert.Equal(t, 0, m.RemoveFunc("a", func(int) bool { return true }))
+
+    m.Put("a", 1, 2, 1)
+    m.Put("b", 3)
+    m.Put("c")
+    assert.Equal(t, 4, m.Len())
+    assert.Equal(t, 2, m.KeyLen())
+    assert.False(t, m.Contains("c"))
+    assert.Equal(t, []int{1, 2, 1}, m.Get("a"))
+    assert.Equal(t, 3, m.Count("a"))
+    assert.Equal(t, []string{"a=1", "a=1", "a=2", "b=3"}, pairs(m.All()))
+
+    assert.Equal(t, 2, m.RemoveFunc("a", func(x int) bool { return x == 1 }))
+    assert.Equal(t, []int{2}, m.Get("a"))
+    assert.Equal(t, 2, m.Len())
+
+    var other multimap.Multimap[string, int]
+    other.Put("a", 4)
+    other.Put("d", 5)
+    m.Merge(&other)
+    assert.Equal(t, []int{2, 4}, m.Get("a"))
+    assert.Equal(t, 4, m.Len())
+
+    assert.Equal(t, []int{2, 4}, m.RemoveKey("a"))
+    assert.Equal(t, 2, m.Len())
+    assert.Equal(t, 1, m.RemoveFunc("d", func(int) bool { return true }))
+    assert.False(t, m.Contains("d"))
+    assert.Equal(t, []string{"b"}, iter.ToSlice(m.Keys()))
+
+    m.Clear()
+    assert.Equal(t, 0, m.Len())
+    assert.Nil(t, pairs(m.All()))
+}
+
+func TestSetMultimap(t *testing.T) {
+    var m multimap.SetMultimap[string, int]
+    assert.Nil(t, m.Get("a"))
+    assert.False(t, m.Remove("a", 1))
+    assert.Nil(t, m.RemoveKey("a"))
+
+    assert.Equal(t, 2, m.Put("a", 1, 2, 1))
+    assert.Equal(t, 1, m.Put("b", 3))
+    assert.Equal(t, 0, m.Put("b", 3))
+    assert.Equal(t, 3, m.Len())
+    assert.Equal(t, 2, m.Count("a"))
+    assert.True(t, m.ContainsKey("b"))
+    assert.Equal(t, []string{"a=1", "a=2", "b=3"}, pairs(m.All()))
+
+    assert.True(t, m.Remove("b", 3))
+    assert.False(t, m.ContainsKey("b"))
+    assert.Equal(t, 2, m.Len())
+
+    var other multimap.SetMultimap[string, int]
+    other.Put("a", 2, 3)
+    m.Merge(&other)
+    assert.Equal(t, 3, m.Len())
+    assert.True(t, m.Get("a").Contains(3))
+
+    assert.Equal(t, 3, m.RemoveKey("a").Len())
+    assert.Equal(t, 0, m.Len())
+    assert.Equal(t, 0, m.KeyLen())
+}
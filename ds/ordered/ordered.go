@@ -0,0 +1,495 @@
+// Package ordered implements a [Map], and a [Set], that keep their keys in
+// sorted order.
+//
+// Unlike a Go map, the keys can be iterated in order, a range of keys can be
+// scanned efficiently, and the nearest key to a given value (which need not
+// be in the map) can be found. Unlike a sorted slice, insertion and deletion
+// take O(log n) time.
+//
+// Both types are implemented as an in-memory B-tree, which stores several
+// keys in each node, and so uses memory and CPU cache more efficiently than
+// a binary search tree.
+package ordered
+
+import (
+    "slices"
+    "sort"
+
+    "github.com/tawesoft/golib/v2/iter"
+    "golang.org/x/exp/constraints"
+)
+
+// degree is the minimum degree of the B-tree: every node except the root has
+// between degree - 1 and maxEntries entries, and every internal node has one
+// more child than it has entries.
+const degree = 16
+const maxEntries = 2 * degree - 1
+
+// Entry is a key, value pair stored in a [Map].
+type Entry[K constraints.Ordered, V any] struct {
+    Key   K
+    Value V
+}
+
+type node[K constraints.Ordered, V any] struct {
+    entries  []Entry[K, V]
+    children []*node[K, V] // nil for a leaf
+}
+
+func (n *node[K, V]) leaf() bool {
+    return n.children == nil
+}
+
+// search returns the index of the first entry with a key not less than key,
+// and true if that key is equal to key.
+func (n *node[K, V]) search(key K) (int, bool) {
+    i := sort.Search(len(n.entries), func(i int) bool {
+        return !(n.entries[i].Key < key)
+    })
+    return i, (i < len(n.entries)) && !(key < n.entries[i].Key)
+}
+
+// removeAt removes the element at index i from a slice.
+func removeAt[X any](xs []X, i int) []X {
+    copy(xs[i:], xs[i+1:])
+    var zero X
+    xs[len(xs) - 1] = zero // allow garbage collection
+    return xs[:len(xs) - 1]
+}
+
+// splitChild splits the full child at index i into two, moving its median
+// entry into n.
+func (n *node[K, V]) splitChild(i int) {
+    c := n.children[i]
+    right := &node[K, V]{
+        entries: slices.Clone(c.entries[degree:]),
+    }
+    if !c.leaf() {
+        right.children = slices.Clone(c.children[degree:])
+        clear(c.children[degree:])
+        c.children = c.children[:degree]
+    }
+    median := c.entries[degree - 1]
+    clear(c.entries[degree - 1:])
+    c.entries = c.entries[:degree - 1]
+
+    n.entries = slices.Insert(n.entries, i, median)
+    n.children = slices.Insert(n.children, i + 1, right)
+}
+
+// merge merges the child at index i + 1, and the entry at index i, into the
+// child at index i.
+func (n *node[K, V]) merge(i int) {
+    left, right := n.children[i], n.children[i + 1]
+    left.entries = append(left.entries, n.entries[i])
+    left.entries = append(left.entries, right.entries...)
+    if !left.leaf() {
+        left.children = append(left.children, right.children...)
+    }
+    n.entries = removeAt(n.entries, i)
+    n.children = removeAt(n.children, i + 1)
+}
+
+// fill ensures that the child at index i has at least degree entries, by
+// moving an entry from a sibling or by merging with a sibling, and returns
+// the (possibly changed) index of that child.
+func (n *node[K, V]) fill(i int) int {
+    c := n.children[i]
+    if len(c.entries) >= degree { return i }
+
+    switch {
+        case (i > 0) && (len(n.children[i - 1].entries) >= degree):
+            // rotate an entry from the left sibling, through n
+            left := n.children[i - 1]
+            last := len(left.entries) - 1
+            c.entries = slices.Insert(c.entries, 0, n.entries[i - 1])
+            n.entries[i - 1] = left.entries[last]
+            left.entries = removeAt(left.entries, last)
+            if !c.leaf() {
+                last := len(left.children) - 1
+                c.children = slices.Insert(c.children, 0, left.children[last])
+                left.children = removeAt(left.children, last)
+            }
+            return i
+        case (i < len(n.entries)) && (len(n.children[i + 1].entries) >= degree):
+            // rotate an entry from the right sibling, through n
+            right := n.children[i + 1]
+            c.entries = append(c.entries, n.entries[i])
+            n.entries[i] = right.entries[0]
+            right.entries = removeAt(right.entries, 0)
+            if !c.leaf() {
+                c.children = append(c.children, right.children[0])
+                right.children = removeAt(right.children, 0)
+            }
+            return i
+        case i < len(n.entries):
+            n.merge(i)
+            return i
+        default:
+            n.merge(i - 1)
+            return i - 1
+    }
+}
+
+// delete removes a key from the subtree rooted at n, which must have at
+// least degree entries unless it is the root.
+func (n *node[K, V]) delete(key K) bool {
+    for {
+        i, found := n.search(key)
+        if n.leaf() {
+            if !found { return false }
+            n.entries = removeAt(n.entries, i)
+            return true
+        }
+
+        if found {
+            switch {
+                case len(n.children[i].entries) >= degree:
+                    // replace with the predecessor
+                    c := n.children[i]
+                    n.entries[i] = c.last()
+                    n, key = c, n.entries[i].Key
+                case len(n.children[i + 1].entries) >= degree:
+                    // replace with the successor
+                    c := n.children[i + 1]
+                    n.entries[i] = c.first()
+                    n, key = c, n.entries[i].Key
+                default:
+                    n.merge(i)
+                    n = n.children[i]
+            }
+            continue
+        }
+
+        n = n.children[n.fill(i)]
+    }
+}
+
+// first returns the entry with the least key in the subtree rooted at n,
+// which must not be empty.
+func (n *node[K, V]) first() Entry[K, V] {
+    for !n.leaf() {
+        n = n.children[0]
+    }
+    return n.entries[0]
+}
+
+// last returns the entry with the greatest key in the subtree rooted at n,
+// which must not be empty.
+func (n *node[K, V]) last() Entry[K, V] {
+    for !n.leaf() {
+        n = n.children[len(n.children) - 1]
+    }
+    return n.entries[len(n.entries) - 1]
+}
+
+// Map is a map of keys to values, sorted by key.
+//
+// The zero-value Map is a useful value. A Map is not suitable for concurrent
+// use without additional synchronization.
+type Map[K constraints.Ordered, V any] struct {
+    root  *node[K, V]
+    count int
+}
+
+// Len returns the number of keys in the map.
+func (m *Map[K, V]) Len() int {
+    return m.count
+}
+
+// Clear removes every key from the map.
+func (m *Map[K, V]) Clear() {
+    m.root = nil
+    m.count = 0
+}
+
+// Put sets the value for a key, and returns true if it replaced an existing
+// value.
+func (m *Map[K, V]) Put(key K, value V) bool {
+    if m.root == nil { m.root = &node[K, V]{} }
+    if len(m.root.entries) == maxEntries {
+        root := &node[K, V]{children: []*node[K, V]{m.root}}
+        root.splitChild(0)
+        m.root = root
+    }
+
+    n := m.root
+    for {
+        i, found := n.search(key)
+        if found {
+            n.entries[i].Value = value
+            return true
+        }
+        if n.leaf() {
+            n.entries = slices.Insert(n.entries, i, Entry[K, V]{key, value})
+            m.count++
+            return false
+        }
+        if len(n.children[i].entries) == maxEntries {
+            n.splitChild(i)
+            if n.entries[i].Key < key {
+                i++
+            } else if !(key < n.entries[i].Key) {
+                n.entries[i].Value = value
+                return true
+            }
+        }
+        n = n.children[i]
+    }
+}
+
+// Get returns the value for a key, or false if the key is not in the map.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+    for n := m.root; n != nil; {
+        i, found := n.search(key)
+        if found { return n.entries[i].Value, true }
+        if n.leaf() { break }
+        n = n.children[i]
+    }
+    var zero V
+    return zero, false
+}
+
+// Contains returns true if the key is in the map.
+func (m *Map[K, V]) Contains(key K) bool {
+    _, ok := m.Get(key)
+    return ok
+}
+
+// Delete removes a key from the map, and returns true if it was in the map.
+func (m *Map[K, V]) Delete(key K) bool {
+    if m.root == nil { return false }
+    ok := m.root.delete(key)
+    if ok { m.count-- }
+    if len(m.root.entries) == 0 {
+        if m.root.leaf() {
+            m.root = nil
+        } else {
+            m.root = m.root.children[0]
+        }
+    }
+    return ok
+}
+
+// Min returns the entry with the least key, or false if the map is empty.
+func (m *Map[K, V]) Min() (Entry[K, V], bool) {
+    if m.root == nil { return Entry[K, V]{}, false }
+    return m.root.first(), true
+}
+
+// Max returns the entry with the greatest key, or false if the map is
+// empty.
+func (m *Map[K, V]) Max() (Entry[K, V], bool) {
+    if m.root == nil { return Entry[K, V]{}, false }
+    return m.root.last(), true
+}
+
+// below returns the entry with the greatest key less than (or, if
+// inclusive, equal to) key.
+func (m *Map[K, V]) below(key K, inclusive bool) (Entry[K, V], bool) {
+    var result Entry[K, V]
+    var ok bool
+    for n := m.root; n != nil; {
+        i, found := n.search(key)
+        if found && inclusive { return n.entries[i], true }
+        if i > 0 { result, ok = n.entries[i - 1], true }
+        if n.leaf() { break }
+        n = n.children[i]
+    }
+    return result, ok
+}
+
+// above returns the entry with the least key greater than (or, if
+// inclusive, equal to) key.
+func (m *Map[K, V]) above(key K, inclusive bool) (Entry[K, V], bool) {
+    var result Entry[K, V]
+    var ok bool
+    for n := m.root; n != nil; {
+        i, found := n.search(key)
+        if found {
+            if inclusive { return n.entries[i], true }
+            i++
+        }
+        if i < len(n.entries) { result, ok = n.entries[i], true }
+        if n.leaf() { break }
+        n = n.children[i]
+    }
+    return result, ok
+}
+
+// Floor returns the entry with the greatest key less than or equal to key,
+// or false if there is none.
+func (m *Map[K, V]) Floor(key K) (Entry[K, V], bool) {
+    return m.below(key, true)
+}
+
+// Ceil returns the entry with the least key greater than or equal to key,
+// or false if there is none.
+func (m *Map[K, V]) Ceil(key K) (Entry[K, V], bool) {
+    return m.above(key, true)
+}
+
+// Lower returns the entry with the greatest key strictly less than key, or
+// false if there is none.
+func (m *Map[K, V]) Lower(key K) (Entry[K, V], bool) {
+    return m.below(key, false)
+}
+
+// Higher returns the entry with the least key strictly greater than key, or
+// false if there is none.
+func (m *Map[K, V]) Higher(key K) (Entry[K, V], bool) {
+    return m.above(key, false)
+}
+
+// frame is a position in a node during iteration.
+type frame[K constraints.Ordered, V any] struct {
+    n *node[K, V]
+    i int // index of the next entry in n
+}
+
+// scan returns an iterator over every entry, in increasing order of key,
+// starting with the first key not less than from, if bounded.
+func (m *Map[K, V]) scan(from K, bounded bool) iter.It[Entry[K, V]] {
+    var stack []frame[K, V]
+    for n := m.root; n != nil; {
+        i, found := 0, false
+        if bounded { i, found = n.search(from) }
+        stack = append(stack, frame[K, V]{n, i})
+        if found || n.leaf() { break }
+        n = n.children[i]
+    }
+
+    return func() (Entry[K, V], bool) {
+        for len(stack) > 0 {
+            top := &stack[len(stack) - 1]
+            if top.i == len(top.n.entries) {
+                stack = stack[:len(stack) - 1]
+                continue
+            }
+            e := top.n.entries[top.i]
+            top.i++
+            if !top.n.leaf() {
+                for n := top.n.children[top.i]; n != nil; {
+                    stack = append(stack, frame[K, V]{n, 0})
+                    if n.leaf() { break }
+                    n = n.children[0]
+                }
+            }
+            return e, true
+        }
+        return Entry[K, V]{}, false
+    }
+}
+
+// All returns an iterator over every entry, in increasing order of key. The
+// map must not be modified until the iterator is exhausted.
+func (m *Map[K, V]) All() iter.It[Entry[K, V]] {
+    var zero K
+    return m.scan(zero, false)
+}
+
+// Range returns an iterator over every entry with a key in the half-open
+// range from <= key < to, in increasing order of key. The map must not be
+// modified until the iterator is exhausted.
+func (m *Map[K, V]) Range(from, to K) iter.It[Entry[K, V]] {
+    it := m.scan(from, true)
+    return func() (Entry[K, V], bool) {
+        e, ok := it()
+        if !ok || !(e.Key < to) {
+            it = iter.Empty[Entry[K, V]]()
+            return Entry[K, V]{}, false
+        }
+        return e, true
+    }
+}
+
+// Set is a set of keys, sorted in order.
+//
+// The zero-value Set is a useful value. A Set is not suitable for concurrent
+// use without additional synchronization.
+type Set[K constraints.Ordered] struct {
+    m Map[K, struct{}]
+}
+
+// Len returns the number of keys in the set.
+func (s *Set[K]) Len() int {
+    return s.m.Len()
+}
+
+// Clear removes every key from the set.
+func (s *Set[K]) Clear() {
+    s.m.Clear()
+}
+
+// Add adds a key to the set, and returns true if it was not already in the
+// set.
+func (s *Set[K]) Add(key K) bool {
+    return !s.m.Put(key, struct{}{})
+}
+
+// Contains returns true if the key is in the set.
+func (s *Set[K]) Contains(key K) bool {
+    return s.m.Contains(key)
+}
+
+// Remove removes a key from the set, and returns true if it was in the set.
+func (s *Set[K]) Remove(key K) bool {
+    return s.m.Delete(key)
+}
+
+func key[K constraints.Ordered](e Entry[K, struct{}], ok bool) (K, bool) {
+    return e.Key, ok
+}
+
+// Min returns the least key, or false if the set is empty.
+func (s *Set[K]) Min() (K, bool) {
+    return key(s.m.Min())
+}
+
+// Max returns the greatest key, or false if the set is empty.
+func (s *Set[K]) Max() (K, bool) {
+    return key(s.m.Max())
+}
+
+// Floor returns the greatest key in the set less than or equal to x, or
+// false if there is none.
+func (s *Set[K]) Floor(x K) (K, bool) {
+    return key(s.m.Floor(x))
+}
+
+// Ceil returns the least key in the set greater than or equal to x, or false
+// if there is none.
+func (s *Set[K]) Ceil(x K) (K, bool) {
+    return key(s.m.Ceil(x))
+}
+
+// Lower returns the greatest key in the set strictly less than x, or false
+// if there is none.
+func (s *Set[K]) Lower(x K) (K, bool) {
+    return key(s.m.Lower(x))
+}
+
+// Higher returns the least key in the set strictly greater than x, or false
+// if there is none.
+func (s *Set[K]) Higher(x K) (K, bool) {
+    return key(s.m.Higher(x))
+}
+
+// All returns an iterator over every key, in increasing order. The set must
+// not be modified until the iterator is exhausted.
+func (s *Set[K]) All() iter.It[K] {
+    return keys(s.m.All())
+}
+
+// Range returns an iterator over every key in the half-open range
+// from <= key < to, in increasing order. The set must not be modified until
+// the iterator is exhausted.
+func (s *Set[K]) Range(from, to K) iter.It[K] {
+    return keys(s.m.Range(from, to))
+}
+
+func keys[K constraints.Ordered](it iter.It[Entry[K, struct{}]]) iter.It[K] {
+    return func() (K, bool) {
+        return key(it())
+    }
+}
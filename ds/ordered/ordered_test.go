@@ -0,0 +1,168 @@
+package ordered_test
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/ordered"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+func ExampleMap() {
+    var m ordered.Map[int, string]
+    m.Put(30, "thirty")
+    m.Put(10, "ten")
+    m.Put(20, "twenty")
+    m.Put(40, "forty")
+
+    for it := m.Range(15, 40); ; {
+        e, ok := it()
+        if !ok { break }
+        fmt.Println(e.Key, e.Value)
+    }
+
+    floor, _ := m.Floor(29)
+    ceil, _ := m.Ceil(29)
+    fmt.Printf("nearest to 29: %d, %d\n", floor.Key, ceil.Key)
+
+    // Output:
+    // 20 twenty
+    // 30 thirty
+    // nearest to 29: 20, 30
+}
+
+// model is a sorted slice of unique keys
+type model []int
+
+func (m model) search(k int) (int, bool) {
+    i := sort.SearchInts(m, k)
+    return i, (i < len(m)) && (m[i] == k)
+}
+
+func TestMap(t *testing.T) {
+    // compare against a sorted slice, with enough keys that the tree has
+    // several levels
+    var m ordered.Map[int, int]
+    var expected model
+
+    check := func() {
+        assert.Equal(t, len(expected), m.Len())
+        var keys []int
+        for _, e := range iter.ToSlice(m.All()) {
+            keys = append(keys, e.Key)
+            assert.Equal(t, -e.Key, e.Value)
+        }
+        assert.Equal(t, []int(expected), keys)
+    }
+
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 20000; i++ {
+        k := r.Intn(3000)
+        idx, exists := expected.search(k)
+        if (i > 10000) && (r.Intn(2) == 0) {
+            assert.Equal(t, exists, m.Delete(k))
+            if exists { expected = append(expected[:idx], expected[idx+1:]...) }
+        } else {
+            assert.Equal(t, exists, m.Put(k, -k))
+            if !exists { expected = append(expected[:idx], append(model{k}, expected[idx:]...)...) }
+        }
+        if i % 1000 == 0 { check() }
+    }
+    check()
+
+    for k := -1; k <= 3001; k++ {
+        idx, exists := expected.search(k)
+        v, ok := m.Get(k)
+        assert.Equal(t, exists, ok)
+        assert.Equal(t, exists, m.Contains(k))
+        if ok { assert.Equal(t, -k, v) }
+
+        expect := func(e ordered.Entry[int, int], ok bool, i int) {
+            if (i < 0) || (i >= len(expected)) {
+                assert.False(t, ok, "key %d", k)
+            } else if assert.True(t, ok, "key %d", k) {
+                assert.Equal(t, expected[i], e.Key)
+                assert.Equal(t, -expected[i], e.Value)
+            }
+        }
+
+        lower, higher := idx - 1, idx
+        if exists { higher++ }
+        floor, ceil := lower, higher
+        if exists { floor, ceil = idx, idx }
+        e, ok := m.Floor(k)
+        expect(e, ok, floor)
+        e, ok = m.Ceil(k)
+        expect(e, ok, ceil)
+        e, ok = m.Lower(k)
+        expect(e, ok, lower)
+        e, ok = m.Higher(k)
+        expect(e, ok, higher)
+    }
+
+    min, _ := m.Min()
+    max, _ := m.Max()
+    assert.Equal(t, expected[0], min.Key)
+    assert.Equal(t, expected[len(expected) - 1], max.Key)
+
+    for i := 0; i < 200; i++ {
+        from, to := r.Intn(3100) - 50, r.Intn(3100) - 50
+        start, _ := expected.search(from)
+        end, _ := expected.search(to)
+        keys := []int{}
+        for _, e := range iter.ToSlice(m.Range(from, to)) {
+            keys = append(keys, e.Key)
+        }
+        if from >= to {
+            assert.Empty(t, keys)
+        } else {
+            assert.Equal(t, []int(expected[start:end]), keys, "range %d, %d", from, to)
+        }
+    }
+
+    for _, k := range append(model{}, expected...) {
+        assert.True(t, m.Delete(k))
+    }
+    assert.Equal(t, 0, m.Len())
+    assert.False(t, m.Delete(0))
+    _, ok := m.Min()
+    assert.False(t, ok)
+    _, ok = m.Floor(0)
+    assert.False(t, ok)
+    assert.Empty(t, iter.ToSlice(m.All()))
+}
+
+func TestSet(t *testing.T) {
+    var s ordered.Set[string]
+    assert.True(t, s.Add("banana"))
+    assert.True(t, s.Add("apple"))
+    assert.True(t, s.Add("cherry"))
+    assert.False(t, s.Add("apple"))
+    assert.Equal(t, 3, s.Len())
+    assert.True(t, s.Contains("banana"))
+
+    assert.Equal(t, []string{"apple", "banana", "cherry"}, iter.ToSlice(s.All()))
+    assert.Equal(t, []string{"banana"}, iter.ToSlice(s.Range("b", "c")))
+
+    x, ok := s.Higher("apple")
+    assert.True(t, ok)
+    assert.Equal(t, "banana", x)
+    x, ok = s.Lower("apple")
+    assert.False(t, ok)
+    x, _ = s.Floor("blueberry")
+    assert.Equal(t, "banana", x)
+    x, _ = s.Ceil("blueberry")
+    assert.Equal(t, "cherry", x)
+    x, _ = s.Min()
+    assert.Equal(t, "apple", x)
+    x, _ = s.Max()
+    assert.Equal(t, "cherry", x)
+
+    assert.True(t, s.Remove("banana"))
+    assert.False(t, s.Remove("banana"))
+    s.Clear()
+    assert.Equal(t, 0, s.Len())
+}
@@ -0,0 +1,179 @@
+// Package ringbuffer implements a fixed-capacity first-in, first-out ring
+// buffer (circular buffer) of any type.
+//
+// A [Ring] of bytes implements [io.Reader] and [io.Writer], and so can be
+// used as a bounded pipe between a producer and a consumer in the same
+// goroutine. A Ring created with [NewOverwriting] never fills up: instead,
+// the oldest values are discarded to make space for new ones, which is
+// useful for keeping only the most recent lines of a log.
+package ringbuffer
+
+import (
+    "errors"
+    "io"
+)
+
+var ErrFull = errors.New("ring buffer is full")
+var ErrRange = errors.New("value out of range")
+
+// Ring is a fixed-capacity ring buffer.
+//
+// A Ring must be created with [New] or [NewOverwriting]. It is not suitable
+// for concurrent use without additional synchronization.
+type Ring[T any] struct {
+    buf       []T
+    head      int // index of the oldest value
+    count     int
+    overwrite bool
+    discarded int64
+}
+
+// New returns a new, empty, Ring with the given capacity, where a write to
+// a full Ring fails with [ErrFull].
+//
+// If capacity is not greater than zero, panics with [ErrRange].
+func New[T any](capacity int) *Ring[T] {
+    if capacity <= 0 { panic(ErrRange) }
+    return &Ring[T]{buf: make([]T, capacity)}
+}
+
+// NewOverwriting returns a new, empty, Ring with the given capacity, where a
+// write to a full Ring discards the oldest values to make space.
+//
+// If capacity is not greater than zero, panics with [ErrRange].
+func NewOverwriting[T any](capacity int) *Ring[T] {
+    r := New[T](capacity)
+    r.overwrite = true
+    return r
+}
+
+// Len returns the number of values in the ring.
+func (r *Ring[T]) Len() int {
+    return r.count
+}
+
+// Cap returns the capacity of the ring.
+func (r *Ring[T]) Cap() int {
+    return len(r.buf)
+}
+
+// Free returns the number of values that can be written before the ring is
+// full.
+func (r *Ring[T]) Free() int {
+    return len(r.buf) - r.count
+}
+
+// Discarded returns the total number of values discarded by writes to a
+// full overwriting Ring.
+func (r *Ring[T]) Discarded() int64 {
+    return r.discarded
+}
+
+// Reset removes every value from the ring.
+func (r *Ring[T]) Reset() {
+    clear(r.buf)
+    r.head = 0
+    r.count = 0
+}
+
+// index returns the index in buf of the i'th oldest value.
+func (r *Ring[T]) index(i int) int {
+    i += r.head
+    if i >= len(r.buf) { i -= len(r.buf) }
+    return i
+}
+
+// Push writes a single value. It returns [ErrFull] if the ring is full and
+// not overwriting, in which case the value is not written.
+func (r *Ring[T]) Push(x T) error {
+    _, err := r.Write([]T{x})
+    return err
+}
+
+// Pop reads and removes the oldest value, or returns false if the ring is
+// empty.
+func (r *Ring[T]) Pop() (T, bool) {
+    var x [1]T
+    n, _ := r.Read(x[:])
+    return x[0], n == 1
+}
+
+// Write writes values from p into the ring, and returns the number of
+// values written.
+//
+// If the ring is not overwriting, and there is not enough space for every
+// value in p, as many values as fit are written, and the error is
+// [ErrFull]. If the ring is overwriting, every value is written (or, if
+// len(p) is greater than the capacity, the last values of p are written),
+// discarding the oldest values as necessary, and the error is always nil.
+func (r *Ring[T]) Write(p []T) (int, error) {
+    var err error
+    written := len(p)
+    if r.overwrite {
+        if len(p) > len(r.buf) {
+            r.discarded += int64(len(p) - len(r.buf))
+            p = p[len(p) - len(r.buf):]
+        }
+        if excess := len(p) - r.Free(); excess > 0 {
+            r.Discard(excess)
+            r.discarded += int64(excess)
+        }
+    } else if len(p) > r.Free() {
+        p = p[:r.Free()]
+        written = len(p)
+        err = ErrFull
+    }
+
+    for len(p) > 0 {
+        tail := r.index(r.count)
+        n := copy(r.buf[tail:min(len(r.buf), tail + r.Free())], p)
+        r.count += n
+        p = p[n:]
+    }
+    return written, err
+}
+
+// Peek copies up to len(p) of the oldest values into p, without removing
+// them, and returns the number of values copied.
+func (r *Ring[T]) Peek(p []T) int {
+    n := min(len(p), r.count)
+    copied := 0
+    for copied < n {
+        start := r.index(copied)
+        end := min(len(r.buf), start + (n - copied))
+        copied += copy(p[copied:n], r.buf[start:end])
+    }
+    return n
+}
+
+// Discard removes up to n of the oldest values, without reading them, and
+// returns the number of values removed.
+func (r *Ring[T]) Discard(n int) int {
+    n = max(0, min(n, r.count))
+    for i := 0; i < n; i++ {
+        var zero T
+        r.buf[r.index(i)] = zero // allow garbage collection
+    }
+    r.head = r.index(n)
+    r.count -= n
+    if r.count == 0 { r.head = 0 }
+    return n
+}
+
+// Read reads and removes up to len(p) of the oldest values into p, and
+// returns the number of values read. If the ring is empty, and p is not
+// empty, the error is [io.EOF].
+func (r *Ring[T]) Read(p []T) (int, error) {
+    if (r.count == 0) && (len(p) > 0) { return 0, io.EOF }
+    n := r.Peek(p)
+    r.Discard(n)
+    return n, nil
+}
+
+// Values returns a new slice of every value in the ring, from oldest to
+// newest, without removing them.
+func (r *Ring[T]) Values() []T {
+    result := make([]T, r.count)
+    r.Peek(result)
+    return result
+}
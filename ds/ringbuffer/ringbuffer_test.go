@@ -0,0 +1,164 @@
+package ringbuffer_test
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "math/rand"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/ringbuffer"
+)
+
+func ExampleNewOverwriting() {
+    // keep only the three most recent log lines
+    log := ringbuffer.NewOverwriting[string](3)
+    for i := 1; i <= 5; i++ {
+        log.Push(fmt.Sprintf("line %d", i))
+    }
+
+    fmt.Println(log.Values(), log.Discarded())
+
+    // Output:
+    // [line 3 line 4 line 5] 2
+}
+
+func ExampleRing_Read() {
+    // a Ring of bytes is an io.Reader and an io.Writer
+    pipe := ringbuffer.New[byte](64)
+    fmt.Fprintf(pipe, "hello\nworld\n")
+
+    scanner := bufio.NewScanner(pipe)
+    for scanner.Scan() {
+        fmt.Println(strings.ToUpper(scanner.Text()))
+    }
+
+    // Output:
+    // HELLO
+    // WORLD
+}
+
+var _ io.ReadWriter = ringbuffer.New[byte](1)
+
+func TestNew(t *testing.T) {
+    assert.PanicsWithValue(t, ringbuffer.ErrRange, func() { ringbuffer.New[int](0) })
+    assert.PanicsWithValue(t, ringbuffer.ErrRange, func() { ringbuffer.NewOverwriting[int](-1) })
+}
+
+func TestRing_Full(t *testing.T) {
+    r := ringbuffer.New[int](4)
+    n, err := r.Write([]int{1, 2, 3})
+    assert.Equal(t, 3, n)
+    assert.Nil(t, err)
+
+    n, err = r.Write([]int{4, 5, 6})
+    assert.Equal(t, 1, n)
+    assert.ErrorIs(t, err, ringbuffer.ErrFull)
+    assert.ErrorIs(t, r.Push(7), ringbuffer.ErrFull)
+    assert.Equal(t, []int{1, 2, 3, 4}, r.Values())
+    assert.Equal(t, 0, r.Free())
+    assert.Equal(t, int64(0), r.Discarded())
+
+    x, ok := r.Pop()
+    assert.True(t, ok)
+    assert.Equal(t, 1, x)
+    assert.Nil(t, r.Push(5))
+    assert.Equal(t, []int{2, 3, 4, 5}, r.Values())
+
+    r.Reset()
+    assert.Equal(t, 0, r.Len())
+    _, ok = r.Pop()
+    assert.False(t, ok)
+    n, err = r.Read(make([]int, 1))
+    assert.Equal(t, 0, n)
+    assert.Equal(t, io.EOF, err)
+    n, err = r.Read(nil)
+    assert.Equal(t, 0, n)
+    assert.Nil(t, err)
+}
+
+func TestRing_Overwrite(t *testing.T) {
+    r := ringbuffer.NewOverwriting[int](4)
+    n, err := r.Write([]int{1, 2, 3})
+    assert.Equal(t, 3, n)
+    assert.Nil(t, err)
+
+    n, err = r.Write([]int{4, 5, 6})
+    assert.Equal(t, 3, n)
+    assert.Nil(t, err)
+    assert.Equal(t, []int{3, 4, 5, 6}, r.Values())
+    assert.Equal(t, int64(2), r.Discarded())
+
+    n, err = r.Write([]int{7, 8, 9, 10, 11, 12})
+    assert.Equal(t, 6, n)
+    assert.Nil(t, err)
+    assert.Equal(t, []int{9, 10, 11, 12}, r.Values())
+    assert.Equal(t, int64(8), r.Discarded())
+}
+
+func TestRing_Random(t *testing.T) {
+    // compare against a slice, with enough operations that the ring wraps
+    // around many times
+    for _, overwrite := range []bool{false, true} {
+        const capacity = 13
+        var r *ringbuffer.Ring[int]
+        if overwrite {
+            r = ringbuffer.NewOverwriting[int](capacity)
+        } else {
+            r = ringbuffer.New[int](capacity)
+        }
+        expected := []int{}
+        var discarded int64
+
+        rnd := rand.New(rand.NewSource(0))
+        next := 0
+        for i := 0; i < 5000; i++ {
+            p := make([]int, rnd.Intn(capacity + 5))
+            switch rnd.Intn(4) {
+                case 0:
+                    for j := range p {
+                        p[j] = next
+                        next++
+                    }
+                    n, err := r.Write(p)
+                    expected = append(expected, p[:n]...)
+                    if len(expected) > capacity {
+                        discarded += int64(len(expected) - capacity)
+                        expected = expected[len(expected) - capacity:]
+                    }
+                    if overwrite {
+                        assert.Equal(t, len(p), n)
+                        assert.Nil(t, err)
+                    } else if n < len(p) {
+                        assert.Equal(t, ringbuffer.ErrFull, err)
+                    } else {
+                        assert.Nil(t, err)
+                    }
+                case 1:
+                    n, err := r.Read(p)
+                    if (len(expected) == 0) && (len(p) > 0) {
+                        assert.Equal(t, io.EOF, err)
+                    } else {
+                        assert.Nil(t, err)
+                    }
+                    assert.Equal(t, min(len(p), len(expected)), n)
+                    assert.Equal(t, expected[:n], p[:n])
+                    expected = expected[n:]
+                case 2:
+                    n := r.Peek(p)
+                    assert.Equal(t, min(len(p), len(expected)), n)
+                    assert.Equal(t, expected[:n], p[:n])
+                case 3:
+                    n := r.Discard(len(p))
+                    assert.Equal(t, min(len(p), len(expected)), n)
+                    expected = expected[n:]
+            }
+            assert.Equal(t, len(expected), r.Len())
+            assert.Equal(t, capacity - len(expected), r.Free())
+        }
+        assert.Equal(t, expected, r.Values())
+        assert.Equal(t, discarded, r.Discarded())
+    }
+}
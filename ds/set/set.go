@@ -0,0 +1,303 @@
+// Package set implements generic set types: [Set], for any comparable
+// values, backed by a map, and [DenseSet], for small non-negative integers
+// such as indexes, backed by a [bitseq.Store].
+//
+// Both types have the same methods, including union, intersection, and
+// difference, and the same zero-value behaviour.
+package set
+
+import (
+    "github.com/tawesoft/golib/v2/ds/bitseq"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// ErrRange is raised in a panic when a negative value is added to a
+// DenseSet.
+var ErrRange = bitseq.ErrRange
+
+// Set is an unordered set of comparable values.
+//
+// The zero-value Set is a useful value. A Set is not suitable for concurrent
+// use without additional synchronization.
+type Set[T comparable] struct {
+    m map[T]struct{}
+}
+
+// New returns a new Set containing the given values.
+func New[T comparable](xs ... T) *Set[T] {
+    s := &Set[T]{}
+    s.Add(xs...)
+    return s
+}
+
+// FromIter returns a new Set containing every value produced by an iterator.
+func FromIter[T comparable](it iter.It[T]) *Set[T] {
+    s := &Set[T]{}
+    s.AddIter(it)
+    return s
+}
+
+// Len returns the number of values in the set.
+func (s *Set[T]) Len() int {
+    return len(s.m)
+}
+
+// Clear removes every value from the set.
+func (s *Set[T]) Clear() {
+    s.m = nil
+}
+
+// Contains returns true if x is in the set.
+func (s *Set[T]) Contains(x T) bool {
+    _, ok := s.m[x]
+    return ok
+}
+
+// Add adds each of the given values to the set.
+func (s *Set[T]) Add(xs ... T) {
+    if s.m == nil { s.m = make(map[T]struct{}, len(xs)) }
+    for _, x := range xs {
+        s.m[x] = struct{}{}
+    }
+}
+
+// AddIter adds every value produced by an iterator to the set.
+func (s *Set[T]) AddIter(it iter.It[T]) {
+    for {
+        x, ok := it()
+        if !ok { break }
+        s.Add(x)
+    }
+}
+
+// Remove removes x from the set, and returns true if it was in the set.
+func (s *Set[T]) Remove(x T) bool {
+    _, ok := s.m[x]
+    delete(s.m, x)
+    return ok
+}
+
+// Values returns an iterator over every value in the set, in an arbitrary
+// order. The set must not be modified until the iterator is exhausted.
+func (s *Set[T]) Values() iter.It[T] {
+    return iter.Keys(iter.FromMap(s.m))
+}
+
+// ToSlice returns a new slice of every value in the set, in an arbitrary
+// order.
+func (s *Set[T]) ToSlice() []T {
+    result := make([]T, 0, len(s.m))
+    for x := range s.m {
+        result = append(result, x)
+    }
+    return result
+}
+
+// Clone returns a new Set containing the same values.
+func (s *Set[T]) Clone() *Set[T] {
+    result := &Set[T]{m: make(map[T]struct{}, len(s.m))}
+    for x := range s.m {
+        result.m[x] = struct{}{}
+    }
+    return result
+}
+
+// Equal returns true if both sets contain exactly the same values.
+func (s *Set[T]) Equal(t *Set[T]) bool {
+    return (len(s.m) == len(t.m)) && s.SubsetOf(t)
+}
+
+// SubsetOf returns true if every value in s is also in t.
+func (s *Set[T]) SubsetOf(t *Set[T]) bool {
+    if len(s.m) > len(t.m) { return false }
+    for x := range s.m {
+        if !t.Contains(x) { return false }
+    }
+    return true
+}
+
+// Union returns a new Set containing every value that is in s, t, or both.
+func (s *Set[T]) Union(t *Set[T]) *Set[T] {
+    result := s.Clone()
+    for x := range t.m {
+        result.m[x] = struct{}{}
+    }
+    return result
+}
+
+// Intersection returns a new Set containing every value that is in both s
+// and t.
+func (s *Set[T]) Intersection(t *Set[T]) *Set[T] {
+    small, large := s, t
+    if len(small.m) > len(large.m) { small, large = large, small }
+    result := &Set[T]{}
+    for x := range small.m {
+        if large.Contains(x) { result.Add(x) }
+    }
+    return result
+}
+
+// Difference returns a new Set containing every value that is in s but not
+// in t.
+func (s *Set[T]) Difference(t *Set[T]) *Set[T] {
+    result := &Set[T]{}
+    for x := range s.m {
+        if !t.Contains(x) { result.Add(x) }
+    }
+    return result
+}
+
+// SymmetricDifference returns a new Set containing every value that is in
+// either s or t, but not both.
+func (s *Set[T]) SymmetricDifference(t *Set[T]) *Set[T] {
+    result := s.Difference(t)
+    for x := range t.m {
+        if !s.Contains(x) { result.Add(x) }
+    }
+    return result
+}
+
+// DenseSet is an ordered set of non-negative integers, represented as a
+// sequence of bits. It is compact and efficient for small integers, such
+// as indexes into an array, but the memory used is proportional to the
+// largest value in the set.
+//
+// The zero-value DenseSet is a useful value. A DenseSet is not suitable for
+// concurrent use without additional synchronization.
+type DenseSet struct {
+    bits bitseq.Store
+}
+
+// NewDense returns a new DenseSet containing the given values.
+//
+// If any value is negative, panics with [ErrRange].
+func NewDense(xs ... int) *DenseSet {
+    s := &DenseSet{}
+    s.Add(xs...)
+    return s
+}
+
+// DenseFromIter returns a new DenseSet containing every value produced by an
+// iterator.
+//
+// If any value is negative, panics with [ErrRange].
+func DenseFromIter(it iter.It[int]) *DenseSet {
+    s := &DenseSet{}
+    s.AddIter(it)
+    return s
+}
+
+// Len returns the number of values in the set.
+func (s *DenseSet) Len() int {
+    return s.bits.CountTrue()
+}
+
+// Clear removes every value from the set.
+func (s *DenseSet) Clear() {
+    s.bits = bitseq.Store{}
+}
+
+// Contains returns true if x is in the set. A negative x is never in the
+// set.
+func (s *DenseSet) Contains(x int) bool {
+    return (x >= 0) && s.bits.Get(x)
+}
+
+// Add adds each of the given values to the set.
+//
+// If any value is negative, panics with [ErrRange].
+func (s *DenseSet) Add(xs ... int) {
+    for _, x := range xs {
+        s.bits.Set(x, true)
+    }
+}
+
+// AddIter adds every value produced by an iterator to the set.
+//
+// If any value is negative, panics with [ErrRange].
+func (s *DenseSet) AddIter(it iter.It[int]) {
+    for {
+        x, ok := it()
+        if !ok { break }
+        s.bits.Set(x, true)
+    }
+}
+
+// Remove removes x from the set, and returns true if it was in the set.
+func (s *DenseSet) Remove(x int) bool {
+    if !s.Contains(x) { return false }
+    s.bits.Set(x, false)
+    return true
+}
+
+// Values returns an iterator over every value in the set, in increasing
+// order. The set must not be modified until the iterator is exhausted.
+func (s *DenseSet) Values() iter.It[int] {
+    current := -1
+    return func() (int, bool) {
+        next, ok := s.bits.NextTrue(current)
+        if !ok { return 0, false }
+        current = next
+        return next, true
+    }
+}
+
+// ToSlice returns a new slice of every value in the set, in increasing
+// order.
+func (s *DenseSet) ToSlice() []int {
+    result := make([]int, 0, s.Len())
+    return iter.AppendToSlice(result, s.Values())
+}
+
+// Clone returns a new DenseSet containing the same values.
+func (s *DenseSet) Clone() *DenseSet {
+    return &DenseSet{bits: s.bits.Clone()}
+}
+
+// Equal returns true if both sets contain exactly the same values.
+func (s *DenseSet) Equal(t *DenseSet) bool {
+    return s.bits.Equal(t.bits)
+}
+
+// SubsetOf returns true if every value in s is also in t.
+func (s *DenseSet) SubsetOf(t *DenseSet) bool {
+    if s.Len() > t.Len() { return false }
+    return s.Difference(t).Len() == 0
+}
+
+// Union returns a new DenseSet containing every value that is in s, t, or
+// both.
+func (s *DenseSet) Union(t *DenseSet) *DenseSet {
+    result := s.Clone()
+    result.bits.Or(t.bits)
+    return result
+}
+
+// Intersection returns a new DenseSet containing every value that is in
+// both s and t.
+func (s *DenseSet) Intersection(t *DenseSet) *DenseSet {
+    result := s.Clone()
+    result.bits.And(t.bits)
+    return result
+}
+
+// Difference returns a new DenseSet containing every value that is in s but
+// not in t.
+func (s *DenseSet) Difference(t *DenseSet) *DenseSet {
+    result := s.Clone()
+    result.bits.AndNot(t.bits)
+    return result
+}
+
+// SymmetricDifference returns a new DenseSet containing every value that is
+// in either s or t, but not both.
+func (s *DenseSet) SymmetricDifference(t *DenseSet) *DenseSet {
+    result := s.Clone()
+    result.bits.Xor(t.bits)
+    return result
+}
+
+// ToSet returns a new (map-backed) Set containing the same values.
+func (s *DenseSet) ToSet() *Set[int] {
+    return FromIter(s.Values())
+}
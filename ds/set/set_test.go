@@ -0,0 +1,85 @@
+package set_test
+
+import (
+    "fmt"
+    "sort"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/set"
+)
+
+func ExampleDenseSet() {
+    a := set.NewDense(1, 2, 3, 64, 100)
+    b := set.NewDense(2, 3, 4, 100)
+
+    fmt.Println(a.Union(b).ToSlice())
+    fmt.Println(a.Intersection(b).ToSlice())
+    fmt.Println(a.Difference(b).ToSlice())
+    fmt.Println(a.SymmetricDifference(b).ToSlice())
+
+    // Output:
+    // [1 2 3 4 64 100]
+    // [2 3 100]
+    // [1 64]
+    // [1 4 64]
+}
+
+func sorted(s *set.Set[string]) []string {
+    xs := s.ToSlice()
+    sort.Strings(xs)
+    return xs
+}
+
+func TestSet(t *testing.T) {
+    var s set.Set[string]
+    assert.Equal(t, 0, s.Len())
+    assert.False(t, s.Contains("a"))
+    assert.False(t, s.Remove("a"))
+
+    s.Add("a", "b", "c", "a")
+    assert.Equal(t, 3, s.Len())
+    assert.True(t, s.Contains("b"))
+    assert.True(t, s.Remove("b"))
+    assert.False(t, s.Contains("b"))
+
+    a := set.New("a", "b", "c")
+    b := set.New("b", "c", "d")
+    assert.Equal(t, []string{"a", "b", "c", "d"}, sorted(a.Union(b)))
+    assert.Equal(t, []string{"b", "c"}, sorted(a.Intersection(b)))
+    assert.Equal(t, []string{"a"}, sorted(a.Difference(b)))
+    assert.Equal(t, []string{"a", "d"}, sorted(a.SymmetricDifference(b)))
+
+    assert.True(t, set.New("b").SubsetOf(a))
+    assert.False(t, b.SubsetOf(a))
+    assert.True(t, a.Equal(a.Clone()))
+    assert.False(t, a.Equal(b))
+    assert.True(t, set.FromIter(a.Values()).Equal(a))
+
+    a.Clear()
+    assert.Equal(t, 0, a.Len())
+}
+
+func TestDenseSet(t *testing.T) {
+    var s set.DenseSet
+    assert.Equal(t, 0, s.Len())
+    assert.False(t, s.Contains(-1))
+    assert.False(t, s.Remove(5))
+    assert.PanicsWithValue(t, set.ErrRange, func() { s.Add(-1) })
+
+    s.Add(200, 0, 63, 64, 0)
+    assert.Equal(t, 4, s.Len())
+    assert.Equal(t, []int{0, 63, 64, 200}, s.ToSlice())
+    assert.True(t, s.Remove(63))
+    assert.Equal(t, []int{0, 64, 200}, s.ToSlice())
+
+    a := set.NewDense(1, 2, 3)
+    assert.True(t, set.NewDense(2, 3).SubsetOf(a))
+    assert.False(t, set.NewDense(3, 4).SubsetOf(a))
+    assert.True(t, a.Equal(a.Clone()))
+    assert.True(t, a.ToSet().Equal(set.New(1, 2, 3)))
+
+    a.Clear()
+    assert.Equal(t, 0, a.Len())
+    assert.Equal(t, []int{}, a.ToSlice())
+}
@@ -0,0 +1,202 @@
+// Package sparseset implements a set, and a map, keyed by small non-negative
+// integers, such as a [graph.VertexIndex] or an index into an array.
+//
+// A sparse set is a pair of arrays: a "sparse" array, indexed by key, that
+// gives each key's position in a "dense" array, which holds the keys (and,
+// for a [Map], the values) contiguously. This gives constant-time insertion,
+// removal, and lookup, a constant-time Clear, and fast iteration over a
+// contiguous slice, at the cost of memory proportional to the largest key.
+//
+// Removing a key moves the last element of the dense array into its place,
+// so the order of iteration changes as keys are removed.
+//
+// [graph.VertexIndex]: https://pkg.go.dev/github.com/tawesoft/golib/v2/ds/graph#VertexIndex
+package sparseset
+
+import (
+    "errors"
+
+    "golang.org/x/exp/constraints"
+)
+
+var ErrRange = errors.New("key out of range")
+
+// index is the sparse array shared by a Set and a Map.
+type index[I constraints.Integer] struct {
+    sparse []int // position of each key in the dense array, if valid
+    dense  []I
+}
+
+// find returns the position of a key in the dense array.
+func (x *index[I]) find(key I) (int, bool) {
+    if (key < 0) || (uint64(key) >= uint64(len(x.sparse))) { return -1, false }
+    i := x.sparse[key]
+    return i, (i < len(x.dense)) && (x.dense[i] == key)
+}
+
+// insert adds a key, which must not already exist, at the end of the dense
+// array.
+func (x *index[I]) insert(key I) {
+    if key < 0 { panic(ErrRange) }
+    if uint64(key) >= uint64(len(x.sparse)) {
+        n := max(int(key) + 1, 2 * len(x.sparse))
+        sparse := make([]int, n)
+        copy(sparse, x.sparse)
+        x.sparse = sparse
+    }
+    x.sparse[key] = len(x.dense)
+    x.dense = append(x.dense, key)
+}
+
+// remove removes the key at position i in the dense array, by moving the
+// last key into its place, and returns the position that the last key was
+// moved from.
+func (x *index[I]) remove(i int) int {
+    last := len(x.dense) - 1
+    moved := x.dense[last]
+    x.dense[i] = moved
+    x.sparse[moved] = i
+    x.dense = x.dense[:last]
+    return last
+}
+
+// Set is a set of small non-negative integers.
+//
+// The zero-value Set is a useful value. A Set is not suitable for concurrent
+// use without additional synchronization.
+type Set[I constraints.Integer] struct {
+    x index[I]
+}
+
+// Len returns the number of keys in the set.
+func (s *Set[I]) Len() int {
+    return len(s.x.dense)
+}
+
+// Clear removes every key from the set, in constant time, keeping the
+// underlying storage for reuse.
+func (s *Set[I]) Clear() {
+    s.x.dense = s.x.dense[:0]
+}
+
+// Contains returns true if the key is in the set.
+func (s *Set[I]) Contains(key I) bool {
+    _, ok := s.x.find(key)
+    return ok
+}
+
+// Add adds a key to the set, and returns true if it was not already in the
+// set.
+//
+// If the key is negative, panics with [ErrRange].
+func (s *Set[I]) Add(key I) bool {
+    if _, ok := s.x.find(key); ok { return false }
+    s.x.insert(key)
+    return true
+}
+
+// Remove removes a key from the set, and returns true if it was in the set.
+func (s *Set[I]) Remove(key I) bool {
+    i, ok := s.x.find(key)
+    if !ok { return false }
+    s.x.remove(i)
+    return true
+}
+
+// Keys returns every key in the set, in an arbitrary order, as a slice that
+// is valid until the set is next modified. The slice must not be modified.
+func (s *Set[I]) Keys() []I {
+    return s.x.dense
+}
+
+// Map is a map from small non-negative integers to values.
+//
+// The zero-value Map is a useful value. A Map is not suitable for concurrent
+// use without additional synchronization.
+type Map[I constraints.Integer, V any] struct {
+    x index[I]
+    values []V // in the same order as x.dense
+}
+
+// Len returns the number of keys in the map.
+func (m *Map[I, V]) Len() int {
+    return len(m.x.dense)
+}
+
+// Clear removes every key from the map, keeping the underlying storage for
+// reuse.
+func (m *Map[I, V]) Clear() {
+    clear(m.values) // allow garbage collection
+    m.values = m.values[:0]
+    m.x.dense = m.x.dense[:0]
+}
+
+// Contains returns true if the key is in the map.
+func (m *Map[I, V]) Contains(key I) bool {
+    _, ok := m.x.find(key)
+    return ok
+}
+
+// Get returns the value for a key, or false if the key is not in the map.
+func (m *Map[I, V]) Get(key I) (V, bool) {
+    i, ok := m.x.find(key)
+    if !ok {
+        var zero V
+        return zero, false
+    }
+    return m.values[i], true
+}
+
+// Ref returns a pointer to the value for a key, which may be used to modify
+// the value in place, or nil if the key is not in the map. The pointer is
+// valid until the map is next modified.
+func (m *Map[I, V]) Ref(key I) *V {
+    i, ok := m.x.find(key)
+    if !ok { return nil }
+    return &m.values[i]
+}
+
+// Put sets the value for a key, and returns true if it replaced an existing
+// value.
+//
+// If the key is negative, panics with [ErrRange].
+func (m *Map[I, V]) Put(key I, value V) bool {
+    if i, ok := m.x.find(key); ok {
+        m.values[i] = value
+        return true
+    }
+    m.x.insert(key)
+    m.values = append(m.values, value)
+    return false
+}
+
+// Delete removes a key from the map, and returns true if it was in the map.
+func (m *Map[I, V]) Delete(key I) bool {
+    i, ok := m.x.find(key)
+    if !ok { return false }
+    last := m.x.remove(i)
+    m.values[i] = m.values[last]
+    var zero V
+    m.values[last] = zero // allow garbage collection
+    m.values = m.values[:last]
+    return true
+}
+
+// Keys returns every key in the map, in an arbitrary order, as a slice that
+// is valid until the map is next modified. The slice must not be modified.
+//
+// The order matches the order of [Map.Values], so that Keys()[i] is the key
+// of Values()[i].
+func (m *Map[I, V]) Keys() []I {
+    return m.x.dense
+}
+
+// Values returns every value in the map, in an arbitrary order, as a slice
+// that is valid until the map is next modified. Values may be modified in
+// place, but the slice must not otherwise be modified.
+//
+// The order matches the order of [Map.Keys], so that Keys()[i] is the key
+// of Values()[i].
+func (m *Map[I, V]) Values() []V {
+    return m.values
+}
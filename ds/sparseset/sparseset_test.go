@@ -0,0 +1,110 @@
+package sparseset_test
+
+import (
+    "fmt"
+    "math/rand"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/sparseset"
+)
+
+func ExampleMap() {
+    type position struct { x, y int }
+
+    // components of an entity-component-system, keyed by entity ID
+    var positions sparseset.Map[uint32, position]
+    positions.Put(7, position{1, 2})
+    positions.Put(3, position{5, 5})
+    positions.Put(9, position{0, 0})
+    positions.Delete(7)
+
+    // iterate densely, updating in place
+    for i := range positions.Values() {
+        positions.Values()[i].x++
+    }
+
+    for i, id := range positions.Keys() {
+        fmt.Println(id, positions.Values()[i])
+    }
+
+    // Output:
+    // 9 {1 0}
+    // 3 {6 5}
+}
+
+func TestSet(t *testing.T) {
+    var s sparseset.Set[int]
+    expected := make(map[int]bool)
+
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 5000; i++ {
+        k := r.Intn(500)
+        if r.Intn(3) == 0 {
+            assert.Equal(t, expected[k], s.Remove(k))
+            delete(expected, k)
+        } else {
+            assert.Equal(t, !expected[k], s.Add(k))
+            expected[k] = true
+        }
+    }
+
+    assert.Equal(t, len(expected), s.Len())
+    for _, k := range s.Keys() {
+        assert.True(t, expected[k])
+    }
+    for k := range expected {
+        assert.True(t, s.Contains(k))
+    }
+    assert.False(t, s.Contains(-1))
+    assert.False(t, s.Contains(100000))
+    assert.PanicsWithValue(t, sparseset.ErrRange, func() { s.Add(-1) })
+
+    s.Clear()
+    assert.Equal(t, 0, s.Len())
+    for k := range expected {
+        assert.False(t, s.Contains(k))
+    }
+}
+
+func TestMap(t *testing.T) {
+    var m sparseset.Map[uint8, string]
+    expected := make(map[uint8]string)
+
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 5000; i++ {
+        k := uint8(r.Intn(256))
+        _, exists := expected[k]
+        if r.Intn(3) == 0 {
+            assert.Equal(t, exists, m.Delete(k))
+            delete(expected, k)
+        } else {
+            v := fmt.Sprint(i)
+            assert.Equal(t, exists, m.Put(k, v))
+            expected[k] = v
+        }
+    }
+
+    assert.Equal(t, len(expected), m.Len())
+    for i, k := range m.Keys() {
+        assert.Equal(t, expected[k], m.Values()[i])
+    }
+    for k, v := range expected {
+        got, ok := m.Get(k)
+        assert.True(t, ok)
+        assert.Equal(t, v, got)
+    }
+
+    for k := range expected {
+        *m.Ref(k) = "x"
+        got, _ := m.Get(k)
+        assert.Equal(t, "x", got)
+        break
+    }
+
+    m.Clear()
+    assert.Equal(t, 0, m.Len())
+    assert.Nil(t, m.Ref(0))
+    _, ok := m.Get(0)
+    assert.False(t, ok)
+}
@@ -0,0 +1,274 @@
+// Package trie implements a compressed radix tree (a space-optimised prefix
+// tree, or trie), that maps keys to values.
+//
+// Keys are strings or byte slices. Unlike a map, a [Tree] can efficiently
+// find the longest key that is a prefix of a given input (for example, to
+// match a path against a routing table), or every key that starts with a
+// given prefix (for example, for autocomplete), and iterates over keys in
+// lexicographic (byte-wise) order.
+package trie
+
+import (
+    "sort"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// Key is the type of a key in a [Tree].
+type Key interface {
+    ~string | ~[]byte
+}
+
+// Entry is a key, value pair stored in a [Tree].
+type Entry[K Key, V any] struct {
+    Key   K
+    Value V
+}
+
+type node[V any] struct {
+    // label is the part of the key on the edge from the parent to this node.
+    // Only the root has an empty label.
+    label string
+
+    value    V
+    hasValue bool
+
+    // children are ordered by the first byte of their label, which is
+    // unique among siblings.
+    children []*node[V]
+}
+
+// child returns the index of the child whose label starts with the byte c,
+// or the index where such a child would be inserted, and true if it exists.
+func (n *node[V]) child(c byte) (int, bool) {
+    i := sort.Search(len(n.children), func(i int) bool {
+        return n.children[i].label[0] >= c
+    })
+    return i, (i < len(n.children)) && (n.children[i].label[0] == c)
+}
+
+// Tree is a compressed radix tree.
+//
+// The zero-value Tree is a useful value. A Tree is not suitable for
+// concurrent use without additional synchronization.
+type Tree[K Key, V any] struct {
+    root  node[V]
+    count int
+}
+
+// Len returns the number of keys in the tree.
+func (t *Tree[K, V]) Len() int {
+    return t.count
+}
+
+// Clear removes every key from the tree.
+func (t *Tree[K, V]) Clear() {
+    t.root = node[V]{}
+    t.count = 0
+}
+
+// commonPrefix returns the length of the longest common prefix of a and b.
+func commonPrefix(a, b string) int {
+    n := min(len(a), len(b))
+    for i := 0; i < n; i++ {
+        if a[i] != b[i] { return i }
+    }
+    return n
+}
+
+// Put sets the value for a key, and returns true if it replaced an existing
+// value.
+func (t *Tree[K, V]) Put(key K, value V) bool {
+    k := string(key)
+    n := &t.root
+    for {
+        if len(k) == 0 {
+            replaced := n.hasValue
+            n.value, n.hasValue = value, true
+            if !replaced { t.count++ }
+            return replaced
+        }
+
+        i, ok := n.child(k[0])
+        if !ok {
+            leaf := &node[V]{label: k, value: value, hasValue: true}
+            n.children = append(n.children, nil)
+            copy(n.children[i + 1:], n.children[i:])
+            n.children[i] = leaf
+            t.count++
+            return false
+        }
+
+        c := n.children[i]
+        common := commonPrefix(k, c.label)
+        if common < len(c.label) {
+            // split the edge, e.g. adding "team" under "test" gives "te"
+            // with children "am" and "st".
+            split := &node[V]{
+                label: c.label[:common],
+                children: []*node[V]{c},
+            }
+            c.label = c.label[common:]
+            n.children[i] = split
+            c = split
+        }
+        n, k = c, k[common:]
+    }
+}
+
+// find returns the node with exactly the given key, or nil.
+func (t *Tree[K, V]) find(k string) *node[V] {
+    n := &t.root
+    for len(k) > 0 {
+        i, ok := n.child(k[0])
+        if !ok { return nil }
+        c := n.children[i]
+        if !strings.HasPrefix(k, c.label) { return nil }
+        n, k = c, k[len(c.label):]
+    }
+    return n
+}
+
+// Get returns the value for a key, or false if the key is not in the tree.
+func (t *Tree[K, V]) Get(key K) (V, bool) {
+    n := t.find(string(key))
+    if (n == nil) || !n.hasValue {
+        var zero V
+        return zero, false
+    }
+    return n.value, true
+}
+
+// Delete removes a key from the tree, and returns true if it existed.
+func (t *Tree[K, V]) Delete(key K) bool {
+    k := string(key)
+
+    // path of (parent, index of child) steps taken from the root
+    type step struct {
+        parent *node[V]
+        index int
+    }
+    var path []step
+
+    n := &t.root
+    for len(k) > 0 {
+        i, ok := n.child(k[0])
+        if !ok { return false }
+        c := n.children[i]
+        if !strings.HasPrefix(k, c.label) { return false }
+        path = append(path, step{n, i})
+        n, k = c, k[len(c.label):]
+    }
+    if !n.hasValue { return false }
+
+    var zero V
+    n.value, n.hasValue = zero, false
+    t.count--
+
+    // tidy up: remove the node if it is now an empty leaf, then merge any
+    // node without a value that is left with only one child.
+    if len(path) == 0 { return true } // the root
+    last := path[len(path) - 1]
+    if len(n.children) == 0 {
+        p := last.parent
+        p.children = append(p.children[:last.index], p.children[last.index + 1:]...)
+        n = p
+        if len(path) == 1 { return true } // the parent is the root
+        last = path[len(path) - 2]
+    }
+    if !n.hasValue && (len(n.children) == 1) {
+        c := n.children[0]
+        c.label = n.label + c.label
+        last.parent.children[last.index] = c
+    }
+    return true
+}
+
+// LongestPrefix returns the longest key in the tree that is a prefix of the
+// input (including the input itself), and its value, or false if no such
+// key exists.
+//
+// For example, in a tree containing the keys "/", "/static/", and
+// "/static/css/", the longest prefix of "/static/img/logo.png" is
+// "/static/".
+func (t *Tree[K, V]) LongestPrefix(input K) (K, V, bool) {
+    k := string(input)
+    var (
+        found  bool
+        length int
+        value  V
+    )
+
+    n, consumed := &t.root, 0
+    for {
+        if n.hasValue {
+            found, length, value = true, consumed, n.value
+        }
+        if consumed == len(k) { break }
+        i, ok := n.child(k[consumed])
+        if !ok { break }
+        c := n.children[i]
+        if !strings.HasPrefix(k[consumed:], c.label) { break }
+        n, consumed = c, consumed + len(c.label)
+    }
+
+    if !found {
+        var zero K
+        return zero, value, false
+    }
+    return K(k[:length]), value, true
+}
+
+// All returns an iterator over every key, value pair in the tree, in
+// lexicographic order of keys. The tree must not be modified until the
+// iterator is exhausted.
+func (t *Tree[K, V]) All() iter.It[Entry[K, V]] {
+    return t.WithPrefix(K(""))
+}
+
+// WithPrefix returns an iterator over every key, value pair in the tree
+// where the key starts with the given prefix, in lexicographic order of
+// keys. The tree must not be modified until the iterator is exhausted.
+func (t *Tree[K, V]) WithPrefix(prefix K) iter.It[Entry[K, V]] {
+    type item struct {
+        n *node[V]
+        key string // full key up to and including n
+    }
+
+    // find the node at or below the prefix
+    k := string(prefix)
+    n, consumed := &t.root, ""
+    for len(k) > 0 {
+        i, ok := n.child(k[0])
+        if !ok { return iter.Empty[Entry[K, V]]() }
+        c := n.children[i]
+        switch {
+            case strings.HasPrefix(k, c.label):
+                n, consumed, k = c, consumed + c.label, k[len(c.label):]
+            case strings.HasPrefix(c.label, k):
+                // the prefix ends part way along the edge to c
+                n, consumed, k = c, consumed + c.label, ""
+            default:
+                return iter.Empty[Entry[K, V]]()
+        }
+    }
+
+    // depth-first, pre-order traversal with an explicit stack, visiting
+    // children in order.
+    stack := []item{{n, consumed}}
+    return func() (Entry[K, V], bool) {
+        for len(stack) > 0 {
+            x := stack[len(stack) - 1]
+            stack = stack[:len(stack) - 1]
+            for i := len(x.n.children) - 1; i >= 0; i-- {
+                c := x.n.children[i]
+                stack = append(stack, item{c, x.key + c.label})
+            }
+            if x.n.hasValue {
+                return Entry[K, V]{K(x.key), x.n.value}, true
+            }
+        }
+        return Entry[K, V]{}, false
+    }
+}
@@ -0,0 +1,161 @@
+package trie_test
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/ds/trie"
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+func ExampleTree_LongestPrefix() {
+    var routes trie.Tree[string, string]
+    routes.Put("/", "index")
+    routes.Put("/static/", "files")
+    routes.Put("/static/css/", "stylesheets")
+
+    prefix, handler, _ := routes.LongestPrefix("/static/img/logo.png")
+    fmt.Println(prefix, handler)
+
+    // Output:
+    // /static/ files
+}
+
+func ExampleTree_WithPrefix() {
+    var words trie.Tree[string, int]
+    for i, w := range []string{"tea", "ten", "team", "to", "inn", "tee"} {
+        words.Put(w, i)
+    }
+
+    for it := words.WithPrefix("te"); ; {
+        e, ok := it()
+        if !ok { break }
+        fmt.Println(e.Key)
+    }
+
+    // Output:
+    // tea
+    // team
+    // tee
+    // ten
+}
+
+func keys[K trie.Key, V any](t *trie.Tree[K, V]) []string {
+    var result []string
+    iter.Walk(func(e trie.Entry[K, V]) {
+        result = append(result, string(e.Key))
+    }, t.All())
+    return result
+}
+
+func TestTree(t *testing.T) {
+    var tree trie.Tree[[]byte, int]
+    expected := make(map[string]int)
+
+    // random keys from a small alphabet, so that there are many shared
+    // prefixes, splits, and merges.
+    r := rand.New(rand.NewSource(0))
+    randomKey := func() string {
+        var sb strings.Builder
+        n := r.Intn(6)
+        for i := 0; i < n; i++ {
+            sb.WriteByte("abc"[r.Intn(3)])
+        }
+        return sb.String()
+    }
+
+    for i := 0; i < 5000; i++ {
+        k := randomKey()
+        if r.Intn(3) == 0 {
+            _, exists := expected[k]
+            assert.Equal(t, exists, tree.Delete([]byte(k)), "delete %q", k)
+            delete(expected, k)
+        } else {
+            _, exists := expected[k]
+            assert.Equal(t, exists, tree.Put([]byte(k), i), "put %q", k)
+            expected[k] = i
+        }
+        if !assert.Equal(t, len(expected), tree.Len()) { return }
+    }
+
+    var sorted []string
+    for k, v := range expected {
+        sorted = append(sorted, k)
+        got, ok := tree.Get([]byte(k))
+        assert.True(t, ok)
+        assert.Equal(t, v, got)
+    }
+    sort.Strings(sorted)
+    assert.Equal(t, sorted, keys(&tree))
+
+    _, ok := tree.Get([]byte("abcabcabc"))
+    assert.False(t, ok)
+
+    for k := range expected {
+        assert.True(t, tree.Delete([]byte(k)))
+    }
+    assert.Equal(t, 0, tree.Len())
+    assert.Nil(t, keys(&tree))
+}
+
+func TestTree_LongestPrefix(t *testing.T) {
+    var tree trie.Tree[string, int]
+    for i, k := range []string{"a", "abc", "abcde", "b"} {
+        tree.Put(k, i)
+    }
+
+    type row struct {
+        input string
+        prefix string
+        found bool
+    }
+    rows := []row{
+        {"",        "",      false},
+        {"a",       "a",     true},
+        {"ab",      "a",     true},
+        {"abc",     "abc",   true},
+        {"abcd",    "abc",   true},
+        {"abcdef",  "abcde", true},
+        {"abx",     "a",     true},
+        {"ba",      "b",     true},
+        {"c",       "",      false},
+    }
+    for _, r := range rows {
+        prefix, _, found := tree.LongestPrefix(r.input)
+        assert.Equal(t, r.found, found, "%q", r.input)
+        assert.Equal(t, r.prefix, prefix, "%q", r.input)
+    }
+
+    tree.Put("", -1)
+    prefix, value, found := tree.LongestPrefix("c")
+    assert.True(t, found)
+    assert.Equal(t, "", prefix)
+    assert.Equal(t, -1, value)
+}
+
+func TestTree_WithPrefix(t *testing.T) {
+    var tree trie.Tree[string, int]
+    for i, k := range []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus"} {
+        tree.Put(k, i)
+    }
+
+    prefixed := func(prefix string) []string {
+        var result []string
+        iter.Walk(func(e trie.Entry[string, int]) {
+            result = append(result, e.Key)
+        }, tree.WithPrefix(prefix))
+        return result
+    }
+
+    assert.Equal(t, []string{"romane", "romanus", "romulus"}, prefixed("ro"))
+    assert.Equal(t, []string{"romane", "romanus"}, prefixed("roma"))
+    assert.Equal(t, []string{"rubicon", "rubicundus"}, prefixed("rubic"))
+    assert.Equal(t, []string{"ruber"}, prefixed("ruber"))
+    assert.Nil(t, prefixed("rubx"))
+    assert.Nil(t, prefixed("x"))
+    assert.Equal(t, 7, len(prefixed("")))
+}
@@ -0,0 +1,149 @@
+// Package checksum implements a streaming 64-bit checksum, used by the
+// opaque binary serialisation formats elsewhere in this module (for example,
+// by [github.com/tawesoft/golib/v2/ds/bitseq.Store.Write]).
+//
+// The checksum is the CRC-64 with the ECMA polynomial, as implemented by
+// [hash/crc64]. This package adds helpers for checksumming 64-bit values
+// directly, and for combining two checksums, so that other serialisers can
+// produce compatible output.
+//
+// The checksum is suitable for detecting accidental corruption, but not
+// deliberate tampering.
+package checksum
+
+import (
+    "encoding/binary"
+    "hash"
+    "hash/crc64"
+)
+
+// Size is the size of a checksum in bytes.
+const Size = 8
+
+// ECMA is the (bit-reversed) ECMA polynomial used by the checksum.
+const ECMA = crc64.ECMA
+
+var table = crc64.MakeTable(ECMA)
+
+// Update returns the result of adding the 64-bit value, encoded in
+// little-endian byte order, to the checksum crc. Start with a crc of zero.
+func Update(crc uint64, value uint64) uint64 {
+    var buf [8]byte
+    binary.LittleEndian.PutUint64(buf[0:8], value)
+    return crc64.Update(crc, table, buf[0:8])
+}
+
+// UpdateBytes returns the result of adding the bytes in p to the checksum
+// crc. Start with a crc of zero.
+func UpdateBytes(crc uint64, p []byte) uint64 {
+    return crc64.Update(crc, table, p)
+}
+
+// Digest computes a checksum in a streaming fashion, and implements the
+// [hash.Hash64] interface.
+//
+// The zero value is ready to use.
+type Digest struct {
+    crc uint64
+    length int64
+}
+
+    // New returns a new [Digest] computing the checksum.
+    func New() *Digest {
+        return &Digest{}
+    }
+
+    // Write adds more data to the running checksum. It never returns an
+    // error.
+    func (d *Digest) Write(p []byte) (int, error) {
+        d.crc = UpdateBytes(d.crc, p)
+        d.length += int64(len(p))
+        return len(p), nil
+    }
+
+    // WriteUint64 adds a 64-bit value, encoded in little-endian byte order,
+    // to the running checksum. This is equivalent to [Update].
+    func (d *Digest) WriteUint64(value uint64) {
+        d.crc = Update(d.crc, value)
+        d.length += 8
+    }
+
+    // Sum64 returns the current checksum.
+    func (d *Digest) Sum64() uint64 { return d.crc }
+
+    // Len returns the number of bytes written to the checksum so far.
+    func (d *Digest) Len() int64 { return d.length }
+
+    // Sum appends the current checksum, in big-endian byte order, to b and
+    // returns the resulting slice. It does not change the underlying
+    // checksum state.
+    func (d *Digest) Sum(b []byte) []byte {
+        return binary.BigEndian.AppendUint64(b, d.crc)
+    }
+
+    // Reset resets the Digest to its initial state.
+    func (d *Digest) Reset() { d.crc, d.length = 0, 0 }
+
+    // Size returns the number of bytes Sum will return.
+    func (d *Digest) Size() int { return Size }
+
+    // BlockSize returns the hash's underlying block size.
+    func (d *Digest) BlockSize() int { return 1 }
+
+var _ hash.Hash64 = (*Digest)(nil)
+
+// Combine returns the checksum of the concatenation of two sequences of bytes,
+// A and B, given only the checksum of A, the checksum of B, and the length of
+// B in bytes.
+//
+// This allows checksums of separate chunks to be computed independently (for
+// example, in parallel) and then combined.
+func Combine(crcA uint64, crcB uint64, lengthB int64) uint64 {
+    // This is the zlib crc32_combine algorithm, adapted to 64 bits. It
+    // applies lengthB zero bytes to crcA by repeated squaring of an operator
+    // matrix over GF(2).
+    if lengthB <= 0 { return crcA }
+
+    var even, odd [64]uint64
+
+    // operator for one zero bit
+    odd[0] = ECMA
+    row := uint64(1)
+    for n := 1; n < 64; n++ {
+        odd[n] = row
+        row <<= 1
+    }
+
+    gf2Square(&even, &odd) // two zero bits
+    gf2Square(&odd, &even) // four zero bits
+
+    for {
+        // apply zeros operator for this bit of lengthB
+        gf2Square(&even, &odd)
+        if lengthB & 1 != 0 { crcA = gf2Times(&even, crcA) }
+        lengthB >>= 1
+        if lengthB == 0 { break }
+
+        gf2Square(&odd, &even)
+        if lengthB & 1 != 0 { crcA = gf2Times(&odd, crcA) }
+        lengthB >>= 1
+        if lengthB == 0 { break }
+    }
+
+    return crcA ^ crcB
+}
+
+func gf2Times(mat *[64]uint64, vec uint64) uint64 {
+    var sum uint64
+    for i := 0; vec != 0; i++ {
+        if vec & 1 != 0 { sum ^= mat[i] }
+        vec >>= 1
+    }
+    return sum
+}
+
+func gf2Square(square *[64]uint64, mat *[64]uint64) {
+    for n := 0; n < 64; n++ {
+        square[n] = gf2Times(mat, mat[n])
+    }
+}
@@ -0,0 +1,47 @@
+package checksum_test
+
+import (
+    "hash/crc64"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/hash/checksum"
+)
+
+func TestDigest(t *testing.T) {
+    data := []byte("The quick brown fox jumps over the lazy dog")
+    want := crc64.Checksum(data, crc64.MakeTable(crc64.ECMA))
+
+    d := checksum.New()
+    d.Write(data[0:10])
+    d.Write(data[10:])
+    if got := d.Sum64(); got != want {
+        t.Errorf("Digest.Sum64: got %x, want %x", got, want)
+    }
+    if d.Len() != int64(len(data)) {
+        t.Errorf("Digest.Len: got %d, want %d", d.Len(), len(data))
+    }
+
+    d.Reset()
+    d.WriteUint64(0x0102030405060708)
+    if got, want := d.Sum64(), checksum.Update(0, 0x0102030405060708); got != want {
+        t.Errorf("Digest.WriteUint64: got %x, want %x", got, want)
+    }
+    le := []byte{8, 7, 6, 5, 4, 3, 2, 1}
+    if got, want := d.Sum64(), checksum.UpdateBytes(0, le); got != want {
+        t.Errorf("Update is not little-endian: got %x, want %x", got, want)
+    }
+}
+
+func TestCombine(t *testing.T) {
+    data := []byte("The quick brown fox jumps over the lazy dog")
+
+    for i := 0; i <= len(data); i++ {
+        a, b := data[0:i], data[i:]
+        crcA := checksum.UpdateBytes(0, a)
+        crcB := checksum.UpdateBytes(0, b)
+        want := checksum.UpdateBytes(0, data)
+        if got := checksum.Combine(crcA, crcB, int64(len(b))); got != want {
+            t.Errorf("Combine at split %d: got %x, want %x", i, got, want)
+        }
+    }
+}
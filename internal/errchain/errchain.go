@@ -0,0 +1,40 @@
+// Package errchain formats the tree of errors wrapped by an error, for
+// messages that explain why an error did not match what was expected.
+//
+// This is shared by the [must] and [test] packages.
+//
+// [must]: https://pkg.go.dev/github.com/tawesoft/golib/v2/must
+// [test]: https://pkg.go.dev/github.com/tawesoft/golib/v2/test
+package errchain
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// String formats every error in the tree of err, in depth-first order, as a
+// list of quoted error messages. If types is true, each message is followed
+// by the type of the error.
+func String(err error, types bool) string {
+    var chain []string
+    var walk func(err error)
+    walk = func(err error) {
+        for err != nil {
+            s := strconv.Quote(err.Error())
+            if types { s = fmt.Sprintf("%s (%T)", s, err) }
+            chain = append(chain, s)
+
+            if x, ok := err.(interface{ Unwrap() []error }); ok {
+                for _, e := range x.Unwrap() {
+                    walk(e)
+                }
+                return
+            }
+            err = errors.Unwrap(err)
+        }
+    }
+    walk(err)
+    return "[" + strings.Join(chain, " -> ") + "]"
+}
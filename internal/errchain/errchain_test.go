@@ -0,0 +1,32 @@
+package errchain_test
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/internal/errchain"
+)
+
+func TestString(t *testing.T) {
+    a := errors.New("a")
+    b := fmt.Errorf("b: %w", a)
+    c := errors.Join(b, errors.New("d"))
+
+    tests := []struct {
+        err      error
+        types    bool
+        expected string
+    }{
+        {nil, false, `[]`},
+        {a, false, `["a"]`},
+        {b, false, `["b: a" -> "a"]`},
+        {c, false, `["b: a\nd" -> "b: a" -> "a" -> "d"]`},
+        {b, true,  `["b: a" (*fmt.wrapError) -> "a" (*errors.errorString)]`},
+    }
+    for _, tt := range tests {
+        if got := errchain.String(tt.err, tt.types); got != tt.expected {
+            t.Errorf("String(%v, %t): got %s, expected %s", tt.err, tt.types, got, tt.expected)
+        }
+    }
+}
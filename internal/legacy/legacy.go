@@ -41,6 +41,10 @@ func intish[T any](i int) T {
 // Caution: invalid key types will panic at runtime. The key type must be int
 // for any type other than a map. See [Rangeable] for details. In a channel,
 // the key is always zero.
+//
+// Deprecated: this uses reflection for every element, which is slow and not
+// type safe. Use ks.RangeSlice, ks.RangeMap, ks.RangeString, ks.RangeChan, or
+// ks.RangeIt instead.
 func Range[K comparable, V any, R Rangeable[K, V]](
     f func(K, V) error,
     r R,
@@ -0,0 +1,307 @@
+// Command gen.go generates text segmentation property information
+package main
+
+import (
+    "bufio"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/internal/legacy"
+    "github.com/tawesoft/golib/v2/must"
+)
+
+const maxRune = 0x10FFFF
+
+func ParseCodepoint(x string) rune {
+    return rune(must.Result(strconv.ParseInt(x, 16, 32)))
+}
+
+// parse reads a file in the format of the Unicode Character Database, such
+// as "0600..0605 ; Prepend # comment", and calls f for each range of code
+// points with the remaining fields.
+//
+// Lines of the form "# @missing: 0000..10FFFF; Other" are also passed to f.
+// These set the default value of a property, and appear before the
+// explicit values in a file.
+func parse(path string, f func(first, last rune, fields []string)) {
+    opener := func() (io.ReadCloser, error) { return os.Open(path) }
+
+    must.Check(legacy.WithCloser(opener, func(r io.ReadCloser) error {
+        scanner := bufio.NewScanner(r)
+        for scanner.Scan() {
+            line := scanner.Text()
+            if missing, ok := strings.CutPrefix(line, "# @missing:"); ok {
+                line = missing
+            }
+            line, _, _ = strings.Cut(line, "#")
+            if strings.TrimSpace(line) == "" { continue }
+
+            fields := strings.Split(line, ";")
+            for i := range fields {
+                fields[i] = strings.TrimSpace(fields[i])
+            }
+
+            first, last, found := strings.Cut(fields[0], "..")
+            if !found { last = first }
+            f(ParseCodepoint(first), ParseCodepoint(last), fields[1:])
+        }
+        return scanner.Err()
+    }))
+}
+
+// property reads an enumerated property from a file in the format of the
+// Unicode Character Database, and returns the index of its value in values
+// for every code point. Values not listed have the index zero.
+func property(path string, values []string) []uint8 {
+    result := make([]uint8, maxRune + 1)
+    index := make(map[string]uint8)
+    for i, v := range values {
+        index[v] = uint8(i)
+    }
+
+    parse(path, func(first, last rune, fields []string) {
+        v, ok := index[fields[0]]
+        if !ok { panic("unknown property value " + fields[0] + " in " + path) }
+        for r := first; r <= last; r++ {
+            result[r] = v
+        }
+    })
+    return result
+}
+
+// binaryProperty reads a binary property, such as Extended_Pictographic,
+// from a file in the format of the Unicode Character Database that may list
+// several properties.
+func binaryProperty(path string, name string) []bool {
+    result := make([]bool, maxRune + 1)
+    parse(path, func(first, last rune, fields []string) {
+        if fields[0] != name { return }
+        if (len(fields) > 1) && (fields[1] == "No") { return }
+        for r := first; r <= last; r++ {
+            result[r] = true
+        }
+    })
+    return result
+}
+
+// Values of the Grapheme_Cluster_Break property, in the order of the gcb
+// constants in text/segment.
+var gcbValues = []string{
+    "Other",
+    "CR",
+    "LF",
+    "Control",
+    "Extend",
+    "ZWJ",
+    "Regional_Indicator",
+    "Prepend",
+    "SpacingMark",
+    "L",
+    "V",
+    "T",
+    "LV",
+    "LVT",
+}
+
+// gcbExtPict is the value used in text/segment for code points with the
+// Extended_Pictographic property, all of which have
+// Grapheme_Cluster_Break=Other.
+const gcbExtPict = 14
+
+// Values of the Word_Break property, in the order of the wb constants in
+// text/segment.
+var wbValues = []string{
+    "Other",
+    "CR",
+    "LF",
+    "Newline",
+    "Extend",
+    "ZWJ",
+    "Regional_Indicator",
+    "Format",
+    "Katakana",
+    "Hebrew_Letter",
+    "ALetter",
+    "Single_Quote",
+    "Double_Quote",
+    "MidNumLet",
+    "MidLetter",
+    "MidNum",
+    "Numeric",
+    "ExtendNumLet",
+    "WSegSpace",
+}
+
+// Values of the Line_Break property, in the order of the lb constants in
+// text/segment, followed by the values that are resolved to another value
+// by rule LB1.
+var lbValues = []string{
+    "AL", "BK", "CR", "LF", "NL", "CM", "ZWJ", "WJ", "ZW", "GL", "SP", "B2",
+    "BA", "BB", "HY", "CB", "CL", "CP", "EX", "IN", "NS", "OP", "QU", "IS",
+    "NU", "PO", "PR", "SY", "EB", "EM", "H2", "H3", "HL", "ID", "JL", "JV",
+    "JT", "RI",
+
+    "AI", "CJ", "SA", "SG", "XX",
+}
+
+// Flags for Line_Break values in text/segment.
+const (
+    // lbEastAsian is set for code points with an East_Asian_Width of
+    // Fullwidth, Wide, or Halfwidth, for rule LB30.
+    lbEastAsian = 0x40
+
+    // lbPictographicUnassigned is set for unassigned code points with the
+    // Extended_Pictographic property, for rule LB30b.
+    lbPictographicUnassigned = 0x80
+)
+
+// lineBreak returns Line_Break values after the resolution of classes AI,
+// CJ, SA, SG and XX by rule LB1, with flags for East_Asian_Width and for
+// unassigned Extended_Pictographic code points.
+func lineBreak(pictographic []bool) []uint8 {
+    index := func(x string) uint8 {
+        for i, v := range lbValues {
+            if v == x { return uint8(i) }
+        }
+        panic("unknown Line_Break value " + x)
+    }
+
+    lb := property("../../DATA/LineBreak.15.0.0.txt", lbValues)
+    gc := property("../../DATA/DerivedGeneralCategory.15.0.0.txt", gcValues)
+    eaw := property("../../DATA/EastAsianWidth.15.0.0.txt", eawValues)
+
+    for r := range lb {
+        switch lbValues[lb[r]] {
+            case "AI", "SG", "XX":
+                lb[r] = index("AL")
+            case "SA":
+                if (gcValues[gc[r]] == "Mn") || (gcValues[gc[r]] == "Mc") {
+                    lb[r] = index("CM")
+                } else {
+                    lb[r] = index("AL")
+                }
+            case "CJ":
+                lb[r] = index("NS")
+        }
+        must.True(lb[r] < lbEastAsian)
+
+        switch eawValues[eaw[r]] {
+            case "F", "W", "H":
+                lb[r] |= lbEastAsian
+        }
+        if pictographic[r] && (gcValues[gc[r]] == "Cn") {
+            lb[r] |= lbPictographicUnassigned
+        }
+    }
+    return lb
+}
+
+// Values of the General_Category property, with Cn (unassigned) first as the
+// default.
+var gcValues = []string{
+    "Cn",
+    "Lu", "Ll", "Lt", "Lm", "Lo",
+    "Mn", "Mc", "Me",
+    "Nd", "Nl", "No",
+    "Pc", "Pd", "Ps", "Pe", "Pi", "Pf", "Po",
+    "Sm", "Sc", "Sk", "So",
+    "Zs", "Zl", "Zp",
+    "Cc", "Cf", "Cs", "Co",
+}
+
+// Values of the East_Asian_Width property, with N (neutral) first as the
+// default.
+var eawValues = []string{"N", "A", "F", "H", "Na", "W"}
+
+func main() {
+    pictographic := binaryProperty("../../DATA/emoji-data.15.0.0.txt", "Extended_Pictographic")
+
+    {
+        gcb := property("../../DATA/GraphemeBreakProperty.15.0.0.txt", gcbValues)
+        for r := range gcb {
+            if !pictographic[r] { continue }
+            must.Equal(gcb[r], 0)
+            gcb[r] = gcbExtPict
+        }
+
+        dest := must.Result(os.Create("../../../../text/segment/gcb.bin"))
+        defer dest.Close()
+        encode(dest, gcb)
+    }
+
+    {
+        wb := property("../../DATA/WordBreakProperty.15.0.0.txt", wbValues)
+        dest := must.Result(os.Create("../../../../text/segment/wb.bin"))
+        defer dest.Close()
+        encode(dest, wb)
+    }
+
+    {
+        lb := lineBreak(pictographic)
+        dest := must.Result(os.Create("../../../../text/segment/lb.bin"))
+        defer dest.Close()
+        encode(dest, lb)
+    }
+}
+
+// rng is a range of code points sharing the same value
+type rng struct {
+    value uint8
+    start rune
+    end rune
+}
+
+// Pack encodes a range as a character's codepoint (21 bits) (the start),
+// length of the range minus one (11 bits), and value (8 bits), for a total of
+// 40 bits, which fits in 5 bytes
+func (r rng) Pack() uint64 {
+    cp := r.start
+    rl := int(r.end) - int(r.start)
+
+    must.True(rl <=    0x7FF)
+    must.True(cp <= 0x1FFFFF)
+
+    var x uint64
+    x |= (uint64(cp)      & 0x1FFFFF)
+    x |= (uint64(rl)      &    0x7FF) << 21
+    x |= (uint64(r.value) &     0xFF) << (21 + 11)
+    return x
+}
+
+// ranges returns the ordered ranges of code points with a non-zero value,
+// split so that each range fits in a packed encoding.
+func ranges(values []uint8) []rng {
+    result := make([]rng, 0)
+    for r := rune(0); r <= maxRune; {
+        v := values[r]
+        end := r
+        for (end < maxRune) && (values[end + 1] == v) && (end - r < 0x7FF) {
+            end++
+        }
+        if v != 0 {
+            result = append(result, rng{value: v, start: r, end: end})
+        }
+        r = end + 1
+    }
+    return result
+}
+
+func encode(dest io.Writer, values []uint8) {
+    for _, r := range ranges(values) {
+        var x = r.Pack()
+        b0 :=       (x) & 0xFF
+        b1 := (x >>  8) & 0xFF
+        b2 := (x >> 16) & 0xFF
+        b3 := (x >> 24) & 0xFF
+        b4 := (x >> 32) & 0xFF
+        must.Result(dest.Write([]byte{
+            uint8(b0),
+            uint8(b1),
+            uint8(b2),
+            uint8(b3),
+            uint8(b4),
+        }))
+    }
+}
@@ -1,6 +1,7 @@
 
 UNICODE_VERSION="13.0.0"
 CLDR_VERSION="41.0"
+SEGMENT_UNICODE_VERSION="15.0.0"
 
 cd "gen-$UNICODE_VERSION/ccc"
 go run .
@@ -18,6 +19,10 @@ cd "gen-$UNICODE_VERSION/np"
 go run .
 cd "../../"
 
+cd "gen-$SEGMENT_UNICODE_VERSION/segment"
+go run .
+cd "../../"
+
 cd "gen-cldr-$CLDR_VERSION/fallback"
 go run .
 cd "../../"
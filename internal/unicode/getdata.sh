@@ -13,3 +13,23 @@ unzip -o "DATA/cldr-common-$CLDR_VERSION.$CLDR_VERSION_MINOR.zip" -d "DATA/cldr-
 
 wget -O "DATA/NormalizationTest.$UNICODE_VERSION.txt" -nc "https://www.unicode.org/Public/$UNICODE_VERSION/ucd/NormalizationTest.txt"
 cp "DATA/NormalizationTest.$UNICODE_VERSION.txt" ../../text/dm/testdata
+
+wget -O "DATA/confusables.$UNICODE_VERSION.txt" -nc "https://www.unicode.org/Public/security/$UNICODE_VERSION/confusables.txt"
+cp "DATA/confusables.$UNICODE_VERSION.txt" ../../text/fold/confusables.txt
+
+for locale in en es; do
+    cp "DATA/cldr-$CLDR_VERSION.$CLDR_VERSION_MINOR/common/rbnf/$locale.xml" ../../text/number/spellout/data
+done
+
+# text/segment uses the same version of Unicode as the Go standard library
+SEGMENT_UNICODE_VERSION="15.0.0"
+
+for file in auxiliary/GraphemeBreakProperty auxiliary/WordBreakProperty LineBreak \
+    EastAsianWidth extracted/DerivedGeneralCategory emoji/emoji-data; do
+    wget -O "DATA/$(basename $file).$SEGMENT_UNICODE_VERSION.txt" -nc "https://www.unicode.org/Public/$SEGMENT_UNICODE_VERSION/ucd/$file.txt"
+done
+
+for file in GraphemeBreakTest WordBreakTest LineBreakTest; do
+    wget -O "DATA/$file.$SEGMENT_UNICODE_VERSION.txt" -nc "https://www.unicode.org/Public/$SEGMENT_UNICODE_VERSION/ucd/auxiliary/$file.txt"
+    cp "DATA/$file.$SEGMENT_UNICODE_VERSION.txt" "../../text/segment/testdata/$file.txt"
+done
@@ -0,0 +1,199 @@
+// Package words implements bulk operations on sequences of bits packed into
+// slices of uint64 words, where bit i is bit (i % 64) of word (i / 64).
+//
+// This is shared by the bit-packed types in [bitseq] and [matrix], so that
+// operations on many bits at once work on whole words, rather than visiting
+// each bit individually. Binary operations are written to avoid bounds
+// checks in their inner loops, and process four words at a time.
+//
+// [bitseq]: https://pkg.go.dev/github.com/tawesoft/golib/v2/ds/bitseq
+// [matrix]: https://pkg.go.dev/github.com/tawesoft/golib/v2/ds/matrix
+package words
+
+import (
+    "math/bits"
+)
+
+// Words returns the number of words needed to store n bits.
+func Words(n int) int {
+    return (n + 63) / 64
+}
+
+// And sets dst[i] &= src[i], for each i less than min(len(dst), len(src)).
+func And(dst, src []uint64) {
+    n := min(len(dst), len(src))
+    dst, src = dst[:n], src[:n]
+    i := 0
+    for ; i + 4 <= n; i += 4 {
+        d, s := dst[i:i+4:i+4], src[i:i+4:i+4]
+        d[0] &= s[0]
+        d[1] &= s[1]
+        d[2] &= s[2]
+        d[3] &= s[3]
+    }
+    for ; i < n; i++ {
+        dst[i] &= src[i]
+    }
+}
+
+// Or sets dst[i] |= src[i], for each i less than min(len(dst), len(src)).
+func Or(dst, src []uint64) {
+    n := min(len(dst), len(src))
+    dst, src = dst[:n], src[:n]
+    i := 0
+    for ; i + 4 <= n; i += 4 {
+        d, s := dst[i:i+4:i+4], src[i:i+4:i+4]
+        d[0] |= s[0]
+        d[1] |= s[1]
+        d[2] |= s[2]
+        d[3] |= s[3]
+    }
+    for ; i < n; i++ {
+        dst[i] |= src[i]
+    }
+}
+
+// Xor sets dst[i] ^= src[i], for each i less than min(len(dst), len(src)).
+func Xor(dst, src []uint64) {
+    n := min(len(dst), len(src))
+    dst, src = dst[:n], src[:n]
+    i := 0
+    for ; i + 4 <= n; i += 4 {
+        d, s := dst[i:i+4:i+4], src[i:i+4:i+4]
+        d[0] ^= s[0]
+        d[1] ^= s[1]
+        d[2] ^= s[2]
+        d[3] ^= s[3]
+    }
+    for ; i < n; i++ {
+        dst[i] ^= src[i]
+    }
+}
+
+// AndNot sets dst[i] &^= src[i], for each i less than
+// min(len(dst), len(src)).
+func AndNot(dst, src []uint64) {
+    n := min(len(dst), len(src))
+    dst, src = dst[:n], src[:n]
+    i := 0
+    for ; i + 4 <= n; i += 4 {
+        d, s := dst[i:i+4:i+4], src[i:i+4:i+4]
+        d[0] &^= s[0]
+        d[1] &^= s[1]
+        d[2] &^= s[2]
+        d[3] &^= s[3]
+    }
+    for ; i < n; i++ {
+        dst[i] &^= src[i]
+    }
+}
+
+// Count returns the number of set bits.
+func Count(ws []uint64) int {
+    var a, b, c, d int
+    i := 0
+    for ; i + 4 <= len(ws); i += 4 {
+        w := ws[i:i+4:i+4]
+        a += bits.OnesCount64(w[0])
+        b += bits.OnesCount64(w[1])
+        c += bits.OnesCount64(w[2])
+        d += bits.OnesCount64(w[3])
+    }
+    for ; i < len(ws); i++ {
+        a += bits.OnesCount64(ws[i])
+    }
+    return a + b + c + d
+}
+
+// Equal returns true if a and b contain the same set bits, where any words
+// past the end of the shorter slice are treated as zero.
+func Equal(a, b []uint64) bool {
+    if len(a) < len(b) { a, b = b, a }
+    for i := range b {
+        if a[i] != b[i] { return false }
+    }
+    return IsZero(a[len(b):])
+}
+
+// IsZero returns true if no bits are set.
+func IsZero(ws []uint64) bool {
+    for _, w := range ws {
+        if w != 0 { return false }
+    }
+    return true
+}
+
+// Next returns the index of the first set bit after the given bit index, or
+// false if there is none. To start at the beginning, use an index of -1.
+func Next(ws []uint64, after int) (int, bool) {
+    start := max(after + 1, 0)
+    word, offset := start / 64, start % 64
+    if word >= len(ws) { return -1, false }
+
+    // ignore bits before the start in the first word
+    if w := ws[word] & (^uint64(0) << offset); w != 0 {
+        return (word * 64) + bits.TrailingZeros64(w), true
+    }
+    for i := word + 1; i < len(ws); i++ {
+        if ws[i] != 0 { return (i * 64) + bits.TrailingZeros64(ws[i]), true }
+    }
+    return -1, false
+}
+
+// Prev returns the index of the last set bit before the given bit index, or
+// false if there is none. To start at the end, use an index greater than or
+// equal to len(ws) * 64.
+func Prev(ws []uint64, before int) (int, bool) {
+    start := min(before - 1, (len(ws) * 64) - 1)
+    if start < 0 { return -1, false }
+    word, offset := start / 64, start % 64
+
+    // ignore bits after the start in the first word
+    if w := ws[word] & (^uint64(0) >> (63 - offset)); w != 0 {
+        return (word * 64) + 63 - bits.LeadingZeros64(w), true
+    }
+    for i := word - 1; i >= 0; i-- {
+        if ws[i] != 0 { return (i * 64) + 63 - bits.LeadingZeros64(ws[i]), true }
+    }
+    return -1, false
+}
+
+// Mask clears every bit at index n or greater.
+func Mask(ws []uint64, n int) {
+    word, offset := n / 64, n % 64
+    if word >= len(ws) { return }
+    ws[word] &= ^(^uint64(0) << offset)
+    clear(ws[word + 1:])
+}
+
+// Extract sets dst to the n bits of src starting at bit index start, where
+// dst has a length of at least Words(n). Any bits in dst past n are cleared.
+// Bits past the end of src are treated as zero.
+func Extract(dst, src []uint64, start, n int) {
+    for i := 0; i < Words(n); i++ {
+        word, offset := (start / 64) + i, start % 64
+        var w uint64
+        if word < len(src) { w = src[word] >> offset }
+        if (offset > 0) && (word + 1 < len(src)) { w |= src[word + 1] << (64 - offset) }
+        dst[i] = w
+    }
+    Mask(dst, n)
+}
+
+// Insert copies the first n bits of src into dst, starting at bit index
+// start, leaving every other bit of dst unchanged. The bits must fit within
+// dst.
+func Insert(dst, src []uint64, start, n int) {
+    for i := 0; i < Words(n); i++ {
+        bits := min(64, n - (i * 64))
+        mask := ^uint64(0) >> (64 - bits)
+        value := src[i] & mask
+
+        pos := start + (i * 64)
+        word, offset := pos / 64, pos % 64
+        dst[word] = (dst[word] &^ (mask << offset)) | (value << offset)
+        if offset + bits > 64 {
+            dst[word + 1] = (dst[word + 1] &^ (mask >> (64 - offset))) | (value >> (64 - offset))
+        }
+    }
+}
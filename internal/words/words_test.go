@@ -0,0 +1,164 @@
+package words_test
+
+import (
+    "math/rand"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/internal/words"
+)
+
+// get returns bit i of ws, as a model for comparison.
+func get(ws []uint64, i int) bool {
+    if i / 64 >= len(ws) { return false }
+    return (ws[i / 64] & (1 << (i % 64))) != 0
+}
+
+func random(r *rand.Rand, n int) []uint64 {
+    ws := make([]uint64, n)
+    for i := range ws {
+        // sparse words exercise Next and Prev across empty words
+        if r.Intn(3) == 0 { ws[i] = r.Uint64() & r.Uint64() & r.Uint64() }
+    }
+    return ws
+}
+
+func TestBinary(t *testing.T) {
+    ops := []struct{
+        name string
+        f    func(dst, src []uint64)
+        op   func(a, b bool) bool
+    }{
+        {"and",    words.And,    func(a, b bool) bool { return a && b }},
+        {"or",     words.Or,     func(a, b bool) bool { return a || b }},
+        {"xor",    words.Xor,    func(a, b bool) bool { return a != b }},
+        {"andnot", words.AndNot, func(a, b bool) bool { return a && !b }},
+    }
+
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 100; i++ {
+        a, b := random(r, r.Intn(11)), random(r, r.Intn(11))
+        for _, op := range ops {
+            dst := append([]uint64{}, a...)
+            op.f(dst, b)
+            n := min(len(a), len(b))
+            for j := 0; j < len(a) * 64; j++ {
+                expected := get(a, j)
+                if j < n * 64 { expected = op.op(get(a, j), get(b, j)) }
+                if get(dst, j) != expected {
+                    t.Fatalf("%s(%x, %x): wrong bit %d", op.name, a, b, j)
+                }
+            }
+        }
+    }
+}
+
+func TestCount_Equal(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 100; i++ {
+        a := random(r, r.Intn(11))
+        count := 0
+        for j := 0; j < len(a) * 64; j++ {
+            if get(a, j) { count++ }
+        }
+        if words.Count(a) != count {
+            t.Errorf("Count(%x): got %d, expected %d", a, words.Count(a), count)
+        }
+        if words.IsZero(a) != (count == 0) {
+            t.Errorf("IsZero(%x): got %t", a, words.IsZero(a))
+        }
+
+        b := append(append([]uint64{}, a...), 0, 0)
+        if !words.Equal(a, b) || !words.Equal(b, a) {
+            t.Errorf("Equal(%x, %x): expected true", a, b)
+        }
+        b[len(b) - 1] = 1
+        if words.Equal(a, b) || words.Equal(b, a) {
+            t.Errorf("Equal(%x, %x): expected false", a, b)
+        }
+    }
+}
+
+func TestNext_Prev(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 100; i++ {
+        a := random(r, r.Intn(5))
+        var set []int
+        for j := 0; j < len(a) * 64; j++ {
+            if get(a, j) { set = append(set, j) }
+        }
+
+        var got []int
+        for j, ok := words.Next(a, -1); ok; j, ok = words.Next(a, j) {
+            got = append(got, j)
+        }
+        if len(got) != len(set) {
+            t.Fatalf("Next(%x): got %v, expected %v", a, got, set)
+        }
+
+        got = got[:0]
+        for j, ok := words.Prev(a, len(a) * 64); ok; j, ok = words.Prev(a, j) {
+            got = append(got, j)
+        }
+        for k := range set {
+            if got[len(got) - 1 - k] != set[k] {
+                t.Fatalf("Prev(%x): got %v, expected reverse of %v", a, got, set)
+            }
+        }
+    }
+}
+
+func TestMask(t *testing.T) {
+    for _, n := range []int{0, 1, 63, 64, 65, 130, 200} {
+        ws := []uint64{^uint64(0), ^uint64(0), ^uint64(0)}
+        words.Mask(ws, n)
+        if words.Count(ws) != min(n, 192) {
+            t.Errorf("Mask(%d): got %d bits set", n, words.Count(ws))
+        }
+    }
+}
+
+func BenchmarkOr(b *testing.B) {
+    r := rand.New(rand.NewSource(0))
+    x, y := random(r, 1024), random(r, 1024)
+    b.SetBytes(8 * 1024)
+    for i := 0; i < b.N; i++ {
+        words.Or(x, y)
+    }
+}
+
+func BenchmarkCount(b *testing.B) {
+    r := rand.New(rand.NewSource(0))
+    x := random(r, 1024)
+    b.SetBytes(8 * 1024)
+    for i := 0; i < b.N; i++ {
+        words.Count(x)
+    }
+}
+
+func TestExtract_Insert(t *testing.T) {
+    r := rand.New(rand.NewSource(0))
+    for i := 0; i < 200; i++ {
+        src := random(r, 1 + r.Intn(5))
+        start := r.Intn(len(src) * 64)
+        n := 1 + r.Intn((len(src) * 64) - start)
+
+        part := make([]uint64, words.Words(n))
+        words.Extract(part, src, start, n)
+        for j := 0; j < len(part) * 64; j++ {
+            if get(part, j) != ((j < n) && get(src, start + j)) {
+                t.Fatalf("Extract(%x, %d, %d): wrong bit %d", src, start, n, j)
+            }
+        }
+
+        dst := random(r, len(src))
+        original := append([]uint64{}, dst...)
+        words.Insert(dst, part, start, n)
+        for j := 0; j < len(dst) * 64; j++ {
+            expected := get(original, j)
+            if (j >= start) && (j < start + n) { expected = get(src, j) }
+            if get(dst, j) != expected {
+                t.Fatalf("Insert(%x, %d, %d): wrong bit %d", part, start, n, j)
+            }
+        }
+    }
+}
@@ -360,6 +360,16 @@ type FinalValue[X any] struct {
     IsFinal bool
 }
 
+// FromChan returns an iterator that produces each value received from
+// channel c, until the channel is closed. Each call to the iterator blocks
+// until a value is received or the channel is closed.
+func FromChan[X any](c <-chan X) It[X] {
+    return func() (X, bool) {
+        x, ok := <-c
+        return x, ok
+    }
+}
+
 // FromMap returns an iterator that produces each (key, value) pair from the
 // input [builtin.Map] (of Go type map[X]Y, not to be confused with the higher
 // order function [Map]) as an Pair. Do not modify the underlying map's keys
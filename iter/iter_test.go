@@ -256,6 +256,19 @@ func TestFinal(t *testing.T) {
     }
 }
 
+func TestFromChan(t *testing.T) {
+    c := make(chan int, 3)
+    c <- 1; c <- 2; c <- 3
+    close(c)
+    it := lazy.FromChan(c)
+
+    x, ok := it(); assert.Equal(t, 1, x); assert.Equal(t,  true, ok)
+    x, ok  = it(); assert.Equal(t, 2, x); assert.Equal(t,  true, ok)
+    x, ok  = it(); assert.Equal(t, 3, x); assert.Equal(t,  true, ok)
+    x, ok  = it(); assert.Equal(t, 0, x); assert.Equal(t, false, ok)
+    x, ok  = it(); assert.Equal(t, 0, x); assert.Equal(t, false, ok)
+}
+
 func TestFromMap(t *testing.T) {
     original := map[string]string{
         "cat": "meow",
@@ -0,0 +1,167 @@
+package ks
+
+import (
+    "errors"
+    "io"
+    "sync"
+)
+
+// Deferred collects cleanup functions, such as the Close methods of
+// several resources, so that they can all be run together from a single
+// defer statement, and any errors they return can be gathered together.
+//
+// Cleanup functions form a stack: they are run in the reverse order that they
+// were added. See also [Deferred.OnError] for cleaning up partially
+// initialised values in constructors.
+//
+// The zero value is an empty collector ready to use. A Deferred is safe for
+// concurrent use. Deferred itself implements [io.Closer].
+//
+// For example:
+//
+//     func work() (err error) {
+//         var cleanup ks.Deferred
+//         defer cleanup.Into(&err)
+//
+//         a, err := os.Open("a.txt")
+//         if err != nil { return err }
+//         cleanup.Closer(a)
+//
+//         b, err := os.Open("b.txt")
+//         if err != nil { return err }
+//         cleanup.Closer(b)
+//
+//         ...
+//     }
+type Deferred struct {
+    mu sync.Mutex
+    fns []func() error
+}
+
+    // Add adds a cleanup function.
+    func (d *Deferred) Add(f func() error) {
+        d.mu.Lock()
+        defer d.mu.Unlock()
+        d.fns = append(d.fns, f)
+    }
+
+    // AddFunc adds a cleanup function that does not return an error.
+    func (d *Deferred) AddFunc(f func()) {
+        d.Add(func() error { f(); return nil })
+    }
+
+    // Closer adds the Close method of c as a cleanup function.
+    func (d *Deferred) Closer(c io.Closer) {
+        d.Add(c.Close)
+    }
+
+    // Close runs every cleanup function, in the reverse order that they
+    // were added (i.e. in the same order as separate defer statements would
+    // have run them), and removes them from the collector.
+    //
+    // Every cleanup function is run, even if an earlier one returns an
+    // error. The returned error is the result of joining every non-nil error
+    // with [errors.Join], or nil if there were none.
+    //
+    // If a cleanup function panics, the remaining cleanup functions are still
+    // run before the first panic is raised again.
+    func (d *Deferred) Close() error {
+        d.mu.Lock()
+        fns := d.fns
+        d.fns = nil
+        d.mu.Unlock()
+
+        return runDeferred(fns)
+    }
+
+    // Into calls [Deferred.Close], and joins any resulting error with the
+    // error pointed to by err, using [errors.Join]. This is intended to be
+    // used with a named error result in a defer statement, so that cleanup
+    // errors are not lost. See the example for [Deferred].
+    func (d *Deferred) Into(err *error) {
+        if cerr := d.Close(); cerr != nil {
+            *err = errors.Join(*err, cerr)
+        }
+    }
+
+    // OnError is like [Deferred.Into], except that the cleanup functions are
+    // only run if the error pointed to by err is not nil. Otherwise, the
+    // cleanup functions are kept.
+    //
+    // This is intended for constructors that acquire several resources in
+    // turn, where a failure part-way through must release the resources
+    // already acquired, but success means that the resources are kept. For
+    // example:
+    //
+    //     func NewThing() (_ *Thing, err error) {
+    //         var cleanup ks.Deferred
+    //         defer cleanup.OnError(&err)
+    //
+    //         a, err := os.Open("a.txt")
+    //         if err != nil { return nil, err }
+    //         cleanup.Closer(a)
+    //
+    //         b, err := os.Open("b.txt")
+    //         if err != nil { return nil, err } // closes a
+    //         cleanup.Closer(b)
+    //
+    //         return &Thing{a: a, b: b, closer: cleanup.Release()}, nil
+    //     }
+    func (d *Deferred) OnError(err *error) {
+        if *err == nil { return }
+        d.Into(err)
+    }
+
+    // Release removes every cleanup function from d without running them,
+    // and returns a new Deferred that holds them instead. This transfers
+    // responsibility for cleanup, for example from a constructor to the
+    // Close method of the constructed value. See [Deferred.OnError].
+    func (d *Deferred) Release() *Deferred {
+        d.mu.Lock()
+        defer d.mu.Unlock()
+        fns := d.fns
+        d.fns = nil
+        return &Deferred{fns: fns}
+    }
+
+// Finally calls f, and joins any resulting error with the error pointed to by
+// err, using [errors.Join]. This is intended to be used with a named error
+// result in a defer statement, so that an error from a single cleanup step
+// is not lost. For example:
+//
+//     func work() (err error) {
+//         f, err := os.Create("out.txt")
+//         if err != nil { return err }
+//         defer ks.Finally(&err, f.Close)
+//         ...
+//     }
+//
+// See [Deferred] for multiple cleanup steps.
+func Finally(err *error, f func() error) {
+    if ferr := f(); ferr != nil {
+        *err = errors.Join(*err, ferr)
+    }
+}
+
+// runDeferred runs each function in fns in reverse order, and joins any
+// errors. If any function panics, the remaining functions still run before
+// the first panic is raised again.
+func runDeferred(fns []func() error) error {
+    var errs []error
+    var panicked bool
+    var recovered any
+
+    for i := len(fns) - 1; i >= 0; i-- {
+        func() {
+            defer func() {
+                if r := recover(); (r != nil) && !panicked {
+                    panicked, recovered = true, r
+                }
+            }()
+            errs = append(errs, fns[i]())
+        }()
+    }
+
+    if panicked { panic(recovered) }
+    return errors.Join(errs...)
+}
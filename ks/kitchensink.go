@@ -3,24 +3,22 @@
 package ks
 
 import (
-    "encoding/binary"
     "errors"
-    "hash/crc64"
-    "slices"
     "strings"
     "unicode/utf8"
 
+    "github.com/tawesoft/golib/v2/hash/checksum"
     "golang.org/x/exp/utf8string"
 )
 
 var ErrTODO = errors.New("TODO")
 
-var crc64table = crc64.MakeTable(crc64.ECMA)
-
+// Checksum64 returns the result of adding the 64-bit value to the checksum
+// crc.
+//
+// Deprecated: use [checksum.Update].
 func Checksum64(crc uint64, value uint64) uint64 {
-    var buf [8]byte
-    binary.LittleEndian.PutUint64(buf[0:8], value)
-    return crc64.Update(crc, crc64table, buf[0:8])
+    return checksum.Update(crc, value)
 }
 
 // LiftErrorFunc takes any 1-arity function "f(x) => error", and
@@ -60,26 +58,6 @@ func MustMap[K comparable, V any](m map[K]V) map[K]V {
     return make(map[K]V)
 }
 
-// Reserve grows a slice, if necessary, to fit at least size extra elements.
-//
-// Deprecated: use [slices.Grow].
-func Reserve[T any](xs []T, size int) []T {
-    return slices.Grow(xs, size)
-}
-
-// SetLength grows a slice, if necessary, so that has a capacity of at least
-// size elements, and a length of exactly size elements. Any trailing elements
-// in the underlying array that fall beyond the original capacity are zeroed.
-func SetLength[T any](xs []T, size int) []T {
-    precap := cap(xs)
-    grow := size - cap(xs)
-    if grow <= 0 { return xs }
-    xs = slices.Grow(xs, grow)
-    xs = xs[0:size]
-    clear(xs[precap:cap(xs)])
-    return xs
-}
-
 // WrapBlock word-wraps a whitespace-delimited string to a given number of
 // columns. The column length is given in runes (Unicode code points), not
 // bytes.
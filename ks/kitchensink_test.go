@@ -1,8 +1,16 @@
 package ks_test
 
 import (
+    "context"
+    "errors"
+    "math"
+    "slices"
+    "sync"
+    "sync/atomic"
     "testing"
+    "time"
 
+    "github.com/tawesoft/golib/v2/iter"
     "github.com/tawesoft/golib/v2/ks"
 )
 
@@ -80,3 +88,418 @@ amet luctus mi leo ut dui.`,
         }
     }
 }
+
+func TestRetry(t *testing.T) {
+    errTemporary := errors.New("temporary")
+    errPermanent := errors.New("permanent")
+
+    policy := ks.RetryPolicy{
+        MaxAttempts:  5,
+        InitialDelay: time.Microsecond,
+        Jitter:       0.5,
+    }
+
+    {
+        // succeeds on the third attempt
+        attempts := 0
+        value, err := ks.RetryValue(context.Background(), policy, func() (int, error) {
+            attempts++
+            if attempts < 3 { return 0, errTemporary }
+            return 42, nil
+        })
+        if (err != nil) || (value != 42) || (attempts != 3) {
+            t.Errorf("RetryValue: got %d, %v after %d attempts", value, err, attempts)
+        }
+    }
+
+    {
+        // gives up after MaxAttempts
+        attempts := 0
+        err := ks.Retry(context.Background(), policy, func() error {
+            attempts++
+            return errTemporary
+        })
+        if !errors.Is(err, errTemporary) || (attempts != 5) {
+            t.Errorf("Retry: got %v after %d attempts", err, attempts)
+        }
+    }
+
+    {
+        // stops at a permanent error
+        attempts := 0
+        p := policy
+        p.Retryable = func(err error) bool { return !errors.Is(err, errPermanent) }
+        err := ks.Retry(context.Background(), p, func() error {
+            attempts++
+            if attempts == 2 { return errPermanent }
+            return errTemporary
+        })
+        if !errors.Is(err, errPermanent) || (attempts != 2) {
+            t.Errorf("Retry: got %v after %d attempts", err, attempts)
+        }
+    }
+
+    {
+        // stops when the context is cancelled
+        ctx, cancel := context.WithCancel(context.Background())
+        attempts := 0
+        p := ks.RetryPolicy{InitialDelay: time.Hour}
+        go func() { time.Sleep(time.Millisecond); cancel() }()
+        err := ks.Retry(ctx, p, func() error {
+            attempts++
+            return errTemporary
+        })
+        if !errors.Is(err, context.Canceled) || !errors.Is(err, errTemporary) || (attempts != 1) {
+            t.Errorf("Retry: got %v after %d attempts", err, attempts)
+        }
+    }
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+    p := ks.RetryPolicy{
+        InitialDelay: 10 * time.Millisecond,
+        MaxDelay:     50 * time.Millisecond,
+    }
+    expected := []time.Duration{0, 10, 20, 40, 50, 50}
+    for i, want := range expected {
+        if got := p.Delay(i); got != want * time.Millisecond {
+            t.Errorf("Delay(%d): got %v, want %v", i, got, want * time.Millisecond)
+        }
+    }
+
+    // without a MaxDelay, the delay saturates instead of overflowing
+    p = ks.RetryPolicy{InitialDelay: time.Second}
+    for _, attempt := range []int{63, 64, 100, 1000, math.MaxInt} {
+        if got := p.Delay(attempt); got != time.Duration(math.MaxInt64) {
+            t.Errorf("Delay(%d): got %v, want %v", attempt, got, time.Duration(math.MaxInt64))
+        }
+    }
+}
+
+func TestWithTimeout(t *testing.T) {
+    {
+        value, err := ks.WithTimeoutValue(time.Second, func(ctx context.Context) (int, error) {
+            return 42, nil
+        })
+        if (err != nil) || (value != 42) {
+            t.Errorf("WithTimeoutValue: got %d, %v", value, err)
+        }
+    }
+
+    {
+        // overrun, even though f ignores its context
+        block := make(chan struct{})
+        defer close(block)
+        err := ks.WithTimeout(time.Millisecond, func(ctx context.Context) error {
+            <-block
+            return nil
+        })
+        if !errors.Is(err, context.DeadlineExceeded) {
+            t.Errorf("WithTimeout: got %v, want %v", err, context.DeadlineExceeded)
+        }
+    }
+
+    {
+        // cancelled
+        ctx, cancel := context.WithCancel(context.Background())
+        cancel()
+        err := ks.WithContext(ctx, func(ctx context.Context) error {
+            t.Errorf("WithContext: f should not be called with a done context")
+            return nil
+        })
+        if !errors.Is(err, context.Canceled) {
+            t.Errorf("WithContext: got %v, want %v", err, context.Canceled)
+        }
+    }
+
+    {
+        // panics propagate
+        defer func() {
+            if r := recover(); r != "oops" {
+                t.Errorf("WithContext: got panic %v, want oops", r)
+            }
+        }()
+        _ = ks.WithContext(context.Background(), func(ctx context.Context) error {
+            panic("oops")
+        })
+    }
+}
+
+func TestSlices(t *testing.T) {
+    type row struct {
+        name string
+        f func([]int) []int
+        input []int // length, with any extra capacity filled with 9s
+        inputCap int
+        expected []int
+        expectedMinCap int
+    }
+
+    tests := []row{
+        {"EnsureCap/noop",   func(xs []int) []int { return ks.EnsureCap(xs, 2) },  []int{1, 2}, 4, []int{1, 2}, 4},
+        {"EnsureCap/grow",   func(xs []int) []int { return ks.EnsureCap(xs, 8) },  []int{1, 2}, 4, []int{1, 2}, 8},
+        {"SetLength/within", func(xs []int) []int { return ks.SetLength(xs, 3) },  []int{1, 2}, 4, []int{1, 2, 9}, 4},
+        {"SetLength/beyond", func(xs []int) []int { return ks.SetLength(xs, 6) },  []int{1, 2}, 4, []int{1, 2, 9, 9, 0, 0}, 6},
+        {"SetLength/shrink", func(xs []int) []int { return ks.SetLength(xs, 1) },  []int{1, 2}, 4, []int{1}, 4},
+        {"GrowLen/within",   func(xs []int) []int { return ks.GrowLen(xs, 2) },    []int{1, 2}, 4, []int{1, 2, 0, 0}, 4},
+        {"GrowLen/beyond",   func(xs []int) []int { return ks.GrowLen(xs, 3) },    []int{1, 2}, 4, []int{1, 2, 0, 0, 0}, 5},
+        {"GrowLen/noop",     func(xs []int) []int { return ks.GrowLen(xs, 0) },    []int{1, 2}, 4, []int{1, 2}, 4},
+        {"Shrink",           func(xs []int) []int { return ks.Shrink(xs, 1) },     []int{1, 2, 3}, 4, []int{1}, 4},
+        {"Shrink/noop",      func(xs []int) []int { return ks.Shrink(xs, 5) },     []int{1, 2, 3}, 4, []int{1, 2, 3}, 4},
+        {"Reuse/within",     func(xs []int) []int { return ks.Reuse(xs, 3) },      []int{1, 2}, 4, []int{0, 0, 0}, 4},
+        {"Reuse/beyond",     func(xs []int) []int { return ks.Reuse(xs, 5) },      []int{1, 2}, 4, []int{0, 0, 0, 0, 0}, 5},
+    }
+
+    for _, tt := range tests {
+        backing := make([]int, tt.inputCap)
+        for i := range backing { backing[i] = 9 }
+        xs := backing[0:copy(backing, tt.input)]
+
+        result := tt.f(xs)
+        if !slices.Equal(result, tt.expected) {
+            t.Errorf("%s: got %v, want %v", tt.name, result, tt.expected)
+        }
+        if cap(result) < tt.expectedMinCap {
+            t.Errorf("%s: got capacity %d, want at least %d", tt.name, cap(result), tt.expectedMinCap)
+        }
+    }
+
+    // Shrink zeroes discarded elements
+    backing := []int{1, 2, 3}
+    ks.Shrink(backing, 1)
+    if !slices.Equal(backing, []int{1, 0, 0}) {
+        t.Errorf("Shrink: discarded elements not zeroed: %v", backing)
+    }
+}
+
+func TestDeferred(t *testing.T) {
+    errA := errors.New("a")
+    errC := errors.New("c")
+    errResult := errors.New("result")
+    var closed []string
+
+    f := func() (err error) {
+        var cleanup ks.Deferred
+        defer cleanup.Into(&err)
+
+        var a ks.Deferred // a Deferred is itself an io.Closer
+        a.Add(func() error {
+            closed = append(closed, "a")
+            return errA
+        })
+        cleanup.Closer(&a)
+        cleanup.AddFunc(func() { closed = append(closed, "b") })
+        cleanup.Add(func() error {
+            closed = append(closed, "c")
+            return errC
+        })
+        return errResult
+    }
+
+    err := f()
+    if !slices.Equal(closed, []string{"c", "b", "a"}) {
+        t.Errorf("got cleanup order %v", closed)
+    }
+    for _, target := range []error{errA, errC, errResult} {
+        if !errors.Is(err, target) {
+            t.Errorf("expected error %v to match %v", err, target)
+        }
+    }
+
+    var empty ks.Deferred
+    if err := empty.Close(); err != nil {
+        t.Errorf("got unexpected error %v for empty Deferred", err)
+    }
+
+    closed = nil
+    var panicking ks.Deferred
+    panicking.AddFunc(func() { closed = append(closed, "a") })
+    panicking.AddFunc(func() { panic("b") })
+    panicking.AddFunc(func() { closed = append(closed, "c") })
+    func() {
+        defer func() {
+            if r := recover(); r != "b" {
+                t.Errorf("got recovered value %v, expected %q", r, "b")
+            }
+        }()
+        _ = panicking.Close()
+    }()
+    if !slices.Equal(closed, []string{"c", "a"}) {
+        t.Errorf("got cleanup order %v after panic", closed)
+    }
+    if err := panicking.Close(); err != nil {
+        t.Errorf("got unexpected error %v for closed Deferred", err)
+    }
+}
+
+func TestRange(t *testing.T) {
+    errStop := errors.New("stop")
+    var seen []string
+
+    stopAt := func(stop string) func(k int, v string) error {
+        return func(k int, v string) error {
+            seen = append(seen, v)
+            if v == stop { return errStop }
+            return nil
+        }
+    }
+
+    seen = nil
+    k, err := ks.RangeSlice(stopAt("c"), []string{"a", "b", "c", "d"})
+    if (k != 2) || (err != errStop) || !slices.Equal(seen, []string{"a", "b", "c"}) {
+        t.Errorf("RangeSlice: got (%d, %v) after %v", k, err, seen)
+    }
+
+    seen = nil
+    k, err = ks.RangeSlice(stopAt("z"), []string{"a", "b"})
+    if (k != 0) || (err != nil) || !slices.Equal(seen, []string{"a", "b"}) {
+        t.Errorf("RangeSlice: got (%d, %v) after %v", k, err, seen)
+    }
+
+    key, err := ks.RangeMap(func(k string, v int) error {
+        if v == 2 { return errStop }
+        return nil
+    }, map[string]int{"one": 1, "two": 2, "three": 3})
+    if (key != "two") || (err != errStop) {
+        t.Errorf("RangeMap: got (%q, %v)", key, err)
+    }
+
+    var runes []rune
+    k, err = ks.RangeString(func(i int, r rune) error {
+        runes = append(runes, r)
+        if r == '€' { return errStop }
+        return nil
+    }, "a€b")
+    if (k != 1) || (err != errStop) || !slices.Equal(runes, []rune("a€")) {
+        t.Errorf("RangeString: got (%d, %v) after %q", k, err, string(runes))
+    }
+
+    c := make(chan string, 4)
+    c <- "a"; c <- "b"; c <- "c"; c <- "d"
+    close(c)
+    seen = nil
+    err = ks.RangeChan(func(v string) error { return stopAt("b")(0, v) }, c)
+    if (err != errStop) || !slices.Equal(seen, []string{"a", "b"}) {
+        t.Errorf("RangeChan: got %v after %v", err, seen)
+    }
+
+    seen = nil
+    err = ks.RangeIt(func(v string) error { return stopAt("z")(0, v) }, iter.FromChan(c))
+    if (err != nil) || !slices.Equal(seen, []string{"c", "d"}) {
+        t.Errorf("RangeIt: got %v after %v", err, seen)
+    }
+}
+
+func TestMemoize(t *testing.T) {
+    var calls []int
+    square := func(x int) int {
+        calls = append(calls, x)
+        return x * x
+    }
+
+    f := ks.Memoize(square)
+    for _, x := range []int{2, 3, 2, 3, 4} {
+        if got := f(x); got != x * x {
+            t.Errorf("Memoize: got f(%d) = %d", x, got)
+        }
+    }
+    if !slices.Equal(calls, []int{2, 3, 4}) {
+        t.Errorf("Memoize: got calls %v", calls)
+    }
+
+    calls = nil
+    f = ks.MemoizeLRU(2, square)
+    for _, x := range []int{1, 2, 1, 3, 2, 1} {
+        if got := f(x); got != x * x {
+            t.Errorf("MemoizeLRU: got f(%d) = %d", x, got)
+        }
+    }
+    // 3 evicts 2 (least recently used), then 2 evicts 1, then 1 evicts 3.
+    if !slices.Equal(calls, []int{1, 2, 3, 2, 1}) {
+        t.Errorf("MemoizeLRU: got calls %v", calls)
+    }
+}
+
+func TestMemoizeConcurrent(t *testing.T) {
+    var count atomic.Int32
+    f := ks.MemoizeConcurrent(8, func(x int) int {
+        count.Add(1)
+        return x * x
+    })
+
+    var wg sync.WaitGroup
+    for i := 0; i < 16; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for x := 0; x < 8; x++ {
+                if got := f(x); got != x * x {
+                    t.Errorf("got f(%d) = %d", x, got)
+                }
+            }
+        }()
+    }
+    wg.Wait()
+
+    if n := count.Load(); (n < 8) || (n > 8 * 16) {
+        t.Errorf("got %d calls", n)
+    }
+    before := count.Load()
+    f(0)
+    if count.Load() != before {
+        t.Errorf("expected cached result")
+    }
+}
+
+func TestDeferred_OnError(t *testing.T) {
+    errOpen := errors.New("open")
+    var closed []string
+
+    construct := func(fail bool) (_ *ks.Deferred, err error) {
+        var cleanup ks.Deferred
+        defer cleanup.OnError(&err)
+
+        var a, b ks.Deferred
+        a.AddFunc(func() { closed = append(closed, "a") })
+        b.AddFunc(func() { closed = append(closed, "b") })
+        cleanup.Closer(&a)
+        cleanup.Closer(&b)
+        if fail { return nil, errOpen }
+        return cleanup.Release(), nil
+    }
+
+    closed = nil
+    _, err := construct(true)
+    if !errors.Is(err, errOpen) || !slices.Equal(closed, []string{"b", "a"}) {
+        t.Errorf("on failure: got %v after closing %v", err, closed)
+    }
+
+    closed = nil
+    resources, err := construct(false)
+    if (err != nil) || (len(closed) != 0) {
+        t.Errorf("on success: got %v after closing %v", err, closed)
+    }
+    if err := resources.Close(); (err != nil) || !slices.Equal(closed, []string{"b", "a"}) {
+        t.Errorf("on release: got %v after closing %v", err, closed)
+    }
+}
+
+func TestFinally(t *testing.T) {
+    errClose := errors.New("close")
+    errResult := errors.New("result")
+
+    f := func(result error, closeErr error) (err error) {
+        defer ks.Finally(&err, func() error { return closeErr })
+        return result
+    }
+
+    if err := f(nil, nil); err != nil {
+        t.Errorf("got unexpected error %v", err)
+    }
+    if err := f(nil, errClose); !errors.Is(err, errClose) {
+        t.Errorf("got %v, expected %v", err, errClose)
+    }
+    if err := f(errResult, errClose); !errors.Is(err, errClose) || !errors.Is(err, errResult) {
+        t.Errorf("got %v, expected both errors", err)
+    }
+}
@@ -0,0 +1,113 @@
+package ks
+
+import (
+    "container/list"
+    "sync"
+)
+
+// Memoize returns a function that wraps a pure function f, such that the
+// result of f(k) for any given k is computed only once, and subsequent
+// calls with the same k return the cached result.
+//
+// The cache is unbounded, so this is only suitable where the number of
+// distinct inputs is small. See [MemoizeLRU] for a bounded variant.
+//
+// The returned function is not safe for concurrent use. See
+// [MemoizeConcurrent] for a concurrency-safe variant.
+func Memoize[K comparable, V any](f func(K) V) func(K) V {
+    cache := make(map[K]V)
+    return func(k K) V {
+        if v, ok := cache[k]; ok { return v }
+        v := f(k)
+        cache[k] = v
+        return v
+    }
+}
+
+// MemoizeLRU is like [Memoize], but the cache holds at most capacity
+// results. When the cache is full, the least-recently used result is
+// evicted to make room for a new one. If capacity is less than or equal to
+// zero, the cache is unbounded, as in [Memoize].
+//
+// The returned function is not safe for concurrent use. See
+// [MemoizeConcurrent] for a concurrency-safe variant.
+func MemoizeLRU[K comparable, V any](capacity int, f func(K) V) func(K) V {
+    if capacity <= 0 { return Memoize(f) }
+    cache := newLRU[K, V](capacity)
+    return func(k K) V {
+        if v, ok := cache.get(k); ok { return v }
+        v := f(k)
+        cache.put(k, v)
+        return v
+    }
+}
+
+// MemoizeConcurrent is like [MemoizeLRU], but the returned function is safe
+// for concurrent use. If capacity is less than or equal to zero, the cache
+// is unbounded.
+//
+// The function f is not called while holding a lock, so that slow
+// computations for different inputs may proceed in parallel. As a result, if
+// several goroutines request the same uncached input at the same time, f may
+// be called more than once for that input. This is harmless if f is pure.
+func MemoizeConcurrent[K comparable, V any](capacity int, f func(K) V) func(K) V {
+    var mu sync.Mutex
+    cache := newLRU[K, V](capacity)
+    return func(k K) V {
+        mu.Lock()
+        v, ok := cache.get(k)
+        mu.Unlock()
+        if ok { return v }
+
+        v = f(k)
+
+        mu.Lock()
+        cache.put(k, v)
+        mu.Unlock()
+        return v
+    }
+}
+
+// lru is a simple least-recently used cache of (key, value) pairs. A
+// capacity less than or equal to zero means the cache is unbounded.
+type lru[K comparable, V any] struct {
+    capacity int
+    order *list.List // of lruEntry, most recently used at the front
+    entries map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+    key K
+    value V
+}
+
+func newLRU[K comparable, V any](capacity int) *lru[K, V] {
+    return &lru[K, V]{
+        capacity: capacity,
+        order:    list.New(),
+        entries:  make(map[K]*list.Element),
+    }
+}
+
+func (c *lru[K, V]) get(k K) (V, bool) {
+    if e, ok := c.entries[k]; ok {
+        c.order.MoveToFront(e)
+        return e.Value.(lruEntry[K, V]).value, true
+    }
+    var zero V
+    return zero, false
+}
+
+func (c *lru[K, V]) put(k K, v V) {
+    if e, ok := c.entries[k]; ok {
+        e.Value = lruEntry[K, V]{k, v}
+        c.order.MoveToFront(e)
+        return
+    }
+    if (c.capacity > 0) && (c.order.Len() >= c.capacity) {
+        oldest := c.order.Back()
+        c.order.Remove(oldest)
+        delete(c.entries, oldest.Value.(lruEntry[K, V]).key)
+    }
+    c.entries[k] = c.order.PushFront(lruEntry[K, V]{k, v})
+}
@@ -0,0 +1,70 @@
+package ks
+
+import (
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// RangeSlice calls some function f(i, x) => err for each index i and element
+// x of slice xs, in order. If the return value of f is not nil, the iteration
+// stops immediately, and returns (i, err) for the given i. Otherwise, returns
+// (0, nil).
+func RangeSlice[X any](f func(int, X) error, xs []X) (int, error) {
+    for i, x := range xs {
+        if err := f(i, x); err != nil { return i, err }
+    }
+    return 0, nil
+}
+
+// RangeMap calls some function f(k, v) => err for each key k and value v of
+// the map collection m, in an unspecified order. If the return value of f is
+// not nil, the iteration stops immediately, and returns (k, err) for the
+// given k. Otherwise, returns (zero, nil).
+func RangeMap[K comparable, V any](f func(K, V) error, m map[K]V) (K, error) {
+    for k, v := range m {
+        if err := f(k, v); err != nil { return k, err }
+    }
+    var zero K
+    return zero, nil
+}
+
+// RangeString calls some function f(i, r) => err for each rune r of string s,
+// where i is the byte offset of the start of that rune, in the same manner
+// as a "for i, r := range s" loop (including the handling of invalid UTF-8).
+// If the return value of f is not nil, the iteration stops immediately, and
+// returns (i, err) for the given i. Otherwise, returns (0, nil).
+func RangeString(f func(int, rune) error, s string) (int, error) {
+    for i, r := range s {
+        if err := f(i, r); err != nil { return i, err }
+    }
+    return 0, nil
+}
+
+// RangeChan calls some function f(x) => err for each value x received from
+// channel c, until the channel is closed. If the return value of f is not
+// nil, the iteration stops immediately, and returns err. Otherwise, returns
+// nil.
+//
+// Note that, if the iteration stops early, any values remaining in the
+// channel are not received.
+func RangeChan[X any](f func(X) error, c <-chan X) error {
+    for x := range c {
+        if err := f(x); err != nil { return err }
+    }
+    return nil
+}
+
+// RangeIt calls some function f(x) => err for each value x produced by the
+// iterator it, until the iterator is exhausted. If the return value of f is
+// not nil, the iteration stops immediately, and returns err. Otherwise,
+// returns nil.
+//
+// Combined with functions such as [iter.FromSlice], [iter.FromMap], and
+// [iter.FromChan], this allows any sequence that can be expressed as an
+// [iter.It] to be ranged over with the same early-termination semantics.
+func RangeIt[X any](f func(X) error, it iter.It[X]) error {
+    for {
+        x, ok := it()
+        if !ok { return nil }
+        if err := f(x); err != nil { return err }
+    }
+}
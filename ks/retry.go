@@ -0,0 +1,118 @@
+package ks
+
+import (
+    "context"
+    "errors"
+    "math"
+    "math/rand"
+    "time"
+)
+
+// RetryPolicy configures the behaviour of [Retry] and [RetryValue].
+//
+// The zero value is a useful policy that retries forever, without any delay
+// between attempts, until success or until the context is cancelled.
+type RetryPolicy struct {
+    // MaxAttempts is the maximum number of times to call the function,
+    // including the first attempt. If zero or negative, there is no limit.
+    MaxAttempts int
+
+    // InitialDelay is the delay before the second attempt.
+    InitialDelay time.Duration
+
+    // MaxDelay, if positive, caps the delay between any two attempts.
+    // Otherwise, the delay is capped at the largest [time.Duration].
+    MaxDelay time.Duration
+
+    // Multiplier scales the delay after each failed attempt, for exponential
+    // backoff. If less than 1 (including the zero value), a multiplier of 2
+    // is used.
+    Multiplier float64
+
+    // Jitter, between 0 and 1, randomly shortens each delay by up to that
+    // fraction of itself. This helps to avoid many callers retrying in
+    // lockstep. For example, a Jitter of 0.25 gives a delay between 75% and
+    // 100% of the calculated value.
+    Jitter float64
+
+    // Retryable, if not nil, is called with each error returned by the
+    // function. If it returns false, the error is considered permanent and
+    // is returned immediately without any further attempts.
+    Retryable func(error) bool
+}
+
+// Delay returns the delay before the given attempt, starting at attempt 1
+// for the first retry (i.e. the second call), before any jitter is applied.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+    if (attempt < 1) || (p.InitialDelay <= 0) { return 0 }
+    multiplier := p.Multiplier
+    if multiplier < 1 { multiplier = 2 }
+
+    limit := time.Duration(math.MaxInt64)
+    if p.MaxDelay > 0 { limit = p.MaxDelay }
+
+    // may be +Inf for a large attempt
+    delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt - 1))
+    if delay >= float64(limit) { return limit }
+    return time.Duration(delay)
+}
+
+// Retry calls f until it returns a nil error, according to the given
+// [RetryPolicy], waiting with exponential backoff between attempts.
+//
+// Retry returns nil on success. If the maximum number of attempts is
+// reached, or an error is not retryable, returns the last error returned by
+// f. If the context is cancelled, returns the last error returned by f (if
+// any) joined with the context's error (see [errors.Join]).
+func Retry(ctx context.Context, policy RetryPolicy, f func() error) error {
+    _, err := RetryValue(ctx, policy, func() (struct{}, error) {
+        return struct{}{}, f()
+    })
+    return err
+}
+
+// RetryValue is like [Retry], but for a function f that returns a (value,
+// error) tuple. On success, returns the value returned by f.
+func RetryValue[T any](ctx context.Context, policy RetryPolicy, f func() (T, error)) (T, error) {
+    var zero T
+    var lastErr error
+    var timer *time.Timer
+
+    for attempt := 0; (policy.MaxAttempts <= 0) || (attempt < policy.MaxAttempts); attempt++ {
+        if attempt > 0 {
+            delay := policy.Delay(attempt)
+            if policy.Jitter > 0 {
+                delay -= time.Duration(rand.Float64() * policy.Jitter * float64(delay))
+            }
+
+            if delay > 0 {
+                if timer == nil {
+                    timer = time.NewTimer(delay)
+                    defer timer.Stop()
+                } else {
+                    timer.Reset(delay)
+                }
+
+                select {
+                    case <-ctx.Done():
+                        return zero, errors.Join(lastErr, ctx.Err())
+                    case <-timer.C:
+                }
+            }
+        }
+
+        if err := ctx.Err(); err != nil {
+            return zero, errors.Join(lastErr, err)
+        }
+
+        value, err := f()
+        if err == nil { return value, nil }
+        lastErr = err
+
+        if (policy.Retryable != nil) && !policy.Retryable(err) {
+            return zero, err
+        }
+    }
+
+    return zero, lastErr
+}
@@ -0,0 +1,87 @@
+package ks
+
+import (
+    "slices"
+)
+
+// The slice helpers in this file share a consistent vocabulary:
+//
+//   - "length" is len(xs) and "capacity" is cap(xs).
+//   - a helper that grows the capacity may reallocate, in which case the
+//     existing elements are copied to the new backing array, and the input
+//     slice should no longer be used.
+//   - a helper documented as "zeroing" an element guarantees that element
+//     is the zero value, even if the backing array is reused and previously
+//     held some other value at that position.
+
+// Reserve grows a slice, if necessary, to fit at least size extra elements.
+//
+// Deprecated: use [slices.Grow].
+func Reserve[T any](xs []T, size int) []T {
+    return slices.Grow(xs, size)
+}
+
+// EnsureCap grows a slice, if necessary, so that it has a capacity of at least
+// size elements. The length is unchanged.
+func EnsureCap[T any](xs []T, size int) []T {
+    if size <= cap(xs) { return xs }
+    return slices.Grow(xs, size - len(xs))
+}
+
+// SetLength grows a slice, if necessary, so that has a capacity of at least
+// size elements, and a length of exactly size elements. If size is less than
+// the current length, the slice is truncated (see also [Shrink]).
+//
+// Elements between the original length and the original capacity keep
+// whatever value the underlying array held at those positions. Any trailing
+// elements in the underlying array that fall beyond the original capacity
+// are zeroed. To always zero the new elements, use [GrowLen].
+func SetLength[T any](xs []T, size int) []T {
+    if size < 0 { size = 0 }
+    precap := cap(xs)
+    if size <= precap { return xs[0:size] }
+    xs = EnsureCap(xs, size)
+    xs = xs[0:size]
+    clear(xs[precap:size])
+    return xs
+}
+
+// GrowLen extends the length of a slice by n elements, growing its capacity
+// if necessary. The new elements are zeroed. If n is zero or negative, the
+// slice is returned unchanged.
+func GrowLen[T any](xs []T, n int) []T {
+    if n <= 0 { return xs }
+    start := len(xs)
+    xs = EnsureCap(xs, start + n)
+    xs = xs[0:start + n]
+    clear(xs[start:])
+    return xs
+}
+
+// Shrink truncates a slice to a length of at most size elements. The
+// discarded elements, between the new length and the original length, are
+// zeroed, so that any values they reference may be garbage collected. The
+// capacity is unchanged.
+//
+// If size is greater than or equal to the current length, the slice is
+// returned unchanged.
+func Shrink[T any](xs []T, size int) []T {
+    if size < 0 { size = 0 }
+    if size >= len(xs) { return xs }
+    clear(xs[size:])
+    return xs[0:size]
+}
+
+// Reuse returns a slice with a length of exactly size elements, all zeroed,
+// that reuses the underlying array of xs if it has the capacity. Otherwise,
+// a new slice is allocated. This is useful for reusing a buffer between
+// successive operations.
+//
+// The existing elements of xs are not preserved.
+func Reuse[T any](xs []T, size int) []T {
+    if size < 0 { size = 0 }
+    if size > cap(xs) { return make([]T, size) }
+    xs = xs[0:size]
+    clear(xs)
+    return xs
+}
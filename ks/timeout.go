@@ -0,0 +1,82 @@
+package ks
+
+import (
+    "context"
+    "time"
+)
+
+// WithContext calls f with ctx, and returns the error returned by f, or the
+// context's error if the context is cancelled or reaches its deadline before
+// f returns.
+//
+// Function f should honour cancellation of the context it is given. However,
+// if it does not, WithContext still returns as soon as the context is done.
+// In that case, f continues to run in a separate goroutine until it returns,
+// and its eventual result is discarded.
+//
+// If f panics, the panic is raised again in the calling goroutine.
+func WithContext(ctx context.Context, f func(ctx context.Context) error) error {
+    _, err := WithContextValue(ctx, func(ctx context.Context) (struct{}, error) {
+        return struct{}{}, f(ctx)
+    })
+    return err
+}
+
+// WithContextValue is like [WithContext], but for a function f that returns
+// a (value, error) tuple.
+func WithContextValue[T any](
+    ctx context.Context,
+    f func(ctx context.Context) (T, error),
+) (T, error) {
+    type result struct {
+        value T
+        err error
+        panicked bool
+        recovered any
+    }
+
+    var zero T
+    if err := ctx.Err(); err != nil { return zero, err }
+
+    // buffered so that the goroutine never blocks, even if nothing receives
+    done := make(chan result, 1)
+
+    go func() {
+        var r result
+        defer func() {
+            if x := recover(); x != nil {
+                r.panicked, r.recovered = true, x
+            }
+            done <- r
+        }()
+        r.value, r.err = f(ctx)
+    }()
+
+    select {
+        case r := <-done:
+            if r.panicked { panic(r.recovered) }
+            return r.value, r.err
+        case <-ctx.Done():
+            return zero, ctx.Err()
+    }
+}
+
+// WithTimeout calls f with a context that is cancelled after duration d, and
+// returns the error returned by f, or [context.DeadlineExceeded] if f does
+// not return in time. See [WithContext].
+func WithTimeout(d time.Duration, f func(ctx context.Context) error) error {
+    ctx, cancel := context.WithTimeout(context.Background(), d)
+    defer cancel()
+    return WithContext(ctx, f)
+}
+
+// WithTimeoutValue is like [WithTimeout], but for a function f that returns a
+// (value, error) tuple.
+func WithTimeoutValue[T any](
+    d time.Duration,
+    f func(ctx context.Context) (T, error),
+) (T, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), d)
+    defer cancel()
+    return WithContextValue(ctx, f)
+}
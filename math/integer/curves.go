@@ -0,0 +1,198 @@
+package integer
+
+// spread2 spreads the lower 32 bits of x so that there is one zero bit
+// between each bit e.g. 0b1111 -> 0b01010101.
+func spread2(x uint64) uint64 {
+    x &= 0x00000000FFFFFFFF
+    x = (x | (x << 16)) & 0x0000FFFF0000FFFF
+    x = (x | (x <<  8)) & 0x00FF00FF00FF00FF
+    x = (x | (x <<  4)) & 0x0F0F0F0F0F0F0F0F
+    x = (x | (x <<  2)) & 0x3333333333333333
+    x = (x | (x <<  1)) & 0x5555555555555555
+    return x
+}
+
+// compact2 is the inverse of spread2.
+func compact2(x uint64) uint64 {
+    x &= 0x5555555555555555
+    x = (x | (x >>  1)) & 0x3333333333333333
+    x = (x | (x >>  2)) & 0x0F0F0F0F0F0F0F0F
+    x = (x | (x >>  4)) & 0x00FF00FF00FF00FF
+    x = (x | (x >>  8)) & 0x0000FFFF0000FFFF
+    x = (x | (x >> 16)) & 0x00000000FFFFFFFF
+    return x
+}
+
+// spread3 spreads the lower 21 bits of x so that there are two zero bits
+// between each bit e.g. 0b111 -> 0b001001001.
+func spread3(x uint64) uint64 {
+    x &= 0x1FFFFF
+    x = (x | (x << 32)) & 0x001F00000000FFFF
+    x = (x | (x << 16)) & 0x001F0000FF0000FF
+    x = (x | (x <<  8)) & 0x100F00F00F00F00F
+    x = (x | (x <<  4)) & 0x10C30C30C30C30C3
+    x = (x | (x <<  2)) & 0x1249249249249249
+    return x
+}
+
+// compact3 is the inverse of spread3.
+func compact3(x uint64) uint64 {
+    x &= 0x1249249249249249
+    x = (x | (x >>  2)) & 0x10C30C30C30C30C3
+    x = (x | (x >>  4)) & 0x100F00F00F00F00F
+    x = (x | (x >>  8)) & 0x001F0000FF0000FF
+    x = (x | (x >> 16)) & 0x001F00000000FFFF
+    x = (x | (x >> 32)) & 0x00000000001FFFFF
+    return x
+}
+
+// InterleaveBits returns the Morton code (also known as the Z-order index) of
+// the given coordinates. This is computed by interleaving the bits of each
+// coordinate so that, for n coordinates, bit i of coordinate j becomes bit
+// (i * n) + j of the result.
+//
+// Points that are close together in space tend to have Morton codes that are
+// close together, so this is useful for laying out multidimensional data
+// in memory in a cache-coherent manner.
+//
+// Bits that would be shifted beyond the 64 bits of the result are discarded.
+// For example, with 2 coordinates, only the lower 32 bits of each coordinate
+// are used. With no coordinates, the result is zero.
+//
+// The 2- and 3-dimensional cases are optimised. See also [InterleaveBits2]
+// and [InterleaveBits3].
+func InterleaveBits(coordinates ... uint64) uint64 {
+    n := len(coordinates)
+    switch n {
+        case 0: return 0
+        case 1: return coordinates[0]
+        case 2: return (spread2(coordinates[1]) << 1) | spread2(coordinates[0])
+        case 3: return (spread3(coordinates[2]) << 2) | (spread3(coordinates[1]) << 1) | spread3(coordinates[0])
+    }
+
+    var result uint64
+    for i := 0; i * n < 64; i++ {
+        for j := 0; j < n; j++ {
+            bit := (i * n) + j
+            if bit >= 64 { break }
+            result |= ((coordinates[j] >> i) & 1) << bit
+        }
+    }
+    return result
+}
+
+    // InterleaveBits2 returns the 2-dimensional Morton code of the
+    // coordinates (x, y). See [InterleaveBits].
+    func InterleaveBits2(x, y uint32) uint64 {
+        return (spread2(uint64(y)) << 1) | spread2(uint64(x))
+    }
+
+    // InterleaveBits3 returns the 3-dimensional Morton code of the
+    // coordinates (x, y, z). Only the lower 21 bits of each coordinate are
+    // used. See [InterleaveBits].
+    func InterleaveBits3(x, y, z uint32) uint64 {
+        return (spread3(uint64(z)) << 2) | (spread3(uint64(y)) << 1) | spread3(uint64(x))
+    }
+
+// DeinterleaveBits is the inverse of [InterleaveBits]. It computes the
+// coordinates encoded by a Morton code. The number of coordinates decoded is
+// given by the length of dest, and the results are stored in dest.
+func DeinterleaveBits(dest []uint64, code uint64) {
+    n := len(dest)
+    switch n {
+        case 0: return
+        case 1: dest[0] = code; return
+        case 2:
+            dest[0] = compact2(code)
+            dest[1] = compact2(code >> 1)
+            return
+        case 3:
+            dest[0] = compact3(code)
+            dest[1] = compact3(code >> 1)
+            dest[2] = compact3(code >> 2)
+            return
+    }
+
+    clear(dest)
+    for i := 0; i * n < 64; i++ {
+        for j := 0; j < n; j++ {
+            bit := (i * n) + j
+            if bit >= 64 { break }
+            dest[j] |= ((code >> bit) & 1) << i
+        }
+    }
+}
+
+    // DeinterleaveBits2 is the inverse of [InterleaveBits2].
+    func DeinterleaveBits2(code uint64) (x, y uint32) {
+        return uint32(compact2(code)), uint32(compact2(code >> 1))
+    }
+
+    // DeinterleaveBits3 is the inverse of [InterleaveBits3].
+    func DeinterleaveBits3(code uint64) (x, y, z uint32) {
+        return uint32(compact3(code)), uint32(compact3(code >> 1)), uint32(compact3(code >> 2))
+    }
+
+// HilbertIndex returns the distance along a 2-dimensional Hilbert curve that
+// fills a square with sides of length 2^order, of the point (x, y).
+//
+// Like a Morton code (see [InterleaveBits]), the Hilbert curve preserves
+// locality, but does so better, at the cost of being more expensive to
+// compute. Unlike a Morton code, consecutive points along the Hilbert curve
+// are always adjacent.
+//
+// The coordinates are taken modulo 2^order. The order must be between 0 and
+// 32 inclusive, or this function panics with [ErrOverflow].
+func HilbertIndex(order int, x, y uint32) uint64 {
+    if (order < 0) || (order > 32) { panic(ErrOverflow) }
+    if order == 0 { return 0 }
+    n := uint64(1) << order
+    rx, ry := uint64(0), uint64(0)
+    px, py := uint64(x) & (n - 1), uint64(y) & (n - 1)
+    var d uint64
+
+    for s := n / 2; s > 0; s /= 2 {
+        rx = 0; if (px & s) != 0 { rx = 1 }
+        ry = 0; if (py & s) != 0 { ry = 1 }
+        d += s * s * ((3 * rx) ^ ry)
+        px, py = hilbertRotate(n, px, py, rx, ry)
+    }
+    return d
+}
+
+// HilbertOffsets is the inverse of [HilbertIndex]. It returns the point (x, y)
+// at distance d along a 2-dimensional Hilbert curve that fills a square with
+// sides of length 2^order.
+//
+// The distance is taken modulo 2^(2 * order). The order must be between 0 and
+// 32 inclusive, or this function panics with [ErrOverflow].
+func HilbertOffsets(order int, d uint64) (x, y uint32) {
+    if (order < 0) || (order > 32) { panic(ErrOverflow) }
+    if order == 0 { return 0, 0 }
+    n := uint64(1) << order
+    var px, py uint64
+    t := d
+    if order < 32 { t &= (n * n) - 1 }
+
+    for s := uint64(1); s < n; s *= 2 {
+        rx := 1 & (t / 2)
+        ry := 1 & (t ^ rx)
+        px, py = hilbertRotate(s, px, py, rx, ry)
+        px += s * rx
+        py += s * ry
+        t /= 4
+    }
+    return uint32(px), uint32(py)
+}
+
+// hilbertRotate rotates and/or flips a quadrant appropriately.
+func hilbertRotate(n, x, y, rx, ry uint64) (uint64, uint64) {
+    if ry == 0 {
+        if rx == 1 {
+            x = n - 1 - x
+            y = n - 1 - y
+        }
+        x, y = y, x
+    }
+    return x, y
+}
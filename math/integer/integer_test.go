@@ -2,10 +2,11 @@ package integer_test
 
 import (
     "math/bits"
+    "slices"
     "testing"
 
-    "github.com/tawesoft/golib/v2/internal/test"
     "github.com/tawesoft/golib/v2/math/integer"
+    "github.com/tawesoft/golib/v2/test"
 )
 
 func TestPower(t *testing.T) {
@@ -286,3 +287,88 @@ func TestNextPowTwo(t *testing.T) {
             }
         }
     }
+
+func TestInterleaveBits(t *testing.T) {
+    tests := []struct{
+        coordinates []uint64
+        expected uint64
+    }{
+        {[]uint64{}, 0},
+        {[]uint64{5}, 5},
+        {[]uint64{0b11, 0b00}, 0b0101},
+        {[]uint64{0b00, 0b11}, 0b1010},
+        {[]uint64{0b101, 0b011}, 0b011011},
+        {[]uint64{0b1, 0b1, 0b1}, 0b111},
+        {[]uint64{0b10, 0b00, 0b01}, 0b001100},
+        {[]uint64{0b1, 0b0, 0b0, 0b1, 0b1}, 0b11001},
+        {[]uint64{0b10, 0b0, 0b0, 0b10, 0b0}, 0b0100100000},
+    }
+
+    for _, tt := range tests {
+        actual := integer.InterleaveBits(tt.coordinates...)
+        if actual != tt.expected {
+            t.Errorf("InterleaveBits(%v) = %b; expected %b", tt.coordinates, actual, tt.expected)
+        }
+
+        dest := make([]uint64, len(tt.coordinates))
+        integer.DeinterleaveBits(dest, actual)
+        if !slices.Equal(dest, tt.coordinates) {
+            t.Errorf("DeinterleaveBits(%b) = %v; expected %v", actual, dest, tt.coordinates)
+        }
+    }
+
+    for i := uint32(0); i < 4096; i += 7 {
+        x, y, z := i, (i * 31) & 0x1FFFFF, (i * 101) & 0x1FFFFF
+
+        code2 := integer.InterleaveBits2(x, y)
+        if code2 != integer.InterleaveBits(uint64(x), uint64(y)) {
+            t.Errorf("InterleaveBits2(%d, %d) disagrees with InterleaveBits", x, y)
+        }
+        if x2, y2 := integer.DeinterleaveBits2(code2); (x2 != x) || (y2 != y) {
+            t.Errorf("DeinterleaveBits2(%d) = %d, %d; expected %d, %d", code2, x2, y2, x, y)
+        }
+
+        code3 := integer.InterleaveBits3(x, y, z)
+        if x3, y3, z3 := integer.DeinterleaveBits3(code3); (x3 != x) || (y3 != y) || (z3 != z) {
+            t.Errorf("DeinterleaveBits3(%d) = %d, %d, %d; expected %d, %d, %d",
+                code3, x3, y3, z3, x, y, z)
+        }
+    }
+}
+
+func TestHilbert(t *testing.T) {
+    // order 1: (0,0), (0,1), (1,1), (1,0)
+    expected := [][2]uint32{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+    for d, xy := range expected {
+        if got := integer.HilbertIndex(1, xy[0], xy[1]); got != uint64(d) {
+            t.Errorf("HilbertIndex(1, %d, %d) = %d; expected %d", xy[0], xy[1], got, d)
+        }
+    }
+
+    for _, order := range []int{1, 2, 3, 5, 8} {
+        n := uint64(1) << order
+        seen := make(map[uint64]bool)
+        var px, py uint32
+        for d := uint64(0); d < n * n; d++ {
+            x, y := integer.HilbertOffsets(order, d)
+            if got := integer.HilbertIndex(order, x, y); got != d {
+                t.Errorf("HilbertIndex(%d, %d, %d) = %d; expected %d", order, x, y, got, d)
+            }
+            seen[uint64(y) * n + uint64(x)] = true
+
+            // consecutive points are always adjacent
+            if d > 0 {
+                dx := int64(x) - int64(px)
+                dy := int64(y) - int64(py)
+                if (dx * dx) + (dy * dy) != 1 {
+                    t.Errorf("HilbertOffsets(%d, %d) = (%d, %d) is not adjacent to (%d, %d)",
+                        order, d, x, y, px, py)
+                }
+            }
+            px, py = x, y
+        }
+        if uint64(len(seen)) != n * n {
+            t.Errorf("Hilbert curve of order %d visited %d points; expected %d", order, len(seen), n * n)
+        }
+    }
+}
@@ -0,0 +1,82 @@
+// Package interp implements interpolation and easing functions for rescaling
+// and blending floating point values.
+package interp
+
+import (
+    "golang.org/x/exp/constraints"
+)
+
+// Lerp performs linear interpolation between a and b by t, returning a value
+// that is a when t is 0, b when t is 1, and a proportional value in between.
+//
+// The result is not clamped, so t outside the range [0, 1] extrapolates. See
+// [LerpClamped].
+func Lerp[F constraints.Float](a F, b F, t F) F {
+    // this form is exact at t = 1, unlike a + t * (b - a)
+    return ((1 - t) * a) + (t * b)
+}
+
+    // LerpClamped is like [Lerp], but t is first clamped to the range [0, 1].
+    func LerpClamped[F constraints.Float](a F, b F, t F) F {
+        return Lerp(a, b, saturate(t))
+    }
+
+// InverseLerp is the inverse of [Lerp]. It returns the t that would produce
+// the value x when linearly interpolating between a and b.
+//
+// If a == b, the result is zero. The result is not clamped, so values of x
+// outside the range [a, b] produce a t outside the range [0, 1]. See
+// [InverseLerpClamped].
+func InverseLerp[F constraints.Float](a F, b F, x F) F {
+    if a == b { return 0 }
+    return (x - a) / (b - a)
+}
+
+    // InverseLerpClamped is like [InverseLerp], but the result is clamped to
+    // the range [0, 1].
+    func InverseLerpClamped[F constraints.Float](a F, b F, x F) F {
+        return saturate(InverseLerp(a, b, x))
+    }
+
+// Remap maps x from the range [inMin, inMax] to the equivalent value in the
+// range [outMin, outMax].
+//
+// For example, Remap(0, 100, 32, 212, 37.0) converts 37 degrees Celsius to
+// Fahrenheit.
+//
+// The result is not clamped. See [RemapClamped].
+func Remap[F constraints.Float](inMin F, inMax F, outMin F, outMax F, x F) F {
+    return Lerp(outMin, outMax, InverseLerp(inMin, inMax, x))
+}
+
+    // RemapClamped is like [Remap], but the result is clamped to the range
+    // [outMin, outMax].
+    func RemapClamped[F constraints.Float](inMin F, inMax F, outMin F, outMax F, x F) F {
+        return Lerp(outMin, outMax, InverseLerpClamped(inMin, inMax, x))
+    }
+
+// SmoothStep performs smooth Hermite interpolation between 0 and 1 when
+// x is between edge0 and edge1. It returns 0 for x <= edge0, and 1 for x >=
+// edge1.
+//
+// This is the same as the GLSL function of the same name. The first
+// derivative is zero at each edge, but the second derivative is not: for
+// that, see [SmootherStep].
+func SmoothStep[F constraints.Float](edge0 F, edge1 F, x F) F {
+    t := InverseLerpClamped(edge0, edge1, x)
+    return t * t * (3 - (2 * t))
+}
+
+// SmootherStep is like [SmoothStep], but has zero first and second
+// derivatives at each edge, as described by Ken Perlin.
+func SmootherStep[F constraints.Float](edge0 F, edge1 F, x F) F {
+    t := InverseLerpClamped(edge0, edge1, x)
+    return t * t * t * ((t * ((t * 6) - 15)) + 10)
+}
+
+// saturate clamps x to the range [0, 1].
+func saturate[F constraints.Float](x F) F {
+    if x < 0 { return 0 }
+    if x > 1 { return 1 }
+    return x
+}
@@ -0,0 +1,54 @@
+package interp_test
+
+import (
+    "fmt"
+    "math"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/math/interp"
+)
+
+func ExampleRemap() {
+    // convert degrees Celsius to Fahrenheit
+    fmt.Printf("%.1f\n", interp.Remap(0, 100, 32, 212, 37.0))
+
+    // Output:
+    // 98.6
+}
+
+func TestInterp(t *testing.T) {
+    near := func(a, b float64) bool {
+        return math.Abs(a - b) < 0.0001
+    }
+
+    tests := []struct{
+        name string
+        f func(x float64) float64
+        x, expected float64
+    }{
+        {"Lerp",               func(x float64) float64 { return interp.Lerp(10, 20, x) },                0.25, 12.5},
+        {"Lerp/extrapolate",   func(x float64) float64 { return interp.Lerp(10, 20, x) },                2.0,  30.0},
+        {"Lerp/exact",         func(x float64) float64 { return interp.Lerp(0.1, 0.7, x) },              1.0,  0.7},
+        {"LerpClamped",        func(x float64) float64 { return interp.LerpClamped(10, 20, x) },         2.0,  20.0},
+        {"LerpClamped/low",    func(x float64) float64 { return interp.LerpClamped(10, 20, x) },        -1.0,  10.0},
+        {"InverseLerp",        func(x float64) float64 { return interp.InverseLerp(10, 20, x) },         15,   0.5},
+        {"InverseLerp/equal",  func(x float64) float64 { return interp.InverseLerp(10, 10, x) },         15,   0.0},
+        {"InverseLerp/beyond", func(x float64) float64 { return interp.InverseLerp(10, 20, x) },         30,   2.0},
+        {"InverseLerpClamped", func(x float64) float64 { return interp.InverseLerpClamped(10, 20, x) },  30,   1.0},
+        {"Remap",              func(x float64) float64 { return interp.Remap(0, 10, 100, 200, x) },      5,    150},
+        {"Remap/reversed",     func(x float64) float64 { return interp.Remap(0, 10, 200, 100, x) },      2,    180},
+        {"RemapClamped",       func(x float64) float64 { return interp.RemapClamped(0, 10, 100, 200, x) }, 20, 200},
+        {"SmoothStep/low",     func(x float64) float64 { return interp.SmoothStep(0, 1, x) },           -1,    0},
+        {"SmoothStep/mid",     func(x float64) float64 { return interp.SmoothStep(0, 1, x) },            0.5,  0.5},
+        {"SmoothStep/quarter", func(x float64) float64 { return interp.SmoothStep(0, 1, x) },            0.25, 0.15625},
+        {"SmoothStep/high",    func(x float64) float64 { return interp.SmoothStep(0, 1, x) },            2,    1},
+        {"SmootherStep/mid",   func(x float64) float64 { return interp.SmootherStep(0, 1, x) },          0.5,  0.5},
+        {"SmootherStep/quarter", func(x float64) float64 { return interp.SmootherStep(0, 1, x) },        0.25, 0.103515625},
+    }
+
+    for _, tt := range tests {
+        if got := tt.f(tt.x); !near(got, tt.expected) {
+            t.Errorf("%s(%f): got %f, want %f", tt.name, tt.x, got, tt.expected)
+        }
+    }
+}
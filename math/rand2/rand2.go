@@ -0,0 +1,260 @@
+// Package rand2 implements small, fast, deterministic pseudo-random number
+// generators that are seedable, serialisable, and splittable.
+//
+// Unlike the generators in the standard library, the output of these
+// generators for any given seed is frozen and will not change in future
+// versions. This makes them suitable for reproducible simulations, procedural
+// generation, and randomised tests.
+//
+// The generators are not cryptographically secure, and are not safe for
+// concurrent use without additional synchronization. Instead of sharing a
+// generator between goroutines, use the Split method to give each goroutine
+// its own independent generator.
+//
+// Each generator implements [math/rand.Source64], so may also be used with
+// [math/rand.New] for access to additional distributions.
+package rand2
+
+import (
+    "encoding/binary"
+    "errors"
+    "math/bits"
+)
+
+// ErrFormat is returned when unmarshalling a generator from invalid input.
+var ErrFormat = errors.New("invalid serialised generator state")
+
+// Source is the interface implemented by each generator in this package.
+type Source interface {
+    // Uint64 returns a pseudo-random 64-bit value.
+    Uint64() uint64
+}
+
+// Float64 returns, using src, a pseudo-random number in the half-open
+// interval [0.0, 1.0).
+func Float64(src Source) float64 {
+    // 53 bits of precision
+    return float64(src.Uint64() >> 11) * 0x1.0p-53
+}
+
+// IntN returns, using src, a pseudo-random number in the half-open interval
+// [0, n). It panics if n <= 0.
+//
+// The result is unbiased, using Lemire's method.
+func IntN(src Source, n int) int {
+    if n <= 0 { panic("rand2.IntN: invalid argument") }
+    return int(Uint64N(src, uint64(n)))
+}
+
+// Uint64N returns, using src, a pseudo-random number in the half-open
+// interval [0, n). It panics if n == 0.
+//
+// The result is unbiased, using Lemire's method.
+func Uint64N(src Source, n uint64) uint64 {
+    if n == 0 { panic("rand2.Uint64N: invalid argument") }
+    hi, lo := bits.Mul64(src.Uint64(), n)
+    if lo < n {
+        threshold := -n % n
+        for lo < threshold {
+            hi, lo = bits.Mul64(src.Uint64(), n)
+        }
+    }
+    return hi
+}
+
+// Shuffle pseudo-randomises, using src, the order of n elements using the
+// Fisher-Yates algorithm. The swap function swaps the elements with indexes
+// i and j. It panics if n < 0.
+func Shuffle(src Source, n int, swap func(i, j int)) {
+    if n < 0 { panic("rand2.Shuffle: invalid argument") }
+    for i := n - 1; i > 0; i-- {
+        j := IntN(src, i + 1)
+        swap(i, j)
+    }
+}
+
+// SplitMix64 implements the SplitMix64 generator, which has 64 bits of state
+// and a period of 2^64. It is very fast, but has weaker statistical
+// properties than [Xoshiro256]. It is most useful for seeding other
+// generators.
+//
+// The zero value is a valid generator, equivalent to NewSplitMix64(0).
+type SplitMix64 struct {
+    state uint64
+}
+
+    // NewSplitMix64 returns a new SplitMix64 generator initialised with the
+    // given seed.
+    func NewSplitMix64(seed uint64) *SplitMix64 {
+        return &SplitMix64{state: seed}
+    }
+
+    // Uint64 returns a pseudo-random 64-bit value.
+    func (g *SplitMix64) Uint64() uint64 {
+        g.state += 0x9E3779B97F4A7C15
+        z := g.state
+        z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+        z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+        return z ^ (z >> 31)
+    }
+
+    // Int63 returns a non-negative pseudo-random 63-bit integer. This
+    // implements the [math/rand.Source] interface.
+    func (g *SplitMix64) Int63() int64 {
+        return int64(g.Uint64() >> 1)
+    }
+
+    // Seed resets the generator to a state given by seed. This implements the
+    // [math/rand.Source] interface.
+    func (g *SplitMix64) Seed(seed int64) {
+        g.state = uint64(seed)
+    }
+
+    // Split returns a new generator, seeded from the output of g, and
+    // advances g. The two generators are statistically independent.
+    func (g *SplitMix64) Split() *SplitMix64 {
+        return NewSplitMix64(mix64(g.Uint64()))
+    }
+
+    // MarshalBinary implements the [encoding.BinaryMarshaler] interface.
+    func (g *SplitMix64) MarshalBinary() ([]byte, error) {
+        b := make([]byte, 0, 16)
+        b = append(b, "splitmix"...)
+        b = binary.LittleEndian.AppendUint64(b, g.state)
+        return b, nil
+    }
+
+    // UnmarshalBinary implements the [encoding.BinaryUnmarshaler] interface.
+    func (g *SplitMix64) UnmarshalBinary(data []byte) error {
+        if (len(data) != 16) || (string(data[0:8]) != "splitmix") {
+            return ErrFormat
+        }
+        g.state = binary.LittleEndian.Uint64(data[8:16])
+        return nil
+    }
+
+// Xoshiro256 implements the xoshiro256** generator, which has 256 bits of
+// state and a period of 2^256 - 1. It is fast, and has excellent statistical
+// properties for general purpose use.
+//
+// The zero value is not a valid generator. Use [NewXoshiro256].
+type Xoshiro256 struct {
+    s [4]uint64
+}
+
+    // NewXoshiro256 returns a new Xoshiro256 generator initialised with the
+    // given seed. The seed is expanded into the generator's full state using
+    // [SplitMix64], as recommended by the algorithm's authors.
+    func NewXoshiro256(seed uint64) *Xoshiro256 {
+        var g Xoshiro256
+        g.Seed(int64(seed))
+        return &g
+    }
+
+    // Uint64 returns a pseudo-random 64-bit value.
+    func (g *Xoshiro256) Uint64() uint64 {
+        s := &g.s
+        result := bits.RotateLeft64(s[1] * 5, 7) * 9
+        t := s[1] << 17
+
+        s[2] ^= s[0]
+        s[3] ^= s[1]
+        s[1] ^= s[2]
+        s[0] ^= s[3]
+
+        s[2] ^= t
+        s[3] = bits.RotateLeft64(s[3], 45)
+
+        return result
+    }
+
+    // Int63 returns a non-negative pseudo-random 63-bit integer. This
+    // implements the [math/rand.Source] interface.
+    func (g *Xoshiro256) Int63() int64 {
+        return int64(g.Uint64() >> 1)
+    }
+
+    // Seed resets the generator to a state given by seed. This implements the
+    // [math/rand.Source] interface.
+    func (g *Xoshiro256) Seed(seed int64) {
+        sm := NewSplitMix64(uint64(seed))
+        for i := 0; i < 4; i++ {
+            g.s[i] = sm.Uint64()
+        }
+    }
+
+    // Jump advances the generator by 2^128 calls to Uint64. This can be used
+    // to generate 2^128 non-overlapping subsequences.
+    func (g *Xoshiro256) Jump() {
+        g.jump([4]uint64{
+            0x180EC6D33CFD0ABA, 0xD5A61266F0C9392C,
+            0xA9582618E03FC9AA, 0x39ABDC4529B1661C,
+        })
+    }
+
+    // LongJump advances the generator by 2^192 calls to Uint64. This can be
+    // used to generate 2^64 starting points, from each of which Jump will
+    // generate 2^64 non-overlapping subsequences.
+    func (g *Xoshiro256) LongJump() {
+        g.jump([4]uint64{
+            0x76E15D3EFEFDCBBF, 0xC5004E441C522FB3,
+            0x77710069854EE241, 0x39109BB02ACBE635,
+        })
+    }
+
+    func (g *Xoshiro256) jump(table [4]uint64) {
+        var s [4]uint64
+        for _, x := range table {
+            for b := 0; b < 64; b++ {
+                if (x & (uint64(1) << b)) != 0 {
+                    s[0] ^= g.s[0]
+                    s[1] ^= g.s[1]
+                    s[2] ^= g.s[2]
+                    s[3] ^= g.s[3]
+                }
+                g.Uint64()
+            }
+        }
+        g.s = s
+    }
+
+    // Split returns a new generator that starts from the current state of g,
+    // and then advances g by 2^128 steps (see [Xoshiro256.Jump]). The two
+    // generators produce non-overlapping sequences.
+    func (g *Xoshiro256) Split() *Xoshiro256 {
+        result := &Xoshiro256{s: g.s}
+        g.Jump()
+        return result
+    }
+
+    // MarshalBinary implements the [encoding.BinaryMarshaler] interface.
+    func (g *Xoshiro256) MarshalBinary() ([]byte, error) {
+        b := make([]byte, 0, 40)
+        b = append(b, "xoshi256"...)
+        for i := 0; i < 4; i++ {
+            b = binary.LittleEndian.AppendUint64(b, g.s[i])
+        }
+        return b, nil
+    }
+
+    // UnmarshalBinary implements the [encoding.BinaryUnmarshaler] interface.
+    func (g *Xoshiro256) UnmarshalBinary(data []byte) error {
+        if (len(data) != 40) || (string(data[0:8]) != "xoshi256") {
+            return ErrFormat
+        }
+        var s [4]uint64
+        for i := 0; i < 4; i++ {
+            s[i] = binary.LittleEndian.Uint64(data[8 + (8 * i):16 + (8 * i)])
+        }
+        if s == [4]uint64{} { return ErrFormat } // all-zero state is invalid
+        g.s = s
+        return nil
+    }
+
+// mix64 is the finaliser of the 64-bit MurmurHash3, used to decorrelate a
+// value used as a seed from the sequence that produced it.
+func mix64(z uint64) uint64 {
+    z = (z ^ (z >> 33)) * 0xFF51AFD7ED558CCD
+    z = (z ^ (z >> 33)) * 0xC4CEB9FE1A85EC53
+    return z ^ (z >> 33)
+}
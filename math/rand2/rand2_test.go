@@ -0,0 +1,132 @@
+package rand2_test
+
+import (
+    "math/rand"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/math/rand2"
+)
+
+// ensure the generators implement rand.Source64
+var _ rand.Source64 = (*rand2.SplitMix64)(nil)
+var _ rand.Source64 = (*rand2.Xoshiro256)(nil)
+
+func TestSplitMix64(t *testing.T) {
+    // reference values for seed 1234567 from the public domain C
+    // implementation by Sebastiano Vigna.
+    expected := []uint64{
+        6457827717110365317,
+        3203168211198807973,
+        9817491932198370423,
+        4593380528125082431,
+        16408922859458223821,
+    }
+
+    g := rand2.NewSplitMix64(1234567)
+    for i, want := range expected {
+        if got := g.Uint64(); got != want {
+            t.Errorf("SplitMix64(1234567) output %d: got %d, want %d", i, got, want)
+        }
+    }
+}
+
+func TestXoshiro256(t *testing.T) {
+    // reference values for state {1, 2, 3, 4} from the public domain C
+    // implementation by David Blackman and Sebastiano Vigna.
+    expected := []uint64{
+        11520,
+        0,
+        1509978240,
+        1215971899390074240,
+    }
+
+    var g rand2.Xoshiro256
+    state := []byte("xoshi256")
+    for _, x := range []byte{1, 2, 3, 4} {
+        state = append(state, x, 0, 0, 0, 0, 0, 0, 0)
+    }
+    if err := g.UnmarshalBinary(state); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    for i, want := range expected {
+        if got := g.Uint64(); got != want {
+            t.Errorf("Xoshiro256{1, 2, 3, 4} output %d: got %d, want %d", i, got, want)
+        }
+    }
+}
+
+func TestMarshal(t *testing.T) {
+    type generator interface {
+        rand2.Source
+        MarshalBinary() ([]byte, error)
+        UnmarshalBinary([]byte) error
+    }
+
+    tests := []struct {
+        name string
+        a, b generator
+    }{
+        {"SplitMix64", rand2.NewSplitMix64(99), rand2.NewSplitMix64(0)},
+        {"Xoshiro256", rand2.NewXoshiro256(99), rand2.NewXoshiro256(0)},
+    }
+
+    for _, tt := range tests {
+        tt.a.Uint64()
+        data, err := tt.a.MarshalBinary()
+        if err != nil { t.Fatalf("%s: unexpected error: %v", tt.name, err) }
+        if err := tt.b.UnmarshalBinary(data); err != nil {
+            t.Fatalf("%s: unexpected error: %v", tt.name, err)
+        }
+        for i := 0; i < 10; i++ {
+            if x, y := tt.a.Uint64(), tt.b.Uint64(); x != y {
+                t.Errorf("%s: restored generator diverged at %d: %d != %d", tt.name, i, x, y)
+            }
+        }
+        if err := tt.b.UnmarshalBinary(data[1:]); err == nil {
+            t.Errorf("%s: expected error for truncated input", tt.name)
+        }
+    }
+}
+
+func TestSplit(t *testing.T) {
+    g := rand2.NewXoshiro256(42)
+    h := g.Split()
+
+    seen := make(map[uint64]bool)
+    for i := 0; i < 1000; i++ {
+        seen[g.Uint64()] = true
+    }
+    for i := 0; i < 1000; i++ {
+        if seen[h.Uint64()] {
+            t.Errorf("split generators overlap")
+            break
+        }
+    }
+
+    // splitting is deterministic
+    a, b := rand2.NewSplitMix64(7), rand2.NewSplitMix64(7)
+    if a.Split().Uint64() != b.Split().Uint64() {
+        t.Errorf("SplitMix64.Split is not deterministic")
+    }
+}
+
+func TestUint64N(t *testing.T) {
+    g := rand2.NewXoshiro256(1)
+    var counts [7]int
+    for i := 0; i < 7000; i++ {
+        x := rand2.IntN(g, 7)
+        if (x < 0) || (x >= 7) { t.Fatalf("IntN(7) out of range: %d", x) }
+        counts[x]++
+    }
+    for i, c := range counts {
+        if (c < 800) || (c > 1200) {
+            t.Errorf("IntN(7): value %d appeared %d times out of 7000", i, c)
+        }
+    }
+
+    for i := 0; i < 1000; i++ {
+        f := rand2.Float64(g)
+        if (f < 0) || (f >= 1) { t.Fatalf("Float64 out of range: %f", f) }
+    }
+}
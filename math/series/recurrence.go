@@ -0,0 +1,150 @@
+package series
+
+import (
+    "github.com/tawesoft/golib/v2/must"
+)
+
+// Recurrence represents a sequence defined by a homogeneous linear recurrence
+// relation with constant coefficients, of the form
+//
+//     a(n) = c[0] a(n-1) + c[1] a(n-2) + ... + c[k-1] a(n-k)
+//
+// for some order k, and some k initial terms a(0), a(1), ..., a(k-1).
+//
+// For example, the Fibonacci sequence has coefficients [1, 1] and initial
+// terms [0, 1].
+type Recurrence[N Number] struct {
+    coefficients, initial []N
+}
+
+    // NewRecurrence returns a new [Recurrence] with the given coefficients and
+    // initial terms. The order of the recurrence is the length of the
+    // coefficients slice, and there must be exactly as many initial terms as
+    // there are coefficients, otherwise this function panics.
+    //
+    // The inputs are copied, so may be safely modified afterwards.
+    func NewRecurrence[N Number](coefficients []N, initial []N) Recurrence[N] {
+        must.Truef(len(coefficients) > 0,
+            "series.NewRecurrence: coefficients must not be empty")
+        must.Truef(len(coefficients) == len(initial),
+            "series.NewRecurrence: got %d coefficients but %d initial terms",
+            len(coefficients), len(initial))
+
+        return Recurrence[N]{
+            coefficients: append([]N(nil), coefficients...),
+            initial:      append([]N(nil), initial...),
+        }
+    }
+
+    // NewFibonacci returns the [Recurrence] for the Fibonacci sequence
+    // 0, 1, 1, 2, 3, 5, 8, ...
+    func NewFibonacci[N Number]() Recurrence[N] {
+        return NewRecurrence([]N{1, 1}, []N{0, 1})
+    }
+
+    // NewLucas returns the [Recurrence] for the Lucas numbers
+    // 2, 1, 3, 4, 7, 11, 18, ...
+    func NewLucas[N Number]() Recurrence[N] {
+        return NewRecurrence([]N{1, 1}, []N{2, 1})
+    }
+
+    // NewPell returns the [Recurrence] for the Pell numbers
+    // 0, 1, 2, 5, 12, 29, 70, ...
+    func NewPell[N Number]() Recurrence[N] {
+        return NewRecurrence([]N{2, 1}, []N{0, 1})
+    }
+
+    // Order returns the order of the recurrence i.e. the number of previous
+    // terms that each term depends upon.
+    func (r Recurrence[N]) Order() int { return len(r.coefficients) }
+
+    // Coefficients returns a copy of the coefficients of the recurrence.
+    func (r Recurrence[N]) Coefficients() []N {
+        return append([]N(nil), r.coefficients...)
+    }
+
+    // Initial returns a copy of the initial terms of the recurrence.
+    func (r Recurrence[N]) Initial() []N {
+        return append([]N(nil), r.initial...)
+    }
+
+    // Term returns the nth term of the sequence r, starting at n = 0. For
+    // n < 0, returns zero.
+    //
+    // This is calculated by exponentiation by squaring of the companion matrix
+    // of the recurrence, and takes O(k^3 log n) time for a recurrence of
+    // order k.
+    //
+    // Note that this function is not robust in the event of integer overflow.
+    func (r Recurrence[N]) Term(n int) N {
+        k := len(r.coefficients)
+        if n < 0 { return 0 }
+        if n < k { return r.initial[n] }
+
+        // The state vector (a(n), a(n-1), ..., a(n-k+1)) is advanced by one
+        // term by multiplication with the companion matrix, which has the
+        // coefficients along its first row, and ones on its subdiagonal.
+        companion := newSquare[N](k)
+        copy(companion.row(0), r.coefficients)
+        for i := 1; i < k; i++ {
+            companion.set(i, i - 1, 1)
+        }
+
+        p := companion.pow(n - k + 1)
+
+        // first row of p, applied to the state (a(k-1), a(k-2), ..., a(0))
+        var result N
+        for j := 0; j < k; j++ {
+            result += p.get(0, j) * r.initial[k - 1 - j]
+        }
+        return result
+    }
+
+// square is a minimal dense square matrix used to advance a [Recurrence].
+type square[N Number] struct {
+    n int
+    values []N
+}
+
+func newSquare[N Number](n int) square[N] {
+    return square[N]{n: n, values: make([]N, n * n)}
+}
+
+func identity[N Number](n int) square[N] {
+    m := newSquare[N](n)
+    for i := 0; i < n; i++ {
+        m.set(i, i, 1)
+    }
+    return m
+}
+
+func (m square[N]) row(i int) []N        { return m.values[i * m.n:(i + 1) * m.n] }
+func (m square[N]) get(i, j int) N       { return m.values[(i * m.n) + j] }
+func (m square[N]) set(i, j int, v N)    { m.values[(i * m.n) + j] = v }
+
+func (m square[N]) mul(o square[N]) square[N] {
+    result := newSquare[N](m.n)
+    for i := 0; i < m.n; i++ {
+        for k := 0; k < m.n; k++ {
+            a := m.get(i, k)
+            if a == 0 { continue }
+            for j := 0; j < m.n; j++ {
+                result.values[(i * m.n) + j] += a * o.get(k, j)
+            }
+        }
+    }
+    return result
+}
+
+// pow returns m^e for e >= 0, by exponentiation by squaring.
+func (m square[N]) pow(e int) square[N] {
+    result := identity[N](m.n)
+    for e > 0 {
+        if e % 2 == 1 {
+            result = result.mul(m)
+        }
+        m = m.mul(m)
+        e = e / 2
+    }
+    return result
+}
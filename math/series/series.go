@@ -4,6 +4,7 @@ import (
     "math"
 
     "github.com/tawesoft/golib/v2/math/integer"
+    "github.com/tawesoft/golib/v2/operator"
     "golang.org/x/exp/constraints"
 )
 
@@ -11,12 +12,6 @@ type Number interface {
     constraints.Float | constraints.Integer
 }
 
-// abs returns the absolute value of x, even if x is unsigned.
-func abs[T Number](x T) T {
-    if x >= 0 { return x }
-    return -x
-}
-
 // Geometric represents the series a + ar + ar^2 + ar^3 + ... for some
 // coefficient a and some ratio r.
 type Geometric[N Number] struct {
@@ -61,6 +56,6 @@ type Geometric[N Number] struct {
     // Iff the second return value is false, then the series does not converge.
     func (g Geometric[N]) Limit() (N, bool) {
         if g.ratio == 0 { return g.coefficient, true }
-        if abs(g.ratio) >= 1 { return 0, false }
+        if operator.Abs(g.ratio) >= 1 { return 0, false }
         return g.coefficient / (1 - g.ratio), true
     }
@@ -161,3 +161,54 @@ func TestGeometric_float(t *testing.T) {
         }
     }
 }
+
+func TestRecurrence(t *testing.T) {
+    tests := []struct{
+        name string
+        r series.Recurrence[int64]
+        terms []int64
+    }{
+        {
+            name:  "Fibonacci",
+            r:     series.NewFibonacci[int64](),
+            terms: []int64{0, 1, 1, 2, 3, 5, 8, 13, 21, 34, 55, 89},
+        },
+        {
+            name:  "Lucas",
+            r:     series.NewLucas[int64](),
+            terms: []int64{2, 1, 3, 4, 7, 11, 18, 29, 47, 76, 123},
+        },
+        {
+            name:  "Pell",
+            r:     series.NewPell[int64](),
+            terms: []int64{0, 1, 2, 5, 12, 29, 70, 169, 408, 985},
+        },
+        {
+            // Tribonacci
+            name:  "Tribonacci",
+            r:     series.NewRecurrence([]int64{1, 1, 1}, []int64{0, 0, 1}),
+            terms: []int64{0, 0, 1, 1, 2, 4, 7, 13, 24, 44, 81, 149},
+        },
+        {
+            // a(n) = 2a(n-1)
+            name:  "PowersOfTwo",
+            r:     series.NewRecurrence([]int64{2}, []int64{1}),
+            terms: []int64{1, 2, 4, 8, 16, 32, 64},
+        },
+    }
+
+    for _, tt := range tests {
+        for i := 0; i < len(tt.terms); i++ {
+            actual := tt.r.Term(i)
+            if actual != tt.terms[i] {
+                t.Errorf("%s.Term(%d): got %d, want %d",
+                    tt.name, i, actual, tt.terms[i])
+            }
+        }
+    }
+
+    // large n
+    if got, want := series.NewFibonacci[int64]().Term(90), int64(2880067194370816120); got != want {
+        t.Errorf("Fibonacci.Term(90): got %d, want %d", got, want)
+    }
+}
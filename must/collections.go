@@ -0,0 +1,154 @@
+package must
+
+import (
+    "fmt"
+    "reflect"
+    "strings"
+)
+
+// Contains returns true if the slice xs contains at least one element equal
+// to x. Otherwise, panics with a [ContainsError].
+func Contains[T comparable](xs []T, x T) bool {
+    if sliceContains(xs, x) { return true }
+    panic(newContainsError("Contains", xs, x, nil))
+}
+
+// Containsf returns true if the slice xs contains at least one element equal
+// to x. Otherwise, panics with a [ContainsError].
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [ContainsError] before panicking.
+func Containsf[T comparable](xs []T, x T, format string, args ... any) bool {
+    if sliceContains(xs, x) { return true }
+    err := fmt.Errorf(format, args...)
+    panic(newContainsError("Contains", xs, x, err))
+}
+
+// ContainsString returns true if substr is within s. Otherwise, panics with a
+// [ContainsError].
+func ContainsString(s string, substr string) bool {
+    if strings.Contains(s, substr) { return true }
+    panic(newContainsError("ContainsString", s, substr, nil))
+}
+
+// ContainsStringf returns true if substr is within s. Otherwise, panics with
+// a [ContainsError].
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [ContainsError] before panicking.
+func ContainsStringf(s string, substr string, format string, args ... any) bool {
+    if strings.Contains(s, substr) { return true }
+    err := fmt.Errorf(format, args...)
+    panic(newContainsError("ContainsString", s, substr, err))
+}
+
+// ContainsKey returns true if the map collection m contains the given key.
+// Otherwise, panics with a [ContainsError].
+func ContainsKey[K comparable, V any](m map[K]V, key K) bool {
+    if _, ok := m[key]; ok { return true }
+    panic(newContainsError("ContainsKey", m, key, nil))
+}
+
+// ContainsKeyf returns true if the map collection m contains the given key.
+// Otherwise, panics with a [ContainsError].
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [ContainsError] before panicking.
+func ContainsKeyf[K comparable, V any](m map[K]V, key K, format string, args ... any) bool {
+    if _, ok := m[key]; ok { return true }
+    err := fmt.Errorf(format, args...)
+    panic(newContainsError("ContainsKey", m, key, err))
+}
+
+// Len returns true if v has exactly length n. Otherwise, panics with a
+// [LenError].
+//
+// The argument v may be any value that supports the builtin len function: a
+// slice, string, map collection, channel, array, or pointer to an array. A
+// nil slice, map collection, or channel has length zero. Any other type
+// always panics.
+func Len(v any, n int) bool {
+    if length, ok := lengthOf(v); ok && (length == n) { return true }
+    panic(newLenError(v, n, nil))
+}
+
+// Lenf returns true if v has exactly length n. Otherwise, panics with a
+// [LenError]. See [Len].
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [LenError] before panicking.
+func Lenf(v any, n int, format string, args ... any) bool {
+    if length, ok := lengthOf(v); ok && (length == n) { return true }
+    err := fmt.Errorf(format, args...)
+    panic(newLenError(v, n, err))
+}
+
+// ElementsMatch returns true if the slices a and b contain the same elements,
+// including the same number of any repeated elements, regardless of their
+// order. Otherwise, panics with an [ElementsMatchError].
+func ElementsMatch[T comparable](a []T, b []T) bool {
+    extraA, extraB := elementsDiff(a, b)
+    if (len(extraA) == 0) && (len(extraB) == 0) { return true }
+    panic(newElementsMatchError(extraA, extraB, nil))
+}
+
+// ElementsMatchf returns true if the slices a and b contain the same
+// elements, including the same number of any repeated elements, regardless
+// of their order. Otherwise, panics with an [ElementsMatchError].
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [ElementsMatchError] before panicking.
+func ElementsMatchf[T comparable](a []T, b []T, format string, args ... any) bool {
+    extraA, extraB := elementsDiff(a, b)
+    if (len(extraA) == 0) && (len(extraB) == 0) { return true }
+    err := fmt.Errorf(format, args...)
+    panic(newElementsMatchError(extraA, extraB, err))
+}
+
+func sliceContains[T comparable](xs []T, x T) bool {
+    for _, i := range xs {
+        if i == x { return true }
+    }
+    return false
+}
+
+func lengthOf(v any) (int, bool) {
+    rv := reflect.ValueOf(v)
+    switch rv.Kind() {
+        case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+            return rv.Len(), true
+        case reflect.Pointer:
+            if rv.Type().Elem().Kind() == reflect.Array {
+                return rv.Type().Elem().Len(), true
+            }
+    }
+    return 0, false
+}
+
+// elementsDiff returns the elements in a that are not matched by an element
+// in b, and the elements in b that are not matched by an element in a.
+func elementsDiff[T comparable](a []T, b []T) (extraA []T, extraB []T) {
+    counts := make(map[T]int)
+    for _, x := range b {
+        counts[x]++
+    }
+    for _, x := range a {
+        if counts[x] > 0 {
+            counts[x]--
+        } else {
+            extraA = append(extraA, x)
+        }
+    }
+    for _, x := range b {
+        if counts[x] > 0 {
+            counts[x]--
+            extraB = append(extraB, x)
+        }
+    }
+    return extraA, extraB
+}
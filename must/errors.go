@@ -3,6 +3,8 @@ package must
 import (
     "errors"
     "fmt"
+
+    "github.com/tawesoft/golib/v2/internal/errchain"
 )
 
 
@@ -110,8 +112,252 @@ func (e CompareError[T]) Unwrap() error {
 }
 
 
+// NilError is the type of error that may be returned by a nil check function
+// such as [Nil] or [NotNil], or a formatting variant of a nil check function
+// (one ending in "f").
+type NilError struct {
+    operation, valueType, value string
+    fmtErr error
+}
+
+func newNilError(operation string, v any, err error) NilError {
+    e := NilError{
+        operation: operation,
+        valueType: "nil",
+        value:     "nil",
+        fmtErr:    err,
+    }
+    if v != nil {
+        e.valueType = typeName(v)
+        if !isNil(v) {
+            e.value = fmt.Sprintf("%v", v)
+        }
+    }
+    return e
+}
+
+// Error implements the standard error interface.
+func (e NilError) Error() string {
+    if e.fmtErr == nil {
+        return fmt.Sprintf("must.NilError<%s>(%s(%s))",
+            e.operation, e.valueType, e.value)
+    } else {
+        return fmt.Sprintf("must.NilError<%s>(%s(%s)): %s",
+            e.operation, e.valueType, e.value, e.fmtErr)
+    }
+}
+
+// Unwrap (for use with [errors.Is], etc.) returns nil for an error returned
+// by a nil check function, or the formatted error message received by a
+// formatting variant of a nil check function (one ending in "f").
+func (e NilError) Unwrap() error {
+    return e.fmtErr // may be nil
+}
+
+
+// ContainsError is the type of error that may be returned by a containment
+// check such as [Contains], [ContainsString], or [ContainsKey], or a
+// formatting variant of a containment check (one ending in "f").
+type ContainsError struct {
+    operation, container, element string
+    fmtErr error
+}
+
+func newContainsError(operation string, container any, element any, err error) ContainsError {
+    fmtValue := func(x any) string {
+        if s, ok := x.(string); ok { return fmt.Sprintf("%q", s) }
+        return fmt.Sprintf("%v", x)
+    }
+    return ContainsError{
+        operation: operation,
+        container: fmtValue(container),
+        element:   fmtValue(element),
+        fmtErr:    err,
+    }
+}
+
+// Error implements the standard error interface.
+func (e ContainsError) Error() string {
+    if e.fmtErr == nil {
+        return fmt.Sprintf("must.ContainsError<%s>(%s, %s)",
+            e.operation, e.container, e.element)
+    } else {
+        return fmt.Sprintf("must.ContainsError<%s>(%s, %s): %s",
+            e.operation, e.container, e.element, e.fmtErr)
+    }
+}
+
+// Unwrap (for use with [errors.Is], etc.) returns nil for an error returned
+// by a containment check, or the formatted error message received by a
+// formatting variant of a containment check (one ending in "f").
+func (e ContainsError) Unwrap() error {
+    return e.fmtErr // may be nil
+}
+
+
+// LenError is the type of error that may be returned by [Len] or [Lenf].
+type LenError struct {
+    valueType string
+    expected, actual int
+    hasLength bool
+    fmtErr error
+}
+
+func newLenError(v any, expected int, err error) LenError {
+    actual, ok := lengthOf(v)
+    return LenError{
+        valueType: typeName(v),
+        expected:  expected,
+        actual:    actual,
+        hasLength: ok,
+        fmtErr:    err,
+    }
+}
+
+// Error implements the standard error interface.
+func (e LenError) Error() string {
+    var msg string
+    if e.hasLength {
+        msg = fmt.Sprintf("must.LenError[%s]: got length %d, want %d",
+            e.valueType, e.actual, e.expected)
+    } else {
+        msg = fmt.Sprintf("must.LenError[%s]: type has no length", e.valueType)
+    }
+    if e.fmtErr == nil { return msg }
+    return fmt.Sprintf("%s: %s", msg, e.fmtErr)
+}
+
+// Unwrap (for use with [errors.Is], etc.) returns nil for an error returned
+// by [Len], or the formatted error message received by [Lenf].
+func (e LenError) Unwrap() error {
+    return e.fmtErr // may be nil
+}
+
+
+// ElementsMatchError is the type of error that may be returned by
+// [ElementsMatch] or [ElementsMatchf].
+type ElementsMatchError struct {
+    extraA, extraB string
+    fmtErr error
+}
+
+func newElementsMatchError[T comparable](extraA []T, extraB []T, err error) ElementsMatchError {
+    return ElementsMatchError{
+        extraA: fmt.Sprintf("%v", extraA),
+        extraB: fmt.Sprintf("%v", extraB),
+        fmtErr: err,
+    }
+}
+
+// Error implements the standard error interface.
+func (e ElementsMatchError) Error() string {
+    msg := fmt.Sprintf("must.ElementsMatchError: extra elements in a: %s, extra elements in b: %s",
+        e.extraA, e.extraB)
+    if e.fmtErr == nil { return msg }
+    return fmt.Sprintf("%s: %s", msg, e.fmtErr)
+}
+
+// Unwrap (for use with [errors.Is], etc.) returns nil for an error returned
+// by [ElementsMatch], or the formatted error message received by
+// [ElementsMatchf].
+func (e ElementsMatchError) Unwrap() error {
+    return e.fmtErr // may be nil
+}
+
+
+// PanicError is the type of error that may be returned by [Panics],
+// [PanicsWith], or a formatting variant of these (one ending in "f"), when
+// a function did not panic in the expected way.
+type PanicError struct {
+    operation string
+    recovered any
+    panicked bool
+    target string
+    fmtErr error
+}
+
+func newPanicError(operation string, r any, panicked bool, target any, err error) PanicError {
+    return PanicError{
+        operation: operation,
+        recovered: r,
+        panicked:  panicked,
+        target:    fmt.Sprintf("%v", target),
+        fmtErr:    err,
+    }
+}
+
+// Recovered returns the value recovered from the panic raised by the function
+// under test, or nil if the function did not panic.
+func (e PanicError) Recovered() any {
+    return e.recovered
+}
+
+// Error implements the standard error interface.
+func (e PanicError) Error() string {
+    var msg string
+    if e.panicked {
+        msg = fmt.Sprintf("must.PanicError<%s>(%s): got panic %v (%T)",
+            e.operation, e.target, e.recovered, e.recovered)
+    } else {
+        msg = fmt.Sprintf("must.PanicError<%s>(%s): function did not panic",
+            e.operation, e.target)
+    }
+    if e.fmtErr == nil { return msg }
+    return fmt.Sprintf("%s: %s", msg, e.fmtErr)
+}
+
+// Unwrap (for use with [errors.Is], etc.) returns nil for an error returned
+// by [Panics] or [PanicsWith], or the formatted error message received by a
+// formatting variant of these (one ending in "f").
+func (e PanicError) Unwrap() error {
+    return e.fmtErr // may be nil
+}
+
+
+// MatchError is the type of error that may be returned by [ErrorIs],
+// [ErrorAs], or a formatting variant of these (one ending in "f"), when an
+// error does not match the expected target.
+type MatchError struct {
+    operation, target, chain string
+    err, fmtErr error
+}
+
+func newMatchError(operation string, err error, target string, fmtErr error) MatchError {
+    return MatchError{
+        operation: operation,
+        target:    target,
+        chain:     errchain.String(err, true),
+        err:       err,
+        fmtErr:    fmtErr,
+    }
+}
+
+// Err returns the original error that did not match the target.
+func (e MatchError) Err() error {
+    return e.err
+}
+
+// Error implements the standard error interface.
+func (e MatchError) Error() string {
+    msg := fmt.Sprintf("must.MatchError<%s>(%s): error chain %s does not match",
+        e.operation, e.target, e.chain)
+    if e.fmtErr == nil { return msg }
+    return fmt.Sprintf("%s: %s", msg, e.fmtErr)
+}
+
+// Unwrap (for use with [errors.Is], etc.) returns nil for an error returned
+// by [ErrorIs] or [ErrorAs], or the formatted error message received by a
+// formatting variant of these (one ending in "f").
+//
+// Note that this does not unwrap to the original error that did not match.
+// Use [MatchError.Err] for that.
+func (e MatchError) Unwrap() error {
+    return e.fmtErr // may be nil
+}
+
+
 // CheckError is the type of error that may be returned by a check function
-// such as [Check], [CheckAll], and [Checkf].
+// such as [Check], [CheckAll], [Checkf], and [CloseAll].
 type CheckError struct {
     fmtErr, err error
 }
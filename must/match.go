@@ -0,0 +1,52 @@
+package must
+
+import (
+    "errors"
+    "fmt"
+)
+
+// ErrorIs returns true if [errors.Is](err, target) is true. Otherwise, panics
+// with a [MatchError] that describes every error in the chain of err.
+func ErrorIs(err error, target error) bool {
+    if errors.Is(err, target) { return true }
+    panic(newMatchError("ErrorIs", err, fmt.Sprint(target), nil))
+}
+
+// ErrorIsf returns true if [errors.Is](err, target) is true. Otherwise,
+// panics with a [MatchError] that describes every error in the chain of err.
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [MatchError] before panicking.
+func ErrorIsf(err error, target error, format string, args ... any) bool {
+    if errors.Is(err, target) { return true }
+    fmtErr := fmt.Errorf(format, args...)
+    panic(newMatchError("ErrorIs", err, fmt.Sprint(target), fmtErr))
+}
+
+// ErrorAs returns the first error in the chain of err that matches type T
+// (see [errors.As]). Otherwise, panics with a [MatchError] that describes
+// every error in the chain of err.
+//
+// For example,
+//
+//     syntaxErr := must.ErrorAs[dimensions.SamplerSyntaxError](err)
+func ErrorAs[T error](err error) T {
+    var target T
+    if errors.As(err, &target) { return target }
+    panic(newMatchError("ErrorAs", err, typeName(target), nil))
+}
+
+// ErrorAsf returns the first error in the chain of err that matches type T
+// (see [errors.As]). Otherwise, panics with a [MatchError] that describes
+// every error in the chain of err.
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [MatchError] before panicking.
+func ErrorAsf[T error](err error, format string, args ... any) T {
+    var target T
+    if errors.As(err, &target) { return target }
+    fmtErr := fmt.Errorf(format, args...)
+    panic(newMatchError("ErrorAs", err, typeName(target), fmtErr))
+}
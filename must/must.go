@@ -2,7 +2,10 @@
 package must
 
 import (
+    "errors"
     "fmt"
+    "io"
+    "reflect"
 )
 
 func typeName(x any) string {
@@ -126,6 +129,22 @@ func CheckAll(errs ... error) {
     }
 }
 
+// CloseAll calls the Close method of every closer, in reverse order (i.e. in
+// the same order as separate defer statements would), even if an earlier
+// call returns an error. If any call returns a non-nil error, the errors are
+// joined (see [errors.Join]) and wrapped in a [CheckError] before panicking.
+//
+// Nil closers are skipped, including typed nil values such as a nil
+// *os.File (see [Nil]).
+func CloseAll(closers ... io.Closer) {
+    var errs []error
+    for i := len(closers) - 1; i >= 0; i-- {
+        if isNil(closers[i]) { continue }
+        errs = append(errs, closers[i].Close())
+    }
+    Check(errors.Join(errs...))
+}
+
 // Try takes a function f() => x that may panic, and instead returns a
 // function f() => (x, error).
 //
@@ -174,36 +193,58 @@ func Neverf(format string, args ... any) {
     panic(NeverError{fmt.Errorf(format, args...)})
 }
 
-// Nil returns true if v is (untyped) nil. Otherwise, panics.
-func Nil(v any) bool {
+// isNil returns true if v is untyped nil, or if v is an interface holding a
+// nil value of a pointer, map, slice, channel, or function type.
+func isNil(v any) bool {
     if v == nil { return true }
-    panic(newCompareError[any]("Nil", v, nil, nil))
+    rv := reflect.ValueOf(v)
+    switch rv.Kind() {
+        case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+            reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+            return rv.IsNil()
+        default:
+            return false
+    }
+}
+
+// Nil returns true if v is nil. Otherwise, panics with a [NilError].
+//
+// Unlike a simple comparison with nil, this correctly handles typed nil
+// values stored in an interface. For example, a nil pointer of type *T is
+// considered nil.
+func Nil(v any) bool {
+    if isNil(v) { return true }
+    panic(newNilError("Nil", v, nil))
 }
 
-// Nilf returns true if v is (untyped) nil. Otherwise, panics.
+// Nilf returns true if v is nil. Otherwise, panics with a [NilError].
 //
 // The [fmt.Sprintf] -style format string and with optional arguments are used
 // to format the error message. The formatted error message is wrapped in
-// [CompareError] before panicking.
+// [NilError] before panicking.
 func Nilf(v any, format string, args ... any) bool {
-    if v == nil { return true }
+    if isNil(v) { return true }
     err := fmt.Errorf(format, args...)
-    panic(newCompareError[any]("Nil", v, nil, err))
+    panic(newNilError("Nil", v, err))
 }
 
-// NotNil returns true if v is not (untyped) nil. Otherwise, panics.
+// NotNil returns true if v is not nil. Otherwise, panics with a [NilError].
+//
+// Unlike a simple comparison with nil, this correctly handles typed nil
+// values stored in an interface. For example, a nil pointer of type *T is
+// considered nil.
 func NotNil(v any) bool {
-    if v != nil { return true }
-    panic(newCompareError[any]("NotNil", v, nil, nil))
+    if !isNil(v) { return true }
+    panic(newNilError("NotNil", v, nil))
 }
 
-// NotNilf returns true if v is not (untyped) nil. Otherwise, panics.
+// NotNilf returns true if v is not nil. Otherwise, panics with a [NilError].
 //
 // The [fmt.Sprintf] -style format string and with optional arguments are used
 // to format the error message. The formatted error message is wrapped in
-// [CompareError] before panicking.
+// [NilError] before panicking.
 func NotNilf(v any, format string, args ... any) bool {
-    if v != nil { return true }
+    if !isNil(v) { return true }
     err := fmt.Errorf(format, args...)
-    panic(newCompareError[any]("NotNil", v, nil, err))
+    panic(newNilError("NotNil", v, err))
 }
@@ -70,3 +70,166 @@ func TestMustFunc(t *testing.T) {
     f := must.Func(successfulFunction)
     assert.Equal(t, "123", f())
 }
+
+func TestNil(t *testing.T) {
+    var nilPtr *int
+    var nilMap map[string]int
+    var nilSlice []int
+    var nilFunc func()
+    var nilErr error
+    var typedNilErr error = (*os.PathError)(nil)
+    x := 5
+
+    nils := []any{nil, nilPtr, nilMap, nilSlice, nilFunc, nilErr, typedNilErr}
+    notNils := []any{&x, 0, "", []int{}, map[string]int{}, struct{}{}}
+
+    for _, v := range nils {
+        assert.NotPanics(t, func() { must.Nil(v) }, "must.Nil(%T)", v)
+        assert.Panics(t, func() { must.NotNil(v) }, "must.NotNil(%T)", v)
+    }
+
+    for _, v := range notNils {
+        assert.Panics(t, func() { must.Nil(v) }, "must.Nil(%T)", v)
+        assert.NotPanics(t, func() { must.NotNil(v) }, "must.NotNil(%T)", v)
+    }
+
+    _, err := must.Try(func() bool { return must.NotNilf(nilPtr, "pointer %s", "foo") })()
+    var nilError must.NilError
+    assert.True(t, errors.As(err, &nilError))
+    assert.Equal(t, "must.NilError<NotNil>(*int(nil)): pointer foo", err.Error())
+
+    _, err = must.Try(func() bool { return must.Nil(&x) })()
+    assert.True(t, errors.As(err, &nilError))
+    assert.Contains(t, err.Error(), "must.NilError<Nil>(*int(0x")
+}
+
+func TestCollections(t *testing.T) {
+    assert.NotPanics(t, func() { must.Contains([]int{1, 2, 3}, 2) })
+    assert.Panics(t, func() { must.Contains([]int{1, 2, 3}, 4) })
+    assert.NotPanics(t, func() { must.ContainsString("hello world", "o w") })
+    assert.Panics(t, func() { must.ContainsString("hello world", "ow") })
+    assert.NotPanics(t, func() { must.ContainsKey(map[string]int{"a": 0}, "a") })
+    assert.Panics(t, func() { must.ContainsKey(map[string]int{"a": 0}, "b") })
+
+    var nilSlice []int
+    assert.NotPanics(t, func() { must.Len([]int{1, 2, 3}, 3) })
+    assert.NotPanics(t, func() { must.Len("abc", 3) })
+    assert.NotPanics(t, func() { must.Len(map[int]int{1: 1}, 1) })
+    assert.NotPanics(t, func() { must.Len(&[4]int{}, 4) })
+    assert.NotPanics(t, func() { must.Len(nilSlice, 0) })
+    assert.Panics(t, func() { must.Len([]int{1, 2, 3}, 2) })
+    assert.Panics(t, func() { must.Len(5, 0) })
+
+    assert.NotPanics(t, func() { must.ElementsMatch([]int{1, 2, 2, 3}, []int{2, 3, 1, 2}) })
+    assert.NotPanics(t, func() { must.ElementsMatch([]int{}, nilSlice) })
+    assert.Panics(t, func() { must.ElementsMatch([]int{1, 2, 2}, []int{1, 2, 3}) })
+
+    var err error
+    _, err = must.Try(func() bool { return must.Containsf([]int{1, 2}, 3, "in %s", "list") })()
+    assert.True(t, errors.As(err, &must.ContainsError{}))
+    assert.Equal(t, "must.ContainsError<Contains>([1 2], 3): in list", err.Error())
+
+    _, err = must.Try(func() bool { return must.Len([]string{"a"}, 2) })()
+    assert.True(t, errors.As(err, &must.LenError{}))
+    assert.Equal(t, "must.LenError[[]string]: got length 1, want 2", err.Error())
+
+    _, err = must.Try(func() bool { return must.ElementsMatch([]int{1, 2, 2}, []int{1, 3, 2}) })()
+    assert.True(t, errors.As(err, &must.ElementsMatchError{}))
+    assert.Equal(t, "must.ElementsMatchError: extra elements in a: [2], extra elements in b: [3]", err.Error())
+}
+
+func TestPanics(t *testing.T) {
+    errTarget := errors.New("target")
+    errOther := errors.New("other")
+
+    assert.NotPanics(t, func() { must.Panics(func() { panic(errTarget) }, errTarget) })
+    assert.NotPanics(t, func() { must.Panics(func() { panic(fmt.Errorf("wrapped: %w", errTarget)) }, errTarget) })
+    assert.NotPanics(t, func() { must.Panics(func() { panic(errOther) }, nil) })
+    assert.Panics(t, func() { must.Panics(func() { panic(errOther) }, errTarget) })
+    assert.Panics(t, func() { must.Panics(func() { panic("not an error") }, nil) })
+    assert.Panics(t, func() { must.Panics(func() {}, nil) })
+
+    assert.NotPanics(t, func() { must.PanicsWith(func() { panic("message") }, "message") })
+    assert.NotPanics(t, func() { must.PanicsWith(func() { panic([]int{1, 2}) }, []int{1, 2}) })
+    assert.Panics(t, func() { must.PanicsWith(func() { panic("message") }, "other") })
+    assert.Panics(t, func() { must.PanicsWith(func() {}, "message") })
+
+    var err error
+    _, err = must.Try(func() bool { return must.Panicsf(func() {}, errTarget, "in %s", "test") })()
+    assert.True(t, errors.As(err, &must.PanicError{}))
+    assert.Equal(t, "must.PanicError<Panics>(target): function did not panic: in test", err.Error())
+
+    _, err = must.Try(func() bool { return must.PanicsWith(func() { panic(1) }, 2) })()
+    var panicErr must.PanicError
+    assert.True(t, errors.As(err, &panicErr))
+    assert.Equal(t, 1, panicErr.Recovered())
+    assert.Equal(t, "must.PanicError<PanicsWith>(2): got panic 1 (int)", err.Error())
+}
+
+type testCloser struct {
+    closed *[]string
+    name string
+    err error
+}
+
+func (c testCloser) Close() error {
+    *c.closed = append(*c.closed, c.name)
+    return c.err
+}
+
+func TestCloseAll(t *testing.T) {
+    errA := errors.New("a")
+    errC := errors.New("c")
+    var closed []string
+
+    assert.NotPanics(t, func() {
+        must.CloseAll(
+            testCloser{&closed, "a", nil},
+            nil,
+            (*testCloser)(nil),
+            testCloser{&closed, "b", nil},
+        )
+    })
+    assert.Equal(t, []string{"b", "a"}, closed)
+
+    closed = nil
+    _, err := must.Try(func() bool {
+        must.CloseAll(
+            testCloser{&closed, "a", errA},
+            testCloser{&closed, "b", nil},
+            testCloser{&closed, "c", errC},
+        )
+        return true
+    })()
+    assert.Equal(t, []string{"c", "b", "a"}, closed)
+    assert.True(t, errors.As(err, &must.CheckError{}))
+    assert.ErrorIs(t, err, errA)
+    assert.ErrorIs(t, err, errC)
+}
+
+type testMatchError struct{ code int }
+
+func (e testMatchError) Error() string { return fmt.Sprintf("code %d", e.code) }
+
+func TestErrorMatch(t *testing.T) {
+    errTarget := errors.New("target")
+    wrapped := fmt.Errorf("wrapped: %w", errors.Join(os.ErrNotExist, testMatchError{5}))
+
+    assert.NotPanics(t, func() { must.ErrorIs(fmt.Errorf("wrapped: %w", errTarget), errTarget) })
+    assert.NotPanics(t, func() { must.ErrorIs(wrapped, os.ErrNotExist) })
+    assert.Equal(t, testMatchError{5}, must.ErrorAs[testMatchError](wrapped))
+
+    _, err := must.Try(func() bool { return must.ErrorIs(wrapped, errTarget) })()
+    var matchErr must.MatchError
+    assert.True(t, errors.As(err, &matchErr))
+    assert.Equal(t, wrapped, matchErr.Err())
+    assert.Equal(t, "must.MatchError<ErrorIs>(target): error chain ["+
+        `"wrapped: file does not exist\ncode 5" (*fmt.wrapError) -> `+
+        `"file does not exist\ncode 5" (*errors.joinError) -> `+
+        `"file does not exist" (*errors.errorString) -> `+
+        `"code 5" (must_test.testMatchError)] does not match`, err.Error())
+
+    _, err = must.Try(func() *os.PathError { return must.ErrorAsf[*os.PathError](errTarget, "in %s", "test") })()
+    assert.True(t, errors.As(err, &matchErr))
+    assert.Equal(t, `must.MatchError<ErrorAs>(*fs.PathError): error chain ["target" (*errors.errorString)] does not match: in test`, err.Error())
+}
@@ -0,0 +1,81 @@
+package must
+
+import (
+    "errors"
+    "fmt"
+    "reflect"
+)
+
+// recovered calls f, and returns the value recovered from any panic raised
+// by f, and true if f panicked.
+func recovered(f func()) (r any, panicked bool) {
+    defer func() {
+        if panicked {
+            r = recover()
+        }
+    }()
+    panicked = true
+    f()
+    panicked = false
+    return nil, false
+}
+
+// panicsWithError returns true if r is an error and either target is nil or
+// errors.Is(r, target) is true.
+func panicsWithError(r any, target error) bool {
+    err, ok := r.(error)
+    if !ok { return false }
+    return (target == nil) || errors.Is(err, target)
+}
+
+// Panics returns true if calling f panics with an error, and either target
+// is nil or [errors.Is](err, target) is true. Otherwise, panics with a
+// [PanicError].
+//
+// Unlike recovering a panic manually, this may be used outside of tests, for
+// example to check invariants in downstream packages.
+func Panics(f func(), target error) bool {
+    r, panicked := recovered(f)
+    if panicked && panicsWithError(r, target) { return true }
+    panic(newPanicError("Panics", r, panicked, target, nil))
+}
+
+// Panicsf returns true if calling f panics with an error, and either target
+// is nil or [errors.Is](err, target) is true. Otherwise, panics with a
+// [PanicError].
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [PanicError] before panicking.
+func Panicsf(f func(), target error, format string, args ... any) bool {
+    r, panicked := recovered(f)
+    if panicked && panicsWithError(r, target) { return true }
+    err := fmt.Errorf(format, args...)
+    panic(newPanicError("Panics", r, panicked, target, err))
+}
+
+// PanicsWith returns true if calling f panics with a value that is deeply
+// equal to value (see [reflect.DeepEqual]). Otherwise, panics with a
+// [PanicError].
+//
+// This is useful where f panics with a value that is not an error, such as a
+// string.
+func PanicsWith(f func(), value any) bool {
+    r, panicked := recovered(f)
+    if panicked && reflect.DeepEqual(r, value) { return true }
+    panic(newPanicError("PanicsWith", r, panicked, value, nil))
+}
+
+// PanicsWithf returns true if calling f panics with a value that is deeply
+// equal to value (see [reflect.DeepEqual]). Otherwise, panics with a
+// [PanicError].
+//
+// The [fmt.Sprintf] -style format string and with optional arguments are used
+// to format the error message. The formatted error message is wrapped in
+// [PanicError] before panicking.
+func PanicsWithf(f func(), value any, format string, args ... any) bool {
+    r, panicked := recovered(f)
+    if panicked && reflect.DeepEqual(r, value) { return true }
+    err := fmt.Errorf(format, args...)
+    panic(newPanicError("PanicsWith", r, panicked, value, err))
+}
@@ -0,0 +1,81 @@
+package checked
+
+// Sum returns (x[0] + x[1] + ... + x[n-1], true) iff every x, and each
+// successive partial sum, all lie between min and max inclusive. Otherwise,
+// returns (0, false). This calculation is robust in the event of integer
+// overflow.
+//
+// The sum of no values is zero, so this returns (0, true) for an empty input
+// iff zero lies between min and max inclusive.
+//
+// Note that because each partial sum is checked, the order of the inputs can
+// matter. For example, with the limits of an int8, the sum of (100, 100, -100)
+// fails but the sum of (100, -100, 100) succeeds.
+func Sum[N Number](min N, max N, xs ... N) (N, bool) {
+    if len(xs) == 0 { return identity[N](min, max, 0) }
+    total := xs[0]
+    if (total < min) || (total > max) { return 0, false }
+    for _, x := range xs[1:] {
+        var ok bool
+        total, ok = Add(min, max, total, x)
+        if !ok { return 0, false }
+    }
+    return total, true
+}
+
+// SumIter is like [Sum], but consumes values from an iterator function (for
+// example, an iter.It) until it is exhausted, or until the sum fails.
+func SumIter[N Number](min N, max N, it func() (N, bool)) (N, bool) {
+    total, ok := it()
+    if !ok { return identity[N](min, max, 0) }
+    if (total < min) || (total > max) { return 0, false }
+    for {
+        x, ok := it()
+        if !ok { break }
+        total, ok = Add(min, max, total, x)
+        if !ok { return 0, false }
+    }
+    return total, true
+}
+
+// Product returns (x[0] * x[1] * ... * x[n-1], true) iff every x, and each
+// successive partial product, all lie between min and max inclusive.
+// Otherwise, returns (0, false). This calculation is robust in the event of
+// integer overflow.
+//
+// The product of no values is one, so this returns (1, true) for an empty
+// input iff one lies between min and max inclusive.
+func Product[N Number](min N, max N, xs ... N) (N, bool) {
+    if len(xs) == 0 { return identity[N](min, max, 1) }
+    total := xs[0]
+    if (total < min) || (total > max) { return 0, false }
+    for _, x := range xs[1:] {
+        var ok bool
+        total, ok = Mul(min, max, total, x)
+        if !ok { return 0, false }
+    }
+    return total, true
+}
+
+// ProductIter is like [Product], but consumes values from an iterator
+// function (for example, an iter.It) until it is exhausted, or until the
+// product fails.
+func ProductIter[N Number](min N, max N, it func() (N, bool)) (N, bool) {
+    total, ok := it()
+    if !ok { return identity[N](min, max, 1) }
+    if (total < min) || (total > max) { return 0, false }
+    for {
+        x, ok := it()
+        if !ok { break }
+        total, ok = Mul(min, max, total, x)
+        if !ok { return 0, false }
+    }
+    return total, true
+}
+
+// identity returns (x, true) iff x lies between min and max inclusive.
+// Otherwise, returns (0, false).
+func identity[N Number](min N, max N, x N) (N, bool) {
+    if (min > max) || (x < min) || (x > max) { return 0, false }
+    return x, true
+}
@@ -99,3 +99,49 @@ func (l Limits[I]) Abs(i I) (I, bool) {
 func (l Limits[I]) Inv(i I) (I, bool) {
     return Inv(l.Min, l.Max, i)
 }
+
+// Neg returns (-i, true) iff both i and the result lie between the Limit min
+// and max inclusive. Otherwise, returns (0, false).
+func (l Limits[I]) Neg(i I) (I, bool) {
+    return Neg(l.Min, l.Max, i)
+}
+
+// Div returns (a / b, true) iff a, b, and the result all lie between the
+// Limit min and max inclusive, and b is not zero, otherwise returns (0,
+// false). This calculation is robust in the event of integer overflow.
+func (l Limits[I]) Div(a I, b I) (I, bool) {
+    return Div(l.Min, l.Max, a, b)
+}
+
+// Mod returns (a mod b, true) iff a, b, and the result all lie between the
+// Limit min and max inclusive, and b is not zero, otherwise returns (0,
+// false). This calculation is robust in the event of integer overflow.
+func (l Limits[I]) Mod(a I, b I) (I, bool) {
+    return Mod(l.Min, l.Max, a, b)
+}
+
+// Sum returns (x[0] + x[1] + ... + x[n-1], true) iff every x, and each
+// successive partial sum, all lie between the Limit min and max inclusive.
+// Otherwise, returns (0, false). See [Sum].
+func (l Limits[I]) Sum(xs ... I) (I, bool) {
+    return Sum(l.Min, l.Max, xs...)
+}
+
+// SumIter is like [Limits.Sum], but consumes values from an iterator
+// function. See [SumIter].
+func (l Limits[I]) SumIter(it func() (I, bool)) (I, bool) {
+    return SumIter(l.Min, l.Max, it)
+}
+
+// Product returns (x[0] * x[1] * ... * x[n-1], true) iff every x, and each
+// successive partial product, all lie between the Limit min and max
+// inclusive. Otherwise, returns (0, false). See [Product].
+func (l Limits[I]) Product(xs ... I) (I, bool) {
+    return Product(l.Min, l.Max, xs...)
+}
+
+// ProductIter is like [Limits.Product], but consumes values from an iterator
+// function. See [ProductIter].
+func (l Limits[I]) ProductIter(it func() (I, bool)) (I, bool) {
+    return ProductIter(l.Min, l.Max, it)
+}
@@ -4,6 +4,10 @@
 // Note: Float variants are not fully tested yet.
 package checked
 
+import (
+    "math"
+)
+
 // Add returns (a + b, true) iff a, b, and the result all lie between min and
 // max inclusive, otherwise returns (0, false). This calculation is robust in
 // the event of integer overflow.
@@ -58,3 +62,65 @@ func Inv[N Number](min N, max N, i N) (N, bool) {
     if (i > 0) && (0 < (min + i)) { return 0, false }
     return -i, true
 }
+
+// Neg returns (-i, true) iff both i and the result lie between min and max
+// inclusive. Otherwise, returns (0, false).
+//
+// Neg is identical to [Inv], and is provided so that the checked operations
+// are named the same as the operations they guard.
+func Neg[N Number](min N, max N, i N) (N, bool) {
+    return Inv(min, max, i)
+}
+
+// Div returns (a / b, true) iff a, b, and the result all lie between min and
+// max inclusive, and b is not zero, otherwise returns (0, false). This
+// calculation is robust in the event of integer overflow, including the
+// special case of dividing the most negative integer by -1.
+//
+// For integers, the result is truncated towards zero, as with the Go "/"
+// operator.
+func Div[N Number](min N, max N, a N, b N) (N, bool) {
+    if (a < min) || (a > max) || (b < min) || (b > max) { return 0, false }
+    if (min > max) || (max < min) { return 0, false }
+    if b == 0 { return 0, false }
+
+    // for signed integers, a / -1 is the only division that can overflow.
+    if (b < 0) && (b + 1 == 0) { return Inv(min, max, a) }
+
+    x := a / b
+    if (x < min) || (x > max) { return 0, false }
+    return x, true
+}
+
+// Mod returns (a mod b, true) iff a, b, and the result all lie between min and
+// max inclusive, and b is not zero, otherwise returns (0, false). This
+// calculation is robust in the event of integer overflow.
+//
+// The result has the same sign as a, as with the Go "%" operator for integers
+// and [math.Mod] for floats.
+func Mod[N Number](min N, max N, a N, b N) (N, bool) {
+    if (a < min) || (a > max) || (b < min) || (b > max) { return 0, false }
+    if (min > max) || (max < min) { return 0, false }
+    if b == 0 { return 0, false }
+
+    var x N
+    if isFloat[N]() {
+        x = N(math.Mod(float64(a), float64(b)))
+    } else if (b < 0) && (b + 1 == 0) {
+        // a % -1 is always zero, but a / -1 (below) can overflow.
+        x = 0
+    } else {
+        // equivalent to a % b for integers
+        x = a - ((a / b) * b)
+    }
+
+    if (x < min) || (x > max) { return 0, false }
+    return x, true
+}
+
+// isFloat returns true iff N is a floating point type.
+func isFloat[N Number]() bool {
+    var half N = 1
+    half /= 2
+    return half != 0
+}
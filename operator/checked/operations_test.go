@@ -5,6 +5,7 @@ import (
     "testing"
 
     "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/iter"
     "github.com/tawesoft/golib/v2/operator/checked"
     "github.com/tawesoft/golib/v2/tuple"
 )
@@ -27,6 +28,110 @@ func TestMul(t *testing.T) {
     assert.False(t, tuple.ToT2(checked.Float64.Mul(math.MaxFloat64, 2.0)).B)
 }
 
+func TestDiv(t *testing.T) {
+    assert.Equal(t, tuple.ToT2(3,       true),  tuple.ToT2(checked.Int.Div(10, 3)))
+    assert.Equal(t, tuple.ToT2(-3,      true),  tuple.ToT2(checked.Int.Div(-10, 3)))
+    assert.Equal(t, tuple.ToT2(0,       false), tuple.ToT2(checked.Int.Div(10, 0)))
+    assert.Equal(t, tuple.ToT2(int8(0), false), tuple.ToT2(checked.Int8.Div(-128, -1)))
+    assert.Equal(t, tuple.ToT2(int8(127), true), tuple.ToT2(checked.Int8.Div(-127, -1)))
+    assert.Equal(t, tuple.ToT2(uint8(0), false), tuple.ToT2(checked.Uint8.Div(255, 0)))
+    assert.Equal(t, tuple.ToT2(uint8(1), true), tuple.ToT2(checked.Uint8.Div(255, 255)))
+    assert.Equal(t, tuple.ToT2(0.5,     true),  tuple.ToT2(checked.Float64.Div(1.0, 2.0)))
+    assert.False(t, tuple.ToT2(checked.Float64.Div(math.MaxFloat64, 0.5)).B)
+    assert.False(t, tuple.ToT2(checked.Limits[int]{Min: 5, Max: 99}.Div(10, 5)).B)
+}
+
+func TestMod(t *testing.T) {
+    assert.Equal(t, tuple.ToT2(1,       true),  tuple.ToT2(checked.Int.Mod(10, 3)))
+    assert.Equal(t, tuple.ToT2(-1,      true),  tuple.ToT2(checked.Int.Mod(-10, 3)))
+    assert.Equal(t, tuple.ToT2(1,       true),  tuple.ToT2(checked.Int.Mod(10, -3)))
+    assert.Equal(t, tuple.ToT2(0,       false), tuple.ToT2(checked.Int.Mod(10, 0)))
+    assert.Equal(t, tuple.ToT2(int8(0), true),  tuple.ToT2(checked.Int8.Mod(-128, -1)))
+    assert.Equal(t, tuple.ToT2(uint8(5), true), tuple.ToT2(checked.Uint8.Mod(255, 10)))
+    assert.Equal(t, tuple.ToT2(1.5,     true),  tuple.ToT2(checked.Float64.Mod(5.5, 2.0)))
+    assert.False(t, tuple.ToT2(checked.Limits[int]{Min: 5, Max: 99}.Mod(10, 5)).B)
+}
+
+func TestNeg(t *testing.T) {
+    assert.Equal(t, tuple.ToT2(-7,       true),  tuple.ToT2(checked.Int.Neg(7)))
+    assert.Equal(t, tuple.ToT2(int8(0),  false), tuple.ToT2(checked.Int8.Neg(-128)))
+    assert.Equal(t, tuple.ToT2(uint8(0), true),  tuple.ToT2(checked.Uint8.Neg(0)))
+    assert.Equal(t, tuple.ToT2(uint8(0), false), tuple.ToT2(checked.Uint8.Neg(1)))
+}
+
+func FuzzDiv_Int32(f *testing.F) {
+    type row struct {
+        a int32
+        b int32
+        min int32
+        max int32
+    }
+
+    rows := []row{
+        {-1, -1, math.MinInt32, math.MaxInt32},
+        {0, 1, math.MinInt32, math.MaxInt32},
+        {1, 0, math.MinInt32, math.MaxInt32},
+        {math.MinInt32, -1, math.MinInt32, math.MaxInt32},
+        {math.MinInt32 + 1, -1, math.MinInt32, math.MaxInt32},
+        {10, 3, 5, math.MaxInt32},
+    }
+
+    for _, tc := range rows {
+        f.Add(tc.min, tc.max, tc.a, tc.b)
+    }
+
+    f.Fuzz(func(t *testing.T, min, max, a, b int32) {
+        if ((min > max) ||
+            (a < min) ||
+            (a > max) ||
+            (b < min) ||
+            (b > max)) {
+            t.SkipNow()
+        }
+
+        result, resultOk := checked.Div(min, max, a, b)
+        if b == 0 {
+            if resultOk {
+                t.Errorf("integer.Div(%v, %v, %v, %v): got %t, expected false",
+                    min, max, a, b, resultOk)
+            }
+            return
+        }
+        expectedResult := int64(a) / int64(b)
+        ok := (expectedResult <= int64(max)) && (expectedResult >= int64(min))
+
+        if ok != resultOk {
+            t.Errorf("integer.Div(%v, %v, %v, %v): got %t, expected %t",
+                min, max, a, b,
+                resultOk, ok)
+        } else if ok && (int64(result) != expectedResult) {
+            t.Errorf("integer.Div(%v, %v, %v, %v): got %v, %t, expected %v",
+                min, max, a, b,
+                result, resultOk, expectedResult)
+        }
+    })
+}
+
+func TestSum(t *testing.T) {
+    assert.Equal(t, tuple.ToT2(10,       true),  tuple.ToT2(checked.Int.Sum(1, 2, 3, 4)))
+    assert.Equal(t, tuple.ToT2(0,        true),  tuple.ToT2(checked.Int.Sum()))
+    assert.Equal(t, tuple.ToT2(int8(0),  false), tuple.ToT2(checked.Int8.Sum(100, 100, -100)))
+    assert.Equal(t, tuple.ToT2(int8(100), true), tuple.ToT2(checked.Int8.Sum(100, -100, 100)))
+    assert.Equal(t, tuple.ToT2(0,        false), tuple.ToT2(checked.Limits[int]{Min: 5, Max: 99}.Sum()))
+    assert.Equal(t, tuple.ToT2(uint8(0), false), tuple.ToT2(checked.Uint8.SumIter(iter.FromSlice([]uint8{200, 50, 6}))))
+    assert.Equal(t, tuple.ToT2(uint8(255), true), tuple.ToT2(checked.Uint8.SumIter(iter.FromSlice([]uint8{200, 50, 5}))))
+    assert.Equal(t, tuple.ToT2(0,        true),  tuple.ToT2(checked.Int.SumIter(iter.Empty[int]())))
+}
+
+func TestProduct(t *testing.T) {
+    assert.Equal(t, tuple.ToT2(24,       true),  tuple.ToT2(checked.Int.Product(1, 2, 3, 4)))
+    assert.Equal(t, tuple.ToT2(1,        true),  tuple.ToT2(checked.Int.Product()))
+    assert.Equal(t, tuple.ToT2(int8(0),  false), tuple.ToT2(checked.Int8.Product(16, 8, 0)))
+    assert.Equal(t, tuple.ToT2(int8(0),  true),  tuple.ToT2(checked.Int8.Product(0, 16, 8)))
+    assert.Equal(t, tuple.ToT2(uint8(120), true), tuple.ToT2(checked.Uint8.ProductIter(iter.FromSlice([]uint8{2, 3, 4, 5}))))
+    assert.Equal(t, tuple.ToT2(uint8(0), false), tuple.ToT2(checked.Uint8.ProductIter(iter.FromSlice([]uint8{2, 3, 4, 5, 6}))))
+}
+
 func FuzzAdd_Int32(f *testing.F) {
     type row struct {
         a int32
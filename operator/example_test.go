@@ -37,3 +37,20 @@ func ExampleAdd() {
     // Output:
     // sum of numbers from 1 to 100: 5050
 }
+
+func ExampleClamp() {
+    fmt.Println(operator.Clamp(-5, 0, 10))
+    fmt.Println(operator.Clamp( 5, 0, 10))
+    fmt.Println(operator.Clamp(15, 0, 10))
+    fmt.Println(operator.MinOf(3, 1, 4, 1, 5))
+    fmt.Println(operator.MaxOf(3, 1, 4, 1, 5))
+    fmt.Println(operator.Sign(-2.5), operator.Sign(0), operator.Sign(uint(7)))
+
+    // Output:
+    // 0
+    // 5
+    // 10
+    // 1
+    // 5
+    // -1 0 1
+}
@@ -63,6 +63,17 @@ func Abs[R Number](r R) R {
     return 0 - r
 }
 
+// Sign returns 1, 0, or -1 (in the same type as the input) depending on
+// whether r is greater than, equal to, or less than zero.
+func Sign[R Number](r R) R {
+    var one R = 1
+    switch {
+        case (r > 0): return one
+        case (r < 0): return 0 - one
+        default:      return 0
+    }
+}
+
 // Inv returns (-r)
 func Inv[R Signed](r R) R {
     return 0 - r
@@ -91,3 +91,41 @@ func GT[O constraints.Ordered](a O, b O) bool {
 func GTE[O constraints.Ordered](a O, b O) bool {
     return a >= b
 }
+
+// Min returns the smaller of a or b.
+func Min[O constraints.Ordered](a O, b O) O {
+    if a < b { return a } else { return b }
+}
+
+// Max returns the larger of a or b.
+func Max[O constraints.Ordered](a O, b O) O {
+    if a > b { return a } else { return b }
+}
+
+// MinOf returns the smallest of x and any of the following arguments.
+func MinOf[O constraints.Ordered](x O, xs ... O) O {
+    for _, i := range xs {
+        if i < x { x = i }
+    }
+    return x
+}
+
+// MaxOf returns the largest of x and any of the following arguments.
+func MaxOf[O constraints.Ordered](x O, xs ... O) O {
+    for _, i := range xs {
+        if i > x { x = i }
+    }
+    return x
+}
+
+// Clamp returns x limited to the range lo to hi inclusive. That is, lo if
+// x < lo, hi if x > hi, or x otherwise.
+//
+// If lo > hi, the result is undefined.
+func Clamp[O constraints.Ordered](x O, lo O, hi O) O {
+    switch {
+        case (x < lo): return lo
+        case (x > hi): return hi
+        default:       return x
+    }
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// GoldenEnv is the name of an environment variable that, when set to a
+// non-empty value, causes [Golden] and [GoldenString] to write the result
+// under test to the golden file instead of comparing against it.
+//
+// For example:
+//
+//     GOLIB_UPDATE_GOLDEN=1 go test ./...
+const GoldenEnv = "GOLIB_UPDATE_GOLDEN"
+
+// Golden compares got with the contents of the golden file at the given
+// path, relative to the "testdata" directory of the package under test. If
+// they differ, or the file cannot be read, calls t.Errorf to fail the test.
+//
+// If the environment variable named by [GoldenEnv] is set, instead writes got
+// to the golden file, creating it and any parent directories as necessary.
+//
+// Returns true if got matched the golden file, or if the golden file was
+// successfully updated.
+func Golden(t testing.TB, path string, got []byte) bool {
+    t.Helper()
+    path = filepath.Join("testdata", filepath.FromSlash(path))
+
+    if os.Getenv(GoldenEnv) != "" {
+        if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+            t.Errorf("error creating directory for golden file %q: %v", path, err)
+            return false
+        }
+        if err := os.WriteFile(path, got, 0644); err != nil {
+            t.Errorf("error updating golden file %q: %v", path, err)
+            return false
+        }
+        return true
+    }
+
+    want, err := os.ReadFile(path)
+    if err != nil {
+        t.Errorf("error reading golden file %q (set %s=1 to create it): %v",
+            path, GoldenEnv, err)
+        return false
+    }
+
+    if !bytes.Equal(got, want) {
+        t.Errorf("result does not match golden file %q (set %s=1 to update it)\n"+
+            "got:\n%s\nwant:\n%s", path, GoldenEnv, got, want)
+        return false
+    }
+    return true
+}
+
+// GoldenString is like [Golden], but for a string result.
+func GoldenString(t testing.TB, path string, got string) bool {
+    t.Helper()
+    return Golden(t, path, []byte(got))
+}
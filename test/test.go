@@ -0,0 +1,102 @@
+// Package test implements helpers for writing tests.
+//
+// These helpers are used by tests throughout golib, and are made available
+// for use in the tests of downstream packages too. Each helper reports any
+// failure through the provided [testing.TB], in the same way as the methods
+// of [testing.T].
+package test
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/tawesoft/golib/v2/internal/errchain"
+)
+
+// Panics returns true iff calling f panics with an error and either target
+// is nil or errors.Is(error, target) is true.
+func Panics(t testing.TB, f func(), target error) (result bool) {
+    t.Helper()
+    defer func() {
+        if r := recover(); r == nil {
+            result = false
+        } else if err, ok := r.(error); ok {
+            result = (target == nil) || errors.Is(err, target)
+        } else {
+            result = (target == nil)
+        }
+    }()
+    f()
+    return false
+}
+
+// Completes executes f (in a goroutine), and blocks until either f returns,
+// or the provided duration has elapsed. In the latter case, calls t.Errorf to
+// fail the test. Provide optional format string and arguments to add
+// context to the test error message.
+func Completes(t testing.TB, duration time.Duration, f func(), args ... interface{}) {
+    t.Helper()
+    done := make(chan struct{}, 1)
+    timeout := time.After(duration)
+    go func() {
+        f()
+        done <- struct{}{}
+    }()
+
+    select {
+        case <-done: // OK
+        case <-timeout:
+            if len(args) > 0 {
+                t.Errorf("test timed out after "+duration.String()+": " + args[0].(string), args[1:]...)
+            } else {
+                t.Errorf("test timed out after %s", duration.String())
+            }
+    }
+}
+
+// Eventually repeatedly calls cond, at a short interval, until either it
+// returns true, or the provided timeout has elapsed. In the latter case,
+// calls t.Errorf to fail the test. Provide optional format string and
+// arguments to add context to the test error message.
+//
+// Returns true if cond returned true before the timeout.
+func Eventually(t testing.TB, timeout time.Duration, cond func() bool, args ... interface{}) bool {
+    t.Helper()
+    const maxInterval = 100 * time.Millisecond
+    interval := min(timeout / 100, maxInterval)
+    if interval <= 0 { interval = time.Millisecond }
+    deadline := time.Now().Add(timeout)
+
+    for {
+        if cond() { return true }
+        if time.Now().After(deadline) { break }
+        time.Sleep(interval)
+    }
+
+    if len(args) > 0 {
+        t.Errorf("condition not met after "+timeout.String()+": " + args[0].(string), args[1:]...)
+    } else {
+        t.Errorf("condition not met after %s", timeout.String())
+    }
+    return false
+}
+
+// ErrorIs returns true if errors.Is(err, target) is true. Otherwise, calls
+// t.Errorf to fail the test, with a message that includes every error in the
+// chain of err. Provide optional format string and arguments to add context
+// to the test error message.
+func ErrorIs(t testing.TB, err error, target error, args ... interface{}) bool {
+    t.Helper()
+    if errors.Is(err, target) { return true }
+
+    msg := fmt.Sprintf("error chain %s does not match target %q",
+        errchain.String(err, false), fmt.Sprint(target))
+    if len(args) > 0 {
+        t.Errorf("%s: %s", msg, fmt.Sprintf(args[0].(string), args[1:]...))
+    } else {
+        t.Errorf("%s", msg)
+    }
+    return false
+}
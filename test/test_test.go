@@ -0,0 +1,91 @@
+package test_test
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/tawesoft/golib/v2/test"
+)
+
+// recorder captures test failures instead of failing the real test.
+type recorder struct {
+    testing.TB
+    failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ... any) {
+    r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestPanics(t *testing.T) {
+    target := errors.New("target")
+    if !test.Panics(t, func() { panic(fmt.Errorf("wrapped: %w", target)) }, target) {
+        t.Errorf("expected panic matching target")
+    }
+    if test.Panics(t, func() { panic(errors.New("other")) }, target) {
+        t.Errorf("expected panic not matching target")
+    }
+    if test.Panics(t, func() {}, nil) {
+        t.Errorf("expected no panic")
+    }
+}
+
+func TestEventually(t *testing.T) {
+    var counter atomic.Int32
+    go func() {
+        for i := 0; i < 3; i++ {
+            time.Sleep(5 * time.Millisecond)
+            counter.Add(1)
+        }
+    }()
+    test.Eventually(t, time.Second, func() bool { return counter.Load() == 3 })
+
+    r := &recorder{TB: t}
+    if test.Eventually(r, 20 * time.Millisecond, func() bool { return false }, "waiting for %s", "nothing") {
+        t.Errorf("expected condition to fail")
+    }
+    if (len(r.failures) != 1) || !strings.HasSuffix(r.failures[0], ": waiting for nothing") {
+        t.Errorf("unexpected failures %q", r.failures)
+    }
+}
+
+func TestErrorIs(t *testing.T) {
+    target := errors.New("target")
+    other := errors.New("other")
+
+    test.ErrorIs(t, fmt.Errorf("wrapped: %w", target), target)
+    test.ErrorIs(t, errors.Join(other, target), target)
+
+    r := &recorder{TB: t}
+    if test.ErrorIs(r, fmt.Errorf("wrapped: %w", other), target) {
+        t.Errorf("expected error not to match")
+    }
+    expected := `error chain ["wrapped: other" -> "other"] does not match target "target"`
+    if (len(r.failures) != 1) || (r.failures[0] != expected) {
+        t.Errorf("unexpected failures %q", r.failures)
+    }
+}
+
+func TestGolden(t *testing.T) {
+    // this test checks the comparison, so must never update the golden files
+    t.Setenv(test.GoldenEnv, "")
+
+    test.GoldenString(t, "hello.golden", "hello, golden\n")
+
+    r := &recorder{TB: t}
+    if test.GoldenString(r, "hello.golden", "goodbye\n") {
+        t.Errorf("expected mismatch")
+    }
+    if test.GoldenString(r, "does-not-exist.golden", "") {
+        t.Errorf("expected missing golden file")
+    }
+    if len(r.failures) != 2 {
+        t.Errorf("unexpected failures %q", r.failures)
+    }
+}
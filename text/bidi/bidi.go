@@ -0,0 +1,156 @@
+// Package bidi implements the [Unicode Standard Annex #9: Unicode
+// Bidirectional Algorithm], for applications that display a mix of
+// right-to-left text (e.g. Arabic or Hebrew) and left-to-right text (e.g.
+// English).
+//
+// It resolves the direction of a paragraph, reorders the runs of a line of
+// text from logical (memory) order to visual (display) order, and strips or
+// injects the Unicode directional formatting characters.
+//
+// The embedding levels are resolved by the rules of the algorithm, including
+// explicit embeddings, overrides and isolates, bracket pairs (rule N0) and
+// neutral characters (rules N1 and N2), using the character properties from
+// the [x/text/unicode/bidi] package.
+//
+// [x/text/unicode/bidi]: https://pkg.go.dev/golang.org/x/text/unicode/bidi
+// [Unicode Standard Annex #9: Unicode Bidirectional Algorithm]: https://www.unicode.org/reports/tr9/
+package bidi
+
+import (
+    "strings"
+    "unicode/utf8"
+
+    "golang.org/x/text/unicode/bidi"
+)
+
+// Direction is the direction of some text. It is one of LeftToRight,
+// RightToLeft, Mixed, or Neutral.
+type Direction = bidi.Direction
+
+const (
+    LeftToRight = bidi.LeftToRight
+    RightToLeft = bidi.RightToLeft
+    Mixed       = bidi.Mixed
+    Neutral     = bidi.Neutral
+)
+
+// Unicode directional formatting characters.
+const (
+    LRM = '\u200E' // Left-to-Right Mark
+    RLM = '\u200F' // Right-to-Left Mark
+    ALM = '\u061C' // Arabic Letter Mark
+    LRE = '\u202A' // Left-to-Right Embedding
+    RLE = '\u202B' // Right-to-Left Embedding
+    PDF = '\u202C' // Pop Directional Formatting
+    LRO = '\u202D' // Left-to-Right Override
+    RLO = '\u202E' // Right-to-Left Override
+    LRI = '\u2066' // Left-to-Right Isolate
+    RLI = '\u2067' // Right-to-Left Isolate
+    FSI = '\u2068' // First Strong Isolate
+    PDI = '\u2069' // Pop Directional Isolate
+)
+
+func class(r rune) bidi.Class {
+    p, _ := bidi.LookupRune(r)
+    return p.Class()
+}
+
+// ParagraphDirection returns the direction of the first paragraph in s,
+// which is the direction of its first strong character (ignoring any
+// characters between an isolate initiator and its matching PDI), as in
+// [Unicode Standard Annex #9] rules P2 and P3. If there is no strong
+// character, returns defaultDirection.
+//
+// [Unicode Standard Annex #9]: https://www.unicode.org/reports/tr9/
+func ParagraphDirection(s string, defaultDirection Direction) Direction {
+    isolates := 0
+    for _, r := range s {
+        switch class(r) {
+            case bidi.B:
+                return defaultDirection
+            case bidi.LRI, bidi.RLI, bidi.FSI:
+                isolates++
+            case bidi.PDI:
+                if isolates > 0 { isolates-- }
+            case bidi.L:
+                if isolates == 0 { return LeftToRight }
+            case bidi.R, bidi.AL:
+                if isolates == 0 { return RightToLeft }
+        }
+    }
+    return defaultDirection
+}
+
+// TextDirection returns LeftToRight if s contains only left-to-right strong
+// characters, RightToLeft if s contains only right-to-left strong characters,
+// Mixed if s contains both, or Neutral if s contains neither.
+func TextDirection(s string) Direction {
+    var ltr, rtl bool
+    for _, r := range s {
+        switch class(r) {
+            case bidi.L:          ltr = true
+            case bidi.R, bidi.AL: rtl = true
+        }
+        if ltr && rtl { return Mixed }
+    }
+    switch {
+        case ltr: return LeftToRight
+        case rtl: return RightToLeft
+        default:  return Neutral
+    }
+}
+
+// IsControl returns true if r is one of the Unicode directional formatting
+// characters.
+func IsControl(r rune) bool {
+    switch r {
+        case LRM, RLM, ALM, LRE, RLE, PDF, LRO, RLO, LRI, RLI, FSI, PDI:
+            return true
+        default:
+            return false
+    }
+}
+
+// StripControls returns s with every Unicode directional formatting character
+// removed.
+func StripControls(s string) string {
+    if strings.IndexFunc(s, IsControl) < 0 { return s }
+    return strings.Map(func(r rune) rune {
+        if IsControl(r) { return -1 }
+        return r
+    }, s)
+}
+
+// Isolate returns s surrounded by directional isolate characters, so that
+// it can be safely inserted into other text (for example, a user name in a
+// message) without affecting the display of the text around it.
+//
+// The direction may be LeftToRight (LRI ... PDI), RightToLeft (RLI ... PDI),
+// or anything else for the direction of the first strong character in s
+// (FSI ... PDI).
+func Isolate(s string, d Direction) string {
+    var initiator rune
+    switch d {
+        case LeftToRight: initiator = LRI
+        case RightToLeft: initiator = RLI
+        default:          initiator = FSI
+    }
+    var sb strings.Builder
+    sb.Grow(len(s) + 2 * utf8.RuneLen(PDI))
+    sb.WriteRune(initiator)
+    sb.WriteString(s)
+    sb.WriteRune(PDI)
+    return sb.String()
+}
+
+// Mark returns a zero-width LRM for LeftToRight, or RLM for RightToLeft, or
+// an empty string otherwise. A mark may be used, for example, to stop
+// punctuation at the end of some left-to-right text from being displayed at
+// the wrong end when it appears in a right-to-left paragraph.
+func Mark(d Direction) string {
+    switch d {
+        case LeftToRight: return string(LRM)
+        case RightToLeft: return string(RLM)
+        default:          return ""
+    }
+}
@@ -0,0 +1,119 @@
+package bidi_test
+
+import (
+    "testing"
+
+    "github.com/tawesoft/golib/v2/text/bidi"
+)
+
+// Hebrew letters alef, bet, gimel: "אבג"
+// Arabic-Indic digits one, two, three: "١٢٣"
+
+func TestParagraphDirection(t *testing.T) {
+    type row struct {
+        input string
+        defaultDirection bidi.Direction
+        expected bidi.Direction
+    }
+    rows := []row{
+        {"", bidi.Neutral, bidi.Neutral},
+        {"123 !", bidi.RightToLeft, bidi.RightToLeft},
+        {"abc אבג", bidi.Neutral, bidi.LeftToRight},
+        {"123 אבג abc", bidi.Neutral, bidi.RightToLeft},
+        {"⁦abc⁩ אבג", bidi.Neutral, bidi.RightToLeft}, // isolate is skipped
+        {"‏abc", bidi.Neutral, bidi.RightToLeft},           // RLM
+        {"123\nאבג", bidi.LeftToRight, bidi.LeftToRight},        // first paragraph only
+    }
+    for _, r := range rows {
+        if got := bidi.ParagraphDirection(r.input, r.defaultDirection); got != r.expected {
+            t.Errorf("ParagraphDirection(%q, %v): got %v, expected %v",
+                r.input, r.defaultDirection, got, r.expected)
+        }
+    }
+}
+
+func TestTextDirection(t *testing.T) {
+    type row struct {
+        input string
+        expected bidi.Direction
+    }
+    rows := []row{
+        {"", bidi.Neutral},
+        {"123 !", bidi.Neutral},
+        {"abc 123", bidi.LeftToRight},
+        {"אבג 123", bidi.RightToLeft},
+        {"abc אבג", bidi.Mixed},
+    }
+    for _, r := range rows {
+        if got := bidi.TextDirection(r.input); got != r.expected {
+            t.Errorf("TextDirection(%q): got %v, expected %v", r.input, got, r.expected)
+        }
+    }
+}
+
+func TestDisplay(t *testing.T) {
+    type row struct {
+        input string
+        defaultDirection bidi.Direction
+        expected string
+    }
+    rows := []row{
+        {"", bidi.LeftToRight, ""},
+        {"abc", bidi.RightToLeft, "abc"},
+        {"אבג", bidi.LeftToRight, "גבא"},
+        {"אבג 123", bidi.LeftToRight, "123 גבא"},           // W7, I1
+        {"abc ١٢٣ אבג", bidi.LeftToRight, "abc גבא ١٢٣"},  // AN
+        {"אבג!", bidi.LeftToRight, "!גבא"},
+        {"abc אבג!", bidi.LeftToRight, "abc גבא!"},
+        {"אבג (1)", bidi.LeftToRight, "(1) גבא"},          // mirrored brackets
+        {"אבג  ", bidi.LeftToRight, "  גבא"},              // L1
+        {"abc אבג\nאבג abc", bidi.LeftToRight, "abc גבא\nabc גבא"},
+        {"abc\nאבג\n", bidi.LeftToRight, "abc\nגבא\n"},          // L1
+        {"אבג  \r\nאבג", bidi.LeftToRight, "  גבא\r\nגבא"},
+        {"שלום (abc) [x]", bidi.LeftToRight, "[x] (abc) םולש"},         // N0, RTL paragraph
+        {"\u200Eשלום (abc) [x]", bidi.LeftToRight, "\u200Eםולש (abc) [x]"}, // N0, LRM
+        {"abc (אבג) def", bidi.LeftToRight, "abc (גבא) def"},           // N0 b
+        {"אבג (abc) def", bidi.RightToLeft, "def (abc) גבא"},           // N0 c
+        {"abc [אבג abc] def", bidi.LeftToRight, "abc [גבא abc] def"},
+        {"abc אבג [!] def", bidi.LeftToRight, "abc גבא [!] def"},       // N0 d, N1
+        {"abc אבג - אבג def", bidi.LeftToRight, "abc גבא - גבא def"},   // N1
+        {"abc אבג - def", bidi.LeftToRight, "abc גבא - def"},           // N2
+        {"אבג abc - אבג", bidi.LeftToRight, "גבא - abc גבא"},           // N2
+        {"abc \u202Bאבג def\u202C ghi", bidi.LeftToRight, "abc \u202Bdef\u202C גבא ghi"}, // RLE
+        {"abc \u2067אבג 123\u2069 jkl", bidi.LeftToRight, "abc \u2067123 גבא\u2069 jkl"}, // RLI
+    }
+    for _, r := range rows {
+        got, err := bidi.Display(r.input, r.defaultDirection)
+        if err != nil {
+            t.Errorf("Display(%q): unexpected error: %v", r.input, err)
+        } else if got != r.expected {
+            t.Errorf("Display(%q): got %q, expected %q", r.input, got, r.expected)
+        }
+    }
+}
+
+func TestRuns(t *testing.T) {
+    s := "abc אבג\nאבג 12"
+    runs, err := bidi.Runs(s, bidi.LeftToRight)
+    if err != nil { t.Fatalf("unexpected error: %v", err) }
+
+    expected := []bidi.Run{
+        {Text: "abc ",     Offset: 0,  Level: 0},
+        {Text: "אבג",      Offset: 4,  Level: 1},
+        {Text: "\n",       Offset: 10, Level: 0},
+        {Text: "אבג ",     Offset: 11, Level: 1},
+        {Text: "12",       Offset: 18, Level: 2},
+    }
+    if len(runs) != len(expected) {
+        t.Fatalf("Runs(%q): got %v, expected %v", s, runs, expected)
+    }
+    for i := range runs {
+        if runs[i] != expected[i] {
+            t.Errorf("Runs(%q): got %v, expected %v", s, runs, expected)
+            break
+        }
+        if s[runs[i].Offset:runs[i].Offset + len(runs[i].Text)] != runs[i].Text {
+            t.Errorf("Runs(%q): run %d has bad offset", s, i)
+        }
+    }
+}
@@ -0,0 +1,53 @@
+package bidi_test
+
+import (
+    "fmt"
+
+    "github.com/tawesoft/golib/v2/text/bidi"
+)
+
+func ExampleDisplay() {
+    // Hebrew letters alef, bet, gimel
+    print := func(s string, d bidi.Direction) {
+        display, err := bidi.Display(s, d)
+        if err != nil { panic(err) }
+        fmt.Printf("%s => %s\n", s, display)
+    }
+
+    print("abc אבג def", bidi.LeftToRight)
+    print("abc אבג 123 def", bidi.LeftToRight)
+    print("אבג abc 12", bidi.LeftToRight)
+    print("123", bidi.RightToLeft)
+
+    // Output:
+    // abc אבג def => abc גבא def
+    // abc אבג 123 def => abc 123 גבא def
+    // אבג abc 12 => abc 12 גבא
+    // 123 => 123
+}
+
+func ExampleReorder() {
+    runs, err := bidi.Reorder("one שתיים three", bidi.LeftToRight)
+    if err != nil { panic(err) }
+
+    for _, run := range runs {
+        fmt.Printf("%q at offset %d, level %d\n", run.Text, run.Offset, run.Level)
+    }
+
+    // Output:
+    // "one " at offset 0, level 0
+    // "שתיים" at offset 4, level 1
+    // " three" at offset 14, level 0
+}
+
+func ExampleIsolate() {
+    name := "אבג"
+    message := fmt.Sprintf("%s has 3 messages", bidi.Isolate(name, bidi.Neutral))
+
+    fmt.Printf("%q\n", message)
+    fmt.Printf("%q\n", bidi.StripControls(message))
+
+    // Output:
+    // "\u2068אבג\u2069 has 3 messages"
+    // "אבג has 3 messages"
+}
@@ -0,0 +1,540 @@
+package bidi
+
+import (
+    "golang.org/x/text/unicode/bidi"
+)
+
+// maxDepth is the maximum explicit embedding level (BD2).
+const maxDepth = 125
+
+// maxBrackets is the maximum number of nested brackets considered for
+// bracket pairs (BD16).
+const maxBrackets = 63
+
+// resolveLevels returns the embedding level of each rune in a single
+// paragraph, with its paragraph embedding level resolved by rules P2 and P3.
+func resolveLevels(paragraph string, defaultDirection Direction) []int {
+    base := 0
+    if ParagraphDirection(paragraph, defaultDirection) == RightToLeft { base = 1 }
+    return paragraphLevels([]rune(paragraph), base)
+}
+
+// paragraphLevels returns the embedding level of each rune in a single
+// paragraph with the given paragraph embedding level, according to the rules
+// of [Unicode Standard Annex #9] (sections 3.3.2 to 3.4).
+//
+// Runes removed by rule X9 are given the level of the rune before them, or
+// the paragraph embedding level if there is none.
+//
+// [Unicode Standard Annex #9]: https://www.unicode.org/reports/tr9/
+func paragraphLevels(runes []rune, base int) []int {
+    original := make([]bidi.Class, len(runes))
+    for i, r := range runes {
+        original[i] = class(r)
+    }
+    classes := append([]bidi.Class(nil), original...)
+    levels := make([]int, len(runes))
+
+    matchingPDI, matchingInitiator := matchIsolates(original)
+    explicitLevels(runes, classes, levels, base, matchingPDI)
+
+    for _, seq := range isolatingRunSequences(classes, levels, base, matchingPDI, matchingInitiator) {
+        seq.resolveWeak()
+        seq.resolveBrackets(runes, original)
+        seq.resolveNeutral()
+        seq.resolveImplicit()
+    }
+
+    // give each rune removed by rule X9 the level of the rune before it
+    for i := range levels {
+        if !removed(classes[i]) { continue }
+        if i == 0 {
+            levels[i] = base
+        } else {
+            levels[i] = levels[i - 1]
+        }
+    }
+
+    applyTrailingWhitespace(original, levels, base)
+    return levels
+}
+
+// removed returns true for the classes of the runes removed by rule X9.
+func removed(c bidi.Class) bool {
+    switch c {
+        case bidi.RLE, bidi.LRE, bidi.RLO, bidi.LRO, bidi.PDF, bidi.BN:
+            return true
+        default:
+            return false
+    }
+}
+
+func isolateInitiator(c bidi.Class) bool {
+    return (c == bidi.LRI) || (c == bidi.RLI) || (c == bidi.FSI)
+}
+
+// matchIsolates returns the index of the matching PDI of each isolate
+// initiator, and the index of the matching isolate initiator of each PDI, or
+// -1 if there is none (BD9).
+func matchIsolates(classes []bidi.Class) (matchingPDI, matchingInitiator []int) {
+    matchingPDI = make([]int, len(classes))
+    matchingInitiator = make([]int, len(classes))
+    var stack []int
+    for i, c := range classes {
+        matchingPDI[i], matchingInitiator[i] = -1, -1
+        switch {
+            case isolateInitiator(c):
+                stack = append(stack, i)
+            case (c == bidi.PDI) && (len(stack) > 0):
+                initiator := stack[len(stack) - 1]
+                stack = stack[0:len(stack) - 1]
+                matchingPDI[initiator] = i
+                matchingInitiator[i] = initiator
+        }
+    }
+    return matchingPDI, matchingInitiator
+}
+
+// explicitLevels applies rules X1 to X8, setting the explicit embedding level
+// of each rune, and its class if there is a directional override.
+func explicitLevels(runes []rune, classes []bidi.Class, levels []int, base int, matchingPDI []int) {
+    type status struct {
+        level int
+        override bidi.Class // L, R, or ON for no override
+        isolate bool
+    }
+    stack := []status{{level: base, override: bidi.ON}}
+    overflowIsolates, overflowEmbeddings, validIsolates := 0, 0, 0
+
+    // next returns the least odd (rtl) or even (ltr) level greater than the
+    // current level.
+    next := func(rtl bool) int {
+        level := stack[len(stack) - 1].level + 1
+        if (level % 2 == 1) != rtl { level++ }
+        return level
+    }
+
+    for i, c := range classes {
+        top := stack[len(stack) - 1]
+
+        switch c {
+            case bidi.RLE, bidi.LRE, bidi.RLO, bidi.LRO: // X2 to X5
+                levels[i] = top.level
+                level := next((c == bidi.RLE) || (c == bidi.RLO))
+                if (level <= maxDepth) && (overflowIsolates == 0) && (overflowEmbeddings == 0) {
+                    override := bidi.ON
+                    if c == bidi.RLO { override = bidi.R }
+                    if c == bidi.LRO { override = bidi.L }
+                    stack = append(stack, status{level: level, override: override})
+                } else if overflowIsolates == 0 {
+                    overflowEmbeddings++
+                }
+
+            case bidi.RLI, bidi.LRI, bidi.FSI: // X5a to X5c
+                levels[i] = top.level
+                if top.override != bidi.ON { classes[i] = top.override }
+
+                rtl := c == bidi.RLI
+                if c == bidi.FSI {
+                    end := len(runes)
+                    if matchingPDI[i] >= 0 { end = matchingPDI[i] }
+                    rtl = ParagraphDirection(string(runes[i + 1:end]), LeftToRight) == RightToLeft
+                }
+
+                level := next(rtl)
+                if (level <= maxDepth) && (overflowIsolates == 0) && (overflowEmbeddings == 0) {
+                    validIsolates++
+                    stack = append(stack, status{level: level, override: bidi.ON, isolate: true})
+                } else {
+                    overflowIsolates++
+                }
+
+            case bidi.PDI: // X6a
+                if overflowIsolates > 0 {
+                    overflowIsolates--
+                } else if validIsolates > 0 {
+                    overflowEmbeddings = 0
+                    for !stack[len(stack) - 1].isolate {
+                        stack = stack[0:len(stack) - 1]
+                    }
+                    stack = stack[0:len(stack) - 1]
+                    validIsolates--
+                }
+                top = stack[len(stack) - 1]
+                levels[i] = top.level
+                if top.override != bidi.ON { classes[i] = top.override }
+
+            case bidi.PDF: // X7
+                levels[i] = top.level
+                if overflowIsolates > 0 {
+                    // do nothing
+                } else if overflowEmbeddings > 0 {
+                    overflowEmbeddings--
+                } else if !top.isolate && (len(stack) >= 2) {
+                    stack = stack[0:len(stack) - 1]
+                }
+
+            case bidi.B: // X8
+                levels[i] = base
+
+            case bidi.BN: // X6 does not apply
+                levels[i] = top.level
+
+            default: // X6
+                levels[i] = top.level
+                if top.override != bidi.ON { classes[i] = top.override }
+        }
+    }
+}
+
+// sequence is an isolating run sequence (BD13): the indexes of its runes,
+// in order, with the classes and levels of the whole paragraph.
+type sequence struct {
+    indexes []int
+    classes []bidi.Class // of the paragraph
+    levels []int         // of the paragraph
+    level int
+    sos, eos bidi.Class  // L or R
+}
+
+// isolatingRunSequences divides a paragraph, ignoring the runes removed by
+// rule X9, into level runs and isolating run sequences as in rule X10.
+func isolatingRunSequences(
+    classes []bidi.Class,
+    levels []int,
+    base int,
+    matchingPDI, matchingInitiator []int,
+) []*sequence {
+    // level runs (BD7), as indexes of the runes that are not removed
+    var runs [][]int
+    var run []int
+    for i, c := range classes {
+        if removed(c) { continue }
+        if (len(run) > 0) && (levels[run[len(run) - 1]] != levels[i]) {
+            runs = append(runs, run)
+            run = nil
+        }
+        run = append(run, i)
+    }
+    if len(run) > 0 { runs = append(runs, run) }
+
+    // the level run starting with each rune
+    runStarting := make(map[int]int)
+    for i, run := range runs {
+        runStarting[run[0]] = i
+    }
+
+    direction := func(level int) bidi.Class {
+        if level % 2 == 0 { return bidi.L }
+        return bidi.R
+    }
+
+    var result []*sequence
+    for _, run := range runs {
+        first := run[0]
+        if (classes[first] == bidi.PDI) && (matchingInitiator[first] >= 0) {
+            continue // part of the sequence of its initiator
+        }
+
+        var indexes []int
+        for {
+            indexes = append(indexes, run...)
+            last := run[len(run) - 1]
+            if !isolateInitiator(classes[last]) || (matchingPDI[last] < 0) { break }
+            next, ok := runStarting[matchingPDI[last]]
+            if !ok { break }
+            run = runs[next]
+        }
+
+        level := levels[indexes[0]]
+
+        before := base
+        for i := indexes[0] - 1; i >= 0; i-- {
+            if !removed(classes[i]) { before = levels[i]; break }
+        }
+
+        after := base
+        last := indexes[len(indexes) - 1]
+        if !isolateInitiator(classes[last]) || (matchingPDI[last] >= 0) {
+            for i := last + 1; i < len(classes); i++ {
+                if !removed(classes[i]) { after = levels[i]; break }
+            }
+        }
+
+        result = append(result, &sequence{
+            indexes: indexes,
+            classes: classes,
+            levels: levels,
+            level: level,
+            sos: direction(max(level, before)),
+            eos: direction(max(level, after)),
+        })
+    }
+    return result
+}
+
+func (s *sequence) class(i int) bidi.Class { return s.classes[s.indexes[i]] }
+func (s *sequence) set(i int, c bidi.Class) { s.classes[s.indexes[i]] = c }
+
+// embedding returns the embedding direction of the sequence, L or R.
+func (s *sequence) embedding() bidi.Class {
+    if s.level % 2 == 0 { return bidi.L }
+    return bidi.R
+}
+
+// resolveWeak applies rules W1 to W7.
+func (s *sequence) resolveWeak() {
+    n := len(s.indexes)
+
+    // W1
+    prev := s.sos
+    for i := 0; i < n; i++ {
+        c := s.class(i)
+        if c == bidi.NSM {
+            if isolateInitiator(prev) || (prev == bidi.PDI) {
+                s.set(i, bidi.ON)
+            } else {
+                s.set(i, prev)
+            }
+        }
+        prev = s.class(i)
+    }
+
+    // W2, W3
+    strong := s.sos
+    for i := 0; i < n; i++ {
+        switch c := s.class(i); c {
+            case bidi.L, bidi.R:
+                strong = c
+            case bidi.AL:
+                strong = c
+                s.set(i, bidi.R)
+            case bidi.EN:
+                if strong == bidi.AL { s.set(i, bidi.AN) }
+        }
+    }
+
+    // W4
+    for i := 1; i < n - 1; i++ {
+        c, before, after := s.class(i), s.class(i - 1), s.class(i + 1)
+        switch {
+            case (c == bidi.ES) && (before == bidi.EN) && (after == bidi.EN):
+                s.set(i, bidi.EN)
+            case (c == bidi.CS) && (before == bidi.EN) && (after == bidi.EN):
+                s.set(i, bidi.EN)
+            case (c == bidi.CS) && (before == bidi.AN) && (after == bidi.AN):
+                s.set(i, bidi.AN)
+        }
+    }
+
+    // W5
+    for i := 0; i < n; {
+        if s.class(i) != bidi.ET { i++; continue }
+        j := i
+        for (j < n) && (s.class(j) == bidi.ET) { j++ }
+        if ((i > 0) && (s.class(i - 1) == bidi.EN)) || ((j < n) && (s.class(j) == bidi.EN)) {
+            for k := i; k < j; k++ {
+                s.set(k, bidi.EN)
+            }
+        }
+        i = j
+    }
+
+    // W6
+    for i := 0; i < n; i++ {
+        switch s.class(i) {
+            case bidi.ES, bidi.ET, bidi.CS:
+                s.set(i, bidi.ON)
+        }
+    }
+
+    // W7
+    strong = s.sos
+    for i := 0; i < n; i++ {
+        switch c := s.class(i); c {
+            case bidi.L, bidi.R:
+                strong = c
+            case bidi.EN:
+                if strong == bidi.L { s.set(i, bidi.L) }
+        }
+    }
+}
+
+// strongDirection returns the direction of a class for rules N0 to N2, where
+// numbers are treated as R, or ON if it does not have a strong direction.
+func strongDirection(c bidi.Class) bidi.Class {
+    switch c {
+        case bidi.L:
+            return bidi.L
+        case bidi.R, bidi.AL, bidi.EN, bidi.AN:
+            return bidi.R
+        default:
+            return bidi.ON
+    }
+}
+
+// pairedBracket returns the Bidi_Paired_Bracket of a bracket, treating the
+// canonically equivalent angle brackets U+2329, U+232A and U+3008, U+3009 as
+// the same.
+func pairedBracket(r rune) rune {
+    return canonicalBracket([]rune(bidi.ReverseString(string(r)))[0])
+}
+
+func canonicalBracket(r rune) rune {
+    switch r {
+        case 0x2329: return 0x3008
+        case 0x232A: return 0x3009
+        default:     return r
+    }
+}
+
+// resolveBrackets applies rule N0 to bracket pairs (BD16).
+func (s *sequence) resolveBrackets(runes []rune, original []bidi.Class) {
+    type bracket struct {
+        closing rune // the closing bracket that pairs with an opening bracket
+        position int
+    }
+    type pair struct {
+        open, close int
+    }
+
+    var stack []bracket
+    var pairs []pair
+
+    find:
+    for i := range s.indexes {
+        if s.class(i) != bidi.ON { continue }
+        r := runes[s.indexes[i]]
+        p, _ := bidi.LookupRune(r)
+        if !p.IsBracket() { continue }
+
+        if p.IsOpeningBracket() {
+            if len(stack) == maxBrackets { break find }
+            stack = append(stack, bracket{closing: pairedBracket(r), position: i})
+            continue
+        }
+
+        for j := len(stack) - 1; j >= 0; j-- {
+            if stack[j].closing != canonicalBracket(r) { continue }
+            pairs = append(pairs, pair{open: stack[j].position, close: i})
+            stack = stack[0:j]
+            break
+        }
+    }
+
+    // in the order of their opening brackets
+    for i := 1; i < len(pairs); i++ {
+        for j := i; (j > 0) && (pairs[j].open < pairs[j - 1].open); j-- {
+            pairs[j], pairs[j - 1] = pairs[j - 1], pairs[j]
+        }
+    }
+
+    e := s.embedding()
+    for _, p := range pairs {
+        // the strong directions inside the brackets
+        var found, opposite bool
+        for i := p.open + 1; i < p.close; i++ {
+            d := strongDirection(s.class(i))
+            if d == e { found = true; break }
+            if d != bidi.ON { opposite = true }
+        }
+
+        var d bidi.Class
+        switch {
+            case found: // N0 b
+                d = e
+            case opposite: // N0 c
+                context := s.sos
+                for i := p.open - 1; i >= 0; i-- {
+                    if c := strongDirection(s.class(i)); c != bidi.ON {
+                        context = c
+                        break
+                    }
+                }
+                d = e
+                if context != e { d = context }
+            default: // N0 d
+                continue
+        }
+
+        for _, i := range []int{p.open, p.close} {
+            s.set(i, d)
+            // NSMs following a bracket take its new type
+            for j := i + 1; (j < len(s.indexes)) && (original[s.indexes[j]] == bidi.NSM); j++ {
+                s.set(j, d)
+            }
+        }
+    }
+}
+
+// neutral returns true for the classes of neutral and isolate formatting
+// characters (NI) in rules N1 and N2.
+func neutral(c bidi.Class) bool {
+    switch c {
+        case bidi.B, bidi.S, bidi.WS, bidi.ON, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI:
+            return true
+        default:
+            return false
+    }
+}
+
+// resolveNeutral applies rules N1 and N2.
+func (s *sequence) resolveNeutral() {
+    n := len(s.indexes)
+    for i := 0; i < n; {
+        if !neutral(s.class(i)) { i++; continue }
+        j := i
+        for (j < n) && neutral(s.class(j)) { j++ }
+
+        before := s.sos
+        if i > 0 { before = strongDirection(s.class(i - 1)) }
+        after := s.eos
+        if j < n { after = strongDirection(s.class(j)) }
+
+        d := s.embedding() // N2
+        if before == after { d = before } // N1
+        for k := i; k < j; k++ {
+            s.set(k, d)
+        }
+        i = j
+    }
+}
+
+// resolveImplicit applies rules I1 and I2.
+func (s *sequence) resolveImplicit() {
+    for _, idx := range s.indexes {
+        c := s.classes[idx]
+        if s.levels[idx] % 2 == 0 {
+            switch c {
+                case bidi.R:           s.levels[idx] += 1
+                case bidi.AN, bidi.EN: s.levels[idx] += 2
+            }
+        } else {
+            switch c {
+                case bidi.L, bidi.AN, bidi.EN: s.levels[idx] += 1
+            }
+        }
+    }
+}
+
+// applyTrailingWhitespace resets the level of whitespace at the end of a
+// line, and before segment and paragraph separators, to the paragraph level,
+// as in rule L1.
+func applyTrailingWhitespace(classes []bidi.Class, levels []int, base int) {
+    reset := true
+    for i := len(classes) - 1; i >= 0; i-- {
+        switch classes[i] {
+            case bidi.B, bidi.S:
+                levels[i] = base
+                reset = true
+            case bidi.WS, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI:
+                if reset { levels[i] = base }
+            default:
+                if removed(classes[i]) {
+                    if reset { levels[i] = base }
+                } else {
+                    reset = false
+                }
+        }
+    }
+}
@@ -0,0 +1,190 @@
+package bidi
+
+import (
+    "strings"
+    "unicode/utf8"
+
+    "golang.org/x/text/unicode/bidi"
+)
+
+// Run is a sequence of text with the same direction.
+type Run struct {
+    // Text is the text of the run, in logical order.
+    Text string
+
+    // Offset is the offset, in bytes, of the start of the run in the input.
+    Offset int
+
+    // Level is the embedding level of the run. Even levels are
+    // left-to-right, and odd levels are right-to-left.
+    Level int
+}
+
+// Direction returns the direction of a run, LeftToRight or RightToLeft.
+func (r Run) Direction() Direction {
+    if r.Level % 2 == 0 { return LeftToRight }
+    return RightToLeft
+}
+
+// Runs splits some text into runs of the same direction, in logical order.
+//
+// Each paragraph in the text has its direction resolved separately (see
+// [ParagraphDirection]) using defaultDirection if it has no strong
+// characters. Each paragraph is treated as a single line (see [Reorder]).
+func Runs(s string, defaultDirection Direction) ([]Run, error) {
+    var runs []Run
+    offset := 0
+    for _, paragraph := range paragraphs(s) {
+        levels := resolveLevels(paragraph, defaultDirection)
+        runs = appendRuns(runs, paragraph, offset, levels)
+        offset += len(paragraph)
+    }
+    return runs, nil
+}
+
+// Reorder splits a line of text into runs of the same direction, in visual
+// order (from left to right as displayed).
+//
+// The text of each run remains in logical order. To display a right-to-left
+// run, its text must be reversed (and any brackets mirrored) e.g. with
+// [ReverseString].
+//
+// A paragraph separator, such as "\n", is a run by itself at the paragraph
+// embedding level, and remains at the end of its paragraph, as in rule L1.
+//
+// If the text is too long to display on a single line, it should be broken
+// into lines (e.g. using the line break opportunities from the
+// [golib/v2/text/segment] package) and each line reordered separately.
+//
+// [golib/v2/text/segment]: https://github.com/tawesoft/golib/v2/text/segment
+func Reorder(line string, defaultDirection Direction) ([]Run, error) {
+    var result []Run
+    offset := 0
+    for _, paragraph := range paragraphs(line) {
+        levels := resolveLevels(paragraph, defaultDirection)
+
+        body, separator := cutSeparator(paragraph)
+        n := utf8.RuneCountInString(body)
+        runs := appendRuns(nil, body, offset, levels[0:n])
+        result = append(result, visualOrder(runs)...)
+        if separator != "" {
+            result = append(result, Run{
+                Text:   separator,
+                Offset: offset + len(body),
+                Level:  levels[n],
+            })
+        }
+        offset += len(paragraph)
+    }
+    return result, nil
+}
+
+// Display returns a line of text reordered from logical order to visual
+// order (from left to right as displayed), with right-to-left runs
+// reversed and any brackets in them mirrored. See [Reorder].
+//
+// This is useful for output devices, such as some terminals, that do not
+// implement the Unicode Bidirectional Algorithm themselves. Note that
+// characters are reversed by code point, so combining characters will not be
+// displayed correctly.
+func Display(line string, defaultDirection Direction) (string, error) {
+    runs, err := Reorder(line, defaultDirection)
+    if err != nil { return "", err }
+
+    var sb strings.Builder
+    sb.Grow(len(line))
+    for _, run := range runs {
+        r, _ := utf8.DecodeRuneInString(run.Text)
+        if (run.Direction() == RightToLeft) && (class(r) != bidi.B) {
+            sb.WriteString(ReverseString(run.Text))
+        } else {
+            sb.WriteString(run.Text)
+        }
+    }
+    return sb.String(), nil
+}
+
+// ReverseString reverses the order of characters in s, replacing brackets
+// with their mirrored counterparts (e.g. "(" with ")").
+func ReverseString(s string) string {
+    return bidi.ReverseString(s)
+}
+
+// paragraphs splits s after each paragraph separator (treating "\r\n" as a
+// single separator).
+func paragraphs(s string) []string {
+    var result []string
+    start := 0
+    for i, r := range s {
+        if class(r) != bidi.B { continue }
+        if (r == '\r') && strings.HasPrefix(s[i+1:], "\n") { continue }
+        end := i + utf8.RuneLen(r)
+        result = append(result, s[start:end])
+        start = end
+    }
+    if start < len(s) { result = append(result, s[start:]) }
+    return result
+}
+
+// cutSeparator splits a paragraph from [paragraphs] into its text and its
+// paragraph separator, if any.
+func cutSeparator(paragraph string) (body string, separator string) {
+    r, size := utf8.DecodeLastRuneInString(paragraph)
+    if (size == 0) || (class(r) != bidi.B) { return paragraph, "" }
+    if strings.HasSuffix(paragraph, "\r\n") { size = 2 }
+    return paragraph[0:len(paragraph) - size], paragraph[len(paragraph) - size:]
+}
+
+// appendRuns appends maximal runs of text with the same level.
+func appendRuns(runs []Run, paragraph string, offset int, levels []int) []Run {
+    start, i := 0, 0
+    for pos := range paragraph {
+        if (i > 0) && (levels[i] != levels[i - 1]) {
+            runs = append(runs, Run{
+                Text:   paragraph[start:pos],
+                Offset: offset + start,
+                Level:  levels[i - 1],
+            })
+            start = pos
+        }
+        i++
+    }
+    if start < len(paragraph) {
+        runs = append(runs, Run{
+            Text:   paragraph[start:],
+            Offset: offset + start,
+            Level:  levels[i - 1],
+        })
+    }
+    return runs
+}
+
+// visualOrder reorders runs (from a single line) from logical order to
+// visual order, as in rule L2: from the highest level to the lowest odd
+// level, reverse any contiguous sequence of runs at that level or higher.
+func visualOrder(runs []Run) []Run {
+    if len(runs) == 0 { return runs }
+
+    highest, lowestOdd := 0, -1
+    for _, run := range runs {
+        if run.Level > highest { highest = run.Level }
+        if (run.Level % 2 == 1) && ((lowestOdd < 0) || (run.Level < lowestOdd)) {
+            lowestOdd = run.Level
+        }
+    }
+    if lowestOdd < 0 { return runs }
+
+    result := append([]Run(nil), runs...)
+    for level := highest; level >= lowestOdd; level-- {
+        for i := 0; i < len(result); {
+            if result[i].Level < level { i++; continue }
+            j := i
+            for (j < len(result)) && (result[j].Level >= level) { j++ }
+            for a, b := i, j - 1; a < b; a, b = a + 1, b - 1 {
+                result[a], result[b] = result[b], result[a]
+            }
+            i = j
+        }
+    }
+    return result
+}
@@ -8,7 +8,7 @@ import (
     "time"
 
     "github.com/stretchr/testify/assert"
-    "github.com/tawesoft/golib/v2/internal/test"
+    "github.com/tawesoft/golib/v2/test"
     "github.com/tawesoft/golib/v2/text/ccc"
     "golang.org/x/text/transform"
 )
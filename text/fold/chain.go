@@ -0,0 +1,195 @@
+package fold
+
+import (
+    "sync"
+    "unicode/utf8"
+
+    "github.com/tawesoft/golib/v2/text/ccc"
+    "golang.org/x/text/runes"
+    "golang.org/x/text/transform"
+)
+
+// Folder is a character folding, which can be combined with other folders
+// by [Chain]. Every folding in this package is a Folder, in addition to its
+// own type.
+//
+// Any [transform.Transformer] is a Folder. Chain merges the foldings from
+// this package, and any [runes.Transformer] that maps each rune
+// independently, such as one returned by [runes.Map], into a single pass.
+// Other transformers are applied in sequence, as by [transform.Chain].
+type Folder interface {
+    transform.Transformer
+}
+
+// folder is the implementation of each Folder in this package. It also
+// knows how to fold each rune independently.
+type folder struct {
+    transform.Transformer
+
+    // mapping appends the folding of r to dst, without canonical
+    // reordering.
+    mapping func(dst []rune, r rune) []rune
+
+    // reorder is true if the output of mapping requires canonical
+    // reordering.
+    reorder bool
+}
+
+// asFolder returns f as a folder, if it is from this package or is a
+// [runes.Transformer]. A runes.Transformer is applied to each rune
+// separately.
+func asFolder(f Folder) (folder, bool) {
+    switch x := f.(type) {
+        case folder:
+            return x, true
+        case runes.Transformer:
+            return folder{
+                Transformer: x,
+                mapping: func(dst []rune, r rune) []rune {
+                    s, _, _ := transform.String(x, string(r))
+                    for _, c := range s {
+                        dst = append(dst, c)
+                    }
+                    return dst
+                },
+            }, true
+    }
+    return folder{}, false
+}
+
+// Chain returns a [Folder] that applies each of the given folders in turn, in
+// the order given.
+//
+// Unlike [transform.Chain], which stacks transformers so that the input is
+// processed once for each of them, the returned folder merges the mappings of
+// each folder into one, and applies them all in a single pass. The merged
+// mapping of each distinct rune is computed once, on first use, and cached.
+// This is faster, and allocates less, especially for a chain of many folders.
+//
+// Only the foldings from this package, and a [runes.Transformer], can be
+// merged in this way. Any other transformer is stacked between the merged
+// folders before and after it, as by transform.Chain.
+//
+// The result is the same as applying each folder in sequence, except that
+// any invalid UTF-8 in the input is folded to [utf8.RuneError].
+//
+// The returned folder may be used concurrently, if the folders given here may
+// be used concurrently.
+func Chain(folders ... Folder) Folder {
+    var stacked []transform.Transformer
+    var merged []folder
+    for _, f := range folders {
+        if x, ok := asFolder(f); ok {
+            merged = append(merged, x)
+            continue
+        }
+        if len(merged) > 0 { stacked = append(stacked, merge(merged)) }
+        stacked = append(stacked, f)
+        merged = nil
+    }
+    if (len(merged) > 0) || (len(stacked) == 0) {
+        stacked = append(stacked, merge(merged))
+    }
+
+    if len(stacked) == 1 { return stacked[0] }
+    return transform.Chain(stacked...)
+}
+
+// merge returns a folder that applies the mapping of each folder in turn, in
+// a single pass.
+func merge(folders []folder) folder {
+    c := &chain{folders: folders}
+    var reorder bool
+    for _, f := range folders {
+        if f.reorder { reorder = true }
+    }
+
+    for r := rune(0); r < utf8.RuneSelf; r++ {
+        c.ascii[r] = c.compute(r)
+    }
+
+    t := transform.Transformer(mappingTransformer{c.mapping})
+    if reorder { t = transform.Chain(t, ccc.Transformer) }
+
+    return folder{
+        Transformer: t,
+        mapping:     c.mapping,
+        reorder:     reorder,
+    }
+}
+
+// chain is the merged mapping of several folders.
+type chain struct {
+    folders []folder
+    ascii [utf8.RuneSelf][]rune // precomputed
+    mu sync.RWMutex
+    cache map[rune][]rune // computed on demand
+}
+
+// compute returns the result of applying each folder in turn to r.
+func (c *chain) compute(r rune) []rune {
+    current := []rune{r}
+    var next []rune
+    for _, f := range c.folders {
+        next = next[0:0]
+        for _, x := range current {
+            next = f.mapping(next, x)
+        }
+        current, next = next, current
+    }
+    return append([]rune(nil), current...)
+}
+
+func (c *chain) mapping(dst []rune, r rune) []rune {
+    if (r >= 0) && (r < utf8.RuneSelf) { return append(dst, c.ascii[r]...) }
+
+    c.mu.RLock()
+    result, ok := c.cache[r]
+    c.mu.RUnlock()
+
+    if !ok {
+        result = c.compute(r)
+        c.mu.Lock()
+        if c.cache == nil { c.cache = make(map[rune][]rune) }
+        c.cache[r] = result
+        c.mu.Unlock()
+    }
+
+    return append(dst, result...)
+}
+
+// mappingTransformer applies a per-rune mapping, without reordering.
+type mappingTransformer struct {
+    mapping func(dst []rune, r rune) []rune
+}
+
+func (m mappingTransformer) Reset() {}
+func (m mappingTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+    var buf [32]rune
+
+    for nSrc < len(src) {
+        if !atEOF && !utf8.FullRune(src[nSrc:]) {
+            return nDst, nSrc, transform.ErrShortSrc
+        }
+        r, rZ := utf8.DecodeRune(src[nSrc:])
+
+        output := m.mapping(buf[0:0], r)
+
+        var n int
+        for _, x := range output {
+            if z := utf8.RuneLen(x); z > 0 {
+                n += z
+            } else {
+                n += utf8.RuneLen(utf8.RuneError)
+            }
+        }
+        if len(dst) - nDst < n {
+            return nDst, nSrc, transform.ErrShortDst
+        }
+        for _, x := range output {
+            nDst += utf8.EncodeRune(dst[nDst:], x)
+        }
+        nSrc += rZ
+    }
+    return nDst, nSrc, nil
+}
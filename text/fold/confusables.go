@@ -0,0 +1,116 @@
+package fold
+
+import (
+    "bufio"
+    "bytes"
+    _ "embed"
+    "strconv"
+    "strings"
+    "sync"
+    "unicode/utf8"
+
+    "github.com/tawesoft/golib/v2/must"
+    "golang.org/x/text/transform"
+    "golang.org/x/text/unicode/norm"
+)
+
+// confusablesTxt contains the confusables.txt data file from Unicode
+// Technical Standard #39, in its original format.
+//go:embed confusables.txt
+var confusablesTxt []byte // updated by internal/unicode/getdata.sh
+
+// confusables maps a source code point to its prototype. It is parsed from
+// confusablesTxt on first use.
+var confusables map[rune]string
+var confusablesOnce sync.Once
+
+func loadConfusables() {
+    confusables = make(map[rune]string)
+    scanner := bufio.NewScanner(bytes.NewReader(confusablesTxt))
+    for scanner.Scan() {
+        line, _, _ := strings.Cut(scanner.Text(), "#")
+        fields := strings.Split(line, ";")
+        if len(fields) < 2 { continue }
+
+        source := []rune(parseCodepoints(fields[0]))
+        must.Equalf(len(source), 1, "fold: invalid confusables source %q", fields[0])
+        confusables[source[0]] = parseCodepoints(fields[1])
+    }
+    must.Check(scanner.Err())
+}
+
+// parseCodepoints parses a space-separated list of hexadecimal code points.
+func parseCodepoints(s string) string {
+    var sb strings.Builder
+    for _, field := range strings.Fields(s) {
+        cp, err := strconv.ParseUint(field, 16, 32)
+        must.Checkf(err, "fold: invalid confusables code point %q", field)
+        sb.WriteRune(rune(cp))
+    }
+    return sb.String()
+}
+
+// Confusables is a transformer that produces the confusables "skeleton" of
+// its input, as defined by [Unicode Technical Standard #39: Unicode Security
+// Mechanisms] section 4, "Confusable Detection".
+//
+// Two strings are confusable (for example, "paypal" with a Cyrillic 'а',
+// and "paypal") if they have the same skeleton. This can be used to detect
+// possible spoofing of identifiers such as usernames.
+//
+// The skeleton is intended only for comparison. It is not meant for display,
+// and does not necessarily preserve the meaning of the input. For example,
+// the letter 'm' and the letter pair "rn" have the same skeleton, "rn".
+//
+// The embedded confusables table is from Unicode 13.0.0.
+//
+// [Unicode Technical Standard #39: Unicode Security Mechanisms]: https://www.unicode.org/reports/tr39/
+var Confusables = confusablesFold
+var confusablesFold Folder = folder{
+    Transformer: transform.Chain(
+        norm.NFD,
+        confusablesTransformer{},
+        norm.NFD,
+    ),
+    mapping: func(dst []rune, r rune) []rune {
+        confusablesOnce.Do(loadConfusables)
+        for _, x := range norm.NFD.String(string(r)) {
+            prototype, ok := confusables[x]
+            if !ok {
+                dst = append(dst, x)
+                continue
+            }
+            for _, y := range norm.NFD.String(prototype) {
+                dst = append(dst, y)
+            }
+        }
+        return dst
+    },
+    reorder: true,
+}
+
+// confusablesTransformer replaces each code point with its prototype, if it
+// has one, without any normalisation.
+type confusablesTransformer struct{}
+
+func (confusablesTransformer) Reset() {}
+func (confusablesTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+    confusablesOnce.Do(loadConfusables)
+
+    for nSrc < len(src) {
+        r, rZ := utf8.DecodeRune(src[nSrc:])
+        if (r == utf8.RuneError) && (rZ <= 1) && !atEOF && !utf8.FullRune(src[nSrc:]) {
+            return nDst, nSrc, transform.ErrShortSrc
+        }
+
+        prototype, ok := confusables[r]
+        if !ok { prototype = string(src[nSrc:nSrc+rZ]) }
+
+        if len(dst) - nDst < len(prototype) {
+            return nDst, nSrc, transform.ErrShortDst
+        }
+        nDst += copy(dst[nDst:], prototype)
+        nSrc += rZ
+    }
+    return nDst, nSrc, nil
+}
@@ -2,7 +2,9 @@
 // target. These operations are called character foldings, and can be used
 // to ignore certain distinctions between similar characters.
 //
-// Each folder implements the [transform.Transform] interface.
+// Each folder implements the [transform.Transformer] interface, and is also
+// a [Folder], so that several folders can be combined with [Chain] and
+// applied in a single pass.
 //
 // DISCLAIMER: these folders are based on suggested foldings that appear in
 // withdrawn drafts of Unicode technical reports. They may not be complete.
@@ -23,8 +25,11 @@ import (
     "unicode"
 
     "github.com/tawesoft/golib/v2/operator"
+    "github.com/tawesoft/golib/v2/text/ccc"
     "github.com/tawesoft/golib/v2/text/dm"
     "github.com/tawesoft/golib/v2/text/np"
+    "golang.org/x/text/cases"
+    "golang.org/x/text/language"
     "golang.org/x/text/runes"
     "golang.org/x/text/transform"
 )
@@ -32,20 +37,146 @@ import (
 // Accents is a transformer that removes accents from Latin/Greek/Cyrillic
 // characters.
 var Accents = accents
-var accents = transform.Chain(
-    dm.CD.TransformerWithFilter(func (r rune) bool {
-        return unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic)
-    }),
-    runes.Remove(runes.Predicate(func (r rune) bool {
-        return unicode.Is(unicode.Mn, r)
-    })),
-)
+var accents transform.Transformer = folder{
+    Transformer: transform.Chain(
+        dm.CD.TransformerWithFilter(isLatinGreekCyrillic),
+        runes.Remove(runes.Predicate(isMn)),
+    ),
+    mapping: func(dst []rune, r rune) []rune {
+        start := len(dst)
+        dst = decompose(dm.CD, isLatinGreekCyrillic)(dst, r)
+        out := dst[start:start]
+        for _, x := range dst[start:] {
+            if !isMn(x) { out = append(out, x) }
+        }
+        return dst[0:start + len(out)]
+    },
+    reorder: true,
+}
+
+// AccentsExcept returns a transformer that, like [Accents], removes accents
+// from Latin/Greek/Cyrillic characters, and removes combining marks, except
+// that it preserves any character in one of the given range tables.
+//
+// This is useful because blanket accent folding is too aggressive for some
+// languages. For example, to preserve Hebrew points, and the marks essential
+// to Vietnamese:
+//
+//     fold.AccentsExcept(unicode.Hebrew, fold.VietnameseMarks)
+//
+// A precomposed character in one of the range tables is unchanged. Otherwise,
+// where only some of the accents of a precomposed character are preserved,
+// the result is left decomposed (see [golang.org/x/text/unicode/norm] to
+// recompose it).
+func AccentsExcept(keep ... *unicode.RangeTable) Folder {
+    keep = append([]*unicode.RangeTable(nil), keep...)
+    decomposeCD := decompose(dm.CD, isLatinGreekCyrillic)
+    mapping := func(dst []rune, r rune) []rune {
+        if unicode.In(r, keep...) { return append(dst, r) }
+        start := len(dst)
+        dst = decomposeCD(dst, r)
+        out := dst[start:start]
+        for _, x := range dst[start:] {
+            if !isMn(x) || unicode.In(x, keep...) { out = append(out, x) }
+        }
+        if (len(out) == len(dst) - start) && (len(out) > 1) {
+            // nothing removed, so keep the original precomposed character
+            return append(dst[0:start], r)
+        }
+        return dst[0:start + len(out)]
+    }
+    return folder{
+        Transformer: transform.Chain(mappingTransformer{mapping}, ccc.Transformer),
+        mapping:     mapping,
+        reorder:     true,
+    }
+}
+
+// VietnameseMarks is a range table of the combining marks that are essential
+// to Vietnamese: the circumflex, breve, and horn that form distinct letters,
+// and the grave, acute, tilde, hook above, and dot below that mark tones.
+//
+// See [AccentsExcept].
+var VietnameseMarks = &unicode.RangeTable{
+    R16: []unicode.Range16{
+        {Lo: 0x0300, Hi: 0x0303, Stride: 1}, // grave, acute, circumflex, tilde
+        {Lo: 0x0306, Hi: 0x0309, Stride: 3}, // breve, hook above
+        {Lo: 0x031B, Hi: 0x031B, Stride: 1}, // horn
+        {Lo: 0x0323, Hi: 0x0323, Stride: 1}, // dot below
+    },
+}
+
+func isLatinGreekCyrillic(r rune) bool {
+    return unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic)
+}
+
+func isMn(r rune) bool {
+    return unicode.Is(unicode.Mn, r)
+}
+
+// Case folding performs full Unicode case folding, as defined by the "C" and
+// "F" mappings in the Unicode Character Database file CaseFolding.txt. For
+// example, 'A' folds to 'a', and 'ß' folds to "ss".
+//
+// This is not locale-sensitive. See [CaseFor] for locale-sensitive handling
+// of the dotted and dotless letter I in Turkish and Azerbaijani.
+var Case = caseFold
+var caseFold Folder = folder{
+    Transformer: cases.Fold(),
+    mapping:     caseMapping,
+}
+
+func caseMapping(dst []rune, r rune) []rune {
+    for _, x := range cases.Fold().String(string(r)) {
+        dst = append(dst, x)
+    }
+    return dst
+}
+
+// CaseFor returns a transformer that performs full Unicode case folding, like
+// [Case], but for a specific language.
+//
+// For Turkish and Azerbaijani, this applies the "T" mappings in the Unicode
+// Character Database file CaseFolding.txt, so that 'I' folds to dotless 'ı',
+// and dotted 'İ' folds to 'i'. For any other language, this behaves like
+// [Case].
+//
+// The returned transformer is not safe for concurrent use.
+func CaseFor(t language.Tag) Folder {
+    if !isTurkic(t) {
+        return folder{Transformer: cases.Fold(), mapping: caseMapping}
+    }
+    return folder{
+        Transformer: transform.Chain(runes.Map(turkicI), cases.Fold()),
+        mapping: func(dst []rune, r rune) []rune {
+            return caseMapping(dst, turkicI(r))
+        },
+    }
+}
+
+func isTurkic(t language.Tag) bool {
+    base, _ := t.Base()
+    switch base.String() {
+        case "tr", "az": return true
+        default:         return false
+    }
+}
+
+func turkicI(r rune) rune {
+    switch r {
+        case 0x0049: return 0x0131 // Latin Capital Letter I => Dotless I
+        case 0x0130: return 0x0069 // Capital I with Dot Above => i
+        default:     return r
+    }
+}
 
 // CanonicalDuplicates is a transformer that folds duplicate singletons
 // (usually when the same character, for historical reasons, has two different
 // code points) (e.g. Ohm => Omega)
 var CanonicalDuplicates = canonicalDuplicates
-var canonicalDuplicates = dm.CD.TransformerWithFilter(func (r rune) bool {
+var canonicalDuplicates transform.Transformer = decomposer(dm.CD, isCanonicalDuplicate)
+
+func isCanonicalDuplicate(r rune) bool {
     if operator.In(r,
         0x0374, 0x037E, 0x0387, 0x1FBE,
         0x1FEF, 0x1FFD, 0x2000, 0x2001,
@@ -55,7 +186,7 @@ var canonicalDuplicates = dm.CD.TransformerWithFilter(func (r rune) bool {
     }
     if (r >= 0x2329) && (r <= 0x232A) { return true }
     return false
-})
+}
 
 // Dashes is a transformer that folds everything in Unicode class Pd ("dash
 // punctuation") to hyphen-minus '-'.
@@ -85,7 +216,7 @@ var digits = runes.Map(func(r rune) rune {
 // GreekLetterforms is a transformer that folds alternative Greek letterforms
 // e.g. 'ϐ' to 'β'.
 var GreekLetterforms = greekLetterforms
-var greekLetterforms = dm.KD.TransformerWithFilter(func (r rune) bool {
+var greekLetterforms transform.Transformer = decomposer(dm.KD, func (r rune) bool {
     switch {
         case (r >= 0x03D0) && (r <= 0x03D2): return true
         case (r >= 0x03D5) && (r <= 0x03D6): return true
@@ -98,30 +229,84 @@ var greekLetterforms = dm.KD.TransformerWithFilter(func (r rune) bool {
 // HebrewAlternates is a transformer that folds e.g. wide Hebrew characters
 // to non-wide variants.
 var HebrewAlternates = hebrewAlternates
-var hebrewAlternates = dm.KD.TransformerWithFilter(func (r rune) bool {
+var hebrewAlternates transform.Transformer = decomposer(dm.KD, func (r rune) bool {
     return (r >= 0xFB20) && (r <= 0xFB28)
 })
 
 // Jamo folding converts from the Hangul Compatibility Jamo Unicode block to
 // the Hangul Jamo Unicode block.
 var Jamo = jamo
-var jamo = dm.KD.TransformerWithFilter(func (r rune) bool {
+var jamo transform.Transformer = decomposer(dm.KD, func (r rune) bool {
     return (r >= 0x3131) && (r <= 0x3183)
 })
 
 // Math folding converts font variants, excluding the HebrewAlternates.
 var Math = math
-var math = dm.New(dm.Font).TransformerWithFilter(func (r rune) bool {
+var math transform.Transformer = decomposer(dm.New(dm.Font), func (r rune) bool {
     return (r < 0xFB20) || (r > 0xFB28)
 })
 
 // NoBreak folding converts non-breaking space and non-breaking hyphens.
 var NoBreak = noBreak
-var noBreak = dm.New(dm.NoBreak).Transformer()
+var noBreak transform.Transformer = decomposer(dm.New(dm.NoBreak), nil)
 
 // Positional folding performs positional forms folding including Arabic ligatures.
+//
+// This covers every character in the Arabic Presentation Forms-A (U+FB50 to
+// U+FDFF) and Arabic Presentation Forms-B (U+FE70 to U+FEFF) blocks that has
+// an initial, medial, final, or isolated decomposition mapping in the Unicode
+// Character Database, e.g. 'ﺑ' to 'ب', and the ligature 'ﻻ' to "لا".
+// Characters in those blocks without such a mapping, such as the ornate
+// parentheses, are unchanged.
 var Positional = positional
-var positional = dm.New(dm.Initial, dm.Medial, dm.Final, dm.Isolated).Transformer()
+var positional transform.Transformer = decomposer(dm.New(dm.Initial, dm.Medial, dm.Final, dm.Isolated), nil)
+
+// Punctuation folding converts typographical punctuation to its ASCII
+// equivalent. This is useful, for example, so that a search for "don't" also
+// matches "don’t".
+//
+// This folds single and double "smart" quotation marks to the ASCII
+// apostrophe and quotation mark, e.g. '‘' and '’' to '\'', and '“', '”' and
+// '„' to '"'; prime marks to apostrophes, e.g. '″' to "''"; the ellipsis
+// '…' to "..."; double punctuation such as '‼' to "!!"; and fullwidth ASCII
+// punctuation such as '！' to '!'.
+//
+// Guillemets (e.g. '«'), and dashes (see [Dashes]), are unchanged.
+var Punctuation = punctuation
+var punctuation Folder = folder{
+    Transformer: mappingTransformer{punctuationMapping},
+    mapping:     punctuationMapping,
+}
+
+func punctuationMapping(dst []rune, r rune) []rune {
+    switch r {
+        case 0x2018, 0x2019, 0x201A, 0x201B, 0x2032, 0x2035:
+            return append(dst, '\'')
+        case 0x201C, 0x201D, 0x201E, 0x201F:
+            return append(dst, '"')
+        case 0x2033, 0x2036: return append(dst, '\'', '\'')
+        case 0x2034, 0x2037: return append(dst, '\'', '\'', '\'')
+        case 0x2057:         return append(dst, '\'', '\'', '\'', '\'')
+        case 0x2024:         return append(dst, '.')
+        case 0x2025:         return append(dst, '.', '.')
+        case 0x2026:         return append(dst, '.', '.', '.')
+        case 0x203C:         return append(dst, '!', '!')
+        case 0x2047:         return append(dst, '?', '?')
+        case 0x2048:         return append(dst, '?', '!')
+        case 0x2049:         return append(dst, '!', '?')
+    }
+
+    // Fullwidth ASCII punctuation
+    switch {
+        case (r >= 0xFF01) && (r <= 0xFF0F),
+            (r >= 0xFF1A) && (r <= 0xFF20),
+            (r >= 0xFF3B) && (r <= 0xFF40),
+            (r >= 0xFF5B) && (r <= 0xFF5E):
+            return append(dst, r - 0xFEE0)
+    }
+
+    return append(dst, r)
+}
 
 // Space folding converts all spaces to a single 0x0020 space.
 var Space = space
@@ -134,4 +319,47 @@ var space = runes.Map(func(r rune) rune {
 
 // Small folding converts small variant forms into normal forms.
 var Small = small
-var small = dm.New(dm.Small).Transformer()
+var small transform.Transformer = decomposer(dm.New(dm.Small), nil)
+
+// Width folding converts fullwidth (zenkaku) and halfwidth (hankaku) forms to
+// their canonical widths e.g. fullwidth 'Ａ' to 'A', and halfwidth 'ｶ' to 'カ'.
+//
+// Note that the halfwidth voiced and semi-voiced sound marks fold to their
+// combining forms (U+3099 and U+309A).
+var Width = width
+var width Folder = decomposer(dm.New(dm.Wide, dm.Narrow), nil)
+
+// decomposer returns a folder that applies the decomposition d to each rune
+// r, where filter is nil or filter(r) is true.
+func decomposer(d dm.Decomposer, filter func(r rune) bool) folder {
+    var t transform.Transformer
+    if filter == nil {
+        t = d.Transformer()
+    } else {
+        t = d.TransformerWithFilter(filter)
+    }
+    return folder{
+        Transformer: t,
+        mapping:     decompose(d, filter),
+        reorder:     true,
+    }
+}
+
+// decompose returns a function that appends to dst the full decomposition of
+// r, without canonical reordering, if filter is nil or filter(r) is true.
+// Otherwise, it appends r unchanged.
+func decompose(d dm.Decomposer, filter func(r rune) bool) func(dst []rune, r rune) []rune {
+    var rec func(dst []rune, r rune) []rune
+    rec = func(dst []rune, r rune) []rune {
+        _, m := d.Map(r)
+        if len(m) == 0 { return append(dst, r) }
+        for _, x := range m {
+            dst = rec(dst, x)
+        }
+        return dst
+    }
+    return func(dst []rune, r rune) []rune {
+        if (filter != nil) && !filter(r) { return append(dst, r) }
+        return rec(dst, r)
+    }
+}
@@ -4,10 +4,25 @@ import (
     "io"
     "strings"
     "testing"
+    "unicode"
+    "unicode/utf8"
 
     "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/text/dm"
     "github.com/tawesoft/golib/v2/text/fold"
+    "golang.org/x/text/language"
+    "golang.org/x/text/runes"
     "golang.org/x/text/transform"
+    "golang.org/x/text/unicode/norm"
+)
+
+// The foldings keep their original types, as well as being a [fold.Folder].
+var (
+    _ transform.Transformer = fold.Accents
+    _ transform.Transformer = fold.Positional
+    _ runes.Transformer     = fold.Dashes
+    _ runes.Transformer     = fold.Digits
+    _ runes.Transformer     = fold.Space
 )
 
 func trans(t transform.Transformer, x string) string {
@@ -30,10 +45,44 @@ func Test(t *testing.T) {
         {fold.Accents,              "café",         "cafe"},    // é => e
         {fold.Accents,              "ёёёё",         "ееее"},    // ё => Cyrillic Small Letter Ie
 
+        {fold.AccentsExcept(),      "café",         "cafe"},    // like Accents
+        {fold.AccentsExcept(unicode.Hebrew), "שָׁלוֹם café", "שָׁלוֹם cafe"}, // Hebrew points preserved
+        {fold.Accents,              "שָׁלוֹם",       "שלום"},    // but not by Accents
+        {fold.AccentsExcept(fold.VietnameseMarks), "Tiếng Việt", "Tiếng Việt"}, // same
+        {fold.AccentsExcept(fold.VietnameseMarks), "Đà Nẵng façade", "Đà Nẵng facade"},
+        {fold.AccentsExcept(fold.VietnameseMarks), "ö", "o"},
+        {fold.AccentsExcept(fold.VietnameseMarks), "ǘ", "u\u0301"}, // diaeresis removed, acute kept decomposed
+
+        {fold.Case,                 "",             ""},        // same
+        {fold.Case,                 "café",         "café"},    // same
+        {fold.Case,                 "CAFÉ",         "café"},
+        {fold.Case,                 "Straße",       "strasse"}, // full folding of sharp s
+        {fold.Case,                 "ΣΊΣΥΦΟΣ",      "σίσυφοσ"}, // final sigma is not special
+        {fold.Case,                 "Iİ",           "ii\u0307"},
+        {fold.CaseFor(language.Turkish),     "Iİıi", "ıiıi"},
+        {fold.CaseFor(language.Azerbaijani), "DİYARBAKIR", "diyarbakır"},
+        {fold.CaseFor(language.English),     "Iİ",   "ii\u0307"},
+
         {fold.CanonicalDuplicates,  "",             ""},        // same
         {fold.CanonicalDuplicates,  "café",         "café"},    // same
         {fold.CanonicalDuplicates,  "aΩaé",         "aΩaé"},    // Ohm => Omega
 
+        {fold.Confusables,          "",             ""},        // same
+        {fold.Confusables,          "abc",          "abc"},     // same
+        {fold.Confusables,          "pаypаl",       "paypal"},  // Cyrillic а => Latin a
+        {fold.Confusables,          "ΑΒΟ",          "ABO"},     // Greek capitals => Latin capitals
+        {fold.Confusables,          "I1l|",         "llll"},
+        {fold.Confusables,          "m",            "rn"},
+        {fold.Confusables,          "é",            "e\u0301"}, // decomposed
+        {fold.Confusables,          "ǆ",            "dz\u030C"}, // target is decomposed
+        {fold.Confusables,          "а\u0301",      "a\u0301"}, // combining marks are preserved
+        {fold.Confusables,          "\u05AD",       "\u0596"},  // first entry of confusables.txt
+        {fold.Confusables,          "ﺛ",            "\u0649\u06DB"},
+        {fold.Confusables,          "⿕",            "龠"},
+        {fold.Confusables,          "𝕥𝟳",           "t7"},
+        {fold.Confusables,          "𞣇",            "l"},
+        {fold.Confusables,          "🯹",            "9"},       // new in Unicode 13.0
+
         {fold.Dashes,               "",             ""},        // same
         {fold.Dashes,               "---",          "---"},     // same
         {fold.Dashes,               "a-b-c",        "a-b-c"},   // same
@@ -71,7 +120,27 @@ func Test(t *testing.T) {
         {fold.NoBreak,              "a\u202Fb",     "a b"},     // nnbsp => space
         {fold.NoBreak,              "a\u2011b",     "a\u2010b"}, // non-breaking hyphen => hyphen
 
-        // TODO tests for fold.Positional
+        {fold.Positional,           "",             ""},        // same
+        {fold.Positional,           "café",         "café"},    // same
+        {fold.Positional,           "بيت",          "بيت"},     // same - already nominal forms
+        {fold.Positional,           "ﺏﺑﺒﺐ",         "بببب"},    // isolated, initial, medial, final Beh
+        {fold.Positional,           "ﺑﻴﺖ",          "بيت"},
+        {fold.Positional,           "ﻻ",            "لا"},      // Lam-Alef ligature
+        {fold.Positional,           "ﻵ",            "لآ"},      // Lam-Alef with Madda; Madda is not decomposed
+        {fold.Positional,           "ﷲ",            "الله"},    // Allah ligature
+        {fold.Positional,           "ﷺ",            "صلى الله عليه وسلم"},
+        {fold.Positional,           "ﭐ",            "ٱ"},       // Forms-A Alef Wasla
+        {fold.Positional,           "﴾﴿",           "﴾﴿"},      // same - ornate parentheses have no mapping
+
+        {fold.Punctuation,          "",             ""},        // same
+        {fold.Punctuation,          "don't \"go\"", "don't \"go\""}, // same
+        {fold.Punctuation,          "don’t “go”",   "don't \"go\""}, // smart quotes
+        {fold.Punctuation,          "‚low‘ „low“",  "'low' \"low\""},
+        {fold.Punctuation,          "5′ 11″",       "5' 11''"}, // prime marks
+        {fold.Punctuation,          "wait…",        "wait..."}, // ellipsis
+        {fold.Punctuation,          "what⁈",        "what?!"},
+        {fold.Punctuation,          "（！？：）",    "(!?:)"},   // fullwidth
+        {fold.Punctuation,          "«a—b»",        "«a—b»"},   // same - guillemets and dashes
 
         {fold.Space,                "",             ""},        // Same
         {fold.Space,                "café",         "café"},    // Same
@@ -84,6 +153,15 @@ func Test(t *testing.T) {
         {fold.Small,                "",             ""},        // same
         {fold.Small,                "café",         "café"},    // same
         {fold.Small,                "f",            "f"},       // small f => regular f
+
+        {fold.Width,                "",             ""},        // same
+        {fold.Width,                "café",         "café"},    // same
+        {fold.Width,                "ＡＢＣ１２３",   "ABC123"},  // fullwidth => ASCII
+        {fold.Width,                "a\u3000b",     "a b"},     // ideographic space => space
+        {fold.Width,                "ｶﾀｶﾅ",         "カタカナ"}, // halfwidth => fullwidth katakana
+        {fold.Width,                "ｶﾞ",           "カ\u3099"}, // halfwidth voiced sound mark => combining
+        {fold.Width,                "ﾡ",            "ㄱ"},       // halfwidth => compatibility jamo
+        {fold.Width,                "￦",            "₩"},       // fullwidth won sign
     }
 
     for i, r := range rows {
@@ -92,3 +170,105 @@ func Test(t *testing.T) {
     }
 
 }
+
+func TestChain(t *testing.T) {
+    folders := []fold.Folder{
+        fold.Width,
+        fold.Case,
+        fold.Accents,
+        fold.Dashes,
+        fold.Digits,
+        fold.Space,
+        fold.NoBreak,
+        fold.Punctuation,
+    }
+    stacked := make([]transform.Transformer, 0, len(folders))
+    for _, f := range folders {
+        stacked = append(stacked, f)
+    }
+
+    inputs := []string{
+        "",
+        "Hello, World!",
+        "ＣＡＦÉ　Straße",
+        "a‑b c",
+        "٣٤٥ ①②",
+        "ｶﾞḍ̇",
+        strings.Repeat("Ｔｈｅ Ｑｕｉｃｋ Ｂｒｏｗｎ Ｆｏｘ – ", 500),
+    }
+
+    chained := fold.Chain(folders...)
+    for i, input := range inputs {
+        expected := trans(transform.Chain(stacked...), input)
+        assert.Equal(t, expected, trans(chained, input), "test %d on input %q", i, input)
+    }
+
+    // chains may themselves be chained
+    nested := fold.Chain(fold.Chain(fold.Width, fold.Case), fold.Accents)
+    assert.Equal(t, "cafe", trans(nested, "ＣＡＦÉ"))
+
+    // other transformers that map each rune independently may be chained
+    upper := fold.Chain(runes.Map(unicode.ToUpper), fold.Accents)
+    assert.Equal(t, "CAFE", trans(upper, "café"))
+
+    // any other transformer is applied to the whole input, in sequence
+    composed := fold.Chain(fold.Case, norm.NFC, fold.Space)
+    assert.Equal(t, "\u00E9 e", trans(composed, "E\u0301\u2003E"))
+}
+
+var benchmarkInput = strings.Repeat("Ｔｈｅ Ｑｕｉｃｋ Ｂｒｏｗｎ Ｆｏｘ – Café Straße ", 200)
+
+func BenchmarkChain(b *testing.B) {
+    t := fold.Chain(fold.Width, fold.Case, fold.Accents, fold.Dashes, fold.Space)
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        _, _, _ = transform.String(t, benchmarkInput)
+    }
+}
+
+func BenchmarkChain_stacked(b *testing.B) {
+    t := transform.Chain(fold.Width, fold.Case, fold.Accents, fold.Dashes, fold.Space)
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        _, _, _ = transform.String(t, benchmarkInput)
+    }
+}
+
+// TestPositional_exhaustive checks every character in the Arabic
+// Presentation Forms-A and Forms-B blocks.
+func TestPositional_exhaustive(t *testing.T) {
+    positional := dm.New(dm.Initial, dm.Medial, dm.Final, dm.Isolated)
+    chained := fold.Chain(fold.Positional)
+
+    isPresentationForm := func(r rune) bool {
+        return ((r >= 0xFB50) && (r <= 0xFDFF)) || ((r >= 0xFE70) && (r <= 0xFEFF))
+    }
+
+    var folded int
+    for _, block := range [][2]rune{{0xFB50, 0xFDFF}, {0xFE70, 0xFEFF}} {
+        for r := block[0]; r <= block[1]; r++ {
+            if !utf8.ValidRune(r) || !unicode.IsPrint(r) { continue }
+            input := string(r)
+            output := trans(fold.Positional, input)
+            assert.Equal(t, output, trans(chained, input), "chained output for %U", r)
+
+            switch ty, _ := dm.Map(r); ty {
+                case dm.Initial, dm.Medial, dm.Final, dm.Isolated:
+                    folded++
+                    expected, err := positional.String(input)
+                    assert.Nil(t, err)
+                    assert.Equal(t, expected, output, "output for %U", r)
+                    assert.NotEmpty(t, output, "output for %U", r)
+                    for _, x := range output {
+                        assert.False(t, isPresentationForm(x),
+                            "output for %U contains presentation form %U", r, x)
+                    }
+                default:
+                    assert.Equal(t, input, output, "expected %U to be unchanged", r)
+            }
+        }
+    }
+
+    // 731 positional mappings as of Unicode 13.0.0
+    assert.Equal(t, 731, folded)
+}
@@ -27,11 +27,11 @@ var RulesetNames = func() []string {
     return xs
 }()
 
-// Formatter returns a new [rbnf.Formatter] for a given algorithmic ruleset
+// Formatter returns a new [rbnf.RuleSet] for a given algorithmic ruleset
 // such as "roman-upper".
-func Formatter(name string) (rbnf.Formatter, bool) {
+func Formatter(name string) (rbnf.RuleSet, bool) {
     if !strings.HasPrefix(name, "%") { name = "%" + name }
-    return group.Formatter(name)
+    return group.RuleSet(name)
 }
 
 // Format formats a number using a given algorithmic ruleset such as
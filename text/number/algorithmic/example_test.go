@@ -35,7 +35,7 @@ func Example_rulesetNames() {
     // > armenian-lower; ռմլդ
     // > armenian-upper; ՌՄԼԴ
     // > cyrillic-lower; ҂асл҃д
-    // > ethiopic; ፩፻፪፻፴፬
+    // > ethiopic; ፲፪፻፴፬
     // > georgian; შსლდ
     // > greek-lower; ͵ασλδ´
     // > greek-upper; ͵ΑΣΛΔ´
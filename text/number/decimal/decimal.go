@@ -0,0 +1,228 @@
+// Package decimal formats integers and floating point numbers as decimal
+// digits, with the grouping separator, decimal point, minus sign, and digit
+// shapes appropriate for a locale, such as "1,234.5" in English, "1.234,5" in
+// German, or "١٬٢٣٤٫٥" in Arabic.
+//
+// The symbols come from the [golib/v2/text/number/symbols] package. The
+// digits for each numbering system are validated against the Unicode numeric
+// properties in the [golib/v2/text/np] package (the same data used by
+// [fold.Digits] to fold them back to ASCII).
+//
+// This complements the [golib/v2/text/number/rbnf] package, which formats
+// numbers with rules, e.g. spelled out in words.
+//
+// [golib/v2/text/number/symbols]: https://github.com/tawesoft/golib/v2/text/number/symbols
+// [golib/v2/text/np]: https://github.com/tawesoft/golib/v2/text/np
+// [golib/v2/text/number/rbnf]: https://github.com/tawesoft/golib/v2/text/number/rbnf
+// [fold.Digits]: https://pkg.go.dev/github.com/tawesoft/golib/v2/text/fold#Digits
+package decimal
+
+import (
+    "math"
+    "strconv"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/text/np"
+    "github.com/tawesoft/golib/v2/text/number/symbols"
+    "golang.org/x/text/language"
+)
+
+// Format describes how to format a number as decimal digits. Use [New] to
+// create a Format for a locale. The fields may then be modified as needed.
+type Format struct {
+    // Decimal separates the integer and fractional part of a number.
+    Decimal string
+
+    // Group separates groups of integer digits, e.g. thousands.
+    Group string
+
+    // MinusSign prefixes negative numbers.
+    MinusSign string
+
+    // Infinity and NaN are used for infinite values and "Not a number".
+    Infinity, NaN string
+
+    // Digits are the digit shapes for the values zero to nine.
+    Digits [10]rune
+
+    // GroupSize is the number of digits in the group closest to the decimal
+    // point (the primary grouping size), e.g. 3 for "1,234,567". If zero,
+    // digits are not grouped.
+    GroupSize int
+
+    // SecondaryGroupSize, if not zero, is the number of digits in every
+    // other group, e.g. 2 for "12,34,567".
+    SecondaryGroupSize int
+
+    // MinimumGroupingDigits is the minimum number of digits that must
+    // appear before the first group separator for grouping to be used at
+    // all. For example, with a value of 2, 1234 is formatted as "1234", but
+    // 12345 is formatted as "12,345".
+    MinimumGroupingDigits int
+
+    // MinFractionDigits and MaxFractionDigits are the minimum and maximum
+    // number of digits after the decimal point of a formatted float.
+    MinFractionDigits, MaxFractionDigits int
+}
+
+// New returns a Format for the given locale.
+//
+// The numbering system may be given by the "nu" Unicode extension of the
+// language tag (for example, "en-u-nu-arab"). Otherwise, the locale's default
+// numbering system is used. If the numbering system is unknown or is not a
+// decimal numbering system, falls back to the ASCII digits "0" to "9".
+//
+// By default, digits are grouped in threes, and floats are formatted with at
+// most three fractional digits, as in the CLDR pattern "#,##0.###". Some
+// locales group digits differently: e.g. "hi" (Hindi) uses "#,##,##0.###",
+// and "es" (Spanish) only groups numbers with at least five integer digits.
+// Only a small set of these exceptions are built in.
+func New(tag language.Tag) Format {
+    base, _ := tag.Base()
+    lang := base.String()
+
+    var script, region, variant string
+    if s, confidence := tag.Script(); confidence == language.Exact {
+        script = s.String()
+    }
+    if r, confidence := tag.Region(); confidence == language.Exact {
+        region = r.String()
+    }
+    if vs := tag.Variants(); len(vs) > 0 {
+        variant = vs[0].String()
+    }
+
+    ns := tag.TypeForKey("nu")
+    if ns == "" { ns = defaultNumberingSystem(lang, region) }
+    digits, ok := Digits(ns)
+    if !ok {
+        ns = "latn"
+        digits, _ = Digits(ns)
+    }
+
+    sym := symbols.Get_(lang, script, region, variant, ns)
+    f := Format{
+        Decimal:               sym.Decimal,
+        Group:                 sym.Group,
+        MinusSign:             sym.MinusSign,
+        Infinity:              sym.Infinity,
+        NaN:                   sym.NaN,
+        Digits:                digits,
+        GroupSize:             3,
+        MinimumGroupingDigits: 1,
+        MaxFractionDigits:     3,
+    }
+
+    switch {
+        case secondaryGrouping(lang, region):
+            f.SecondaryGroupSize = 2
+        case minimumGroupingDigits2(lang, region):
+            f.MinimumGroupingDigits = 2
+    }
+
+    return f
+}
+
+// FormatInt formats an integer.
+func (f Format) FormatInt(v int64) string {
+    var sb strings.Builder
+    if v < 0 { sb.WriteString(f.MinusSign) }
+    f.writeInteger(&sb, strconv.FormatUint(absUint64(v), 10))
+    return sb.String()
+}
+
+// FormatFloat formats a floating point number, rounded to at most
+// MaxFractionDigits digits after the decimal point.
+func (f Format) FormatFloat(v float64) string {
+    switch {
+        case math.IsNaN(v):   return f.NaN
+        case math.IsInf(v, 1):  return f.Infinity
+        case math.IsInf(v, -1): return f.MinusSign + f.Infinity
+    }
+
+    s := strconv.FormatFloat(math.Abs(v), 'f', max(0, f.MaxFractionDigits), 64)
+    integer, fraction, _ := strings.Cut(s, ".")
+    for (len(fraction) > f.MinFractionDigits) && strings.HasSuffix(fraction, "0") {
+        fraction = fraction[:len(fraction) - 1]
+    }
+    for len(fraction) < f.MinFractionDigits {
+        fraction += "0"
+    }
+
+    var sb strings.Builder
+    if math.Signbit(v) && ((strings.Trim(integer, "0") != "") || (strings.Trim(fraction, "0") != "")) {
+        sb.WriteString(f.MinusSign)
+    }
+    f.writeInteger(&sb, integer)
+    if len(fraction) > 0 {
+        sb.WriteString(f.Decimal)
+        f.writeDigits(&sb, fraction)
+    }
+    return sb.String()
+}
+
+// writeInteger writes ASCII integer digits with grouping separators applied.
+func (f Format) writeInteger(sb *strings.Builder, digits string) {
+    primary, secondary := f.GroupSize, f.SecondaryGroupSize
+    if secondary <= 0 { secondary = primary }
+
+    if (primary <= 0) || (len(digits) < primary + max(1, f.MinimumGroupingDigits)) {
+        f.writeDigits(sb, digits)
+        return
+    }
+
+    // split into groups, from the right
+    groups := []string{digits[len(digits) - primary:]}
+    digits = digits[:len(digits) - primary]
+    for len(digits) > secondary {
+        groups = append(groups, digits[len(digits) - secondary:])
+        digits = digits[:len(digits) - secondary]
+    }
+    groups = append(groups, digits)
+
+    for i := len(groups) - 1; i >= 0; i-- {
+        f.writeDigits(sb, groups[i])
+        if i > 0 { sb.WriteString(f.Group) }
+    }
+}
+
+// writeDigits writes ASCII digits using the Format's digit shapes.
+func (f Format) writeDigits(sb *strings.Builder, digits string) {
+    for _, c := range digits {
+        sb.WriteRune(f.Digits[c - '0'])
+    }
+}
+
+// Digits returns the digit shapes for the values zero to nine in a CLDR
+// decimal numbering system, such as "latn" (the ASCII digits "0" to "9"),
+// "arab" ("٠" to "٩"), or "deva" ("०" to "९"). Returns false if the numbering
+// system is unknown or is not a decimal numbering system.
+func Digits(numberingSystem string) ([10]rune, bool) {
+    var digits [10]rune
+    numberingSystem = strings.ToLower(numberingSystem)
+
+    if numberingSystem == "hanidec" {
+        copy(digits[:], []rune("〇一二三四五六七八九"))
+        return digits, true
+    }
+
+    zero, ok := numberingSystemZeros[numberingSystem]
+    if !ok { return digits, false }
+
+    for i := range digits {
+        r := zero + rune(i)
+        ty, value := np.Get(r)
+        if (ty != np.Decimal) || (value.Denominator != 1) || (value.Numerator != int64(i)) {
+            return digits, false
+        }
+        digits[i] = r
+    }
+    return digits, true
+}
+
+// absUint64 returns the absolute value of v, which is always representable
+// as an uint64.
+func absUint64(v int64) uint64 {
+    if v < 0 { return uint64(-(v + 1)) + 1 }
+    return uint64(v)
+}
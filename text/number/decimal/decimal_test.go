@@ -0,0 +1,84 @@
+package decimal_test
+
+import (
+    "testing"
+
+    "github.com/tawesoft/golib/v2/text/fold"
+    "github.com/tawesoft/golib/v2/text/number/decimal"
+    "golang.org/x/text/transform"
+)
+
+func TestDigits(t *testing.T) {
+    systems := []string{
+        "adlm", "ahom", "arab", "arabext", "bali", "beng", "bhks", "brah",
+        "cakm", "cham", "deva", "diak", "fullwide", "gong", "gonm", "gujr",
+        "guru", "hmng", "hmnp", "java", "kali", "khmr", "knda", "lana",
+        "lanatham", "laoo", "latn", "lepc", "limb", "mathbold", "mathdbl",
+        "mathmono", "mathsanb", "mathsans", "mlym", "modi", "mong", "mroo",
+        "mtei", "mymr", "mymrshan", "mymrtlng", "newa", "nkoo", "olck", "orya",
+        "osma", "rohg", "saur", "segment", "shrd", "sind", "sinh", "sora",
+        "sund", "takr", "talu", "tamldec", "telu", "thai", "tibt", "tirh",
+        "vaii", "wara", "wcho",
+    }
+    for _, ns := range systems {
+        digits, ok := decimal.Digits(ns)
+        if !ok {
+            t.Errorf("Digits(%q): not ok", ns)
+            continue
+        }
+        folded, _, err := transform.String(fold.Digits, string(digits[:]))
+        if err != nil {
+            t.Errorf("Digits(%q): fold error: %v", ns, err)
+        } else if folded != "0123456789" {
+            t.Errorf("Digits(%q): got %q, folds to %q", ns, string(digits[:]), folded)
+        }
+    }
+
+    if digits, ok := decimal.Digits("hanidec"); !ok || (string(digits[:]) != "〇一二三四五六七八九") {
+        t.Errorf("Digits(%q): got %q, %t", "hanidec", string(digits[:]), ok)
+    }
+
+    for _, ns := range []string{"", "roman", "hans", "unknown"} {
+        if _, ok := decimal.Digits(ns); ok {
+            t.Errorf("Digits(%q): unexpectedly ok", ns)
+        }
+    }
+}
+
+func TestFormat_FormatInt(t *testing.T) {
+    f := decimal.Format{
+        Decimal:               ".",
+        Group:                 ",",
+        MinusSign:             "-",
+        Digits:                [10]rune{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9'},
+        GroupSize:             3,
+        SecondaryGroupSize:    2,
+        MinimumGroupingDigits: 2,
+    }
+
+    type row struct {
+        input int64
+        expected string
+    }
+    rows := []row{
+        {0, "0"},
+        {-1, "-1"},
+        {999, "999"},
+        {1000, "1000"},
+        {9999, "9999"},
+        {10000, "10,000"},
+        {123456, "1,23,456"},
+        {-1234567, "-12,34,567"},
+        {-9223372036854775808, "-92,23,37,20,36,85,47,75,808"},
+    }
+    for _, r := range rows {
+        if got := f.FormatInt(r.input); got != r.expected {
+            t.Errorf("FormatInt(%d): got %q, expected %q", r.input, got, r.expected)
+        }
+    }
+
+    f.GroupSize = 0
+    if got := f.FormatInt(1234567); got != "1234567" {
+        t.Errorf("FormatInt with no grouping: got %q", got)
+    }
+}
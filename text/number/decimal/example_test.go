@@ -0,0 +1,52 @@
+package decimal_test
+
+import (
+    "fmt"
+
+    "github.com/tawesoft/golib/v2/text/number/decimal"
+    "golang.org/x/text/language"
+)
+
+func ExampleNew() {
+    print := func(tag string) {
+        f := decimal.New(language.MustParse(tag))
+        fmt.Printf("%s: %q %q %q\n", tag,
+            f.FormatInt(1234567), f.FormatInt(-1234), f.FormatFloat(-1234.5678))
+    }
+
+    print("en")
+    print("de")
+    print("fr")
+    print("es")
+    print("hi")
+    print("ar")
+    print("ar-MA")
+    print("en-u-nu-deva")
+    print("fa")
+
+    // Output:
+    // en: "1,234,567" "-1,234" "-1,234.568"
+    // de: "1.234.567" "-1.234" "-1.234,568"
+    // fr: "1\u202f234\u202f567" "-1\u202f234" "-1\u202f234,568"
+    // es: "1.234.567" "-1234" "-1234,568"
+    // hi: "12,34,567" "-1,234" "-1,234.568"
+    // ar: "١٬٢٣٤٬٥٦٧" "\u061c-١٬٢٣٤" "\u061c-١٬٢٣٤٫٥٦٨"
+    // ar-MA: "1.234.567" "\u200e-1.234" "\u200e-1.234,568"
+    // en-u-nu-deva: "१,२३४,५६७" "-१,२३४" "-१,२३४.५६८"
+    // fa: "۱٬۲۳۴٬۵۶۷" "\u200e−۱٬۲۳۴" "\u200e−۱٬۲۳۴٫۵۶۸"
+}
+
+func ExampleFormat_FormatFloat() {
+    f := decimal.New(language.English)
+    f.MinFractionDigits = 2
+    f.MaxFractionDigits = 2
+
+    fmt.Println(f.FormatFloat(0.5))
+    fmt.Println(f.FormatFloat(1e6))
+    fmt.Println(f.FormatFloat(-0.001))
+
+    // Output:
+    // 0.50
+    // 1,000,000.00
+    // 0.00
+}
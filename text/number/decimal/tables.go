@@ -0,0 +1,114 @@
+package decimal
+
+// numberingSystemZeros maps CLDR decimal numbering systems, whose digits are
+// contiguous code points, to the digit zero.
+var numberingSystemZeros = map[string]rune{
+    "adlm":     0x1E950,
+    "ahom":     0x11730,
+    "arab":     0x0660,
+    "arabext":  0x06F0,
+    "bali":     0x1B50,
+    "beng":     0x09E6,
+    "bhks":     0x11C50,
+    "brah":     0x11066,
+    "cakm":     0x11136,
+    "cham":     0xAA50,
+    "deva":     0x0966,
+    "diak":     0x11950,
+    "fullwide": 0xFF10,
+    "gong":     0x11DA0,
+    "gonm":     0x11D50,
+    "gujr":     0x0AE6,
+    "guru":     0x0A66,
+    "hmng":     0x16B50,
+    "hmnp":     0x1E140,
+    "java":     0xA9D0,
+    "kali":     0xA900,
+    "khmr":     0x17E0,
+    "knda":     0x0CE6,
+    "lana":     0x1A80,
+    "lanatham": 0x1A90,
+    "laoo":     0x0ED0,
+    "latn":     0x0030,
+    "lepc":     0x1C40,
+    "limb":     0x1946,
+    "mathbold": 0x1D7CE,
+    "mathdbl":  0x1D7D8,
+    "mathmono": 0x1D7F6,
+    "mathsanb": 0x1D7EC,
+    "mathsans": 0x1D7E2,
+    "mlym":     0x0D66,
+    "modi":     0x11650,
+    "mong":     0x1810,
+    "mroo":     0x16A60,
+    "mtei":     0xABF0,
+    "mymr":     0x1040,
+    "mymrshan": 0x1090,
+    "mymrtlng": 0xA9F0,
+    "newa":     0x11450,
+    "nkoo":     0x07C0,
+    "olck":     0x1C50,
+    "orya":     0x0B66,
+    "osma":     0x104A0,
+    "rohg":     0x10D30,
+    "saur":     0xA8D0,
+    "segment":  0x1FBF0,
+    "shrd":     0x111D0,
+    "sind":     0x112F0,
+    "sinh":     0x0DE6,
+    "sora":     0x110F0,
+    "sund":     0x1BB0,
+    "takr":     0x116C0,
+    "talu":     0x19D0,
+    "tamldec":  0x0BE6,
+    "telu":     0x0C66,
+    "thai":     0x0E50,
+    "tibt":     0x0F20,
+    "tirh":     0x114D0,
+    "vaii":     0xA620,
+    "wara":     0x118E0,
+    "wcho":     0x1E2F0,
+}
+
+// defaultNumberingSystem returns the default numbering system for a locale,
+// for the locales where it is not "latn".
+func defaultNumberingSystem(lang string, region string) string {
+    switch lang {
+        case "ar":
+            switch region {
+                case "DZ", "EH", "LY", "MA", "TN": return "latn"
+                default: return "arab"
+            }
+        case "fa", "ps": return "arabext"
+        case "bn":       return "beng"
+        case "mr", "ne": return "deva"
+        case "my":       return "mymr"
+        default:         return "latn"
+    }
+}
+
+// secondaryGrouping returns true for locales that group digits in twos after
+// the first group of three, as in "12,34,567".
+func secondaryGrouping(lang string, region string) bool {
+    switch lang {
+        case "bn", "gu", "hi", "kn", "ml", "mr", "ta", "te":
+            return true
+        case "en":
+            return region == "IN"
+        default:
+            return false
+    }
+}
+
+// minimumGroupingDigits2 returns true for locales that do not group numbers
+// with fewer than five integer digits.
+func minimumGroupingDigits2(lang string, region string) bool {
+    switch lang {
+        case "es", "pl":
+            return true
+        case "pt":
+            return region == "PT"
+        default:
+            return false
+    }
+}
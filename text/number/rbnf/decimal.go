@@ -0,0 +1,90 @@
+package rbnf
+
+import (
+    "math"
+    "strconv"
+    "strings"
+)
+
+// decimalPattern is a minimal subset of an ICU DecimalFormat pattern, such as
+// "#,##0" or "#,##0.00", as it appears in a rule substitution.
+type decimalPattern struct {
+    grouping int // digits per group, or zero for no grouping
+    minInt int   // minimum integer digits
+    minFrac int  // minimum fraction digits
+    maxFrac int  // maximum fraction digits
+}
+
+// parseDecimalPattern parses a decimal format pattern. Only the digits "0"
+// and "#", the grouping separator ",", and the decimal separator "." are
+// understood. Anything else is ignored.
+func parseDecimalPattern(s string) decimalPattern {
+    var p decimalPattern
+    if idx := strings.IndexByte(s, ';'); idx >= 0 { s = s[:idx] }
+
+    integer, fraction, _ := strings.Cut(s, ".")
+    if idx := strings.LastIndexByte(integer, ','); idx >= 0 {
+        p.grouping = strings.Count(integer[idx+1:], "0") + strings.Count(integer[idx+1:], "#")
+    }
+    p.minInt = strings.Count(integer, "0")
+    p.minFrac = strings.Count(fraction, "0")
+    p.maxFrac = p.minFrac + strings.Count(fraction, "#")
+    return p
+}
+
+// group returns the integer digits with a minimum number of digits and
+// grouping separators applied.
+func (p decimalPattern) group(digits string, sym Symbols) string {
+    if len(digits) < p.minInt {
+        digits = strings.Repeat("0", p.minInt - len(digits)) + digits
+    }
+    if (p.grouping <= 0) || (len(digits) <= p.grouping) { return digits }
+
+    var sb strings.Builder
+    first := len(digits) % p.grouping
+    if first > 0 { sb.WriteString(digits[:first]) }
+    for i := first; i < len(digits); i += p.grouping {
+        if i > 0 { sb.WriteString(sym.group()) }
+        sb.WriteString(digits[i:i + p.grouping])
+    }
+    return sb.String()
+}
+
+func (p decimalPattern) formatInt(v int64, sym Symbols) string {
+    return p.formatDigits(absUint64(v), v < 0, sym)
+}
+
+// formatDigits formats an integer given by its absolute value and sign.
+func (p decimalPattern) formatDigits(abs uint64, negative bool, sym Symbols) string {
+    s := p.group(strconv.FormatUint(abs, 10), sym)
+    if p.minFrac > 0 { s += sym.decimal() + strings.Repeat("0", p.minFrac) }
+    if negative { s = "-" + s }
+    return s
+}
+
+func (p decimalPattern) formatFloat(v float64, sym Symbols) string {
+    switch {
+        case math.IsNaN(v):   return "NaN"
+        case math.IsInf(v, 1):  return "∞"
+        case math.IsInf(v, -1): return "-∞"
+    }
+
+    s := strconv.FormatFloat(math.Abs(v), 'f', p.maxFrac, 64)
+    integer, fraction, _ := strings.Cut(s, ".")
+    for (len(fraction) > p.minFrac) && strings.HasSuffix(fraction, "0") {
+        fraction = fraction[:len(fraction) - 1]
+    }
+    if (integer == "0") && (p.minInt == 0) && (len(fraction) > 0) { integer = "" }
+
+    s = p.group(integer, sym)
+    if len(fraction) > 0 { s += sym.decimal() + fraction }
+    if v < 0 { s = "-" + s }
+    return s
+}
+
+// absUint64 returns the absolute value of v, which is always representable
+// as an uint64.
+func absUint64(v int64) uint64 {
+    if v < 0 { return uint64(-(v + 1)) + 1 }
+    return uint64(v)
+}
@@ -0,0 +1,630 @@
+package rbnf
+
+import (
+    "math"
+    "strconv"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/operator"
+    "github.com/tawesoft/golib/v2/operator/checked"
+    "github.com/tawesoft/golib/v2/text/number/plurals"
+    "github.com/tawesoft/golib/v2/text/number/rbnf/internal/body"
+    "github.com/tawesoft/golib/v2/text/number/rbnf/internal/descriptor"
+)
+
+// maxDepth limits how deeply rules may substitute into other rules, so that
+// a rule set that refers to itself without changing the number (e.g.
+// "=%self=") returns an error instead of recursing forever.
+const maxDepth = 64
+
+// Format formats an integer using the rule set. For example, with the CLDR
+// English "%spellout-cardinal" rule set, Format(123) returns "one hundred
+// twenty-three".
+//
+// Rules are selected and applied as described by the ICU
+// RuleBasedNumberFormat documentation (see [New]):
+//
+//   - "←←" formats the number divided by the rule's divisor.
+//   - "→→" formats the remainder of the number divided by the rule's divisor
+//     or, in a negative-number rule, the absolute value of the number.
+//   - "→→→" formats the remainder, like "→→", but with the rule that
+//     precedes this one in the rule set.
+//   - "==" formats the number unchanged.
+//   - Text in square brackets is omitted if the number is an even multiple of
+//     the rule's divisor.
+//   - "$(ordinal,one{st}two{nd}few{rd}other{th})$" formats the text for the
+//     ordinal plural form of the number divided by the rule's divisor, and
+//     "$(cardinal,...)$" likewise for the cardinal plural form (see [New]).
+//
+// As in ICU, if a rule set has no negative-number rule, a negative number is
+// formatted by the rule that would be selected for its absolute value.
+//
+// A substitution may name another rule set (e.g. "←%%and←") or a simple
+// decimal format pattern (e.g. "=#,##0="). Decimal formats use the
+// separators set by [Group.SetSymbols], or by default "," for grouping and "."
+// for the decimal point.
+func (r RuleSet) Format(value int64) (string, error) {
+    if r.rs == nil { return "", ErrNoRule }
+    f := formatter{g: r.g, root: r.rs}
+    if err := f.formatInt(r.rs, value); err != nil { return "", err }
+    return f.sb.String(), nil
+}
+
+// FormatFloat formats a floating point number using the rule set. This is
+// like [RuleSet.Format], but may also use the rule set's special rules:
+//
+//   - "x.x" (improper fraction) and "0.x" (proper fraction) rules, for
+//     numbers with a fractional part. In these rules, "←←" formats the
+//     integral part of the number, and "→→" formats the fractional part.
+//     Text in square brackets in an "x.x" rule is omitted if the number is
+//     between 0 and 1.
+//   - "x.0" (default) rules, for any number.
+//   - "Inf" and "NaN" rules.
+//
+// If "→→" names a fraction rule set - e.g. "→%%frac→" - then the
+// fractional part is formatted as a fraction using the rule in that rule set
+// whose base value is the closest denominator (e.g. "three quarters").
+// Otherwise, the fractional part is formatted one digit at a time, with each
+// digit separated by a space, or with no separator for "→→→".
+//
+// In a fraction rule set, "←←" formats the numerator of the fraction, by
+// default using the rule set that FormatFloat was called on. Text in square
+// brackets is omitted if the numerator is one.
+func (r RuleSet) FormatFloat(value float64) (string, error) {
+    if r.rs == nil { return "", ErrNoRule }
+    f := formatter{g: r.g, root: r.rs}
+    if err := f.formatFloat(r.rs, value); err != nil { return "", err }
+    return f.sb.String(), nil
+}
+
+// formatter holds the state of a single call to a format method.
+type formatter struct {
+    g *Group
+    root *ruleset // default rule set for a fraction rule set
+    sb strings.Builder
+    depth int
+}
+
+func (f *formatter) enter() error {
+    f.depth++
+    if f.depth > maxDepth { return ErrRecursion }
+    return nil
+}
+
+func (f *formatter) leave() {
+    f.depth--
+}
+
+func isNormalRule(rule desc) bool {
+    return operator.In(descriptor.Type(rule.Type),
+        descriptor.TypeBaseValue, descriptor.TypeBaseValueAndRadix)
+}
+
+func isFractionRule(rule desc) bool {
+    return operator.In(descriptor.Type(rule.Type),
+        descriptor.TypeImproperFraction, descriptor.TypeProperFraction,
+        descriptor.TypeDefault)
+}
+
+// tokens returns the tokens of a rule body.
+func (g *Group) tokens(rule desc) []token {
+    return g.bodies[int(rule.BodyIdx) : int(rule.BodyIdx) + int(rule.NumTokens)]
+}
+
+// previousNormalRule returns the index of the normal rule that precedes the
+// rule at index i in a rule set.
+func (rs *ruleset) previousNormalRule(i int) (int, bool) {
+    for i--; i >= 0; i-- {
+        if isNormalRule(rs.descriptors[i]) { return i, true }
+    }
+    return 0, false
+}
+
+// shouldRollBack returns true if, for the given number, the rule that
+// precedes this rule should be used instead.
+//
+// This is the case where the rule has a modulus substitution, its base value
+// is not an even multiple of its divisor, and the number is an even multiple
+// of the rule's divisor. For example, for the rule "50/60: ←← and →→", the
+// number 60 uses the rule before it.
+func (g *Group) shouldRollBack(rule desc, v int64) bool {
+    if (v % rule.Divisor) != 0 { return false }
+    if (rule.Base % rule.Divisor) == 0 { return false }
+    for _, tok := range g.tokens(rule) {
+        ty, _ := decodeTokenType(tok.Type)
+        if operator.In(ty, body.TypeSubstRightArrow, body.TypeTripleRightArrow) {
+            return true
+        }
+    }
+    return false
+}
+
+// findNormalRule returns the index of the rule with the highest base value
+// less than or equal to the absolute value of the number.
+func (f *formatter) findNormalRule(rs *ruleset, v int64) (int, error) {
+    abs := absUint64(v)
+    highestBaseValue := int64(-1)
+    highestIdx := -1
+    for i := 0; i < len(rs.descriptors); i++ {
+        d := rs.descriptors[i]
+        if !isNormalRule(d) || (d.Base < 0) { continue }
+        if (uint64(d.Base) <= abs) && (d.Base > highestBaseValue) {
+            highestBaseValue = d.Base
+            highestIdx = i
+        }
+    }
+    if highestIdx < 0 { return 0, ErrNoRule }
+
+    if f.g.shouldRollBack(rs.descriptors[highestIdx], v) {
+        previous, ok := rs.previousNormalRule(highestIdx)
+        if !ok { return 0, ErrNoRule }
+        return previous, nil
+    }
+    return highestIdx, nil
+}
+
+// formatInt selects a rule from a rule set to format an integer, and applies
+// it.
+func (f *formatter) formatInt(rs *ruleset, v int64) error {
+    if err := f.enter(); err != nil { return err }
+    defer f.leave()
+
+    if rs.fraction { return f.formatFraction(rs, float64(v)) }
+
+    if v < 0 {
+        if idx, ok := rs.findRule(descriptor.TypeNegativeNumber); ok {
+            return f.applyInt(rs, idx, v, false, false)
+        }
+    }
+
+    idx, err := f.findNormalRule(rs, v)
+    if err != nil { return err }
+    return f.applyInt(rs, idx, v, false, false)
+}
+
+// formatNegated is like formatInt, but formats -v instead of v, where v is
+// math.MinInt64, which has no positive int64 counterpart.
+func (f *formatter) formatNegated(rs *ruleset, v int64) error {
+    if err := f.enter(); err != nil { return err }
+    defer f.leave()
+
+    if rs.fraction { return f.formatFraction(rs, -float64(v)) }
+
+    idx, err := f.findNormalRule(rs, v)
+    if err != nil { return err }
+    return f.applyInt(rs, idx, v, false, true)
+}
+
+// applyInt applies the normal or negative-number rule at index i in a rule set
+// to format an integer. If negated is true, the normal rule formats -v
+// instead of v (see [formatter.formatNegated]).
+//
+// If the rule is applied by a "→→→" substitution, bypassing rule selection,
+// then, as in ICU, text in square brackets is never omitted.
+func (f *formatter) applyInt(rs *ruleset, i int, v int64, bypass bool, negated bool) error {
+    rule := rs.descriptors[i]
+    ty := descriptor.Type(rule.Type)
+    normal := isNormalRule(rule)
+    isOptional := false
+
+    substitute := f.substituteInt
+    if negated { substitute = f.substituteNegated }
+
+    for _, tok := range f.g.tokens(rule) {
+        tt, _ := decodeTokenType(tok.Type)
+        switch tt {
+            case body.TypeOptionalStart:
+                isOptional = true
+                continue
+            case body.TypeOptionalEnd:
+                isOptional = false
+                continue
+        }
+
+        if isOptional {
+            // Omit the optional text if the number is an even multiple of
+            // the rule's divisor
+            if !normal { return ErrInvalidState }
+            if !bypass && ((v % rule.Divisor) == 0) { continue }
+        }
+
+        var err error
+        switch {
+            case tt == body.TypeLiteral:
+                f.sb.WriteString(f.g.getString(tok))
+
+            case (tt == body.TypeSubstLeftArrow) && normal:
+                // Divide the number by the rule's divisor and format the
+                // quotient. Like ICU, which formats the quotient as a
+                // floating point number, a decimal format rounds it down.
+                q := v / rule.Divisor
+                _, st := decodeTokenType(tok.Type)
+                if (st == body.SubstTypeDecimalFormat) && ((v % rule.Divisor) < 0) && !negated { q-- }
+                err = substitute(tok, rs, q)
+
+            case (tt == body.TypeSubstRightArrow) && normal:
+                // Divide the number by the rule's divisor and format the
+                // remainder
+                err = substitute(tok, rs, v % rule.Divisor)
+
+            case (tt == body.TypeSubstRightArrow) && (ty == descriptor.TypeNegativeNumber):
+                // Find the absolute value of the number and format the result
+                err = f.substituteNegated(tok, rs, v)
+
+            case (tt == body.TypeTripleRightArrow) && normal:
+                // Divide the number by the rule's divisor and format the
+                // remainder, but bypass the normal rule-selection process and
+                // just use the rule that precedes this one in this rule list.
+                previous, ok := rs.previousNormalRule(i)
+                if !ok { return ErrInvalidState }
+                if err = f.enter(); err != nil { return err }
+                err = f.applyInt(rs, previous, v % rule.Divisor, true, negated)
+                f.leave()
+
+            case (tt == body.TypeSubstEqualsSign) && normal:
+                // Format the number unchanged
+                err = substitute(tok, rs, v)
+
+            case operator.In(tt, body.TypeSubstPluralCardinal, body.TypeSubstPluralOrdinal) && normal:
+                // Select the plural form of the number divided by the
+                // rule's divisor
+                q := strconv.FormatInt(v / rule.Divisor, 10)
+                if negated { q = strconv.FormatUint(absUint64(v / rule.Divisor), 10) }
+                err = f.plural(tok, q)
+
+            default:
+                err = ErrInvalidState
+        }
+        if err != nil { return err }
+    }
+
+    return nil
+}
+
+// plural writes the text of a plural substitution, such as
+// "$(ordinal,one{st}two{nd}few{rd}other{th})$", for the plural form of a
+// number formatted as a decimal string. If there is no text for that plural
+// form, the text for the "other" form is used.
+func (f *formatter) plural(tok token, number string) error {
+    if f.g.pluralRules == nil { return ErrInvalidState }
+
+    tt, _ := decodeTokenType(tok.Type)
+    var form plurals.Form
+    if tt == body.TypeSubstPluralOrdinal {
+        form = f.g.pluralRules.Ordinal(number)
+    } else {
+        form = f.g.pluralRules.Cardinal(number)
+    }
+
+    var text, other string
+    var found, haveOther bool
+    ok := pluralForms(f.g.getString(tok), func(keyword, t string) {
+        if found { return }
+        if keyword == pluralKeywords[form] {
+            text, found = t, true
+        } else if keyword == "other" {
+            other, haveOther = t, true
+        }
+    })
+
+    switch {
+        case !ok:
+            return ErrInvalidState
+        case found:
+            f.sb.WriteString(text)
+        case haveOther:
+            f.sb.WriteString(other)
+        default:
+            return ErrInvalidState
+    }
+    return nil
+}
+
+// pluralForms calls f with the keyword and text of each plural form in plural
+// syntax such as "one{st}two{nd}few{rd}other{th}", in order, and returns false
+// if the syntax is invalid.
+func pluralForms(s string, f func(keyword, text string)) bool {
+    for len(s) > 0 {
+        open := strings.IndexByte(s, '{')
+        end := strings.IndexByte(s, '}')
+        if (open < 0) || (end < open) { return false }
+        f(strings.TrimSpace(s[:open]), s[open+1:end])
+        s = s[end+1:]
+    }
+    return true
+}
+
+// pluralKeywords maps a plural form to its keyword in plural syntax.
+var pluralKeywords = [...]string{
+    plurals.Other: "other",
+    plurals.Zero:  "zero",
+    plurals.One:   "one",
+    plurals.Two:   "two",
+    plurals.Few:   "few",
+    plurals.Many:  "many",
+}
+
+// substituteInt formats an integer using the rule set or decimal format named
+// by a substitution token, or the current rule set if the substitution
+// descriptor is empty.
+func (f *formatter) substituteInt(tok token, current *ruleset, v int64) error {
+    _, st := decodeTokenType(tok.Type)
+    switch st {
+        case body.SubstTypeEmpty:
+            return f.formatInt(current, v)
+        case body.SubstTypeRulesetName:
+            return f.formatInt(&f.g.rulesets[int(tok.Len)], v)
+        case body.SubstTypeDecimalFormat:
+            f.sb.WriteString(parseDecimalPattern(f.g.getString(tok)).formatInt(v, f.g.symbols))
+            return nil
+        default:
+            return ErrInvalidState
+    }
+}
+
+// substituteNegated is like substituteInt, but formats -v instead of v,
+// including where v is math.MinInt64.
+func (f *formatter) substituteNegated(tok token, current *ruleset, v int64) error {
+    if x, ok := checked.Int64.Neg(v); ok { return f.substituteInt(tok, current, x) }
+
+    _, st := decodeTokenType(tok.Type)
+    switch st {
+        case body.SubstTypeEmpty:
+            return f.formatNegated(current, v)
+        case body.SubstTypeRulesetName:
+            return f.formatNegated(&f.g.rulesets[int(tok.Len)], v)
+        case body.SubstTypeDecimalFormat:
+            f.sb.WriteString(parseDecimalPattern(f.g.getString(tok)).formatDigits(absUint64(v), false, f.g.symbols))
+            return nil
+        default:
+            return ErrInvalidState
+    }
+}
+
+// formatFloat selects a rule from a rule set to format a floating point
+// number, and applies it.
+func (f *formatter) formatFloat(rs *ruleset, v float64) error {
+    if err := f.enter(); err != nil { return err }
+    defer f.leave()
+
+    if rs.fraction { return f.formatFraction(rs, v) }
+
+    apply := func(ty descriptor.Type) error {
+        idx, ok := rs.findRule(ty)
+        if !ok { return ErrNoRule }
+        return f.applyFloat(rs, idx, v)
+    }
+
+    if math.IsNaN(v) { return apply(descriptor.TypeNaN) }
+    if v < 0 {
+        if _, ok := rs.findRule(descriptor.TypeNegativeNumber); ok {
+            return apply(descriptor.TypeNegativeNumber)
+        }
+    }
+
+    abs := math.Abs(v)
+    if math.IsInf(abs, 1) { return apply(descriptor.TypeInfinity) }
+
+    if abs != math.Floor(abs) {
+        if _, ok := rs.findRule(descriptor.TypeProperFraction); ok && (abs < 1) {
+            return apply(descriptor.TypeProperFraction)
+        }
+        if _, ok := rs.findRule(descriptor.TypeImproperFraction); ok {
+            return apply(descriptor.TypeImproperFraction)
+        }
+    }
+
+    if _, ok := rs.findRule(descriptor.TypeDefault); ok {
+        return apply(descriptor.TypeDefault)
+    }
+
+    v = math.Round(v)
+    if math.Abs(v) >= math.MaxInt64 { return ErrRange }
+    return f.formatInt(rs, int64(v))
+}
+
+// applyFloat applies the special rule at index i in a rule set to format a
+// floating point number.
+func (f *formatter) applyFloat(rs *ruleset, i int, v float64) error {
+    rule := rs.descriptors[i]
+    ty := descriptor.Type(rule.Type)
+    fraction := isFractionRule(rule)
+    isOptional := false
+
+    for _, tok := range f.g.tokens(rule) {
+        tt, _ := decodeTokenType(tok.Type)
+        switch tt {
+            case body.TypeOptionalStart:
+                isOptional = true
+                continue
+            case body.TypeOptionalEnd:
+                isOptional = false
+                continue
+        }
+
+        if isOptional {
+            switch ty {
+                case descriptor.TypeImproperFraction:
+                    // Omit the optional text if the number is between 0 and 1
+                    if v < 1 { continue }
+                case descriptor.TypeDefault:
+                    // Omit the optional text if the number is an integer
+                    if v == math.Floor(v) { continue }
+                default:
+                    return ErrInvalidState
+            }
+        }
+
+        var err error
+        switch {
+            case tt == body.TypeLiteral:
+                f.sb.WriteString(f.g.getString(tok))
+
+            case (tt == body.TypeSubstLeftArrow) && fraction:
+                // Isolate the number's integral part and format it
+                err = f.substituteFloat(tok, rs, math.Floor(v))
+
+            case (tt == body.TypeSubstRightArrow) && fraction:
+                // Isolate the number's fractional part and format it
+                err = f.substituteFractionalPart(tok, rs, v, true)
+
+            case (tt == body.TypeTripleRightArrow) && fraction:
+                err = f.substituteFractionalPart(tok, rs, v, false)
+
+            case (tt == body.TypeSubstRightArrow) && (ty == descriptor.TypeNegativeNumber):
+                // Find the absolute value of the number and format the result
+                err = f.substituteFloat(tok, rs, -v)
+
+            case (tt == body.TypeSubstEqualsSign) && fraction:
+                // Format the number unchanged
+                err = f.substituteFloat(tok, rs, v)
+
+            case operator.In(tt, body.TypeSubstPluralCardinal, body.TypeSubstPluralOrdinal) && fraction:
+                // Select the plural form of the number
+                err = f.plural(tok, strconv.FormatFloat(v, 'f', -1, 64))
+
+            default:
+                err = ErrInvalidState
+        }
+        if err != nil { return err }
+    }
+
+    return nil
+}
+
+// substituteFloat is like substituteInt, for a floating point number. If the
+// number is an integer, it is formatted as an integer.
+func (f *formatter) substituteFloat(tok token, current *ruleset, v float64) error {
+    if (v == math.Floor(v)) && (v > math.MinInt64) && (v < math.MaxInt64) {
+        return f.substituteInt(tok, current, int64(v))
+    }
+
+    _, st := decodeTokenType(tok.Type)
+    switch st {
+        case body.SubstTypeEmpty:
+            return f.formatFloat(current, v)
+        case body.SubstTypeRulesetName:
+            return f.formatFloat(&f.g.rulesets[int(tok.Len)], v)
+        case body.SubstTypeDecimalFormat:
+            f.sb.WriteString(parseDecimalPattern(f.g.getString(tok)).formatFloat(v, f.g.symbols))
+            return nil
+        default:
+            return ErrInvalidState
+    }
+}
+
+// substituteFractionalPart formats the fractional part of a number, either
+// using a fraction rule set, or by formatting each digit in turn.
+func (f *formatter) substituteFractionalPart(tok token, current *ruleset, v float64, spaces bool) error {
+    _, frac := math.Modf(v)
+
+    rs := current
+    _, st := decodeTokenType(tok.Type)
+    switch st {
+        case body.SubstTypeEmpty:
+            break
+        case body.SubstTypeRulesetName:
+            rs = &f.g.rulesets[int(tok.Len)]
+            if rs.fraction { return f.formatFraction(rs, frac) }
+        case body.SubstTypeDecimalFormat:
+            f.sb.WriteString(parseDecimalPattern(f.g.getString(tok)).formatFloat(frac, f.g.symbols))
+            return nil
+        default:
+            return ErrInvalidState
+    }
+
+    for i, digit := range fractionDigits(v) {
+        if (i > 0) && spaces { f.sb.WriteByte(' ') }
+        if err := f.formatInt(rs, int64(digit - '0')); err != nil { return err }
+    }
+    return nil
+}
+
+// fractionDigits returns the decimal digits after the decimal point in the
+// shortest representation of v.
+func fractionDigits(v float64) string {
+    s := strconv.FormatFloat(math.Abs(v), 'f', -1, 64)
+    if idx := strings.IndexByte(s, '.'); idx >= 0 {
+        return s[idx+1:]
+    }
+    return ""
+}
+
+// formatFraction selects a rule from a fraction rule set to format a number
+// between zero and one, and applies it.
+//
+// For each rule in the list, the number being formatted is multiplied by the
+// rule's base value, and the rule that produces the result closest to an
+// integer is used, or the first such rule in the event of a tie. (The idea
+// here is to try each rule's base value as a possible denominator of a
+// fraction.) If the rule following the matching rule has the same base value,
+// it is used instead if the numerator of the fraction is anything other than
+// 1. This allows singular and plural forms of the rule text.
+func (f *formatter) formatFraction(rs *ruleset, v float64) error {
+    best, bestDistance := -1, math.Inf(1)
+    for i, d := range rs.descriptors {
+        if !isNormalRule(d) || (d.Base <= 0) { continue }
+        x := v * float64(d.Base)
+        distance := math.Abs(x - math.Round(x))
+        if distance < bestDistance {
+            best, bestDistance = i, distance
+            if distance == 0 { break }
+        }
+    }
+    if best < 0 { return ErrNoRule }
+
+    base := rs.descriptors[best].Base
+    numerator := math.Round(v * float64(base))
+    if next := best + 1; (next < len(rs.descriptors)) &&
+        isNormalRule(rs.descriptors[next]) &&
+        (rs.descriptors[next].Base == base) && (numerator != 1) {
+        best = next
+    }
+
+    if numerator >= math.MaxInt64 { return ErrRange }
+    return f.applyFraction(rs, best, int64(numerator))
+}
+
+// applyFraction applies the rule at index i in a fraction rule set, with the
+// given numerator.
+func (f *formatter) applyFraction(rs *ruleset, i int, numerator int64) error {
+    rule := rs.descriptors[i]
+    isOptional := false
+
+    for _, tok := range f.g.tokens(rule) {
+        tt, st := decodeTokenType(tok.Type)
+        switch tt {
+            case body.TypeOptionalStart:
+                isOptional = true
+                continue
+            case body.TypeOptionalEnd:
+                isOptional = false
+                continue
+        }
+
+        // Omit the optional text if multiplying the number by the rule's
+        // base value yields 1.
+        if isOptional && (numerator == 1) { continue }
+
+        var err error
+        switch tt {
+            case body.TypeLiteral:
+                f.sb.WriteString(f.g.getString(tok))
+
+            case body.TypeSubstLeftArrow:
+                // Multiply the number by the rule's base value and format
+                // the result, by default using the default rule set.
+                if st == body.SubstTypeEmpty {
+                    err = f.formatInt(f.root, numerator)
+                } else {
+                    err = f.substituteInt(tok, rs, numerator)
+                }
+
+            default:
+                err = ErrInvalidState
+        }
+        if err != nil { return err }
+    }
+
+    return nil
+}
@@ -232,21 +232,13 @@ func next(s string, start int) (Token, int) {
 
 // possibly consumes "→→→"
 func consumeTripleRightArrow(s string, start int) (Token, int, bool) {
-    seen := 0
     end := start
-    for {
+    for seen := 0; seen < 3; seen++ {
         next, z := decode(s[end:])
+        if next != '→' { return Token{}, 0, false }
         end += z
-        if (z == 0) || (seen >= 3) {
-            if seen == 3 {
-                t := Token{Type: TypeTripleRightArrow}
-                return t, end, true
-            }
-            break
-        }
-        if (next == '→') { seen++ } else { break }
     }
-    return Token{}, 0, false
+    return Token{Type: TypeTripleRightArrow}, end, true
 }
 
 // consumes e.g. " million", stopping at any special sequence
@@ -26,6 +26,7 @@ func Test_consumeDirect(t *testing.T) {
         {"SimpleSubstitution", len("X→"),    "X→→ X",     "",   TypeSubstRightArrow, -2},
         {"SimpleSubstitution", len("X→"),    "X→foo→ X", "foo", TypeSubstRightArrow, -2},
         {"TripleRightArrow",   len("X"),     "X→→→",     "",    TypeTripleRightArrow, 0},
+        {"TripleRightArrow",   len("X"),     "X→→→]",    "",    TypeTripleRightArrow, -1},
 
         {"PluralSubstitution", len("$("),    "$(ordinal,foo)$ X",  "foo", TypeSubstPluralOrdinal, -2},
         {"PluralSubstitution", len("$("),    "$(cardinal,foo)$ X", "foo", TypeSubstPluralCardinal,-2},
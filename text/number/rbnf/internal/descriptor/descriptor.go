@@ -56,20 +56,23 @@ func isAsciiDigitString(x string) bool {
 //   of 10 less than or equal to the base value.
 // * bv/rad: The rule's divisor is the highest power of rad less than or equal to
 //   the base value.
-// * -x: The rule is a negative-number rule.
+// * -x: The rule is a negative-number rule. The minus sign may also be
+//   U+2212 MINUS SIGN "−".
 // * x.x: The rule is an improper fraction rule.
 // * 0.x: The rule is a proper fraction rule.
 // * x.0: The rule is a default rule.
+// * x,x, 0,x, x,0: as above, for locales that use a comma as a decimal
+//   separator.
 // * Inf: The rule for infinity.
 // * NaN: The rule for an IEEE 754 NaN (not a number).
 func Parse(s string) Descriptor {
     const ferr = "invalid rule descriptor syntax %q"
 
     switch s {
-        case "-x":  { return Descriptor{Type: TypeNegativeNumber} }
-        case "x.x": { return Descriptor{Type: TypeImproperFraction} }
-        case "0.x": { return Descriptor{Type: TypeProperFraction} }
-        case "x.0": { return Descriptor{Type: TypeDefault} }
+        case "-x", "−x":   { return Descriptor{Type: TypeNegativeNumber} }
+        case "x.x", "x,x": { return Descriptor{Type: TypeImproperFraction} }
+        case "0.x", "0,x": { return Descriptor{Type: TypeProperFraction} }
+        case "x.0", "x,0": { return Descriptor{Type: TypeDefault} }
         case "Inf": { return Descriptor{Type: TypeInfinity} }
         case "NaN": { return Descriptor{Type: TypeNaN} }
     }
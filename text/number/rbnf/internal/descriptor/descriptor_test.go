@@ -12,6 +12,12 @@ func TestParseRuleDescriptor(t *testing.T) {
     rows := []row{
         {input: "100000",      r: Descriptor{Type: TypeBaseValue,         Base: 100_000, Divisor: 0}},
         {input: "100000/1000", r: Descriptor{Type: TypeBaseValueAndRadix, Base: 100_000, Divisor: 1000}},
+        {input: "-x",          r: Descriptor{Type: TypeNegativeNumber}},
+        {input: "−x",          r: Descriptor{Type: TypeNegativeNumber}},
+        {input: "x.x",         r: Descriptor{Type: TypeImproperFraction}},
+        {input: "x,x",         r: Descriptor{Type: TypeImproperFraction}},
+        {input: "0,x",         r: Descriptor{Type: TypeProperFraction}},
+        {input: "x,0",         r: Descriptor{Type: TypeDefault}},
     }
     for _, test := range rows {
         got := Parse(test.input)
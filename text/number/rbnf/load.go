@@ -0,0 +1,110 @@
+package rbnf
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/internal/unicode/ldml"
+    "github.com/tawesoft/golib/v2/text/number/plurals"
+)
+
+// Load is like [New], but reads the rules from r, which may be either in the
+// text format accepted by New, or a Unicode Common Locale Data Repository
+// (CLDR) XML document such as "common/rbnf/en.xml".
+//
+// For a CLDR XML document, the rule sets of every rule set grouping (e.g.
+// "SpelloutRules", "OrdinalRules") are loaded into the same Group. Private
+// rule sets are given a "%%" prefix, and public rule sets a "%" prefix. The
+// "lenient-parse" rule sets, which do not contain number formatting rules,
+// are skipped.
+//
+// A CLDR XML document may use "<" and ">" in place of "←" and "→", as in
+// older versions of the CLDR. A single rule element may also contain more
+// than one rule (e.g. "0 seconds; 1 second; =0= seconds;"), in which case
+// each rule after the first has a base value one greater than the rule
+// before it, as in ICU.
+func Load(p plurals.Rules, r io.Reader) (*Group, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, fmt.Errorf("error reading rbnf rules: %w", err)
+    }
+
+    if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+        return New(p, string(data))
+    }
+
+    rules, err := cldrRules(data)
+    if err != nil {
+        return nil, fmt.Errorf("error parsing rbnf CLDR XML: %w", err)
+    }
+    return New(p, rules)
+}
+
+// cldrRules converts the rule sets in a CLDR XML document to the text format
+// accepted by [New].
+func cldrRules(data []byte) (string, error) {
+    doc, err := ldml.Parse(data)
+    if err != nil { return "", err }
+    if doc.Type != ldml.DocTypeLdml {
+        return "", fmt.Errorf("expected ldml document, but got %q", doc.Type)
+    }
+
+    arrows := strings.NewReplacer("<", "←", ">", "→")
+
+    var sb strings.Builder
+    for _, grouping := range doc.Ldml.RbnfRulesetGroupings {
+        for _, ruleset := range grouping.Rulesets {
+            if ruleset.Type == "lenient-parse" { continue }
+            if len(ruleset.Rules) == 0 { continue }
+
+            if ruleset.IsPrivate() {
+                sb.WriteString("%%")
+            } else {
+                sb.WriteString("%")
+            }
+            sb.WriteString(ruleset.Type)
+            sb.WriteString(":\n")
+
+            for _, rule := range ruleset.Rules {
+                content := arrows.Replace(strings.TrimSpace(rule.Content))
+                for i, body := range splitRules(content) {
+                    if i > 0 {
+                        // implicit rule descriptor
+                        base, err := strconv.ParseInt(rule.Value, 10, 64)
+                        if err != nil {
+                            return "", fmt.Errorf("ruleset %q: implicit rule descriptor after %q", ruleset.Type, rule.Value)
+                        }
+                        rule.Value = strconv.FormatInt(base + 1, 10)
+                        rule.Radix = ""
+                    }
+                    rule.Content = body
+                    sb.WriteString("    ")
+                    sb.WriteString(rule.IcuStyle())
+                    sb.WriteString("\n")
+                }
+            }
+        }
+    }
+
+    if sb.Len() == 0 {
+        return "", fmt.Errorf("no rule sets found")
+    }
+    return sb.String(), nil
+}
+
+// splitRules splits the content of a CLDR rule element into one or more
+// rule bodies, each terminated by a semicolon.
+func splitRules(content string) []string {
+    var result []string
+    for {
+        body, rest, found := strings.Cut(content, ";")
+        body = strings.TrimLeft(body, " ")
+        if (body != "") || (len(result) == 0) { result = append(result, body + ";") }
+        if !found { break }
+        content = rest
+    }
+    return result
+}
@@ -29,6 +29,7 @@ func (g *Group) parse(s string) error {
     if err := g.parse2(s); err != nil {
         return err
     }
+    g.markFractionRulesets()
     /*if err := g.check(); err != nil {
         return err
     }*/
@@ -160,6 +161,22 @@ func (g *Group) parse2(s string) (retError error) {
     return
 }
 
+// markFractionRulesets marks each rule set named by a fractional part
+// substitution (e.g. "→%%frac→" in a "x.x" rule) as a fraction rule set.
+func (g *Group) markFractionRulesets() {
+    for _, rs := range g.rulesets {
+        for _, d := range rs.descriptors {
+            if !isFractionRule(d) { continue }
+            for _, tok := range g.tokens(d) {
+                ty, sty := decodeTokenType(tok.Type)
+                if (ty == body.TypeSubstRightArrow) && (sty == body.SubstTypeRulesetName) {
+                    g.rulesets[int(tok.Len)].fraction = true
+                }
+            }
+        }
+    }
+}
+
 // parser parses a stream of Unicode runes
 type parser struct {
     r *runeio.Reader
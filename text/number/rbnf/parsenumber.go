@@ -0,0 +1,339 @@
+package rbnf
+
+import (
+    "math"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/tawesoft/golib/v2/operator/checked"
+    "github.com/tawesoft/golib/v2/text/number/rbnf/internal/body"
+    "github.com/tawesoft/golib/v2/text/number/rbnf/internal/descriptor"
+)
+
+// Parse parses text formatted by the rule set, such as "one hundred
+// twenty-three", back into a number. It is the counterpart of
+// [RuleSet.Format].
+//
+// Parsing is lenient: letters are compared case-insensitively, whitespace is
+// ignored, a hyphen or dash that appears in a rule is optional in the input,
+// and a U+2212 MINUS SIGN "−" that appears in a rule also matches a
+// hyphen-minus "-". Numbers formatted by a decimal format substitution (e.g.
+// "=#,##0=") may be written with or without grouping separators.
+//
+// Like ICU's RuleBasedNumberFormat, each substitution that divides the number
+// by a rule's divisor only matches text formatted by a rule with a base
+// value less than that divisor. Where the text can be parsed in more than one
+// way, rules with a higher base value take priority. Only integers are
+// supported, so fraction rules and fraction rule sets are never matched.
+//
+// If the whole of the input cannot be parsed, returns [ErrSyntax].
+func (r RuleSet) Parse(s string) (int64, error) {
+    if r.rs == nil { return 0, ErrNoRule }
+
+    input := make([]rune, 0, len(s))
+    for _, c := range s {
+        if unicode.IsSpace(c) { continue }
+        input = append(input, unicode.ToLower(c))
+    }
+
+    p := numberParser{
+        g: r.g,
+        input: input,
+        memo: make(map[parseKey][]parseMatch),
+    }
+    for _, m := range p.parseRuleset(r.rs, 0, math.MaxInt64) {
+        if m.end == len(input) { return m.value, nil }
+    }
+    if p.exceeded { return 0, ErrRecursion }
+    return 0, ErrSyntax
+}
+
+// parseMatch is one possible way of parsing some input, ending at an offset
+// into the input.
+type parseMatch struct {
+    value int64
+    end int
+}
+
+type parseKey struct {
+    rs *ruleset
+    pos int
+    upperBound int64
+}
+
+// numberParser holds the state of a single call to [RuleSet.Parse].
+type numberParser struct {
+    g *Group
+    input []rune // case folded, with whitespace removed
+    memo map[parseKey][]parseMatch
+    depth int
+    exceeded bool
+}
+
+// parseRuleset returns every way of parsing the input, starting at pos, with
+// a rule from the rule set that has a base value less than upperBound, where
+// an upperBound of math.MaxInt64 means there is no bound. Where there are
+// several ways of parsing the input that end at the same offset, only the
+// one from the rule with the highest base value is returned.
+func (p *numberParser) parseRuleset(rs *ruleset, pos int, upperBound int64) []parseMatch {
+    key := parseKey{rs, pos, upperBound}
+    if matches, ok := p.memo[key]; ok { return matches }
+
+    p.depth++
+    defer func() { p.depth-- }()
+    if p.depth > maxDepth {
+        p.exceeded = true
+        return nil
+    }
+    if rs.fraction { return nil }
+
+    var matches []parseMatch
+    seen := make(map[int]bool)
+    add := func(ms []parseMatch) {
+        for _, m := range ms {
+            if (!below(m.value, upperBound)) || seen[m.end] { continue }
+            seen[m.end] = true
+            matches = append(matches, m)
+        }
+    }
+
+    for i := len(rs.descriptors) - 1; i >= 0; i-- {
+        d := rs.descriptors[i]
+        switch {
+            case isNormalRule(d) && (d.Base < upperBound):
+                add(p.parseRule(rs, i, pos, upperBound))
+            case (descriptor.Type(d.Type) == descriptor.TypeNegativeNumber) &&
+                (upperBound == math.MaxInt64):
+                add(p.parseRule(rs, i, pos, upperBound))
+        }
+    }
+
+    p.memo[key] = matches
+    return matches
+}
+
+// below returns true if v is less than upperBound, or if upperBound is
+// math.MaxInt64, which means there is no bound.
+func below(v int64, upperBound int64) bool {
+    return (v < upperBound) || (upperBound == math.MaxInt64)
+}
+
+// parseState is a partial parse of a rule body.
+type parseState struct {
+    pos int
+    quotient, remainder, same int64
+    hasQuotient, hasRemainder, hasSame bool
+}
+
+// parseRule returns every way of parsing the input, starting at pos, with the
+// normal or negative-number rule at index i in a rule set.
+func (p *numberParser) parseRule(rs *ruleset, i int, pos int, upperBound int64) []parseMatch {
+    rule := rs.descriptors[i]
+    tokens := p.g.tokens(rule)
+
+    // A rule with optional text is parsed both with, and without, it.
+    var optional bool
+    for _, tok := range tokens {
+        if tt, _ := decodeTokenType(tok.Type); tt == body.TypeOptionalStart {
+            optional = true
+        }
+    }
+
+    var matches []parseMatch
+    variants := []bool{true}
+    if optional { variants = []bool{true, false} }
+
+    for _, withOptional := range variants {
+        states := []parseState{{pos: pos}}
+        inOptional := false
+
+        for _, tok := range tokens {
+            tt, _ := decodeTokenType(tok.Type)
+            switch tt {
+                case body.TypeOptionalStart:
+                    inOptional = true
+                    continue
+                case body.TypeOptionalEnd:
+                    inOptional = false
+                    continue
+            }
+            if inOptional && !withOptional { continue }
+
+            var next []parseState
+            for _, state := range states {
+                next = append(next, p.parseToken(rs, i, tok, state, upperBound)...)
+            }
+            states = next
+            if len(states) == 0 { break }
+        }
+
+        for _, state := range states {
+            value, ok := composeRuleValue(rule, state)
+            if !ok { continue }
+
+            // The optional text is omitted when formatting an even multiple
+            // of the rule's divisor, so should not be present when parsing.
+            if optional && withOptional && isNormalRule(rule) &&
+                ((value % rule.Divisor) == 0) { continue }
+
+            matches = append(matches, parseMatch{value: value, end: state.pos})
+        }
+    }
+
+    return matches
+}
+
+// parseToken returns every way of advancing a partial parse of a rule body by
+// parsing one token.
+func (p *numberParser) parseToken(rs *ruleset, i int, tok token, state parseState, upperBound int64) []parseState {
+    rule := rs.descriptors[i]
+    tt, _ := decodeTokenType(tok.Type)
+
+    var results []parseState
+    with := func(ms []parseMatch, f func(s *parseState, v int64)) []parseState {
+        for _, m := range ms {
+            s := state
+            s.pos = m.end
+            f(&s, m.value)
+            results = append(results, s)
+        }
+        return results
+    }
+    setQuotient  := func(s *parseState, v int64) { s.quotient, s.hasQuotient = v, true }
+    setRemainder := func(s *parseState, v int64) { s.remainder, s.hasRemainder = v, true }
+    setSame      := func(s *parseState, v int64) { s.same, s.hasSame = v, true }
+
+    normal := isNormalRule(rule)
+    switch {
+        case tt == body.TypeLiteral:
+            if end, ok := p.matchLiteral(state.pos, p.g.getString(tok)); ok {
+                state.pos = end
+                return []parseState{state}
+            }
+            return nil
+
+        case (tt == body.TypeSubstLeftArrow) && normal:
+            return with(p.substitute(tok, rs, state.pos, rule.Divisor), setQuotient)
+
+        case (tt == body.TypeSubstRightArrow) && normal:
+            return with(p.substitute(tok, rs, state.pos, rule.Divisor), setRemainder)
+
+        case (tt == body.TypeSubstRightArrow) && !normal:
+            return with(p.substitute(tok, rs, state.pos, upperBound), setSame)
+
+        case (tt == body.TypeTripleRightArrow) && normal:
+            previous, ok := rs.previousNormalRule(i)
+            if !ok { return nil }
+            return with(p.parseRule(rs, previous, state.pos, rule.Divisor), setRemainder)
+
+        case (tt == body.TypeSubstEqualsSign) && normal:
+            return with(p.substitute(tok, rs, state.pos, upperBound), setSame)
+
+        case tt == body.TypeSubstPluralCardinal, tt == body.TypeSubstPluralOrdinal:
+            // Accept the text of any plural form
+            pluralForms(p.g.getString(tok), func(_, text string) {
+                if end, ok := p.matchLiteral(state.pos, text); ok {
+                    s := state
+                    s.pos = end
+                    results = append(results, s)
+                }
+            })
+            return results
+
+        default:
+            return nil
+    }
+}
+
+// composeRuleValue returns the number represented by a complete parse of a
+// rule body.
+func composeRuleValue(rule desc, s parseState) (int64, bool) {
+    if descriptor.Type(rule.Type) == descriptor.TypeNegativeNumber {
+        if (!s.hasSame) || (s.same < 0) { return 0, false }
+        return -s.same, true
+    }
+
+    if s.hasSame { return s.same, true }
+
+    value := rule.Base
+    if s.hasQuotient {
+        if s.quotient < 1 { return 0, false }
+        v, ok := checked.Int64.Mul(s.quotient, rule.Divisor)
+        if !ok { return 0, false }
+        value = v
+    }
+    if s.hasRemainder {
+        if (s.remainder < 0) || (s.remainder >= rule.Divisor) { return 0, false }
+        value = value - (value % rule.Divisor) + s.remainder
+    }
+    return value, true
+}
+
+// substitute returns every way of parsing the input, starting at pos, using
+// the rule set or decimal format named by a substitution token, or the
+// current rule set if the substitution descriptor is empty.
+func (p *numberParser) substitute(tok token, current *ruleset, pos int, upperBound int64) []parseMatch {
+    _, st := decodeTokenType(tok.Type)
+    switch st {
+        case body.SubstTypeEmpty:
+            return p.parseRuleset(current, pos, upperBound)
+        case body.SubstTypeRulesetName:
+            return p.parseRuleset(&p.g.rulesets[int(tok.Len)], pos, upperBound)
+        case body.SubstTypeDecimalFormat:
+            if m, ok := p.parseDigits(pos); ok && below(m.value, upperBound) {
+                return []parseMatch{m}
+            }
+            return nil
+        default:
+            return nil
+    }
+}
+
+// parseDigits parses a non-negative decimal integer, starting at pos, with
+// optional grouping separators between digits.
+func (p *numberParser) parseDigits(pos int) (parseMatch, bool) {
+    var value int64
+    var digits int
+    end := pos
+    sep, _ := utf8.DecodeRuneInString(p.g.symbols.group())
+    sep = unicode.ToLower(sep)
+    for i := pos; i < len(p.input); i++ {
+        c := p.input[i]
+        if (c == sep) && (digits > 0) { continue }
+        if (c < '0') || (c > '9') { break }
+
+        v, ok := checked.Int64.Mul(value, 10)
+        if !ok { return parseMatch{}, false }
+        v, ok = checked.Int64.Add(v, int64(c - '0'))
+        if !ok { return parseMatch{}, false }
+        value = v
+        digits++
+        end = i + 1
+    }
+    return parseMatch{value: value, end: end}, digits > 0
+}
+
+// matchLiteral matches literal rule text against the input, starting at pos,
+// and returns the offset of the end of the match.
+func (p *numberParser) matchLiteral(pos int, literal string) (int, bool) {
+    for _, c := range literal {
+        if unicode.IsSpace(c) { continue }
+        c = unicode.ToLower(c)
+
+        var next rune = -1
+        if pos < len(p.input) { next = p.input[pos] }
+
+        switch {
+            case c == next:
+                pos++
+            case (c == '−') && (next == '-'):
+                pos++
+            case unicode.Is(unicode.Pd, c):
+                // optional hyphen or dash
+                if unicode.Is(unicode.Pd, next) { pos++ }
+            default:
+                return 0, false
+        }
+    }
+    return pos, true
+}
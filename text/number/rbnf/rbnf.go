@@ -12,12 +12,15 @@
 // This must be handled at a higher layer.
 //
 // This package does not store any rules directly. You will have to obtain
-// these from the Unicode Common Locale Data Repository (CLDR), or other
-// sources, or define your own. Some rules CLDR rules for non-decimal number
-// systems are implemented at [golib/v2/text/number/algorithmic].
+// these from the Unicode Common Locale Data Repository (CLDR) (see [Load]),
+// or other sources, or define your own. Some rules CLDR rules for non-decimal
+// number systems are implemented at [golib/v2/text/number/algorithmic], and
+// spell-out rules for some common locales are implemented at
+// [golib/v2/text/number/spellout].
 //
 // Rule-Based Number Format (RBNF): https://unicode.org/reports/tr35/tr35-numbers.html#6-rule-based-number-formatting
 // [golib/v2/text/number/algorithmic]: https://github.com/tawesoft/golib/v2/text/number/algorithmic
+// [golib/v2/text/number/spellout]: https://github.com/tawesoft/golib/v2/text/number/spellout
 //
 // ## Security model
 //
@@ -49,10 +52,8 @@ import (
     "strings"
 
     "github.com/tawesoft/golib/v2/must"
-    "github.com/tawesoft/golib/v2/operator"
     "github.com/tawesoft/golib/v2/operator/checked"
     "github.com/tawesoft/golib/v2/text/number/plurals"
-    "github.com/tawesoft/golib/v2/text/number/rbnf/internal/body"
     "github.com/tawesoft/golib/v2/text/number/rbnf/internal/descriptor"
 )
 
@@ -65,6 +66,31 @@ type Group struct {
     rulesetNames map[string]int // index into rulesets
     stringData string
     bodies []token
+    symbols Symbols
+}
+
+// Symbols are the locale-specific symbols used to format a number with a
+// decimal format pattern in a rule substitution (e.g. "=#,##0=").
+type Symbols struct {
+    Decimal string // decimal separator, or "." if empty
+    Group string   // grouping separator, or "," if empty
+}
+
+func (s Symbols) decimal() string {
+    if s.Decimal == "" { return "." }
+    return s.Decimal
+}
+
+func (s Symbols) group() string {
+    if s.Group == "" { return "," }
+    return s.Group
+}
+
+// SetSymbols sets the symbols used to format numbers with a decimal format
+// pattern. These should usually match the locale that the rule sets apply
+// to. SetSymbols must not be called concurrently with any other method.
+func (g *Group) SetSymbols(s Symbols) {
+    g.symbols = s
 }
 
 // RulesetNames returns a slice of the names of the public rulesets in a group,
@@ -80,35 +106,53 @@ func (g *Group) RulesetNames() []string {
     return result
 }
 
-type Formatter struct {
+// RuleSet formats numbers using a specific named rule set from a [Group].
+type RuleSet struct {
     rs *ruleset
     g *Group
 }
 
-// Formatter returns a Formatter that uses a specific named ruleset from
-// a group to format numbers.
-func (g *Group) Formatter(name string) (Formatter, bool) {
+// RuleSet returns the public rule set with the given name, including the
+// leading "%" e.g. "%spellout-cardinal".
+func (g *Group) RuleSet(name string) (RuleSet, bool) {
     rs, ok := g.getPublicRuleset(name)
     if !ok {
-        return Formatter{}, false
+        return RuleSet{}, false
     }
-    return Formatter{
+    return RuleSet{
         rs: rs,
         g: g,
     }, true
 }
 
-func (f Formatter) FormatInteger(v int64) (string, error) {
-    return f.g.formatIntegerUsingRuleset(f.rs, v)
+// Formatter is an alias of [RuleSet].
+//
+// Deprecated: use [RuleSet].
+type Formatter = RuleSet
+
+// Formatter returns a Formatter that uses a specific named ruleset from
+// a group to format numbers.
+//
+// Deprecated: use [Group.RuleSet].
+func (g *Group) Formatter(name string) (Formatter, bool) {
+    return g.RuleSet(name)
+}
+
+// FormatInteger is an alias of [RuleSet.Format].
+//
+// Deprecated: use [RuleSet.Format].
+func (r RuleSet) FormatInteger(v int64) (string, error) {
+    return r.Format(v)
 }
 
 type ruleset struct {
     descriptors []desc
+    fraction bool // true if referenced by a fractional part substitution
 }
 
 type desc struct {
     Base int64
-    Divisor int64
+    Divisor int64 // the rule's divisor, computed from its base and radix
     NumTokens uint8
     Type int8
     BodyIdx uint16
@@ -127,9 +171,10 @@ type token struct {
 // and ordinals) used e.g. in spelling out "1st", "2nd", "3rd" or "1 cat",
 // "2 cats", etc. If the ruleset does not contain any rules that use the
 // cardinal syntax ("$(cardinal,plural syntax)$)") or ordinal syntax
-// ("$(ordidinal,plural syntax)$)") then you may simply pass a nil Plural
-// If specified, the methods implemented by the plural argument should
-// usually match the same locale that the ruleset applies to.
+// ("$(ordinal,plural syntax)$)") then you may simply pass a nil Plural.
+// Otherwise, formatting such a rule returns [ErrInvalidState]. If specified,
+// the methods implemented by the plural argument should usually match the
+// same locale that the ruleset applies to.
 //
 // The rules string contains one or more rule sets in the format described by
 // the International Components for Unicode (ICU) software implementations
@@ -141,17 +186,17 @@ type token struct {
 //   may be omitted. In this implementation, the name is always required.
 // * In the ICU implementations, a rule descriptor may be left out and have
 //   an implicit meaning depending on the previous rule. In this implementation,
-//   rule descriptors are always required (in any case, this doesn't appear
-//   in the data files, regardless).
+//   rule descriptors are always required (but see [Load], which accepts
+//   implicit rule descriptors in CLDR XML documents).
 // * The ICU API documentation does not specify if a rule set name may appear
 //   twice. In this implementation, this is treated as an error.
 // * Only the following rule descriptors are supported (those not supported
 //   do not seem to appear in the data files, regardless): "bv", "bv/rad",
-//   "-x", "x.x", "0.x", "x.0", "Inf", "NaN".
-// * For "x.x", "0.x", "x.0" rules, replacing the dot with a comma is not
-//   supported (this does not seem to appear in the data files, regardless).
-//   Note that this does not mean numbers cannot be *formatted* using commas,
-//   only that they can not appear this way in a rule descriptor.
+//   "-x" (or "−x" with U+2212 MINUS SIGN), "x.x", "0.x", "x.0", "Inf", "NaN".
+// * For "x.x", "0.x", "x.0" rules, the dot may be replaced with a comma e.g.
+//   "x,x". ICU selects between the two variants based on the decimal
+//   separator of the locale. In this implementation, the first such rule in
+//   a rule set is always used.
 //
 // Also note that a rule set is an ordered set.
 //
@@ -209,9 +254,17 @@ func (g *Group) addRuleDescriptor(rs *ruleset, str string) *desc {
         panic("too much rule body data in ruleset group")
     }
     d := descriptor.Parse(str)
+    divisor := int64(1)
+    switch d.Type {
+        case descriptor.TypeBaseValue:
+            divisor = highestPower(10, d.Base)
+        case descriptor.TypeBaseValueAndRadix:
+            if d.Divisor < 1 { panic(fmt.Errorf("invalid radix in %q", str)) }
+            divisor = highestPower(d.Divisor, d.Base)
+    }
     rs.descriptors = append(rs.descriptors, desc{
         Base:      d.Base,
-        Divisor:   d.Divisor,
+        Divisor:   divisor,
         Type:      int8(d.Type),
         BodyIdx:   uint16(len(g.bodies)),
     })
@@ -223,315 +276,43 @@ func (g *Group) getString(tok token) string {
     return g.stringData[int(tok.Left) : int(tok.Left) + int(tok.Len)]
 }
 
-func (g *Group) getRule(rs *ruleset, condition func(d desc) bool) (desc, bool) {
+// findRule returns the index of the first rule in a rule set with the given
+// descriptor type.
+func (rs *ruleset) findRule(ty descriptor.Type) (int, bool) {
     for i := 0; i < len(rs.descriptors); i++ {
-        d := rs.descriptors[i]
-        if condition(d) {
-            return rs.descriptors[i], true
-        }
+        if descriptor.Type(rs.descriptors[i].Type) == ty { return i, true }
     }
-    return desc{}, false
+    return 0, false
 }
 
-// Errors returned by the Format methods.
+// highestPower returns the highest power of radix less than or equal to base,
+// or 1 if there is no such power greater than one.
+func highestPower(radix, base int64) int64 {
+    result := int64(1)
+    if radix < 2 { return result }
+    for {
+        next, ok := checked.Int64.Mul(result, radix)
+        if (!ok) || (next > base) { return result }
+        result = next
+    }
+}
+
+// Errors returned by the Format and Parse methods.
 var (
     ErrRange  = errors.New("value out of range")
     ErrNoRule = errors.New("no rule for this input")
     ErrNotImplemented = errors.New("rule logic not implemented for this input")
     ErrInvalidState = errors.New("invalid rule state")
+    ErrRecursion = errors.New("rule recursion limit exceeded")
+    ErrSyntax = errors.New("invalid syntax")
 )
 
+// FormatInteger formats an integer using the public rule set with the given
+// name, including the leading "%". If the rule set does not exist, this
+// function panics.
 func (g *Group) FormatInteger(rulesetName string, v int64) (string, error) {
-    rs := must.Ok(g.getPublicRuleset(rulesetName))
-    return g.formatIntegerUsingRuleset(rs, v)
-}
-
-func (g *Group) formatIntegerUsingRuleset(rs *ruleset, v int64) (string, error) {
-    var sb strings.Builder
-    if err := g.formatInteger(&sb, rs, v, true); err == nil {
-        return sb.String(), nil
-    } else {
-        return "", err
-    }
-}
-
-func (g *Group) formatInteger(sb *strings.Builder, rs *ruleset, v int64, isRegular bool) error {
-    if isRegular { // If the rule set is a regular rule set, do the following:
-
-        // If the rule set includes a default rule (and the number was passed in as a
-        // double), use the default rule. (If the number being formatted was passed in
-        // as a long, the default rule is ignored.)
-        // == ignored
-
-        // If the number is negative, use the negative-number rule.
-        if v < 0 {
-            rule, ok := g.getRule(rs, func(d desc) bool {
-                return descriptor.Type(d.Type) == descriptor.TypeNegativeNumber
-            })
-            if !ok { return ErrNoRule }
-            return g.applyIntegerRule(sb, rs, rule, v, isRegular)
-        }
-
-        // If the number has a fractional part and is greater than 1, use the
-        // improper fraction rule.
-        // ...
-
-        // If the number has a fractional part and is between 0 and
-        // 1, use the proper fraction rule.
-        // ...
-
-        // Binary-search the rule list for the rule with the highest base value
-        // less than or equal to the number.
-        // TODO for now this is a linear search
-        highestBaseValue := int64(-1)
-        highestIdx := -1
-        previousIdx := -1
-        for i := 0; i < len(rs.descriptors); i++ {
-            d := rs.descriptors[i]
-
-            if !operator.In(descriptor.Type(d.Type),
-                descriptor.TypeBaseValue, descriptor.TypeBaseValueAndRadix) { continue }
-
-            if (d.Base <= v) && (d.Base > highestBaseValue) {
-                highestBaseValue = d.Base
-                highestIdx = i
-                previousIdx = i-1
-            }
-        }
-        if highestIdx < 0 { return ErrNoRule }
-        rule := rs.descriptors[highestIdx]
-
-        // If that rule has two substitutions, its base value is not an even
-        // multiple of its divisor, and the number is an even multiple of the
-        // rule's divisor, use the rule that precedes it in the rule list.
-        // Otherwise, use the rule itself.
-        if (previousIdx >= 0) && (rule.Divisor > 0) {
-            hasTwoSubs := func(r desc) bool {
-                count := 0
-                for i := 0; i < int(rule.NumTokens); i++ {
-                    tok := g.bodies[int(rule.BodyIdx) + i]
-                    _, st := decodeTokenType(tok.Type)
-                    if body.SubstType(st) != body.SubstTypeNone {
-                        count++
-                    }
-                }
-                return count == 2
-            }
-
-            // base value is an even multiple?
-            baseValueIsEvenMultiple := (rule.Base % rule.Divisor) == 0
-            numberIsEvenMultuple := (v % rule.Divisor) == 0
-            if hasTwoSubs(rule) && (!baseValueIsEvenMultiple) && numberIsEvenMultuple {
-                rule = rs.descriptors[previousIdx]
-            }
-        }
-        return g.applyIntegerRule(sb, rs, rule, v, isRegular)
-    }
-
-    /*
-    If the rule set is a fraction rule set, do the following:
-
-    Ignore negative-number and fraction rules.
-
-    For each rule in the list, multiply the number being formatted (which will
-    always be between 0 and 1) by the rule's base value. Keep track of the distance
-    between the result the nearest integer.
-
-    Use the rule that produced the result closest to zero in the above calculation.
-    In the event of a tie or a direct hit, use the first matching rule encountered.
-    (The idea here is to try each rule's base value as a possible denominator of a
-    fraction. Whichever denominator produces the fraction closest in value to the
-    number being formatted wins.) If the rule following the matching rule has the
-    same base value, use it if the numerator of the fraction is anything other than
-    1; if the numerator is 1, use the original matching rule. (This is to allow
-    singular and plural forms of the rule text without a lot of extra hassle.)
-    */
-    return nil
-}
-
-func isNormalRule(rule desc) bool {
-    return operator.In(descriptor.Type(rule.Type),
-        descriptor.TypeBaseValue, descriptor.TypeBaseValueAndRadix)
-}
-
-func divisor(rule desc, v int64) int64 {
-    for {
-        switch descriptor.Type(rule.Type) {
-            case descriptor.TypeBaseValue:
-                // The rule's divisor is the highest power of 10 less than or equal to the base value.
-                if v == 0 { return 1 }
-                digits := int(math.Log10(float64(v))) // e.g. 900 => 2.95 => 2
-                return int64(0.5 + math.Pow10(digits)) // e.g. 2 => 10^2 => 100
-            case descriptor.TypeBaseValueAndRadix:
-                // The rule's divisor is the highest power of rad less than or equal to the base value.
-                if v == 0 { return 1 }
-                n := rule.Divisor
-                highest := n
-                for {
-                    if x, ok := checked.Int64.Mul(n, n); ok {
-                        n = x
-                    } else {
-                        return highest
-                    }
-                    if n >= rule.Base {
-                        return highest
-                    }
-                    highest = n
-                }
-                return highest
-            default:
-                must.Never()
-        }
-    }
-}
-
-func (g *Group) selectRuleset(t token, current *ruleset) (*ruleset, error) {
-    ty, sty := decodeTokenType(t.Type)
-    switch sty {
-        case body.SubstTypeEmpty:
-            // You can't have an empty substitution descriptor with a == substitution.
-            if ty == body.TypeSubstEqualsSign {
-                return nil, ErrInvalidState
-            }
-            /*
-            Perform the mathematical operation on the number, and format the result using the rule set containing the current rule, except:
-            TODO
-            If you omit the substitution descriptor in a >> substitution in a fraction rule, format the result one digit at a time using the rule set containing the current rule.
-            If you omit the substitution descriptor in a << substitution in a rule in a fraction rule set, format the result using the default rule set for this formatter.
-             */
-            return current, nil
-        case body.SubstTypeNone:
-            return current, nil
-        case body.SubstTypeDecimalFormat:
-            return nil, ErrNotImplemented
-        case body.SubstTypeRulesetName:
-            return &(g.rulesets[int(t.Len)]), nil
-        default:
-            return nil, ErrInvalidState
-    }
-}
-
-func (g *Group) applyIntegerRule(sb *strings.Builder, rs *ruleset, rule desc, v int64, isRegular bool) error {
-    isOptional := false
-
-    for i := 0; i < int(rule.NumTokens); i++ {
-        tok := g.bodies[int(rule.BodyIdx) + i]
-
-        ty, _ := decodeTokenType(tok.Type)
-        if ty == body.TypeOptionalEnd {
-            isOptional = false
-            continue
-        } else if isOptional {
-            switch descriptor.Type(rule.Type) {
-                case descriptor.TypeBaseValue: fallthrough
-                case descriptor.TypeBaseValueAndRadix:
-                    // Omit the optional text if the number is
-                    // an even multiple of the rule's divisor
-                    d := divisor(rule, v)
-                    if (v % d) == 0 { continue }
-                case descriptor.TypeNegativeNumber:
-                    return ErrInvalidState
-                case descriptor.TypeProperFraction:
-                    return ErrInvalidState
-                case descriptor.TypeDefault:
-                    // Omit the optional text if the number is an integer (same
-                    // as specifying both an x.x rule and an x.0 rule)
-                    continue
-                case descriptor.TypeImproperFraction:
-                    // Omit the optional text if the number is between 0 and 1
-                    // (same as specifying both an x.x rule and a 0.x rule)
-                    if (v == 0) || (v == 1) { continue }
-                default:
-                    if !isRegular {
-                        // Omit the optional text if multiplying the number by
-                        // the rule's base value yields 1.
-                        if rule.Base * v == 1 { continue }
-                    }
-            }
-        }
-
-        switch ty {
-            case body.TypeOptionalStart:
-                isOptional = true
-            case body.TypeLiteral:
-                sb.WriteString(g.getString(tok))
-
-            case body.TypeSubstLeftArrow:
-                if !isRegular {
-                    // Multiply the number by the rule's base value and
-                    // format the result.
-                    n, ok := checked.Int64.Mul(v, rule.Base)
-                    if !ok { return ErrRange }
-                    err := g.formatInteger(sb, rs, n, isRegular)
-                    if err != nil { return err }
-                }
-                switch descriptor.Type(rule.Type) {
-                    case descriptor.TypeBaseValue: fallthrough
-                    case descriptor.TypeBaseValueAndRadix:
-                        // Divide the number by the rule's divisor and format
-                        // the quotient
-                        n := v / divisor(rule, v)
-                        nrs, err := g.selectRuleset(tok, rs)
-                        if err != nil { return err }
-                        err = g.formatInteger(sb, nrs, n, isRegular)
-                        if err != nil { return err }
-
-                    case descriptor.TypeNegativeNumber:
-                        return ErrInvalidState
-                    case descriptor.TypeDefault: fallthrough
-                    case descriptor.TypeProperFraction: fallthrough
-                    case descriptor.TypeImproperFraction:
-                        // Isolate the number's integral part and format it.
-                        // Here its already integer part.
-                        return ErrInvalidState
-                    default:
-                        return ErrInvalidState
-                }
-
-            case body.TypeSubstRightArrow:
-                switch descriptor.Type(rule.Type) {
-                    case descriptor.TypeBaseValue: fallthrough
-
-                    case descriptor.TypeBaseValueAndRadix:
-                        // Divide the number by the rule's divisor and format
-                        // the remainder
-                        n := v % divisor(rule, v)
-                        nrs, err := g.selectRuleset(tok, rs)
-                        if err != nil { return err }
-                        err = g.formatInteger(sb, nrs, n, isRegular)
-                        if err != nil { return err }
-
-                    case descriptor.TypeNegativeNumber:
-                        abs, ok := checked.Int64.Abs(v)
-                        if !ok { return ErrRange }
-                        nrs, err := g.selectRuleset(tok, rs)
-                        if err != nil { return err }
-                        err = g.formatInteger(sb, nrs, abs, isRegular)
-                        if err != nil { return err }
-
-                    case descriptor.TypeDefault: fallthrough
-                    case descriptor.TypeProperFraction:
-                        // Isolate the number's fractional part and format it.
-                        return ErrNotImplemented
-
-                    // in rule in fraction rule set: Not allowed.
-
-                    // other???
-                    default: return ErrInvalidState
-                }
-
-            case body.TypeSubstEqualsSign:
-                // Format the number unchanged
-                nrs, err := g.selectRuleset(tok, rs)
-                if err != nil { return err }
-                err = g.formatInteger(sb, nrs, v, isRegular)
-                if err != nil { return err }
-
-            // other???
-            default:
-                return ErrNotImplemented
-        }
-    }
-
-    return nil
+    return RuleSet{
+        rs: must.Ok(g.getPublicRuleset(rulesetName)),
+        g: g,
+    }.Format(v)
 }
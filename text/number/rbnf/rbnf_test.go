@@ -1,9 +1,15 @@
 package rbnf
 
 import (
+    "errors"
+    "math"
+    "sort"
+    "strings"
     "testing"
 
     "github.com/tawesoft/golib/v2/must"
+    "github.com/tawesoft/golib/v2/text/number/plurals"
+    "golang.org/x/text/language"
 )
 
 func TestNew(t *testing.T) {
@@ -80,3 +86,455 @@ func TestNew(t *testing.T) {
 
     // Output:
 }
+
+func TestRuleSet_Format(t *testing.T) {
+    g := must.Result(New(nil, `
+        %spellout-cardinal:
+            -x: minus →→;
+            x.x: ←← point →→;
+            Inf: infinite;
+            NaN: not a number;
+            0: zero;
+            1: one;
+            2: two;
+            3: three;
+            4: four;
+            5: five;
+            6: six;
+            7: seven;
+            8: eight;
+            9: nine;
+            10: ten;
+            11: eleven;
+            12: twelve;
+            13: thirteen;
+            14: fourteen;
+            15: fifteen;
+            16: sixteen;
+            17: seventeen;
+            18: eighteen;
+            19: nineteen;
+            20: twenty[-→→];
+            30: thirty[-→→];
+            40: forty[-→→];
+            50: fifty[-→→];
+            60: sixty[-→→];
+            70: seventy[-→→];
+            80: eighty[-→→];
+            90: ninety[-→→];
+            100: ←← hundred[ →→];
+            1000: ←← thousand[ →→];
+            1000000: ←← million[ →→];
+            1000000000: ←← billion[ →→];
+            1000000000000: ←← trillion[ →→];
+            1000000000000000: ←← quadrillion[ →→];
+            1000000000000000000: =#,##0=;
+        %fraction:
+            -x: minus →→;
+            x.x: ←← and →%%frac→;
+            0.x: →%%frac→;
+            0: =%spellout-cardinal=;
+        %%frac:
+            2: ←%spellout-cardinal← half;
+            2: ←%spellout-cardinal← halves;
+            3: ←%spellout-cardinal← third[s];
+            4: ←%spellout-cardinal← quarter[s];
+        %digits:
+            x.x: ←←.→→→;
+            0: 0;
+            1: 1;
+            2: 2;
+            3: 3;
+            4: 4;
+            5: 5;
+            6: 6;
+            7: 7;
+            8: 8;
+            9: 9;
+            10: ←←→→;
+        %triple:
+            0: none;
+            1: one of =%spellout-cardinal=;
+            10: ten and →→→;
+        %hours:
+            0: =%spellout-cardinal= minutes;
+            60/60: ←%spellout-cardinal← hours[ and →→];
+        %decimal:
+            0: =0.00=;
+        %big:
+            -x: minus →→;
+            0: =#,##0=;
+            1000000000000000000: ←%spellout-cardinal← quintillion[ →→];
+        %loop:
+            0: =%loop=;
+    `))
+
+    type row struct {
+        ruleset string
+        input   int64
+        expected string
+        err error
+    }
+    rows := []row{
+        {"%spellout-cardinal", 0, "zero", nil},
+        {"%spellout-cardinal", 25, "twenty-five", nil},
+        {"%spellout-cardinal", 100, "one hundred", nil},
+        {"%spellout-cardinal", 25000, "twenty-five thousand", nil},
+        {"%spellout-cardinal", 999999, "nine hundred ninety-nine thousand nine hundred ninety-nine", nil},
+        {"%spellout-cardinal", -1000001, "minus one million one", nil},
+        {"%spellout-cardinal", 1234567890123456789, "1,234,567,890,123,456,789", nil},
+        {"%spellout-cardinal", -9223372036854775808, "minus 9,223,372,036,854,775,808", nil},
+        {"%big", -9223372036854775808, "minus nine quintillion 223,372,036,854,775,808", nil},
+        {"%big", -9223372036854775807, "minus nine quintillion 223,372,036,854,775,807", nil},
+        {"%digits", 1234, "1234", nil},
+        {"%digits", -9223372036854775808, "9223372036854775808", nil},
+        {"%triple", 10, "ten and one of zero", nil},
+        {"%triple", 13, "ten and one of three", nil},
+        {"%hours", 59, "fifty-nine minutes", nil},
+        {"%hours", 61, "one hours and one minutes", nil},
+        {"%hours", 120, "two hours", nil},
+        {"%decimal", 12, "12.00", nil},
+        {"%loop", 1, "", ErrRecursion},
+    }
+
+    for _, r := range rows {
+        got, err := must.Ok(g.RuleSet(r.ruleset)).Format(r.input)
+        if !errors.Is(err, r.err) {
+            t.Errorf("%s.Format(%d): got error %v, expected %v", r.ruleset, r.input, err, r.err)
+        } else if got != r.expected {
+            t.Errorf("%s.Format(%d): got %q, expected %q", r.ruleset, r.input, got, r.expected)
+        }
+    }
+
+    type floatRow struct {
+        ruleset string
+        input   float64
+        expected string
+    }
+    floatRows := []floatRow{
+        {"%spellout-cardinal", 3, "three"},
+        {"%spellout-cardinal", 1.25, "one point two five"},
+        {"%spellout-cardinal", 0.5, "zero point five"},
+        {"%spellout-cardinal", -2.5, "minus two point five"},
+        {"%spellout-cardinal", math.Inf(1), "infinite"},
+        {"%spellout-cardinal", math.NaN(), "not a number"},
+        {"%fraction", 0.5, "one half"},
+        {"%fraction", 0.75, "three quarters"},
+        {"%fraction", 2.25, "two and one quarter"},
+        {"%fraction", 1.0/3.0, "one third"},
+        {"%fraction", -0.666, "minus two thirds"},
+        {"%digits", 3.14, "3.14"},
+    }
+
+    for _, r := range floatRows {
+        got, err := must.Ok(g.RuleSet(r.ruleset)).FormatFloat(r.input)
+        if err != nil {
+            t.Errorf("%s.FormatFloat(%g): unexpected error %v", r.ruleset, r.input, err)
+        } else if got != r.expected {
+            t.Errorf("%s.FormatFloat(%g): got %q, expected %q", r.ruleset, r.input, got, r.expected)
+        }
+    }
+
+    if _, ok := g.RuleSet("%%frac"); ok {
+        t.Errorf("expected private rule set to be unavailable")
+    }
+}
+
+func TestLoad(t *testing.T) {
+    const xmlRules = `<?xml version="1.0" encoding="UTF-8" ?>
+<ldml>
+    <identity><language type="en"/></identity>
+    <rbnf>
+        <rulesetGrouping type="SpelloutRules">
+            <ruleset type="lenient-parse" access="private">
+                <rbnfrule value="0">&amp;[last primary ignorable ] &lt;&lt; ' ';</rbnfrule>
+            </ruleset>
+            <ruleset type="units" access="private">
+                <rbnfrule value="0">zero;</rbnfrule>
+                <rbnfrule value="1">one;</rbnfrule>
+                <rbnfrule value="2">two;</rbnfrule>
+            </ruleset>
+            <ruleset type="binary">
+                <rbnfrule value="-x">minus &gt;&gt;;</rbnfrule>
+                <rbnfrule value="0">=%%units=;</rbnfrule>
+                <rbnfrule value="2" radix="2">←← →→</rbnfrule>
+            </ruleset>
+        </rulesetGrouping>
+        <rulesetGrouping type="OrdinalRules">
+            <ruleset type="digits">
+                <rbnfrule value="0">=#,##0=;</rbnfrule>
+            </ruleset>
+        </rulesetGrouping>
+        <rulesetGrouping type="DurationRules">
+            <ruleset type="with-words">
+                <rbnfrule value="0">0 seconds; 1 second; =0= seconds;</rbnfrule>
+                <rbnfrule value="60" radix="60">←%%min←[, →→];</rbnfrule>
+                <rbnfrule value="3600" radix="60">←%%hr←[, →→→];</rbnfrule>
+            </ruleset>
+            <ruleset type="min" access="private">
+                <rbnfrule value="0">0 minutes; 1 minute; =0= minutes;</rbnfrule>
+            </ruleset>
+            <ruleset type="hr" access="private">
+                <rbnfrule value="0">0 hours; 1 hour; =0= hours;</rbnfrule>
+            </ruleset>
+        </rulesetGrouping>
+    </rbnf>
+</ldml>`
+
+    const textRules = `
+        %%units:
+            0: zero;
+            1: one;
+            2: two;
+        %binary:
+            -x: minus →→;
+            0: =%%units=;
+            2/2: ←← →→;
+        %digits:
+            0: =#,##0=;
+        %with-words:
+            0: 0 seconds;
+            1: 1 second;
+            2: =0= seconds;
+            60/60: ←%%min←[, →→];
+            3600/60: ←%%hr←[, →→→];
+        %%min:
+            0: 0 minutes;
+            1: 1 minute;
+            2: =0= minutes;
+        %%hr:
+            0: 0 hours;
+            1: 1 hour;
+            2: =0= hours;
+    `
+
+    for _, input := range []string{xmlRules, textRules} {
+        g, err := Load(nil, strings.NewReader(input))
+        if err != nil {
+            t.Fatalf("unexpected error: %v", err)
+        }
+
+        names := g.RulesetNames()
+        sort.Strings(names)
+        if strings.Join(names, ",") != "binary,digits,with-words" {
+            t.Errorf("got rule set names %v", names)
+        }
+
+        if got := must.Result(g.FormatInteger("%binary", -6)); got != "minus one one zero" {
+            t.Errorf("got %q", got)
+        }
+        if got := must.Result(g.FormatInteger("%digits", 12345)); got != "12,345" {
+            t.Errorf("got %q", got)
+        }
+
+        // as in ICU, "→→→" never omits the optional text of the previous rule
+        durations := []struct {
+            input int64
+            expected string
+        }{
+            {1, "1 second"},
+            {121, "2 minutes, 1 second"},
+            {7200, "2 hours"},
+            {7201, "2 hours, 0 minutes, 1 second"},
+            {7260, "2 hours, 1 minute, 0 seconds"},
+        }
+        for _, d := range durations {
+            if got := must.Result(g.FormatInteger("%with-words", d.input)); got != d.expected {
+                t.Errorf("with-words(%d): got %q, expected %q", d.input, got, d.expected)
+            }
+        }
+    }
+
+    if _, err := Load(nil, strings.NewReader(`<ldml></ldml>`)); err == nil {
+        t.Errorf("expected error for document without rule sets")
+    }
+}
+
+func TestRuleSet_Format_plurals(t *testing.T) {
+    rules := `
+        %digits-ordinal:
+            −x: −→→;
+            x,x: =#,##0.#=;
+            0: =#,##0=$(ordinal,one{st}two{nd}few{rd}other{th})$;
+        %cats:
+            0: no cats;
+            1: =#,##0= $(cardinal,one{cat}other{cats})$;
+    `
+
+    g := must.Result(New(plurals.New(language.English), rules))
+    digitsOrdinal := must.Ok(g.RuleSet("%digits-ordinal"))
+    cats := must.Ok(g.RuleSet("%cats"))
+
+    type row struct {
+        rs RuleSet
+        input int64
+        expected string
+    }
+    rows := []row{
+        {digitsOrdinal, 0, "0th"},
+        {digitsOrdinal, 1, "1st"},
+        {digitsOrdinal, 2, "2nd"},
+        {digitsOrdinal, 3, "3rd"},
+        {digitsOrdinal, 4, "4th"},
+        {digitsOrdinal, 11, "11th"},
+        {digitsOrdinal, 12, "12th"},
+        {digitsOrdinal, 13, "13th"},
+        {digitsOrdinal, 21, "21st"},
+        {digitsOrdinal, 102, "102nd"},
+        {digitsOrdinal, 1003, "1,003rd"},
+        {digitsOrdinal, -22, "−22nd"},
+        {cats, 0, "no cats"},
+        {cats, 1, "1 cat"},
+        {cats, 2, "2 cats"},
+    }
+    for _, r := range rows {
+        got, err := r.rs.Format(r.input)
+        if err != nil {
+            t.Errorf("Format(%d): unexpected error %v", r.input, err)
+        } else if got != r.expected {
+            t.Errorf("Format(%d): got %q, expected %q", r.input, got, r.expected)
+        }
+    }
+
+    if got := must.Result(digitsOrdinal.FormatFloat(1.5)); got != "1.5" {
+        t.Errorf("FormatFloat(1.5): got %q", got)
+    }
+
+    // without plural rules
+    g = must.Result(New(nil, rules))
+    if _, err := must.Ok(g.RuleSet("%cats")).Format(2); !errors.Is(err, ErrInvalidState) {
+        t.Errorf("expected ErrInvalidState without plural rules, got %v", err)
+    }
+}
+
+func TestGroup_SetSymbols(t *testing.T) {
+    g := must.Result(New(nil, `
+        %digits:
+            x.x: =#,##0.0#=;
+            0: =#,##0=º;
+    `))
+    g.SetSymbols(Symbols{Decimal: ",", Group: "."})
+    digits := must.Ok(g.RuleSet("%digits"))
+
+    rows := []struct {
+        input int64
+        expected string
+    }{
+        {999, "999º"},
+        {1500, "1.500º"},
+        {1500000, "1.500.000º"},
+    }
+    for _, r := range rows {
+        if got := must.Result(digits.Format(r.input)); got != r.expected {
+            t.Errorf("Format(%d): got %q, expected %q", r.input, got, r.expected)
+        }
+    }
+
+    if got := must.Result(digits.FormatFloat(12345.5)); got != "12.345,5" {
+        t.Errorf("FormatFloat(12345.5): got %q", got)
+    }
+    if got, err := digits.Parse("1.500.000º"); (err != nil) || (got != 1500000) {
+        t.Errorf("Parse: got %d, %v", got, err)
+    }
+}
+
+func TestRuleSet_Parse(t *testing.T) {
+    g := must.Result(New(plurals.New(language.English), `
+        %spellout-numbering:
+            -x: minus →→;
+            0: =%spellout-cardinal=;
+        %spellout-cardinal:
+            −x: minus →→;
+            0: zero;
+            1: one;
+            2: two;
+            3: three;
+            4: four;
+            5: five;
+            6: six;
+            7: seven;
+            8: eight;
+            9: nine;
+            10: ten;
+            11: eleven;
+            12: twelve;
+            13: thirteen;
+            14: fourteen;
+            15: fifteen;
+            16: sixteen;
+            17: seventeen;
+            18: eighteen;
+            19: nineteen;
+            20: twenty[-→→];
+            30: thirty[-→→];
+            40: forty[-→→];
+            50: fifty[-→→];
+            60: sixty[-→→];
+            70: seventy[-→→];
+            80: eighty[-→→];
+            90: ninety[-→→];
+            100: ←← hundred[ →→];
+            1000: ←← thousand[ →→];
+            1000000: ←← million[ →→];
+            1000000000: ←← billion[ →→];
+            1000000000000: ←← trillion[ →→];
+            1000000000000000: ←← quadrillion[ →→];
+            1000000000000000000: =#,##0=;
+        %digits-ordinal:
+            −x: −→→;
+            0: =#,##0=$(ordinal,one{st}two{nd}few{rd}other{th})$;
+        %loop:
+            0: =%loop=;
+    `))
+    cardinal := must.Ok(g.RuleSet("%spellout-cardinal"))
+
+    // round trip
+    inputs := []int64{
+        0, 1, 9, 10, 19, 20, 21, 99, 100, 101, 110, 999, 1000, 1001, 1100,
+        12_345, 100_000, 999_999, 1_000_000, 7_000_007, -42,
+        999_999_999_999_999_999, 1_000_000_000_000_000_000, math.MaxInt64,
+        -math.MaxInt64,
+    }
+    for _, input := range inputs {
+        s := must.Result(cardinal.Format(input))
+        got, err := cardinal.Parse(s)
+        if err != nil {
+            t.Errorf("Parse(%q): unexpected error %v", s, err)
+        } else if got != input {
+            t.Errorf("Parse(%q): got %d, expected %d", s, got, input)
+        }
+    }
+
+    type row struct {
+        ruleset string
+        input string
+        expected int64
+        err error
+    }
+    rows := []row{
+        {"%spellout-cardinal", "Twenty Five", 25, nil},
+        {"%spellout-cardinal", "twentyfive", 25, nil},
+        {"%spellout-cardinal", "  one   hundred  ", 100, nil},
+        {"%spellout-cardinal", "eleven hundred", 1100, nil},
+        {"%spellout-cardinal", "two thousand and one", 0, ErrSyntax},
+        {"%spellout-cardinal", "one hundred zero", 0, ErrSyntax},
+        {"%spellout-cardinal", "twenty-twenty", 0, ErrSyntax},
+        {"%spellout-cardinal", "minus minus one", 0, ErrSyntax},
+        {"%spellout-cardinal", "", 0, ErrSyntax},
+        {"%spellout-numbering", "minus three", -3, nil},
+        {"%digits-ordinal", "1,002nd", 1002, nil},
+        {"%digits-ordinal", "1002nd", 1002, nil},
+        {"%digits-ordinal", "-3rd", -3, nil},
+        {"%digits-ordinal", "3", 0, ErrSyntax},
+        {"%digits-ordinal", "99999999999999999999th", 0, ErrSyntax},
+        {"%loop", "zero", 0, ErrRecursion},
+    }
+    for _, r := range rows {
+        got, err := must.Ok(g.RuleSet(r.ruleset)).Parse(r.input)
+        if !errors.Is(err, r.err) {
+            t.Errorf("%s.Parse(%q): got error %v, expected %v", r.ruleset, r.input, err, r.err)
+        } else if got != r.expected {
+            t.Errorf("%s.Parse(%q): got %d, expected %d", r.ruleset, r.input, got, r.expected)
+        }
+    }
+}
@@ -0,0 +1,69 @@
+package spellout_test
+
+import (
+    "fmt"
+
+    "github.com/tawesoft/golib/v2/text/number/spellout"
+    "golang.org/x/text/language"
+)
+
+func ExampleFormat() {
+    print := func(tag language.Tag, name string, n int64) {
+        if s, err := spellout.Format(tag, name, n); err == nil {
+            fmt.Printf("%s %s(%d): %s\n", tag, name, n, s)
+        } else {
+            fmt.Printf("%s %s(%d): error: %v\n", tag, name, n, err)
+        }
+    }
+
+    print(language.English, "spellout-cardinal", 123)
+    print(language.English, "spellout-cardinal-verbose", 100_123)
+    print(language.BritishEnglish, "spellout-numbering", -2_500_017)
+    print(language.Spanish, "spellout-numbering", 21)
+    print(language.Spanish, "spellout-cardinal-masculine", 21_000)
+    print(language.Spanish, "spellout-cardinal-feminine", 21_001)
+    print(language.LatinAmericanSpanish, "spellout-numbering", 1_000_000_000)
+    print(language.English, "spellout-ordinal", 1)
+    print(language.English, "spellout-ordinal", 42)
+    print(language.English, "spellout-ordinal", 113)
+    print(language.English, "spellout-ordinal", 1_000)
+    print(language.English, "digits-ordinal", 1_002)
+    print(language.English, "digits-ordinal", 13)
+    print(language.English, "digits-ordinal", 23)
+    print(language.English, "spellout-numbering-year", 1999)
+    print(language.English, "spellout-numbering-year", 1905)
+    print(language.English, "spellout-numbering-year", 1900)
+    print(language.English, "spellout-numbering-year", 2008)
+    print(language.English, "spellout-numbering-year", 2024)
+    print(language.Spanish, "spellout-ordinal-masculine", 21)
+    print(language.Spanish, "spellout-ordinal-feminine", 3)
+    print(language.Spanish, "digits-ordinal-feminine", 1_500)
+    print(language.Spanish, "spellout-numbering-year", 1492)
+    print(language.Spanish, "spellout-ordinal", 1)
+
+    // Output:
+    // en spellout-cardinal(123): one hundred twenty-three
+    // en spellout-cardinal-verbose(100123): one hundred thousand, one hundred and twenty-three
+    // en-GB spellout-numbering(-2500017): minus two million five hundred thousand seventeen
+    // es spellout-numbering(21): veintiuno
+    // es spellout-cardinal-masculine(21000): veintiún mil
+    // es spellout-cardinal-feminine(21001): veintiún mil una
+    // es-419 spellout-numbering(1000000000): mil millones
+    // en spellout-ordinal(1): first
+    // en spellout-ordinal(42): forty-second
+    // en spellout-ordinal(113): one hundred thirteenth
+    // en spellout-ordinal(1000): one thousandth
+    // en digits-ordinal(1002): 1,002nd
+    // en digits-ordinal(13): 13th
+    // en digits-ordinal(23): 23rd
+    // en spellout-numbering-year(1999): nineteen ninety-nine
+    // en spellout-numbering-year(1905): nineteen oh-five
+    // en spellout-numbering-year(1900): nineteen hundred
+    // en spellout-numbering-year(2008): two thousand eight
+    // en spellout-numbering-year(2024): twenty twenty-four
+    // es spellout-ordinal-masculine(21): vigésimo primero
+    // es spellout-ordinal-feminine(3): tercera
+    // es digits-ordinal-feminine(1500): 1.500.ª
+    // es spellout-numbering-year(1492): mil cuatrocientos noventa y dos
+    // es spellout-ordinal(1): error: no rule for this input
+}
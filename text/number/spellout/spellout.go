@@ -0,0 +1,108 @@
+// Package spellout implements spelling out numbers in words, such as 123 as
+// "one hundred twenty-three", for some common locales.
+//
+// These use the rules from the Unicode Common Locale Data Repository (CLDR)
+// (e.g. "common/rbnf/en.xml"), formatted by the [golib/v2/text/number/rbnf]
+// package. Every rule set of the CLDR file is embedded for each of the
+// following locales. For other locales, see [rbnf.Load].
+//
+//   - en: spellout-numbering-year, spellout-numbering,
+//     spellout-numbering-verbose, spellout-cardinal,
+//     spellout-cardinal-verbose, spellout-ordinal, spellout-ordinal-verbose,
+//     digits-ordinal, with-words, in-numerals, duration.
+//   - es: spellout-numbering-year, spellout-numbering,
+//     spellout-cardinal-masculine, spellout-cardinal-feminine,
+//     spellout-ordinal-masculine, spellout-ordinal-masculine-adjective,
+//     spellout-ordinal-masculine-plural, spellout-ordinal-feminine,
+//     spellout-ordinal-feminine-plural, digits-ordinal,
+//     digits-ordinal-masculine, digits-ordinal-masculine-adjective,
+//     digits-ordinal-masculine-plural, digits-ordinal-feminine,
+//     digits-ordinal-feminine-plural.
+//
+// The rule sets for each locale are loaded on first use.
+//
+// Where a rule formats a number using digits (e.g. "1.500.ª"), the decimal
+// and grouping separators of the locale are used (see [rbnf.Group.SetSymbols]).
+//
+// [golib/v2/text/number/rbnf]: https://github.com/tawesoft/golib/v2/text/number/rbnf
+package spellout
+
+import (
+    "embed"
+    "strings"
+    "sync"
+
+    "github.com/tawesoft/golib/v2/must"
+    "github.com/tawesoft/golib/v2/text/number/plurals"
+    "github.com/tawesoft/golib/v2/text/number/rbnf"
+    "github.com/tawesoft/golib/v2/text/number/symbols"
+    "golang.org/x/text/language"
+)
+
+//go:embed data/*.xml
+var data embed.FS
+
+// Locales is a slice of every locale with rules embedded in this package.
+var Locales = []language.Tag{
+    language.English,
+    language.Spanish,
+}
+
+var matcher = language.NewMatcher(Locales)
+
+type locale struct {
+    once sync.Once
+    group *rbnf.Group
+}
+
+var locales = func() []*locale {
+    result := make([]*locale, len(Locales))
+    for i := range result {
+        result[i] = &locale{}
+    }
+    return result
+}()
+
+// Group returns the group of rule sets for the embedded locale that best
+// matches the given language tag, or false if there is no match.
+func Group(tag language.Tag) (*rbnf.Group, bool) {
+    _, idx, confidence := matcher.Match(tag)
+    if confidence == language.No { return nil, false }
+
+    l := locales[idx]
+    l.once.Do(func() {
+        base, _ := Locales[idx].Base()
+        f := must.Result(data.Open("data/" + base.String() + ".xml"))
+        defer f.Close()
+        l.group = must.Result(rbnf.Load(plurals.New(Locales[idx]), f))
+
+        sym := symbols.Get_(base.String(), "", "", "", "")
+        l.group.SetSymbols(rbnf.Symbols{Decimal: sym.Decimal, Group: sym.Group})
+    })
+    return l.group, true
+}
+
+// RuleSet returns a named public rule set, such as "spellout-cardinal", for
+// the embedded locale that best matches the given language tag. The name may
+// be given with or without the leading "%".
+func RuleSet(tag language.Tag, name string) (rbnf.RuleSet, bool) {
+    g, ok := Group(tag)
+    if !ok { return rbnf.RuleSet{}, false }
+    if !strings.HasPrefix(name, "%") { name = "%" + name }
+    return g.RuleSet(name)
+}
+
+// Format spells out a number using a named rule set, such as
+// "spellout-cardinal", for the embedded locale that best matches the given
+// language tag. If there is no such rule set, returns [rbnf.ErrNoRule].
+//
+// For example,
+//
+//     spellout.Format(language.English, "spellout-cardinal", 123)
+//
+// returns "one hundred twenty-three".
+func Format(tag language.Tag, name string, number int64) (string, error) {
+    rs, ok := RuleSet(tag, name)
+    if !ok { return "", rbnf.ErrNoRule }
+    return rs.Format(number)
+}
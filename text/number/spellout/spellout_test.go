@@ -0,0 +1,58 @@
+package spellout_test
+
+import (
+    "bufio"
+    "os"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/text/number/spellout"
+    "golang.org/x/text/language"
+)
+
+// TestFormat_icu compares Format against the output of ICU for every public
+// rule set of every embedded locale.
+func TestFormat_icu(t *testing.T) {
+    for _, tag := range spellout.Locales {
+        base, _ := tag.Base()
+        t.Run(base.String(), func(t *testing.T) {
+            testFormatICU(t, tag, "testdata/" + base.String() + ".txt")
+        })
+    }
+}
+
+func testFormatICU(t *testing.T, tag language.Tag, path string) {
+    f, err := os.Open(path)
+    if err != nil { t.Fatal(err) }
+    defer f.Close()
+
+    g, ok := spellout.Group(tag)
+    if !ok { t.Fatalf("no rules for %v", tag) }
+
+    tested := make(map[string]bool)
+    scanner := bufio.NewScanner(f)
+    for line := 1; scanner.Scan(); line++ {
+        text := scanner.Text()
+        if (text == "") || strings.HasPrefix(text, "#") { continue }
+
+        fields := strings.Split(text, "\t")
+        if len(fields) != 3 { t.Fatalf("line %d: expected 3 fields", line) }
+        name, expected := fields[0], fields[2]
+        n, err := strconv.ParseInt(fields[1], 10, 64)
+        if err != nil { t.Fatalf("line %d: %v", line, err) }
+
+        tested[name] = true
+        got, err := spellout.Format(tag, name, n)
+        if err != nil {
+            t.Errorf("%s(%d): unexpected error %v", name, n, err)
+        } else if got != expected {
+            t.Errorf("%s(%d): got %q, expected %q", name, n, got, expected)
+        }
+    }
+    if err := scanner.Err(); err != nil { t.Fatal(err) }
+
+    for _, name := range g.RulesetNames() {
+        if !tested[name] { t.Errorf("rule set %q is not tested", name) }
+    }
+}
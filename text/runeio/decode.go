@@ -0,0 +1,105 @@
+package runeio
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "io"
+
+    "golang.org/x/text/encoding"
+    "golang.org/x/text/encoding/htmlindex"
+    "golang.org/x/text/encoding/unicode"
+    "golang.org/x/text/transform"
+)
+
+// maxSniff is the maximum number of bytes examined by [DetectEncoding] and
+// [NewDecodingReader], matching the CSS Syntax Module Level 3 algorithm for
+// determining the fallback encoding.
+const maxSniff = 1024
+
+// DetectEncoding determines the character encoding of some input from its
+// first few bytes (up to 1024 bytes are examined), in the manner of the
+// [CSS Syntax Module Level 3] "decode bytes" algorithm:
+//
+//   1. If the input begins with a UTF-8, UTF-16BE, or UTF-16LE byte order
+//      mark (BOM), that encoding is used, and bomLength is the length of the
+//      byte order mark.
+//   2. Otherwise, if the input begins with an `@charset "label";` rule, the
+//      encoding named by that label is used, if it is known (see the
+//      [WHATWG Encoding Standard]). If the label names UTF-16BE or UTF-16LE,
+//      UTF-8 is used instead.
+//   3. Otherwise, the fallback encoding is used, or UTF-8 if the fallback is
+//      nil.
+//
+// [CSS Syntax Module Level 3]: https://www.w3.org/TR/css-syntax-3/#input-byte-stream
+// [WHATWG Encoding Standard]: https://encoding.spec.whatwg.org/#names-and-labels
+func DetectEncoding(prefix []byte, fallback encoding.Encoding) (enc encoding.Encoding, bomLength int) {
+    switch {
+        case bytes.HasPrefix(prefix, []byte{0xEF, 0xBB, 0xBF}):
+            return unicode.UTF8, 3
+        case bytes.HasPrefix(prefix, []byte{0xFE, 0xFF}):
+            return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), 2
+        case bytes.HasPrefix(prefix, []byte{0xFF, 0xFE}):
+            return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), 2
+    }
+
+    if label, ok := charsetLabel(prefix); ok {
+        if e, err := htmlindex.Get(label); err == nil {
+            name, _ := htmlindex.Name(e)
+            if (name == "utf-16be") || (name == "utf-16le") {
+                return unicode.UTF8, 0
+            }
+            return e, 0
+        }
+    }
+
+    if fallback == nil { return unicode.UTF8, 0 }
+    return fallback, 0
+}
+
+// charsetLabel returns the label of an `@charset "label";` rule at the
+// start of the input, if there is one.
+func charsetLabel(prefix []byte) (string, bool) {
+    const start = `@charset "`
+    if len(prefix) > maxSniff { prefix = prefix[0:maxSniff] }
+    if !bytes.HasPrefix(prefix, []byte(start)) { return "", false }
+    rest := prefix[len(start):]
+
+    for i, c := range rest {
+        if c == '"' {
+            if (i + 1 < len(rest)) && (rest[i + 1] == ';') {
+                return string(rest[0:i]), true
+            }
+            return "", false
+        }
+        if c > 0x7F { return "", false } // labels are ASCII
+    }
+    return "", false
+}
+
+// NewDecodingReader returns a new [Reader] that reads runes from rd, decoded
+// from the character encoding determined by [DetectEncoding]. Any byte order
+// mark is removed from the stream. Invalid input in the detected encoding is
+// replaced with the Unicode replacement character U+FFFD.
+//
+// This is useful to produce a clean stream of runes from input in an unknown
+// encoding, such as a CSS stylesheet. The fallback encoding may be nil, in
+// which case it defaults to UTF-8.
+//
+// It also returns the detected encoding. An error is returned only if
+// reading the first few bytes of rd fails.
+func NewDecodingReader(rd io.Reader, fallback encoding.Encoding) (*Reader, encoding.Encoding, error) {
+    br := bufio.NewReaderSize(rd, maxSniff)
+    prefix, err := br.Peek(maxSniff)
+    if (err != nil) && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+        return nil, nil, err
+    }
+
+    enc, bomLength := DetectEncoding(prefix, fallback)
+    if _, err := br.Discard(bomLength); err != nil {
+        return nil, nil, err
+    }
+
+    decoded := transform.NewReader(br, enc.NewDecoder())
+    return NewReader(decoded), enc, nil
+}
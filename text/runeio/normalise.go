@@ -0,0 +1,120 @@
+package runeio
+
+import (
+    "bytes"
+    "errors"
+    "io"
+
+    "golang.org/x/text/transform"
+    "golang.org/x/text/unicode/norm"
+)
+
+// IsNormal returns true if b is already in the Unicode normalisation form f
+// (for example, [norm.NFC] or [norm.NFD]), so that normalising it would not
+// change it.
+//
+// If atEOF is false, b is the start of some longer input, and IsNormal
+// returns true if b is normal so far (runes at the end of b may still combine
+// with the rest of the input).
+//
+// This uses the quick check from [Unicode Standard Annex #15: Unicode
+// Normalization Forms], so may return false for some input that is actually
+// normal, but never returns true for input that is not.
+//
+// [Unicode Standard Annex #15: Unicode Normalization Forms]: https://www.unicode.org/reports/tr15/
+func IsNormal(b []byte, f norm.Form, atEOF bool) bool {
+    _, err := f.Span(b, atEOF)
+    return (err == nil) || errors.Is(err, transform.ErrShortSrc)
+}
+
+// NewNormalisingReader returns a new [Reader] that reads runes from rd,
+// normalised to the Unicode normalisation form f (for example, [norm.NFC] or
+// [norm.NFD]).
+//
+// The input is checked as it is read (see [IsNormal]), and is passed through
+// unchanged for as long as it is already normal. Only once some input is
+// found that may not be normal is the rest of the input normalised. This
+// avoids most of the cost of normalisation in the common case where the
+// input is already normalised.
+func NewNormalisingReader(rd io.Reader, f norm.Form) *Reader {
+    return NewReader(&normalisingReader{
+        rd:   rd,
+        form: f,
+        buf:  make([]byte, 4096),
+    })
+}
+
+// normalisingReader is an io.Reader that passes through input from rd
+// while it is normal, and then normalises the rest.
+type normalisingReader struct {
+    rd io.Reader
+    form norm.Form
+
+    // buf[pos:span] is normal input, not yet read. buf[span:end] is input
+    // that is held back until more input is available.
+    buf []byte
+    pos, span, end int
+
+    err error // from rd
+
+    // normalised is the rest of the input, normalised, set once some input
+    // is found that may not be normal.
+    normalised io.Reader
+}
+
+func (r *normalisingReader) Read(p []byte) (int, error) {
+    for {
+        if r.pos < r.span {
+            n := copy(p, r.buf[r.pos:r.span])
+            r.pos += n
+            return n, nil
+        }
+        if r.normalised != nil { return r.normalised.Read(p) }
+        if r.err != nil { return 0, r.err }
+        r.fill()
+    }
+}
+
+// fill reads more input, and checks it.
+func (r *normalisingReader) fill() {
+    r.end = copy(r.buf, r.buf[r.span:r.end])
+    r.pos, r.span = 0, 0
+
+    if r.end == len(r.buf) {
+        // held back input fills the buffer, so give up checking it
+        r.normalise()
+        return
+    }
+
+    n, err := r.rd.Read(r.buf[r.end:])
+    r.end += n
+    if err != nil { r.err = err }
+
+    span, err := r.form.Span(r.buf[0:r.end], r.err != nil)
+    r.span = span
+    if (err != nil) && !errors.Is(err, transform.ErrShortSrc) {
+        r.normalise()
+    }
+}
+
+// normalise normalises the rest of the input, starting with the input
+// held back in the buffer.
+func (r *normalisingReader) normalise() {
+    var rest io.Reader = bytes.NewReader(r.buf[r.span:r.end])
+    switch {
+        case r.err == nil:
+            rest = io.MultiReader(rest, r.rd)
+        case !errors.Is(r.err, io.EOF):
+            rest = io.MultiReader(rest, errorReader{r.err})
+    }
+    r.normalised = transform.NewReader(rest, r.form)
+}
+
+// errorReader is an io.Reader that always returns an error.
+type errorReader struct {
+    err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+    return 0, r.err
+}
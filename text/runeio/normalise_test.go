@@ -0,0 +1,85 @@
+package runeio_test
+
+import (
+    "errors"
+    "io"
+    "strings"
+    "testing"
+    "testing/iotest"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/text/runeio"
+    "golang.org/x/text/unicode/norm"
+)
+
+func TestIsNormal(t *testing.T) {
+    type row struct {
+        input string
+        form norm.Form
+        atEOF bool
+        expected bool
+    }
+    rows := []row{
+        {"",              norm.NFC, true,  true},
+        {"cafe",          norm.NFC, true,  true},
+        {"café",          norm.NFC, true,  true},
+        {"cafe\u0301",    norm.NFC, true,  false},
+        {"café",          norm.NFD, true,  false},
+        {"cafe\u0301",    norm.NFD, true,  true},
+        {"cafe",          norm.NFC, false, true}, // may be followed by a combining mark
+        {"a\u0323\u0302", norm.NFD, true,  true},
+        {"a\u0302\u0323", norm.NFD, true,  false}, // not in canonical order
+    }
+    for _, r := range rows {
+        got := runeio.IsNormal([]byte(r.input), r.form, r.atEOF)
+        assert.Equal(t, r.expected, got, "IsNormal(%q, %v, %t)", r.input, r.form, r.atEOF)
+    }
+}
+
+func TestNewNormalisingReader(t *testing.T) {
+    readAll := func(rd *runeio.Reader) (string, error) {
+        var sb strings.Builder
+        for {
+            x, err := rd.Next()
+            if errors.Is(err, io.EOF) { return sb.String(), nil }
+            if err != nil { return sb.String(), err }
+            sb.WriteRune(x)
+        }
+    }
+
+    long := strings.Repeat("Ünïcödé ", 1000)
+    type row struct {
+        input string
+        form norm.Form
+    }
+    rows := []row{
+        {"",                           norm.NFC},
+        {"café",                       norm.NFC},
+        {"cafe\u0301",                 norm.NFC},
+        {"café",                       norm.NFD},
+        {long,                         norm.NFC},
+        {norm.NFD.String(long),        norm.NFC},
+        {long + "e\u0301",             norm.NFC}, // only the end is not normal
+        {long,                         norm.NFD},
+        {strings.Repeat("\u0301", 5000), norm.NFC}, // a very long segment
+    }
+    for i, r := range rows {
+        expected := r.form.String(r.input)
+
+        got, err := readAll(runeio.NewNormalisingReader(strings.NewReader(r.input), r.form))
+        assert.Nil(t, err)
+        assert.Equal(t, expected, got, "test %d", i)
+
+        // across read boundaries
+        rd := iotest.OneByteReader(strings.NewReader(r.input))
+        got, err = readAll(runeio.NewNormalisingReader(rd, r.form))
+        assert.Nil(t, err)
+        assert.Equal(t, expected, got, "test %d, one byte at a time", i)
+    }
+
+    // read errors are returned after any input
+    rd := io.MultiReader(strings.NewReader("café"), iotest.ErrReader(io.ErrUnexpectedEOF))
+    got, err := readAll(runeio.NewNormalisingReader(rd, norm.NFC))
+    assert.Equal(t, "café", got)
+    assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
@@ -1,6 +1,15 @@
 // Package runeio implements a mechanism to read a stream of Unicode code
 // points (runes) from an io.Reader, with an internal buffer to push code
 // points back to the front of the stream to allow limited peeking and rewind.
+//
+// A [Reader] may also be constructed with [NewDecodingReader] to decode input
+// in an unknown character encoding, detected from a byte order mark or an
+// `@charset` rule, or with [NewNormalisingReader] to normalise input that is
+// not already in a given Unicode normalisation form.
+//
+// A [Writer] is the counterpart to a Reader, and writes a stream of runes to
+// an io.Writer, with an internal buffer so that the most recently written
+// runes may be taken back.
 package runeio
 
 import (
@@ -37,7 +46,7 @@ const RuneEOF = rune(0xFFFFFF)
 
 func NewReader(rd io.Reader) *Reader {
     return &Reader{
-        rdr: bufio.NewReaderSize(rd, utf8.MaxRune * 64),
+        rdr: bufio.NewReader(rd),
     }
 }
 
@@ -62,7 +71,8 @@ func (r *Reader) Last() rune {
 // provided buf is ignored.
 //
 // For example, to be able to push back at least n Unicode codepoints (runes),
-// pass a buffer with capacity utf8.UTFMax * n, or 4n.
+// pass a buffer with capacity utf8.UTFMax * n, or 4n. See also
+// [Reader.Lookahead].
 func (r *Reader) Buffer(buf []byte, capacity int) {
     fitErr := fmt.Errorf("runeio error: existing buffer does not fit in new buffer")
     if capacity == 0 {
@@ -77,23 +87,42 @@ func (r *Reader) Buffer(buf []byte, capacity int) {
     if buf == nil {
         buf = make([]byte, 0, capacity)
     }
-    if capacity < cap(buf) {
+    if cap(buf) < capacity {
         panic(fmt.Errorf("runeio error: cap(buffer) < capacity"))
     }
-    buf = buf[0:0]
 
     // copy existing buffer
     if capacity < len(r.buf) {
-        panic(fmt.Errorf("runeio error: cap(buffer) < length of existing buffer"))
-    }
-    for i := 0; i < len(r.buf); i++ {
-        buf[i] = r.buf[i]
+        panic(fitErr)
     }
+    buf = append(buf[0:0], r.buf...)
 
     r.buf = buf
     r.bufmax = capacity
 }
 
+// Lookahead configures the pushback buffer so that it can hold at least n
+// runes of any size, in addition to any runes currently in the buffer. This
+// determines how far ahead [Reader.Peek], [Reader.PeekN], and
+// [Reader.PeekRunes] can look. The buffer only ever grows, and its existing
+// contents are kept.
+//
+// If n is negative, the pushback buffer is unbounded, and grows as needed, so
+// that the reader supports arbitrary lookahead.
+func (r *Reader) Lookahead(n int) {
+    if n < 0 {
+        r.bufmax = -1
+        return
+    }
+    if r.bufmax < 0 { return } // already unbounded
+    capacity := len(r.buf) + (utf8.UTFMax * n)
+    if capacity <= r.bufmax { return }
+    buf := make([]byte, len(r.buf), capacity)
+    copy(buf, r.buf)
+    r.buf = buf
+    r.bufmax = capacity
+}
+
 // Clear clears the reader's pushback buffer (if one exists).
 func (r *Reader) Clear() {
     if r.buf != nil {
@@ -130,7 +159,7 @@ func (r *Reader) push(x rune) {
         x = utf8.RuneError
         size = 3
     }
-    if len(r.buf) + size > r.bufmax {
+    if (r.bufmax >= 0) && (len(r.buf) + size > r.bufmax) {
         panic(fmt.Errorf("runeio pushback buffer overflow"))
     }
 
@@ -141,8 +170,9 @@ func (r *Reader) push(x rune) {
 // the input stream is read from.
 //
 // If a maximum buffer capacity has been set, a panic will be raised if pushing
-// a rune would exceed that capacity.
+// a rune would exceed that capacity. Pushing RuneEOF does nothing.
 func (r *Reader) Push(x rune) {
+    if x == RuneEOF { return }
     r.push(x)
     r.pushedRunes++
 }
@@ -163,7 +193,9 @@ func (r *Reader) next() (rune, int, error) {
 func (r *Reader) Next() (rune, error) {
     x, size, err := r.next()
 
-    if (r.pushedRunes == 0) && (x != RuneEOF) {
+    if r.pushedRunes > 0 {
+        r.pushedRunes--
+    } else if err == nil {
         r.offset.Byte += int64(size)
         if x == '\n' {
             r.offset.Rune = 0
@@ -171,8 +203,6 @@ func (r *Reader) Next() (rune, error) {
         } else {
             r.offset.Rune++
         }
-    } else {
-        r.pushedRunes--
     }
 
     r.last = x
@@ -194,7 +224,12 @@ func (r *Reader) Peek() (rune, error) {
 // buffer. It returns the number of elements read, ending early in the event of
 // EOF. The first n elements of dest are set to the special value RuneEOF
 // unless updated with a successfully peeked value. The pushback buffer must be
-// able to handle at least n elements, in addition to its existing contents.
+// able to handle at least n elements, in addition to its existing contents
+// (see [Reader.Lookahead]).
+//
+// In the event of a read error, any runes successfully peeked are still
+// stored in dest and pushed back, so that they are not lost, and the number
+// of them is returned along with the error.
 func (r *Reader) PeekN(dest []rune, n int) (int, error) {
 
     for i := 0; i < n; i++ {
@@ -202,12 +237,14 @@ func (r *Reader) PeekN(dest []rune, n int) (int, error) {
     }
 
     var numRead int
+    var readErr error
     for i := 0; i < n; i++ {
         x, _, err := r.next()
         if errors.Is(err, io.EOF) {
             break
         } else if err != nil {
-            return 0, err
+            readErr = err
+            break
         }
         dest[numRead] = x
         numRead++
@@ -217,7 +254,16 @@ func (r *Reader) PeekN(dest []rune, n int) (int, error) {
         r.push(dest[numRead-i-1])
     }
 
-    return numRead, nil
+    return numRead, readErr
+}
+
+// PeekRunes is like [Reader.PeekN], but returns a new slice of up to n runes
+// that the next n calls to [Reader.Next] would return, ending early in the
+// event of EOF.
+func (r *Reader) PeekRunes(n int) ([]rune, error) {
+    dest := make([]rune, n)
+    numRead, err := r.PeekN(dest, n)
+    return dest[0:numRead], err
 }
 
 func (r *Reader) Skip(n int) error {
@@ -1,12 +1,20 @@
 package runeio_test
 
 import (
+    "bytes"
+    "errors"
+    "io"
     "strings"
     "testing"
+    "testing/iotest"
     "unicode/utf8"
 
     "github.com/stretchr/testify/assert"
     "github.com/tawesoft/golib/v2/text/runeio"
+    "golang.org/x/text/encoding"
+    "golang.org/x/text/encoding/charmap"
+    "golang.org/x/text/encoding/htmlindex"
+    "golang.org/x/text/encoding/unicode"
 )
 
 func TestPeekN(t *testing.T) {
@@ -25,6 +33,24 @@ func TestPeekN(t *testing.T) {
     assert.Equal(t, runeio.RuneEOF, buf[5])
 }
 
+func TestPeekN_error(t *testing.T) {
+    var buf [6]rune
+    rd := io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(io.ErrUnexpectedEOF))
+    r := runeio.NewReader(rd)
+    r.Buffer(nil, utf8.UTFMax * 6)
+    n, err := r.PeekN(buf[:], 6)
+
+    assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+    assert.Equal(t, 3, n)
+    assert.Equal(t, []rune("abc"), buf[0:n])
+    assert.Equal(t, runeio.RuneEOF, buf[3])
+
+    // the runes read before the error are not lost
+    assert.Equal(t, 'a', runeio.Must(r.Next()))
+    assert.Equal(t, 'b', runeio.Must(r.Next()))
+    assert.Equal(t, 'c', runeio.Must(r.Next()))
+}
+
 func TestPush(t *testing.T) {
     r := runeio.NewReader(strings.NewReader("hello"))
     r.Buffer(nil, utf8.UTFMax * 1)
@@ -93,4 +119,99 @@ func TestOffset(t *testing.T) {
 }
 
 func TestOffsetEof(t *testing.T) {
+    r := runeio.NewReader(strings.NewReader("a"))
+    r.Buffer(nil, utf8.UTFMax * 2)
+    assert.Equal(t, 'a', runeio.Must(r.Next()))
+    assert.Equal(t, runeio.RuneEOF, runeio.Must(r.Next()))
+    assert.Equal(t, runeio.RuneEOF, runeio.Must(r.Next()))
+    assert.Equal(t, runeio.Offset{1, 1, 0}, r.Offset())
+
+    // pushing RuneEOF does nothing
+    r.Push(runeio.RuneEOF)
+    r.Push('b')
+    assert.Equal(t, 'b', runeio.Must(r.Next()))
+    assert.Equal(t, runeio.RuneEOF, runeio.Must(r.Next()))
+    assert.Equal(t, runeio.Offset{1, 1, 0}, r.Offset())
+}
+
+func TestLookahead(t *testing.T) {
+    input := strings.Repeat("héllo, wörld ", 20)
+
+    r := runeio.NewReader(strings.NewReader(input))
+    r.Buffer(nil, utf8.UTFMax * 2)
+    r.Push('x')
+    r.Lookahead(3)
+    xs, err := r.PeekRunes(3)
+    assert.Nil(t, err)
+    assert.Equal(t, "xhé", string(xs))
+
+    r = runeio.NewReader(strings.NewReader(input))
+    r.Lookahead(-1)
+    xs, err = r.PeekRunes(1000)
+    assert.Nil(t, err)
+    assert.Equal(t, input, string(xs))
+    assert.Equal(t, 'h', runeio.Must(r.Next()))
+    assert.Equal(t, runeio.Offset{1, 1, 0}, r.Offset())
+
+    r = runeio.NewReader(strings.NewReader("ééé"))
+    r.Buffer(nil, utf8.UTFMax * 1)
+    assert.Panics(t, func() { r.PeekRunes(3) })
+}
+
+func TestBuffer_copy(t *testing.T) {
+    r := runeio.NewReader(strings.NewReader("abc"))
+    r.Buffer(nil, utf8.UTFMax * 1)
+    r.Push('x')
+    r.Buffer(nil, utf8.UTFMax * 4)
+    r.Push('y')
+    assert.Equal(t, 'y', runeio.Must(r.Next()))
+    assert.Equal(t, 'x', runeio.Must(r.Next()))
+    assert.Equal(t, 'a', runeio.Must(r.Next()))
+}
+
+func TestNewDecodingReader(t *testing.T) {
+    type row struct {
+        input []byte
+        fallback encoding.Encoding
+        expected string
+        encoding string
+    }
+
+    utf16le := func(s string) []byte {
+        bs, _ := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte(s))
+        return bs
+    }
+    utf16be := func(s string) []byte {
+        bs, _ := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte(s))
+        return bs
+    }
+
+    rows := []row{
+        {[]byte("a{b:c}"), nil, "a{b:c}", "utf-8"},
+        {[]byte(""), nil, "", "utf-8"},
+        {[]byte("\xEF\xBB\xBFcafé"), nil, "café", "utf-8"},
+        {append([]byte{0xFF, 0xFE}, utf16le("café")...), nil, "café", "utf-16le"},
+        {append([]byte{0xFE, 0xFF}, utf16be("café")...), nil, "café", "utf-16be"},
+        {[]byte("caf\xE9"), charmap.Windows1252, "café", "windows-1252"},
+        {[]byte("@charset \"iso-8859-1\"; caf\xE9"), nil, "@charset \"iso-8859-1\"; café", "windows-1252"},
+        {[]byte("@charset \"utf-16le\"; café"), nil, "@charset \"utf-16le\"; café", "utf-8"},
+        {[]byte("@charset \"bogus\"; caf\xE9"), charmap.Windows1252, "@charset \"bogus\"; café", "windows-1252"},
+        {[]byte("@charset 'latin1'; caf\xE9"), nil, "@charset 'latin1'; caf�", "utf-8"}, // single quotes don't count
+        {[]byte("\xEF\xBB\xBF@charset \"latin1\"; café"), nil, "@charset \"latin1\"; café", "utf-8"}, // BOM wins
+    }
+
+    for i, r := range rows {
+        rdr, enc, err := runeio.NewDecodingReader(bytes.NewReader(r.input), r.fallback)
+        if !assert.Nil(t, err, "test %d", i) { continue }
+        name, _ := htmlindex.Name(enc)
+        assert.Equal(t, r.encoding, name, "test %d", i)
+
+        var sb strings.Builder
+        for {
+            c := runeio.Must(rdr.Next())
+            if c == runeio.RuneEOF { break }
+            sb.WriteRune(c)
+        }
+        assert.Equal(t, r.expected, sb.String(), "test %d", i)
+    }
 }
@@ -0,0 +1,154 @@
+package runeio
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "unicode/utf8"
+)
+
+// ErrUnwrite is returned by [Writer.UnwriteRune] when there is no rune that
+// can be unwritten.
+var ErrUnwrite = errors.New("runeio error: no rune to unwrite")
+
+// Writer writes a stream of Unicode code points (runes) to an io.Writer, and
+// is the counterpart to [Reader].
+//
+// The most recently written runes are held in an internal buffer before they
+// are written to the underlying io.Writer, so that they can be taken back
+// with [Writer.UnwriteRune]. This allows, for example, a serialiser to
+// speculatively write a separator and then remove it again. Call
+// [Writer.Flush] to write any buffered output.
+type Writer struct {
+    wtr *bufio.Writer
+    buf []byte // written, but not yet flushed, and may be unwritten
+    bufmax int
+    start Offset // offset at the start of buf
+    offset Offset
+}
+
+// NewWriter returns a new [Writer] that writes to w. At least one rune may be
+// unwritten (see [Writer.Lookbehind]).
+func NewWriter(w io.Writer) *Writer {
+    return &Writer{
+        wtr: bufio.NewWriter(w),
+        buf: make([]byte, 0, utf8.UTFMax),
+        bufmax: utf8.UTFMax,
+    }
+}
+
+// Offset returns the offset of the end of the output, including any runes
+// that are buffered but not yet flushed. Unwriting a rune moves the offset
+// back.
+func (w *Writer) Offset() Offset {
+    return w.offset
+}
+
+// Last returns the rune most recently written, and not unwritten. If there is
+// no such rune remaining in the buffer, it returns RuneEOF.
+func (w *Writer) Last() rune {
+    if len(w.buf) == 0 { return RuneEOF }
+    x, _ := utf8.DecodeLastRune(w.buf)
+    return x
+}
+
+// Lookbehind configures the buffer so that at least the n most recently
+// written runes, of any size, can be unwritten with [Writer.UnwriteRune]. The
+// buffer only ever grows.
+//
+// If n is negative, the buffer is unbounded, and nothing is written to the
+// underlying io.Writer until [Writer.Flush] is called.
+func (w *Writer) Lookbehind(n int) {
+    if n < 0 {
+        w.bufmax = -1
+        return
+    }
+    if w.bufmax < 0 { return } // already unbounded
+    w.bufmax = max(w.bufmax, utf8.UTFMax * n)
+}
+
+// WriteRune writes a single rune, returning the number of bytes written. An
+// invalid rune is written as [utf8.RuneError]. Writing RuneEOF does nothing.
+//
+// The error, if any, is from writing earlier buffered runes to the
+// underlying io.Writer.
+func (w *Writer) WriteRune(x rune) (int, error) {
+    if x == RuneEOF { return 0, nil }
+    if !utf8.ValidRune(x) { x = utf8.RuneError }
+    size := utf8.RuneLen(x)
+
+    w.buf = utf8.AppendRune(w.buf, x)
+    w.offset = advance(w.offset, x, size)
+
+    var err error
+    if (w.bufmax >= 0) && (len(w.buf) > w.bufmax) {
+        err = w.flush(len(w.buf) - w.bufmax)
+    }
+    return size, err
+}
+
+// WriteString writes each rune in s, returning the number of bytes written.
+// Any invalid UTF-8 is written as [utf8.RuneError].
+func (w *Writer) WriteString(s string) (int, error) {
+    var n int
+    for _, x := range s {
+        size, err := w.WriteRune(x)
+        n += size
+        if err != nil { return n, err }
+    }
+    return n, nil
+}
+
+// UnwriteRune removes the most recently written rune from the buffer, and
+// returns it. If the rune has already been written to the underlying
+// io.Writer, returns [ErrUnwrite].
+func (w *Writer) UnwriteRune() (rune, error) {
+    if len(w.buf) == 0 { return RuneEOF, ErrUnwrite }
+    x, size := utf8.DecodeLastRune(w.buf)
+    w.buf = w.buf[0 : len(w.buf) - size]
+
+    if x == '\n' {
+        // recompute the rune offset of the previous line
+        w.offset = w.start
+        for _, y := range string(w.buf) {
+            w.offset = advance(w.offset, y, utf8.RuneLen(y))
+        }
+    } else {
+        w.offset.Byte -= int64(size)
+        w.offset.Rune--
+    }
+    return x, nil
+}
+
+// Flush writes all buffered runes to the underlying io.Writer. After this,
+// they can no longer be unwritten.
+func (w *Writer) Flush() error {
+    if err := w.flush(len(w.buf)); err != nil { return err }
+    return w.wtr.Flush()
+}
+
+// flush writes at least n bytes from the start of the buffer, rounded up to
+// a whole rune, to the underlying writer.
+func (w *Writer) flush(n int) error {
+    i := 0
+    for i < n {
+        x, size := utf8.DecodeRune(w.buf[i:])
+        w.start = advance(w.start, x, size)
+        i += size
+    }
+    _, err := w.wtr.Write(w.buf[0:i])
+    w.buf = w.buf[0:copy(w.buf, w.buf[i:])]
+    return err
+}
+
+// advance returns an offset moved forward past a rune of a given size.
+func advance(offset Offset, x rune, size int) Offset {
+    offset.Byte += int64(size)
+    if x == '\n' {
+        offset.Rune = 0
+        offset.Line++
+    } else {
+        offset.Rune++
+    }
+    return offset
+}
@@ -0,0 +1,93 @@
+package runeio_test
+
+import (
+    "errors"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/tawesoft/golib/v2/text/runeio"
+)
+
+func TestWriter_UnwriteRune(t *testing.T) {
+    var sb strings.Builder
+    w := runeio.NewWriter(&sb)
+
+    w.WriteString("世界")
+    w.WriteRune('!')
+    x, err := w.UnwriteRune()
+    assert.Nil(t, err)
+    assert.Equal(t, '!', x)
+    assert.Equal(t, '界', w.Last())
+
+    // by default, at least one rune can be unwritten
+    x, err = w.UnwriteRune()
+    assert.Equal(t, '界', x)
+    assert.Nil(t, err)
+    _, err = w.UnwriteRune()
+    assert.True(t, errors.Is(err, runeio.ErrUnwrite))
+    assert.Equal(t, runeio.RuneEOF, w.Last())
+
+    w.WriteRune('!')
+    assert.Nil(t, w.Flush())
+    assert.Equal(t, "世!", sb.String())
+    _, err = w.UnwriteRune()
+    assert.True(t, errors.Is(err, runeio.ErrUnwrite))
+}
+
+func TestWriter_Lookbehind(t *testing.T) {
+    var sb strings.Builder
+    w := runeio.NewWriter(&sb)
+    w.Lookbehind(3)
+
+    w.WriteString("héllo, 世界")
+    for i := 0; i < 3; i++ {
+        _, err := w.UnwriteRune()
+        assert.Nil(t, err)
+    }
+    w.WriteString("!")
+    assert.Nil(t, w.Flush())
+    assert.Equal(t, "héllo,!", sb.String())
+
+    sb.Reset()
+    w = runeio.NewWriter(&sb)
+    w.Lookbehind(-1)
+    w.WriteString(strings.Repeat("x", 10000))
+    assert.Equal(t, "", sb.String()) // nothing written until flushed
+    for i := 0; i < 10000; i++ {
+        w.UnwriteRune()
+    }
+    assert.Equal(t, runeio.RuneEOF, w.Last())
+    assert.Nil(t, w.Flush())
+    assert.Equal(t, "", sb.String())
+}
+
+func TestWriter_Offset(t *testing.T) {
+    var sb strings.Builder
+    w := runeio.NewWriter(&sb)
+    w.Lookbehind(-1)
+
+    w.WriteString("héllo\nworld")
+    assert.Equal(t, runeio.Offset{12, 5, 1}, w.Offset())
+    for i := 0; i < 5; i++ { w.UnwriteRune() }
+    assert.Equal(t, runeio.Offset{7, 0, 1}, w.Offset())
+    w.UnwriteRune() // "\n"
+    assert.Equal(t, runeio.Offset{6, 5, 0}, w.Offset())
+
+    // the line before a newline is partly flushed
+    sb.Reset()
+    w = runeio.NewWriter(&sb)
+    w.WriteString("héllo\nab")
+    assert.Equal(t, runeio.Offset{9, 2, 1}, w.Offset())
+    w.UnwriteRune()
+    w.UnwriteRune()
+    w.UnwriteRune() // "\n"
+    assert.Equal(t, runeio.Offset{6, 5, 0}, w.Offset())
+    w.UnwriteRune()
+    assert.Equal(t, runeio.Offset{5, 4, 0}, w.Offset())
+    _, err := w.UnwriteRune()
+    assert.True(t, errors.Is(err, runeio.ErrUnwrite))
+    assert.Equal(t, runeio.Offset{5, 4, 0}, w.Offset())
+    assert.Nil(t, w.Flush())
+    assert.Equal(t, "héll", sb.String())
+}
@@ -0,0 +1,80 @@
+package segment_test
+
+import (
+    "bufio"
+    "fmt"
+    "strings"
+
+    "github.com/tawesoft/golib/v2/iter"
+    "github.com/tawesoft/golib/v2/text/segment"
+)
+
+func ExampleGraphemes() {
+    s := "🇬🇧🇫🇷e\u0301👩\u200D👩\u200D👧!\r\n"
+
+    fmt.Printf("%d bytes\n", len(s))
+    fmt.Printf("%d graphemes\n", segment.CountGraphemes(s))
+    fmt.Printf("%q\n", iter.ToSlice(segment.Graphemes(s)))
+
+    // Output:
+    // 40 bytes
+    // 6 graphemes
+    // ["🇬🇧" "🇫🇷" "é" "👩\u200d👩\u200d👧" "!" "\r\n"]
+}
+
+func ExampleWords() {
+    s := "Don't panic, it's 3.5°C!"
+
+    for _, word := range iter.ToSlice(segment.Words(s)) {
+        if !segment.IsWordLike(word) { continue }
+        fmt.Printf("%q\n", word)
+    }
+
+    // Output:
+    // "Don't"
+    // "panic"
+    // "it's"
+    // "3.5"
+    // "C"
+}
+
+func ExampleLineBreaks() {
+    s := "The quick (\"brown\") fox can't jump 32.3 feet, right?\nYes."
+
+    // wrap onto lines of at most 20 bytes
+    start, last := 0, 0
+    for _, b := range iter.ToSlice(segment.LineBreaks(s)) {
+        if (b.Offset - start > 20) && (last > start) {
+            fmt.Printf("%q\n", s[start:last])
+            start = last
+        }
+        if b.Mandatory {
+            fmt.Printf("%q\n", s[start:b.Offset])
+            start = b.Offset
+        }
+        last = b.Offset
+    }
+    if start < len(s) { fmt.Printf("%q\n", s[start:]) }
+
+    // Output:
+    // "The quick (\"brown\") "
+    // "fox can't jump 32.3 "
+    // "feet, right?\n"
+    // "Yes."
+}
+
+func ExampleBreaker() {
+    s := "Hello, 世界! 👩\u200D💻"
+
+    scanner := bufio.NewScanner(strings.NewReader(s))
+    scanner.Split(segment.WordBreaker.SplitFunc)
+
+    var words []string
+    for scanner.Scan() {
+        words = append(words, scanner.Text())
+    }
+    fmt.Printf("%q\n", words)
+
+    // Output:
+    // ["Hello" "," " " "世" "界" "!" " " "👩\u200d💻"]
+}
@@ -0,0 +1,46 @@
+package segment
+
+import (
+    _ "embed"
+)
+
+// gcb is a value of the Grapheme_Cluster_Break property, with the addition
+// of gcbExtPict for the Extended_Pictographic property.
+type gcb uint8
+
+const (
+    gcbOther gcb = iota
+    gcbCR
+    gcbLF
+    gcbControl
+    gcbExtend
+    gcbZWJ
+    gcbRegionalIndicator
+    gcbPrepend
+    gcbSpacingMark
+    gcbL
+    gcbV
+    gcbT
+    gcbLV
+    gcbLVT
+    gcbExtPict
+)
+
+// gcbBin contains packed Grapheme_Cluster_Break values for ordered ranges of
+// codepoints, with gcbExtPict for the Extended_Pictographic property.
+//go:embed gcb.bin
+var gcbBin []byte // generated by internal/unicode/gen.sh
+
+var gcbTable = newTable(gcbBin)
+
+// graphemeClusterBreak returns the Grapheme_Cluster_Break property of a rune,
+// or gcbExtPict if it has the Extended_Pictographic property.
+func graphemeClusterBreak(r rune) gcb {
+    return gcb(gcbTable.lookup(r))
+}
+
+// extendedPictographic returns true if a rune has the Extended_Pictographic
+// property.
+func extendedPictographic(r rune) bool {
+    return graphemeClusterBreak(r) == gcbExtPict
+}
@@ -0,0 +1,134 @@
+package segment
+
+import (
+    "bufio"
+
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// Graphemes returns an iterator that produces each extended grapheme cluster
+// in a string, from left to right.
+//
+// Each byte of invalid UTF-8 is treated as a single U+FFFD REPLACEMENT
+// CHARACTER.
+func Graphemes(s string) iter.It[string] {
+    return func() (string, bool) {
+        if len(s) == 0 { return "", false }
+        n, _ := FirstGrapheme(s, true)
+        cluster := s[:n]
+        s = s[n:]
+        return cluster, true
+    }
+}
+
+// CountGraphemes returns the number of extended grapheme clusters in a
+// string.
+func CountGraphemes(s string) int {
+    var count int
+    for len(s) > 0 {
+        n, _ := FirstGrapheme(s, true)
+        s = s[n:]
+        count++
+    }
+    return count
+}
+
+// ScanGraphemes is a [bufio.SplitFunc] for a [bufio.Scanner] that returns
+// each extended grapheme cluster as a token.
+func ScanGraphemes(data []byte, atEOF bool) (advance int, token []byte, err error) {
+    if atEOF && (len(data) == 0) { return 0, nil, nil }
+    n, ok := FirstGrapheme(data, atEOF)
+    if !ok { return 0, nil, nil } // request more data
+    return n, data[:n], nil
+}
+
+var _ bufio.SplitFunc = ScanGraphemes
+
+// FirstGrapheme returns the length, in bytes, of the first extended grapheme
+// cluster in s, which may be a string or a byte slice.
+//
+// If atEOF is false, then s may be followed by more input. In this case, if
+// the end of the cluster cannot be found without more input, returns (0,
+// false). Otherwise, returns (length, true). This is intended for use, for
+// example, in implementing a [transform.Transformer], which would return
+// [transform.ErrShortSrc] if more input is needed.
+//
+// If s is empty, returns (0, atEOF).
+//
+// [transform.Transformer]: https://pkg.go.dev/golang.org/x/text/transform#Transformer
+// [transform.ErrShortSrc]: https://pkg.go.dev/golang.org/x/text/transform#ErrShortSrc
+func FirstGrapheme[T string | []byte](s T, atEOF bool) (int, bool) {
+    if len(s) == 0 { return 0, atEOF }
+    if (!atEOF) && (!fullRune(s)) { return 0, false }
+
+    r, size := decodeRune(s)
+    prev := graphemeClusterBreak(r)
+    i := size
+    if (prev == gcbControl) || (prev == gcbLF) { return i, true } // GB4
+
+    // state for rules GB11, GB12 and GB13
+    regionalIndicators := 0
+    if prev == gcbRegionalIndicator { regionalIndicators = 1 }
+    pictographic := prev == gcbExtPict // ExtPict Extend*
+    pictographicZWJ := false           // ExtPict Extend* ZWJ
+
+    for i < len(s) {
+        if (!atEOF) && (!fullRune(s[i:])) { return 0, false }
+        r, size = decodeRune(s[i:])
+        next := graphemeClusterBreak(r)
+
+        if graphemeBoundary(prev, next, regionalIndicators, pictographicZWJ) {
+            return i, true
+        }
+
+        if next == gcbRegionalIndicator {
+            regionalIndicators++
+        } else {
+            regionalIndicators = 0
+        }
+        pictographicZWJ = pictographic && (next == gcbZWJ)
+        pictographic = (next == gcbExtPict) || (pictographic && (next == gcbExtend))
+
+        prev = next
+        i += size
+        if prev == gcbLF { return i, true } // GB3, GB4
+    }
+
+    if atEOF { return len(s), true }
+    return 0, false
+}
+
+// graphemeBoundary returns true if there is an extended grapheme cluster
+// boundary between two runes, given the number of consecutive regional
+// indicators ending with prev, and whether prev ends a sequence of an
+// Extended_Pictographic rune, any number of Extend runes, then a ZWJ.
+func graphemeBoundary(prev, next gcb, regionalIndicators int, pictographicZWJ bool) bool {
+    switch {
+        case (prev == gcbCR) && (next == gcbLF): // GB3
+            return false
+        case (prev == gcbControl) || (prev == gcbCR) || (prev == gcbLF): // GB4
+            return true
+        case (next == gcbControl) || (next == gcbCR) || (next == gcbLF): // GB5
+            return true
+        case (prev == gcbL) &&
+            ((next == gcbL) || (next == gcbV) || (next == gcbLV) || (next == gcbLVT)): // GB6
+            return false
+        case ((prev == gcbLV) || (prev == gcbV)) &&
+            ((next == gcbV) || (next == gcbT)): // GB7
+            return false
+        case ((prev == gcbLVT) || (prev == gcbT)) && (next == gcbT): // GB8
+            return false
+        case (next == gcbExtend) || (next == gcbZWJ): // GB9
+            return false
+        case next == gcbSpacingMark: // GB9a
+            return false
+        case prev == gcbPrepend: // GB9b
+            return false
+        case pictographicZWJ && (next == gcbExtPict): // GB11
+            return false
+        case (prev == gcbRegionalIndicator) && (next == gcbRegionalIndicator): // GB12, GB13
+            return regionalIndicators % 2 == 0
+        default: // GB999
+            return true
+    }
+}
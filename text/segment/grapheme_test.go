@@ -0,0 +1,119 @@
+package segment_test
+
+import (
+    "bufio"
+    "strings"
+    "testing"
+    "testing/iotest"
+
+    "github.com/tawesoft/golib/v2/iter"
+    "github.com/tawesoft/golib/v2/text/segment"
+)
+
+func TestGraphemes(t *testing.T) {
+    type row struct {
+        input string
+        expected []string
+    }
+    rows := []row{
+        {"", nil},
+        {"abc", []string{"a", "b", "c"}},
+        {"\r\n\n\r", []string{"\r\n", "\n", "\r"}},
+        {"a\u0301\u0302b", []string{"a\u0301\u0302", "b"}}, // combining marks
+        {"\u0301a", []string{"\u0301", "a"}},                 // isolated mark
+        {"a\u200Cb", []string{"a\u200C", "b"}},               // ZWNJ is Extend
+        {"\t\u0301", []string{"\t", "\u0301"}},               // GB4
+        {"\uAC01\uAC00\u11A8", []string{"\uAC01", "\uAC00\u11A8"}}, // LVT, LV T
+        {"\u1100\u1161\u11A8", []string{"\u1100\u1161\u11A8"}},     // L V T
+        {"\u1100\uAC00", []string{"\u1100\uAC00"}},                 // L LV
+        {"\u11A8\u1100", []string{"\u11A8", "\u1100"}},             // T L
+        {"🇬🇧🇫🇷🇩", []string{"🇬🇧", "🇫🇷", "🇩"}},                // flags
+        {"a🇬🇧", []string{"a", "🇬🇧"}},
+        {"👍🏽👍", []string{"👍🏽", "👍"}},                        // emoji modifier
+        {"👩\u200D💻", []string{"👩\u200D💻"}},                 // GB11
+        {"👩\u0301\u200D💻", []string{"👩\u0301\u200D💻"}},     // GB11 with Extend
+        {"a\u200D💻", []string{"a\u200D", "💻"}},               // not GB11
+        {"\u0600123", []string{"\u06001", "2", "3"}},         // Prepend
+        {"\u0E01\u0E33", []string{"\u0E01\u0E33"}},           // SpacingMark
+        {"\u0915\u093F", []string{"\u0915\u093F"}},           // SpacingMark
+        {"\xffa\xfe", []string{"\xff", "a", "\xfe"}},         // invalid UTF-8
+    }
+    for _, r := range rows {
+        got := iter.ToSlice(segment.Graphemes(r.input))
+        if strings.Join(got, "|") != strings.Join(r.expected, "|") || (len(got) != len(r.expected)) {
+            t.Errorf("Graphemes(%q): got %q, expected %q", r.input, got, r.expected)
+        }
+        if n := segment.CountGraphemes(r.input); n != len(r.expected) {
+            t.Errorf("CountGraphemes(%q): got %d, expected %d", r.input, n, len(r.expected))
+        }
+    }
+}
+
+// TestGraphemes_conformance tests every case in the Unicode
+// GraphemeBreakTest.txt file.
+func TestGraphemes_conformance(t *testing.T) {
+    for _, test := range readBreakTests(t, "testdata/GraphemeBreakTest.txt") {
+        got := iter.ToSlice(segment.Graphemes(test.input))
+        if strings.Join(got, "|") != strings.Join(test.expected, "|") || (len(got) != len(test.expected)) {
+            t.Errorf("line %d: Graphemes(%+q): got %+q, expected %+q\n  %s",
+                test.line, test.input, got, test.expected, test.comment)
+        }
+    }
+}
+
+func TestFirstGrapheme(t *testing.T) {
+    type row struct {
+        input string
+        atEOF bool
+        expectedN int
+        expectedOk bool
+    }
+    rows := []row{
+        {"", false, 0, false},
+        {"", true, 0, true},
+        {"a", false, 0, false},
+        {"a", true, 1, true},
+        {"ab", false, 1, true},
+        {"\r", false, 0, false},
+        {"\r\n", false, 2, true},
+        {"\r\na", false, 2, true},
+        {"\n", false, 1, true},
+        {"e\u0301", false, 0, false},
+        {"e\u0301x", false, 3, true},
+        {"\xe2\x82", false, 0, false}, // incomplete rune
+        {"\xe2\x82", true, 1, true},
+    }
+    for _, r := range rows {
+        n, ok := segment.FirstGrapheme(r.input, r.atEOF)
+        if (n != r.expectedN) || (ok != r.expectedOk) {
+            t.Errorf("FirstGrapheme(%q, %t): got (%d, %t), expected (%d, %t)",
+                r.input, r.atEOF, n, ok, r.expectedN, r.expectedOk)
+        }
+        n, ok = segment.FirstGrapheme([]byte(r.input), r.atEOF)
+        if (n != r.expectedN) || (ok != r.expectedOk) {
+            t.Errorf("FirstGrapheme([]byte(%q), %t): got (%d, %t), expected (%d, %t)",
+                r.input, r.atEOF, n, ok, r.expectedN, r.expectedOk)
+        }
+    }
+}
+
+func TestScanGraphemes(t *testing.T) {
+    input := strings.Repeat("🇬🇧e\u0301\r\n👩\u200D💻", 1000)
+    expected := iter.ToSlice(segment.Graphemes(input))
+
+    // reading one byte at a time means the scanner must request more data
+    // in the middle of clusters
+    scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+    scanner.Split(segment.ScanGraphemes)
+
+    var got []string
+    for scanner.Scan() {
+        got = append(got, scanner.Text())
+    }
+    if err := scanner.Err(); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if (len(got) != 4000) || (strings.Join(got, "|") != strings.Join(expected, "|")) {
+        t.Errorf("ScanGraphemes: got %d clusters, expected %d", len(got), len(expected))
+    }
+}
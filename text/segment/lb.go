@@ -0,0 +1,87 @@
+package segment
+
+import (
+    _ "embed"
+)
+
+// lb is a value of the Line_Break property, after the resolution of classes
+// AI, CJ, SA, SG, and XX by rule LB1.
+type lb uint8
+
+const (
+    lbAL lb = iota // Alphabetic (also AI, SA, SG, XX)
+    lbBK           // Mandatory Break
+    lbCR           // Carriage Return
+    lbLF           // Line Feed
+    lbNL           // Next Line
+    lbCM           // Combining Mark
+    lbZWJ          // Zero Width Joiner
+    lbWJ           // Word Joiner
+    lbZW           // Zero Width Space
+    lbGL           // Non-breaking ("Glue")
+    lbSP           // Space
+    lbB2           // Break Opportunity Before and After
+    lbBA           // Break After
+    lbBB           // Break Before
+    lbHY           // Hyphen
+    lbCB           // Contingent Break Opportunity
+    lbCL           // Close Punctuation
+    lbCP           // Close Parenthesis
+    lbEX           // Exclamation/Interrogation
+    lbIN           // Inseparable
+    lbNS           // Nonstarter (also CJ)
+    lbOP           // Open Punctuation
+    lbQU           // Quotation
+    lbIS           // Infix Numeric Separator
+    lbNU           // Numeric
+    lbPO           // Postfix Numeric
+    lbPR           // Prefix Numeric
+    lbSY           // Symbols Allowing Break After
+    lbEB           // Emoji Base
+    lbEM           // Emoji Modifier
+    lbH2           // Hangul LV Syllable
+    lbH3           // Hangul LVT Syllable
+    lbHL           // Hebrew Letter
+    lbID           // Ideographic
+    lbJL           // Hangul L Jamo
+    lbJV           // Hangul V Jamo
+    lbJT           // Hangul T Jamo
+    lbRI           // Regional Indicator
+)
+
+// Flags set, in addition to the class, in the values of lbTable.
+const (
+    // lbEastAsian is set for runes with an East_Asian_Width of Fullwidth,
+    // Wide, or Halfwidth.
+    lbEastAsian = 0x40
+
+    // lbPictographicUnassigned is set for unassigned code points with the
+    // Extended_Pictographic property.
+    lbPictographicUnassigned = 0x80
+
+    lbClassMask = 0x3F
+)
+
+// lbBin contains packed Line_Break values, after rule LB1, for ordered ranges
+// of codepoints, with the flags above.
+//go:embed lb.bin
+var lbBin []byte // generated by internal/unicode/gen.sh
+
+var lbTable = newTable(lbBin)
+
+// lineBreak returns the Line_Break property of a rune, after rule LB1.
+func lineBreak(r rune) lb {
+    return lb(lbTable.lookup(r) & lbClassMask)
+}
+
+// eastAsian returns true for runes with an East_Asian_Width of Fullwidth,
+// Wide, or Halfwidth.
+func eastAsian(r rune) bool {
+    return lbTable.lookup(r) & lbEastAsian != 0
+}
+
+// pictographicUnassigned returns true for unassigned code points with the
+// Extended_Pictographic property, which are reserved for future emoji.
+func pictographicUnassigned(r rune) bool {
+    return lbTable.lookup(r) & lbPictographicUnassigned != 0
+}
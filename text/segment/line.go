@@ -0,0 +1,312 @@
+package segment
+
+import (
+    "bufio"
+
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// LineBreak is a line break opportunity in some text.
+type LineBreak struct {
+    // Offset is the offset, in bytes, where the text may be broken onto a
+    // new line. This is the start of the next line, which means that any
+    // spaces are left at the end of the previous line.
+    Offset int
+
+    // Mandatory is true if the text must be broken here, e.g. after a
+    // newline.
+    Mandatory bool
+}
+
+// LineBreaks returns an iterator that produces each line break opportunity in
+// a string, according to the rules of
+// [Unicode Standard Annex #14: Unicode Line Breaking Algorithm], from left to
+// right. The end of a non-empty string is always a line break opportunity.
+//
+// Only the default rules are implemented: in particular, there is no
+// dictionary for breaking text in scripts that are written without spaces
+// between words, such as Thai. The exception is rule LB25, which uses the
+// tailoring for numbers given as an example in the standard (and used by
+// its LineBreakTest.txt), so that e.g. "$(12.50)" is not broken, but "a.2"
+// may be broken after the full stop.
+//
+// [Unicode Standard Annex #14: Unicode Line Breaking Algorithm]: https://www.unicode.org/reports/tr14/
+func LineBreaks(s string) iter.It[LineBreak] {
+    offset := 0
+    return func() (LineBreak, bool) {
+        if offset >= len(s) { return LineBreak{}, false }
+        n, mandatory, _ := FirstLineSegment(s[offset:], true)
+        offset += n
+        return LineBreak{Offset: offset, Mandatory: mandatory}, true
+    }
+}
+
+// LineSegments returns an iterator that produces each segment of a string
+// between line break opportunities, from left to right. See [LineBreaks].
+//
+// For example, "The quick (\"brown\") fox can't jump 32.3 feet, right?" is
+// segmented as "The ", "quick ", "(\"brown\") ", "fox ", "can't ", "jump ",
+// "32.3 ", "feet, ", "right?".
+func LineSegments(s string) iter.It[string] {
+    return func() (string, bool) {
+        if len(s) == 0 { return "", false }
+        n, _, _ := FirstLineSegment(s, true)
+        segment := s[:n]
+        s = s[n:]
+        return segment, true
+    }
+}
+
+// ScanLineSegments is a [bufio.SplitFunc] for a [bufio.Scanner] that returns
+// each segment between line break opportunities as a token. See
+// [LineSegments].
+func ScanLineSegments(data []byte, atEOF bool) (advance int, token []byte, err error) {
+    if atEOF && (len(data) == 0) { return 0, nil, nil }
+    n, _, ok := FirstLineSegment(data, atEOF)
+    if !ok { return 0, nil, nil } // request more data
+    return n, data[:n], nil
+}
+
+var _ bufio.SplitFunc = ScanLineSegments
+
+// FirstLineSegment returns the length, in bytes, of the first segment between
+// line break opportunities in s, which may be a string or a byte slice, and
+// whether the line break at the end of the segment is mandatory. See
+// [FirstGrapheme] for the meaning of atEOF and the ok return value.
+func FirstLineSegment[T string | []byte](s T, atEOF bool) (n int, mandatory bool, ok bool) {
+    if len(s) == 0 { return 0, false, atEOF }
+    if (!atEOF) && (!fullRune(s)) { return 0, false, false }
+
+    r, size := decodeRune(s)
+    raw := lineBreak(r)
+    i := size
+
+    // The class of the previous rune (raw), and the classes of the previous
+    // two runes after rules LB9 and LB10 (prev, prevPrev). If prev is a
+    // space, beforeSpaces is the class before the spaces.
+    prev, prevRune := raw, r
+    if (prev == lbCM) || (prev == lbZWJ) { prev = lbAL } // LB10
+    prevPrev, beforeSpaces := lbAL, lbAL
+    regionalIndicators := 0
+    if prev == lbRI { regionalIndicators = 1 }
+    number := lbNumberNone.then(prev)
+
+    for i < len(s) {
+        if (raw == lbBK) || (raw == lbLF) || (raw == lbNL) { return i, true, true } // LB4, LB5
+        if (!atEOF) && (!fullRune(s[i:])) { return 0, false, false }
+        r, size = decodeRune(s[i:])
+        next := lineBreak(r)
+
+        // find the class of the rune after this one, ignoring combining
+        // marks by rule LB9, only if needed by rule LB25.
+        lookahead := func() (lb, bool) {
+            for j := i + size; j < len(s); {
+                if (!atEOF) && (!fullRune(s[j:])) { return lbAL, false }
+                r, size := decodeRune(s[j:])
+                c := lineBreak(r)
+                if (c != lbCM) && (c != lbZWJ) { return c, true }
+                j += size
+            }
+            return lbAL, atEOF
+        }
+
+        action, ok := lineBoundary(raw, prev, prevPrev, beforeSpaces, prevRune,
+            next, r, regionalIndicators, number, lookahead)
+        if !ok { return 0, false, false }
+        switch action {
+            case lineBreakOpportunity: return i, false, true
+            case lineBreakMandatory:   return i, true, true
+        }
+
+        raw = next
+        i += size
+
+        switch next {
+            case lbCM, lbZWJ:
+                switch prev {
+                    case lbBK, lbCR, lbLF, lbNL, lbSP, lbZW:
+                        next = lbAL // LB10
+                    default:
+                        continue // LB9
+                }
+        }
+
+        if (next == lbSP) && (prev != lbSP) { beforeSpaces = prev }
+        if next == lbRI {
+            regionalIndicators++
+        } else {
+            regionalIndicators = 0
+        }
+        number = number.then(next)
+        prevPrev, prev, prevRune = prev, next, r
+    }
+
+    if atEOF {
+        mandatory := (raw == lbBK) || (raw == lbCR) || (raw == lbLF) || (raw == lbNL)
+        return len(s), mandatory, true // LB3
+    }
+    return 0, false, false
+}
+
+type lineBreakAction uint8
+
+const (
+    lineBreakProhibited lineBreakAction = iota
+    lineBreakOpportunity
+    lineBreakMandatory
+)
+
+// lbNumber is the state of a number matched by rule LB25, which is
+// NU (NU | SY | IS)* (CL | CP)?.
+type lbNumber uint8
+
+const (
+    lbNumberNone   lbNumber = iota
+    lbNumberDigits // NU (NU | SY | IS)*
+    lbNumberClose  // NU (NU | SY | IS)* (CL | CP)
+)
+
+// then returns the state of a number after a rune of the given class.
+func (n lbNumber) then(c lb) lbNumber {
+    switch {
+        case c == lbNU:
+            return lbNumberDigits
+        case (n == lbNumberDigits) && ((c == lbSY) || (c == lbIS)):
+            return lbNumberDigits
+        case (n == lbNumberDigits) && ((c == lbCL) || (c == lbCP)):
+            return lbNumberClose
+        default:
+            return lbNumberNone
+    }
+}
+
+// lineBoundary returns the line breaking action before a rune, given its
+// class (next), the class of the rune before it (raw), the classes of the
+// previous two runes after rules LB9 and LB10 (prev, prevPrev), the rune
+// with class prev (prevRune), the class before a sequence of spaces if prev
+// is a space (beforeSpaces), the number of consecutive regional indicators
+// ending with prev, and the state of a number ending with prev.
+//
+// The lookahead function returns the class of the rune after next, ignoring
+// combining marks, or false if more input is needed. In that case,
+// lineBoundary also returns false for its second return value.
+func lineBoundary(
+    raw lb,
+    prev, prevPrev, beforeSpaces lb, prevRune rune,
+    next lb, nextRune rune,
+    regionalIndicators int,
+    number lbNumber,
+    lookahead func() (lb, bool),
+) (lineBreakAction, bool) {
+    in := func(x lb, xs ... lb) bool {
+        for _, y := range xs {
+            if x == y { return true }
+        }
+        return false
+    }
+
+    // peek returns true if the rune after next has the given class.
+    needMore := false
+    peek := func(c lb) bool {
+        after, ok := lookahead()
+        if !ok { needMore = true }
+        return ok && (after == c)
+    }
+
+    // prev, or the class before spaces if prev is a space, for rules of the
+    // form "X SP* ×".
+    beforeSP := prev
+    if prev == lbSP { beforeSP = beforeSpaces }
+
+    switch {
+        case raw == lbBK: // LB4
+            return lineBreakMandatory, true
+        case (raw == lbCR) && (next == lbLF): // LB5
+            return lineBreakProhibited, true
+        case in(raw, lbCR, lbLF, lbNL): // LB5
+            return lineBreakMandatory, true
+        case in(next, lbBK, lbCR, lbLF, lbNL): // LB6
+            return lineBreakProhibited, true
+        case in(next, lbSP, lbZW): // LB7
+            return lineBreakProhibited, true
+        case beforeSP == lbZW: // LB8
+            return lineBreakOpportunity, true
+        case raw == lbZWJ: // LB8a
+            return lineBreakProhibited, true
+        case in(next, lbCM, lbZWJ) && !in(prev, lbBK, lbCR, lbLF, lbNL, lbSP, lbZW): // LB9
+            return lineBreakProhibited, true
+    }
+
+    if in(next, lbCM, lbZWJ) { next = lbAL } // LB10
+
+    switch {
+        case (next == lbWJ) || (prev == lbWJ): // LB11
+            return lineBreakProhibited, true
+        case prev == lbGL: // LB12
+            return lineBreakProhibited, true
+        case (next == lbGL) && !in(prev, lbSP, lbBA, lbHY): // LB12a
+            return lineBreakProhibited, true
+        case in(next, lbCL, lbCP, lbEX, lbIS, lbSY): // LB13
+            return lineBreakProhibited, true
+        case beforeSP == lbOP: // LB14
+            return lineBreakProhibited, true
+        case (beforeSP == lbQU) && (next == lbOP): // LB15
+            return lineBreakProhibited, true
+        case in(beforeSP, lbCL, lbCP) && (next == lbNS): // LB16
+            return lineBreakProhibited, true
+        case (beforeSP == lbB2) && (next == lbB2): // LB17
+            return lineBreakProhibited, true
+        case prev == lbSP: // LB18
+            return lineBreakOpportunity, true
+        case (next == lbQU) || (prev == lbQU): // LB19
+            return lineBreakProhibited, true
+        case (next == lbCB) || (prev == lbCB): // LB20
+            return lineBreakOpportunity, true
+        case in(next, lbBA, lbHY, lbNS) || (prev == lbBB): // LB21
+            return lineBreakProhibited, true
+        case (prevPrev == lbHL) && in(prev, lbHY, lbBA): // LB21a
+            return lineBreakProhibited, true
+        case (prev == lbSY) && (next == lbHL): // LB21b
+            return lineBreakProhibited, true
+        case next == lbIN: // LB22
+            return lineBreakProhibited, true
+        case in(prev, lbAL, lbHL) && (next == lbNU), // LB23
+            (prev == lbNU) && in(next, lbAL, lbHL):
+            return lineBreakProhibited, true
+        case (prev == lbPR) && in(next, lbID, lbEB, lbEM), // LB23a
+            in(prev, lbID, lbEB, lbEM) && (next == lbPO):
+            return lineBreakProhibited, true
+        case in(prev, lbPR, lbPO) && in(next, lbAL, lbHL), // LB24
+            in(prev, lbAL, lbHL) && in(next, lbPR, lbPO):
+            return lineBreakProhibited, true
+        case in(prev, lbPR, lbPO) && (next == lbNU), // LB25
+            in(prev, lbPR, lbPO) && in(next, lbOP, lbHY) && peek(lbNU),
+            in(prev, lbOP, lbHY) && (next == lbNU),
+            (number == lbNumberDigits) && in(next, lbNU, lbSY, lbIS, lbCL, lbCP),
+            (number != lbNumberNone) && in(next, lbPO, lbPR):
+            return lineBreakProhibited, true
+        case (prev == lbJL) && in(next, lbJL, lbJV, lbH2, lbH3), // LB26
+            in(prev, lbJV, lbH2) && in(next, lbJV, lbJT),
+            in(prev, lbJT, lbH3) && (next == lbJT):
+            return lineBreakProhibited, true
+        case in(prev, lbJL, lbJV, lbJT, lbH2, lbH3) && (next == lbPO), // LB27
+            (prev == lbPR) && in(next, lbJL, lbJV, lbJT, lbH2, lbH3):
+            return lineBreakProhibited, true
+        case in(prev, lbAL, lbHL) && in(next, lbAL, lbHL): // LB28
+            return lineBreakProhibited, true
+        case (prev == lbIS) && in(next, lbAL, lbHL): // LB29
+            return lineBreakProhibited, true
+        case in(prev, lbAL, lbHL, lbNU) && (next == lbOP) && !eastAsian(nextRune), // LB30
+            (prev == lbCP) && !eastAsian(prevRune) && in(next, lbAL, lbHL, lbNU):
+            return lineBreakProhibited, true
+        case (prev == lbRI) && (next == lbRI): // LB30a
+            if regionalIndicators % 2 == 1 { return lineBreakProhibited, true }
+            return lineBreakOpportunity, true
+        case (prev == lbEB) && (next == lbEM), // LB30b
+            pictographicUnassigned(prevRune) && (next == lbEM):
+            return lineBreakProhibited, true
+    }
+
+    if needMore { return lineBreakProhibited, false }
+    return lineBreakOpportunity, true // LB31
+}
@@ -0,0 +1,140 @@
+package segment_test
+
+import (
+    "bufio"
+    "strings"
+    "testing"
+    "testing/iotest"
+
+    "github.com/tawesoft/golib/v2/iter"
+    "github.com/tawesoft/golib/v2/text/segment"
+)
+
+func TestLineSegments(t *testing.T) {
+    type row struct {
+        input string
+        expected []string
+    }
+    rows := []row{
+        {"", nil},
+        {"hello world", []string{"hello ", "world"}},
+        {"a  b", []string{"a  ", "b"}},                                   // LB7, LB18
+        {"a\r\nb\nc", []string{"a\r\n", "b\n", "c"}},                     // LB5
+        {"(a) b", []string{"(a) ", "b"}},                                 // LB13, LB14
+        {"( a", []string{"( a"}},                                         // LB14
+        {"a !", []string{"a !"}},                                         // LB13
+        {"well-known", []string{"well-", "known"}},                       // LB21
+        {"$100.00 50%", []string{"$100.00 ", "50%"}},                     // LB25
+        {"$(12.50) a.2", []string{"$(12.50) ", "a.", "2"}},               // LB25
+        {"a\u00A0b", []string{"a\u00A0b"}},                               // LB12
+        {"a\u200Bb", []string{"a\u200B", "b"}},                           // LB8
+        {"a\u2060b", []string{"a\u2060b"}},                               // LB11
+        {"日本語", []string{"日", "本", "語"}},
+        {"日本。", []string{"日", "本。"}},                                   // LB13
+        {"あぁ", []string{"あぁ"}},                       // small kana
+        {"각", []string{"각"}},                       // LB26
+        {"🇬🇧🇫🇷", []string{"🇬🇧", "🇫🇷"}},                                   // LB30a
+        {"👍🏽👍", []string{"👍🏽", "👍"}},                                   // LB30b
+        {"a\u0301 b", []string{"a\u0301 ", "b"}},                         // LB9
+        {" \u0301", []string{" ", "\u0301"}},                              // LB10
+        {"f(x)", []string{"f(x)"}},                                       // LB30
+        {"\uFF08x\uFF09x", []string{"\uFF08x\uFF09", "x"}},                  // LB30
+        {"\"a\" b", []string{"\"a\" ", "b"}},                             // LB19
+        {"a—b", []string{"a", "—", "b"}},                                 // B2
+    }
+    for _, r := range rows {
+        got := iter.ToSlice(segment.LineSegments(r.input))
+        if (len(got) != len(r.expected)) || (strings.Join(got, "|") != strings.Join(r.expected, "|")) {
+            t.Errorf("LineSegments(%q): got %q, expected %q", r.input, got, r.expected)
+        }
+    }
+}
+
+// TestLineSegments_conformance tests every case in the Unicode
+// LineBreakTest.txt file.
+func TestLineSegments_conformance(t *testing.T) {
+    for _, test := range readBreakTests(t, "testdata/LineBreakTest.txt") {
+        got := iter.ToSlice(segment.LineSegments(test.input))
+        if strings.Join(got, "|") != strings.Join(test.expected, "|") || (len(got) != len(test.expected)) {
+            t.Errorf("line %d: LineSegments(%+q): got %+q, expected %+q\n  %s",
+                test.line, test.input, got, test.expected, test.comment)
+        }
+    }
+}
+
+func TestFirstLineSegment(t *testing.T) {
+    type row struct {
+        input string
+        atEOF bool
+        expectedN int
+        expectedMandatory bool
+        expectedOk bool
+    }
+    rows := []row{
+        {"", false, 0, false, false},
+        {"", true, 0, false, true},
+        {"a b", false, 2, false, true},
+        {"a\nb", false, 2, true, true},
+        {"a\r", false, 0, false, false},
+        {"a\r", true, 2, true, true},
+        {"$(", false, 0, false, false}, // needs lookahead for LB25
+        {"$(", true, 1, false, true},
+        {"$(\u0308a", false, 1, false, true},
+        {"$(1 a", false, 4, false, true},
+    }
+    for _, r := range rows {
+        n, mandatory, ok := segment.FirstLineSegment(r.input, r.atEOF)
+        if (n != r.expectedN) || (mandatory != r.expectedMandatory) || (ok != r.expectedOk) {
+            t.Errorf("FirstLineSegment(%q, %t): got (%d, %t, %t), expected (%d, %t, %t)",
+                r.input, r.atEOF, n, mandatory, ok, r.expectedN, r.expectedMandatory, r.expectedOk)
+        }
+    }
+}
+
+func TestLineBreaks(t *testing.T) {
+    got := iter.ToSlice(segment.LineBreaks("a b\nc\r\n"))
+    expected := []segment.LineBreak{
+        {Offset: 2, Mandatory: false},
+        {Offset: 4, Mandatory: true},
+        {Offset: 7, Mandatory: true},
+    }
+    if len(got) != len(expected) {
+        t.Fatalf("LineBreaks: got %v, expected %v", got, expected)
+    }
+    for i := range got {
+        if got[i] != expected[i] {
+            t.Errorf("LineBreaks: got %v, expected %v", got, expected)
+        }
+    }
+}
+
+func TestBreaker_SplitFunc(t *testing.T) {
+    input := strings.Repeat("Can't 3.5°C — \"日本\" 🇬🇧🇫🇷 a.\u0301b, e\u0301!\r\n", 100)
+
+    type row struct {
+        name string
+        breaker segment.Breaker
+        expected []string
+    }
+    rows := []row{
+        {"graphemes", segment.GraphemeBreaker, iter.ToSlice(segment.Graphemes(input))},
+        {"words",     segment.WordBreaker,     iter.ToSlice(segment.Words(input))},
+        {"lines",     segment.LineBreaker,     iter.ToSlice(segment.LineSegments(input))},
+    }
+    for _, r := range rows {
+        // reading one byte at a time means the scanner must request more
+        // data in the middle of segments
+        scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+        scanner.Split(r.breaker.SplitFunc)
+
+        var got []string
+        for scanner.Scan() {
+            got = append(got, scanner.Text())
+        }
+        if err := scanner.Err(); err != nil {
+            t.Errorf("%s: unexpected error: %v", r.name, err)
+        } else if (len(got) != len(r.expected)) || (strings.Join(got, "|") != strings.Join(r.expected, "|")) {
+            t.Errorf("%s: got %d segments, expected %d", r.name, len(got), len(r.expected))
+        }
+    }
+}
@@ -0,0 +1,91 @@
+// Package segment implements the segmentation of Unicode text into grapheme
+// clusters (user-perceived characters) and words, according to the rules of
+// [Unicode Standard Annex #29: Unicode Text Segmentation], and into line
+// segments between line break opportunities, according to the rules of
+// [Unicode Standard Annex #14: Unicode Line Breaking Algorithm].
+//
+// For example, the text "🇬🇧é" is three code points, but two grapheme
+// clusters: a flag, and the letter "e" with an acute accent.
+//
+// Segments are available as an iterator (for a string), as a
+// [bufio.SplitFunc] (for a [bufio.Scanner]), or as a function that finds the
+// length of the first segment in a buffer that may not yet contain all the
+// input, which is useful for implementing a [transform.Transformer].
+//
+// A [Breaker] can be used to write code, such as for text layout or search,
+// that works with any of these types of segmentation.
+//
+// The character properties are generated from the Unicode Character
+// Database, version 15.0.0 (the same version as the Go standard library
+// [unicode] package), and each type of segmentation passes every case in its
+// GraphemeBreakTest.txt, WordBreakTest.txt, and LineBreakTest.txt.
+//
+// Note that rule GB9c (Indic conjunct breaks), added in Unicode 15.1, is not
+// implemented, so e.g. the Devanagari "स्ते" is segmented as two clusters, as
+// it was in earlier versions of Unicode.
+//
+// [Unicode Standard Annex #29: Unicode Text Segmentation]: https://www.unicode.org/reports/tr29/
+// [Unicode Standard Annex #14: Unicode Line Breaking Algorithm]: https://www.unicode.org/reports/tr14/
+// [transform.Transformer]: https://pkg.go.dev/golang.org/x/text/transform#Transformer
+package segment
+
+import (
+    "unicode/utf8"
+)
+
+// text is a string or byte slice that can be segmented.
+type text interface {
+    string | []byte
+}
+
+// decodeRune decodes the first rune in a string or byte slice.
+func decodeRune[T text](s T) (rune, int) {
+    switch v := any(s).(type) {
+        case string: return utf8.DecodeRuneInString(v)
+        case []byte: return utf8.DecodeRune(v)
+        default:     return utf8.RuneError, 0 // unreachable
+    }
+}
+
+// fullRune returns true if s begins with a complete UTF-8 encoding of a rune,
+// or an invalid encoding that can never become valid with more input.
+func fullRune[T text](s T) bool {
+    switch v := any(s).(type) {
+        case string: return utf8.FullRuneInString(v)
+        case []byte: return utf8.FullRune(v)
+        default:     return true // unreachable
+    }
+}
+
+// Breaker is a function that returns the length, in bytes, of the first
+// segment in b. See [FirstGrapheme] for the meaning of atEOF and the return
+// values.
+//
+// This allows code, such as text layout or search, to be written for any of
+// the types of segmentation in this package.
+type Breaker func(b []byte, atEOF bool) (int, bool)
+
+var (
+    // GraphemeBreaker is a Breaker for extended grapheme clusters
+    // (see [FirstGrapheme]).
+    GraphemeBreaker Breaker = FirstGrapheme[[]byte]
+
+    // WordBreaker is a Breaker for word boundaries (see [FirstWord]).
+    WordBreaker Breaker = FirstWord[[]byte]
+
+    // LineBreaker is a Breaker for line break opportunities
+    // (see [FirstLineSegment]).
+    LineBreaker Breaker = func(b []byte, atEOF bool) (int, bool) {
+        n, _, ok := FirstLineSegment(b, atEOF)
+        return n, ok
+    }
+)
+
+// SplitFunc implements a [bufio.SplitFunc] for a [bufio.Scanner], which
+// returns each segment as a token.
+func (b Breaker) SplitFunc(data []byte, atEOF bool) (advance int, token []byte, err error) {
+    if atEOF && (len(data) == 0) { return 0, nil, nil }
+    n, ok := b(data, atEOF)
+    if !ok { return 0, nil, nil } // request more data
+    return n, data[:n], nil
+}
@@ -0,0 +1,62 @@
+package segment_test
+
+import (
+    "bufio"
+    "os"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// breakTest is a test case from a file in the format of the Unicode
+// Character Database GraphemeBreakTest.txt, WordBreakTest.txt, and
+// LineBreakTest.txt files.
+type breakTest struct {
+    line int
+    input string
+    expected []string // the input, split at each break
+    comment string
+}
+
+// readBreakTests reads every test case from a file in the format of
+// GraphemeBreakTest.txt e.g. "÷ 0020 × 0308 ÷ 0020 ÷ # comment".
+func readBreakTests(t *testing.T, path string) []breakTest {
+    f, err := os.Open(path)
+    if err != nil { t.Fatal(err) }
+    defer f.Close()
+
+    var tests []breakTest
+    scanner := bufio.NewScanner(f)
+    for line := 1; scanner.Scan(); line++ {
+        text, comment, _ := strings.Cut(scanner.Text(), "#")
+        fields := strings.Fields(text)
+        if len(fields) == 0 { continue }
+
+        test := breakTest{line: line, comment: strings.TrimSpace(comment)}
+        var segment strings.Builder
+        for i, field := range fields {
+            if i % 2 == 0 {
+                if (field != "÷") && (field != "×") {
+                    t.Fatalf("%s:%d: expected ÷ or ×, got %q", path, line, field)
+                }
+                if (field == "÷") && (segment.Len() > 0) {
+                    test.expected = append(test.expected, segment.String())
+                    segment.Reset()
+                }
+                continue
+            }
+
+            cp, err := strconv.ParseUint(field, 16, 32)
+            if err != nil { t.Fatalf("%s:%d: %v", path, line, err) }
+            segment.WriteRune(rune(cp))
+            test.input += string(rune(cp))
+        }
+        if segment.Len() > 0 {
+            t.Fatalf("%s:%d: expected a break at the end of the text", path, line)
+        }
+        tests = append(tests, test)
+    }
+    if err := scanner.Err(); err != nil { t.Fatal(err) }
+    if len(tests) == 0 { t.Fatalf("%s: no test cases", path) }
+    return tests
+}
@@ -0,0 +1,76 @@
+package segment
+
+import (
+    "sort"
+)
+
+// table is an ordered table of property values for ranges of code points,
+// decoded from a file generated by internal/unicode/gen.sh. Code points not
+// in any range have the value zero.
+type table struct {
+    ascii [128]uint8 // values for ASCII code points, for speed
+    ranges []tableRange
+}
+
+type tableRange struct {
+    Start rune
+    End   rune
+    Value uint8
+}
+
+func tableDecode(x uint64) tableRange {
+    cp := (x      ) & 0x1FFFFF
+    rl := (x >> 21) & 0x7FF
+    v  := (x >> 32) & 0xFF
+    return tableRange{
+        Start: rune(cp),
+        End: rune(cp) + rune(rl),
+        Value: uint8(v),
+    }
+}
+
+// newTable unpacks a table of ranges, each packed as the first code point
+// (21 bits), the length of the range minus one (11 bits), and the value
+// (8 bits), in 5 bytes.
+func newTable(bin []byte) *table {
+    t := &table{
+        ranges: make([]tableRange, len(bin) / 5),
+    }
+
+    for i := 0; i < len(t.ranges); i++ {
+        b0 := bin[0]
+        b1 := bin[1]
+        b2 := bin[2]
+        b3 := bin[3]
+        b4 := bin[4]
+        bin = bin[5:]
+        x :=
+            uint64(b0)         |
+            (uint64(b1) <<  8) |
+            (uint64(b2) << 16) |
+            (uint64(b3) << 24) |
+            (uint64(b4) << 32)
+        t.ranges[i] = tableDecode(x)
+    }
+
+    for r := range t.ascii {
+        t.ascii[r] = t.search(rune(r))
+    }
+    return t
+}
+
+// lookup returns the value for a code point.
+func (t *table) lookup(r rune) uint8 {
+    if (r >= 0) && (r < 128) { return t.ascii[r] }
+    return t.search(r)
+}
+
+func (t *table) search(r rune) uint8 {
+    idx := sort.Search(len(t.ranges), func(i int) bool {
+        return t.ranges[i].End >= r
+    })
+    if (idx < len(t.ranges)) && (t.ranges[idx].Start <= r) {
+        return t.ranges[idx].Value
+    }
+    return 0
+}
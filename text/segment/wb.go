@@ -0,0 +1,41 @@
+package segment
+
+import (
+    _ "embed"
+)
+
+// wb is a value of the Word_Break property.
+type wb uint8
+
+const (
+    wbOther wb = iota
+    wbCR
+    wbLF
+    wbNewline
+    wbExtend
+    wbZWJ
+    wbRegionalIndicator
+    wbFormat
+    wbKatakana
+    wbHebrewLetter
+    wbALetter
+    wbSingleQuote
+    wbDoubleQuote
+    wbMidNumLet
+    wbMidLetter
+    wbMidNum
+    wbNumeric
+    wbExtendNumLet
+    wbWSegSpace
+)
+
+// wbBin contains packed Word_Break values for ordered ranges of codepoints.
+//go:embed wb.bin
+var wbBin []byte // generated by internal/unicode/gen.sh
+
+var wbTable = newTable(wbBin)
+
+// wordBreak returns the Word_Break property of a rune.
+func wordBreak(r rune) wb {
+    return wb(wbTable.lookup(r))
+}
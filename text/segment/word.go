@@ -0,0 +1,189 @@
+package segment
+
+import (
+    "bufio"
+    "unicode"
+
+    "github.com/tawesoft/golib/v2/iter"
+)
+
+// Words returns an iterator that produces each segment of a string between
+// word boundaries, from left to right.
+//
+// Note that this includes the segments between words, such as spaces and
+// punctuation. Use [IsWordLike] to tell them apart.
+//
+// For example, "Don't panic, 3.5 °C" is segmented as "Don't", " ", "panic",
+// ",", " ", "3.5", " ", "°", "C".
+func Words(s string) iter.It[string] {
+    return func() (string, bool) {
+        if len(s) == 0 { return "", false }
+        n, _ := FirstWord(s, true)
+        word := s[:n]
+        s = s[n:]
+        return word, true
+    }
+}
+
+// ScanWords is a [bufio.SplitFunc] for a [bufio.Scanner] that returns each
+// segment between word boundaries as a token. See [Words].
+func ScanWords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+    if atEOF && (len(data) == 0) { return 0, nil, nil }
+    n, ok := FirstWord(data, atEOF)
+    if !ok { return 0, nil, nil } // request more data
+    return n, data[:n], nil
+}
+
+var _ bufio.SplitFunc = ScanWords
+
+// IsWordLike returns true if a segment returned by [Words] contains a letter,
+// a number, or an ideograph, rather than only spaces, punctuation, or
+// symbols.
+func IsWordLike[T string | []byte](segment T) bool {
+    for len(segment) > 0 {
+        r, size := decodeRune(segment)
+        if unicode.In(r, unicode.L, unicode.N) { return true }
+        segment = segment[size:]
+    }
+    return false
+}
+
+// FirstWord returns the length, in bytes, of the first segment between word
+// boundaries in s, which may be a string or a byte slice. See
+// [FirstGrapheme] for the meaning of atEOF and the return values.
+func FirstWord[T string | []byte](s T, atEOF bool) (int, bool) {
+    if len(s) == 0 { return 0, atEOF }
+    if (!atEOF) && (!fullRune(s)) { return 0, false }
+
+    r, size := decodeRune(s)
+    raw := wordBreak(r)
+    i := size
+
+    // The property of the previous rune (raw), and the properties of the
+    // previous two runes, ignoring Extend, Format and ZWJ by rule WB4 (prev,
+    // prevPrev).
+    prev, prevPrev := raw, wbOther
+    regionalIndicators := 0
+    if raw == wbRegionalIndicator { regionalIndicators = 1 }
+
+    if (raw == wbNewline) || (raw == wbLF) { return i, true } // WB3a
+
+    for i < len(s) {
+        if (!atEOF) && (!fullRune(s[i:])) { return 0, false }
+        r, size = decodeRune(s[i:])
+        next := wordBreak(r)
+
+        // find the next rune after this one, ignoring Extend, Format and
+        // ZWJ, only if needed by rules WB6, WB7b and WB12.
+        lookahead := func() (wb, bool) {
+            for j := i + size; j < len(s); {
+                if (!atEOF) && (!fullRune(s[j:])) { return wbOther, false }
+                r, size := decodeRune(s[j:])
+                p := wordBreak(r)
+                if (p != wbExtend) && (p != wbFormat) && (p != wbZWJ) { return p, true }
+                j += size
+            }
+            return wbOther, atEOF
+        }
+
+        boundary, ok := wordBoundary(raw, prev, prevPrev, next, r,
+            regionalIndicators, lookahead)
+        if !ok { return 0, false }
+        if boundary { return i, true }
+
+        raw = next
+        if (next != wbExtend) && (next != wbFormat) && (next != wbZWJ) {
+            prevPrev, prev = prev, next
+            if next == wbRegionalIndicator {
+                regionalIndicators++
+            } else {
+                regionalIndicators = 0
+            }
+        }
+        i += size
+        if (raw == wbNewline) || (raw == wbLF) { return i, true } // WB3, WB3a
+    }
+
+    if atEOF { return len(s), true }
+    return 0, false
+}
+
+// wordBoundary returns true if there is a word boundary before a rune,
+// given its Word_Break property (next), the Word_Break property of the rune
+// before it (raw), the properties of the previous two runes ignoring Extend,
+// Format, and ZWJ (prev, prevPrev), and the number of consecutive regional
+// indicators ending with prev.
+//
+// The lookahead function returns the property of the rune after next,
+// ignoring Extend, Format, and ZWJ, or false if more input is needed. In that
+// case, wordBoundary also returns false for its second return value.
+func wordBoundary(
+    raw wb,
+    prev, prevPrev wb,
+    next wb, nextRune rune,
+    regionalIndicators int,
+    lookahead func() (wb, bool),
+) (bool, bool) {
+    aHLetter := func(x wb) bool { return (x == wbALetter) || (x == wbHebrewLetter) }
+    midNumLetQ := func(x wb) bool { return (x == wbMidNumLet) || (x == wbSingleQuote) }
+
+    // peek returns true if the rune after next satisfies f.
+    needMore := false
+    peek := func(f func(x wb) bool) bool {
+        after, ok := lookahead()
+        if !ok { needMore = true }
+        return ok && f(after)
+    }
+
+    switch {
+        case (raw == wbCR) && (next == wbLF): // WB3
+            return false, true
+        case (raw == wbNewline) || (raw == wbCR) || (raw == wbLF): // WB3a
+            return true, true
+        case (next == wbNewline) || (next == wbCR) || (next == wbLF): // WB3b
+            return true, true
+        case (raw == wbZWJ) && extendedPictographic(nextRune): // WB3c
+            return false, true
+        case (raw == wbWSegSpace) && (next == wbWSegSpace): // WB3d
+            return false, true
+        case (next == wbExtend) || (next == wbFormat) || (next == wbZWJ): // WB4
+            return false, true
+        case aHLetter(prev) && aHLetter(next): // WB5
+            return false, true
+        case aHLetter(prev) && ((next == wbMidLetter) || midNumLetQ(next)) && peek(aHLetter): // WB6
+            return false, true
+        case aHLetter(prevPrev) && ((prev == wbMidLetter) || midNumLetQ(prev)) && aHLetter(next): // WB7
+            return false, true
+        case (prev == wbHebrewLetter) && (next == wbSingleQuote): // WB7a
+            return false, true
+        case (prev == wbHebrewLetter) && (next == wbDoubleQuote) &&
+            peek(func(x wb) bool { return x == wbHebrewLetter }): // WB7b
+            return false, true
+        case (prevPrev == wbHebrewLetter) && (prev == wbDoubleQuote) && (next == wbHebrewLetter): // WB7c
+            return false, true
+        case (prev == wbNumeric) && (next == wbNumeric): // WB8
+            return false, true
+        case aHLetter(prev) && (next == wbNumeric): // WB9
+            return false, true
+        case (prev == wbNumeric) && aHLetter(next): // WB10
+            return false, true
+        case (prevPrev == wbNumeric) && ((prev == wbMidNum) || midNumLetQ(prev)) && (next == wbNumeric): // WB11
+            return false, true
+        case (prev == wbNumeric) && ((next == wbMidNum) || midNumLetQ(next)) &&
+            peek(func(x wb) bool { return x == wbNumeric }): // WB12
+            return false, true
+        case (prev == wbKatakana) && (next == wbKatakana): // WB13
+            return false, true
+        case (aHLetter(prev) || (prev == wbNumeric) || (prev == wbKatakana) ||
+            (prev == wbExtendNumLet)) && (next == wbExtendNumLet): // WB13a
+            return false, true
+        case (prev == wbExtendNumLet) &&
+            (aHLetter(next) || (next == wbNumeric) || (next == wbKatakana)): // WB13b
+            return false, true
+        case (prev == wbRegionalIndicator) && (next == wbRegionalIndicator): // WB15, WB16
+            return regionalIndicators % 2 == 0, true
+    }
+
+    if needMore { return false, false }
+    return true, true // WB999
+}
@@ -0,0 +1,83 @@
+package segment_test
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/tawesoft/golib/v2/iter"
+    "github.com/tawesoft/golib/v2/text/segment"
+)
+
+func TestWords(t *testing.T) {
+    type row struct {
+        input string
+        expected []string
+    }
+    rows := []row{
+        {"", nil},
+        {"hello world", []string{"hello", " ", "world"}},
+        {"a  b", []string{"a", "  ", "b"}},                            // WB3d
+        {"a\r\n\nb", []string{"a", "\r\n", "\n", "b"}},                // WB3
+        {"can't stop", []string{"can't", " ", "stop"}},                // WB6, WB7
+        {"e.g. x", []string{"e.g", ".", " ", "x"}},
+        {"end.", []string{"end", "."}},
+        {"a\u0301b", []string{"a\u0301b"}},                            // WB4
+        {"3.14, 1,000", []string{"3.14", ",", " ", "1,000"}},          // WB11, WB12
+        {"1,", []string{"1", ","}},
+        {"abc123", []string{"abc123"}},                                // WB9
+        {"snake_case_1", []string{"snake_case_1"}},                    // WB13a, WB13b
+        {"カタカナ", []string{"カタカナ"}},                                // WB13
+        {"日本語", []string{"日", "本", "語"}},
+        {"א\"ב", []string{"א\"ב"}},                  // WB7b, WB7c
+        {"א'", []string{"א'"}},                              // WB7a
+        {"🇬🇧🇫🇷", []string{"🇬🇧", "🇫🇷"}},                                // WB15
+        {"👩\u200D💻!", []string{"👩\u200D💻", "!"}},                   // WB3c
+        {"a.b.", []string{"a.b", "."}},
+    }
+    for _, r := range rows {
+        got := iter.ToSlice(segment.Words(r.input))
+        if (len(got) != len(r.expected)) || (strings.Join(got, "|") != strings.Join(r.expected, "|")) {
+            t.Errorf("Words(%q): got %q, expected %q", r.input, got, r.expected)
+        }
+    }
+}
+
+// TestWords_conformance tests every case in the Unicode
+// WordBreakTest.txt file.
+func TestWords_conformance(t *testing.T) {
+    for _, test := range readBreakTests(t, "testdata/WordBreakTest.txt") {
+        got := iter.ToSlice(segment.Words(test.input))
+        if strings.Join(got, "|") != strings.Join(test.expected, "|") || (len(got) != len(test.expected)) {
+            t.Errorf("line %d: Words(%+q): got %+q, expected %+q\n  %s",
+                test.line, test.input, got, test.expected, test.comment)
+        }
+    }
+}
+
+func TestFirstWord(t *testing.T) {
+    type row struct {
+        input string
+        atEOF bool
+        expectedN int
+        expectedOk bool
+    }
+    rows := []row{
+        {"", false, 0, false},
+        {"", true, 0, true},
+        {"ab", false, 0, false},
+        {"ab", true, 2, true},
+        {"ab ", false, 2, true},
+        {"a.", false, 0, false}, // needs lookahead for WB6
+        {"a.\u0301", false, 0, false},
+        {"a.b", false, 0, false},
+        {"a. ", false, 1, true},
+        {"\n", false, 1, true},
+    }
+    for _, r := range rows {
+        n, ok := segment.FirstWord(r.input, r.atEOF)
+        if (n != r.expectedN) || (ok != r.expectedOk) {
+            t.Errorf("FirstWord(%q, %t): got (%d, %t), expected (%d, %t)",
+                r.input, r.atEOF, n, ok, r.expectedN, r.expectedOk)
+        }
+    }
+}
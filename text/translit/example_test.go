@@ -0,0 +1,32 @@
+package translit_test
+
+import (
+    "fmt"
+
+    "github.com/tawesoft/golib/v2/text/translit"
+    "golang.org/x/text/transform"
+)
+
+func ExampleLatin() {
+    for _, s := range []string{
+        "Пётр Ильич Чайковский",
+        "ДОСТОЕВСКИЙ",
+        "Θεσσαλονίκη",
+    } {
+        result, _, _ := transform.String(translit.Latin(), s)
+        fmt.Println(result)
+    }
+
+    // Output:
+    // Pëtr Ilʹich Chaykovskiy
+    // DOSTOEVSKIY
+    // Thessaloníki
+}
+
+func ExampleASCII() {
+    result, _, _ := transform.String(translit.ASCII(), "Пётр Ильич Чайковский")
+    fmt.Println(result)
+
+    // Output:
+    // Petr Ilich Chaykovskiy
+}
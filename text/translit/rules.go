@@ -0,0 +1,69 @@
+package translit
+
+import (
+    "strings"
+)
+
+// table returns a rule for each pair of From and To in pairs.
+func table(pairs ... string) []Rule {
+    rules := make([]Rule, 0, len(pairs) / 2)
+    for i := 0; i + 1 < len(pairs); i += 2 {
+        rules = append(rules, Rule{From: pairs[i], To: pairs[i+1]})
+    }
+    return rules
+}
+
+// in returns a function that returns true for any rune in s.
+func in(s string) func(r rune) bool {
+    return func(r rune) bool {
+        return strings.ContainsRune(s, r)
+    }
+}
+
+var cyrillicRules = table(
+    // Russian
+    "а", "a",  "б", "b",  "в", "v",  "г", "g",   "д", "d",    "е", "e",
+    "ё", "ë",  "ж", "zh", "з", "z",  "и", "i",   "й", "y",    "к", "k",
+    "л", "l",  "м", "m",  "н", "n",  "о", "o",   "п", "p",    "р", "r",
+    "с", "s",  "т", "t",  "у", "u",  "ф", "f",   "х", "kh",   "ц", "ts",
+    "ч", "ch", "ш", "sh", "щ", "shch", "ъ", "ʺ", "ы", "y",    "ь", "ʹ",
+    "э", "e",  "ю", "yu", "я", "ya",
+
+    // Ukrainian and Belarusian
+    "ґ", "g",  "є", "ye", "і", "i",  "ї", "yi",  "ў", "ŭ",
+
+    // Serbian and Macedonian
+    "ђ", "đ",  "ѓ", "gj", "ѕ", "dz", "ј", "j",   "љ", "lj",   "њ", "nj",
+    "ћ", "ć",  "ќ", "kj", "џ", "dž",
+)
+
+// greekVoiced returns true for the vowels and voiced consonants, before which
+// "αυ", "ευ", and "ηυ" are pronounced with a 'v' instead of an 'f'.
+var greekVoiced = in("αάεέηήιίϊΐοόυύϋΰωώβγδζλμνρ")
+
+var greekRules = append(table(
+    "α", "a",  "ά", "á",  "β", "v",  "γ", "g",   "δ", "d",   "ε", "e",
+    "έ", "é",  "ζ", "z",  "η", "i",  "ή", "í",   "θ", "th",  "ι", "i",
+    "ί", "í",  "ϊ", "ï",  "ΐ", "ḯ",  "κ", "k",   "λ", "l",   "μ", "m",
+    "ν", "n",  "ξ", "x",  "ο", "o",  "ό", "ó",   "π", "p",   "ρ", "r",
+    "σ", "s",  "ς", "s",  "τ", "t",  "υ", "y",   "ύ", "ý",   "ϋ", "ÿ",
+    "ΰ", "ÿ́", "φ", "f", "χ", "ch", "ψ", "ps", "ω", "o", "ώ", "ó",
+
+    "γγ", "ng", "γξ", "nx", "γχ", "nch",
+    "ου", "ou", "ού", "oú",
+),
+    Rule{From: "αυ", To: "av", Before: greekVoiced}, Rule{From: "αυ", To: "af"},
+    Rule{From: "αύ", To: "áv", Before: greekVoiced}, Rule{From: "αύ", To: "áf"},
+    Rule{From: "ευ", To: "ev", Before: greekVoiced}, Rule{From: "ευ", To: "ef"},
+    Rule{From: "εύ", To: "év", Before: greekVoiced}, Rule{From: "εύ", To: "éf"},
+    Rule{From: "ηυ", To: "iv", Before: greekVoiced}, Rule{From: "ηυ", To: "if"},
+    Rule{From: "ηύ", To: "ív", Before: greekVoiced}, Rule{From: "ηύ", To: "íf"},
+)
+
+// asciiRules replace Latin letters, and modifier letters produced by other
+// rules, that have no ASCII decomposition.
+var asciiRules = table(
+    "æ", "ae", "ð", "d",  "đ", "dj", "ħ", "h",   "ı", "i",   "ł", "l",
+    "ŋ", "ng", "ø", "o",  "œ", "oe", "ß", "ss",  "þ", "th",
+    "ʹ", "",   "ʺ", "",
+)
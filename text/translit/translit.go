@@ -0,0 +1,258 @@
+// Package translit implements rule-based transliteration, which replaces
+// text written in one script with text written in another, for example
+// "Чайковский" with "Chaykovskiy".
+//
+// Transliteration is lossy, and the result is intended for people to read
+// (or for use in identifiers such as URL slugs), not to be converted back.
+//
+// Each transliterator is a [transform.Transformer]. Several transliterators,
+// and the character foldings in the [golib/v2/text/fold] package, can be
+// combined with [transform.Chain].
+//
+// [golib/v2/text/fold]: https://pkg.go.dev/github.com/tawesoft/golib/v2/text/fold
+package translit
+
+import (
+    "strings"
+    "sync"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/tawesoft/golib/v2/text/fold"
+    "golang.org/x/text/transform"
+)
+
+// Rule is a transliteration rule that replaces a sequence of one or more
+// runes, From, with To.
+//
+// Rules are written in lower case. Each rule also applies to its input in
+// title case or in upper case, and the output is changed to match. For
+// example, the rule "щ" to "shch" also replaces "Щ" with "Shch", or with
+// "SHCH" in text that is all in upper case.
+type Rule struct {
+    From string
+    To   string
+
+    // Before, if not nil, restricts the rule to only apply where Before
+    // returns true for the rune following From (in lower case), or for -1
+    // at the end of the input.
+    Before func(r rune) bool
+}
+
+// ruleSet is an immutable set of rules, indexed for matching.
+type ruleSet struct {
+    rules  map[string][]Rule // by From
+    starts map[rune]bool     // first rune of each From
+    maxLen int               // longest From, in runes
+}
+
+func newRuleSet(rules ... Rule) *ruleSet {
+    rs := &ruleSet{
+        rules:  make(map[string][]Rule),
+        starts: make(map[rune]bool),
+    }
+    for _, rule := range rules {
+        rule.From = strings.ToLower(rule.From)
+        first, size := utf8.DecodeRuneInString(rule.From)
+        if size == 0 { continue }
+        rs.rules[rule.From] = append(rs.rules[rule.From], rule)
+        rs.starts[first] = true
+        rs.maxLen = max(rs.maxLen, utf8.RuneCountInString(rule.From))
+    }
+    return rs
+}
+
+// New returns a transliterator that applies the given rules.
+//
+// At each position in the input, the rule with the longest matching From
+// applies. Where several rules have the same From, the first one given that
+// satisfies its Before condition applies. Input that does not match any
+// rule is unchanged.
+//
+// The returned transformer is not safe for concurrent use.
+func New(rules ... Rule) transform.Transformer {
+    return &transliterator{ruleSet: newRuleSet(rules...)}
+}
+
+// lazyRuleSet returns a function that constructs a ruleSet on first use.
+func lazyRuleSet(rules func() []Rule) func() *ruleSet {
+    var once sync.Once
+    var rs *ruleSet
+    return func() *ruleSet {
+        once.Do(func() { rs = newRuleSet(rules()...) })
+        return rs
+    }
+}
+
+var (
+    cyrillicRuleSet = lazyRuleSet(func() []Rule { return cyrillicRules })
+    greekRuleSet    = lazyRuleSet(func() []Rule { return greekRules })
+    latinRuleSet    = lazyRuleSet(func() []Rule {
+        return append(append([]Rule(nil), cyrillicRules...), greekRules...)
+    })
+    asciiRuleSet    = lazyRuleSet(func() []Rule { return asciiRules })
+)
+
+// Cyrillic returns a transliterator from the Cyrillic script to the Latin
+// script.
+//
+// This is based on the BGN/PCGN romanization of Russian (e.g. "Щука" to
+// "Shchuka"), without its contextual rules, and extended with the additional
+// letters of Ukrainian, Belarusian, Serbian, and Macedonian. Note that this
+// does not follow the national romanization of each of those languages: for
+// example, Ukrainian 'г' is transliterated as "g", and not as "h".
+//
+// The returned transformer is not safe for concurrent use.
+func Cyrillic() transform.Transformer {
+    return &transliterator{ruleSet: cyrillicRuleSet()}
+}
+
+// Greek returns a transliterator from the Greek script to the Latin script.
+//
+// This is based on the ELOT 743 transcription of Modern Greek (e.g.
+// "Αύγουστος" to "Ávgoustos"). The tonos is kept as an acute accent.
+//
+// The returned transformer is not safe for concurrent use.
+func Greek() transform.Transformer {
+    return &transliterator{ruleSet: greekRuleSet()}
+}
+
+// Latin returns a transliterator from any supported script (currently,
+// Cyrillic and Greek) to the Latin script. Text in other scripts is
+// unchanged. See [Cyrillic] and [Greek].
+//
+// The returned transformer is not safe for concurrent use.
+func Latin() transform.Transformer {
+    return &transliterator{ruleSet: latinRuleSet()}
+}
+
+// ASCII returns a transliterator like [Latin], that also removes accents
+// (using [fold.Accents]) and replaces Latin letters that have no ASCII
+// decomposition (e.g. 'đ' to "dj", 'ß' to "ss", 'ø' to "o"), so that the
+// result of transliterating text in a supported script is entirely ASCII.
+// Text in other scripts, and other characters such as punctuation and
+// symbols, may be unchanged.
+//
+// The returned transformer is not safe for concurrent use.
+func ASCII() transform.Transformer {
+    return transform.Chain(
+        Latin(),
+        fold.Chain(fold.Accents),
+        &transliterator{ruleSet: asciiRuleSet()},
+    )
+}
+
+// transliterator applies a ruleSet.
+type transliterator struct {
+    *ruleSet
+
+    // upper is true if the last rune of the previous match, or the previous
+    // unmatched rune, was upper case.
+    upper bool
+}
+
+func (t *transliterator) Reset() {
+    t.upper = false
+}
+
+func (t *transliterator) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+    var runeBuf [8]rune
+    var sizeBuf [8]int
+
+    for nSrc < len(src) {
+        if !atEOF && !utf8.FullRune(src[nSrc:]) {
+            return nDst, nSrc, transform.ErrShortSrc
+        }
+        r, size := utf8.DecodeRune(src[nSrc:])
+
+        if !t.starts[unicode.ToLower(r)] {
+            if len(dst) - nDst < size { return nDst, nSrc, transform.ErrShortDst }
+            nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+            nSrc += size
+            t.upper = unicode.IsUpper(r)
+            continue
+        }
+
+        // decode enough runes to match the longest rule, plus the rune
+        // after it for the Before condition and case
+        runes, sizes := runeBuf[:0], sizeBuf[:0]
+        for i := nSrc; (i < len(src)) && (len(runes) <= t.maxLen); {
+            if !atEOF && !utf8.FullRune(src[i:]) { break }
+            r, size := utf8.DecodeRune(src[i:])
+            runes = append(runes, r)
+            sizes = append(sizes, size)
+            i += size
+        }
+        if !atEOF && (len(runes) <= t.maxLen) {
+            return nDst, nSrc, transform.ErrShortSrc
+        }
+
+        n, to := t.match(runes)
+        if n == 0 {
+            if len(dst) - nDst < size { return nDst, nSrc, transform.ErrShortDst }
+            nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+            nSrc += size
+            t.upper = unicode.IsUpper(r)
+            continue
+        }
+
+        next := rune(-1)
+        if n < len(runes) { next = runes[n] }
+        to = t.cased(to, runes[:n], next)
+
+        if len(dst) - nDst < len(to) { return nDst, nSrc, transform.ErrShortDst }
+        nDst += copy(dst[nDst:], to)
+        for _, size := range sizes[:n] {
+            nSrc += size
+        }
+        t.upper = unicode.IsUpper(runes[n-1])
+    }
+    return nDst, nSrc, nil
+}
+
+// match returns the length, in runes, of the longest rule matching the
+// start of runes, and its output, or zero if there is no match.
+func (t *transliterator) match(runes []rune) (int, string) {
+    var keyBuf [8]rune
+    key := keyBuf[:0]
+    for _, r := range runes {
+        key = append(key, unicode.ToLower(r))
+    }
+
+    for n := min(t.maxLen, len(runes)); n > 0; n-- {
+        rules, ok := t.rules[string(key[:n])]
+        if !ok { continue }
+
+        next := rune(-1)
+        if n < len(runes) { next = key[n] }
+        for _, rule := range rules {
+            if (rule.Before == nil) || rule.Before(next) { return n, rule.To }
+        }
+    }
+    return 0, ""
+}
+
+// cased returns the output of a rule, to, in the same case as its input,
+// match, given the rune after the match, or -1 at the end of the input.
+//
+// Where the case of the output would be ambiguous (e.g. a single upper case
+// letter transliterated as "Shch" or "SHCH"), the output is all upper case if
+// the input is part of a word in upper case.
+func (t *transliterator) cased(to string, match []rune, next rune) string {
+    if !unicode.IsUpper(match[0]) { return to }
+
+    var upper bool
+    switch {
+        case len(match) > 1:
+            upper = unicode.IsUpper(match[1])
+        case (next >= 0) && unicode.IsLetter(next):
+            upper = unicode.IsUpper(next)
+        default:
+            upper = t.upper
+    }
+
+    if upper { return strings.ToUpper(to) }
+    r, size := utf8.DecodeRuneInString(to)
+    if size == 0 { return to }
+    return string(unicode.ToUpper(r)) + to[size:]
+}
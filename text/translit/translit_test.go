@@ -0,0 +1,101 @@
+package translit_test
+
+import (
+    "io"
+    "strings"
+    "testing"
+    "testing/iotest"
+
+    "github.com/tawesoft/golib/v2/text/translit"
+    "golang.org/x/text/transform"
+)
+
+func TestTransliterators(t *testing.T) {
+    type row struct {
+        t func() transform.Transformer
+        input string
+        expected string
+    }
+
+    rows := []row{
+        {translit.Cyrillic, "",             ""},
+        {translit.Cyrillic, "abc",          "abc"},         // same
+        {translit.Cyrillic, "Щука",         "Shchuka"},     // title case
+        {translit.Cyrillic, "ЩУКА",         "SHCHUKA"},     // upper case
+        {translit.Cyrillic, "БОРЩ",         "BORSHCH"},     // upper case, at end
+        {translit.Cyrillic, "Ж",            "Zh"},
+        {translit.Cyrillic, "Ж ЖУК",        "Zh ZHUK"},
+        {translit.Cyrillic, "Чайковский",   "Chaykovskiy"},
+        {translit.Cyrillic, "объём",        "obʺëm"},
+        {translit.Cyrillic, "Київ",         "Kiyiv"},
+        {translit.Cyrillic, "Ђорђе Џаја",   "Đorđe Džaja"},
+        {translit.Cyrillic, "Αθήνα",        "Αθήνα"},       // same
+
+        {translit.Greek,    "Αθήνα",        "Athína"},
+        {translit.Greek,    "ΑΘΗΝΑ",        "ATHINA"},
+        {translit.Greek,    "Θεσσαλονίκη",  "Thessaloníki"},
+        {translit.Greek,    "Αύγουστος",    "Ávgoustos"},   // αυ before a voiced consonant
+        {translit.Greek,    "αυτός",        "aftós"},       // αυ before a voiceless consonant
+        {translit.Greek,    "Ευρώπη",       "Evrópi"},
+        {translit.Greek,    "άγγελος",      "ángelos"},
+        {translit.Greek,    "Ψυχή",         "Psychí"},
+        {translit.Greek,    "ΨΥΧΗ",         "PSYCHI"},
+        {translit.Greek,    "Щука",         "Щука"},        // same
+
+        {translit.Latin,    "Щука и Αθήνα", "Shchuka i Athína"},
+        {translit.Latin,    "日本",          "日本"},         // same
+
+        {translit.ASCII,    "Щука и Αθήνα", "Shchuka i Athina"},
+        {translit.ASCII,    "объём",        "obem"},
+        {translit.ASCII,    "Ђорђе Џаја",   "Djordje Dzaja"},
+        {translit.ASCII,    "ЂОРЂЕ",        "DJORDJE"},
+        {translit.ASCII,    "Straße, Øresund", "Strasse, Oresund"},
+    }
+
+    for _, r := range rows {
+        got, _, err := transform.String(r.t(), r.input)
+        if err != nil {
+            t.Errorf("%q: unexpected error: %v", r.input, err)
+        } else if got != r.expected {
+            t.Errorf("%q: got %q, expected %q", r.input, got, r.expected)
+        }
+
+        // one byte at a time
+        bs, err := io.ReadAll(transform.NewReader(iotest.OneByteReader(strings.NewReader(r.input)), r.t()))
+        if err != nil {
+            t.Errorf("%q: unexpected error reading: %v", r.input, err)
+        } else if string(bs) != r.expected {
+            t.Errorf("%q: got %q reading, expected %q", r.input, string(bs), r.expected)
+        }
+    }
+}
+
+func TestNew(t *testing.T) {
+    vowel := func(r rune) bool { return strings.ContainsRune("aeiou", r) }
+    tr := translit.New(
+        translit.Rule{From: "c",  To: "k"},
+        translit.Rule{From: "c",  To: "s", Before: func(r rune) bool { return (r == 'e') || (r == 'i') }},
+        translit.Rule{From: "ph", To: "f"},
+        translit.Rule{From: "y",  To: "i", Before: func(r rune) bool { return !vowel(r) }},
+    )
+
+    type row struct {
+        input string
+        expected string
+    }
+    rows := []row{
+        {"",            ""},
+        {"cat",         "kat"},     // first rule applies
+        {"Philosophy",  "Filosofi"},
+        {"PHYSICS",     "FISIKS"},  // Before in upper case input
+        {"yes",         "yes"},     // Before not satisfied
+    }
+    for _, r := range rows {
+        got, _, err := transform.String(tr, r.input)
+        if err != nil {
+            t.Errorf("%q: unexpected error: %v", r.input, err)
+        } else if got != r.expected {
+            t.Errorf("%q: got %q, expected %q", r.input, got, r.expected)
+        }
+    }
+}